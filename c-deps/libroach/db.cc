@@ -893,7 +893,10 @@ struct DBSstFileWriter {
   virtual ~DBSstFileWriter() {}
 };
 
-DBSstFileWriter* DBSstFileWriterNew() {
+namespace {
+// NewSstFileWriter creates a new SstFileWriter whose blocks are compressed with
+// the given compression type.
+DBSstFileWriter* NewSstFileWriter(rocksdb::CompressionType compression) {
   // TODO(dan): Right now, backup is the only user of this code, so that's what
   // the options are tuned for. If something else starts using it, we'll likely
   // have to add some configurability.
@@ -911,6 +914,7 @@ DBSstFileWriter* DBSstFileWriterNew() {
 
   rocksdb::Options* options = new rocksdb::Options();
   options->comparator = &kComparator;
+  options->compression = compression;
   options->table_factory.reset(rocksdb::NewBlockBasedTableFactory(table_options));
 
   // Use the TablePropertiesCollector hook to store the min and max MVCC
@@ -927,6 +931,9 @@ DBSstFileWriter* DBSstFileWriterNew() {
 
   return new DBSstFileWriter(options, memenv.release());
 }
+}  // namespace
+
+DBSstFileWriter* DBSstFileWriterNew() { return NewSstFileWriter(rocksdb::kSnappyCompression); }
 
 DBStatus DBSstFileWriterOpen(DBSstFileWriter* fw) {
   rocksdb::Status status = fw->rep.Open("sst");
@@ -1043,9 +1050,11 @@ DBStatus DBUnlockFile(DBFileLock lock) {
 }
 
 DBStatus DBExportToSst(DBKey start, DBKey end, bool export_all_revisions, DBIterOptions iter_opts,
-                       DBEngine* engine, DBString* data, DBString* write_intent,
-                       DBString* summary) {
-  DBSstFileWriter* writer = DBSstFileWriterNew();
+                       DBEngine* engine, uint64_t target_size, bool disable_compression,
+                       DBString* data, DBString* write_intent, DBString* summary,
+                       DBString* resume) {
+  DBSstFileWriter* writer = NewSstFileWriter(disable_compression ? rocksdb::kNoCompression
+                                                                 : rocksdb::kSnappyCompression);
   DBStatus status = DBSstFileWriterOpen(writer);
   if (status.data != NULL) {
     return status;
@@ -1057,6 +1066,9 @@ DBStatus DBExportToSst(DBKey start, DBKey end, bool export_all_revisions, DBIter
   RowCounter row_counter;
 
   bool skip_current_key_versions = !export_all_revisions;
+  // The last key added to the sstable, without its timestamp. Only tracked
+  // when there is a target size, to stop between two keys.
+  std::string last_key;
   DBIterState state;
   const std::string end_key = EncodeKey(end);
   for (state = iter.seek(start);; state = iter.next(skip_current_key_versions)) {
@@ -1083,6 +1095,17 @@ DBStatus DBExportToSst(DBKey start, DBKey end, bool export_all_revisions, DBIter
       continue;
     }
 
+    if (target_size > 0) {
+      // Once the target size is reached, stop at the next key rather than
+      // between two versions of a key, so that all the exported versions of a
+      // key are in the same sstable, and return the key to resume from.
+      if (bulkop_summary.data_size() >= target_size && decoded_key.compare(last_key) != 0) {
+        *resume = ToDBString(decoded_key);
+        break;
+      }
+      last_key.assign(decoded_key.data(), decoded_key.size());
+    }
+
     // Insert key into sst and update statistics.
     status = DBSstFileWriterAddRaw(writer, iter.key(), iter.value());
     if (status.data != NULL) {
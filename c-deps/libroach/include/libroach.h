@@ -536,9 +536,14 @@ DBStatus DBLockFile(DBSlice filename, DBFileLock* lock);
 DBStatus DBUnlockFile(DBFileLock lock);
 
 // DBExportToSst exports changes over the keyrange and time interval between the
-// start and end DBKeys to an SSTable using an IncrementalIterator.
+// start and end DBKeys to an SSTable using an IncrementalIterator. If
+// target_size is positive, the export stops at the first key after target_size
+// bytes have been exported and that key is returned in resume. The blocks of
+// the SSTable are compressed with snappy unless disable_compression is set.
 DBStatus DBExportToSst(DBKey start, DBKey end, bool export_all_revisions, DBIterOptions iter_opts,
-                       DBEngine* engine, DBString* data, DBString* write_intent, DBString* summary);
+                       DBEngine* engine, uint64_t target_size, bool disable_compression,
+                       DBString* data, DBString* write_intent, DBString* summary,
+                       DBString* resume);
 
 #ifdef __cplusplus
 }  // extern "C"
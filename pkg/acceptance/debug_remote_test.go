@@ -15,10 +15,13 @@ import (
 	gosql "database/sql"
 	"fmt"
 	"net/http"
+	"net/http/cookiejar"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/acceptance/cluster"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -46,6 +49,40 @@ func testDebugRemote(t *testing.T) {
 	}
 	defer db.Close()
 
+	// The debug endpoints require a web session of a user with the viewdebug
+	// role.
+	resp, err := cluster.HTTPClient.Get(l.URL(ctx, 0) + "/debug/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected %d without a web session, but got %d",
+			http.StatusUnauthorized, resp.StatusCode)
+	}
+	for _, stmt := range []string{
+		"CREATE USER debugger WITH PASSWORD 'debugger'",
+		"CREATE ROLE viewdebug",
+		"GRANT viewdebug TO debugger",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := cluster.HTTPClient
+	httpClient.Jar = jar
+	if _, err := httputil.PostJSONWithRequest(
+		httpClient, l.URL(ctx, 0)+"/login",
+		&serverpb.UserLoginRequest{Username: "debugger", Password: "debugger"},
+		&serverpb.UserLoginResponse{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
 	testCases := []struct {
 		remoteDebug string
 		status      int
@@ -73,7 +110,7 @@ func testDebugRemote(t *testing.T) {
 				"/debug/logspy?duration=1ns",
 			} {
 				t.Run(url, func(t *testing.T) {
-					resp, err := cluster.HTTPClient.Get(l.URL(ctx, 0) + url)
+					resp, err := httpClient.Get(l.URL(ctx, 0) + url)
 					if err != nil {
 						t.Fatalf("%d: %v", i, err)
 					}
@@ -41,6 +41,9 @@ func (c *ClusterIDContainer) String() string {
 	return val.String()
 }
 
+// SafeValue implements the log.SafeValue interface.
+func (c *ClusterIDContainer) SafeValue() {}
+
 // Get returns the current cluster ID; uuid.Nil if it is unset.
 func (c *ClusterIDContainer) Get() uuid.UUID {
 	c.Lock()
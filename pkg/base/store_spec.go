@@ -171,6 +171,10 @@ type StoreSpec struct {
 	// ExtraOptions is a serialized protobuf set by Go CCL code and passed through
 	// to C CCL code.
 	ExtraOptions []byte
+	// BallastSize is the size of the emergency ballast file kept in the
+	// store's auxiliary directory, either in bytes or as a percentage of the
+	// total disk space. If nil, a default size is used.
+	BallastSize *SizeSpec
 }
 
 // String returns a fully parsable version of the store spec.
@@ -188,6 +192,13 @@ func (ss StoreSpec) String() string {
 	if ss.Size.Percent > 0 {
 		fmt.Fprintf(&buffer, "size=%s%%,", humanize.Ftoa(ss.Size.Percent))
 	}
+	if ss.BallastSize != nil {
+		if ss.BallastSize.Percent > 0 {
+			fmt.Fprintf(&buffer, "ballast-size=%s%%,", humanize.Ftoa(ss.BallastSize.Percent))
+		} else {
+			fmt.Fprintf(&buffer, "ballast-size=%s,", humanizeutil.IBytes(ss.BallastSize.InBytes))
+		}
+	}
 	if len(ss.Attributes.Attrs) > 0 {
 		fmt.Fprint(&buffer, "attrs=")
 		for i, attr := range ss.Attributes.Attrs {
@@ -289,6 +300,19 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 			if err != nil {
 				return StoreSpec{}, err
 			}
+		case "ballast-size":
+			var minBytesAllowed int64
+			var minPercent float64
+			var maxPercent float64 = 50
+			ballastSize, err := NewSizeSpec(
+				value,
+				&intInterval{min: &minBytesAllowed},
+				&floatInterval{min: &minPercent, max: &maxPercent},
+			)
+			if err != nil {
+				return StoreSpec{}, err
+			}
+			ss.BallastSize = &ballastSize
 		case "attrs":
 			// Check to make sure there are no duplicate attributes.
 			attrMap := make(map[string]struct{})
@@ -322,6 +346,9 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 		if ss.Size.Percent == 0 && ss.Size.InBytes == 0 {
 			return StoreSpec{}, fmt.Errorf("size must be specified for an in memory store")
 		}
+		if ss.BallastSize != nil {
+			return StoreSpec{}, fmt.Errorf("ballast-size specified for in memory store")
+		}
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
 	}
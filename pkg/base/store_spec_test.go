@@ -114,6 +114,13 @@ func TestNewStoreSpec(t *testing.T) {
 		{"path=/mnt/hda1,type=other", "other is not a valid store type", StoreSpec{}},
 		{"path=/mnt/hda1,type=mem,size=20GiB", "path specified for in memory store", StoreSpec{}},
 
+		// ballast size
+		{"path=/mnt/hda1,ballast-size=671088640", "", StoreSpec{Path: "/mnt/hda1", BallastSize: &SizeSpec{InBytes: 671088640}}},
+		{"path=/mnt/hda1,ballast-size=0", "", StoreSpec{Path: "/mnt/hda1", BallastSize: &SizeSpec{}}},
+		{"path=/mnt/hda1,ballast-size=2%", "", StoreSpec{Path: "/mnt/hda1", BallastSize: &SizeSpec{Percent: 2}}},
+		{"path=/mnt/hda1,ballast-size=50.1%", "store size (50.1%) must be between 0.000000% and 50.000000%", StoreSpec{}},
+		{"type=mem,size=20GiB,ballast-size=1GiB", "ballast-size specified for in memory store", StoreSpec{}},
+
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
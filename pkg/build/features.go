@@ -0,0 +1,47 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package build
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var features struct {
+	mu    syncutil.Mutex
+	names map[string]struct{}
+}
+
+// RegisterFeature records that the named feature is compiled into the
+// binary. It is called by the init-time hooks of the CCL packages, so that
+// support can tell which enterprise features a binary contains.
+func RegisterFeature(name string) {
+	features.mu.Lock()
+	defer features.mu.Unlock()
+	if features.names == nil {
+		features.names = make(map[string]struct{})
+	}
+	features.names[name] = struct{}{}
+}
+
+// Features returns the sorted names of the features registered with
+// RegisterFeature.
+func Features() []string {
+	features.mu.Lock()
+	defer features.mu.Unlock()
+	names := make([]string, 0, len(features.names))
+	for name := range features.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -15,6 +15,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
@@ -39,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -59,18 +61,34 @@ const (
 	// BackupDescriptorCheckpointName is the file name used to store the
 	// serialized BackupDescriptor proto while the backup is in progress.
 	BackupDescriptorCheckpointName = "BACKUP-CHECKPOINT"
+	// BackupEncryptionInfoName is the file name used for the serialized
+	// EncryptionInfo proto of an encrypted backup. It is not encrypted.
+	BackupEncryptionInfoName = "ENCRYPTION-INFO"
 	// BackupFormatDescriptorTrackingVersion added tracking of complete DBs.
 	BackupFormatDescriptorTrackingVersion uint32 = 1
 )
 
 const (
 	backupOptRevisionHistory = "revision_history"
+	backupOptEncPassphrase   = "encryption_passphrase"
+	backupOptEncKMS          = "kms"
+	backupOptCompression     = "compression"
+	backupOptTargetFileSize  = "target_file_size"
 	localityURLParam         = "COCKROACH_LOCALITY"
 	defaultLocalityValue     = "default"
 )
 
+const (
+	backupCompressionSnappy = "snappy"
+	backupCompressionNone   = "none"
+)
+
 var backupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	backupOptRevisionHistory: sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:   sql.KVStringOptRequireValue,
+	backupOptEncKMS:          sql.KVStringOptRequireValue,
+	backupOptCompression:     sql.KVStringOptRequireValue,
+	backupOptTargetFileSize:  sql.KVStringOptRequireValue,
 }
 
 // BackupCheckpointInterval is the interval at which backup progress is saved
@@ -79,16 +97,19 @@ var BackupCheckpointInterval = time.Minute
 
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
-// export storage.
+// export storage, decrypting it if encryption is non-nil.
 func ReadBackupDescriptorFromURI(
-	ctx context.Context, uri string, settings *cluster.Settings,
+	ctx context.Context,
+	uri string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
 	defer exportStore.Close()
-	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName)
+	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
@@ -99,9 +120,12 @@ func ReadBackupDescriptorFromURI(
 }
 
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
-// the provided export store.
+// the provided export store, decrypting it if encryption is non-nil.
 func readBackupDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -112,6 +136,9 @@ func readBackupDescriptor(
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
+	if descBytes, err = maybeDecrypt(descBytes, encryption); err != nil {
+		return BackupDescriptor{}, err
+	}
 	var backupDesc BackupDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupDescriptor{}, err
@@ -120,7 +147,10 @@ func readBackupDescriptor(
 }
 
 func readBackupPartitionDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupPartitionDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -131,6 +161,9 @@ func readBackupPartitionDescriptor(
 	if err != nil {
 		return BackupPartitionDescriptor{}, err
 	}
+	if descBytes, err = maybeDecrypt(descBytes, encryption); err != nil {
+		return BackupPartitionDescriptor{}, err
+	}
 	var backupDesc BackupPartitionDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupPartitionDescriptor{}, err
@@ -489,10 +522,15 @@ func backupJobDescription(
 	incrementalFrom []string,
 	opts map[string]string,
 ) (string, error) {
+	redactedOpts, err := redactEncryptionOptions(opts)
+	if err != nil {
+		return "", err
+	}
 	b := &tree.Backup{
-		AsOf:    backup.AsOf,
-		Options: optsToKVOptions(opts),
-		Targets: backup.Targets,
+		AsOf:               backup.AsOf,
+		Options:            optsToKVOptions(redactedOpts),
+		Targets:            backup.Targets,
+		DescriptorCoverage: backup.DescriptorCoverage,
 	}
 
 	for _, t := range to {
@@ -542,6 +580,7 @@ func writeBackupDescriptor(
 	exportStore storageccl.ExportStorage,
 	filename string,
 	desc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	sort.Sort(BackupFileDescriptors(desc.Files))
 
@@ -549,6 +588,9 @@ func writeBackupDescriptor(
 	if err != nil {
 		return err
 	}
+	if descBuf, err = maybeEncrypt(descBuf, encryption); err != nil {
+		return err
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
@@ -561,11 +603,15 @@ func writeBackupPartitionDescriptor(
 	exportStore storageccl.ExportStorage,
 	filename string,
 	desc *BackupPartitionDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	descBuf, err := protoutil.Marshal(desc)
 	if err != nil {
 		return err
 	}
+	if descBuf, err = maybeEncrypt(descBuf, encryption); err != nil {
+		return err
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
@@ -609,6 +655,63 @@ func ResolveTargetsToDescriptors(
 	return matched.descs, matched.expandedDB, nil
 }
 
+// fullClusterSystemTables are the system tables which hold the cluster
+// metadata included in a full cluster backup. The other system tables either
+// hold the schema, which is backed up as descriptors, or are specific to the
+// nodes of the backed up cluster, like leases and the event and range logs.
+var fullClusterSystemTables = []string{
+	sqlbase.UsersTable.Name,
+	sqlbase.ZonesTable.Name,
+	sqlbase.SettingsTable.Name,
+	sqlbase.UITable.Name,
+	sqlbase.JobsTable.Name,
+	sqlbase.LocationsTable.Name,
+	sqlbase.RoleMembersTable.Name,
+	sqlbase.CommentsTable.Name,
+	sqlbase.ScheduledJobsTable.Name,
+}
+
+// fullClusterTargets returns the descriptors included in a full cluster
+// backup: every database, every table which isn't being dropped and the
+// system tables in fullClusterSystemTables. Every database but the system
+// database is complete.
+func fullClusterTargets(
+	ctx context.Context, p sql.PlanHookState, endTime hlc.Timestamp,
+) ([]sqlbase.Descriptor, []sqlbase.ID, error) {
+	allDescs, err := loadAllDescs(ctx, p.ExecCfg().DB, endTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	systemTables := make(map[string]struct{}, len(fullClusterSystemTables))
+	for _, name := range fullClusterSystemTables {
+		systemTables[name] = struct{}{}
+	}
+
+	var descs []sqlbase.Descriptor
+	var completeDBs []sqlbase.ID
+	for _, desc := range allDescs {
+		if dbDesc := desc.GetDatabase(); dbDesc != nil {
+			descs = append(descs, desc)
+			if dbDesc.ID != sqlbase.SystemDB.ID {
+				completeDBs = append(completeDBs, dbDesc.ID)
+			}
+		}
+		if tableDesc := desc.GetTable(); tableDesc != nil {
+			if tableDesc.ParentID == sqlbase.SystemDB.ID {
+				if _, ok := systemTables[tableDesc.Name]; ok {
+					descs = append(descs, desc)
+				}
+			} else if !tableDesc.Dropped() {
+				descs = append(descs, desc)
+			}
+		}
+	}
+
+	sort.Slice(descs, func(i, j int) bool { return descs[i].GetID() < descs[j].GetID() })
+	return descs, completeDBs, nil
+}
+
 type spanAndTime struct {
 	span       roachpb.Span
 	start, end hlc.Timestamp
@@ -632,6 +735,9 @@ func backup(
 	backupDesc *BackupDescriptor,
 	checkpointDesc *BackupDescriptor,
 	resultsCh chan<- tree.Datums,
+	encryption *roachpb.FileEncryptionOptions,
+	targetFileSize int64,
+	disableCompression bool,
 ) (roachpb.BulkOpSummary, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
 	// for grpc.
@@ -744,6 +850,9 @@ func backup(
 					StorageByLocalityKV: storageByLocalityKV,
 					StartTime:           span.start,
 					MVCCFilter:          roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					Encryption:          encryption,
+					TargetFileSize:      targetFileSize,
+					DisableCompression:  disableCompression,
 				}
 				rawRes, pErr := client.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
 				if pErr != nil {
@@ -785,7 +894,7 @@ func backup(
 					checkpointMu.Lock()
 					backupDesc.Files = checkpointFiles
 					err := writeBackupDescriptor(
-						ctx, defaultStore, BackupDescriptorCheckpointName, backupDesc,
+						ctx, defaultStore, BackupDescriptorCheckpointName, backupDesc, encryption,
 					)
 					checkpointMu.Unlock()
 					if err != nil {
@@ -839,14 +948,14 @@ func backup(
 					return err
 				}
 				defer store.Close()
-				return writeBackupPartitionDescriptor(ctx, store, filename, &desc)
+				return writeBackupPartitionDescriptor(ctx, store, filename, &desc, encryption)
 			}(); err != nil {
 				return mu.exported, err
 			}
 		}
 	}
 
-	if err := writeBackupDescriptor(ctx, defaultStore, BackupDescriptorName, backupDesc); err != nil {
+	if err := writeBackupDescriptor(ctx, defaultStore, BackupDescriptorName, backupDesc, encryption); err != nil {
 		return mu.exported, err
 	}
 
@@ -893,7 +1002,7 @@ func VerifyUsableExportTarget(
 			readable, BackupDescriptorCheckpointName)
 	}
 	if err := writeBackupDescriptor(
-		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{},
+		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{}, nil, /* encryption */
 	); err != nil {
 		return errors.Wrapf(err, "cannot write to %s", readable)
 	}
@@ -989,7 +1098,35 @@ func backupPlanHook(
 			mvccFilter = MVCCFilter_All
 		}
 
-		targetDescs, completeDBs, err := ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
+		var disableCompression bool
+		if codec, ok := opts[backupOptCompression]; ok {
+			switch strings.ToLower(codec) {
+			case backupCompressionSnappy:
+			case backupCompressionNone:
+				disableCompression = true
+			default:
+				return errors.Errorf("unsupported %s %q: expected %q or %q",
+					backupOptCompression, codec, backupCompressionSnappy, backupCompressionNone)
+			}
+		}
+
+		var targetFileSize int64
+		if override, ok := opts[backupOptTargetFileSize]; ok {
+			if targetFileSize, err = humanizeutil.ParseBytes(override); err != nil {
+				return err
+			}
+			if targetFileSize < 1 {
+				return errors.Errorf("%s out of range: %d", backupOptTargetFileSize, targetFileSize)
+			}
+		}
+
+		var targetDescs []sqlbase.Descriptor
+		var completeDBs []sqlbase.ID
+		if backupStmt.DescriptorCoverage == tree.AllDescriptors {
+			targetDescs, completeDBs, err = fullClusterTargets(ctx, p, endTime)
+		} else {
+			targetDescs, completeDBs, err = ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
+		}
 		if err != nil {
 			return err
 		}
@@ -1013,12 +1150,19 @@ func backupPlanHook(
 			return err
 		}
 
+		encryption, encryptionInfo, err := makeBackupEncryption(
+			ctx, p.ExecCfg().Settings, opts, incrementalFrom,
+		)
+		if err != nil {
+			return err
+		}
+
 		var prevBackups []BackupDescriptor
 		if len(incrementalFrom) > 0 {
 			clusterID := p.ExecCfg().ClusterID()
 			prevBackups = make([]BackupDescriptor, len(incrementalFrom))
 			for i, uri := range incrementalFrom {
-				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings)
+				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings, encryption)
 				if err != nil {
 					return errors.Wrapf(err, "failed to read backup from %q", uri)
 				}
@@ -1028,6 +1172,14 @@ func backupPlanHook(
 				if !desc.ClusterID.Equal(clusterID) {
 					return errors.Newf("previous BACKUP %q belongs to cluster %s", uri, desc.ClusterID.String())
 				}
+				// A full cluster backup can only be restored as a whole if every
+				// backup of the chain covers the whole cluster.
+				if desc.DescriptorCoverage != backupStmt.DescriptorCoverage {
+					if backupStmt.DescriptorCoverage == tree.AllDescriptors {
+						return errors.Newf("previous BACKUP %q is not a full cluster backup", uri)
+					}
+					return errors.Newf("previous BACKUP %q is a full cluster backup", uri)
+				}
 				prevBackups[i] = desc
 			}
 		}
@@ -1130,18 +1282,19 @@ func backupPlanHook(
 		// of requiring full backups after schema changes remains.
 
 		backupDesc := BackupDescriptor{
-			StartTime:         startTime,
-			EndTime:           endTime,
-			MVCCFilter:        mvccFilter,
-			Descriptors:       targetDescs,
-			DescriptorChanges: revs,
-			CompleteDbs:       completeDBs,
-			Spans:             spans,
-			IntroducedSpans:   newSpans,
-			FormatVersion:     BackupFormatDescriptorTrackingVersion,
-			BuildInfo:         build.GetInfo(),
-			NodeID:            p.ExecCfg().NodeID.Get(),
-			ClusterID:         p.ExecCfg().ClusterID(),
+			StartTime:          startTime,
+			EndTime:            endTime,
+			MVCCFilter:         mvccFilter,
+			Descriptors:        targetDescs,
+			DescriptorChanges:  revs,
+			CompleteDbs:        completeDBs,
+			Spans:              spans,
+			IntroducedSpans:    newSpans,
+			FormatVersion:      BackupFormatDescriptorTrackingVersion,
+			BuildInfo:          build.GetInfo(),
+			NodeID:             p.ExecCfg().NodeID.Get(),
+			ClusterID:          p.ExecCfg().ClusterID(),
+			DescriptorCoverage: backupStmt.DescriptorCoverage,
 		}
 
 		// Sanity check: re-run the validation that RESTORE will do, but this time
@@ -1174,6 +1327,11 @@ func backupPlanHook(
 		if err := VerifyUsableExportTarget(ctx, defaultStore, defaultURI); err != nil {
 			return err
 		}
+		if encryptionInfo != nil {
+			if err := writeEncryptionInfo(ctx, defaultStore, encryptionInfo); err != nil {
+				return errors.Wrapf(err, "writing encryption info to %s", defaultURI)
+			}
+		}
 
 		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
 			Description: description,
@@ -1185,11 +1343,14 @@ func backupPlanHook(
 				return sqlDescIDs
 			}(),
 			Details: jobspb.BackupDetails{
-				StartTime:        startTime,
-				EndTime:          endTime,
-				URI:              defaultURI,
-				URIsByLocalityKV: urisByLocalityKV,
-				BackupDescriptor: descBytes,
+				StartTime:          startTime,
+				EndTime:            endTime,
+				URI:                defaultURI,
+				URIsByLocalityKV:   urisByLocalityKV,
+				BackupDescriptor:   descBytes,
+				Encryption:         encryption,
+				TargetFileSize:     targetFileSize,
+				DisableCompression: disableCompression,
 			},
 			Progress: jobspb.BackupProgress{},
 		})
@@ -1242,7 +1403,9 @@ func (b *backupResumer) Resume(
 		storageByLocalityKV[kv] = &conf
 	}
 	var checkpointDesc *BackupDescriptor
-	if desc, err := readBackupDescriptor(ctx, defaultStore, BackupDescriptorCheckpointName); err == nil {
+	if desc, err := readBackupDescriptor(
+		ctx, defaultStore, BackupDescriptorCheckpointName, details.Encryption,
+	); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -1267,6 +1430,9 @@ func (b *backupResumer) Resume(
 		&backupDesc,
 		checkpointDesc,
 		resultsCh,
+		details.Encryption,
+		details.TargetFileSize,
+		details.DisableCompression,
 	)
 	b.res = res
 	return err
@@ -1371,6 +1537,7 @@ func getAllRevisions(
 var _ jobs.Resumer = &backupResumer{}
 
 func init() {
+	build.RegisterFeature("backup")
 	sql.AddPlanHook(backupPlanHook)
 	jobs.RegisterConstructor(
 		jobspb.TypeBackup,
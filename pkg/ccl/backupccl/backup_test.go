@@ -3057,3 +3057,66 @@ func TestCreateStatsAfterRestore(t *testing.T) {
 			{"__auto__", "{payload}", "1", "1", "0"},
 		})
 }
+
+func TestRestoreDatabaseNewDBName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE VIEW data.rich AS SELECT id FROM data.bank WHERE balance > 0`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
+
+	sqlDB.ExpectErr(t, `database "data" already exists`, `RESTORE DATABASE data FROM $1`, localFoo)
+	sqlDB.ExpectErr(t, `"new_db_name" option can only be used when restoring a single database`,
+		`RESTORE data.bank FROM $1 WITH new_db_name = 'data2'`, localFoo)
+	sqlDB.ExpectErr(t, `cannot use "into_db" option when restoring database`,
+		`RESTORE DATABASE data FROM $1 WITH new_db_name = 'data2', into_db = 'data3'`, localFoo)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 WITH new_db_name = 'data2'`, localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data2.bank`, [][]string{{"10"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM data2.rich`, sqlDB.QueryStr(t, `SELECT count(*) FROM data.rich`))
+
+	// The view of the restored database must refer to its tables.
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM data2.rich`, sqlDB.QueryStr(t, `SELECT count(*) FROM data2.bank WHERE balance > 0`))
+	sqlDB.ExpectErr(t, `database "data2" already exists`,
+		`RESTORE DATABASE data FROM $1 WITH new_db_name = 'data2'`, localFoo)
+}
+
+func TestBackupRestoreCompressionAndTargetFileSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 1000
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.ExpectErr(t, `unsupported compression "zstd"`,
+		`BACKUP data.bank TO $1 WITH compression = 'zstd'`, localFoo)
+	sqlDB.ExpectErr(t, `target_file_size out of range`,
+		`BACKUP data.bank TO $1 WITH target_file_size = '0'`, localFoo)
+
+	numFiles := func(uri string) int {
+		var n int
+		sqlDB.QueryRow(t, `SELECT count(*) FROM [SHOW BACKUP FILES $1]`, uri).Scan(&n)
+		return n
+	}
+
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, localFoo+"/default")
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 WITH target_file_size = '1KiB'`, localFoo+"/small")
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 WITH compression = 'none'`, localFoo+"/none")
+	if def, small := numFiles(localFoo+"/default"), numFiles(localFoo+"/small"); small <= def {
+		t.Fatalf("expected a small target file size to split the backup into more than %d files, got %d",
+			def, small)
+	}
+
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+	for _, dir := range []string{"small", "none"} {
+		sqlDB.Exec(t, `DROP TABLE data.bank`)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1`, localFoo+"/"+dir)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+	}
+}
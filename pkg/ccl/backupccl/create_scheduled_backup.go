@@ -0,0 +1,321 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// scheduledBackupExecutorType is the executor type of the schedules created
+// by CREATE SCHEDULE FOR BACKUP.
+const scheduledBackupExecutorType = "scheduled-backup"
+
+// scheduledBackupDirFormat is the format of the name of the directory of
+// each backup taken by a schedule, under the location of the schedule.
+const scheduledBackupDirFormat = "20060102-150405.00"
+
+// scheduledBackupArgs are the execution arguments of a backup schedule.
+type scheduledBackupArgs struct {
+	// Targets are the targets of the backups, formatted as in a BACKUP
+	// statement.
+	Targets string `json:"targets"`
+	// Destination is the location under which the backups are stored, each
+	// in its own directory.
+	Destination string            `json:"destination"`
+	Options     map[string]string `json:"options,omitempty"`
+	// FullBackupExpr is the crontab expression of the full backups. It is
+	// empty if every backup is a full backup.
+	FullBackupExpr string `json:"full_backup_expr,omitempty"`
+	// NextFullBackup is the time after which the next backup is a full
+	// backup.
+	NextFullBackup time.Time `json:"next_full_backup"`
+	// Chain are the locations of the last full backup and of the incremental
+	// backups taken on top of it.
+	Chain []string `json:"chain,omitempty"`
+}
+
+// defaultFullBackupExpr returns the crontab expression of the full backups
+// of a schedule whose FULL BACKUP clause is omitted, or the empty string if
+// every backup of the schedule should be a full backup. Schedules which recur
+// at least hourly take a full backup daily, and schedules which recur at
+// least daily take one weekly.
+func defaultFullBackupExpr(recurrence string) (string, error) {
+	first, err := jobs.NextRunTime(recurrence, timeutil.Now())
+	if err != nil {
+		return "", err
+	}
+	second, err := jobs.NextRunTime(recurrence, first)
+	if err != nil {
+		return "", err
+	}
+	switch interval := second.Sub(first); {
+	case interval <= time.Hour:
+		return "@daily", nil
+	case interval <= 24*time.Hour:
+		return "@weekly", nil
+	default:
+		return "", nil
+	}
+}
+
+func createScheduledBackupPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, bool, error) {
+	schedStmt, ok := stmt.(*tree.ScheduledBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	const opName = "CREATE SCHEDULE FOR BACKUP"
+	var labelFn func() (string, error)
+	if schedStmt.ScheduleLabel != nil {
+		var err error
+		if labelFn, err = p.TypeAsString(schedStmt.ScheduleLabel, opName); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	toFn, err := p.TypeAsString(schedStmt.To, opName)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	recurrenceFn, err := p.TypeAsString(schedStmt.Recurrence, opName)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var fullRecurrenceFn func() (string, error)
+	if schedStmt.FullBackup != nil && !schedStmt.FullBackup.AlwaysFull {
+		if fullRecurrenceFn, err = p.TypeAsString(schedStmt.FullBackup.Recurrence, opName); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	optsFn, err := p.TypeAsStringOpts(schedStmt.BackupOptions, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "schedule_id", Typ: types.Int},
+		{Name: "name", Typ: types.String},
+		{Name: "next_run", Typ: types.Timestamp},
+		{Name: "full_backup", Typ: types.String},
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "BACKUP",
+		); err != nil {
+			return err
+		}
+
+		if err := p.RequireAdminRole(ctx, opName); err != nil {
+			return err
+		}
+
+		// Resolve the targets now, so that a schedule which can never succeed
+		// is rejected upfront.
+		if _, _, err := ResolveTargetsToDescriptors(
+			ctx, p, p.ExecCfg().Clock.Now(), schedStmt.Targets,
+		); err != nil {
+			return err
+		}
+
+		args := scheduledBackupArgs{Targets: tree.AsString(&schedStmt.Targets)}
+		if args.Destination, err = toFn(); err != nil {
+			return err
+		}
+		if _, err := url.Parse(args.Destination); err != nil {
+			return errors.Wrapf(err, "invalid backup location %q", args.Destination)
+		}
+		if args.Options, err = optsFn(); err != nil {
+			return err
+		}
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+		nextRun, err := jobs.NextRunTime(recurrence, timeutil.Now())
+		if err != nil {
+			return err
+		}
+		switch {
+		case fullRecurrenceFn != nil:
+			if args.FullBackupExpr, err = fullRecurrenceFn(); err != nil {
+				return err
+			}
+			if _, err := jobs.NextRunTime(args.FullBackupExpr, timeutil.Now()); err != nil {
+				return err
+			}
+		case schedStmt.FullBackup == nil:
+			if args.FullBackupExpr, err = defaultFullBackupExpr(recurrence); err != nil {
+				return err
+			}
+		}
+
+		name := "BACKUP " + args.Targets
+		if labelFn != nil {
+			if name, err = labelFn(); err != nil {
+				return err
+			}
+		}
+
+		encodedArgs, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		schedule := &jobs.ScheduledJob{
+			Name:          name,
+			Owner:         p.User(),
+			NextRun:       nextRun,
+			ScheduleExpr:  recurrence,
+			ExecutorType:  scheduledBackupExecutorType,
+			ExecutionArgs: string(encodedArgs),
+		}
+		if _, err := jobs.CreateScheduledJob(
+			ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn, schedule,
+		); err != nil {
+			return err
+		}
+
+		fullBackup := "ALWAYS"
+		if args.FullBackupExpr != "" {
+			fullBackup = args.FullBackupExpr
+		}
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(schedule.ID)),
+			tree.NewDString(schedule.Name),
+			tree.MakeDTimestamp(schedule.NextRun, time.Microsecond),
+			tree.NewDString(fullBackup),
+		}
+		return nil
+	}
+	return fn, header, nil, false, nil
+}
+
+// scheduledBackupExecutor executes backup schedules by running BACKUP
+// statements on behalf of their owners. Each execution takes either a full
+// backup, which starts a new chain of backups, or an incremental backup on
+// top of the current chain.
+type scheduledBackupExecutor struct{}
+
+var _ jobs.ScheduledJobExecutor = scheduledBackupExecutor{}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface.
+func (scheduledBackupExecutor) ExecuteJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, schedule *jobs.ScheduledJob,
+) (int64, error) {
+	ie, ok := ex.(*sql.InternalExecutor)
+	if !ok {
+		return 0, errors.AssertionFailedf("unexpected internal executor %T", ex)
+	}
+	var args scheduledBackupArgs
+	if err := json.Unmarshal([]byte(schedule.ExecutionArgs), &args); err != nil {
+		return 0, errors.Wrap(err, "invalid arguments of backup schedule")
+	}
+
+	now := timeutil.Now()
+	full := args.FullBackupExpr == "" || len(args.Chain) == 0 || !now.Before(args.NextFullBackup)
+	to, err := scheduledBackupLocation(args.Destination, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "BACKUP %s TO %s", args.Targets, lex.EscapeSQLString(to))
+	if !full {
+		buf.WriteString(" INCREMENTAL FROM ")
+		for i, from := range args.Chain {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(lex.EscapeSQLString(from))
+		}
+	}
+	if len(args.Options) > 0 {
+		keys := make([]string, 0, len(args.Options))
+		for k := range args.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		opts := make(tree.KVOptions, len(keys))
+		for i, k := range keys {
+			opts[i].Key = tree.Name(k)
+			if v := args.Options[k]; v != "" {
+				opts[i].Value = tree.NewDString(v)
+			}
+		}
+		buf.WriteString(" WITH ")
+		buf.WriteString(tree.AsString(&opts))
+	}
+
+	row, _, err := ie.QueryWithUser(
+		ctx, "scheduled-backup", nil /* txn */, schedule.Owner, buf.String(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if len(row) != 1 {
+		return 0, errors.AssertionFailedf("unexpected result of BACKUP: %v", row)
+	}
+	jobID := int64(tree.MustBeDInt(row[0][0]))
+
+	if full {
+		args.Chain = []string{to}
+		if args.FullBackupExpr != "" {
+			if args.NextFullBackup, err = jobs.NextRunTime(args.FullBackupExpr, now); err != nil {
+				return jobID, err
+			}
+		}
+	} else {
+		args.Chain = append(args.Chain, to)
+	}
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return jobID, err
+	}
+	schedule.ExecutionArgs = string(encodedArgs)
+	return jobID, nil
+}
+
+// scheduledBackupLocation returns the location of a backup taken at the given
+// time under the location of a schedule.
+func scheduledBackupLocation(destination string, t time.Time) (string, error) {
+	uri, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+	uri.Path = path.Join(uri.Path, t.UTC().Format(scheduledBackupDirFormat))
+	return uri.String(), nil
+}
+
+func init() {
+	sql.AddPlanHook(createScheduledBackupPlanHook)
+	jobs.RegisterScheduledJobExecutor(scheduledBackupExecutorType, scheduledBackupExecutor{})
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDefaultFullBackupExpr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for recurrence, expected := range map[string]string{
+		"*/5 * * * *": "@daily",
+		"@hourly":     "@daily",
+		"0 */6 * * *": "@weekly",
+		"@daily":      "@weekly",
+		"@weekly":     "",
+		"@monthly":    "",
+	} {
+		full, err := defaultFullBackupExpr(recurrence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if full != expected {
+			t.Errorf("%s: expected %q, got %q", recurrence, expected, full)
+		}
+	}
+}
+
+func TestScheduledBackupLocation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := time.Date(2019, 10, 15, 10, 30, 15, 250000000, time.UTC)
+	for dest, expected := range map[string]string{
+		"nodelocal:///foo":        "nodelocal:///foo/20191015-103015.25",
+		"s3://bucket/path?AUTH=x": "s3://bucket/path/20191015-103015.25?AUTH=x",
+	} {
+		loc, err := scheduledBackupLocation(dest, ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loc != expected {
+			t.Errorf("%s: expected %s, got %s", dest, expected, loc)
+		}
+	}
+}
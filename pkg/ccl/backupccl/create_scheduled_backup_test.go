@@ -0,0 +1,95 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+func TestScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.pace = '10ms'`)
+
+	var id int64
+	var name, fullBackup string
+	var nextRun time.Time
+	sqlDB.QueryRow(t,
+		`CREATE SCHEDULE 'hourly' FOR BACKUP DATABASE data INTO $1 RECURRING '@hourly'`, localFoo,
+	).Scan(&id, &name, &nextRun, &fullBackup)
+	if name != "hourly" || fullBackup != "@daily" || !nextRun.After(timeutil.Now()) {
+		t.Fatalf("unexpected schedule: %s, %s, %s", name, nextRun, fullBackup)
+	}
+
+	// runSchedule makes the schedule due, waits for its execution and returns
+	// the length of its chain of backups.
+	runSchedule := func() int {
+		before := timeutil.Now()
+		sqlDB.Exec(t,
+			`UPDATE system.scheduled_jobs SET next_run = now() - '1m'::INTERVAL WHERE schedule_id = $1`, id,
+		)
+		var status string
+		var chainLen int
+		testutils.SucceedsSoon(t, func() error {
+			var ran bool
+			sqlDB.QueryRow(t, `
+SELECT COALESCE(last_run >= $2, false), COALESCE(last_status, ''),
+       COALESCE(jsonb_array_length(execution_args->'chain'), 0)
+  FROM system.scheduled_jobs WHERE schedule_id = $1`, id, before,
+			).Scan(&ran, &status, &chainLen)
+			if !ran || status == "" || strings.HasPrefix(status, "running") {
+				return errors.Errorf("schedule %d not executed yet", id)
+			}
+			return nil
+		})
+		if status != "succeeded" {
+			t.Fatalf("unexpected status %q", status)
+		}
+		return chainLen
+	}
+
+	// The first backup is a full backup, and the next one is incremental.
+	if n := runSchedule(); n != 1 {
+		t.Fatalf("expected a chain of 1 backup, got %d", n)
+	}
+	if n := runSchedule(); n != 2 {
+		t.Fatalf("expected a chain of 2 backups, got %d", n)
+	}
+
+	sqlDB.Exec(t, `PAUSE SCHEDULE $1`, id)
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT next_run IS NULL FROM system.scheduled_jobs WHERE schedule_id = %d`, id),
+		[][]string{{"true"}},
+	)
+	sqlDB.Exec(t, `RESUME SCHEDULES SELECT $1`, id)
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT next_run > now()::TIMESTAMP FROM system.scheduled_jobs WHERE schedule_id = %d`, id),
+		[][]string{{"true"}},
+	)
+	sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT count(*) FROM system.scheduled_jobs WHERE schedule_id = %d`, id),
+		[][]string{{"0"}},
+	)
+
+	sqlDB.ExpectErr(t, `invalid crontab expression`,
+		`CREATE SCHEDULE FOR BACKUP DATABASE data INTO $1 RECURRING 'sometimes'`, localFoo)
+	sqlDB.ExpectErr(t, `does not exist`, `PAUSE SCHEDULE 1234`)
+}
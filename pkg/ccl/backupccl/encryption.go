@@ -0,0 +1,285 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// encryptionRequested returns whether the options of a statement request the
+// encryption or decryption of backups. It returns an error if they request
+// both a passphrase and KMSes.
+func encryptionRequested(opts map[string]string) (bool, error) {
+	_, passphrase := opts[backupOptEncPassphrase]
+	_, kms := opts[backupOptEncKMS]
+	if passphrase && kms {
+		return false, errors.Errorf(
+			"cannot use both %q and %q options", backupOptEncPassphrase, backupOptEncKMS)
+	}
+	return passphrase || kms, nil
+}
+
+// kmsURIs returns the space-separated URIs of the kms option.
+func kmsURIs(opts map[string]string) ([]string, error) {
+	uris := strings.Fields(opts[backupOptEncKMS])
+	if len(uris) == 0 {
+		return nil, errors.Errorf("option %q requires at least one KMS URI", backupOptEncKMS)
+	}
+	return uris, nil
+}
+
+// makeBackupEncryption returns the options to encrypt the files of a backup
+// and the EncryptionInfo to store along with them, or nils if the options
+// of the backup do not request encryption.
+//
+// A full backup gets a new key. An incremental backup uses the key of the
+// backups it builds on, which is recovered from the EncryptionInfo of the
+// latest of them. When encrypting with KMSes, the data key is stored
+// encrypted with each of the given KMSes, only one of which needs to have
+// encrypted the data key of the previous backups. This allows rotating the
+// master keys along a chain of backups.
+func makeBackupEncryption(
+	ctx context.Context, settings *cluster.Settings, opts map[string]string, incrementalFrom []string,
+) (*roachpb.FileEncryptionOptions, *EncryptionInfo, error) {
+	if ok, err := encryptionRequested(opts); !ok || err != nil {
+		return nil, nil, err
+	}
+
+	var prevInfo *EncryptionInfo
+	if len(incrementalFrom) > 0 {
+		var err error
+		prevInfo, err = readEncryptionInfoFromURI(ctx, incrementalFrom[len(incrementalFrom)-1], settings)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		var salt []byte
+		if prevInfo != nil {
+			if len(prevInfo.Salt) == 0 {
+				return nil, nil, errors.Errorf(
+					"previous backups were not encrypted with option %q", backupOptEncPassphrase)
+			}
+			salt = prevInfo.Salt
+		} else {
+			var err error
+			if salt, err = storageccl.GenerateSalt(); err != nil {
+				return nil, nil, err
+			}
+		}
+		key := storageccl.GenerateKey([]byte(passphrase), salt)
+		return &roachpb.FileEncryptionOptions{Key: key}, &EncryptionInfo{Salt: salt}, nil
+	}
+
+	kmses, err := openKMSes(ctx, settings, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeKMSes(kmses)
+
+	var dataKey []byte
+	if prevInfo != nil {
+		if dataKey, err = decryptDataKey(ctx, prevInfo, kmses); err != nil {
+			return nil, nil, err
+		}
+	} else if dataKey, err = storageccl.GenerateDataKey(); err != nil {
+		return nil, nil, err
+	}
+	info := &EncryptionInfo{EncryptedDataKeyByKMSMasterKeyID: make(map[string][]byte, len(kmses))}
+	for _, kms := range kmses {
+		encryptedDataKey, err := kms.Encrypt(ctx, dataKey)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "encrypting data key with %s", kms.MasterKeyID())
+		}
+		info.EncryptedDataKeyByKMSMasterKeyID[kms.MasterKeyID()] = encryptedDataKey
+	}
+	return &roachpb.FileEncryptionOptions{Key: dataKey}, info, nil
+}
+
+// getBackupEncryption returns the options to decrypt the files of a chain of
+// backups, whose latest backup is stored at the given URI, or nil if the
+// options of the statement do not request decryption.
+func getBackupEncryption(
+	ctx context.Context, settings *cluster.Settings, opts map[string]string, uri string,
+) (*roachpb.FileEncryptionOptions, error) {
+	if ok, err := encryptionRequested(opts); !ok || err != nil {
+		return nil, err
+	}
+	info, err := readEncryptionInfoFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		if len(info.Salt) == 0 {
+			return nil, errors.Errorf("backup %q was not encrypted with option %q", uri, backupOptEncPassphrase)
+		}
+		return &roachpb.FileEncryptionOptions{
+			Key: storageccl.GenerateKey([]byte(passphrase), info.Salt),
+		}, nil
+	}
+	kmses, err := openKMSes(ctx, settings, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeKMSes(kmses)
+	dataKey, err := decryptDataKey(ctx, info, kmses)
+	if err != nil {
+		return nil, err
+	}
+	return &roachpb.FileEncryptionOptions{Key: dataKey}, nil
+}
+
+func openKMSes(
+	ctx context.Context, settings *cluster.Settings, opts map[string]string,
+) ([]storageccl.KMS, error) {
+	uris, err := kmsURIs(opts)
+	if err != nil {
+		return nil, err
+	}
+	kmses := make([]storageccl.KMS, 0, len(uris))
+	for _, uri := range uris {
+		kms, err := storageccl.KMSFromURI(ctx, uri, settings)
+		if err != nil {
+			closeKMSes(kmses)
+			return nil, err
+		}
+		kmses = append(kmses, kms)
+	}
+	return kmses, nil
+}
+
+func closeKMSes(kmses []storageccl.KMS) {
+	for _, kms := range kmses {
+		_ = kms.Close()
+	}
+}
+
+// decryptDataKey decrypts the data key of an EncryptionInfo with the first of
+// the given KMSes which encrypted it.
+func decryptDataKey(
+	ctx context.Context, info *EncryptionInfo, kmses []storageccl.KMS,
+) ([]byte, error) {
+	for _, kms := range kmses {
+		encryptedDataKey, ok := info.EncryptedDataKeyByKMSMasterKeyID[kms.MasterKeyID()]
+		if !ok {
+			continue
+		}
+		dataKey, err := kms.Decrypt(ctx, encryptedDataKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypting data key with %s", kms.MasterKeyID())
+		}
+		return dataKey, nil
+	}
+	if len(info.EncryptedDataKeyByKMSMasterKeyID) == 0 {
+		return nil, errors.Errorf("backup was not encrypted with option %q", backupOptEncKMS)
+	}
+	ids := make([]string, 0, len(info.EncryptedDataKeyByKMSMasterKeyID))
+	for id := range info.EncryptedDataKeyByKMSMasterKeyID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return nil, errors.Errorf(
+		"none of the given KMSes encrypted the backup, which was encrypted with: %s",
+		strings.Join(ids, ", "))
+}
+
+// readEncryptionInfoFromURI reads the EncryptionInfo of the backup at the
+// given URI.
+func readEncryptionInfoFromURI(
+	ctx context.Context, uri string, settings *cluster.Settings,
+) (*EncryptionInfo, error) {
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer exportStore.Close()
+	r, err := exportStore.ReadFile(ctx, BackupEncryptionInfoName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the encryption info of %q (is it encrypted?)", uri)
+	}
+	defer r.Close()
+	infoBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var info EncryptionInfo
+	if err := protoutil.Unmarshal(infoBytes, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func writeEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage, info *EncryptionInfo,
+) error {
+	infoBytes, err := protoutil.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return exportStore.WriteFile(ctx, BackupEncryptionInfoName, bytes.NewReader(infoBytes))
+}
+
+// redactEncryptionOptions returns a copy of the options of a statement in
+// which the secrets of the encryption options are redacted, for use in job
+// descriptions.
+func redactEncryptionOptions(opts map[string]string) (map[string]string, error) {
+	redacted := make(map[string]string, len(opts))
+	for k, v := range opts {
+		redacted[k] = v
+	}
+	if _, ok := opts[backupOptEncPassphrase]; ok {
+		redacted[backupOptEncPassphrase] = "redacted"
+	}
+	if v, ok := opts[backupOptEncKMS]; ok {
+		uris := strings.Fields(v)
+		for i := range uris {
+			sanitized, err := storageccl.SanitizeExportStorageURI(uris[i])
+			if err != nil {
+				return nil, err
+			}
+			uris[i] = sanitized
+		}
+		redacted[backupOptEncKMS] = strings.Join(uris, " ")
+	}
+	return redacted, nil
+}
+
+// maybeEncrypt encrypts the contents of a file if encryption is non-nil.
+func maybeEncrypt(contents []byte, encryption *roachpb.FileEncryptionOptions) ([]byte, error) {
+	if encryption == nil {
+		return contents, nil
+	}
+	return storageccl.EncryptFile(contents, encryption.Key)
+}
+
+// maybeDecrypt decrypts the contents of a file if encryption is non-nil. It
+// returns an error if the contents appear to be encrypted but encryption is
+// nil.
+func maybeDecrypt(contents []byte, encryption *roachpb.FileEncryptionOptions) ([]byte, error) {
+	if encryption == nil {
+		if storageccl.AppearsEncrypted(contents) {
+			return nil, errors.Errorf(
+				"file appears encrypted -- try specifying option %q or %q",
+				backupOptEncPassphrase, backupOptEncKMS)
+		}
+		return contents, nil
+	}
+	return storageccl.DecryptFile(contents, encryption.Key)
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+// testKMS is a KMS whose master key is its URI. It "encrypts" data by
+// prefixing it with the master key, which is enough to check that data is
+// decrypted by the KMS which encrypted it.
+type testKMS struct {
+	id string
+}
+
+func (k *testKMS) MasterKeyID() string { return k.id }
+
+func (k *testKMS) Encrypt(_ context.Context, data []byte) ([]byte, error) {
+	return append([]byte(k.id), data...), nil
+}
+
+func (k *testKMS) Decrypt(_ context.Context, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(k.id)) {
+		return nil, errors.Errorf("data was not encrypted by %s", k.id)
+	}
+	return data[len(k.id):], nil
+}
+
+func (k *testKMS) Close() error { return nil }
+
+func init() {
+	storageccl.RegisterKMSFactory("testkms", func(
+		_ context.Context, uri *url.URL, _ *cluster.Settings,
+	) (storageccl.KMS, error) {
+		id := *uri
+		id.RawQuery = ""
+		return &testKMS{id: id.String()}, nil
+	})
+}
+
+func TestBackupRestoreEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	t.Run("passphrase", func(t *testing.T) {
+		full, inc := "nodelocal:///passphrase/full", "nodelocal:///passphrase/inc"
+		sqlDB.Exec(t, `BACKUP data.bank TO $1 WITH encryption_passphrase = 'abc'`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+		expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+		sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2 WITH encryption_passphrase = 'abc'`,
+			inc, full)
+
+		// The descriptor of an encrypted backup must not be readable as is.
+		descBytes, err := ioutil.ReadFile(filepath.Join(dir, "passphrase", "full", backupccl.BackupDescriptorName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !storageccl.AppearsEncrypted(descBytes) {
+			t.Fatal("expected the backup descriptor to be encrypted")
+		}
+
+		sqlDB.ExpectErr(t, "file appears encrypted", `SHOW BACKUP $1`, full)
+		sqlDB.ExpectErr(t, "is the key correct",
+			`SHOW BACKUP $1 WITH encryption_passphrase = 'wrong'`, full)
+		sqlDB.Exec(t, `SHOW BACKUP $1 WITH encryption_passphrase = 'abc'`, inc)
+
+		sqlDB.Exec(t, `DROP TABLE data.bank`)
+		sqlDB.ExpectErr(t, "file appears encrypted", `RESTORE data.bank FROM $1, $2`, full, inc)
+		sqlDB.ExpectErr(t, "cannot use both",
+			`RESTORE data.bank FROM $1, $2 WITH encryption_passphrase = 'abc', kms = 'testkms:///a'`,
+			full, inc)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1, $2 WITH encryption_passphrase = 'abc'`, full, inc)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+
+		sqlDB.CheckQueryResults(t,
+			`SELECT description FROM [SHOW JOBS] WHERE description LIKE '%passphrase/full%' ORDER BY created`,
+			[][]string{
+				{"BACKUP TABLE data.bank TO 'nodelocal:///passphrase/full' WITH encryption_passphrase = 'redacted'"},
+				{"BACKUP TABLE data.bank TO 'nodelocal:///passphrase/inc' INCREMENTAL FROM 'nodelocal:///passphrase/full' WITH encryption_passphrase = 'redacted'"},
+				{"RESTORE TABLE data.bank FROM 'nodelocal:///passphrase/full', 'nodelocal:///passphrase/inc' WITH encryption_passphrase = 'redacted'"},
+			})
+	})
+
+	t.Run("kms", func(t *testing.T) {
+		full, inc := "nodelocal:///kms/full", "nodelocal:///kms/inc"
+		sqlDB.Exec(t, `BACKUP data.bank TO $1 WITH kms = 'testkms:///a?SECRET=1'`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+		expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+
+		sqlDB.ExpectErr(t, "encrypted with: testkms:///a",
+			`BACKUP data.bank TO $1 INCREMENTAL FROM $2 WITH kms = 'testkms:///b'`, inc, full)
+		// Rotate the master key: the incremental backup is encrypted with both the
+		// old and the new one, so that it can be restored with the new one alone.
+		sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2 WITH kms = 'testkms:///a testkms:///b'`,
+			inc, full)
+
+		sqlDB.Exec(t, `DROP TABLE data.bank`)
+		sqlDB.ExpectErr(t, "file appears encrypted", `RESTORE data.bank FROM $1, $2`, full, inc)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1, $2 WITH kms = 'testkms:///b'`, full, inc)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+
+		var description string
+		sqlDB.QueryRow(t,
+			`SELECT description FROM [SHOW JOBS] WHERE description LIKE 'BACKUP%kms/full%' ORDER BY created LIMIT 1`,
+		).Scan(&description)
+		if strings.Contains(description, "SECRET=1") {
+			t.Fatalf("expected the KMS credentials to be redacted from %q", description)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestFullClusterBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	ctx, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE USER maxroach`)
+	sqlDB.Exec(t, `GRANT SELECT ON data.bank TO maxroach`)
+	sqlDB.Exec(t, `ALTER TABLE data.bank CONFIGURE ZONE USING gc.ttlseconds = 3600`)
+	sqlDB.Exec(t, `COMMENT ON TABLE data.bank IS 'accounts'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.bulk_io_write.concurrent_export_requests = 5`)
+	sqlDB.Exec(t, `BACKUP TO $1`, localFoo)
+
+	args := base.TestServerArgs{ExternalIODir: dir}
+	tcRestore := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+	defer tcRestore.Stopper().Stop(ctx)
+	sqlDBRestore := sqlutils.MakeSQLRunner(tcRestore.Conns[0])
+
+	sqlDBRestore.Exec(t, `RESTORE FROM $1`, localFoo)
+
+	// The tables keep their IDs, along with the privileges, zone configurations
+	// and comments which refer to them.
+	for _, query := range []string{
+		`SELECT id FROM system.namespace WHERE name = 'bank'`,
+		`SELECT count(*) FROM data.bank`,
+		`SELECT username FROM system.users`,
+		`SHOW GRANTS ON data.bank`,
+		`SELECT config_sql FROM [SHOW ZONE CONFIGURATION FOR TABLE data.bank]`,
+		`SELECT object_id, comment FROM system.comments`,
+		`SELECT value FROM system.settings WHERE name = 'kv.bulk_io_write.concurrent_export_requests'`,
+	} {
+		sqlDBRestore.CheckQueryResults(t, query, sqlDB.QueryStr(t, query))
+	}
+	// The backup job was still running when it was backed up.
+	sqlDBRestore.CheckQueryResults(t,
+		`SELECT status FROM [SHOW JOBS] WHERE job_type = 'BACKUP'`, [][]string{{"paused"}})
+	sqlDBRestore.CheckQueryResults(t,
+		`SELECT count(*) FROM system.namespace WHERE name = 'crdb_temp_system'`, [][]string{{"0"}})
+
+	sqlDBRestore.ExpectErr(t, `can only be run on a cluster with no tables or databases but found "data"`,
+		`RESTORE FROM $1`, localFoo)
+
+	const localFooDB = localFoo + "/db"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFooDB)
+	sqlDBRestore.ExpectErr(t, `full cluster RESTORE can only be used on a full cluster BACKUP`,
+		`RESTORE FROM $1`, localFooDB)
+	sqlDB.ExpectErr(t, `is not a full cluster backup`,
+		`BACKUP TO $1 INCREMENTAL FROM $2`, localFoo+"/inc", localFooDB)
+	sqlDB.ExpectErr(t, `is a full cluster backup`,
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, localFoo+"/inc", localFoo)
+}
@@ -11,12 +11,14 @@ package backupccl
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"math"
 	"runtime"
 	"sort"
 	"sync/atomic"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/intervalccl"
@@ -33,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -52,25 +55,37 @@ type TableRewriteMap map[sqlbase.ID]*jobspb.RestoreDetails_TableRewrite
 
 const (
 	restoreOptIntoDB               = "into_db"
+	restoreOptNewDBName            = "new_db_name"
 	restoreOptSkipMissingFKs       = "skip_missing_foreign_keys"
 	restoreOptSkipMissingSequences = "skip_missing_sequences"
 	restoreOptSkipMissingViews     = "skip_missing_views"
+
+	// restoreTempSystemDB is the name of the database into which a full
+	// cluster restore restores the system tables of the backup, before copying
+	// their rows into the system tables.
+	restoreTempSystemDB = "crdb_temp_system"
 )
 
 var restoreOptionExpectValues = map[string]sql.KVStringOptValidate{
 	restoreOptIntoDB:               sql.KVStringOptRequireValue,
+	restoreOptNewDBName:            sql.KVStringOptRequireValue,
 	restoreOptSkipMissingFKs:       sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingSequences: sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingViews:     sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:         sql.KVStringOptRequireValue,
+	backupOptEncKMS:                sql.KVStringOptRequireValue,
 }
 
 func loadBackupDescs(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, error) {
 	backupDescs := make([]BackupDescriptor, len(uris))
 
 	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings)
+		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings, encryption)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read backup descriptor")
 		}
@@ -88,7 +103,10 @@ func loadBackupDescs(
 // default) original backup locality values to URIs that currently contain
 // the backup files.
 func getBackupLocalityInfo(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (jobspb.RestoreDetails_BackupLocalityInfo, error) {
 	var info jobspb.RestoreDetails_BackupLocalityInfo
 	if len(uris) == 1 {
@@ -110,7 +128,7 @@ func getBackupLocalityInfo(
 
 	// First read the main backup descriptor, which is required to be at the first
 	// URI in the list.
-	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName)
+	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName, encryption)
 	if err != nil {
 		return info, err
 	}
@@ -121,7 +139,7 @@ func getBackupLocalityInfo(
 	for _, filename := range mainBackupDesc.PartitionDescriptorFilenames {
 		found := false
 		for i, store := range stores {
-			if desc, err := readBackupPartitionDescriptor(ctx, store, filename); err == nil {
+			if desc, err := readBackupPartitionDescriptor(ctx, store, filename, encryption); err == nil {
 				if desc.BackupID != mainBackupDesc.ID {
 					return info, errors.Errorf(
 						"expected backup part to have backup ID %s, found %s",
@@ -199,9 +217,15 @@ func selectTargets(
 	p sql.PlanHookState,
 	backupDescs []BackupDescriptor,
 	targets tree.TargetList,
+	descriptorCoverage tree.DescriptorCoverage,
 	asOf hlc.Timestamp,
 ) ([]sqlbase.Descriptor, []*sqlbase.DatabaseDescriptor, error) {
 	allDescs, lastBackupDesc := loadSQLDescsFromBackupsAtTime(backupDescs, asOf)
+	if descriptorCoverage == tree.AllDescriptors {
+		// A full cluster restore restores every descriptor of the backup, whose
+		// databases are handled by allocateFullClusterTableRewrites.
+		return allDescs, nil, nil
+	}
 	matched, err := descriptorsMatchingTargets(ctx,
 		p.CurrentDatabase(), p.CurrentSearchPath(), allDescs, targets)
 	if err != nil {
@@ -308,14 +332,31 @@ func allocateTableRewrites(
 ) (TableRewriteMap, error) {
 	tableRewrites := make(TableRewriteMap)
 	overrideDB, renaming := opts[restoreOptIntoDB]
+	newDBName, renamingDB := opts[restoreOptNewDBName]
 
-	restoreDBNames := make(map[string]*sqlbase.DatabaseDescriptor, len(restoreDBs))
-	for _, db := range restoreDBs {
-		restoreDBNames[db.Name] = db
+	if len(restoreDBs) > 0 && renaming {
+		return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptIntoDB)
+	}
+	if renamingDB {
+		if len(restoreDBs) != 1 {
+			return nil, errors.Errorf("%q option can only be used when restoring a single database",
+				restoreOptNewDBName)
+		}
+		if newDBName == "" {
+			return nil, errors.Errorf("%q option requires a non-empty database name", restoreOptNewDBName)
+		}
 	}
 
-	if len(restoreDBNames) > 0 && renaming {
-		return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptIntoDB)
+	// restoreDBName returns the name under which a database is restored.
+	restoreDBName := func(db *sqlbase.DatabaseDescriptor) string {
+		if renamingDB {
+			return newDBName
+		}
+		return db.Name
+	}
+	restoreDBNames := make(map[string]*sqlbase.DatabaseDescriptor, len(restoreDBs))
+	for _, db := range restoreDBs {
+		restoreDBNames[restoreDBName(db)] = db
 	}
 
 	// The logic at the end of this function leaks table IDs, so fail fast if
@@ -385,6 +426,9 @@ func allocateTableRewrites(
 						table.ParentID, table.Name)
 				}
 				targetDB = database.Name
+				if renamingDB && database.ID == restoreDBs[0].ID {
+					targetDB = newDBName
+				}
 			}
 
 			if _, ok := restoreDBNames[targetDB]; ok {
@@ -456,7 +500,7 @@ func allocateTableRewrites(
 			return nil, err
 		}
 		tableRewrites[db.ID] = &jobspb.RestoreDetails_TableRewrite{TableID: newID}
-		for _, tableID := range needsNewParentIDs[db.Name] {
+		for _, tableID := range needsNewParentIDs[restoreDBName(db)] {
 			tableRewrites[tableID] = &jobspb.RestoreDetails_TableRewrite{ParentID: newID}
 		}
 	}
@@ -477,6 +521,114 @@ func allocateTableRewrites(
 	return tableRewrites, nil
 }
 
+// allocateFullClusterTableRewrites returns the TableRewrites of a full cluster
+// restore. Databases and tables keep the IDs they had in the backed up
+// cluster, so the zone configurations, comments and jobs restored into the
+// system tables still refer to them. The system tables of the backup are
+// restored into the tables of a new database, restoreTempSystemDB, whose IDs
+// are allocated once the descriptor ID generator has been moved past every ID
+// in the backup.
+func allocateFullClusterTableRewrites(
+	ctx context.Context,
+	p sql.PlanHookState,
+	databasesByID map[sqlbase.ID]*sql.DatabaseDescriptor,
+	tablesByID map[sqlbase.ID]*sql.TableDescriptor,
+	opts map[string]string,
+) (TableRewriteMap, error) {
+	for _, opt := range []string{restoreOptIntoDB, restoreOptNewDBName} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf("cannot use %q option with a full cluster RESTORE", opt)
+		}
+	}
+
+	tableRewrites := make(TableRewriteMap)
+	var maxID sqlbase.ID
+	for id := range databasesByID {
+		if id == sqlbase.SystemDB.ID {
+			continue
+		}
+		tableRewrites[id] = &jobspb.RestoreDetails_TableRewrite{TableID: id}
+		if id > maxID {
+			maxID = id
+		}
+	}
+	var systemTables []*sqlbase.TableDescriptor
+	for _, table := range tablesByID {
+		if table.ParentID == sqlbase.SystemDB.ID {
+			systemTables = append(systemTables, table)
+			continue
+		}
+		tableRewrites[table.ID] = &jobspb.RestoreDetails_TableRewrite{
+			TableID: table.ID, ParentID: table.ParentID,
+		}
+		if table.ID > maxID {
+			maxID = table.ID
+		}
+	}
+
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		nextID, err := txn.Get(ctx, keys.DescIDGenerator)
+		if err != nil {
+			return err
+		}
+		if sqlbase.ID(nextID.ValueInt()) > maxID {
+			return nil
+		}
+		return txn.Put(ctx, keys.DescIDGenerator, int64(maxID)+1)
+	}); err != nil {
+		return nil, err
+	}
+
+	tempSystemDBID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+	if err != nil {
+		return nil, err
+	}
+	tableRewrites[sqlbase.SystemDB.ID] = &jobspb.RestoreDetails_TableRewrite{TableID: tempSystemDBID}
+	sort.Sort(sqlbase.TableDescriptors(systemTables))
+	for _, table := range systemTables {
+		newTableID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return nil, err
+		}
+		tableRewrites[table.ID] = &jobspb.RestoreDetails_TableRewrite{
+			TableID: newTableID, ParentID: tempSystemDBID,
+		}
+	}
+	return tableRewrites, nil
+}
+
+// defaultUserDBs are the empty databases created with every cluster, which a
+// full cluster restore replaces with the databases of the backup.
+var defaultUserDBs = []string{sessiondata.DefaultDatabaseName, sessiondata.PgDatabaseName}
+
+// checkClusterEmpty returns an error unless the only databases and tables of
+// the cluster are the system ones and defaultUserDBs, as required by a full
+// cluster restore.
+func checkClusterEmpty(ctx context.Context, p sql.PlanHookState) error {
+	allDescs, err := loadAllDescs(ctx, p.ExecCfg().DB, p.ExecCfg().Clock.Now())
+	if err != nil {
+		return err
+	}
+	for _, desc := range allDescs {
+		if desc.GetID() <= keys.MaxReservedDescID {
+			continue
+		}
+		if dbDesc := desc.GetDatabase(); dbDesc != nil {
+			isDefault := false
+			for _, name := range defaultUserDBs {
+				isDefault = isDefault || dbDesc.Name == name
+			}
+			if isDefault {
+				continue
+			}
+		}
+		return errors.Errorf(
+			"full cluster RESTORE can only be run on a cluster with no tables or databases but found %q",
+			desc.GetName())
+	}
+	return nil
+}
+
 // CheckTableExists returns an error if a table already exists with given
 // parent and name.
 func CheckTableExists(
@@ -1016,12 +1168,15 @@ func splitAndScatter(
 // TableDescriptor for the new table, then flip (or initialize) the name -> ID
 // entry so any new queries will use the new one. The tables are assigned the
 // permissions of their parent database and the user must have CREATE permission
-// on that database at the time this function is called.
+// on that database at the time this function is called. A full cluster restore,
+// which also restores the users and roles, keeps the permissions of the backed
+// up databases and tables instead.
 func WriteTableDescs(
 	ctx context.Context,
 	txn *client.Txn,
 	databases []*sqlbase.DatabaseDescriptor,
 	tables []*sqlbase.TableDescriptor,
+	descCoverage tree.DescriptorCoverage,
 	user string,
 	settings *cluster.Settings,
 	extra []roachpb.KeyValue,
@@ -1033,7 +1188,9 @@ func WriteTableDescs(
 		wroteDBs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
 		for _, desc := range databases {
 			// TODO(dt): support restoring privs.
-			desc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			if descCoverage == tree.RequestedDescriptors {
+				desc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			}
 			wroteDBs[desc.ID] = desc
 			if err := sql.WriteNewDescToBatch(ctx, false /* kvTrace */, settings, b, desc.ID, desc); err != nil {
 				return err
@@ -1042,7 +1199,9 @@ func WriteTableDescs(
 		}
 		for i := range tables {
 			if wrote, ok := wroteDBs[tables[i].ParentID]; ok {
-				tables[i].Privileges = wrote.GetPrivileges()
+				if descCoverage == tree.RequestedDescriptors {
+					tables[i].Privileges = wrote.GetPrivileges()
+				}
 			} else {
 				parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, tables[i].ParentID)
 				if err != nil {
@@ -1086,11 +1245,16 @@ func WriteTableDescs(
 func restoreJobDescription(
 	p sql.PlanHookState, restore *tree.Restore, from [][]string, opts map[string]string,
 ) (string, error) {
+	redactedOpts, err := redactEncryptionOptions(opts)
+	if err != nil {
+		return "", err
+	}
 	r := &tree.Restore{
-		AsOf:    restore.AsOf,
-		Options: optsToKVOptions(opts),
-		Targets: restore.Targets,
-		From:    make([]tree.PartitionedBackup, len(restore.From)),
+		AsOf:               restore.AsOf,
+		Options:            optsToKVOptions(redactedOpts),
+		Targets:            restore.Targets,
+		DescriptorCoverage: restore.DescriptorCoverage,
+		From:               make([]tree.PartitionedBackup, len(restore.From)),
 	}
 
 	for i, backup := range from {
@@ -1164,8 +1328,10 @@ func restore(
 	sqlDescs []sqlbase.Descriptor,
 	tableRewrites TableRewriteMap,
 	overrideDB string,
+	newDBName string,
 	job *jobs.Job,
 	resultsCh chan<- tree.Datums,
+	encryption *roachpb.FileEncryptionOptions,
 ) (roachpb.BulkOpSummary, []*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
 	// A note about contexts and spans in this method: the top-level context
 	// `restoreCtx` is used for orchestration logging. All operations that carry
@@ -1185,12 +1351,24 @@ func restore(
 	var oldTableIDs []sqlbase.ID
 	for _, desc := range sqlDescs {
 		if tableDesc := desc.GetTable(); tableDesc != nil {
+			if tableDesc.ParentID == sqlbase.SystemDB.ID {
+				// Only a full cluster restore restores system tables, as regular
+				// tables of restoreTempSystemDB.
+				tableDesc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			}
 			tables = append(tables, tableDesc)
 			oldTableIDs = append(oldTableIDs, tableDesc.ID)
 		}
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
 			if rewrite, ok := tableRewrites[dbDesc.ID]; ok {
+				if dbDesc.ID == sqlbase.SystemDB.ID {
+					dbDesc.Name = restoreTempSystemDB
+					dbDesc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+				}
 				dbDesc.ID = rewrite.TableID
+				if newDBName != "" {
+					dbDesc.Name = newDBName
+				}
 				databases = append(databases, dbDesc)
 			}
 		}
@@ -1204,6 +1382,9 @@ func restore(
 
 	// Assign new IDs and privileges to the tables, and update all references to
 	// use the new IDs.
+	if newDBName != "" {
+		overrideDB = newDBName
+	}
 	if err := RewriteTableDescs(tables, tableRewrites, overrideDB); err != nil {
 		return mu.res, nil, nil, err
 	}
@@ -1329,6 +1510,7 @@ func restore(
 				Files:         readyForImportSpan.files,
 				EndTime:       endTime,
 				Rekeys:        rekeys,
+				Encryption:    encryption,
 			}
 
 			log.VEventf(restoreCtx, 1, "importing %d of %d", idx, len(importSpans))
@@ -1472,18 +1654,28 @@ func doRestorePlan(
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
 ) error {
+	if len(from) == 0 || len(from[0]) == 0 {
+		return errors.New("invalid base backup specified")
+	}
+	// All the backups of a chain are encrypted with the same key, which can be
+	// recovered from the encryption info of any of them.
+	encryption, err := getBackupEncryption(ctx, p.ExecCfg().Settings, opts, from[len(from)-1][0])
+	if err != nil {
+		return err
+	}
+
 	defaultURIs := make([]string, len(from))
 	localityInfo := make([]jobspb.RestoreDetails_BackupLocalityInfo, len(from))
 	for i, uris := range from {
 		// The first URI in the list must contain the main BACKUP manifest.
 		defaultURIs[i] = uris[0]
-		info, err := getBackupLocalityInfo(ctx, uris, p.ExecCfg().Settings)
+		info, err := getBackupLocalityInfo(ctx, uris, p.ExecCfg().Settings, encryption)
 		if err != nil {
 			return err
 		}
 		localityInfo[i] = info
 	}
-	mainBackupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().Settings)
+	mainBackupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().Settings, encryption)
 	if err != nil {
 		return err
 	}
@@ -1519,7 +1711,18 @@ func doRestorePlan(
 		}
 	}
 
-	sqlDescs, restoreDBs, err := selectTargets(ctx, p, mainBackupDescs, restoreStmt.Targets, endTime)
+	if restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+		if mainBackupDescs[len(mainBackupDescs)-1].DescriptorCoverage != tree.AllDescriptors {
+			return errors.Errorf("full cluster RESTORE can only be used on a full cluster BACKUP")
+		}
+		if err := checkClusterEmpty(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	sqlDescs, restoreDBs, err := selectTargets(
+		ctx, p, mainBackupDescs, restoreStmt.Targets, restoreStmt.DescriptorCoverage, endTime,
+	)
 	if err != nil {
 		return err
 	}
@@ -1540,7 +1743,12 @@ func doRestorePlan(
 	if len(filteredTablesByID) == 0 {
 		return errors.Errorf("no tables to restore: %s", tree.ErrString(&restoreStmt.Targets))
 	}
-	tableRewrites, err := allocateTableRewrites(ctx, p, databasesByID, filteredTablesByID, restoreDBs, opts)
+	var tableRewrites TableRewriteMap
+	if restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+		tableRewrites, err = allocateFullClusterTableRewrites(ctx, p, databasesByID, filteredTablesByID, opts)
+	} else {
+		tableRewrites, err = allocateTableRewrites(ctx, p, databasesByID, filteredTablesByID, restoreDBs, opts)
+	}
 	if err != nil {
 		return err
 	}
@@ -1553,7 +1761,11 @@ func doRestorePlan(
 	for _, desc := range filteredTablesByID {
 		tables = append(tables, desc)
 	}
-	if err := RewriteTableDescs(tables, tableRewrites, opts[restoreOptIntoDB]); err != nil {
+	overrideDB := opts[restoreOptIntoDB]
+	if newDBName, ok := opts[restoreOptNewDBName]; ok {
+		overrideDB = newDBName
+	}
+	if err := RewriteTableDescs(tables, tableRewrites, overrideDB); err != nil {
 		return err
 	}
 
@@ -1573,6 +1785,9 @@ func doRestorePlan(
 			BackupLocalityInfo: localityInfo,
 			TableDescs:         tables,
 			OverrideDB:         opts[restoreOptIntoDB],
+			NewDBName:          opts[restoreOptNewDBName],
+			Encryption:         encryption,
+			DescriptorCoverage: restoreStmt.DescriptorCoverage,
 		},
 		Progress: jobspb.RestoreProgress{},
 	})
@@ -1585,7 +1800,7 @@ func doRestorePlan(
 func loadBackupSQLDescs(
 	ctx context.Context, details jobspb.RestoreDetails, settings *cluster.Settings,
 ) ([]BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings)
+	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings, details.Encryption)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1633,13 +1848,120 @@ func (r *restoreResumer) Resume(
 		sqlDescs,
 		details.TableRewrites,
 		details.OverrideDB,
+		details.NewDBName,
 		r.job,
 		resultsCh,
+		details.Encryption,
 	)
 	r.res = res
 	r.databases = databases
 	r.tables = tables
 	r.statsRefresher = p.ExecCfg().StatsRefresher
+	if err != nil {
+		return err
+	}
+
+	if details.DescriptorCoverage == tree.AllDescriptors {
+		// The rows of the system tables are copied out of the restored tables of
+		// restoreTempSystemDB, so the descriptors are published before the job
+		// succeeds rather than in OnSuccess.
+		if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return publishFullClusterDescs(
+				ctx, txn, r.databases, r.tables, r.job.Payload().Username, r.settings,
+			)
+		}); err != nil {
+			return err
+		}
+		if err := restoreSystemTables(ctx, p.ExecCfg().DB, p.ExecCfg().InternalExecutor); err != nil {
+			return err
+		}
+		tempSystemDBID := details.TableRewrites[sqlbase.SystemDB.ID].TableID
+		userTables := r.tables[:0]
+		for _, table := range r.tables {
+			if table.ParentID != tempSystemDBID {
+				userTables = append(userTables, table)
+			}
+		}
+		r.tables = userTables
+	}
+	return nil
+}
+
+// publishFullClusterDescs writes the descriptors restored by a full cluster
+// restore, replacing the defaultUserDBs of the restoring cluster.
+func publishFullClusterDescs(
+	ctx context.Context,
+	txn *client.Txn,
+	databases []*sqlbase.DatabaseDescriptor,
+	tables []*sqlbase.TableDescriptor,
+	user string,
+	settings *cluster.Settings,
+) error {
+	if err := txn.SetSystemConfigTrigger(); err != nil {
+		return err
+	}
+	b := txn.NewBatch()
+	for _, name := range defaultUserDBs {
+		nameKey := sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, name)
+		existing, err := txn.Get(ctx, nameKey)
+		if err != nil {
+			return err
+		}
+		if existing.Value == nil {
+			continue
+		}
+		id, err := existing.Value.GetInt()
+		if err != nil {
+			return err
+		}
+		b.Del(nameKey, sqlbase.MakeDescMetadataKey(sqlbase.ID(id)))
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		return err
+	}
+	return WriteTableDescs(ctx, txn, databases, tables, tree.AllDescriptors, user, settings, nil)
+}
+
+// restoreSystemTables replaces the rows of the system tables with those of the
+// backup, restored into restoreTempSystemDB, and then drops it. The cluster
+// version isn't restored, since it must match the running binaries, and the
+// jobs of the restoring cluster are kept. Restored jobs which hadn't finished
+// are paused, to be resumed once the restored cluster is ready for them.
+func restoreSystemTables(
+	ctx context.Context, db *client.DB, ie *sql.InternalExecutor,
+) error {
+	for _, table := range fullClusterSystemTables {
+		deleteQuery := fmt.Sprintf(`DELETE FROM system.%s`, table)
+		insertQuery := fmt.Sprintf(
+			`INSERT INTO system.%s SELECT * FROM %s.%s`, table, restoreTempSystemDB, table)
+		switch table {
+		case sqlbase.SettingsTable.Name:
+			deleteQuery += ` WHERE name != 'version'`
+			insertQuery += ` WHERE name != 'version'`
+		case sqlbase.JobsTable.Name:
+			deleteQuery = ``
+			insertQuery = fmt.Sprintf(
+				`INSERT INTO system.jobs (id, status, created, payload, progress) `+
+					`SELECT id, IF(status IN ('%s', '%s', '%s'), status, '%s'), created, payload, progress `+
+					`FROM %s.jobs ON CONFLICT DO NOTHING`,
+				jobs.StatusSucceeded, jobs.StatusFailed, jobs.StatusCanceled, jobs.StatusPaused,
+				restoreTempSystemDB)
+		}
+		opName := "restore-system-" + table
+		if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			if deleteQuery != `` {
+				if _, err := ie.Exec(ctx, opName, txn, deleteQuery); err != nil {
+					return err
+				}
+			}
+			_, err := ie.Exec(ctx, opName, txn, insertQuery)
+			return err
+		}); err != nil {
+			return errors.Wrapf(err, "restoring system.%s", table)
+		}
+	}
+	dropQuery := fmt.Sprintf(`DROP DATABASE %s CASCADE`, restoreTempSystemDB)
+	_, err := ie.Exec(ctx, "restore-drop-temp-system-db", nil /* txn */, dropQuery)
 	return err
 }
 
@@ -1666,13 +1988,19 @@ func (r *restoreResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn) er
 
 // OnSuccess is part of the jobs.Resumer interface.
 func (r *restoreResumer) OnSuccess(ctx context.Context, txn *client.Txn) error {
-	log.Event(ctx, "making tables live")
-
-	// Write the new TableDescriptors and flip the namespace entries over to
-	// them. After this call, any queries on a table will be served by the newly
-	// restored data.
-	if err := WriteTableDescs(ctx, txn, r.databases, r.tables, r.job.Payload().Username, r.settings, nil); err != nil {
-		return errors.Wrapf(err, "restoring %d TableDescriptors", len(r.tables))
+	// A full cluster restore already published its descriptors in Resume.
+	if r.job.Details().(jobspb.RestoreDetails).DescriptorCoverage == tree.RequestedDescriptors {
+		log.Event(ctx, "making tables live")
+
+		// Write the new TableDescriptors and flip the namespace entries over to
+		// them. After this call, any queries on a table will be served by the
+		// newly restored data.
+		if err := WriteTableDescs(
+			ctx, txn, r.databases, r.tables, tree.RequestedDescriptors, r.job.Payload().Username,
+			r.settings, nil,
+		); err != nil {
+			return errors.Wrapf(err, "restoring %d TableDescriptors", len(r.tables))
+		}
 	}
 
 	// Initiate a run of CREATE STATISTICS. We don't know the actual number of
@@ -1710,6 +2038,7 @@ func (r *restoreResumer) OnTerminal(
 var _ jobs.Resumer = &restoreResumer{}
 
 func init() {
+	build.RegisterFeature("restore")
 	sql.AddPlanHook(restorePlanHook)
 	jobs.RegisterConstructor(
 		jobspb.TypeRestore,
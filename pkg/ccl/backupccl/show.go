@@ -9,20 +9,35 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 )
 
+const showBackupOptCheckFiles = "check_files"
+
+var showBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptEncPassphrase:  sql.KVStringOptRequireValue,
+	backupOptEncKMS:         sql.KVStringOptRequireValue,
+	showBackupOptCheckFiles: sql.KVStringOptRequireNoValue,
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
@@ -46,6 +61,10 @@ func showBackupPlanHook(
 	if err != nil {
 		return nil, nil, nil, false, err
 	}
+	optsFn, err := p.TypeAsStringOpts(backup.Options, showBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
 
 	var shower backupShower
 	switch backup.Details {
@@ -66,10 +85,23 @@ func showBackupPlanHook(
 		if err != nil {
 			return err
 		}
-		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings)
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		encryption, err := getBackupEncryption(ctx, p.ExecCfg().Settings, opts, str)
+		if err != nil {
+			return err
+		}
+		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings, encryption)
 		if err != nil {
 			return err
 		}
+		if _, ok := opts[showBackupOptCheckFiles]; ok {
+			if err := checkBackupFiles(ctx, str, p.ExecCfg().Settings, desc, encryption); err != nil {
+				return pgerror.Wrapf(err, pgcode.DataCorrupted, "backup %s failed validation", str)
+			}
+		}
 
 		for _, row := range shower.fn(desc) {
 			select {
@@ -84,6 +116,98 @@ func showBackupPlanHook(
 	return fn, shower.header, nil, false, nil
 }
 
+// checkBackupFiles verifies that the descriptor of a backup is consistent with
+// the files it lists, and that each of them is present with the contents
+// checksummed when it was written. The files of the non-default localities of
+// partitioned backups cannot be checked, since their location is not known.
+func checkBackupFiles(
+	ctx context.Context,
+	uri string,
+	settings *cluster.Settings,
+	desc BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
+) error {
+	var counts roachpb.BulkOpSummary
+	paths := make(map[string]struct{}, len(desc.Files))
+	for _, file := range desc.Files {
+		if file.Path == "" {
+			return errors.Errorf("file for span %s has no path", file.Span)
+		}
+		if _, ok := paths[file.Path]; ok {
+			return errors.Errorf("file %s is listed more than once", file.Path)
+		}
+		paths[file.Path] = struct{}{}
+		if !spansContain(desc.Spans, file.Span) {
+			return errors.Errorf("file %s covers span %s outside of the backed up spans", file.Path, file.Span)
+		}
+		if file.LocalityKV != "" {
+			return errors.Errorf(
+				"file %s is stored in locality %s; cannot check the files of partitioned backups",
+				file.Path, file.LocalityKV)
+		}
+		counts.Add(file.EntryCounts)
+	}
+	if counts != desc.EntryCounts {
+		return errors.Errorf("the files contain %+v but the descriptor expects %+v", counts, desc.EntryCounts)
+	}
+
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return err
+	}
+	defer exportStore.Close()
+	for _, file := range desc.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := checkBackupFile(ctx, exportStore, file, encryption); err != nil {
+			return errors.Wrapf(err, "checking file %s", file.Path)
+		}
+	}
+	return nil
+}
+
+func checkBackupFile(
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	file BackupDescriptor_File,
+	encryption *roachpb.FileEncryptionOptions,
+) error {
+	r, err := exportStore.ReadFile(ctx, file.Path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if contents, err = maybeDecrypt(contents, encryption); err != nil {
+		return err
+	}
+	if len(file.Sha512) == 0 {
+		return nil
+	}
+	checksum, err := storageccl.SHA512ChecksumData(contents)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, file.Sha512) {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// spansContain returns whether one of spans contains span.
+func spansContain(spans []roachpb.Span, span roachpb.Span) bool {
+	for _, s := range spans {
+		if s.Contains(span) {
+			return true
+		}
+	}
+	return false
+}
+
 type backupShower struct {
 	header sqlbase.ResultColumns
 	fn     func(BackupDescriptor) []tree.Datums
@@ -11,6 +11,9 @@ package backupccl_test
 import (
 	"database/sql/driver"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -210,3 +213,46 @@ func TestShowBackup(t *testing.T) {
 func eqWhitespace(a, b string) bool {
 	return strings.Replace(a, "\t", "", -1) == strings.Replace(b, "\t", "", -1)
 }
+
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 11
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	full := localFoo + "/checkfiles"
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, full)
+	sqlDB.Exec(t, `SHOW BACKUP $1 WITH check_files`, full)
+
+	paths := sqlDB.QueryStr(t, `SELECT path FROM [SHOW BACKUP FILES $1]`, full)
+	if len(paths) == 0 {
+		t.Fatal("expected the backup to contain files")
+	}
+	sstPath := filepath.Join(dir, "foo", "checkfiles", paths[0][0])
+	contents, err := ioutil.ReadFile(sstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt a file: SHOW BACKUP still works without checking the files.
+	corrupted := append([]byte(nil), contents...)
+	corrupted[len(corrupted)/2] ^= 1
+	if err := ioutil.WriteFile(sstPath, corrupted, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Exec(t, `SHOW BACKUP $1`, full)
+	sqlDB.ExpectErr(t, "failed validation: checking file .*: checksum mismatch",
+		`SHOW BACKUP $1 WITH check_files`, full)
+
+	// Remove a file.
+	if err := os.Remove(sstPath); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.ExpectErr(t, "failed validation: checking file", `SHOW BACKUP $1 WITH check_files`, full)
+
+	if err := ioutil.WriteFile(sstPath, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Exec(t, `SHOW BACKUP FILES $1 WITH check_files`, full)
+}
@@ -53,15 +53,18 @@ type emitEntry struct {
 	bufferGetTimestamp time.Time
 }
 
-// kvsToRows gets changed kvs from a closure and converts them into sql rows. It
+// kvsToRows gets changed kvs from a closure and converts them into sql rows,
+// which are projected and filtered by the given projection if it's not nil. It
 // returns a closure that may be repeatedly called to advance the changefeed.
 // The returned closure is not threadsafe.
 func kvsToRows(
 	leaseMgr *sql.LeaseManager,
 	details jobspb.ChangefeedDetails,
+	projection *changefeedProjection,
 	inputFn func(context.Context) (bufferEntry, error),
 ) func(context.Context) ([]emitEntry, error) {
 	rfCache := newRowFetcherCache(leaseMgr)
+	_, splitColumnFamilies := details.Opts[optSplitColumnFamilies]
 
 	var kvs row.SpanKVFetcher
 	appendEmitEntryForKV := func(
@@ -83,11 +86,27 @@ func kvsToRows(
 			return nil, nil
 		}
 
-		rf, err := rfCache.RowFetcherForTableDesc(desc)
-		if err != nil {
-			return nil, err
+		// Without split_column_families, the table has exactly one column family
+		// (which is checked whenever its descriptor changes), so the kv is the
+		// entire row. With it, the kv is turned into a row of only the columns
+		// in its family.
+		var rf *row.Fetcher
+		var family *sqlbase.ColumnFamilyDescriptor
+		if splitColumnFamilies {
+			familyID, err := familyIDForKey(kv.Key)
+			if err != nil {
+				return nil, err
+			}
+			rf, family, err = rfCache.RowFetcherForColumnFamily(desc, familyID)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			rf, err = rfCache.RowFetcherForTableDesc(desc)
+			if err != nil {
+				return nil, err
+			}
 		}
-		// TODO(dan): Handle tables with multiple column families.
 		kvs.KVs = append(kvs.KVs, kv)
 		if err := rf.StartScanFrom(ctx, &kvs); err != nil {
 			return nil, err
@@ -106,6 +125,15 @@ func kvsToRows(
 			r.row.datums = append(sqlbase.EncDatumRow(nil), r.row.datums...)
 			r.row.deleted = rf.RowIsDeleted()
 			r.row.updated = schemaTimestamp
+			r.row.family = family
+			if projection != nil {
+				var ok bool
+				if r.row, ok, err = projection.project(r.row); err != nil {
+					return nil, err
+				} else if !ok {
+					continue
+				}
+			}
 			output = append(output, r)
 		}
 		return output, nil
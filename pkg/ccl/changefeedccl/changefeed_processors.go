@@ -123,6 +123,17 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 
 	nodeID := ca.flowCtx.EvalCtx.NodeID
 	var err error
+	var projection *changefeedProjection
+	if ca.spec.Feed.Select != `` {
+		if projection, err = parseChangefeedProjection(
+			ca.spec.Feed.Select, ca.flowCtx.NewEvalCtx(),
+		); err != nil {
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return ctx
+		}
+	}
+
 	if ca.sink, err = getSink(
 		ca.spec.Feed.SinkURI, nodeID, ca.spec.Feed.Opts, ca.spec.Feed.Targets, ca.flowCtx.Cfg.Settings,
 	); err != nil {
@@ -178,7 +189,7 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		ca.flowCtx.Cfg.Settings, ca.flowCtx.Cfg.DB, ca.flowCtx.Cfg.DB.Clock(), ca.flowCtx.Cfg.Gossip,
 		spans, ca.spec.Feed, initialHighWater, buf, leaseMgr, metrics, ca.pollerMemMon,
 	)
-	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, buf.Get)
+	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, projection, buf.Get)
 
 	ca.tickFn = emitEntries(
 		ca.flowCtx.Cfg.Settings, ca.spec.Feed, spans, ca.encoder, ca.sink, rowsFn, knobs, metrics)
@@ -328,6 +339,9 @@ type changeFrontier struct {
 	lastEmitResolved time.Time
 	// lastSlowSpanLog is the last time a slow span from `sf` was logged.
 	lastSlowSpanLog time.Time
+	// initialScanOnly is set for changefeeds which finish once every tracked
+	// span has been scanned at the statement time.
+	initialScanOnly bool
 
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
@@ -399,6 +413,8 @@ func newChangeFrontierProcessor(
 		cf.freqEmitResolved = emitNoResolved
 	}
 
+	cf.initialScanOnly = changefeedInitialScan(spec.Feed.Opts) == optInitialScanOnly
+
 	var err error
 	if cf.encoder, err = getEncoder(spec.Feed.Opts); err != nil {
 		return nil, err
@@ -509,6 +525,12 @@ func (cf *changeFrontier) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetada
 			return cf.resolvedBuf.Pop(), nil
 		}
 
+		if cf.initialScanDone() {
+			// Everything the changefeed will ever emit has been returned.
+			cf.MoveToDraining(nil /* err */)
+			break
+		}
+
 		row, meta := cf.input.Next()
 		if meta != nil {
 			if meta.Err != nil {
@@ -619,6 +641,12 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 	return nil
 }
 
+// initialScanDone returns whether the changefeed only scans its tables and
+// every tracked span has been resolved at the scan timestamp.
+func (cf *changeFrontier) initialScanDone() bool {
+	return cf.initialScanOnly && !cf.sf.Frontier().Less(cf.spec.Feed.StatementTime)
+}
+
 // ConsumerDone is part of the RowSource interface.
 func (cf *changeFrontier) ConsumerDone() {
 	cf.MoveToDraining(nil /* err */)
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
@@ -35,6 +36,7 @@ import (
 )
 
 func init() {
+	build.RegisterFeature("changefeeds")
 	sql.AddPlanHook(changefeedPlanHook)
 	jobs.RegisterConstructor(
 		jobspb.TypeChangefeed,
@@ -46,32 +48,48 @@ func init() {
 
 type envelopeType string
 type formatType string
+type initialScanType string
 
 const (
 	optConfluentSchemaRegistry = `confluent_schema_registry`
 	optCursor                  = `cursor`
 	optEnvelope                = `envelope`
 	optFormat                  = `format`
+	optInitialScan             = `initial_scan`
 	optKeyInValue              = `key_in_value`
 	optResolvedTimestamps      = `resolved`
+	optSplitColumnFamilies     = `split_column_families`
 	optUpdatedTimestamps       = `updated`
 
+	optEnvelopeBare          envelopeType = `bare`
 	optEnvelopeKeyOnly       envelopeType = `key_only`
 	optEnvelopeRow           envelopeType = `row`
 	optEnvelopeDeprecatedRow envelopeType = `deprecated_row`
 	optEnvelopeWrapped       envelopeType = `wrapped`
 
-	optFormatJSON formatType = `json`
-	optFormatAvro formatType = `experimental_avro`
+	optFormatJSON             formatType = `json`
+	optFormatAvro             formatType = `avro`
+	optFormatExperimentalAvro formatType = `experimental_avro`
 
+	optInitialScanYes  initialScanType = `yes`
+	optInitialScanNo   initialScanType = `no`
+	optInitialScanOnly initialScanType = `only`
+
+	sinkParamBatchSize        = `batch_size`
 	sinkParamCACert           = `ca_cert`
 	sinkParamFileSize         = `file_size`
+	sinkParamHMACKey          = `hmac_key`
+	sinkParamMaxRetries       = `max_retries`
+	sinkParamRetryBackoff     = `retry_backoff`
 	sinkParamSchemaTopic      = `schema_topic`
 	sinkParamTLSEnabled       = `tls_enabled`
 	sinkParamTopicPrefix      = `topic_prefix`
 	sinkSchemeBuffer          = ``
 	sinkSchemeExperimentalSQL = `experimental-sql`
+	sinkSchemeGCPubsub        = `gcpubsub`
 	sinkSchemeKafka           = `kafka`
+	sinkSchemeWebhookHTTP     = `webhook-http`
+	sinkSchemeWebhookHTTPS    = `webhook-https`
 	sinkParamSASLEnabled      = `sasl_enabled`
 	sinkParamSASLHandshake    = `sasl_handshake`
 	sinkParamSASLUser         = `sasl_user`
@@ -83,8 +101,10 @@ var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	optCursor:                  sql.KVStringOptRequireValue,
 	optEnvelope:                sql.KVStringOptRequireValue,
 	optFormat:                  sql.KVStringOptRequireValue,
+	optInitialScan:             sql.KVStringOptAny,
 	optKeyInValue:              sql.KVStringOptRequireNoValue,
 	optResolvedTimestamps:      sql.KVStringOptAny,
+	optSplitColumnFamilies:     sql.KVStringOptRequireNoValue,
 	optUpdatedTimestamps:       sql.KVStringOptRequireNoValue,
 }
 
@@ -202,9 +222,18 @@ func changefeedPlanHook(
 				targets[tableDesc.ID] = jobspb.ChangefeedTarget{
 					StatementTimeName: tableDesc.Name,
 				}
-				if err := validateChangefeedTable(targets, tableDesc); err != nil {
+				if err := validateChangefeedTable(targets, tableDesc, opts); err != nil {
 					return err
 				}
+				if sel := changefeedStmt.Select; sel != nil {
+					// Resolve the SELECT now to return any errors in it to the
+					// user. Each change aggregator resolves it again against every
+					// version of the table.
+					projection := makeChangefeedProjection(sel, &p.ExtendedEvalContext().EvalContext)
+					if _, err := projection.forTable(tableDesc); err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -214,6 +243,9 @@ func changefeedPlanHook(
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
 		}
+		if changefeedStmt.Select != nil {
+			details.Select = tree.AsStringWithFlags(changefeedStmt.Select, tree.FmtParsable)
+		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{HighWater: &initialHighWater},
 			Details: &jobspb.Progress_Changefeed{
@@ -344,6 +376,7 @@ func changefeedJobDescription(
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
 		SinkURI: tree.NewDString(cleanedSinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
@@ -375,11 +408,20 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		}
 	}
 
+	switch initialScanType(details.Opts[optInitialScan]) {
+	case ``, optInitialScanYes, optInitialScanNo, optInitialScanOnly:
+	default:
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`unknown %s: %s`, optInitialScan, details.Opts[optInitialScan])
+	}
+
 	switch envelopeType(details.Opts[optEnvelope]) {
 	case optEnvelopeRow, optEnvelopeDeprecatedRow:
 		details.Opts[optEnvelope] = string(optEnvelopeRow)
 	case optEnvelopeKeyOnly:
 		details.Opts[optEnvelope] = string(optEnvelopeKeyOnly)
+	case optEnvelopeBare:
+		details.Opts[optEnvelope] = string(optEnvelopeBare)
 	case ``, optEnvelopeWrapped:
 		details.Opts[optEnvelope] = string(optEnvelopeWrapped)
 	default:
@@ -387,11 +429,16 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 			`unknown %s: %s`, optEnvelope, details.Opts[optEnvelope])
 	}
 
+	if _, ok := details.Opts[optSplitColumnFamilies]; ok && details.Select != `` {
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`%s is not supported with a CHANGEFEED over a SELECT`, optSplitColumnFamilies)
+	}
+
 	switch formatType(details.Opts[optFormat]) {
 	case ``, optFormatJSON:
 		details.Opts[optFormat] = string(optFormatJSON)
-	case optFormatAvro:
-		// No-op.
+	case optFormatAvro, optFormatExperimentalAvro:
+		details.Opts[optFormat] = string(optFormatAvro)
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
 			`unknown %s: %s`, optFormat, details.Opts[optFormat])
@@ -400,8 +447,25 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	return details, nil
 }
 
+// changefeedInitialScan returns whether a changefeed scans its tables when it
+// starts. A changefeed with a cursor only emits the changes after the cursor,
+// unless a scan is explicitly requested.
+func changefeedInitialScan(opts map[string]string) initialScanType {
+	s, ok := opts[optInitialScan]
+	if !ok {
+		if _, ok := opts[optCursor]; ok {
+			return optInitialScanNo
+		}
+		return optInitialScanYes
+	}
+	if s == `` {
+		return optInitialScanYes
+	}
+	return initialScanType(s)
+}
+
 func validateChangefeedTable(
-	targets jobspb.ChangefeedTargets, tableDesc *sqlbase.TableDescriptor,
+	targets jobspb.ChangefeedTargets, tableDesc *sqlbase.TableDescriptor, opts map[string]string,
 ) error {
 	t, ok := targets[tableDesc.ID]
 	if !ok {
@@ -425,10 +489,10 @@ func validateChangefeedTable(
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.Name)
 	}
-	if len(tableDesc.Families) != 1 {
+	if _, ok := opts[optSplitColumnFamilies]; !ok && len(tableDesc.Families) != 1 {
 		return errors.Errorf(
-			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
-			tableDesc.Name, len(tableDesc.Families))
+			`CHANGEFEEDs without %s are supported on tables with exactly 1 column family: %s has %d`,
+			optSplitColumnFamilies, tableDesc.Name, len(tableDesc.Families))
 	}
 
 	if tableDesc.State == sqlbase.TableDescriptor_DROP {
@@ -121,6 +121,16 @@ func TestChangefeedEnvelope(t *testing.T) {
 			defer closeFeed(t, foo)
 			assertPayloads(t, foo, []string{`foo: [1]->{"after": {"a": 1, "b": "a"}, "key": [1]}`})
 		})
+		t.Run(`envelope=bare`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH envelope='bare'`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{`foo: [1]->{"a": 1, "b": "a"}`})
+		})
+		t.Run(`envelope=bare,key_in_value`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH key_in_value, envelope='bare'`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{`foo: [1]->{"__crdb__": {"key": [1]}, "a": 1, "b": "a"}`})
+		})
 	}
 
 	t.Run(`sinkless`, sinklessTest(testFn))
@@ -208,6 +218,54 @@ func TestChangefeedCursor(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedInitialScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'before')`)
+		var tsLogical string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&tsLogical)
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'after')`)
+
+		// A scan requested along with a cursor happens at the cursor.
+		withCursor := feed(t, f, `CREATE CHANGEFEED FOR foo WITH cursor=$1, initial_scan`, tsLogical)
+		defer closeFeed(t, withCursor)
+		assertPayloads(t, withCursor, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "before"}}`,
+			`foo: [1]->{"after": {"a": 1, "b": "after"}}`,
+		})
+
+		// A changefeed which only scans finishes after the scan is resolved.
+		scanOnly := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan='only', resolved`)
+		defer closeFeed(t, scanOnly)
+		assertPayloads(t, scanOnly, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "after"}}`,
+		})
+		expectResolvedTimestamp(t, scanOnly)
+
+		// The sinkless tests currently start the changefeed lazily, so only
+		// verify skipping the scan and the job status for enterprise.
+		if e, ok := scanOnly.(*cdctest.TableFeed); ok {
+			sqlDB.CheckQueryResultsRetry(t,
+				fmt.Sprintf(`SELECT status FROM [SHOW JOBS] WHERE job_id = %d`, e.JobID),
+				[][]string{{`succeeded`}},
+			)
+
+			noScan := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan='no'`)
+			defer closeFeed(t, noScan)
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'new')`)
+			assertPayloads(t, noScan, []string{
+				`foo: [2]->{"after": {"a": 2, "b": "new"}}`,
+			})
+		}
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedTimestamps(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -779,6 +837,24 @@ func TestChangefeedColumnFamily(t *testing.T) {
 		if _, err := bar.Next(); !testutils.IsError(err, `exactly 1 column family`) {
 			t.Errorf(`expected "exactly 1 column family" error got: %+v`, err)
 		}
+
+		// With split_column_families, each column family is emitted separately.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'dog')`)
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH split_column_families`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0}, "family": "fam_0_a"}`,
+			`foo: [0]->{"after": {"b": "dog"}, "family": "fam_1_b"}`,
+		})
+		sqlDB.Exec(t, `UPDATE foo SET b = 'cat' WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"b": "cat"}, "family": "fam_1_b"}`,
+		})
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": null, "family": "fam_0_a"}`,
+			`foo: [0]->{"after": null, "family": "fam_1_b"}`,
+		})
 	}
 
 	t.Run(`sinkless`, sinklessTest(testFn))
@@ -813,6 +889,38 @@ func TestChangefeedComputedColumn(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedProjection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, secret STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'skip', 's0'), (1, 'keep', 's1')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED AS SELECT b FROM foo WHERE b != 'skip'`)
+		defer closeFeed(t, foo)
+
+		// The primary key is emitted even though it isn't selected.
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "keep"}}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'skip', 's2'), (3, 'keep', 's3')`)
+		assertPayloads(t, foo, []string{
+			`foo: [3]->{"after": {"a": 3, "b": "keep"}}`,
+		})
+
+		// Deletions are never filtered out.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedUpdatePrimaryKey(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1438,23 +1546,78 @@ func TestChangefeedErrors(t *testing.T) {
 
 	// The avro format doesn't support key_in_value yet.
 	sqlDB.ExpectErr(
-		t, `key_in_value is not supported with format=experimental_avro`,
+		t, `key_in_value is not supported with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, format='avro'`,
+		`kafka://nope`,
+	)
+	// The old name of the avro format is still accepted.
+	sqlDB.ExpectErr(
+		t, `key_in_value is not supported with format=avro`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, format='experimental_avro'`,
 		`kafka://nope`,
 	)
 
 	// The cloudStorageSink is particular about the options it will work with.
 	sqlDB.ExpectErr(
-		t, `this sink is incompatible with format=experimental_avro`,
-		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', confluent_schema_registry=$2`,
+		t, `this sink is incompatible with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='avro', confluent_schema_registry=$2`,
 		`experimental-nodelocal:///bar`, `schemareg-nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='avro', confluent_schema_registry=$2`,
+		`webhook-https://nope/`, `schemareg-nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `param batch_size must be a positive integer`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://nope/?batch_size=0`,
+	)
+	sqlDB.ExpectErr(
+		t, `param hmac_key must be base 64 encoded`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://nope/?hmac_key=%21`,
+	)
+	sqlDB.ExpectErr(
+		t, `the Google Cloud project must be specified`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `gcpubsub:///`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown sink query parameter: nope`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `gcpubsub://project?nope=1`,
+	)
 	sqlDB.ExpectErr(
 		t, `this sink is incompatible with envelope=key_only`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
 		`experimental-nodelocal:///bar`,
 	)
 
+	// The SELECT of a changefeed can only project and filter columns.
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT nope FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED cannot rename columns`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a AS b FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED can only select columns, not a \+ 1`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a + 1 FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `split_column_families is not supported with a CHANGEFEED over a SELECT`,
+		`CREATE CHANGEFEED INTO $1 WITH split_column_families AS SELECT a FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `split_column_families is not supported with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH split_column_families, format='avro', confluent_schema_registry=$2`,
+		`kafka://nope`, `https://schemareg-nope`,
+	)
+
+	sqlDB.ExpectErr(
+		t, `unknown initial_scan: sometimes`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH initial_scan='sometimes'`, `kafka://nope`,
+	)
+
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
 		t, `key_in_value is only usable with envelope=wrapped`,
@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -31,6 +32,10 @@ const (
 	confluentSubjectSuffixKey    = `-key`
 	confluentSubjectSuffixValue  = `-value`
 	confluentAvroWireFormatMagic = byte(0)
+
+	// confluentSchemaRegistryTimeout bounds each request to the schema registry
+	// so that an unresponsive registry can't wedge a changefeed.
+	confluentSchemaRegistryTimeout = 30 * time.Second
 )
 
 // encodeRow holds all the pieces necessary to encode a row change into a key or
@@ -47,6 +52,11 @@ type encodeRow struct {
 	// tableDesc is a TableDescriptor for the table containing `datums`.
 	// It's valid for interpreting the row at `updated`.
 	tableDesc *sqlbase.TableDescriptor
+	// family, if non-nil, is the column family that `datums` was decoded from
+	// when the changefeed emits one message per column family. In this case,
+	// only the primary key columns and the columns in the family are set in
+	// `datums`.
+	family *sqlbase.ColumnFamilyDescriptor
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -72,7 +82,9 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 	switch formatType(opts[optFormat]) {
 	case ``, optFormatJSON:
 		return makeJSONEncoder(opts)
-	case optFormatAvro:
+	case optFormatAvro, optFormatExperimentalAvro:
+		// Jobs created before the avro format graduated from experimental have
+		// the old name persisted in their details.
 		return newConfluentAvroEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, optFormat, opts[optFormat])
@@ -83,8 +95,12 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 // columns in a JSON array. Values are a JSON object mapping every column name
 // to its value. Updated timestamps in rows and resolved timestamp payloads are
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
+//
+// The bare envelope is like the row envelope, except that a deleted row is
+// emitted as a value with only the `__crdb__` sub-object, which also holds the
+// key when it's requested in the value.
 type jsonEncoder struct {
-	updatedField, wrapped, keyOnly, keyInValue bool
+	updatedField, wrapped, bare, keyOnly, keyInValue bool
 
 	alloc sqlbase.DatumAlloc
 	buf   bytes.Buffer
@@ -96,12 +112,13 @@ func makeJSONEncoder(opts map[string]string) (*jsonEncoder, error) {
 	e := &jsonEncoder{
 		keyOnly: envelopeType(opts[optEnvelope]) == optEnvelopeKeyOnly,
 		wrapped: envelopeType(opts[optEnvelope]) == optEnvelopeWrapped,
+		bare:    envelopeType(opts[optEnvelope]) == optEnvelopeBare,
 	}
 	_, e.updatedField = opts[optUpdatedTimestamps]
 	_, e.keyInValue = opts[optKeyInValue]
-	if e.keyInValue && !e.wrapped {
-		return nil, errors.Errorf(`%s is only usable with %s=%s`,
-			optKeyInValue, optEnvelope, optEnvelopeWrapped)
+	if e.keyInValue && !e.wrapped && !e.bare {
+		return nil, errors.Errorf(`%s is only usable with %s=%s or %s=%s`,
+			optKeyInValue, optEnvelope, optEnvelopeWrapped, optEnvelope, optEnvelopeBare)
 	}
 	return e, nil
 }
@@ -144,7 +161,7 @@ func (e *jsonEncoder) encodeKeyRaw(row encodeRow) ([]interface{}, error) {
 
 // EncodeValue implements the Encoder interface.
 func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
-	if e.keyOnly || (!e.wrapped && row.deleted) {
+	if e.keyOnly || (!e.wrapped && !e.bare && row.deleted) {
 		return nil, nil
 	}
 
@@ -154,6 +171,9 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		after = make(map[string]interface{}, len(columns))
 		for i := range columns {
 			col := &columns[i]
+			if row.family != nil && !familyHasColumn(row.family, col.ID) {
+				continue
+			}
 			datum := row.datums[i]
 			if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
 				return nil, err
@@ -166,34 +186,42 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		}
 	}
 
-	var jsonEntries map[string]interface{}
+	var jsonEntries, meta map[string]interface{}
 	if e.wrapped {
 		if after != nil {
 			jsonEntries = map[string]interface{}{`after`: after}
 		} else {
 			jsonEntries = map[string]interface{}{`after`: nil}
 		}
-		if e.keyInValue {
-			keyEntries, err := e.encodeKeyRaw(row)
-			if err != nil {
-				return nil, err
-			}
-			jsonEntries[`key`] = keyEntries
-		}
+		meta = jsonEntries
 	} else {
 		jsonEntries = after
+		if jsonEntries == nil {
+			// Only the bare envelope gets here with a deleted row.
+			jsonEntries = make(map[string]interface{}, 1)
+		}
+		meta = make(map[string]interface{}, 1)
 	}
 
-	if e.updatedField {
-		var meta map[string]interface{}
-		if e.wrapped {
-			meta = jsonEntries
-		} else {
-			meta = make(map[string]interface{}, 1)
-			jsonEntries[jsonMetaSentinel] = meta
+	if e.keyInValue {
+		keyEntries, err := e.encodeKeyRaw(row)
+		if err != nil {
+			return nil, err
 		}
+		meta[`key`] = keyEntries
+	}
+	if e.bare && row.deleted {
+		meta[`deleted`] = true
+	}
+	if row.family != nil {
+		meta[`family`] = row.family.Name
+	}
+	if e.updatedField {
 		meta[`updated`] = row.updated.AsOfSystemTime()
 	}
+	if !e.wrapped && len(meta) > 0 {
+		jsonEntries[jsonMetaSentinel] = meta
+	}
 
 	j, err := json.MakeJSON(jsonEntries)
 	if err != nil {
@@ -204,6 +232,15 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 	return e.buf.Bytes(), nil
 }
 
+func familyHasColumn(family *sqlbase.ColumnFamilyDescriptor, colID sqlbase.ColumnID) bool {
+	for _, id := range family.ColumnIDs {
+		if id == colID {
+			return true
+		}
+	}
+	return false
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp) ([]byte, error) {
 	meta := map[string]interface{}{
@@ -225,6 +262,7 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp)
 // columns in a record.
 type confluentAvroEncoder struct {
 	registryURL           string
+	registryClient        *http.Client
 	updatedField, keyOnly bool
 
 	keyCache      map[tableIDAndVersion]confluentRegisteredKeySchema
@@ -251,7 +289,10 @@ type confluentRegisteredEnvelopeSchema struct {
 var _ Encoder = &confluentAvroEncoder{}
 
 func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, error) {
-	e := &confluentAvroEncoder{registryURL: opts[optConfluentSchemaRegistry]}
+	e := &confluentAvroEncoder{
+		registryURL:    opts[optConfluentSchemaRegistry],
+		registryClient: &http.Client{Timeout: confluentSchemaRegistryTimeout},
+	}
 
 	switch opts[optEnvelope] {
 	case string(optEnvelopeKeyOnly):
@@ -267,6 +308,10 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 		return nil, errors.Errorf(`%s is not supported with %s=%s`,
 			optKeyInValue, optFormat, optFormatAvro)
 	}
+	if _, ok := opts[optSplitColumnFamilies]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optSplitColumnFamilies, optFormat, optFormatAvro)
+	}
 
 	if len(e.registryURL) == 0 {
 		return nil, errors.Errorf(`WITH option %s is required for %s=%s`,
@@ -422,13 +467,26 @@ func (e *confluentAvroEncoder) register(schema *avroRecord, subject string) (int
 		return 0, err
 	}
 
-	resp, err := http.Post(url.String(), confluentSchemaContentType, &buf)
+	resp, err := e.registryClient.Post(url.String(), confluentSchemaContentType, &buf)
 	if err != nil {
-		return 0, err
+		// The registry may just be temporarily unreachable.
+		return 0, MarkRetryableError(err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := ioutil.ReadAll(resp.Body)
+		switch {
+		case resp.StatusCode == http.StatusConflict:
+			// The registry enforces the compatibility level configured for the
+			// subject, so a column change that produces an incompatible schema
+			// is rejected here. Retrying won't help.
+			return 0, errors.Errorf(
+				`schema for %s is incompatible with a schema previously registered to %s: %s`,
+				subject, e.registryURL, body)
+		case resp.StatusCode >= 500:
+			return 0, MarkRetryableError(errors.Errorf(
+				`registering schema to %s %s: %s`, url.String(), resp.Status, body))
+		}
 		return 0, errors.Errorf(`registering schema to %s %s: %s`, url.String(), resp.Status, body)
 	}
 	var res confluentSchemaVersionResponse
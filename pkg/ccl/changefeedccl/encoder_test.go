@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach-go/crdb"
@@ -49,6 +50,7 @@ func TestEncoders(t *testing.T) {
 	for _, f := range []string{string(optFormatJSON), string(optFormatAvro)} {
 		for _, e := range []string{
 			string(optEnvelopeKeyOnly), string(optEnvelopeRow), string(optEnvelopeWrapped),
+			string(optEnvelopeBare),
 		} {
 			opts = append(opts,
 				map[string]string{optFormat: f, optEnvelope: e},
@@ -94,29 +96,45 @@ func TestEncoders(t *testing.T) {
 			delete:   `[1]->{"after": null, "updated": "1.0000000002"}`,
 			resolved: `{"resolved":"1.0000000002"}`,
 		},
-		`format=experimental_avro,envelope=key_only`: {
+		`format=json,envelope=bare`: {
+			insert:   `[1]->{"a": 1, "b": "bar"}`,
+			delete:   `[1]->{"__crdb__": {"deleted": true}}`,
+			resolved: `{"__crdb__":{"resolved":"1.0000000002"}}`,
+		},
+		`format=json,envelope=bare,updated`: {
+			insert:   `[1]->{"__crdb__": {"updated": "1.0000000002"}, "a": 1, "b": "bar"}`,
+			delete:   `[1]->{"__crdb__": {"deleted": true, "updated": "1.0000000002"}}`,
+			resolved: `{"__crdb__":{"resolved":"1.0000000002"}}`,
+		},
+		`format=avro,envelope=key_only`: {
 			insert:   `{"a":{"long":1}}->`,
 			delete:   `{"a":{"long":1}}->`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=key_only,updated`: {
+		`format=avro,envelope=key_only,updated`: {
 			insert:   `{"a":{"long":1}}->`,
 			delete:   `{"a":{"long":1}}->`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=row`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=row,updated`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row,updated`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=wrapped`: {
+		`format=avro,envelope=wrapped`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}}}`,
 			delete:   `{"a":{"long":1}}->{"after":null}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=wrapped,updated`: {
+		`format=avro,envelope=bare`: {
+			err: `envelope=bare is not supported with format=avro`,
+		},
+		`format=avro,envelope=bare,updated`: {
+			err: `envelope=bare is not supported with format=avro`,
+		},
+		`format=avro,envelope=wrapped,updated`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}},` +
 				`"updated":{"string":"1.0000000002"}}`,
@@ -199,6 +217,9 @@ type testSchemaRegistry struct {
 		syncutil.Mutex
 		idAlloc int32
 		schemas map[int32]string
+		// incompatibleFn, if set, is used to reject schemas as the registry
+		// does when they violate the compatibility level of a subject.
+		incompatibleFn func(schema string) bool
 	}
 }
 
@@ -228,6 +249,12 @@ func (r *testSchemaRegistry) Register(hw http.ResponseWriter, hr *http.Request)
 		}
 
 		r.mu.Lock()
+		if fn := r.mu.incompatibleFn; fn != nil && fn(req.Schema) {
+			r.mu.Unlock()
+			http.Error(hw, `Schema being registered is incompatible with an earlier schema`,
+				http.StatusConflict)
+			return nil
+		}
 		id := r.mu.idAlloc
 		r.mu.idAlloc++
 		r.mu.schemas[id] = req.Schema
@@ -352,6 +379,54 @@ func TestAvroMigrateToUnsupportedColumn(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestAvroSchemaEvolution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		reg := makeTestSchemaRegistry()
+		defer reg.Close()
+
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo `+
+			`WITH format=$1, confluent_schema_registry=$2`,
+			optFormatAvro, reg.server.URL)
+		defer closeFeed(t, foo)
+		assertPayloadsAvro(t, reg, foo, []string{
+			`foo: {"a":{"long":1}}->{"after":{"foo":{"a":{"long":1}}}}`,
+		})
+
+		// Adding a column registers a new version of the value schema.
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN b STRING`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'bar')`)
+		assertPayloadsAvro(t, reg, foo, []string{
+			`foo: {"a":{"long":2}}->{"after":{"foo":{"a":{"long":2},"b":{"string":"bar"}}}}`,
+		})
+
+		// A schema rejected by the registry fails the changefeed.
+		reg.mu.Lock()
+		reg.mu.incompatibleFn = func(schema string) bool {
+			return strings.Contains(schema, `"c"`)
+		}
+		reg.mu.Unlock()
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN c INT`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'baz', 4)`)
+		for {
+			if _, err := foo.Next(); err != nil {
+				if !testutils.IsError(err, `schema for foo-value is incompatible`) {
+					t.Fatalf(`expected "schema for foo-value is incompatible" error got: %+v`, err)
+				}
+				break
+			}
+		}
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestAvroLedger(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
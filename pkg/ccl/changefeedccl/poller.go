@@ -103,11 +103,27 @@ func makePoller(
 	// If no highWater is specified, set the highwater to the statement time
 	// and add a scanBoundary at the statement time to trigger an immediate output
 	// of the full table.
-	if highWater == (hlc.Timestamp{}) {
+	//
+	// A changefeed with a cursor starts with the cursor as its highWater, so a
+	// scan requested along with the cursor is redone on restarts until the
+	// highWater first moves past it. A changefeed which only scans has nothing
+	// to resume from, so it redoes the whole scan whenever it's restarted.
+	initialScan := changefeedInitialScan(details.Opts)
+	_, withCursor := details.Opts[optCursor]
+	switch {
+	case initialScan == optInitialScanOnly:
 		p.mu.highWater = details.StatementTime
 		p.mu.scanBoundaries = append(p.mu.scanBoundaries, details.StatementTime)
-	} else {
+	case highWater == (hlc.Timestamp{}):
+		p.mu.highWater = details.StatementTime
+		if initialScan != optInitialScanNo {
+			p.mu.scanBoundaries = append(p.mu.scanBoundaries, details.StatementTime)
+		}
+	default:
 		p.mu.highWater = highWater
+		if withCursor && initialScan == optInitialScanYes && highWater == details.StatementTime {
+			p.mu.scanBoundaries = append(p.mu.scanBoundaries, details.StatementTime)
+		}
 	}
 	p.tableHist = makeTableHistory(p.validateTable, highWater)
 	return p
@@ -159,6 +175,13 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 				return err
 			}
 		}
+		if changefeedInitialScan(p.details.Opts) == optInitialScanOnly {
+			// Nothing after the scan is emitted. The changeFrontier finishes the
+			// changefeed once it has seen every span resolved at the scan
+			// timestamp, which shuts down the poller.
+			<-ctx.Done()
+			return ctx.Err()
+		}
 
 		// Start rangefeeds, exit polling if we hit a resolved timestamp beyond
 		// the next scan boundary.
@@ -554,7 +577,7 @@ func clusterNodeCount(g *gossip.Gossip) int {
 }
 
 func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescriptor) error {
-	if err := validateChangefeedTable(p.details.Targets, desc); err != nil {
+	if err := validateChangefeedTable(p.details.Targets, desc, p.details.Opts); err != nil {
 		return err
 	}
 	p.mu.Lock()
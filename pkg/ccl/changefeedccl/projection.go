@@ -0,0 +1,216 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/pkg/errors"
+)
+
+// changefeedProjection projects and filters the rows of a changefeed defined
+// over a SELECT. The SELECT is resolved again for every version of the table
+// descriptor, so a schema change which drops a selected column fails the
+// changefeed.
+//
+// Only the primary key columns of deleted rows are known, so deletions are
+// never filtered out: the row may have matched the filter before it was
+// deleted.
+type changefeedProjection struct {
+	sel     *tree.SelectClause
+	evalCtx *tree.EvalContext
+	alloc   sqlbase.DatumAlloc
+
+	tables map[tableIDAndVersion]*projectedTable
+}
+
+// projectedTable is the projection and filter of one version of a table
+// descriptor.
+type projectedTable struct {
+	// desc is a copy of the table descriptor which only has the selected
+	// columns, followed by any primary key columns which weren't selected.
+	desc *sqlbase.TableDescriptor
+	// colIdxs maps each column of desc to its index in the rows of the table.
+	colIdxs []int
+	// filter is the WHERE clause of the SELECT, or nil if there is none.
+	filter tree.TypedExpr
+	ivars  *projectionIVarContainer
+}
+
+// projectionIVarContainer evaluates the column references of a filter to the
+// current row of the table.
+type projectionIVarContainer struct {
+	cols []sqlbase.ColumnDescriptor
+	row  tree.Datums
+}
+
+var _ tree.IndexedVarContainer = &projectionIVarContainer{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *projectionIVarContainer) IndexedVarEval(
+	idx int, _ *tree.EvalContext,
+) (tree.Datum, error) {
+	return c.row[idx], nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *projectionIVarContainer) IndexedVarResolvedType(idx int) *types.T {
+	return &c.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *projectionIVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].Name)
+	return &n
+}
+
+func makeChangefeedProjection(
+	sel *tree.SelectClause, evalCtx *tree.EvalContext,
+) *changefeedProjection {
+	return &changefeedProjection{
+		sel:     sel,
+		evalCtx: evalCtx,
+		tables:  make(map[tableIDAndVersion]*projectedTable),
+	}
+}
+
+// parseChangefeedProjection returns the projection of the SELECT clause stored
+// in the details of a changefeed.
+func parseChangefeedProjection(
+	selectClause string, evalCtx *tree.EvalContext,
+) (*changefeedProjection, error) {
+	stmt, err := parser.ParseOne(selectClause)
+	if err != nil {
+		return nil, err
+	}
+	if sel, ok := stmt.AST.(*tree.Select); ok {
+		if clause, ok := sel.Select.(*tree.SelectClause); ok {
+			return makeChangefeedProjection(clause, evalCtx), nil
+		}
+	}
+	return nil, errors.Errorf(`expected a SELECT clause: %s`, selectClause)
+}
+
+// project returns the row to emit for the given row, or false if the row is
+// filtered out.
+func (p *changefeedProjection) project(row encodeRow) (encodeRow, bool, error) {
+	t, err := p.forTable(row.tableDesc)
+	if err != nil {
+		return encodeRow{}, false, err
+	}
+	if t.filter != nil && !row.deleted {
+		for i := range t.ivars.cols {
+			if err := row.datums[i].EnsureDecoded(&t.ivars.cols[i].Type, &p.alloc); err != nil {
+				return encodeRow{}, false, err
+			}
+			t.ivars.row[i] = row.datums[i].Datum
+		}
+		p.evalCtx.PushIVarContainer(t.ivars)
+		pass, err := sqlbase.RunFilter(t.filter, p.evalCtx)
+		p.evalCtx.PopIVarContainer()
+		if err != nil || !pass {
+			return encodeRow{}, false, err
+		}
+	}
+	datums := make(sqlbase.EncDatumRow, len(t.colIdxs))
+	for i, idx := range t.colIdxs {
+		datums[i] = row.datums[idx]
+	}
+	row.datums, row.tableDesc = datums, t.desc
+	return row, true, nil
+}
+
+// forTable resolves the SELECT against a version of its table descriptor.
+func (p *changefeedProjection) forTable(
+	desc *sqlbase.TableDescriptor,
+) (*projectedTable, error) {
+	cacheKey := makeTableIDAndVersion(desc.ID, desc.Version)
+	if t, ok := p.tables[cacheKey]; ok {
+		return t, nil
+	}
+
+	t := &projectedTable{
+		ivars: &projectionIVarContainer{
+			cols: desc.Columns,
+			row:  make(tree.Datums, len(desc.Columns)),
+		},
+	}
+	colIdxByID := desc.ColumnIdxMap()
+	var cols []sqlbase.ColumnDescriptor
+	addCol := func(idx int) {
+		for _, existing := range t.colIdxs {
+			if existing == idx {
+				return
+			}
+		}
+		cols = append(cols, desc.Columns[idx])
+		t.colIdxs = append(t.colIdxs, idx)
+	}
+	for _, expr := range p.sel.Exprs {
+		if expr.As != `` {
+			return nil, errors.Errorf(`CHANGEFEED cannot rename columns: %s`, tree.AsString(&expr))
+		}
+		varName := tree.VarName(tree.UnqualifiedStar{})
+		if name, ok := expr.Expr.(*tree.UnresolvedName); ok {
+			var err error
+			if varName, err = name.NormalizeVarName(); err != nil {
+				return nil, err
+			}
+		} else if _, ok := expr.Expr.(tree.UnqualifiedStar); !ok {
+			return nil, errors.Errorf(`CHANGEFEED can only select columns, not %s`, tree.AsString(expr.Expr))
+		}
+		switch v := varName.(type) {
+		case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+			for i := range desc.Columns {
+				addCol(i)
+			}
+		case *tree.ColumnItem:
+			col, err := desc.FindActiveColumnByName(string(v.ColumnName))
+			if err != nil {
+				return nil, err
+			}
+			addCol(colIdxByID[col.ID])
+		default:
+			return nil, errors.Errorf(`CHANGEFEED can only select columns, not %s`, tree.AsString(expr.Expr))
+		}
+	}
+	// The primary key is always emitted as the key of each message, so it's
+	// not hidden from the value either.
+	for _, colID := range desc.PrimaryIndex.ColumnIDs {
+		addCol(colIdxByID[colID])
+	}
+	projected := *desc
+	projected.Columns = cols
+	t.desc = &projected
+
+	if p.sel.Where != nil {
+		tn := tree.MakeUnqualifiedTableName(tree.Name(desc.Name))
+		sources := sqlbase.MakeMultiSourceInfo(sqlbase.NewSourceInfoForSingleTable(
+			tn, sqlbase.ResultColumnsFromColDescs(desc.Columns),
+		))
+		ivarHelper := tree.MakeIndexedVarHelper(t.ivars, len(desc.Columns))
+		expr, _, _, err := sqlbase.ResolveNames(
+			p.sel.Where.Expr, sources, ivarHelper, p.evalCtx.SessionData.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		semaCtx := tree.MakeSemaContext()
+		semaCtx.IVarContainer = t.ivars
+		semaCtx.Properties.Require(`CHANGEFEED`,
+			tree.RejectSpecial|tree.RejectImpureFunctions|tree.RejectSubqueries)
+		if t.filter, err = tree.TypeCheckAndRequire(expr, &semaCtx, types.Bool, `WHERE`); err != nil {
+			return nil, err
+		}
+	}
+
+	p.tables[cacheKey] = t
+	return t, nil
+}
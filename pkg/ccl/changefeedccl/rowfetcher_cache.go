@@ -11,6 +11,7 @@ package changefeedccl
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
@@ -26,19 +27,28 @@ import (
 // StartScanFrom can be used to turn that key (or all the keys making up the
 // column families of one row) into a row.
 type rowFetcherCache struct {
-	leaseMgr *sql.LeaseManager
-	fetchers map[*sqlbase.ImmutableTableDescriptor]*row.Fetcher
+	leaseMgr       *sql.LeaseManager
+	fetchers       map[*sqlbase.ImmutableTableDescriptor]*row.Fetcher
+	familyFetchers map[familyFetcherKey]*row.Fetcher
 
 	a sqlbase.DatumAlloc
 }
 
 func newRowFetcherCache(leaseMgr *sql.LeaseManager) *rowFetcherCache {
 	return &rowFetcherCache{
-		leaseMgr: leaseMgr,
-		fetchers: make(map[*sqlbase.ImmutableTableDescriptor]*row.Fetcher),
+		leaseMgr:       leaseMgr,
+		fetchers:       make(map[*sqlbase.ImmutableTableDescriptor]*row.Fetcher),
+		familyFetchers: make(map[familyFetcherKey]*row.Fetcher),
 	}
 }
 
+// familyFetcherKey identifies a Fetcher which only decodes the columns of one
+// column family.
+type familyFetcherKey struct {
+	tableDesc *sqlbase.ImmutableTableDescriptor
+	familyID  sqlbase.FamilyID
+}
+
 func (c *rowFetcherCache) TableDescForKey(
 	ctx context.Context, key roachpb.Key, ts hlc.Timestamp,
 ) (*sqlbase.ImmutableTableDescriptor, error) {
@@ -88,13 +98,76 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 		return rf, nil
 	}
 
-	// TODO(dan): Allow for decoding a subset of the columns.
-	colIdxMap := make(map[sqlbase.ColumnID]int)
 	var valNeededForCol util.FastIntSet
 	for colIdx := range tableDesc.Columns {
-		colIdxMap[tableDesc.Columns[colIdx].ID] = colIdx
 		valNeededForCol.Add(colIdx)
 	}
+	rf, err := c.makeRowFetcher(tableDesc, valNeededForCol)
+	if err != nil {
+		return nil, err
+	}
+	// TODO(dan): Bound the size of the cache. Resolved notifications will let
+	// us evict anything for timestamps entirely before the notification. Then
+	// probably an LRU just in case?
+	c.fetchers[tableDesc] = rf
+	return rf, nil
+}
+
+// RowFetcherForColumnFamily returns a Fetcher which turns a single key of the
+// given column family into a row. Only the primary key columns and the columns
+// in the family are decoded, the rest are left unset.
+//
+// The column family 0 kv is the only one which is always present for a row, so
+// a tombstone for any other family is indistinguishable from every column in
+// that family being set to NULL. Either way, the Fetcher reports it as deleted.
+func (c *rowFetcherCache) RowFetcherForColumnFamily(
+	tableDesc *sqlbase.ImmutableTableDescriptor, familyID sqlbase.FamilyID,
+) (*row.Fetcher, *sqlbase.ColumnFamilyDescriptor, error) {
+	family, err := tableDesc.FindFamilyByID(familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := familyFetcherKey{tableDesc: tableDesc, familyID: familyID}
+	if rf, ok := c.familyFetchers[key]; ok {
+		return rf, family, nil
+	}
+
+	colIdxMap := tableDesc.ColumnIdxMap()
+	var valNeededForCol util.FastIntSet
+	for _, colID := range tableDesc.PrimaryIndex.ColumnIDs {
+		valNeededForCol.Add(colIdxMap[colID])
+	}
+	for _, colID := range family.ColumnIDs {
+		valNeededForCol.Add(colIdxMap[colID])
+	}
+	rf, err := c.makeRowFetcher(tableDesc, valNeededForCol)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.familyFetchers[key] = rf
+	return rf, family, nil
+}
+
+// familyIDForKey returns the ID of the column family of a primary index key.
+func familyIDForKey(key roachpb.Key) (sqlbase.FamilyID, error) {
+	prefixLen, err := keys.GetRowPrefixLength(key)
+	if err != nil {
+		return 0, err
+	}
+	_, familyID, err := encoding.DecodeUvarintAscending(key[prefixLen:])
+	if err != nil {
+		return 0, err
+	}
+	return sqlbase.FamilyID(familyID), nil
+}
+
+func (c *rowFetcherCache) makeRowFetcher(
+	tableDesc *sqlbase.ImmutableTableDescriptor, valNeededForCol util.FastIntSet,
+) (*row.Fetcher, error) {
+	colIdxMap := make(map[sqlbase.ColumnID]int)
+	for colIdx := range tableDesc.Columns {
+		colIdxMap[tableDesc.Columns[colIdx].ID] = colIdx
+	}
 
 	var rf row.Fetcher
 	if err := rf.Init(
@@ -111,9 +184,5 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 	); err != nil {
 		return nil, err
 	}
-	// TODO(dan): Bound the size of the cache. Resolved notifications will let
-	// us evict anything for timestamps entirely before the notification. Then
-	// probably an LRU just in case?
-	c.fetchers[tableDesc] = &rf
 	return &rf, nil
 }
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -43,6 +44,15 @@ import (
 )
 
 // Sink is an abstraction for anything that a changefeed may emit into.
+//
+// Changefeeds guarantee that the first time a row is emitted, it is emitted
+// after every earlier change to the same primary key. Rows may be emitted
+// again later (for example, when a changefeed is restarted), but once a
+// resolved timestamp has been emitted, any row with an earlier updated
+// timestamp is a duplicate. To keep these guarantees, a Sink must deliver the
+// messages for a key in the order they're given, including when a message is
+// retried, and Flush must only return once every message given before it has
+// been delivered.
 type Sink interface {
 	// EmitRow enqueues a row message for asynchronous delivery on the sink. An
 	// error may be returned if a previously enqueued message has failed.
@@ -174,6 +184,54 @@ func getSink(
 		makeSink = func() (Sink, error) {
 			return makeCloudStorageSink(u.String(), nodeID, fileSize, settings, opts)
 		}
+	case u.Scheme == sinkSchemeWebhookHTTP || u.Scheme == sinkSchemeWebhookHTTPS:
+		if err := requireJSONFormat(opts); err != nil {
+			return nil, err
+		}
+		cfg, err := consumeHTTPSinkParams(q)
+		if err != nil {
+			return nil, err
+		}
+		var hmacKey, caCert []byte
+		if s := q.Get(sinkParamHMACKey); s != `` {
+			if hmacKey, err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, sinkParamHMACKey, err)
+			}
+		}
+		q.Del(sinkParamHMACKey)
+		if s := q.Get(sinkParamCACert); s != `` {
+			if caCert, err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, sinkParamCACert, err)
+			}
+		}
+		q.Del(sinkParamCACert)
+		u.Scheme = strings.TrimPrefix(u.Scheme, `webhook-`)
+		// Any remaining query parameters belong to the endpoint.
+		u.RawQuery = q.Encode()
+		q = url.Values{}
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(u, cfg, hmacKey, caCert, targets)
+		}
+	case u.Scheme == sinkSchemeGCPubsub:
+		if err := requireJSONFormat(opts); err != nil {
+			return nil, err
+		}
+		if u.Host == `` {
+			return nil, errors.Errorf(`the Google Cloud project must be specified as the host of the sink URI`)
+		}
+		cfg, err := consumeHTTPSinkParams(q)
+		if err != nil {
+			return nil, err
+		}
+		topicPrefix := q.Get(sinkParamTopicPrefix)
+		q.Del(sinkParamTopicPrefix)
+		auth, credentials := q.Get(storageccl.AuthParam), q.Get(storageccl.CredentialsParam)
+		q.Del(storageccl.AuthParam)
+		q.Del(storageccl.CredentialsParam)
+		makeSink = func() (Sink, error) {
+			return makePubsubSink(
+				context.TODO(), u.Host, topicPrefix, auth, credentials, cfg, targets, settings)
+		}
 	case u.Scheme == sinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -204,6 +262,17 @@ func getSink(
 	return s, nil
 }
 
+// requireJSONFormat returns an error if a changefeed with the given options
+// doesn't emit JSON, for the sinks which can only deliver JSON.
+func requireJSONFormat(opts map[string]string) error {
+	switch formatType(opts[optFormat]) {
+	case ``, optFormatJSON:
+		return nil
+	default:
+		return errors.Errorf(`this sink is incompatible with %s=%s`, optFormat, opts[optFormat])
+	}
+}
+
 // errorWrapperSink delegates to another sink and marks all returned errors as
 // retryable. During changefeed setup, we use the sink once without this to
 // verify configuration, but in the steady state, no sink error should be
@@ -314,6 +383,9 @@ func makeKafkaSink(
 	config.ClientID = `CockroachDB`
 	config.Producer.Return.Successes = true
 	config.Producer.Partitioner = newChangefeedPartitioner
+	// With more than one request in flight to a broker, a retried request can
+	// land after a later one, which reorders the messages for a key.
+	config.Net.MaxOpenRequests = 1
 
 	if cfg.caCert != nil {
 		if !cfg.tlsEnabled {
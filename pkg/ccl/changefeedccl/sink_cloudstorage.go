@@ -149,7 +149,7 @@ func makeCloudStorageSink(
 	}
 
 	switch envelopeType(opts[optEnvelope]) {
-	case optEnvelopeWrapped:
+	case optEnvelopeWrapped, optEnvelopeBare:
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			optEnvelope, opts[optEnvelope])
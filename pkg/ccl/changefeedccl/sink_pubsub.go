@@ -0,0 +1,167 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	gojson "encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/pkg/errors"
+)
+
+const (
+	pubsubScope   = `https://www.googleapis.com/auth/pubsub`
+	pubsubBaseURL = `https://pubsub.googleapis.com/v1/`
+
+	// pubsubMaxBatchSize is the maximum number of messages in a publish request
+	// accepted by Pub/Sub.
+	pubsubMaxBatchSize = 1000
+
+	// pubsubAttributeKey is the attribute holding the key of a row message.
+	pubsubAttributeKey = `key`
+)
+
+// pubsubMessage is a message in a request to the publish method of the Pub/Sub
+// REST API.
+type pubsubMessage struct {
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// pubsubSink emits to Google Cloud Pub/Sub through its REST API. Like in
+// kafkaSink, each table is emitted to its own topic, which must already exist.
+// Pub/Sub doesn't keep the order of the messages published to a topic, so
+// consumers which care about it must use the updated timestamps of rows. It is
+// not concurrency-safe; all calls to Emit and Flush should be from the same
+// goroutine.
+type pubsubSink struct {
+	// topicsURL is the URL of the topics of the project in the REST API.
+	topicsURL   string
+	client      *http.Client
+	cfg         httpSinkConfig
+	topicPrefix string
+	topics      map[string]struct{}
+
+	buf     map[string][]pubsubMessage
+	scratch bufalloc.ByteAllocator
+}
+
+func makePubsubSink(
+	ctx context.Context,
+	project string,
+	topicPrefix string,
+	auth, credentials string,
+	cfg httpSinkConfig,
+	targets jobspb.ChangefeedTargets,
+	settings *cluster.Settings,
+) (*pubsubSink, error) {
+	if cfg.batchSize > pubsubMaxBatchSize {
+		return nil, errors.Errorf(`param %s must be at most %d`, sinkParamBatchSize, pubsubMaxBatchSize)
+	}
+	client, err := storageccl.GoogleCloudHTTPClient(ctx, auth, credentials, settings, pubsubScope)
+	if err != nil {
+		return nil, errors.Wrap(err, `creating Pub/Sub client`)
+	}
+	s := &pubsubSink{
+		topicsURL:   pubsubBaseURL + `projects/` + url.PathEscape(project) + `/topics/`,
+		client:      client,
+		cfg:         cfg,
+		topicPrefix: topicPrefix,
+		topics:      make(map[string]struct{}),
+		buf:         make(map[string][]pubsubMessage),
+	}
+	for _, t := range targets {
+		s.topics[topicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
+	}
+	return s, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *pubsubSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	topic := s.topicPrefix + SQLNameToKafkaName(table.Name)
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	return s.emit(ctx, topic, pubsubMessage{
+		Data:       value,
+		Attributes: map[string]string{pubsubAttributeKey: string(key)},
+	})
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *pubsubSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	for topic := range s.topics {
+		payload, err := encoder.EncodeResolvedTimestamp(topic, resolved)
+		if err != nil {
+			return err
+		}
+		s.scratch, payload = s.scratch.Copy(payload, 0 /* extraCap */)
+		if err := s.emit(ctx, topic, pubsubMessage{Data: payload}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *pubsubSink) emit(ctx context.Context, topic string, msg pubsubMessage) error {
+	s.buf[topic] = append(s.buf[topic], msg)
+	if len(s.buf[topic]) >= s.cfg.batchSize {
+		return s.publish(ctx, topic)
+	}
+	return nil
+}
+
+// publish sends the messages buffered for a topic.
+func (s *pubsubSink) publish(ctx context.Context, topic string) error {
+	msgs := s.buf[topic]
+	if len(msgs) == 0 {
+		return nil
+	}
+	body, err := gojson.Marshal(struct {
+		Messages []pubsubMessage `json:"messages"`
+	}{Messages: msgs})
+	if err != nil {
+		return err
+	}
+	header := make(http.Header)
+	header.Set(`Content-Type`, `application/json`)
+	publishURL := s.topicsURL + url.PathEscape(topic) + `:publish`
+	if err := postWithRetry(ctx, s.client, s.cfg.retryOpts, publishURL, header, body); err != nil {
+		return errors.Wrapf(err, `publishing to Pub/Sub topic %s`, topic)
+	}
+	s.buf[topic] = msgs[:0]
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *pubsubSink) Flush(ctx context.Context) error {
+	for topic := range s.buf {
+		if err := s.publish(ctx, topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *pubsubSink) Close() error {
+	return nil
+}
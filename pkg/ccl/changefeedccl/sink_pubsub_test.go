@@ -0,0 +1,86 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPubsubSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	srv := makeTestHTTPSinkServer()
+	defer srv.server.Close()
+
+	cfg := defaultHTTPSinkConfig()
+	cfg.batchSize = 2
+	cfg.retryOpts.InitialBackoff = time.Millisecond
+	cfg.retryOpts.MaxRetries = 1
+	sink := &pubsubSink{
+		topicsURL:   srv.server.URL + `/v1/projects/p/topics/`,
+		client:      srv.server.Client(),
+		cfg:         cfg,
+		topicPrefix: `cdc_`,
+		topics:      map[string]struct{}{`cdc_t`: {}, `cdc_u`: {}},
+		buf:         make(map[string][]pubsubMessage),
+	}
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	table := func(name string) *sqlbase.TableDescriptor {
+		return &sqlbase.TableDescriptor{Name: name}
+	}
+	encoder, err := makeJSONEncoder(map[string]string{optEnvelope: string(optEnvelopeWrapped)})
+	require.NoError(t, err)
+
+	// Messages are batched per topic.
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), []byte(`[1]`), []byte(`1`), zeroTS))
+	require.NoError(t, sink.EmitRow(ctx, table(`u`), []byte(`[2]`), []byte(`2`), zeroTS))
+	bodies, _, _ := srv.requests()
+	require.Empty(t, bodies)
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), []byte(`[3]`), []byte(`3`), zeroTS))
+	bodies, _, paths := srv.requests()
+	require.Equal(t, []string{`/v1/projects/p/topics/cdc_t:publish`}, paths)
+	require.Equal(t, []string{
+		`{"messages":[{"data":"MQ==","attributes":{"key":"[1]"}},{"data":"Mw==","attributes":{"key":"[3]"}}]}`,
+	}, bodies)
+	require.EqualError(t,
+		sink.EmitRow(ctx, table(`v`), nil, nil, zeroTS), `cannot emit to undeclared topic: cdc_v`)
+
+	// Resolved timestamps are published to every topic.
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, encoder, hlc.Timestamp{WallTime: 1}))
+	require.NoError(t, sink.Flush(ctx))
+	bodies, _, paths = srv.requests()
+	require.Len(t, bodies, 3)
+	sort.Strings(paths)
+	require.Equal(t, []string{
+		`/v1/projects/p/topics/cdc_t:publish`,
+		`/v1/projects/p/topics/cdc_t:publish`,
+		`/v1/projects/p/topics/cdc_u:publish`,
+	}, paths)
+
+	srv.fail(2, http.StatusInternalServerError)
+	require.NoError(t, sink.EmitRow(ctx, table(`u`), []byte(`[4]`), []byte(`4`), zeroTS))
+	if err := sink.Flush(ctx); !testutils.IsError(err, `publishing to Pub/Sub topic cdc_u`) {
+		t.Fatalf(`expected a publishing error got: %+v`, err)
+	}
+	require.NoError(t, sink.Flush(ctx))
+	bodies, _, _ = srv.requests()
+	require.Len(t, bodies, 4)
+}
@@ -0,0 +1,256 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	gojson "encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/pkg/errors"
+)
+
+const (
+	// webhookSignatureHeader holds the hex encoded HMAC-SHA256 of the body of
+	// each webhook request, when the sink is configured with an HMAC key.
+	webhookSignatureHeader = `X-Cockroach-Signature`
+
+	// httpSinkRequestTimeout bounds each request made by the sinks which
+	// deliver messages over HTTP.
+	httpSinkRequestTimeout = time.Minute
+)
+
+// httpSinkConfig is the batching and retry configuration of the sinks which
+// deliver messages over HTTP.
+type httpSinkConfig struct {
+	// batchSize is the number of buffered messages at which a request is sent
+	// without waiting for the next Flush.
+	batchSize int
+	retryOpts retry.Options
+}
+
+func defaultHTTPSinkConfig() httpSinkConfig {
+	return httpSinkConfig{
+		batchSize: 100,
+		retryOpts: retry.Options{
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+			Multiplier:     2,
+			MaxRetries:     5,
+		},
+	}
+}
+
+// consumeHTTPSinkParams parses and removes the batching and retry parameters
+// from the query parameters of a sink URI.
+func consumeHTTPSinkParams(q url.Values) (httpSinkConfig, error) {
+	cfg := defaultHTTPSinkConfig()
+	if s := q.Get(sinkParamBatchSize); s != `` {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return cfg, errors.Errorf(`param %s must be a positive integer: %s`, sinkParamBatchSize, s)
+		}
+		cfg.batchSize = n
+	}
+	q.Del(sinkParamBatchSize)
+	if s := q.Get(sinkParamMaxRetries); s != `` {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return cfg, errors.Errorf(`param %s must be a positive integer: %s`, sinkParamMaxRetries, s)
+		}
+		cfg.retryOpts.MaxRetries = n
+	}
+	q.Del(sinkParamMaxRetries)
+	if s := q.Get(sinkParamRetryBackoff); s != `` {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return cfg, errors.Errorf(`param %s must be a positive duration: %s`, sinkParamRetryBackoff, s)
+		}
+		cfg.retryOpts.InitialBackoff = d
+		if cfg.retryOpts.MaxBackoff < d {
+			cfg.retryOpts.MaxBackoff = d
+		}
+	}
+	q.Del(sinkParamRetryBackoff)
+	return cfg, nil
+}
+
+// postWithRetry POSTs body to url, retrying with backoff on errors which may
+// be transient: network errors, throttling and server errors.
+func postWithRetry(
+	ctx context.Context,
+	client *http.Client,
+	opts retry.Options,
+	url string,
+	header http.Header,
+	body []byte,
+) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		var retryable bool
+		if retryable, err = post(ctx, client, url, header, body); err == nil || !retryable {
+			return err
+		}
+		if log.V(1) {
+			log.Infof(ctx, "retrying request to sink after error: %v", err)
+		}
+	}
+	return err
+}
+
+func post(
+	ctx context.Context, client *http.Client, url string, header http.Header, body []byte,
+) (retryable bool, _ error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = header
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	err = errors.Errorf(`sink responded with %s: %s`, resp.Status, respBody)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// webhookMessage is one entry of the payload of a webhook request. Resolved
+// timestamps have no key.
+type webhookMessage struct {
+	Topic string            `json:"topic,omitempty"`
+	Key   gojson.RawMessage `json:"key,omitempty"`
+	Value gojson.RawMessage `json:"value"`
+}
+
+// webhookSink emits batches of messages as JSON to an HTTP endpoint. Each
+// request body is of the form `{"payload": [...], "length": n}`. It is not
+// concurrency-safe; all calls to Emit and Flush should be from the same
+// goroutine.
+type webhookSink struct {
+	url     string
+	client  *http.Client
+	cfg     httpSinkConfig
+	hmacKey []byte
+	topics  map[string]struct{}
+
+	buf     []webhookMessage
+	scratch bufalloc.ByteAllocator
+}
+
+func makeWebhookSink(
+	u *url.URL, cfg httpSinkConfig, hmacKey, caCert []byte, targets jobspb.ChangefeedTargets,
+) (*webhookSink, error) {
+	s := &webhookSink{
+		url:     u.String(),
+		client:  &http.Client{Timeout: httpSinkRequestTimeout},
+		cfg:     cfg,
+		hmacKey: hmacKey,
+		topics:  make(map[string]struct{}),
+	}
+	if caCert != nil {
+		if u.Scheme != `https` {
+			return nil, errors.Errorf(`%s requires the %s scheme`, sinkParamCACert, sinkSchemeWebhookHTTPS)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf(`param %s does not contain a PEM encoded certificate`, sinkParamCACert)
+		}
+		s.client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
+	}
+	for _, t := range targets {
+		s.topics[t.StatementTimeName] = struct{}{}
+	}
+	return s, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	topic := table.Name
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	return s.emit(ctx, webhookMessage{Topic: topic, Key: key, Value: value})
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	// Every topic is delivered to the same endpoint, so a single message is
+	// enough to resolve all of them.
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	s.scratch, payload = s.scratch.Copy(payload, 0 /* extraCap */)
+	return s.emit(ctx, webhookMessage{Value: payload})
+}
+
+func (s *webhookSink) emit(ctx context.Context, msg webhookMessage) error {
+	s.buf = append(s.buf, msg)
+	if len(s.buf) >= s.cfg.batchSize {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	body, err := gojson.Marshal(struct {
+		Payload []webhookMessage `json:"payload"`
+		Length  int              `json:"length"`
+	}{Payload: s.buf, Length: len(s.buf)})
+	if err != nil {
+		return err
+	}
+	header := make(http.Header)
+	header.Set(`Content-Type`, `application/json`)
+	if s.hmacKey != nil {
+		mac := hmac.New(sha256.New, s.hmacKey)
+		_, _ = mac.Write(body)
+		header.Set(webhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	if err := postWithRetry(ctx, s.client, s.cfg.retryOpts, s.url, header, body); err != nil {
+		return err
+	}
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testHTTPSinkServer records the bodies of the requests it receives and fails
+// the next failures requests with the given status.
+type testHTTPSinkServer struct {
+	server *httptest.Server
+	mu     struct {
+		syncutil.Mutex
+		bodies   []string
+		headers  []http.Header
+		paths    []string
+		failures int
+		status   int
+	}
+}
+
+func makeTestHTTPSinkServer() *testHTTPSinkServer {
+	s := &testHTTPSinkServer{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.mu.failures > 0 {
+			s.mu.failures--
+			http.Error(w, `injected failure`, s.mu.status)
+			return
+		}
+		s.mu.bodies = append(s.mu.bodies, string(body))
+		s.mu.headers = append(s.mu.headers, r.Header)
+		s.mu.paths = append(s.mu.paths, r.URL.Path)
+	}))
+	return s
+}
+
+func (s *testHTTPSinkServer) fail(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failures, s.mu.status = n, status
+}
+
+func (s *testHTTPSinkServer) requests() (bodies []string, headers []http.Header, paths []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.mu.bodies...), append([]http.Header(nil), s.mu.headers...),
+		append([]string(nil), s.mu.paths...)
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	srv := makeTestHTTPSinkServer()
+	defer srv.server.Close()
+
+	hmacKey := []byte(`secret`)
+	sinkURI := `webhook-` + srv.server.URL + `?batch_size=2&retry_backoff=1ms&max_retries=2` +
+		`&hmac_key=` + url.QueryEscape(base64.StdEncoding.EncodeToString(hmacKey))
+	targets := jobspb.ChangefeedTargets{0: jobspb.ChangefeedTarget{StatementTimeName: `t`}}
+	sink, err := getSink(sinkURI, 0 /* nodeID */, nil /* opts */, targets, nil /* settings */)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	table := &sqlbase.TableDescriptor{Name: `t`}
+	encoder, err := makeJSONEncoder(map[string]string{optEnvelope: string(optEnvelopeWrapped)})
+	require.NoError(t, err)
+
+	// Nothing is sent until the batch is full or the sink is flushed.
+	require.NoError(t, sink.EmitRow(ctx, table, []byte(`[1]`), []byte(`{"a": 1}`), zeroTS))
+	bodies, _, _ := srv.requests()
+	require.Empty(t, bodies)
+	require.NoError(t, sink.EmitRow(ctx, table, []byte(`[2]`), []byte(`{"a": 2}`), zeroTS))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, encoder, hlc.Timestamp{WallTime: 1}))
+	require.NoError(t, sink.Flush(ctx))
+	require.EqualError(t,
+		sink.EmitRow(ctx, &sqlbase.TableDescriptor{Name: `u`}, nil, nil, zeroTS),
+		`cannot emit to undeclared topic: u`)
+
+	bodies, headers, _ := srv.requests()
+	require.Equal(t, []string{
+		`{"payload":[{"topic":"t","key":[1],"value":{"a":1}},{"topic":"t","key":[2],"value":{"a":2}}],"length":2}`,
+		`{"payload":[{"value":{"resolved":"1.0000000000"}}],"length":1}`,
+	}, bodies)
+	for i := range bodies {
+		mac := hmac.New(sha256.New, hmacKey)
+		_, _ = mac.Write([]byte(bodies[i]))
+		require.Equal(t, hex.EncodeToString(mac.Sum(nil)), headers[i].Get(webhookSignatureHeader))
+	}
+
+	// Server errors are retried.
+	srv.fail(2, http.StatusServiceUnavailable)
+	require.NoError(t, sink.EmitRow(ctx, table, []byte(`[3]`), []byte(`{"a": 3}`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	bodies, _, _ = srv.requests()
+	require.Len(t, bodies, 3)
+
+	// Until they're not.
+	srv.fail(3, http.StatusServiceUnavailable)
+	require.NoError(t, sink.EmitRow(ctx, table, []byte(`[4]`), []byte(`{"a": 4}`), zeroTS))
+	if err := sink.Flush(ctx); !testutils.IsError(err, `503 Service Unavailable`) {
+		t.Fatalf(`expected a 503 error got: %+v`, err)
+	}
+
+	// Client errors are not retried.
+	srv.fail(1, http.StatusBadRequest)
+	if err := sink.Flush(ctx); !testutils.IsError(err, `400 Bad Request`) {
+		t.Fatalf(`expected a 400 error got: %+v`, err)
+	}
+	require.NoError(t, sink.Flush(ctx))
+	bodies, _, _ = srv.requests()
+	require.Equal(t, `{"payload":[{"topic":"t","key":[4],"value":{"a":4}}],"length":1}`, bodies[3])
+}
+
+func TestHTTPSinkParams(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cfg, err := consumeHTTPSinkParams(url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, defaultHTTPSinkConfig(), cfg)
+
+	q := url.Values{
+		sinkParamBatchSize:    {`10`},
+		sinkParamMaxRetries:   {`3`},
+		sinkParamRetryBackoff: {`1m`},
+		`other`:               {`x`},
+	}
+	cfg, err = consumeHTTPSinkParams(q)
+	require.NoError(t, err)
+	require.Equal(t, 10, cfg.batchSize)
+	require.Equal(t, 3, cfg.retryOpts.MaxRetries)
+	require.Equal(t, time.Minute, cfg.retryOpts.InitialBackoff)
+	require.Equal(t, time.Minute, cfg.retryOpts.MaxBackoff)
+	require.Equal(t, url.Values{`other`: {`x`}}, q)
+
+	for _, bad := range []url.Values{
+		{sinkParamBatchSize: {`0`}},
+		{sinkParamMaxRetries: {`-1`}},
+		{sinkParamRetryBackoff: {`soon`}},
+	} {
+		if _, err := consumeHTTPSinkParams(bad); !testutils.IsError(err, `must be a positive`) {
+			t.Fatalf(`expected an error for %v got: %+v`, bad, err)
+		}
+	}
+}
@@ -89,7 +89,7 @@ AES128_CTR:be235...   # AES-128 encryption with store key ID
 		"print active store key ID and exit")
 
 	// Add encryption flag to all OSS debug commands that want it.
-	for _, cmd := range cli.DebugCmdsForRocksDB {
+	for _, cmd := range append(cli.DebugCmdsForRocksDB, cli.DebugRecoverCmdsForRocksDB...) {
 		// storeEncryptionSpecs is in start.go.
 		cli.VarFlag(cmd.Flags(), &storeEncryptionSpecs, cliflagsccl.EnterpriseEncryption)
 	}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -144,6 +145,7 @@ func (f oracleFactory) Oracle(txn *client.Txn) replicaoracle.Oracle {
 var followerReadAwareChoice = replicaoracle.RegisterPolicy(newOracleFactory)
 
 func init() {
+	build.RegisterFeature("follower-reads")
 	sql.ReplicaOraclePolicy = followerReadAwareChoice
 	builtins.EvalFollowerReadOffset = evalFollowerReadOffset
 	kv.CanSendToFollower = canSendToFollower
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"unsafe"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -188,5 +189,6 @@ func checkEntry(entry hba.Entry) error {
 }
 
 func init() {
+	build.RegisterFeature("gssapi")
 	pgwire.RegisterAuthMethod("gss", authGSS, checkEntry)
 }
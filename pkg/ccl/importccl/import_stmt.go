@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -42,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -52,6 +54,9 @@ const (
 	csvNullIf    = "nullif"
 	csvSkip      = "skip"
 
+	csvMaxRowErrors = "max_row_errors"
+	csvSaveRejected = "save_rejected"
+
 	mysqlOutfileRowSep   = "rows_terminated_by"
 	mysqlOutfileFieldSep = "fields_terminated_by"
 	mysqlOutfileEnclose  = "fields_enclosed_by"
@@ -62,6 +67,9 @@ const (
 	importOptionOversample = "oversample"
 	importOptionSkipFKs    = "skip_foreign_keys"
 
+	importOptionTables            = "tables"
+	importOptionIgnoreUnsupported = "ignore_unsupported"
+
 	importOptionDirectIngest = "experimental_direct_ingestion"
 	importOptionSortedIngest = "experimental_sorted_ingestion"
 
@@ -69,6 +77,10 @@ const (
 	pgCopyNull      = "nullif"
 
 	pgMaxRowSize = "max_row_size"
+
+	avroSchema        = "schema"
+	avroBinaryRecords = "data_as_binary_records"
+	avroStrict        = "strict_validation"
 )
 
 var importOptionExpectValues = map[string]sql.KVStringOptValidate{
@@ -77,6 +89,9 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	csvNullIf:    sql.KVStringOptRequireValue,
 	csvSkip:      sql.KVStringOptRequireValue,
 
+	csvMaxRowErrors: sql.KVStringOptRequireValue,
+	csvSaveRejected: sql.KVStringOptRequireNoValue,
+
 	mysqlOutfileRowSep:   sql.KVStringOptRequireValue,
 	mysqlOutfileFieldSep: sql.KVStringOptRequireValue,
 	mysqlOutfileEnclose:  sql.KVStringOptRequireValue,
@@ -88,10 +103,45 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 
 	importOptionSkipFKs: sql.KVStringOptRequireNoValue,
 
+	importOptionTables:            sql.KVStringOptRequireValue,
+	importOptionIgnoreUnsupported: sql.KVStringOptRequireNoValue,
+
 	importOptionDirectIngest: sql.KVStringOptRequireNoValue,
 	importOptionSortedIngest: sql.KVStringOptRequireNoValue,
 
 	pgMaxRowSize: sql.KVStringOptRequireValue,
+
+	avroSchema:        sql.KVStringOptRequireValue,
+	avroBinaryRecords: sql.KVStringOptRequireNoValue,
+	avroStrict:        sql.KVStringOptRequireNoValue,
+}
+
+// unsupportedHandler is called with each statement (or part of one) of a dump
+// file which IMPORT doesn't support, along with the error explaining why. It
+// returns an error to fail the IMPORT, or nil to skip what isn't supported.
+type unsupportedHandler func(stmt string, err error) error
+
+// makeUnsupportedHandler returns an unsupportedHandler which fails the IMPORT
+// unless ignore is set, in which case it logs a warning instead.
+func makeUnsupportedHandler(ctx context.Context, ignore bool) unsupportedHandler {
+	return func(stmt string, err error) error {
+		if !ignore {
+			return errors.WithHintf(err,
+				"use WITH %s to skip unsupported statements", importOptionIgnoreUnsupported)
+		}
+		log.Warningf(ctx, "IMPORT skipping unsupported statement %q: %v", stmt, err)
+		return nil
+	}
+}
+
+// sortedNames returns the names in the given set in sorted order.
+func sortedNames(names map[string]bool) []string {
+	ret := make([]string, 0, len(names))
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
 }
 
 func importJobDescription(
@@ -243,6 +293,24 @@ func importPlanHook(
 				}
 				format.Csv.Skip = uint32(skip)
 			}
+
+			if override, ok := opts[csvMaxRowErrors]; ok {
+				maxRowErrors, err := strconv.ParseInt(override, 10, 64)
+				if err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid %s value", csvMaxRowErrors)
+				}
+				if maxRowErrors < 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s must be >= 0", csvMaxRowErrors)
+				}
+				format.Csv.MaxRowErrors = maxRowErrors
+			}
+
+			if _, ok := opts[csvSaveRejected]; ok {
+				if format.Csv.MaxRowErrors == 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s requires %s", csvSaveRejected, csvMaxRowErrors)
+				}
+				format.Csv.SaveRejected = true
+			}
 		case "MYSQLOUTFILE":
 			telemetry.Count("import.format.mysqlout")
 			format.Format = roachpb.IOFileFormat_MysqlOutfile
@@ -331,6 +399,38 @@ func importPlanHook(
 				maxRowSize = int32(sz)
 			}
 			format.PgDump.MaxRowSize = maxRowSize
+			_, format.PgDump.IgnoreUnsupported = opts[importOptionIgnoreUnsupported]
+		case "AVRO":
+			telemetry.Count("import.format.avro")
+			format.Format = roachpb.IOFileFormat_Avro
+			_, format.Avro.BinaryRecords = opts[avroBinaryRecords]
+			_, format.Avro.StrictMode = opts[avroStrict]
+			if override, ok := opts[avroSchema]; ok {
+				if !format.Avro.BinaryRecords {
+					return pgerror.Newf(pgcode.Syntax,
+						"%q can only be used with %q: object container files embed their schema",
+						avroSchema, avroBinaryRecords)
+				}
+				// Check the schema now rather than when reading each file.
+				if _, err := goavro.NewCodec(override); err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid %q value", avroSchema)
+				}
+				format.Avro.SchemaJSON = override
+			} else if format.Avro.BinaryRecords {
+				return pgerror.Newf(pgcode.Syntax, "%q requires %q", avroBinaryRecords, avroSchema)
+			}
+			maxRecordSize := int32(defaultScanBuffer)
+			if override, ok := opts[pgMaxRowSize]; ok {
+				sz, err := humanizeutil.ParseBytes(override)
+				if err != nil {
+					return err
+				}
+				if sz < 1 || sz > math.MaxInt32 {
+					return errors.Errorf("%s out of range: %d", pgMaxRowSize, sz)
+				}
+				maxRecordSize = int32(sz)
+			}
+			format.Avro.MaxRecordSize = maxRecordSize
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -360,6 +460,33 @@ func importPlanHook(
 			skipFKs = true
 		}
 
+		_, ignoreUnsupported := opts[importOptionIgnoreUnsupported]
+		if ignoreUnsupported && format.Format != roachpb.IOFileFormat_PgDump &&
+			format.Format != roachpb.IOFileFormat_Mysqldump {
+			return pgerror.Newf(pgcode.Syntax,
+				"%q can only be used when importing a PGDUMP or MYSQLDUMP file", importOptionIgnoreUnsupported)
+		}
+		var matchTables map[string]bool
+		if override, ok := opts[importOptionTables]; ok {
+			if !importStmt.Bundle {
+				return pgerror.Newf(pgcode.Syntax,
+					"%q can only be used when importing a PGDUMP or MYSQLDUMP file", importOptionTables)
+			}
+			if table != nil {
+				return pgerror.Newf(pgcode.Syntax,
+					"%q cannot be used with IMPORT TABLE", importOptionTables)
+			}
+			matchTables = make(map[string]bool)
+			for _, name := range strings.Split(override, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					matchTables[name] = true
+				}
+			}
+			if len(matchTables) == 0 {
+				return pgerror.Newf(pgcode.Syntax, "%q requires at least one table name", importOptionTables)
+			}
+		}
+
 		if override, ok := opts[importOptionDecompress]; ok {
 			found := false
 			for name, value := range roachpb.IOFileFormat_Compression_value {
@@ -458,19 +585,24 @@ func importPlanHook(
 				}
 				defer reader.Close()
 
-				var match string
+				match := matchTables
 				if table != nil {
-					match = table.TableName.String()
+					match = map[string]bool{table.TableName.String(): true}
 				}
 
 				fks := fkHandler{skip: skipFKs, allowed: true, resolver: make(fkResolver)}
 				switch format.Format {
 				case roachpb.IOFileFormat_Mysqldump:
 					evalCtx := &p.ExtendedEvalContext().EvalContext
-					tableDescs, err = readMysqlCreateTable(ctx, reader, evalCtx, defaultCSVTableID, parentID, match, fks, seqVals)
+					tableDescs, err = readMysqlCreateTable(
+						ctx, reader, evalCtx, defaultCSVTableID, parentID, match, fks, seqVals, ignoreUnsupported,
+					)
 				case roachpb.IOFileFormat_PgDump:
 					evalCtx := &p.ExtendedEvalContext().EvalContext
-					tableDescs, err = readPostgresCreateTable(reader, evalCtx, p.ExecCfg().Settings, match, parentID, walltime, fks, int(format.PgDump.MaxRowSize))
+					tableDescs, err = readPostgresCreateTable(
+						ctx, reader, evalCtx, p.ExecCfg().Settings, match, parentID, walltime, fks,
+						int(format.PgDump.MaxRowSize), ignoreUnsupported,
+					)
 				default:
 					return errors.Errorf("non-bundle format %q does not support reading schemas", format.Format.String())
 				}
@@ -705,7 +837,9 @@ func prepareNewTableDescsForIngestion(
 	// Write the new TableDescriptors and flip the namespace entries over to
 	// them. After this call, any queries on a table will be served by the newly
 	// imported data.
-	if err := backupccl.WriteTableDescs(ctx, txn, nil, tableDescs, p.User(), p.ExecCfg().Settings, seqValKVs); err != nil {
+	if err := backupccl.WriteTableDescs(
+		ctx, txn, nil, tableDescs, tree.RequestedDescriptors, p.User(), p.ExecCfg().Settings, seqValKVs,
+	); err != nil {
 		return nil, errors.Wrapf(err, "creating tables")
 	}
 
@@ -1084,6 +1218,7 @@ func (r *importResumer) OnTerminal(
 var _ jobs.Resumer = &importResumer{}
 
 func init() {
+	build.RegisterFeature("import")
 	sql.AddPlanHook(importPlanHook)
 	jobs.RegisterConstructor(
 		jobspb.TypeImport,
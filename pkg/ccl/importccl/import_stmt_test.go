@@ -13,10 +13,13 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -44,6 +47,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -544,6 +549,78 @@ COPY t (a, b, c) FROM stdin;
 				`SHOW TABLES`: {{"weather"}},
 			},
 		},
+		{
+			name: "fk before referenced table",
+			typ:  "PGDUMP",
+			data: `
+				CREATE TABLE weather (city STRING REFERENCES cities (city), temp_lo INT8);
+				CREATE TABLE cities (city STRING PRIMARY KEY);
+			`,
+			query: map[string][][]string{
+				`SHOW TABLES`: {{"cities"}, {"weather"}},
+				`SELECT count(*) FROM crdb_internal.backward_dependencies`: {{"1"}},
+			},
+		},
+		{
+			name: "tables",
+			typ:  "PGDUMP",
+			data: testPgdumpFk,
+			with: `WITH tables = 'cities'`,
+			query: map[string][][]string{
+				`SHOW TABLES`:             {{"cities"}},
+				`SELECT city FROM cities`: {{"Berkeley"}},
+			},
+		},
+		{
+			name: "tables fk unreferenced",
+			typ:  "PGDUMP",
+			data: testPgdumpFk,
+			with: `WITH tables = 'weather'`,
+			err:  `table "cities" not found`,
+		},
+		{
+			name: "tables fk unreferenced skipped",
+			typ:  "PGDUMP",
+			data: testPgdumpFk,
+			with: `WITH tables = 'weather', skip_foreign_keys`,
+			query: map[string][][]string{
+				`SHOW TABLES`:              {{"weather"}},
+				`SELECT city FROM weather`: {{"Berkeley"}},
+			},
+		},
+		{
+			name: "tables not found",
+			typ:  "PGDUMP",
+			data: testPgdumpFk,
+			with: `WITH tables = 'cities, nope'`,
+			err:  `table "nope" not found in file \(found tables: cities, weather\)`,
+		},
+		{
+			name: "unsupported statement",
+			typ:  "PGDUMP",
+			data: `
+				CREATE TABLE t (i INT8 NOT NULL);
+				ALTER TABLE t ALTER COLUMN i DROP NOT NULL;
+			`,
+			err: `unsupported statement: ALTER TABLE t ALTER COLUMN i DROP NOT NULL`,
+		},
+		{
+			name: "unsupported statement ignored",
+			typ:  "PGDUMP",
+			data: `
+				CREATE TABLE t (i INT8 NOT NULL);
+				ALTER TABLE t ALTER COLUMN i DROP NOT NULL;
+				CREATE TYPE mood AS ENUM ('sad', 'ok');
+				COPY t (i) FROM stdin;
+1
+\.
+			`,
+			with: `WITH ignore_unsupported`,
+			query: map[string][][]string{
+				`SHOW TABLES`:     {{"t"}},
+				`SELECT i FROM t`: {{"1"}},
+			},
+		},
 		{
 			name: "sequence",
 			typ:  "PGDUMP",
@@ -1209,6 +1286,50 @@ func TestImportCSVStmt(t *testing.T) {
 // -> FK and constraint violation
 // -> CSV containing keys which will shadow existing data
 // -> Rollback of a failed IMPORT INTO
+func TestImportCSVMaxRowErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	// Rows 3 and 4 are malformed: one fails to parse and one has a missing
+	// field.
+	data := "1,a\n2,b\nx,c\n3\n4,d\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "data.csv"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	// Either malformed row may be found first.
+	sqlDB.ExpectErr(t, `row [34]: `,
+		`IMPORT TABLE t1 (a INT8 PRIMARY KEY, b STRING) CSV DATA ('nodelocal:///data.csv')`)
+	sqlDB.ExpectErr(t, `more than 1 malformed rows`,
+		`IMPORT TABLE t2 (a INT8 PRIMARY KEY, b STRING) CSV DATA ('nodelocal:///data.csv')
+		WITH max_row_errors = '1'`)
+	sqlDB.ExpectErr(t, `save_rejected requires max_row_errors`,
+		`IMPORT TABLE t3 (a INT8 PRIMARY KEY, b STRING) CSV DATA ('nodelocal:///data.csv')
+		WITH save_rejected`)
+
+	sqlDB.Exec(t, `IMPORT TABLE t4 (a INT8 PRIMARY KEY, b STRING) CSV DATA ('nodelocal:///data.csv')
+		WITH max_row_errors = '2', save_rejected`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM t4 ORDER BY a`,
+		[][]string{{"1", "a"}, {"2", "b"}, {"4", "d"}})
+
+	rejected, err := ioutil.ReadFile(filepath.Join(dir, "data.csv.rejected"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rows are rejected concurrently, in no particular order.
+	lines := strings.Split(strings.TrimSpace(string(rejected)), "\n")
+	sort.Strings(lines)
+	if expected := []string{"3", "x,c"}; !reflect.DeepEqual(expected, lines) {
+		t.Fatalf("expected rejected rows %q, got %q", expected, lines)
+	}
+}
+
 func TestImportIntoCSV(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -2728,6 +2849,101 @@ func TestImportPgCopy(t *testing.T) {
 	}
 }
 
+func TestImportAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	const schema = `{
+		"type": "record",
+		"name": "account",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "name", "type": ["null", "string"]},
+			{"name": "created", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+			{"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+			{"name": "extra", "type": "int"}
+		]
+	}`
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numRows = 10
+	var records []interface{}
+	var binary []byte
+	for i := 0; i < numRows; i++ {
+		record := map[string]interface{}{
+			"id":      int64(i),
+			"name":    goavro.Union("string", fmt.Sprintf("account %d", i)),
+			"created": timeutil.Unix(int64(i), 0),
+			"balance": big.NewRat(int64(i*100+5), 100),
+			"extra":   int32(i),
+		}
+		if i == 0 {
+			record["name"] = nil
+		}
+		records = append(records, record)
+		if binary, err = codec.BinaryFromNative(binary, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "data.bin"), binary, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var ocf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &ocf, Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Append(records); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "data.avro"), ocf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const tableDef = `(id INT8 PRIMARY KEY, name STRING, created TIMESTAMP, balance DECIMAL(10,2))`
+	expected := make([][]string, numRows)
+	for i := range expected {
+		name := fmt.Sprintf("account %d", i)
+		if i == 0 {
+			name = "NULL"
+		}
+		expected[i] = []string{fmt.Sprint(i), name, fmt.Sprint(i), fmt.Sprintf("%d.05", i)}
+	}
+	const query = `SELECT id, name, extract(epoch FROM created)::INT8, balance FROM %s ORDER BY id`
+
+	t.Run("ocf", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE ocf `+tableDef+` AVRO DATA ('nodelocal:///data.avro')`)
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(query, "ocf"), expected)
+	})
+
+	t.Run("binary-records", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE bin `+tableDef+` AVRO DATA ('nodelocal:///data.bin')
+			WITH data_as_binary_records, schema = $1`, schema)
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(query, "bin"), expected)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `"data_as_binary_records" requires "schema"`,
+			`IMPORT TABLE err1 `+tableDef+` AVRO DATA ('nodelocal:///data.bin') WITH data_as_binary_records`)
+		sqlDB.ExpectErr(t, `"schema" can only be used with "data_as_binary_records"`,
+			`IMPORT TABLE err2 `+tableDef+` AVRO DATA ('nodelocal:///data.avro') WITH schema = $1`, schema)
+		sqlDB.ExpectErr(t, `avro field "extra" does not match any column`,
+			`IMPORT TABLE err3 `+tableDef+` AVRO DATA ('nodelocal:///data.avro') WITH strict_validation`)
+		sqlDB.ExpectErr(t, `avro schema has no field for non-nullable column "missing"`,
+			`IMPORT TABLE err4 (id INT8 PRIMARY KEY, missing INT8 NOT NULL) AVRO DATA ('nodelocal:///data.avro')
+			WITH strict_validation`)
+	})
+}
+
 func TestImportPgDump(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -0,0 +1,432 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro"
+)
+
+// avroInputReader reads avro records, either from object container files,
+// which embed the schema of their records, or from a stream of schemaless
+// binary records whose schema is given as an option.
+//
+// The fields of the records are mapped to the columns of the table by name.
+// The avro library decodes logical types to time.Time, time.Duration or
+// *big.Rat, which are converted to the type of their column.
+type avroInputReader struct {
+	conv row.DatumRowConverter
+	opts roachpb.AvroOptions
+}
+
+var _ inputConverter = &avroInputReader{}
+
+func newAvroInputReader(
+	kvCh chan []roachpb.KeyValue,
+	opts roachpb.AvroOptions,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*avroInputReader, error) {
+	conv, err := row.NewDatumRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &avroInputReader{
+		conv: *conv,
+		opts: opts,
+	}, nil
+}
+
+func (a *avroInputReader) start(ctx ctxgroup.Group) {
+}
+
+func (a *avroInputReader) inputFinished(ctx context.Context) {
+	close(a.conv.KvCh)
+}
+
+func (a *avroInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	format roachpb.IOFileFormat,
+	progressFn func(float32) error,
+	settings *cluster.Settings,
+) error {
+	return readInputFiles(ctx, dataFiles, format, a.readFile, progressFn, settings)
+}
+
+// avroRecordStream is a source of decoded avro records.
+type avroRecordStream interface {
+	// next returns the next record, or io.EOF once all were read.
+	next() (interface{}, error)
+}
+
+// avroOCFStream reads the records of an object container file.
+type avroOCFStream struct {
+	ocf *goavro.OCFReader
+}
+
+func (s *avroOCFStream) next() (interface{}, error) {
+	if !s.ocf.Scan() {
+		if err := s.ocf.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return s.ocf.Read()
+}
+
+// avroBinaryStream reads a sequence of schemaless binary records. Records are
+// not delimited, so it buffers the input until a whole record can be decoded.
+type avroBinaryStream struct {
+	r             io.Reader
+	codec         *goavro.Codec
+	maxRecordSize int
+	buf           []byte
+	eof           bool
+}
+
+const avroBinaryStreamReadSize = 64 << 10
+
+func (s *avroBinaryStream) next() (interface{}, error) {
+	for {
+		if len(s.buf) > 0 {
+			native, rest, err := s.codec.NativeFromBinary(s.buf)
+			if err == nil {
+				s.buf = rest
+				return native, nil
+			}
+			// The error may be caused by a record split across reads: only give up
+			// once there is no more input, or the record is too large.
+			if s.eof {
+				return nil, err
+			}
+			if len(s.buf) >= s.maxRecordSize {
+				return nil, errors.Wrapf(err, "record larger than the maximum of %d bytes", s.maxRecordSize)
+			}
+		} else if s.eof {
+			return nil, io.EOF
+		}
+
+		chunk := make([]byte, avroBinaryStreamReadSize)
+		n, err := s.r.Read(chunk)
+		s.buf = append(s.buf, chunk[:n]...)
+		if err == io.EOF {
+			s.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// avroField is the part of the schema of a record field needed to map it to a
+// column.
+type avroField struct {
+	Name string          `json:"name"`
+	Type json.RawMessage `json:"type"`
+}
+
+// avroColumn maps a record field to the datum of a column.
+type avroColumn struct {
+	field string
+	// union is set if the field is a union, whose values the avro library wraps
+	// in a map keyed by the name of their type.
+	union bool
+	// datumIdx is the index of the column in the converted datums.
+	datumIdx int
+	col      *sqlbase.ColumnDescriptor
+	typ      *types.T
+}
+
+// makeAvroColumns maps the fields of the records described by the given
+// schema to the target columns of the converter.
+func makeAvroColumns(
+	schemaJSON string, conv *row.DatumRowConverter, strict bool,
+) ([]avroColumn, error) {
+	var schema struct {
+		Type   string      `json:"type"`
+		Fields []avroField `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, errors.Wrap(err, "parsing avro schema")
+	}
+	if schema.Type != "record" {
+		return nil, errors.Errorf("expected avro schema of type record, got %q", schema.Type)
+	}
+
+	fieldByName := make(map[string]avroField, len(schema.Fields))
+	for _, f := range schema.Fields {
+		fieldByName[strings.ToLower(f.Name)] = f
+	}
+
+	var cols []avroColumn
+	datumIdx := 0
+	for i := range conv.VisibleCols {
+		if _, ok := conv.IsTargetCol[i]; !ok {
+			continue
+		}
+		col := &conv.VisibleCols[i]
+		f, ok := fieldByName[strings.ToLower(col.Name)]
+		if ok {
+			delete(fieldByName, strings.ToLower(col.Name))
+			cols = append(cols, avroColumn{
+				field:    f.Name,
+				union:    strings.HasPrefix(strings.TrimSpace(string(f.Type)), "["),
+				datumIdx: datumIdx,
+				col:      col,
+				typ:      conv.VisibleColTypes[i],
+			})
+		} else if strict && !col.Nullable {
+			return nil, errors.Errorf("avro schema has no field for non-nullable column %q", col.Name)
+		}
+		datumIdx++
+	}
+	if strict {
+		for _, f := range schema.Fields {
+			if _, ok := fieldByName[strings.ToLower(f.Name)]; ok {
+				return nil, errors.Errorf("avro field %q does not match any column", f.Name)
+			}
+		}
+	}
+	return cols, nil
+}
+
+func (a *avroInputReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	var stream avroRecordStream
+	var schemaJSON string
+	if a.opts.BinaryRecords {
+		codec, err := goavro.NewCodec(a.opts.SchemaJSON)
+		if err != nil {
+			return errors.Wrap(err, "parsing avro schema")
+		}
+		schemaJSON = a.opts.SchemaJSON
+		stream = &avroBinaryStream{r: input, codec: codec, maxRecordSize: int(a.opts.MaxRecordSize)}
+	} else {
+		ocf, err := goavro.NewOCFReader(input)
+		if err != nil {
+			return errors.Wrapf(err, "%q: reading avro object container file", inputName)
+		}
+		schemaJSON = ocf.Codec().Schema()
+		stream = &avroOCFStream{ocf: ocf}
+	}
+
+	cols, err := makeAvroColumns(schemaJSON, &a.conv, a.opts.StrictMode)
+	if err != nil {
+		return errors.Wrapf(err, "%q", inputName)
+	}
+
+	for count := int64(1); ; count++ {
+		native, err := stream.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Syntax, "decoding avro record")
+		}
+		record, ok := native.(map[string]interface{})
+		if !ok {
+			return makeRowErr(inputName, count, pgcode.Syntax,
+				"expected avro record, got %T", native)
+		}
+
+		for i := range a.conv.Datums {
+			a.conv.Datums[i] = tree.DNull
+		}
+		for _, c := range cols {
+			v := record[c.field]
+			if c.union {
+				v = unwrapAvroUnion(v)
+			}
+			a.conv.Datums[c.datumIdx], err = avroNativeToDatum(v, c.typ, a.conv.EvalCtx)
+			if err != nil {
+				return wrapRowErr(err, inputName, count, pgcode.Syntax,
+					"convert %q to %s", c.col.Name, c.col.Type.SQLString())
+			}
+		}
+
+		if err := a.conv.Row(ctx, inputIdx, count); err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+		}
+	}
+
+	return a.conv.SendBatch(ctx)
+}
+
+// unwrapAvroUnion returns the value of a union, which the avro library
+// decodes as nil or as a map from the name of its type to its value.
+func unwrapAvroUnion(v interface{}) interface{} {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 1 {
+		for _, inner := range m {
+			return inner
+		}
+	}
+	return v
+}
+
+// avroNativeToDatum converts a value decoded by the avro library to a datum
+// of the given type. Values whose avro type does not directly map to the type
+// of their column are parsed from their string representation.
+func avroNativeToDatum(v interface{}, typ *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	switch x := v.(type) {
+	case nil:
+		return tree.DNull, nil
+	case bool:
+		if typ.Family() == types.BoolFamily {
+			return tree.MakeDBool(tree.DBool(x)), nil
+		}
+		return tree.ParseDatumStringAs(typ, strconv.FormatBool(x), evalCtx)
+	case int32:
+		return avroNativeToDatum(int64(x), typ, evalCtx)
+	case int64:
+		switch typ.Family() {
+		case types.IntFamily:
+			return tree.NewDInt(tree.DInt(x)), nil
+		case types.FloatFamily:
+			return tree.NewDFloat(tree.DFloat(x)), nil
+		case types.DecimalFamily:
+			d := &tree.DDecimal{}
+			d.SetFinite(x, 0)
+			return d, nil
+		}
+		return tree.ParseDatumStringAs(typ, strconv.FormatInt(x, 10), evalCtx)
+	case float32:
+		return avroNativeToDatum(float64(x), typ, evalCtx)
+	case float64:
+		if typ.Family() == types.FloatFamily {
+			return tree.NewDFloat(tree.DFloat(x)), nil
+		}
+		return tree.ParseDatumStringAs(typ, strconv.FormatFloat(x, 'g', -1, 64), evalCtx)
+	case string:
+		if typ.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(x)), nil
+		}
+		return tree.ParseDatumStringAs(typ, x, evalCtx)
+	case []byte:
+		switch typ.Family() {
+		case types.BytesFamily:
+			return tree.NewDBytes(tree.DBytes(x)), nil
+		case types.UuidFamily:
+			if len(x) == 16 {
+				return tree.ParseDUuidFromBytes(x)
+			}
+		}
+		return tree.ParseDatumStringAs(typ, string(x), evalCtx)
+	case time.Time:
+		switch typ.Family() {
+		case types.DateFamily:
+			return tree.NewDDateFromTime(x)
+		case types.TimestampFamily:
+			return tree.MakeDTimestamp(x, time.Microsecond), nil
+		case types.TimestampTZFamily:
+			return tree.MakeDTimestampTZ(x, time.Microsecond), nil
+		case types.TimeFamily:
+			return tree.MakeDTime(timeofday.FromTime(x)), nil
+		}
+		return tree.ParseDatumStringAs(typ, x.Format(time.RFC3339Nano), evalCtx)
+	case time.Duration:
+		switch typ.Family() {
+		case types.TimeFamily:
+			return tree.MakeDTime(timeofday.TimeOfDay(x / time.Microsecond)), nil
+		case types.IntervalFamily:
+			return &tree.DInterval{Duration: duration.MakeDuration(x.Nanoseconds(), 0, 0)}, nil
+		}
+	case *big.Rat:
+		switch typ.Family() {
+		case types.DecimalFamily:
+			return avroRatToDecimal(x)
+		case types.FloatFamily:
+			f, _ := x.Float64()
+			return tree.NewDFloat(tree.DFloat(f)), nil
+		}
+		d, err := avroRatToDecimal(x)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ParseDatumStringAs(typ, d.String(), evalCtx)
+	case []interface{}:
+		if typ.Family() == types.ArrayFamily {
+			arr := tree.NewDArray(typ.ArrayContents())
+			for _, elem := range x {
+				d, err := avroNativeToDatum(unwrapAvroUnion(elem), typ.ArrayContents(), evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+		if typ.Family() == types.JsonFamily {
+			return avroNativeToJSON(x)
+		}
+	case map[string]interface{}:
+		if typ.Family() == types.JsonFamily {
+			return avroNativeToJSON(x)
+		}
+	}
+	return nil, errors.Errorf("cannot convert avro value of type %T to %s", v, typ.SQLString())
+}
+
+func avroNativeToJSON(v interface{}) (tree.Datum, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return tree.ParseDJSON(string(encoded))
+}
+
+// avroRatToDecimal converts the value of an avro decimal to a decimal datum.
+// Since the unscaled value of an avro decimal is an integer and its scale a
+// power of ten, the denominator of the value only has the factors 2 and 5 and
+// the value is exactly representable as a decimal.
+func avroRatToDecimal(r *big.Rat) (*tree.DDecimal, error) {
+	// The scale is the largest of the powers of 2 and 5 in the denominator.
+	denom := new(big.Int).Set(r.Denom())
+	var scale int32
+	for _, factor := range []int64{2, 5} {
+		f := big.NewInt(factor)
+		var power int32
+		for m := new(big.Int); m.Mod(denom, f).Sign() == 0; power++ {
+			denom.Quo(denom, f)
+		}
+		if power > scale {
+			scale = power
+		}
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.Errorf("avro decimal %s has no exact decimal representation", r.String())
+	}
+	// The unscaled value is num * 10^scale / denom.
+	coeff := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	coeff.Mul(coeff, r.Num())
+	coeff.Quo(coeff, r.Denom())
+	return &tree.DDecimal{Decimal: *apd.NewWithBigInt(coeff, -scale)}, nil
+}
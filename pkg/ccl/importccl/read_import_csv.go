@@ -9,11 +9,13 @@
 package importccl
 
 import (
+	"bytes"
 	"context"
 	"io"
-	"runtime"
+	"net/url"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -22,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
@@ -37,6 +41,9 @@ type csvInputReader struct {
 	tableDesc    *sqlbase.TableDescriptor
 	targetCols   tree.NameList
 	expectedCols int
+	rowErrors    *csvRowErrors
+	settings     *cluster.Settings
+	workers      *conversionWorkers
 }
 
 var _ inputConverter = &csvInputReader{}
@@ -48,6 +55,7 @@ func newCSVInputReader(
 	tableDesc *sqlbase.TableDescriptor,
 	targetCols tree.NameList,
 	evalCtx *tree.EvalContext,
+	settings *cluster.Settings,
 ) *csvInputReader {
 	return &csvInputReader{
 		evalCtx:      evalCtx,
@@ -59,6 +67,9 @@ func newCSVInputReader(
 		targetCols:   targetCols,
 		recordCh:     make(chan csvRecord),
 		batchSize:    500,
+		rowErrors:    newCSVRowErrors(opts),
+		settings:     settings,
+		workers:      newConversionWorkers(&settings.SV),
 	}
 }
 
@@ -68,9 +79,12 @@ func (c *csvInputReader) start(group ctxgroup.Group) {
 		defer tracing.FinishSpan(span)
 
 		defer close(c.kvCh)
-		return ctxgroup.GroupWorkers(ctx, runtime.NumCPU(), func(ctx context.Context) error {
+		if err := ctxgroup.GroupWorkers(ctx, c.workers.count(), func(ctx context.Context) error {
 			return c.convertRecordWorker(ctx)
-		})
+		}); err != nil {
+			return err
+		}
+		return c.rowErrors.writeRejected(ctx, c.settings)
 	})
 }
 
@@ -137,7 +151,14 @@ func (c *csvInputReader) readFile(
 			break
 		}
 		if err != nil {
-			return errors.Wrapf(err, "row %d: reading CSV record", i)
+			err = errors.Wrapf(err, "row %d: reading CSV record", i)
+			if err := c.rowErrors.add(inputName, record, err); err != nil {
+				return err
+			}
+			// Keep the place of the row so that the rows of the batch keep their
+			// row numbers.
+			c.batch.r = append(c.batch.r, nil)
+			continue
 		}
 		// Ignore the first N lines.
 		if uint32(i) <= c.opts.Skip {
@@ -149,7 +170,14 @@ func (c *csvInputReader) readFile(
 			// Line has the optional trailing comma, ignore the empty field.
 			record = record[:c.expectedCols]
 		} else {
-			return errors.Errorf("row %d: expected %d fields, got %d", i, c.expectedCols, len(record))
+			err := errors.Errorf("row %d: expected %d fields, got %d", i, c.expectedCols, len(record))
+			if err := c.rowErrors.add(inputName, record, err); err != nil {
+				return err
+			}
+			// Keep the place of the row so that the rows of the batch keep their
+			// row numbers.
+			c.batch.r = append(c.batch.r, nil)
+			continue
 		}
 		c.batch.r = append(c.batch.r, record)
 	}
@@ -181,34 +209,148 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 	timestamp := uint64(c.walltime) / precision
 
 	for batch := range c.recordCh {
-		for batchIdx, record := range batch.r {
-			rowNum := int64(batch.rowOffset + batchIdx)
-			datumIdx := 0
-			for i, v := range record {
-				// Skip over record entries corresponding to columns not in the target
-				// columns specified by the user.
-				if _, ok := conv.IsTargetCol[i]; !ok {
-					continue
-				}
-				col := conv.VisibleCols[i]
-				if c.opts.NullEncoding != nil && v == *c.opts.NullEncoding {
-					conv.Datums[datumIdx] = tree.DNull
-				} else {
-					var err error
-					conv.Datums[datumIdx], err = tree.ParseDatumStringAs(conv.VisibleColTypes[i], v, conv.EvalCtx)
-					if err != nil {
-						return wrapRowErr(err, batch.file, rowNum, pgcode.Syntax,
-							"parse %q as %s", col.Name, col.Type.SQLString())
+		// Hold a conversion slot while converting each batch, so the number of
+		// busy workers follows the kv.import.conversion_concurrency setting.
+		if err := c.workers.begin(ctx); err != nil {
+			return err
+		}
+		err := c.convertBatch(ctx, conv, batch, timestamp)
+		c.workers.finish()
+		if err != nil {
+			return err
+		}
+	}
+	return conv.SendBatch(ctx)
+}
+
+// convertBatch converts a batch of CSV records into KV pairs.
+func (c *csvInputReader) convertBatch(
+	ctx context.Context, conv *row.DatumRowConverter, batch csvRecord, timestamp uint64,
+) error {
+records:
+	for batchIdx, record := range batch.r {
+		if record == nil {
+			// A malformed row which was skipped.
+			continue
+		}
+		rowNum := int64(batch.rowOffset + batchIdx)
+		datumIdx := 0
+		for i, v := range record {
+			// Skip over record entries corresponding to columns not in the target
+			// columns specified by the user.
+			if _, ok := conv.IsTargetCol[i]; !ok {
+				continue
+			}
+			col := conv.VisibleCols[i]
+			if c.opts.NullEncoding != nil && v == *c.opts.NullEncoding {
+				conv.Datums[datumIdx] = tree.DNull
+			} else {
+				var err error
+				conv.Datums[datumIdx], err = tree.ParseDatumStringAs(conv.VisibleColTypes[i], v, conv.EvalCtx)
+				if err != nil {
+					err = wrapRowErr(err, batch.file, rowNum, pgcode.Syntax,
+						"parse %q as %s", col.Name, col.Type.SQLString())
+					if err := c.rowErrors.add(batch.file, record, err); err != nil {
+						return err
 					}
+					continue records
 				}
-				datumIdx++
 			}
+			datumIdx++
+		}
 
-			rowIndex := int64(timestamp) + rowNum
-			if err := conv.Row(ctx, batch.fileIndex, rowIndex); err != nil {
-				return wrapRowErr(err, batch.file, rowNum, pgcode.Uncategorized, "")
+		rowIndex := int64(timestamp) + rowNum
+		if err := conv.Row(ctx, batch.fileIndex, rowIndex); err != nil {
+			err = wrapRowErr(err, batch.file, rowNum, pgcode.Uncategorized, "")
+			if err := c.rowErrors.add(batch.file, record, err); err != nil {
+				return err
 			}
 		}
 	}
-	return conv.SendBatch(ctx)
+	return nil
+}
+
+// csvRowErrors tracks the malformed rows of the input files of a CSV import,
+// which are skipped up to a maximum number per file and optionally saved to be
+// written to a rejected file next to their input file.
+type csvRowErrors struct {
+	max   int64
+	save  bool
+	comma rune
+
+	mu struct {
+		syncutil.Mutex
+		countByFile    map[string]int64
+		rejectedByFile map[string]*bytes.Buffer
+	}
+}
+
+func newCSVRowErrors(opts roachpb.CSVOptions) *csvRowErrors {
+	e := &csvRowErrors{max: opts.MaxRowErrors, save: opts.SaveRejected, comma: opts.Comma}
+	e.mu.countByFile = make(map[string]int64)
+	e.mu.rejectedByFile = make(map[string]*bytes.Buffer)
+	return e
+}
+
+// add records the error of a malformed row of the given input file. It
+// returns the error if the file has more malformed rows than tolerated, in
+// which case the import must fail, and nil if the row must be skipped.
+func (e *csvRowErrors) add(file string, record []string, err error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mu.countByFile[file]++
+	if e.mu.countByFile[file] > e.max {
+		if e.max > 0 {
+			return errors.Wrapf(err, "more than %d malformed rows", e.max)
+		}
+		return err
+	}
+	if !e.save {
+		return nil
+	}
+	buf, ok := e.mu.rejectedByFile[file]
+	if !ok {
+		buf = &bytes.Buffer{}
+		e.mu.rejectedByFile[file] = buf
+	}
+	w := csv.NewWriter(buf)
+	if e.comma != 0 {
+		w.Comma = e.comma
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeRejected writes the saved malformed rows of each input file to a file
+// named after it with a ".rejected" suffix.
+func (e *csvRowErrors) writeRejected(ctx context.Context, settings *cluster.Settings) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var skipped int64
+	for _, count := range e.mu.countByFile {
+		skipped += count
+	}
+	if skipped > 0 {
+		log.Warningf(ctx, "skipped %d malformed rows", skipped)
+	}
+	for file, buf := range e.mu.rejectedByFile {
+		uri, err := url.Parse(file)
+		if err != nil {
+			return err
+		}
+		uri.Path += ".rejected"
+		store, err := storageccl.ExportStorageFromURI(ctx, uri.String(), settings)
+		if err != nil {
+			return err
+		}
+		err = store.WriteFile(ctx, "", bytes.NewReader(buf.Bytes()))
+		store.Close()
+		if err != nil {
+			return errors.Wrap(err, "writing rejected rows")
+		}
+	}
+	return nil
 }
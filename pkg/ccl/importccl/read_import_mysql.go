@@ -254,28 +254,37 @@ func mysqlValueToDatum(
 
 // readMysqlCreateTable parses mysql-dialect SQL from input to extract table
 // definitions and return them as Cockroach's TableDescriptors. If `match` is
-// non-empty, only the table with that name is returned and an error is returned
-// if a matching table is not found in the input. Otherwise, if match is empty,
-// all tables encountered are returned (or an error is returned if no tables are
-// found). Returned tables are given dummy, placeholder IDs -- it is up to the
-// caller to allocate and assign real IDs.
+// non-empty, only the tables with those names are returned and an error is
+// returned if any of them is not found in the input. Otherwise, if match is
+// empty, all tables encountered are returned (or an error is returned if no
+// tables are found). Returned tables are given dummy, placeholder IDs -- it is
+// up to the caller to allocate and assign real IDs. Foreign keys are only
+// resolved once every table has been read.
 func readMysqlCreateTable(
 	ctx context.Context,
 	input io.Reader,
 	evalCtx *tree.EvalContext,
 	startingID, parentID sqlbase.ID,
-	match string,
+	match map[string]bool,
 	fks fkHandler,
 	seqVals map[sqlbase.ID]int64,
+	ignoreUnsupported bool,
 ) ([]*sqlbase.TableDescriptor, error) {
-	match = lex.NormalizeName(match)
+	if match != nil {
+		normalized := make(map[string]bool, len(match))
+		for name := range match {
+			normalized[lex.NormalizeName(name)] = true
+		}
+		match = normalized
+	}
+	unsupported := makeUnsupportedHandler(ctx, ignoreUnsupported)
 	r := bufio.NewReaderSize(input, 1024*64)
 	tokens := mysql.NewTokenizer(r)
 	tokens.SkipSpecialComments = true
 
 	var ret []*sqlbase.TableDescriptor
 	var fkDefs []delayedFK
-	var found bool
+	found := make(map[string]bool)
 	var names []string
 	for {
 		stmt, err := mysql.ParseNextStrictDDL(tokens)
@@ -290,19 +299,21 @@ func readMysqlCreateTable(
 		}
 		if i, ok := stmt.(*mysql.DDL); ok && i.Action == mysql.CreateStr {
 			name := safeString(i.NewName.Name)
-			if match != "" && match != name {
-				names = append(names, name)
+			names = append(names, name)
+			if match != nil && !match[name] {
 				continue
 			}
 			id := sqlbase.ID(int(startingID) + len(ret))
-			tbl, moreFKs, err := mysqlTableToCockroach(ctx, evalCtx, parentID, id, name, i.TableSpec, fks, seqVals)
+			tbl, moreFKs, err := mysqlTableToCockroach(
+				ctx, evalCtx, parentID, id, name, i.TableSpec, fks, seqVals, unsupported,
+			)
 			if err != nil {
 				return nil, err
 			}
 			fkDefs = append(fkDefs, moreFKs...)
 			ret = append(ret, tbl...)
-			if match == name {
-				found = true
+			found[name] = true
+			if match != nil && len(found) == len(match) {
 				break
 			}
 		}
@@ -310,8 +321,10 @@ func readMysqlCreateTable(
 	if ret == nil {
 		return nil, errors.Errorf("no table definitions found")
 	}
-	if match != "" && !found {
-		return nil, errors.Errorf("table %q not found in file (found tables: %s)", match, strings.Join(names, ", "))
+	for _, name := range sortedNames(match) {
+		if !found[name] {
+			return nil, errors.Errorf("table %q not found in file (found tables: %s)", name, strings.Join(names, ", "))
+		}
 	}
 	if err := addDelayedFKs(ctx, fkDefs, fks.resolver); err != nil {
 		return nil, err
@@ -340,6 +353,7 @@ func mysqlTableToCockroach(
 	in *mysql.TableSpec,
 	fks fkHandler,
 	seqVals map[sqlbase.ID]int64,
+	unsupported unsupportedHandler,
 ) ([]*sqlbase.TableDescriptor, []delayedFK, error) {
 	if in == nil {
 		return nil, nil, errors.Errorf("could not read definition for table %q (possible unsupported type?)", name)
@@ -394,7 +408,7 @@ func mysqlTableToCockroach(
 	checks := make(map[string]*tree.CheckConstraintTableDef)
 
 	for _, raw := range in.Columns {
-		def, err := mysqlColToCockroach(safeString(raw.Name), raw.Type, checks)
+		def, err := mysqlColToCockroach(safeString(raw.Name), raw.Type, checks, unsupported)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -524,7 +538,10 @@ func toNameList(cols mysql.Columns) tree.NameList {
 // even if they have only cosmetic (i.e. when viewing schemas) effects compared
 // to their behavior in MySQL.
 func mysqlColToCockroach(
-	name string, col mysql.ColumnType, checks map[string]*tree.CheckConstraintTableDef,
+	name string,
+	col mysql.ColumnType,
+	checks map[string]*tree.CheckConstraintTableDef,
+	unsupported unsupportedHandler,
 ) (*tree.ColumnTableDef, error) {
 	def := &tree.ColumnTableDef{Name: tree.Name(name)}
 
@@ -654,9 +671,16 @@ func mysqlColToCockroach(
 		} else {
 			expr, err := parser.ParseExpr(exprString)
 			if err != nil {
-				return nil, unimplemented.Newf("import.mysql.default", "unsupported default expression %q for column %q: %v", exprString, name, err)
+				// The column is still imported, just without its default.
+				if err := unsupported(
+					fmt.Sprintf("DEFAULT %s", exprString),
+					unimplemented.Newf("import.mysql.default", "unsupported default expression %q for column %q: %v", exprString, name, err),
+				); err != nil {
+					return nil, err
+				}
+			} else {
+				def.DefaultExpr.Expr = expr
 			}
-			def.DefaultExpr.Expr = expr
 		}
 	}
 	return def, nil
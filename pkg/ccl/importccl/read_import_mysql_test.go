@@ -159,7 +159,13 @@ func readMysqlCreateFrom(
 	}
 	defer f.Close()
 
-	tbl, err := readMysqlCreateTable(context.TODO(), f, testEvalCtx, id, expectedParent, name, fks, map[sqlbase.ID]int64{})
+	var match map[string]bool
+	if name != "" {
+		match = map[string]bool{name: true}
+	}
+	tbl, err := readMysqlCreateTable(
+		context.TODO(), f, testEvalCtx, id, expectedParent, match, fks, map[sqlbase.ID]int64{},
+		false /* ignoreUnsupported */)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -13,6 +13,7 @@ import (
 	"context"
 	"io"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -33,16 +34,18 @@ import (
 )
 
 type postgreStream struct {
-	s    *bufio.Scanner
-	copy *postgreStreamCopy
+	s           *bufio.Scanner
+	copy        *postgreStreamCopy
+	unsupported unsupportedHandler
 }
 
 // newPostgreStream returns a struct that can stream statements from an
-// io.Reader.
-func newPostgreStream(r io.Reader, max int) *postgreStream {
+// io.Reader. Statements which can't be parsed are passed to unsupported, if
+// it's non-nil, to decide whether to skip them.
+func newPostgreStream(r io.Reader, max int, unsupported unsupportedHandler) *postgreStream {
 	s := bufio.NewScanner(r)
 	s.Buffer(nil, max)
-	p := &postgreStream{s: s}
+	p := &postgreStream{s: s, unsupported: unsupported}
 	s.Split(p.split)
 	return p
 }
@@ -93,6 +96,12 @@ func (p *postgreStream) Next() (interface{}, error) {
 			if isIgnoredStatement(t) {
 				continue
 			}
+			if p.unsupported != nil {
+				if err := p.unsupported(t, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			return nil, err
 		}
 		switch len(stmts) {
@@ -206,16 +215,21 @@ func removeDefaultRegclass(create *tree.CreateTable) {
 }
 
 // readPostgresCreateTable returns table descriptors for all tables or the
-// matching table from SQL statements.
+// matching tables from SQL statements. Foreign keys, whether they're declared
+// in the CREATE TABLE or added by a later ALTER TABLE, are only resolved once
+// every table has been created, so they may reference tables defined later in
+// the input.
 func readPostgresCreateTable(
+	ctx context.Context,
 	input io.Reader,
 	evalCtx *tree.EvalContext,
 	settings *cluster.Settings,
-	match string,
+	match map[string]bool,
 	parentID sqlbase.ID,
 	walltime int64,
 	fks fkHandler,
 	max int,
+	ignoreUnsupported bool,
 ) ([]*sqlbase.TableDescriptor, error) {
 	// Modify the CreateTable stmt with the various index additions. We do this
 	// instead of creating a full table descriptor first and adding indexes
@@ -226,7 +240,8 @@ func readPostgresCreateTable(
 	createTbl := make(map[string]*tree.CreateTable)
 	createSeq := make(map[string]*tree.CreateSequence)
 	tableFKs := make(map[string][]*tree.ForeignKeyConstraintTableDef)
-	ps := newPostgreStream(input, max)
+	unsupported := makeUnsupportedHandler(ctx, ignoreUnsupported)
+	ps := newPostgreStream(input, max, unsupported)
 	for {
 		stmt, err := ps.Next()
 		if err == io.EOF {
@@ -255,7 +270,7 @@ func readPostgresCreateTable(
 				}
 				removeDefaultRegclass(create)
 				id := sqlbase.ID(int(defaultCSVTableID) + len(ret))
-				desc, err := MakeSimpleTableDescriptor(evalCtx.Ctx(), settings, create, parentID, id, fks, walltime)
+				desc, err := MakeSimpleTableDescriptor(ctx, settings, create, parentID, id, fks, walltime)
 				if err != nil {
 					return nil, err
 				}
@@ -269,7 +284,7 @@ func readPostgresCreateTable(
 					continue
 				}
 				for _, constraint := range constraints {
-					if err := sql.ResolveFK(evalCtx.Ctx(), nil /* txn */, fks.resolver, desc, constraint, backrefs, sql.NewTable, tree.ValidationDefault); err != nil {
+					if err := sql.ResolveFK(ctx, nil /* txn */, fks.resolver, desc, constraint, backrefs, sql.NewTable, tree.ValidationDefault); err != nil {
 						return nil, err
 					}
 				}
@@ -277,12 +292,15 @@ func readPostgresCreateTable(
 					return nil, err
 				}
 			}
-			if match != "" && len(ret) != 1 {
-				found := make([]string, 0, len(createTbl))
-				for name := range createTbl {
-					found = append(found, name)
+			for _, name := range sortedNames(match) {
+				if createTbl[name] == nil && createSeq[name] == nil {
+					found := make([]string, 0, len(createTbl))
+					for name := range createTbl {
+						found = append(found, name)
+					}
+					sort.Strings(found)
+					return nil, errors.Errorf("table %q not found in file (found tables: %s)", name, strings.Join(found, ", "))
 				}
-				return nil, errors.Errorf("table %q not found in file (found tables: %s)", match, strings.Join(found, ", "))
 			}
 			if len(ret) == 0 {
 				return nil, errors.Errorf("no table definition found")
@@ -298,11 +316,26 @@ func readPostgresCreateTable(
 			if err != nil {
 				return nil, err
 			}
-			if match != "" && match != name {
+			if match != nil && !match[name] {
 				createTbl[name] = nil
-			} else {
-				createTbl[name] = stmt
+				break
 			}
+			// Foreign keys declared in the CREATE TABLE are deferred like the ones
+			// added by ALTER TABLE, since they may reference a table that hasn't
+			// been created yet.
+			stmt.HoistConstraints()
+			defs := stmt.Defs[:0]
+			for _, def := range stmt.Defs {
+				if fk, ok := def.(*tree.ForeignKeyConstraintTableDef); ok {
+					if !fks.skip {
+						tableFKs[name] = append(tableFKs[name], fk)
+					}
+					continue
+				}
+				defs = append(defs, def)
+			}
+			stmt.Defs = defs
+			createTbl[name] = stmt
 		case *tree.CreateIndex:
 			name, err := getTableName(&stmt.Table)
 			if err != nil {
@@ -356,7 +389,11 @@ func readPostgresCreateTable(
 				case *tree.AlterTableValidateConstraint:
 					// ignore
 				default:
-					return nil, errors.Errorf("unsupported statement: %s", stmt)
+					if err := unsupported(
+						stmt.String(), errors.Errorf("unsupported statement: %s", stmt),
+					); err != nil {
+						return nil, err
+					}
 				}
 			}
 		case *tree.CreateSequence:
@@ -364,7 +401,7 @@ func readPostgresCreateTable(
 			if err != nil {
 				return nil, err
 			}
-			if match == "" || match == name {
+			if match == nil || match[name] {
 				createSeq[name] = stmt
 			}
 		}
@@ -447,7 +484,8 @@ func (m *pgDumpReader) readFile(
 	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
 ) error {
 	var inserts, count int64
-	ps := newPostgreStream(input, int(m.opts.MaxRowSize))
+	ps := newPostgreStream(
+		input, int(m.opts.MaxRowSize), makeUnsupportedHandler(ctx, m.opts.IgnoreUnsupported))
 	semaCtx := &tree.SemaContext{}
 	for {
 		stmt, err := ps.Next()
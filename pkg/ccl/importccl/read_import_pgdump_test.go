@@ -30,7 +30,7 @@ select '123456789012345678901234567890123456789012345678901234567890123456789012
 --
 `
 
-	p := newPostgreStream(strings.NewReader(sql), defaultScanBuffer)
+	p := newPostgreStream(strings.NewReader(sql), defaultScanBuffer, nil /* unsupported */)
 	var sb strings.Builder
 	for {
 		s, err := p.Next()
@@ -111,7 +111,7 @@ COPY public.t (s) FROM stdin;
 --
 `
 
-	p := newPostgreStream(strings.NewReader(sql), defaultScanBuffer)
+	p := newPostgreStream(strings.NewReader(sql), defaultScanBuffer, nil /* unsupported */)
 	var sb strings.Builder
 	for {
 		s, err := p.Next()
@@ -16,6 +16,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/url"
+	"runtime"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
@@ -35,12 +37,34 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/limit"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
+	"golang.org/x/time/rate"
 )
 
+// importConversionMaxRate limits the rate at which each node reads the input
+// files of an IMPORT.
+var importConversionMaxRate = settings.RegisterByteSizeSetting(
+	"kv.import.conversion_max_rate",
+	"the rate limit (bytes/sec) at which a node reads the input files of an IMPORT",
+	1<<40,
+)
+
+// importConversionConcurrency limits the number of workers which convert the
+// input of an IMPORT into KVs on each node.
+var importConversionConcurrency = settings.RegisterNonNegativeIntSetting(
+	"kv.import.conversion_concurrency",
+	"number of workers a node uses to convert the input of an IMPORT, or 0 for one per CPU",
+	0,
+)
+
+// importConversionRateBurst is the burst of the limiter of
+// kv.import.conversion_max_rate.
+const importConversionRateBurst = 2 << 20 // 2MB
+
 type readFileFunc func(context.Context, io.Reader, int32, string, progressFn) error
 
 // readInputFile reads each of the passed dataFiles using the passed func. The
@@ -85,6 +109,11 @@ func readInputFiles(
 	updateFromFiles := progressFn != nil && totalBytes == 0
 	updateFromBytes := progressFn != nil && totalBytes > 0
 
+	// The limiter is shared by all the files so the rate limit applies to the
+	// whole input read by this node.
+	limiter := rate.NewLimiter(
+		rate.Limit(importConversionMaxRate.Get(&settings.SV)), importConversionRateBurst)
+
 	currentFile := 0
 	for dataFileIndex, dataFile := range dataFiles {
 		currentFile++
@@ -108,7 +137,8 @@ func readInputFiles(
 				return err
 			}
 			defer f.Close()
-			bc := &byteCounter{r: f}
+			rl := &rateLimitedReader{ctx: ctx, r: f, sv: &settings.SV, limiter: limiter}
+			bc := &byteCounter{r: rl}
 			src, err := decompressingReader(bc, dataFile, format.Compression)
 			if err != nil {
 				return err
@@ -192,6 +222,74 @@ func (b *byteCounter) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// rateLimitedReader limits the rate at which it reads to the
+// kv.import.conversion_max_rate setting. The setting is checked on every read,
+// so changing it throttles running IMPORTs.
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	sv      *settings.Values
+	limiter *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if l := rate.Limit(importConversionMaxRate.Get(r.sv)); l != r.limiter.Limit() {
+		r.limiter.SetLimit(l)
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if err := storageccl.WaitForByteRate(r.ctx, r.limiter, n); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// conversionWorkers limits the number of workers of an IMPORT which convert
+// their input at once. Workers hold a slot while converting each batch, and
+// the number of slots follows the kv.import.conversion_concurrency setting, so
+// changing it throttles running IMPORTs.
+type conversionWorkers struct {
+	sv  *settings.Values
+	sem limit.ConcurrentRequestLimiter
+}
+
+func newConversionWorkers(sv *settings.Values) *conversionWorkers {
+	return &conversionWorkers{
+		sv:  sv,
+		sem: limit.MakeConcurrentRequestLimiter("importConversionLimiter", importConversionWorkers(sv)),
+	}
+}
+
+// importConversionWorkers returns the number of workers a node uses to
+// convert the input of an IMPORT.
+func importConversionWorkers(sv *settings.Values) int {
+	if n := importConversionConcurrency.Get(sv); n > 0 {
+		return int(n)
+	}
+	return runtime.NumCPU()
+}
+
+// count returns the number of workers to start, which is at least one per CPU.
+// Raising the setting later on can't add workers beyond this number.
+func (w *conversionWorkers) count() int {
+	if n := importConversionWorkers(w.sv); n > runtime.NumCPU() {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// begin blocks until the worker can convert a batch.
+func (w *conversionWorkers) begin(ctx context.Context) error {
+	w.sem.SetLimit(importConversionWorkers(w.sv))
+	return w.sem.Begin(ctx)
+}
+
+// finish releases the slot taken by begin.
+func (w *conversionWorkers) finish() {
+	w.sem.Finish()
+}
+
 var csvOutputTypes = []types.T{
 	*types.Bytes,
 	*types.Bytes,
@@ -296,7 +394,10 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 		if isWorkload {
 			conv = newWorkloadReader(kvCh, singleTable, evalCtx)
 		} else {
-			conv = newCSVInputReader(kvCh, cp.spec.Format.Csv, cp.spec.WalltimeNanos, singleTable, singleTableTargetCols, evalCtx)
+			conv = newCSVInputReader(
+				kvCh, cp.spec.Format.Csv, cp.spec.WalltimeNanos, singleTable, singleTableTargetCols, evalCtx,
+				cp.flowCtx.Cfg.Settings,
+			)
 		}
 	case roachpb.IOFileFormat_MysqlOutfile:
 		conv, err = newMysqloutfileReader(kvCh, cp.spec.Format.MysqlOut, singleTable, evalCtx)
@@ -306,6 +407,8 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		conv, err = newPgDumpReader(kvCh, cp.spec.Format.PgDump, cp.spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_Avro:
+		conv, err = newAvroInputReader(kvCh, cp.spec.Format.Avro, singleTable, singleTableTargetCols, evalCtx)
 	default:
 		err = errors.Errorf("Requested IMPORT format (%d) not supported by this node", cp.spec.Format.Format)
 	}
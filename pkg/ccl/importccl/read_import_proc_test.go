@@ -0,0 +1,60 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestImportConversionLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+
+	t.Run("workers", func(t *testing.T) {
+		importConversionConcurrency.Override(&st.SV, 2)
+		w := newConversionWorkers(&st.SV)
+		require.True(t, w.count() >= runtime.NumCPU())
+		require.NoError(t, w.begin(ctx))
+		require.NoError(t, w.begin(ctx))
+
+		// A third worker has to wait for one of the others to finish.
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.Error(t, w.begin(waitCtx))
+
+		// Unless the setting is raised while they're running.
+		importConversionConcurrency.Override(&st.SV, 3)
+		require.NoError(t, w.begin(ctx))
+		for i := 0; i < 3; i++ {
+			w.finish()
+		}
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		limiter := rate.NewLimiter(1, importConversionRateBurst)
+		importConversionMaxRate.Override(&st.SV, 1<<30)
+		r := &rateLimitedReader{ctx: ctx, r: strings.NewReader(`abc`), sv: &st.SV, limiter: limiter}
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, `abc`, string(b))
+		require.Equal(t, rate.Limit(1<<30), limiter.Limit())
+	})
+}
@@ -11,7 +11,6 @@ package importccl
 import (
 	"context"
 	"net/url"
-	"runtime"
 	"strings"
 	"sync/atomic"
 	"unsafe"
@@ -112,7 +111,7 @@ func (w *workloadReader) readFiles(
 	dataFiles map[int32]string,
 	_ roachpb.IOFileFormat,
 	progressFn func(float32) error,
-	_ *cluster.Settings,
+	settings *cluster.Settings,
 ) error {
 	progress := jobs.ProgressUpdateBatcher{Report: func(ctx context.Context, pct float32) error {
 		return progressFn(pct)
@@ -178,8 +177,9 @@ func (w *workloadReader) readFiles(
 			w.table, t.InitialRows, int(conf.BatchBegin), int(conf.BatchEnd), w.kvCh)
 		wcs = append(wcs, wc)
 	}
+	workers := importConversionWorkers(&settings.SV)
 	for _, wc := range wcs {
-		if err := ctxgroup.GroupWorkers(ctx, runtime.NumCPU(), func(ctx context.Context) error {
+		if err := ctxgroup.GroupWorkers(ctx, workers, func(ctx context.Context) error {
 			evalCtx := w.evalCtx.Copy()
 			return wc.Worker(ctx, evalCtx, finishedBatchFn)
 		}); err != nil {
@@ -0,0 +1,433 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package oidcccl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jws"
+)
+
+const (
+	// oidcLoginPath redirects the browser to the OIDC provider to log in.
+	oidcLoginPath = "/oidc/v1/login"
+	// oidcCallbackPath is where the OIDC provider redirects the browser after
+	// authentication. The redirect_url setting must point to it.
+	oidcCallbackPath = "/oidc/v1/callback"
+
+	// stateCookieName is the name of the cookie which holds the state
+	// parameter of a login in progress, which protects the callback against
+	// cross-site request forgery.
+	stateCookieName   = "oidc_state"
+	stateCookieMaxAge = 5 * time.Minute
+
+	// providerRequestTimeout bounds the requests made to the OIDC provider.
+	providerRequestTimeout = 30 * time.Second
+)
+
+// oidcAuthenticationServer implements the OIDC authorization code flow for
+// the admin UI. Once the provider has authenticated a user, the SQL user
+// given by the configured claim of the ID token is logged in with a regular
+// web session.
+type oidcAuthenticationServer struct {
+	st               *cluster.Settings
+	clusterID        uuid.UUID
+	userLoginFromSSO func(ctx context.Context, username string) (*http.Cookie, error)
+	ambientCtx       log.AmbientContext
+	httpClient       *http.Client
+
+	mu struct {
+		syncutil.Mutex
+		// provider is the configuration of the provider, or nil if it must be
+		// discovered again, because the settings changed or the signing keys
+		// of the provider may have been rotated.
+		provider *oidcProvider
+	}
+}
+
+// oidcProvider is the discovered configuration of an OIDC provider.
+type oidcProvider struct {
+	issuer string
+	config oauth2.Config
+	// keys are the keys of the provider used to verify ID tokens, by key ID.
+	keys map[string]*rsa.PublicKey
+}
+
+var _ server.OIDC = &oidcAuthenticationServer{}
+
+// LoginEnabled is part of the server.OIDC interface.
+func (s *oidcAuthenticationServer) LoginEnabled() bool {
+	return s.checkEnabled() == nil
+}
+
+func (s *oidcAuthenticationServer) checkEnabled() error {
+	if !oidcEnabled.Get(&s.st.SV) {
+		return errors.New("OIDC login is disabled")
+	}
+	return utilccl.CheckEnterpriseEnabled(
+		s.st, s.clusterID, sql.ClusterOrganization.Get(&s.st.SV), "OIDC login",
+	)
+}
+
+// handleLogin redirects the browser to the authentication page of the
+// provider.
+func (s *oidcAuthenticationServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := s.ambientCtx.AnnotateCtx(r.Context())
+	if err := s.checkEnabled(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		log.Warningf(ctx, "OIDC: unable to discover the provider: %v", err)
+		http.Error(w, "unable to reach the OIDC provider", http.StatusInternalServerError)
+		return
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		log.Error(ctx, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state := hex.EncodeToString(stateBytes)
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     oidcCallbackPath,
+		MaxAge:   int(stateCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+	})
+	http.Redirect(w, r, p.config.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleCallback completes the login of a user authenticated by the
+// provider: the authorization code is exchanged for an ID token, from which
+// the SQL user is determined, and a web session is created for it.
+func (s *oidcAuthenticationServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := s.ambientCtx.AnnotateCtx(r.Context())
+	if err := s.checkEnabled(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	stateCookie, err := r.Cookie(stateCookieName)
+	if err != nil || stateCookie.Value == "" || stateCookie.Value != query.Get("state") {
+		http.Error(w, "invalid OIDC login state", http.StatusBadRequest)
+		return
+	}
+	// The state is only valid once.
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookieName,
+		Path:     oidcCallbackPath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+	})
+	if e := query.Get("error"); e != "" {
+		http.Error(w, fmt.Sprintf("OIDC login failed: %s %s", e, query.Get("error_description")),
+			http.StatusUnauthorized)
+		return
+	}
+
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		log.Warningf(ctx, "OIDC: unable to discover the provider: %v", err)
+		http.Error(w, "unable to reach the OIDC provider", http.StatusInternalServerError)
+		return
+	}
+	token, err := p.config.Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, s.httpClient), query.Get("code"))
+	if err != nil {
+		log.Warningf(ctx, "OIDC: unable to exchange the authorization code: %v", err)
+		http.Error(w, "unable to obtain a token from the OIDC provider", http.StatusUnauthorized)
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		http.Error(w, "the OIDC provider didn't return an ID token", http.StatusUnauthorized)
+		return
+	}
+	claims, err := s.verifyIDToken(ctx, p, rawIDToken)
+	if err != nil {
+		log.Warningf(ctx, "OIDC: invalid ID token: %v", err)
+		http.Error(w, "invalid ID token", http.StatusUnauthorized)
+		return
+	}
+	re, err := regexp.Compile(oidcPrincipalRegex.Get(&s.st.SV))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	username, err := extractUsername(claims, oidcClaimJSONKey.Get(&s.st.SV), re)
+	if err != nil {
+		log.Warningf(ctx, "OIDC: %v", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	cookie, err := s.userLoginFromSSO(ctx, username)
+	if err != nil {
+		log.Warningf(ctx, "OIDC: unable to log in user %s: %v", username, err)
+		http.Error(w, fmt.Sprintf("unable to log in user %s", username), http.StatusForbidden)
+		return
+	}
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// getProvider returns the configuration of the provider, discovering it if
+// needed.
+func (s *oidcAuthenticationServer) getProvider(ctx context.Context) (*oidcProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.provider != nil {
+		return s.mu.provider, nil
+	}
+	p, err := discoverProvider(ctx, s.httpClient, oidcProviderURL.Get(&s.st.SV), oauth2.Config{
+		ClientID:     oidcClientID.Get(&s.st.SV),
+		ClientSecret: oidcClientSecret.Get(&s.st.SV),
+		RedirectURL:  oidcRedirectURL.Get(&s.st.SV),
+		Scopes:       strings.Fields(oidcScopes.Get(&s.st.SV)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.mu.provider = p
+	return p, nil
+}
+
+func (s *oidcAuthenticationServer) resetProvider() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.provider = nil
+}
+
+// verifyIDToken verifies the signature, issuer, audience and expiration of
+// an ID token, and returns its claims. Only RS256 signatures are supported,
+// which all OIDC providers must support.
+func (s *oidcAuthenticationServer) verifyIDToken(
+	ctx context.Context, p *oidcProvider, rawIDToken string,
+) (map[string]interface{}, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	if header.Alg != "RS256" {
+		return nil, errors.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+	key, ok := p.keys[header.Kid]
+	if !ok {
+		// The provider may have rotated its keys, which are fetched again on
+		// the next login.
+		s.resetProvider()
+		return nil, errors.Errorf("unknown signing key %q", header.Kid)
+	}
+	if err := jws.Verify(rawIDToken, key); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	if iss, _ := claims["iss"].(string); iss != p.issuer {
+		return nil, errors.Errorf("unexpected issuer %q", iss)
+	}
+	if !hasAudience(claims["aud"], p.config.ClientID) {
+		return nil, errors.Errorf("the token is not intended for client %q", p.config.ClientID)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("the token has no expiration")
+	}
+	if now := timeutil.Now(); !now.Before(timeutil.Unix(int64(exp), 0)) {
+		return nil, errors.New("the token is expired")
+	}
+	return claims, nil
+}
+
+// hasAudience returns whether the aud claim of an ID token, which is either a
+// string or an array of strings, contains clientID.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch t := aud.(type) {
+	case string:
+		return t == clientID
+	case []interface{}:
+		for _, a := range t {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractUsername returns the name of the SQL user identified by the given
+// claim of an ID token, which is the first capture group of the principal
+// regex.
+func extractUsername(
+	claims map[string]interface{}, claimKey string, principalRegex *regexp.Regexp,
+) (string, error) {
+	principal, ok := claims[claimKey].(string)
+	if !ok {
+		return "", errors.Errorf("the ID token has no %q claim", claimKey)
+	}
+	match := principalRegex.FindStringSubmatch(principal)
+	if len(match) < 2 || match[1] == "" {
+		return "", errors.Errorf("the %q claim %q doesn't match the principal regex", claimKey, principal)
+	}
+	return sql.NormalizeAndValidateUsername(match[1])
+}
+
+// discoverProvider fetches the configuration and the signing keys of the
+// provider with the given URL, and completes config with its endpoints.
+func discoverProvider(
+	ctx context.Context, client *http.Client, providerURL string, config oauth2.Config,
+) (*oidcProvider, error) {
+	if providerURL == "" {
+		return nil, errors.Errorf("%sprovider_url is not set", baseOIDCSettingName)
+	}
+	providerURL = strings.TrimSuffix(providerURL, "/")
+	var doc struct {
+		Issuer   string `json:"issuer"`
+		AuthURL  string `json:"authorization_endpoint"`
+		TokenURL string `json:"token_endpoint"`
+		JWKSURL  string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, client, providerURL+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	// The issuer must be the URL the configuration was discovered from.
+	if strings.TrimSuffix(doc.Issuer, "/") != providerURL {
+		return nil, errors.Errorf("the issuer %q of the provider doesn't match %q", doc.Issuer, providerURL)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, client, doc.JWKSURL, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid modulus of key %q", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid exponent of key %q", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the provider has no RSA signing keys")
+	}
+
+	config.Endpoint = oauth2.Endpoint{AuthURL: doc.AuthURL, TokenURL: doc.TokenURL}
+	return &oidcProvider{issuer: doc.Issuer, config: config, keys: keys}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(v), "GET %s", url)
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a JWT.
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func configureOIDC(
+	ctx context.Context,
+	st *cluster.Settings,
+	handle func(pattern string, handler http.Handler),
+	userLoginFromSSO func(ctx context.Context, username string) (*http.Cookie, error),
+	ambientCtx log.AmbientContext,
+	clusterID uuid.UUID,
+) (server.OIDC, error) {
+	s := &oidcAuthenticationServer{
+		st:               st,
+		clusterID:        clusterID,
+		userLoginFromSSO: userLoginFromSSO,
+		ambientCtx:       ambientCtx,
+		httpClient:       &http.Client{Timeout: providerRequestTimeout},
+	}
+	// Discover the provider again whenever its configuration changes.
+	for _, setting := range []settings.Setting{
+		oidcProviderURL, oidcClientID, oidcClientSecret, oidcRedirectURL, oidcScopes,
+	} {
+		setting.SetOnChange(&st.SV, s.resetProvider)
+	}
+	handle(oidcLoginPath, http.HandlerFunc(s.handleLogin))
+	handle(oidcCallbackPath, http.HandlerFunc(s.handleCallback))
+	return s, nil
+}
+
+func init() {
+	build.RegisterFeature("oidc")
+	server.ConfigureOIDC = configureOIDC
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package oidcccl
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"golang.org/x/oauth2"
+)
+
+// makeIDToken returns an ID token with the given claims, signed by key.
+func makeIDToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := encode(map[string]string{"alg": "RS256", "kid": kid}) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A provider serving its configuration and signing keys.
+	mux := http.NewServeMux()
+	provider := httptest.NewServer(mux)
+	defer provider.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 provider.URL,
+			"authorization_endpoint": provider.URL + "/auth",
+			"token_endpoint":         provider.URL + "/token",
+			"jwks_uri":               provider.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	ctx := context.Background()
+	p, err := discoverProvider(ctx, http.DefaultClient, provider.URL+"/", oauth2.Config{
+		ClientID: "cockroach",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.config.Endpoint.TokenURL != provider.URL+"/token" {
+		t.Fatalf("unexpected endpoint: %+v", p.config.Endpoint)
+	}
+	if _, err := discoverProvider(ctx, http.DefaultClient, provider.URL+"/other", oauth2.Config{}); err == nil {
+		t.Fatal("expected discovery from a URL other than the issuer to fail")
+	}
+
+	exp := timeutil.Now().Add(time.Hour).Unix()
+	claims := func(iss string, aud interface{}, exp int64) map[string]interface{} {
+		return map[string]interface{}{"iss": iss, "aud": aud, "exp": exp, "email": "carl@example.com"}
+	}
+	s := &oidcAuthenticationServer{}
+	for _, tc := range []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{"valid", makeIDToken(t, key, "k1", claims(provider.URL, "cockroach", exp)), ""},
+		{"audience list", makeIDToken(t, key, "k1", claims(provider.URL, []string{"x", "cockroach"}, exp)), ""},
+		{"malformed", "abc", "malformed token"},
+		{"unknown key", makeIDToken(t, key, "k2", claims(provider.URL, "cockroach", exp)), "unknown signing key"},
+		{"bad signature", makeIDToken(t, otherKey, "k1", claims(provider.URL, "cockroach", exp)), "verification error"},
+		{"wrong issuer", makeIDToken(t, key, "k1", claims("https://evil", "cockroach", exp)), "unexpected issuer"},
+		{"wrong audience", makeIDToken(t, key, "k1", claims(provider.URL, "other", exp)), "not intended for client"},
+		{"expired", makeIDToken(t, key, "k1", claims(provider.URL, "cockroach", 1)), "expired"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := s.verifyIDToken(ctx, p, tc.token)
+			if !testutils.IsError(err, tc.expected) {
+				t.Fatalf("expected error %q, got %v", tc.expected, err)
+			}
+			if err == nil && c["email"] != "carl@example.com" {
+				t.Fatalf("unexpected claims: %v", c)
+			}
+		})
+	}
+}
+
+func TestExtractUsername(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	claims := map[string]interface{}{"email": "Carl@example.com", "sub": 12}
+	for _, tc := range []struct {
+		key      string
+		regex    string
+		expected string
+		err      string
+	}{
+		{"email", "^([^@]+)@example.com$", "carl", ""},
+		{"email", "^([^@]+)@cockroachlabs.com$", "", "doesn't match the principal regex"},
+		{"email", "(.+)", "", "invalid"},
+		{"sub", "(.+)", "", `no "sub" claim`},
+		{"name", "(.+)", "", `no "name" claim`},
+	} {
+		username, err := extractUsername(claims, tc.key, regexp.MustCompile(tc.regex))
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s %s: expected error %q, got %v", tc.key, tc.regex, tc.err, err)
+		} else if username != tc.expected {
+			t.Errorf("%s %s: expected %q, got %q", tc.key, tc.regex, tc.expected, username)
+		}
+	}
+}
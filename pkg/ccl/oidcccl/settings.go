@@ -0,0 +1,92 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package oidcccl
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/pkg/errors"
+)
+
+const baseOIDCSettingName = "server.oidc_authentication."
+
+var oidcEnabled = settings.RegisterBoolSetting(
+	baseOIDCSettingName+"enabled",
+	"enables logging in to the admin UI with an OIDC provider",
+	false,
+)
+
+var oidcProviderURL = settings.RegisterStringSetting(
+	baseOIDCSettingName+"provider_url",
+	"the URL of the OIDC provider, from which its configuration is discovered "+
+		"at {provider_url}/.well-known/openid-configuration",
+	"",
+)
+
+var oidcClientID = settings.RegisterStringSetting(
+	baseOIDCSettingName+"client_id",
+	"the client ID of the cluster at the OIDC provider",
+	"",
+)
+
+var oidcClientSecret = func() *settings.StringSetting {
+	s := settings.RegisterStringSetting(
+		baseOIDCSettingName+"client_secret",
+		"the client secret of the cluster at the OIDC provider",
+		"",
+	)
+	s.SetConfidential()
+	return s
+}()
+
+var oidcRedirectURL = settings.RegisterStringSetting(
+	baseOIDCSettingName+"redirect_url",
+	"the URL the OIDC provider redirects to after authentication, which must be "+
+		"the /oidc/v1/callback endpoint of a node of the cluster",
+	"https://localhost:8080/oidc/v1/callback",
+)
+
+var oidcScopes = settings.RegisterValidatedStringSetting(
+	baseOIDCSettingName+"scopes",
+	"the space-separated scopes requested from the OIDC provider, which must include openid",
+	"openid",
+	func(_ *settings.Values, s string) error {
+		for _, scope := range strings.Fields(s) {
+			if scope == "openid" {
+				return nil
+			}
+		}
+		return errors.New("the scopes must include openid")
+	},
+)
+
+var oidcClaimJSONKey = settings.RegisterStringSetting(
+	baseOIDCSettingName+"claim_json_key",
+	"the claim of the OIDC ID token which identifies the SQL user logging in",
+	"email",
+)
+
+var oidcPrincipalRegex = settings.RegisterValidatedStringSetting(
+	baseOIDCSettingName+"principal_regex",
+	"the regular expression applied to the claim given by claim_json_key, whose "+
+		"first capture group is the name of the SQL user logging in",
+	"(.+)",
+	func(_ *settings.Values, s string) error {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return errors.Wrap(err, "invalid principal regex")
+		}
+		if re.NumSubexp() < 1 {
+			return errors.New("the principal regex must have a capture group")
+		}
+		return nil
+	},
+)
@@ -12,6 +12,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -461,5 +462,6 @@ func selectPartitionExprsByName(
 }
 
 func init() {
+	build.RegisterFeature("partitioning")
 	sql.CreatePartitioningCCL = createPartitioning
 }
@@ -11,6 +11,7 @@ package roleccl
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -312,6 +313,7 @@ func revokeRolePlanHook(
 }
 
 func init() {
+	build.RegisterFeature("roles")
 	sql.AddWrappedPlanHook(createRolePlanHook)
 	sql.AddWrappedPlanHook(dropRolePlanHook)
 	sql.AddPlanHook(grantRolePlanHook)
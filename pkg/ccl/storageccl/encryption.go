@@ -0,0 +1,123 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// EncryptionKeySize is the size in bytes of the keys used to encrypt files,
+// which are AES-256 keys.
+const EncryptionKeySize = 32
+
+// encryptionSaltSize is the size in bytes of the salts used to derive keys
+// from passphrases.
+const encryptionSaltSize = 16
+
+// encryptionKeyDerivationIterations is the number of iterations of PBKDF2
+// used to derive keys from passphrases.
+const encryptionKeyDerivationIterations = 64000
+
+// encryptionPreamble is the prefix of encrypted files, which is followed by
+// the version of the encryption format.
+var encryptionPreamble = []byte("encrypt")
+
+// encryptionVersionGCM is the version of the encryption format where the
+// preamble is followed by a random nonce and the contents of the file sealed
+// with AES-GCM.
+const encryptionVersionGCM = 1
+
+const encryptionNonceSize = 12
+
+// GenerateSalt returns a random salt to derive a key from a passphrase.
+func GenerateSalt() ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// GenerateKey derives a key to encrypt files from a passphrase and a salt.
+func GenerateKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, encryptionKeyDerivationIterations, EncryptionKeySize, sha256.New)
+}
+
+// GenerateDataKey returns a random key to encrypt files.
+func GenerateDataKey() ([]byte, error) {
+	key := make([]byte, EncryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AppearsEncrypted returns whether the contents of a file appear to have been
+// encrypted by EncryptFile.
+func AppearsEncrypted(text []byte) bool {
+	return bytes.HasPrefix(text, encryptionPreamble)
+}
+
+// EncryptFile encrypts the contents of a file with the given key.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptionPreamble) + 1 + encryptionNonceSize
+	ciphertext := make([]byte, headerSize, headerSize+len(plaintext)+gcm.Overhead())
+	copy(ciphertext, encryptionPreamble)
+	ciphertext[len(encryptionPreamble)] = encryptionVersionGCM
+	nonce := ciphertext[len(encryptionPreamble)+1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(ciphertext, nonce, plaintext, nil), nil
+}
+
+// DecryptFile decrypts the contents of a file encrypted by EncryptFile with
+// the given key.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	if !AppearsEncrypted(ciphertext) {
+		return nil, errors.New("file does not appear to be encrypted")
+	}
+	ciphertext = ciphertext[len(encryptionPreamble):]
+	if len(ciphertext) < 1+encryptionNonceSize {
+		return nil, errors.New("invalid encryption header")
+	}
+	if version := ciphertext[0]; version != encryptionVersionGCM {
+		return nil, errors.Errorf("unexpected encryption version %d", version)
+	}
+	nonce := ciphertext[1 : 1+encryptionNonceSize]
+	ciphertext = ciphertext[1+encryptionNonceSize:]
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt file (is the key correct?)")
+	}
+	return plaintext, nil
+}
+
+func aesGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := GenerateSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := GenerateKey([]byte("passphrase"), salt)
+	if len(key) != EncryptionKeySize {
+		t.Fatalf("expected a key of %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	if other := GenerateKey([]byte("passphrase"), salt); !bytes.Equal(key, other) {
+		t.Fatal("expected the same passphrase and salt to derive the same key")
+	}
+
+	for _, plaintext := range [][]byte{nil, []byte("a"), bytes.Repeat([]byte("backup"), 1000)} {
+		ciphertext, err := EncryptFile(plaintext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !AppearsEncrypted(ciphertext) {
+			t.Fatal("expected the ciphertext to appear encrypted")
+		}
+		if len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext) {
+			t.Fatal("expected the ciphertext not to contain the plaintext")
+		}
+		decrypted, err := DecryptFile(ciphertext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, decrypted) {
+			t.Fatalf("expected %q, got %q", plaintext, decrypted)
+		}
+
+		wrongKey, err := GenerateDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecryptFile(ciphertext, wrongKey); !testutils.IsError(err, "is the key correct") {
+			t.Fatalf("expected a wrong key error, got %v", err)
+		}
+		ciphertext[len(ciphertext)-1] ^= 1
+		if _, err := DecryptFile(ciphertext, key); !testutils.IsError(err, "is the key correct") {
+			t.Fatalf("expected a corruption error, got %v", err)
+		}
+	}
+
+	if AppearsEncrypted([]byte("plaintext")) {
+		t.Fatal("expected plaintext not to appear encrypted")
+	}
+	if _, err := DecryptFile([]byte("plaintext"), key); !testutils.IsError(err, "does not appear to be encrypted") {
+		t.Fatalf("expected an error, got %v", err)
+	}
+}
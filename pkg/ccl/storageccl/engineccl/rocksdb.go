@@ -11,6 +11,7 @@ package engineccl
 import (
 	"unsafe"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -34,6 +35,7 @@ import (
 import "C"
 
 func init() {
+	build.RegisterFeature("encryption-at-rest")
 	engine.SetRocksDBOpenHook(C.DBOpenHookCCL)
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 func init() {
@@ -133,48 +134,96 @@ func evalExport(
 
 	e := spanset.GetDBEngine(batch, roachpb.Span{Key: args.Key, EndKey: args.EndKey})
 
-	data, summary, err := engine.ExportToSst(ctx, e, start, end, exportAllRevisions, io)
-
-	if err != nil {
-		return result.Result{}, err
-	}
-
-	if summary.DataSize == 0 {
-		reply.Files = []roachpb.ExportResponse_File{}
-		return result.Result{}, nil
+	var targetSize uint64
+	if args.TargetFileSize > 0 {
+		targetSize = uint64(args.TargetFileSize)
 	}
-
-	var checksum []byte
-	if !args.OmitChecksum {
-		// Compute the checksum before we upload and remove the local file.
-		checksum, err = SHA512ChecksumData(data)
+	reply.Files = []roachpb.ExportResponse_File{}
+	for {
+		data, summary, resume, err := engine.ExportToSst(
+			ctx, e, start, end, exportAllRevisions, targetSize, args.DisableCompression, io,
+		)
 		if err != nil {
 			return result.Result{}, err
 		}
-	}
 
-	exported := roachpb.ExportResponse_File{
-		Span:       args.Span(),
-		Exported:   summary,
-		Sha512:     checksum,
-		LocalityKV: localityKV,
-	}
+		if summary.DataSize == 0 {
+			break
+		}
 
-	if exportStore != nil {
-		exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
-		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(data)); err != nil {
-			return result.Result{}, err
+		span := roachpb.Span{Key: start.Key, EndKey: args.EndKey}
+		if resume != nil {
+			span.EndKey = resume
 		}
-	}
 
-	if args.ReturnSST {
-		exported.SST = data
-	}
+		var checksum []byte
+		if !args.OmitChecksum {
+			// Compute the checksum before we upload and remove the local file.
+			checksum, err = SHA512ChecksumData(data)
+			if err != nil {
+				return result.Result{}, err
+			}
+		}
+
+		exported := roachpb.ExportResponse_File{
+			Span:       span,
+			Exported:   summary,
+			Sha512:     checksum,
+			LocalityKV: localityKV,
+		}
 
-	reply.Files = []roachpb.ExportResponse_File{exported}
+		if exportStore != nil {
+			exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
+			payload := data
+			if args.Encryption != nil {
+				// The checksum is of the unencrypted data, which is what is
+				// checksummed again when the file is imported.
+				payload, err = EncryptFile(data, args.Encryption.Key)
+				if err != nil {
+					return result.Result{}, err
+				}
+			}
+			if err := WaitForByteRate(ctx, cArgs.EvalCtx.GetLimiters().ExportRequestRate, len(payload)); err != nil {
+				return result.Result{}, err
+			}
+			if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(payload)); err != nil {
+				return result.Result{}, err
+			}
+		}
+
+		if args.ReturnSST {
+			exported.SST = data
+		}
+
+		reply.Files = append(reply.Files, exported)
+
+		if resume == nil {
+			break
+		}
+		start.Key = resume
+	}
 	return result.Result{}, nil
 }
 
+// WaitForByteRate waits until the given number of bytes are allowed by a
+// limiter of bytes per second. A nil limiter allows everything.
+func WaitForByteRate(ctx context.Context, limiter *rate.Limiter, size int) error {
+	if limiter == nil || limiter.Burst() <= 0 {
+		return nil
+	}
+	// WaitN fails if asked for more than the burst of the limiter at once.
+	for burst := limiter.Burst(); size > 0; size -= burst {
+		n := size
+		if n > burst {
+			n = burst
+		}
+		if err := limiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SHA512ChecksumData returns the SHA512 checksum of data.
 func SHA512ChecksumData(data []byte) ([]byte, error) {
 	h := sha512.New()
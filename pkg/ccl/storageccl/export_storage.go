@@ -41,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/workload"
 	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 )
@@ -701,27 +702,24 @@ func (g *gcsStorage) Conf() roachpb.ExportStorage {
 	}
 }
 
-func makeGCSStorage(
-	ctx context.Context, conf *roachpb.ExportStorage_GCS, settings *cluster.Settings,
-) (ExportStorage, error) {
-	if conf == nil {
-		return nil, errors.Errorf("google cloud storage upload requested but info missing")
-	}
-	const scope = gcs.ScopeReadWrite
-	opts := []option.ClientOption{option.WithScopes(scope)}
-
+// gcsTokenSource returns the source of the OAuth tokens used to authenticate
+// to Google Cloud with the given AUTH and CREDENTIALS parameters, or nil if the
+// credentials of the environment should be used.
+func gcsTokenSource(
+	ctx context.Context, auth, credentials string, settings *cluster.Settings, scope string,
+) (oauth2.TokenSource, error) {
 	// "default": only use the key in the settings; error if not present.
 	// "specified": the JSON object for authentication is given by the CREDENTIALS param.
 	// "implicit": only use the environment data.
 	// "": if default key is in the settings use it; otherwise use environment data.
-	switch conf.Auth {
+	switch auth {
 	case "", authParamDefault:
 		var key string
 		if settings != nil {
 			key = gcsDefault.Get(&settings.SV)
 		}
 		// We expect a key to be present if default is specified.
-		if conf.Auth == authParamDefault && key == "" {
+		if auth == authParamDefault && key == "" {
 			return nil, errors.Errorf("expected settings value for %s", cloudstorageGSDefaultKey)
 		}
 		if key != "" {
@@ -729,10 +727,10 @@ func makeGCSStorage(
 			if err != nil {
 				return nil, errors.Wrap(err, "creating GCS oauth token source")
 			}
-			opts = append(opts, option.WithTokenSource(source.TokenSource(ctx)))
+			return source.TokenSource(ctx), nil
 		}
 	case authParamSpecified:
-		if conf.Credentials == "" {
+		if credentials == "" {
 			return nil, errors.Errorf(
 				"%s is set to '%s', but %s is not set",
 				AuthParam,
@@ -740,7 +738,7 @@ func makeGCSStorage(
 				CredentialsParam,
 			)
 		}
-		decodedKey, err := base64.StdEncoding.DecodeString(conf.Credentials)
+		decodedKey, err := base64.StdEncoding.DecodeString(credentials)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("decoding value of %s", CredentialsParam))
 		}
@@ -748,12 +746,51 @@ func makeGCSStorage(
 		if err != nil {
 			return nil, errors.Wrap(err, "creating GCS oauth token source from specified credentials")
 		}
-		opts = append(opts, option.WithTokenSource(source.TokenSource(ctx)))
+		return source.TokenSource(ctx), nil
 	case authParamImplicit:
 		// Do nothing; use implicit params:
 		// https://godoc.org/golang.org/x/oauth2/google#FindDefaultCredentials
 	default:
-		return nil, errors.Errorf("unsupported value %s for %s", conf.Auth, AuthParam)
+		return nil, errors.Errorf("unsupported value %s for %s", auth, AuthParam)
+	}
+	return nil, nil
+}
+
+// GoogleCloudHTTPClient returns a client for the REST APIs of Google Cloud,
+// authenticated with the given AUTH and CREDENTIALS parameters as in gs storage
+// URIs.
+func GoogleCloudHTTPClient(
+	ctx context.Context, auth, credentials string, settings *cluster.Settings, scope string,
+) (*http.Client, error) {
+	source, err := gcsTokenSource(ctx, auth, credentials, settings, scope)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		return oauth2.NewClient(ctx, source), nil
+	}
+	client, err := google.DefaultClient(ctx, scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating google cloud client")
+	}
+	return client, nil
+}
+
+func makeGCSStorage(
+	ctx context.Context, conf *roachpb.ExportStorage_GCS, settings *cluster.Settings,
+) (ExportStorage, error) {
+	if conf == nil {
+		return nil, errors.Errorf("google cloud storage upload requested but info missing")
+	}
+	const scope = gcs.ScopeReadWrite
+	opts := []option.ClientOption{option.WithScopes(scope)}
+
+	source, err := gcsTokenSource(ctx, conf.Auth, conf.Credentials, settings, scope)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		opts = append(opts, option.WithTokenSource(source))
 	}
 	g, err := gcs.NewClient(ctx, opts...)
 	if err != nil {
@@ -325,7 +325,9 @@ func assertEqualKVs(
 			io.MaxTimestampHint = endTime
 			io.MinTimestampHint = startTime
 		}
-		sst, _, err := engine.ExportToSst(ctx, e, start, end, exportAllRevisions, io)
+		sst, _, _, err := engine.ExportToSst(
+			ctx, e, start, end, exportAllRevisions, 0 /* targetSize */, false /* disableCompression */, io,
+		)
 		if err != nil {
 			t.Fatal(err)
 		}
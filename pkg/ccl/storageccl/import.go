@@ -112,6 +112,19 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		}
 		dataSize := int64(len(fileContents))
 		log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
+		limiter := cArgs.EvalCtx.GetLimiters().ImportRequestRate
+		if err := WaitForByteRate(ctx, limiter, len(fileContents)); err != nil {
+			return nil, err
+		}
+
+		if args.Encryption != nil {
+			fileContents, err = DecryptFile(fileContents, args.Encryption.Key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decrypting %q", file.Path)
+			}
+		} else if AppearsEncrypted(fileContents) {
+			return nil, errors.Errorf("%q appears to be encrypted", file.Path)
+		}
 
 		if len(file.Sha512) > 0 {
 			checksum, err := SHA512ChecksumData(fileContents)
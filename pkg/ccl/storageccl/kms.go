@@ -0,0 +1,259 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/pkg/errors"
+)
+
+// KMS encrypts and decrypts small amounts of data, such as the data keys of
+// backups, with a master key held by a key management service.
+type KMS interface {
+	// MasterKeyID returns the ID of the master key of the KMS. It is the same
+	// for all the URIs of a master key which differ only by their credentials.
+	MasterKeyID() string
+	// Encrypt encrypts data with the master key.
+	Encrypt(ctx context.Context, data []byte) ([]byte, error)
+	// Decrypt decrypts data encrypted with the master key.
+	Decrypt(ctx context.Context, data []byte) ([]byte, error)
+	Close() error
+}
+
+// KMSFactory makes the KMS of a URI.
+type KMSFactory func(ctx context.Context, uri *url.URL, settings *cluster.Settings) (KMS, error)
+
+var kmsFactories = map[string]KMSFactory{
+	"aws": makeAWSKMS,
+	"gs":  makeGCSKMS,
+}
+
+// RegisterKMSFactory registers the factory of the KMSes whose URIs have the
+// given scheme.
+func RegisterKMSFactory(scheme string, factory KMSFactory) {
+	kmsFactories[scheme] = factory
+}
+
+// KMSFromURI returns the KMS of the given URI.
+func KMSFromURI(ctx context.Context, uri string, settings *cluster.Settings) (KMS, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	factory, ok := kmsFactories[parsed.Scheme]
+	if !ok {
+		return nil, errors.Errorf("unsupported KMS scheme: %q", parsed.Scheme)
+	}
+	return factory(ctx, parsed, settings)
+}
+
+// kmsMasterKeyID returns the ID of the master key of a KMS URI, which is the
+// URI without its query parameters.
+func kmsMasterKeyID(uri *url.URL) string {
+	id := *uri
+	id.RawQuery = ""
+	return id.String()
+}
+
+type awsKMS struct {
+	id    string
+	keyID string
+	kms   *kms.KMS
+}
+
+var _ KMS = &awsKMS{}
+
+// makeAWSKMS makes the KMS of a URI of the form
+// aws:///<key ID, alias or ARN>?AWS_REGION=<region>, authenticated with the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY parameters, or with the
+// credentials of the environment if AUTH=implicit.
+func makeAWSKMS(_ context.Context, uri *url.URL, _ *cluster.Settings) (KMS, error) {
+	keyID := strings.TrimPrefix(uri.Path, "/")
+	if keyID == "" {
+		return nil, errors.New("aws KMS uri missing the key ID")
+	}
+	q := uri.Query()
+	region := q.Get(S3RegionParam)
+	if region == "" {
+		return nil, errors.Errorf("aws KMS uri missing %q parameter", S3RegionParam)
+	}
+	config := &aws.Config{Region: aws.String(region)}
+	switch q.Get(AuthParam) {
+	case "", authParamSpecified:
+		accessKey, secret := q.Get(S3AccessKeyParam), q.Get(S3SecretParam)
+		if accessKey == "" {
+			return nil, errors.Errorf("aws KMS uri missing %q parameter", S3AccessKeyParam)
+		}
+		if secret == "" {
+			return nil, errors.Errorf("aws KMS uri missing %q parameter", S3SecretParam)
+		}
+		// As in S3 URIs, unescaped + characters of the secret are turned into
+		// spaces, which never occur in AWS secrets.
+		secret = strings.Replace(secret, " ", "+", -1)
+		config.Credentials = credentials.NewStaticCredentials(accessKey, secret, q.Get(S3TempTokenParam))
+	case authParamImplicit:
+		// Use the credentials of the environment.
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", q.Get(AuthParam), AuthParam)
+	}
+	if endpoint := q.Get(S3EndpointParam); endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "new aws session")
+	}
+	return &awsKMS{
+		id:    kmsMasterKeyID(uri),
+		keyID: keyID,
+		kms:   kms.New(sess),
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *awsKMS) MasterKeyID() string {
+	return k.id
+}
+
+// Encrypt implements the KMS interface.
+func (k *awsKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	out, err := k.kms.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: data,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "aws KMS encrypt")
+	}
+	return out.CiphertextBlob, nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *awsKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	// The ciphertext of symmetric keys identifies the master key which
+	// encrypted it.
+	out, err := k.kms.DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: data})
+	if err != nil {
+		return nil, errors.Wrap(err, "aws KMS decrypt")
+	}
+	return out.Plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *awsKMS) Close() error {
+	return nil
+}
+
+const gcsKMSScope = "https://www.googleapis.com/auth/cloudkms"
+
+type gcsKMS struct {
+	id string
+	// keyURL is the URL of the crypto key in the REST API of Cloud KMS.
+	keyURL string
+	client *http.Client
+}
+
+var _ KMS = &gcsKMS{}
+
+// makeGCSKMS makes the KMS of a URI of the form
+// gs:///projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>,
+// authenticated with the AUTH and CREDENTIALS parameters as in gs storage
+// URIs.
+func makeGCSKMS(ctx context.Context, uri *url.URL, settings *cluster.Settings) (KMS, error) {
+	name := strings.TrimPrefix(uri.Path, "/")
+	if !strings.HasPrefix(name, "projects/") {
+		return nil, errors.Errorf("gs KMS uri must be the resource name of a crypto key, got %q", name)
+	}
+	q := uri.Query()
+	client, err := GoogleCloudHTTPClient(
+		ctx, q.Get(AuthParam), q.Get(CredentialsParam), settings, gcsKMSScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating Cloud KMS client")
+	}
+	return &gcsKMS{
+		id:     kmsMasterKeyID(uri),
+		keyURL: "https://cloudkms.googleapis.com/v1/" + name,
+		client: client,
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *gcsKMS) MasterKeyID() string {
+	return k.id
+}
+
+// Encrypt implements the KMS interface.
+func (k *gcsKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var res struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	req := struct {
+		Plaintext []byte `json:"plaintext"`
+	}{Plaintext: data}
+	if err := k.call(ctx, "encrypt", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Ciphertext, nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *gcsKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var res struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	req := struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}{Ciphertext: data}
+	if err := k.call(ctx, "decrypt", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Plaintext, nil
+}
+
+// call calls a method of the crypto key in the REST API of Cloud KMS.
+func (k *gcsKMS) call(ctx context.Context, method string, req, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", k.keyURL+":"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := k.client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "gs KMS %s", method)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("gs KMS %s: %s: %s", method, resp.Status, respBody)
+	}
+	return json.Unmarshal(respBody, res)
+}
+
+// Close implements the KMS interface.
+func (k *gcsKMS) Close() error {
+	return nil
+}
@@ -69,9 +69,6 @@ func TestAllRegisteredImportFixture(t *testing.T) {
 		case `ycsb`, `startrek`, `roachmart`, `interleavedpartitioned`:
 			// These don't work with IMPORT.
 			continue
-		case `tpch`:
-			// tpch has an incomplete initial data implemention.
-			continue
 		}
 
 		t.Run(meta.Name, func(t *testing.T) {
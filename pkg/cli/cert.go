@@ -189,6 +189,52 @@ func runCreateClientCert(cmd *cobra.Command, args []string) error {
 		"failed to generate client certificate and key")
 }
 
+// A rotateCerts command re-issues the node and client certificates found in
+// the cert directory.
+var rotateCertsCmd = &cobra.Command{
+	Use:   "rotate --certs-dir=<path to cockroach certs dir> --ca-key=<path-to-ca-key>",
+	Short: "re-issue node and client certificates and keys",
+	Long: `
+Re-issue the node certificate "<certs-dir>/node.crt" and the client
+certificates "<certs-dir>/client.<username>.crt" found in the certificate
+directory, along with new keys. The new certificates are valid for the same
+hosts and users as the certificates they replace.
+
+Each file is replaced atomically. Running nodes keep using their current
+certificates until they are told to reload them, either by sending SIGHUP
+to the cockroach process, or with a POST request by an admin user to the
+/_status/reload_certificates/local HTTP endpoint of the node. Neither
+requires restarting the node.
+
+Requires a CA cert in "<certs-dir>/ca.crt" and matching key in "--ca-key".
+If "<certs-dir>/ca-client.crt" exists, the client certificates are signed
+by it instead, and "--ca-key" must match it.
+Rotation fails if the CA expiration time is before the desired certificate expiration.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runRotateCerts),
+}
+
+// runRotateCerts re-issues the node and client certificates and keys in the
+// cert directory.
+func runRotateCerts(cmd *cobra.Command, args []string) error {
+	rotated, err := security.RotateCertificates(
+		baseCfg.SSLCertsDir,
+		baseCfg.SSLCAKey,
+		keySize,
+		certificateLifetime)
+	if err != nil {
+		return errors.Wrap(err, "failed to rotate certificates")
+	}
+	if len(rotated) == 0 {
+		return errors.Errorf("no node or client certificates found in %s", baseCfg.SSLCertsDir)
+	}
+	for _, path := range rotated {
+		fmt.Printf("rotated %s\n", path)
+	}
+	return nil
+}
+
 // A listCerts command generates a client certificate and stores it
 // in the cert directory under <username>.crt and key under <username>.key.
 var listCertsCmd = &cobra.Command{
@@ -300,6 +346,7 @@ var certCmds = []*cobra.Command{
 	createClientCACertCmd,
 	createNodeCertCmd,
 	createClientCertCmd,
+	rotateCertsCmd,
 	listCertsCmd,
 }
 
@@ -12,8 +12,10 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"strings"
@@ -21,6 +23,8 @@ import (
 
 	_ "github.com/benesch/cgosymbolizer" // calls runtime.SetCgoTraceback on import
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
@@ -63,21 +67,23 @@ func Main() {
 
 	defer log.RecoverAndReportPanic(context.Background(), &serverCfg.Settings.SV)
 
-	errCode := 0
+	errCode := exitSuccess
 	if err := Run(os.Args[1:]); err != nil {
-		// Display the error and its details/hints.
-		fmt.Fprintln(stderr, "Error:", err.Error())
-		maybeShowErrorDetails(stderr, err, false /* printNewline */)
-
-		// Remind the user of which command was being run.
-		fmt.Fprintf(stderr, "Failed running %q\n", cmdName)
-
-		// Finally, extract the error code, as optionally specified
-		// by the sub-command.
-		errCode = 1
-		var cliErr *cliError
-		if errors.As(err, &cliErr) {
-			errCode = cliErr.exitCode
+		// Extract the error code, as optionally specified by the
+		// sub-command.
+		errCode = errorExitCode(err)
+
+		switch cliCtx.tableDisplayFormat {
+		case tableDisplayJSON, tableDisplayNDJSON:
+			// Scripts requesting JSON output also get a JSON error.
+			reportErrorJSON(stderr, err, cmdName, errCode)
+		default:
+			// Display the error and its details/hints.
+			fmt.Fprintln(stderr, "Error:", err.Error())
+			maybeShowErrorDetails(stderr, err, false /* printNewline */)
+
+			// Remind the user of which command was being run.
+			fmt.Fprintf(stderr, "Failed running %q\n", cmdName)
 		}
 	}
 	os.Exit(errCode)
@@ -133,9 +139,16 @@ var versionCmd = &cobra.Command{
 	Short: "output version information",
 	Long: `
 Output build version information.
+
+With --detail, the information is output as JSON, along with the storage
+engines, the CCL features compiled into the binary, the range of cluster
+versions it is compatible with and its toolchain.
 `,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if versionCtx.detail {
+			return printVersionDetail(os.Stdout)
+		}
 		info := build.GetInfo()
 		tw := tabwriter.NewWriter(os.Stdout, 2, 1, 2, ' ', 0)
 		fmt.Fprintf(tw, "Build Tag:    %s\n", info.Tag)
@@ -154,11 +167,69 @@ Output build version information.
 	},
 }
 
+// versionDetail is the output of cockroach version --detail. Its fields are
+// meant to be consumed by tools, and must not be renamed.
+type versionDetail struct {
+	Build struct {
+		Tag          string `json:"tag"`
+		Time         string `json:"time"`
+		Revision     string `json:"revision"`
+		Distribution string `json:"distribution"`
+		Type         string `json:"type"`
+		Channel      string `json:"channel"`
+	} `json:"build"`
+	StorageEngines       []string `json:"storage_engines"`
+	DefaultStorageEngine string   `json:"default_storage_engine"`
+	CCLFeatures          []string `json:"ccl_features"`
+	ClusterVersion       struct {
+		Binary           string `json:"binary"`
+		MinimumSupported string `json:"minimum_supported"`
+	} `json:"cluster_version"`
+	Toolchain struct {
+		GoVersion       string `json:"go_version"`
+		Platform        string `json:"platform"`
+		CgoCompiler     string `json:"cgo_compiler"`
+		CgoTargetTriple string `json:"cgo_target_triple"`
+	} `json:"toolchain"`
+}
+
+// printVersionDetail prints the output of cockroach version --detail to w.
+func printVersionDetail(w io.Writer) error {
+	info := build.GetInfo()
+	var d versionDetail
+	d.Build.Tag = info.Tag
+	d.Build.Time = info.Time
+	d.Build.Revision = info.Revision
+	d.Build.Distribution = info.Distribution
+	d.Build.Type = info.Type
+	d.Build.Channel = info.Channel
+	for _, e := range []enginepb.EngineType{enginepb.EngineTypeRocksDB, enginepb.EngineTypePebble} {
+		d.StorageEngines = append(d.StorageEngines, e.String())
+	}
+	d.DefaultStorageEngine = serverCfg.StorageEngine.String()
+	d.CCLFeatures = build.Features()
+	d.ClusterVersion.Binary = cluster.BinaryServerVersion.String()
+	d.ClusterVersion.MinimumSupported = cluster.BinaryMinimumSupportedVersion.String()
+	d.Toolchain.GoVersion = info.GoVersion
+	d.Toolchain.Platform = info.Platform
+	d.Toolchain.CgoCompiler = info.CgoCompiler
+	d.Toolchain.CgoTargetTriple = info.CgoTargetTriple
+
+	out, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
 var cockroachCmd = &cobra.Command{
 	Use:   "cockroach [command] (flags)",
 	Short: "CockroachDB command-line interface and server",
 	// TODO(cdo): Add a pointer to the docs in Long.
-	Long: `CockroachDB command-line interface and server.`,
+	Long: `CockroachDB command-line interface and server.
+
+` + exitCodesHelp,
 	// Disable automatic printing of usage information whenever an error
 	// occurs. Many errors are not the result of a bad command invocation,
 	// e.g. attempting to start a node on an in-use port, and printing the
@@ -195,11 +266,15 @@ func init() {
 		userCmd,
 		nodeCmd,
 		dumpCmd,
+		nodeLocalCmd,
+		userFileCmd,
+		stmtDiagCmd,
 
 		// Miscellaneous commands.
 		// TODO(pmattis): stats
 		demoCmd,
 		genCmd,
+		convertURLCmd,
 		versionCmd,
 		DebugCmd,
 		sqlfmtCmd,
@@ -14,6 +14,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -358,7 +359,7 @@ func isSQLCommand(args []string) bool {
 		return false
 	}
 	switch args[0] {
-	case "user", "sql", "dump", "workload":
+	case "user", "sql", "dump", "workload", "statement-diag", "convert-url":
 		return true
 	case "node":
 		if len(args) == 0 {
@@ -370,6 +371,8 @@ func isSQLCommand(args []string) bool {
 		default:
 			return false
 		}
+	case "gen":
+		return len(args) > 1 && args[1] == "settings-list"
 	default:
 		return false
 	}
@@ -450,6 +453,30 @@ func TestQuit(t *testing.T) {
 	<-c.Stopper().IsStopped()
 }
 
+func TestNodeDrain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	out, err := c.RunWithCapture("node drain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out, "ok\n") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	// The node is drained but keeps running.
+	if !c.PGServer().IsDraining() {
+		t.Fatal("expected SQL server to be draining")
+	}
+	select {
+	case <-c.Stopper().IsStopped():
+		t.Fatal("expected node to keep running")
+	default:
+	}
+}
+
 func Example_logging() {
 	c := newCLITest(cliTestParams{})
 	defer c.cleanup()
@@ -797,6 +824,12 @@ thenshort`,
 	// ## 1
 	// 0
 	// # 1 row
+	// sql --format=json -e select * from t.u
+	// [
+	//   {"f\"oo":0,"f'oo":0,"f\\oo":0,"short\nvery very long\nnot much":0,"very very long\nthenshort":0,"κόσμε":0,"a|b":0,"܈85":0}
+	// ]
+	// sql --format=ndjson -e select * from t.u
+	// {"f\"oo":0,"f'oo":0,"f\\oo":0,"short\nvery very long\nnot much":0,"very very long\nthenshort":0,"κόσμε":0,"a|b":0,"܈85":0}
 }
 
 func Example_sql_empty_table() {
@@ -839,6 +872,9 @@ func Example_sql_empty_table() {
 	// sql --format=raw -e select * from t.norows
 	// # 1 column
 	// # 0 rows
+	// sql --format=json -e select * from t.norows
+	// []
+	// sql --format=ndjson -e select * from t.norows
 	// sql --format=tsv -e select * from t.nocols
 	// # no columns
 	// # empty
@@ -877,6 +913,16 @@ func Example_sql_empty_table() {
 	// # row 2
 	// # row 3
 	// # 3 rows
+	// sql --format=json -e select * from t.nocols
+	// [
+	//   {},
+	//   {},
+	//   {}
+	// ]
+	// sql --format=ndjson -e select * from t.nocols
+	// {}
+	// {}
+	// {}
 	// sql --format=tsv -e select * from t.nocolsnorows
 	// # no columns
 	// sql --format=csv -e select * from t.nocolsnorows
@@ -899,6 +945,9 @@ func Example_sql_empty_table() {
 	// sql --format=raw -e select * from t.nocolsnorows
 	// # 0 columns
 	// # 0 rows
+	// sql --format=json -e select * from t.nocolsnorows
+	// []
+	// sql --format=ndjson -e select * from t.nocolsnorows
 }
 
 func Example_csv_tsv_quoting() {
@@ -1276,6 +1325,28 @@ func Example_sql_table() {
 	// ## 4
 	// tabs
 	// # 9 rows
+	// sql --format=json -e select * from t.t
+	// [
+	//   {"s":"foo","d":"printable ASCII"},
+	//   {"s":"\"foo","d":"printable ASCII with quotes"},
+	//   {"s":"\\foo","d":"printable ASCII with backslash"},
+	//   {"s":"foo\nbar","d":"non-printable ASCII"},
+	//   {"s":"κόσμε","d":"printable UTF8"},
+	//   {"s":"ñ","d":"printable UTF8 using escapes"},
+	//   {"s":"\\x01","d":"non-printable UTF8 string"},
+	//   {"s":"܈85","d":"UTF8 string with RTL char"},
+	//   {"s":"a\tb\tc\n12\t123123213\t12313","d":"tabs"}
+	// ]
+	// sql --format=ndjson -e select * from t.t
+	// {"s":"foo","d":"printable ASCII"}
+	// {"s":"\"foo","d":"printable ASCII with quotes"}
+	// {"s":"\\foo","d":"printable ASCII with backslash"}
+	// {"s":"foo\nbar","d":"non-printable ASCII"}
+	// {"s":"κόσμε","d":"printable UTF8"}
+	// {"s":"ñ","d":"printable UTF8 using escapes"}
+	// {"s":"\\x01","d":"non-printable UTF8 string"}
+	// {"s":"܈85","d":"UTF8 string with RTL char"}
+	// {"s":"a\tb\tc\n12\t123123213\t12313","d":"tabs"}
 }
 
 func TestRenderHTML(t *testing.T) {
@@ -1357,6 +1428,41 @@ func TestRenderHTML(t *testing.T) {
 	}
 }
 
+func TestWriteJSONValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		typ, val, out string
+	}{
+		{"INT8", "NULL", `null`},
+		{"STRING", "NULL", `null`},
+		{"INT8", "123", `123`},
+		{"INT8", "-123", `-123`},
+		{"FLOAT8", "1.5e+10", `1.5e+10`},
+		{"FLOAT8", "NaN", `"NaN"`},
+		{"FLOAT8", "+Inf", `"+Inf"`},
+		{"NUMERIC", "1.50", `1.50`},
+		{"BOOL", "true", `true`},
+		{"BOOL", "false", `false`},
+		{"JSONB", `{"a": [1, "<b>"]}`, `{"a": [1, "<b>"]}`},
+		{"STRING", "<b>foo</b>", `"<b>foo</b>"`},
+		{"STRING", "123", `"123"`},
+		{"", "true", `"true"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.typ+"/"+tc.val, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeJSONValue(&buf, tc.typ, tc.val); err != nil {
+				t.Fatal(err)
+			}
+			if tc.out != buf.String() {
+				t.Errorf("expected %s, got %s", tc.out, buf.String())
+			}
+		})
+	}
+}
+
 func Example_misc_table() {
 	c := newCLITest(cliTestParams{})
 	defer c.cleanup()
@@ -1559,11 +1665,15 @@ Available Commands:
 
   sql               open a sql shell
   user              get, set, list and remove users (deprecated)
-  node              list, inspect or remove nodes
+  node              list, inspect, drain or remove nodes
   dump              dump sql tables
+  nodelocal         upload and manage files in the external IO directory of nodes
+  userfile          upload, list and manage files stored in the database
+  statement-diag    list, request, download and cancel statement diagnostics bundles
 
   demo              open a demo sql shell
   gen               generate auxiliary files
+  convert-url       convert a SQL connection URL for use with various client drivers
   version           output version information
   debug             debugging commands
   sqlfmt            format SQL statements
@@ -1573,12 +1683,14 @@ Available Commands:
 
 Flags:
   -h, --help                             help for cockroach
+      --log-format string                format of log entries written to files and stderr (text or json) (default "text")
       --logtostderr Severity[=DEFAULT]   logs at or above this threshold go to stderr (default NONE)
       --no-color                         disable standard error log colorization
 
 Use "cockroach [command] --help" for more information about a command.
 `
-	helpExpected := fmt.Sprintf("CockroachDB command-line interface and server.\n\n%s", expUsage)
+	helpExpected := fmt.Sprintf("CockroachDB command-line interface and server.\n\n%s\n\n%s",
+		exitCodesHelp, expUsage)
 	badFlagExpected := fmt.Sprintf("%s\nError: unknown flag: --foo\n", expUsage)
 
 	testCases := []struct {
@@ -2025,6 +2137,85 @@ func TestGenAutocomplete(t *testing.T) {
 	}
 }
 
+func TestGenSettingsList(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	out, err := c.RunWithCapture("gen settings-list --format=csv --type=duration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "\nserver.shutdown.query_wait,duration,10s,") {
+		t.Errorf("expected duration setting in output, got:\n%s", out)
+	}
+	if strings.Contains(out, ",boolean,") {
+		t.Errorf("expected only duration settings in output, got:\n%s", out)
+	}
+
+	c.RunWithArgs([]string{"sql", "-e", "SET CLUSTER SETTING server.shutdown.query_wait = '5s'"})
+	out, err = c.RunWithCapture("gen settings-list --format=csv --changed --type=duration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the changed settings are listed, along with their current value.
+	for _, expected := range []string{
+		"\nSetting,Type,Default,Description,Value\n",
+		"\nserver.shutdown.query_wait,duration,10s,the server will wait for at least this " +
+			"amount of time for active queries to finish,5s\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "\nserver.shutdown.drain_wait,") {
+		t.Errorf("expected unchanged settings to be omitted, got:\n%s", out)
+	}
+
+	out, err = c.RunWithCapture("gen settings-list --changed --visibility=all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "--changed requires --visibility=public") {
+		t.Errorf("expected error, got:\n%s", out)
+	}
+}
+
+func TestVersionDetail(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer build.TestingOverrideTag("v19.2.0")()
+	build.RegisterFeature("test-feature")
+
+	var buf bytes.Buffer
+	if err := printVersionDetail(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var d versionDetail
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if d.Build.Tag != "v19.2.0" {
+		t.Errorf("expected tag v19.2.0, got %q", d.Build.Tag)
+	}
+	if !reflect.DeepEqual(d.StorageEngines, []string{"rocksdb", "pebble"}) ||
+		d.DefaultStorageEngine != "rocksdb" {
+		t.Errorf("unexpected storage engines %q (default %q)", d.StorageEngines, d.DefaultStorageEngine)
+	}
+	found := false
+	for _, f := range d.CCLFeatures {
+		found = found || f == "test-feature"
+	}
+	if !found {
+		t.Errorf("expected test-feature in %q", d.CCLFeatures)
+	}
+	if d.ClusterVersion.Binary == "" || d.ClusterVersion.MinimumSupported == "" ||
+		d.Toolchain.GoVersion == "" {
+		t.Errorf("missing fields in output:\n%s", buf.String())
+	}
+}
+
 func TestJunkPositionalArguments(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -2132,6 +2323,8 @@ writing ` + os.DevNull + `
   debug/crdb_internal.schema_changes.txt
   debug/crdb_internal.partitions.txt
   debug/crdb_internal.zones.txt
+  debug/system.descriptor.txt
+  debug/system.namespace.txt
   debug/nodes/1/status.json
   debug/nodes/1/crdb_internal.feature_usage.txt
   debug/nodes/1/crdb_internal.gossip_alerts.txt
@@ -2150,6 +2343,9 @@ writing ` + os.DevNull + `
   debug/nodes/1/enginestats.json
   debug/nodes/1/stacks.txt
   debug/nodes/1/heap.pprof
+  debug/nodes/1/goroutine.pprof
+  debug/nodes/1/mutex.pprof
+  debug/nodes/1/block.pprof
   debug/nodes/1/ranges/1.json
   debug/nodes/1/ranges/2.json
   debug/nodes/1/ranges/3.json
@@ -2170,6 +2366,8 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/18.json
   debug/nodes/1/ranges/19.json
   debug/nodes/1/ranges/20.json
+  debug/nodes/1/ranges/21.json
+  debug/nodes/1/ranges/22.json
   debug/schema/defaultdb@details.json
   debug/schema/postgres@details.json
   debug/schema/system@details.json
@@ -2182,8 +2380,10 @@ writing ` + os.DevNull + `
   debug/schema/system/namespace.json
   debug/schema/system/rangelog.json
   debug/schema/system/role_members.json
+  debug/schema/system/scheduled_jobs.json
   debug/schema/system/settings.json
   debug/schema/system/table_statistics.json
+  debug/schema/system/transaction_retry_statistics.json
   debug/schema/system/ui.json
   debug/schema/system/users.json
   debug/schema/system/web_sessions.json
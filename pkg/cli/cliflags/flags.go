@@ -187,6 +187,13 @@ If left unspecified, defaults to 128MiB. A percentage of physical memory
 can also be specified (e.g. .25).`,
 	}
 
+	StorageEngine = FlagInfo{
+		Name: "storage-engine",
+		Description: `
+Storage engine to use for all stores on this cockroach node. Options are rocksdb,
+or pebble.`,
+	}
+
 	ClientHost = FlagInfo{
 		Name:   "host",
 		EnvVar: "COCKROACH_HOST",
@@ -238,6 +245,40 @@ with a non-zero status code and further statements are not executed. The
 results of each SQL statement are printed on the standard output.`,
 	}
 
+	SQLFile = FlagInfo{
+		Name:      "file",
+		Shorthand: "f",
+		Description: `
+Read the SQL statements to execute from the given file instead of the standard
+input, then exit.`,
+	}
+
+	SQLBatchSize = FlagInfo{
+		Name: "batch-size",
+		Description: `
+Execute the statements read from a file or from the standard input in batches
+of the given number of statements, each batch in its own transaction. A failed
+batch is rolled back, and the batches before it remain committed, so that the
+script can be resumed with --skip-batches. The input must not contain
+transaction control statements, and the results of the statements are not
+printed. Zero disables batching.`,
+	}
+
+	SQLSkipBatches = FlagInfo{
+		Name: "skip-batches",
+		Description: `
+Skip the given number of batches at the beginning of the input, for example to
+resume a script that failed. Requires --batch-size.`,
+	}
+
+	SQLContinueOnError = FlagInfo{
+		Name: "continue-on-error",
+		Description: `
+When a batch fails, roll it back and continue with the next batch instead of
+stopping. The command still exits with an error listing the failed batches.
+Requires --batch-size.`,
+	}
+
 	EchoSQL = FlagInfo{
 		Name: "echo-sql",
 		Description: `
@@ -275,8 +316,14 @@ shell. This flag may be specified multiple times.`,
 		Name: "format",
 		Description: `
 Selects how to display table rows in results. Possible values: tsv,
-csv, table, records, sql, raw, html. If left unspecified, defaults to
-tsv for non-interactive sessions and table for interactive sessions.`,
+csv, table, records, sql, raw, html, json, ndjson. If left unspecified,
+defaults to tsv for non-interactive sessions and table for interactive
+sessions.
+
+The json format renders the result as an array of objects, one per row,
+keyed by column name; ndjson renders one such object per line. Values of
+numeric, boolean and JSON columns are rendered as the corresponding JSON
+values, NULL as null, and all other values as strings.`,
 	}
 
 	ClusterName = FlagInfo{
@@ -669,6 +716,18 @@ for example:
   --store=path=/mnt/ssd01,size=0.2             -> 20% of available space
   --store=path=/mnt/ssd01,size=.2              -> 20% of available space
 
+</PRE>
+The "ballast-size" field sets the size of the emergency ballast file that is
+kept in the auxiliary directory of the store. A node does not start when the
+space available on the disk of one of its stores falls below half of the size
+of the ballast; removing the ballast file frees up space for the node to start
+and recover. The size is given in a bytes-based unit or as a percentage of hard
+drive space, and defaults to 1% of the disk, up to 1GiB. A size of zero
+disables the ballast, for example:
+<PRE>
+
+  --store=path=/mnt/ssd01,ballast-size=2GiB
+
 </PRE>
 For an in-memory store, the "type" and "size" fields are required, and the
 "path" field is forbidden. The "type" field must be set to "mem", and the
@@ -800,6 +859,95 @@ File containing the JSON output from a node's /_status/gossip/ endpoint.
 If specified, takes priority over host/port flags.`,
 	}
 
+	ZipRedact = FlagInfo{
+		Name: "redact",
+		Description: `
+If specified, hide user data in the generated zip file. Keys, SQL constants and
+escaped byte sequences in logs, system tables and range data are replaced by
+salted hashes, so that the zip can be shared without revealing the data stored
+in the cluster. In the logs written with --redactable-logs, all the data
+enclosed in redaction markers is replaced.`,
+	}
+
+	StmtDiagMinLatency = FlagInfo{
+		Name: "min-latency",
+		Description: `
+Only collect the diagnostics of an execution of the statement which takes at
+least this long. The faster executions are traced, but their bundles are
+discarded and the request remains pending.`,
+	}
+
+	StmtDiagExpiresAfter = FlagInfo{
+		Name: "expires-after",
+		Description: `
+Discard the request if it hasn't been completed after this amount of time. The
+request doesn't expire if zero.`,
+	}
+
+	StmtDiagSamplingProbability = FlagInfo{
+		Name: "sampling-probability",
+		Description: `
+Fraction, between 0 and 1, of the matching executions to trace. All of them are
+traced if zero. Sampling limits the overhead of tracing a frequent statement
+while waiting for a slow execution with --min-latency.`,
+	}
+
+	VersionDetail = FlagInfo{
+		Name: "detail",
+		Description: `
+Output detailed build information as JSON, including the storage engines, the
+CCL features compiled into the binary, the range of compatible cluster
+versions and the toolchain.`,
+	}
+
+	DebugFilesNodes = FlagInfo{
+		Name: "nodes",
+		Description: `
+Comma-separated list of the IDs of the nodes whose files are listed or
+retrieved. By default, the files of all the nodes are.`,
+	}
+
+	DebugFileTypes = FlagInfo{
+		Name: "file-types",
+		Description: `
+Comma-separated list of the types of files to list or retrieve. Possible
+values: log, heap, goroutines. By default, files of all types are.`,
+	}
+
+	DebugFilePattern = FlagInfo{
+		Name: "files",
+		Description: `
+Glob pattern restricting the names of the files to list or retrieve.`,
+	}
+
+	TimeSeriesDumpFormat = FlagInfo{
+		Name: "format",
+		Description: `
+Selects how to print the time series data. Possible values: raw, csv,
+openmetrics.`,
+	}
+
+	TimeSeriesDumpMetrics = FlagInfo{
+		Name: "metrics",
+		Description: `
+Comma-separated list of the names of the metrics to dump, for example
+cr.node.sql.conns. If left unspecified, all metrics are dumped.`,
+	}
+
+	TimeSeriesDumpFrom = FlagInfo{
+		Name: "from",
+		Description: `
+Only dump the datapoints at or after this time, in RFC 3339 format
+(for example, 2019-10-01T00:00:00Z).`,
+	}
+
+	TimeSeriesDumpTo = FlagInfo{
+		Name: "to",
+		Description: `
+Only dump the datapoints before this time, in RFC 3339 format
+(for example, 2019-10-02T00:00:00Z).`,
+	}
+
 	PrintSystemConfig = FlagInfo{
 		Name: "print-system-config",
 		Description: `
@@ -814,6 +962,57 @@ If specified, decommissions the node and waits for it to rebalance before
 shutting down the node.`,
 	}
 
+	SettingsVisibility = FlagInfo{
+		Name: "visibility",
+		Description: `
+Which cluster settings to list: the public settings, the reserved settings that
+are not meant to be changed by users, or all of them.
+<PRE>
+
+  public, reserved, all
+
+</PRE>`,
+	}
+
+	SettingsType = FlagInfo{
+		Name: "type",
+		Description: `
+Only list the cluster settings of the given types, for example
+--type=duration,boolean. By default, settings of all types are listed.`,
+	}
+
+	SettingsChanged = FlagInfo{
+		Name: "changed",
+		Description: `
+Connect to a cluster and only list the settings whose value differs from the
+default, along with their current value.`,
+	}
+
+	DrainTimeout = FlagInfo{
+		Name: "drain-timeout",
+		Description: `
+Amount of time to wait for the node to drain before returning. When the node
+fails to drain in time, quit proceeds with a hard shutdown while node drain
+returns an error.`,
+	}
+
+	DrainWait = FlagInfo{
+		Name: "drain-wait",
+		Description: `
+Amount of time the node waits in an unready state, failing health checks, before
+it starts draining SQL clients, so that load balancers can redirect new
+connections to other nodes. Overrides the server.shutdown.drain_wait cluster
+setting when non-zero.`,
+	}
+
+	QueryWait = FlagInfo{
+		Name: "query-wait",
+		Description: `
+Maximum amount of time the node waits for active queries to finish while
+draining SQL clients. Overrides the server.shutdown.query_wait cluster setting
+when non-zero.`,
+	}
+
 	Wait = FlagInfo{
 		Name: "wait",
 		Description: `
@@ -829,6 +1028,15 @@ Takes any of the following values:
 </PRE>`,
 	}
 
+	DecommissionStallTimeout = FlagInfo{
+		Name: "stall-timeout",
+		Description: `
+If nonzero, return with an error if the number of replicas on the target nodes
+has not decreased for the specified duration while waiting for decommissioning
+to complete. The timeout is specified with a suffix of 's' for seconds, 'm' for
+minutes, and 'h' for hours.`,
+	}
+
 	Timeout = FlagInfo{
 		Name: "timeout",
 		Description: `
@@ -889,6 +1097,30 @@ The line length where sqlfmt will try to wrap.`,
 		Description: `How many in-memory nodes to create for the demo.`,
 	}
 
+	DemoNodeLocality = FlagInfo{
+		Name: "demo-locality",
+		Description: `
+Locality information for each demo node. The input is a colon separated
+list of localities for each node. The i'th locality in the colon separated
+list sets the locality for the i'th demo cockroach node. For example:
+<PRE>
+
+--demo-locality=region=us-east1,az=1:region=us-east1,az=2:region=us-east1,az=3
+
+</PRE>
+If unspecified, the nodes of a multi-node demo cluster are spread across
+three regions: us-east1, us-west1 and europe-west1.`,
+	}
+
+	Global = FlagInfo{
+		Name: "global",
+		Description: `
+Simulate a global cluster by adding artificial latencies to the network
+requests between nodes in different regions, as determined by their
+localities. The latencies are those observed between the corresponding
+regions of a public cloud. Requires at least 3 nodes.`,
+	}
+
 	UseEmptyDatabase = FlagInfo{
 		Name: "empty",
 		Description: `
@@ -925,6 +1157,50 @@ Minimum verbosity of messages written to the log file.
 `,
 	}
 
+	LogSink = FlagInfo{
+		Name: "log-sink",
+		Description: `
+Comma-separated list of network sinks to which log entries are sent, in
+addition to the log files. Each sink has the form
+<PRE>
+
+  [<channel>=]<scheme>://<host>:<port>
+
+</PRE>
+where the scheme is syslog+tcp or syslog+udp for a syslog server, or fluent for
+the forward input of a Fluentd server. The channel is the name of a logger,
+e.g. cockroach or cockroach-sql-audit; a sink without a channel receives the
+entries of all loggers. Entries are buffered while a server is unavailable.
+The flag can be specified multiple times.
+`,
+	}
+
+	LogChannel = FlagInfo{
+		Name: "log-channel",
+		Description: `
+Rotation and retention limits of the log files of a log channel, which default
+to --log-file-max-size and --log-dir-max-size. For example:
+<PRE>
+
+  --log-channel=SENSITIVE_ACCESS:file-max-size=50MiB,group-max-size=10GiB
+
+</PRE>
+The channels are OPS, HEALTH, SQL_EXEC, SENSITIVE_ACCESS, STORAGE and SQL_PERF.
+The flag can be specified once per channel.
+`,
+	}
+
+	RedactableLogs = FlagInfo{
+		Name: "redactable-logs",
+		Description: `
+Enclose the data which may be sensitive, such as SQL statements, keys and
+client addresses, in redaction markers (‹ and ›) in the log messages. This
+allows the user data to be stripped mechanically from the log files, for
+example by "cockroach debug zip --redact", while the operational details of
+the messages are preserved.
+`,
+	}
+
 	WriteSize = FlagInfo{
 		Name: "write-size",
 		Description: `
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -87,6 +88,10 @@ func initCLIDefaults() {
 	sqlCtx.showTimes = false
 	sqlCtx.debugMode = false
 	sqlCtx.echo = false
+	sqlCtx.inputFile = ""
+	sqlCtx.batchSize = 0
+	sqlCtx.skipBatches = 0
+	sqlCtx.continueOnError = false
 
 	dumpCtx.dumpMode = dumpBoth
 	dumpCtx.asOf = ""
@@ -100,13 +105,24 @@ func initCLIDefaults() {
 	debugCtx.printSystemConfig = false
 	debugCtx.maxResults = 1000
 	debugCtx.ballastSize = base.SizeSpec{InBytes: 1000000000}
+	debugCtx.zipRedact = false
+	debugCtx.nodes = nil
+	debugCtx.fileTypes = nil
+	debugCtx.filePattern = "*"
+	debugCtx.tsDumpFormat = tsDumpRaw
+	debugCtx.tsDumpMetrics = nil
+	debugCtx.tsDumpFrom = timeValue{}
+	debugCtx.tsDumpTo = timeValue{}
 
 	serverCfg.ReadyFn = nil
 	serverCfg.DelayedBootstrapFn = nil
 	serverCfg.SocketFile = ""
 	serverCfg.JoinList = nil
+	serverCfg.StorageEngine = enginepb.EngineTypeRocksDB
 	serverCfg.DefaultZoneConfig = config.DefaultZoneConfig()
 	serverCfg.DefaultSystemZoneConfig = config.DefaultSystemZoneConfig()
+	serverCfg.DrainWait = 0
+	serverCfg.QueryWait = 0
 
 	startCtx.serverInsecure = baseCfg.Insecure
 	startCtx.serverSSLCertsDir = base.DefaultCertsDirectory
@@ -117,9 +133,21 @@ func initCLIDefaults() {
 	startCtx.pidFile = ""
 	startCtx.inBackground = false
 
+	genSettingsListCtx.visibility = settingsPublic
+	genSettingsListCtx.types = nil
+	genSettingsListCtx.changed = false
+
+	versionCtx.detail = false
+
 	quitCtx.serverDecommission = false
+	quitCtx.drainTimeout = time.Minute
+
+	stmtDiagCtx.minLatency = 0
+	stmtDiagCtx.expiresAfter = 0
+	stmtDiagCtx.samplingProbability = 0
 
 	nodeCtx.nodeDecommissionWait = nodeDecommissionWaitAll
+	nodeCtx.nodeDecommissionStallTimeout = 0
 	nodeCtx.statusShowRanges = false
 	nodeCtx.statusShowStats = false
 	nodeCtx.statusShowAll = false
@@ -145,6 +173,8 @@ func initCLIDefaults() {
 
 	demoCtx.nodes = 1
 	demoCtx.useEmptyDatabase = false
+	demoCtx.localities = nil
+	demoCtx.simulateLatency = false
 
 	initPreFlagsDefaults()
 
@@ -230,6 +260,22 @@ var sqlCtx = struct {
 	// "intelligent behavior" in the SQL shell as possible and become
 	// more verbose (sets echo).
 	debugMode bool
+
+	// inputFile is the file to read statements from instead of the
+	// standard input.
+	inputFile string
+
+	// batchSize, when non-zero, requests that the statements read from
+	// the input be executed in transactions of batchSize statements.
+	batchSize int
+
+	// skipBatches is the number of batches to skip at the beginning of
+	// the input.
+	skipBatches int
+
+	// continueOnError, when set, requests that the execution continue
+	// with the next batch when a batch fails.
+	continueOnError bool
 }{cliContext: &cliCtx}
 
 // dumpCtx captures the command-line parameters of the `dump` command.
@@ -253,6 +299,14 @@ var debugCtx struct {
 	ballastSize       base.SizeSpec
 	printSystemConfig bool
 	maxResults        int64
+	zipRedact         bool
+	nodes             []string
+	fileTypes         []string
+	filePattern       string
+	tsDumpFormat      tsDumpFormat
+	tsDumpMetrics     []string
+	tsDumpFrom        timeValue
+	tsDumpTo          timeValue
 }
 
 // startCtx captures the command-line arguments for the `start` command.
@@ -285,20 +339,49 @@ var startCtx struct {
 	logDir log.DirName
 }
 
+// genSettingsListCtx captures the command-line parameters of the `gen
+// settings-list` command.
+// Defaults set by InitCLIDefaults() above.
+var genSettingsListCtx struct {
+	visibility settingsVisibility
+	types      []string
+	changed    bool
+}
+
+// versionCtx captures the command-line parameters of the `version` command.
+// Defaults set by InitCLIDefaults() above.
+var versionCtx struct {
+	// detail outputs detailed build information as JSON.
+	detail bool
+}
+
 // quitCtx captures the command-line parameters of the `quit` command.
 // Defaults set by InitCLIDefaults() above.
 var quitCtx struct {
 	serverDecommission bool
+	// drainTimeout is the amount of time to wait for the node to drain,
+	// used by both quit and node drain.
+	drainTimeout time.Duration
+}
+
+// stmtDiagCtx captures the command-line parameters of the `statement-diag`
+// commands.
+// Defaults set by InitCLIDefaults() above.
+var stmtDiagCtx struct {
+	minLatency          time.Duration
+	expiresAfter        time.Duration
+	samplingProbability float64
 }
 
 // nodeCtx captures the command-line parameters of the `node` command.
 // Defaults set by InitCLIDefaults() above.
 var nodeCtx struct {
-	nodeDecommissionWait   nodeDecommissionWaitType
-	statusShowRanges       bool
-	statusShowStats        bool
-	statusShowDecommission bool
-	statusShowAll          bool
+	nodeDecommissionWait         nodeDecommissionWaitType
+	nodeDecommissionStallTimeout time.Duration
+	statusShowRanges             bool
+	statusShowStats              bool
+	statusShowDecommission       bool
+	statusShowAll                bool
 }
 
 // systemBenchCtx captures the command-line parameters of the `systembench` command.
@@ -334,4 +417,6 @@ var sqlfmtCtx struct {
 var demoCtx struct {
 	nodes            int
 	useEmptyDatabase bool
+	localities       demoLocalityList
+	simulateLatency  bool
 }
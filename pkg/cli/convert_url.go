@@ -0,0 +1,130 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var convertURLCmd = &cobra.Command{
+	Use:   "convert-url [options]",
+	Short: "convert a SQL connection URL for use with various client drivers",
+	Long: `
+Convert the connection parameters given with --url, or with discrete flags
+such as --host, --user and --certs-dir, into the connection strings expected
+by common drivers and tools: a PostgreSQL URL, a JDBC URL, a psql command
+line and a libpq key/value DSN. TLS parameters are included for secure
+clusters.
+
+Note that the JDBC driver requires the client key in PKCS#8 format, as
+produced by cockroach cert create-client --also-generate-pkcs8-key.
+`,
+	Example: `  cockroach convert-url --url "postgresql://root@localhost:26257/defaultdb?sslmode=disable"
+  cockroach convert-url --host db.example.com --user maxroach --certs-dir certs`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runConvertURL),
+}
+
+func runConvertURL(cmd *cobra.Command, args []string) error {
+	u, err := cliCtx.makeClientConnURL()
+	if err != nil {
+		return err
+	}
+	printConvertedURLs(os.Stdout, u)
+	return nil
+}
+
+// printConvertedURLs prints the connection URL u in all the supported
+// formats.
+func printConvertedURLs(w io.Writer, u url.URL) {
+	fmt.Fprintf(w, "# Connection URL for drivers that accept PostgreSQL URLs:\n%s\n\n", u.String())
+	fmt.Fprintf(w, "# JDBC connection URL:\n%s\n\n", jdbcURL(u))
+	fmt.Fprintf(w, "# psql command line:\npsql %s\n\n", shellQuote(libpqDSN(u)))
+	fmt.Fprintf(w, "# libpq key/value connection string (DSN):\n%s\n", libpqDSN(u))
+}
+
+// jdbcURL converts a PostgreSQL URL to the format expected by the
+// PostgreSQL JDBC driver, which takes the user name and password as
+// parameters and needs the client key in PKCS#8 format.
+func jdbcURL(u url.URL) string {
+	opts := u.Query()
+	if u.User != nil {
+		opts.Set("user", u.User.Username())
+		if pw, ok := u.User.Password(); ok {
+			opts.Set("password", pw)
+		}
+	}
+	if key := opts.Get("sslkey"); key != "" {
+		opts.Set("sslkey", key+".pk8")
+	}
+	jdbc := url.URL{
+		Host:     u.Host,
+		Path:     "/" + strings.TrimLeft(u.Path, "/"),
+		RawQuery: opts.Encode(),
+	}
+	return "jdbc:postgresql:" + jdbc.String()
+}
+
+// libpqDSN converts a PostgreSQL URL to a libpq key/value connection
+// string.
+func libpqDSN(u url.URL) string {
+	var params []string
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if !strings.ContainsAny(value, ` '\`) {
+			params = append(params, key+"="+value)
+			return
+		}
+		value = strings.Replace(value, `\`, `\\`, -1)
+		value = strings.Replace(value, `'`, `\'`, -1)
+		params = append(params, key+"='"+value+"'")
+	}
+
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host = u.Host
+	}
+	add("host", host)
+	add("port", port)
+	add("dbname", strings.TrimLeft(u.Path, "/"))
+	if u.User != nil {
+		add("user", u.User.Username())
+		if pw, ok := u.User.Password(); ok {
+			add("password", pw)
+		}
+	}
+	opts := u.Query()
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(k, opts.Get(k))
+	}
+	return strings.Join(params, " ")
+}
+
+// shellQuote quotes a string for use as a single argument in a POSIX
+// shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, `'`, `'\''`, -1) + "'"
+}
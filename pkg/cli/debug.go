@@ -26,6 +26,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/cli/syncbench"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
@@ -55,6 +57,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/kr/pretty"
+	"github.com/petermattis/pebble/tool"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"go.etcd.io/etcd/raft/raftpb"
@@ -71,11 +74,20 @@ Pretty-prints all keys in a store.
 }
 
 var debugBallastCmd = &cobra.Command{
-	Use:   "ballast <file>",
+	Use:   "ballast <file | store directory>",
 	Short: "create a ballast file",
 	Long: `
-Create a ballast file to fill the store directory up to a given amount
+Create a ballast file to fill the store directory up to a given amount.
+
+When given a store directory instead of a file, create or resize the emergency
+ballast of the store instead. A node refuses to start when the disk of one of
+its stores is nearly full; removing the emergency ballast frees up enough space
+for the node to start and recover. The ballast is re-created automatically
+when the node starts with enough space available. Without --size, the size
+configured for the store applies: 1% of the disk, up to 1 GiB.
 `,
+	Example: `  cockroach debug ballast /mnt/data1/ballast --size=90%
+  cockroach debug ballast /mnt/data1 --size=2GiB`,
 	Args: cobra.ExactArgs(1),
 	RunE: runDebugBallast,
 }
@@ -182,6 +194,9 @@ func runDebugKeys(cmd *cobra.Command, args []string) error {
 
 func runDebugBallast(cmd *cobra.Command, args []string) error {
 	ballastFile := args[0] // we use cobra.ExactArgs(1)
+	if info, err := os.Stat(ballastFile); err == nil && info.IsDir() {
+		return runDebugEmergencyBallast(ballastFile, cmd.Flags().Changed(cliflags.Size.Name))
+	}
 	dataDirectory := filepath.Dir(ballastFile)
 
 	fs, err := sysutil.StatFS(dataDirectory)
@@ -234,6 +249,34 @@ func runDebugBallast(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runDebugEmergencyBallast creates or resizes the emergency ballast of the
+// store in the given directory, to the size given with --size if sizeSet,
+// or to the default ballast size otherwise.
+func runDebugEmergencyBallast(dir string, sizeSet bool) error {
+	fs, err := sysutil.StatFS(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat filesystem %s", dir)
+	}
+	total := fs.TotalBlocks * fs.BlockSize
+	avail := fs.AvailBlocks * fs.BlockSize
+
+	spec := base.StoreSpec{Path: dir}
+	if sizeSet {
+		size := debugCtx.ballastSize
+		if size.InBytes < 0 || size.Percent < 0 || size.Percent > 50 {
+			return errors.Errorf("emergency ballast size must be between 0 and 50%% of the disk")
+		}
+		spec.BallastSize = &size
+	}
+	path := engine.EmergencyBallastFile(dir)
+	size := engine.BallastSizeBytes(spec, total)
+	if _, err := engine.ResizeBallast(path, size, avail); err != nil {
+		return err
+	}
+	fmt.Printf("emergency ballast %s resized to %s\n", path, humanizeutil.IBytes(size))
+	return nil
+}
+
 var debugRangeDataCmd = &cobra.Command{
 	Use:   "range-data <directory> <range id>",
 	Short: "dump all the data in a range",
@@ -758,6 +801,17 @@ https://github.com/facebook/rocksdb/wiki/Administration-and-Data-Access-Tool#ldb
 	},
 }
 
+var debugPebbleCmd = &cobra.Command{
+	Use:   "pebble [command]",
+	Short: "run a Pebble introspection tool command",
+	Long: `
+Allows the use of Pebble tools, such as to introspect manifests, sstables, the
+WAL, or to check the consistency of a store. Keys are printed using the
+CockroachDB MVCC key encoding.
+`,
+	RunE: usageAndErr,
+}
+
 var debugSSTDumpCmd = &cobra.Command{
 	Use:   "sst_dump",
 	Short: "run the RocksDB 'sst_dump' tool",
@@ -789,10 +843,16 @@ var debugCompactCmd = &cobra.Command{
 	Use:   "compact <directory>",
 	Short: "compact the sstables in a store",
 	Long: `
-Compact the sstables in a store.
+Compact the sstables in a store. The node using the store must be stopped.
+
+By default, the whole store is compacted. With --from and/or --to, only
+the given key range is compacted down to the bottommost level, which
+reclaims the space used by range tombstones left by mass deletions
+without rewriting the rest of the store.
 `,
-	Args: cobra.ExactArgs(1),
-	RunE: MaybeDecorateGRPCError(runDebugCompact),
+	Example: `  cockroach debug compact /mnt/data1 --from=/Table/53 --to=/Table/54`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    MaybeDecorateGRPCError(runDebugCompact),
 }
 
 func runDebugCompact(cmd *cobra.Command, args []string) error {
@@ -804,20 +864,34 @@ func runDebugCompact(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	start, end := roachpb.KeyMin, roachpb.KeyMax
+	wholeStore := debugCtx.startKey.Equal(engine.NilKey) && debugCtx.endKey.Equal(engine.MVCCKeyMax)
+	if !wholeStore {
+		start, end = debugCtx.startKey.Key, debugCtx.endKey.Key
+		if end.Compare(start) <= 0 {
+			return errors.Errorf("invalid key range: %s must be before %s", start, end)
+		}
+	}
+
 	{
-		approxBytesBefore, err := db.ApproximateDiskBytes(roachpb.KeyMin, roachpb.KeyMax)
+		approxBytesBefore, err := db.ApproximateDiskBytes(start, end)
 		if err != nil {
 			return errors.Wrap(err, "while computing approximate size before compaction")
 		}
 		fmt.Printf("approximate reported database size before compaction: %s\n", humanizeutil.IBytes(int64(approxBytesBefore)))
 	}
 
-	if err := db.Compact(); err != nil {
+	if wholeStore {
+		err = db.Compact()
+	} else {
+		err = db.CompactRange(start, end, true /* forceBottommost */)
+	}
+	if err != nil {
 		return errors.Wrap(err, "while compacting")
 	}
 
 	{
-		approxBytesAfter, err := db.ApproximateDiskBytes(roachpb.KeyMin, roachpb.KeyMax)
+		approxBytesAfter, err := db.ApproximateDiskBytes(start, end)
 		if err != nil {
 			return errors.Wrap(err, "while computing approximate size after compaction")
 		}
@@ -998,8 +1072,17 @@ var debugTimeSeriesDumpCmd = &cobra.Command{
 	Use:   "tsdump",
 	Short: "dump all the raw timeseries values in a cluster",
 	Long: `
-Dumps all of the raw timeseries values in a cluster.
+Dumps all of the raw timeseries values in a cluster. Only the data at the
+10-second resolution is dumped.
+
+With --format=csv, one record is printed per datapoint, with the name, the
+source, the timestamp and the value of the datapoint. With
+--format=openmetrics, the datapoints are printed in the OpenMetrics text
+exposition format, with the source as a label, so that the dump can be
+loaded into tools that understand Prometheus data.
 `,
+	Example: `  cockroach debug tsdump --format=csv --metrics=cr.node.sql.conns \
+    --from=2019-10-01T00:00:00Z --to=2019-10-02T00:00:00Z`,
 	RunE: MaybeDecorateGRPCError(runTimeSeriesDump),
 }
 
@@ -1019,21 +1102,22 @@ func runTimeSeriesDump(cmd *cobra.Command, args []string) error {
 		log.Fatal(context.Background(), err)
 	}
 
-	var name, source string
+	filter := makeTSDumpFilter(debugCtx.tsDumpMetrics,
+		time.Time(debugCtx.tsDumpFrom), time.Time(debugCtx.tsDumpTo))
+	w := makeTSWriter(os.Stdout, debugCtx.tsDumpFormat)
 	for {
 		data, err := stream.Recv()
 		if err != nil {
 			if err != io.EOF {
 				return err
 			}
-			return nil
+			return w.Flush()
 		}
-		if name != data.Name || source != data.Source {
-			name, source = data.Name, data.Source
-			fmt.Printf("%s %s\n", name, source)
+		if data = filter.apply(data); data == nil {
+			continue
 		}
-		for _, d := range data.Datapoints {
-			fmt.Printf("%d %v\n", d.TimestampNanos, d.Value)
+		if err := w.Emit(data); err != nil {
+			return err
 		}
 	}
 }
@@ -1120,7 +1204,12 @@ func runDebugUnsafeRemoveDeadReplicas(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 	defer batch.Close()
+	return confirmAndCommitBatch(batch)
+}
 
+// confirmAndCommitBatch prompts the user for confirmation on stdin and
+// commits the batch if it is given.
+func confirmAndCommitBatch(batch engine.Batch) error {
 	fmt.Printf("Proceed with the above rewrites? [y/N] ")
 
 	reader := bufio.NewReader(os.Stdin)
@@ -1143,8 +1232,6 @@ func runDebugUnsafeRemoveDeadReplicas(cmd *cobra.Command, args []string) error {
 func removeDeadReplicas(
 	db engine.Engine, deadStoreIDs map[roachpb.StoreID]struct{},
 ) (engine.Batch, error) {
-	clock := hlc.NewClock(hlc.UnixNano, 0)
-
 	ctx := context.Background()
 
 	storeIdent, err := storage.ReadStoreIdent(ctx, db)
@@ -1197,7 +1284,16 @@ func removeDeadReplicas(
 	if len(newDescs) == 0 {
 		return nil, nil
 	}
+	return writeRangeDescriptors(ctx, db, newDescs)
+}
 
+// writeRangeDescriptors returns a batch which overwrites the range descriptors
+// of the given ranges. Intents on the descriptors are resolved by aborting the
+// transactions that wrote them.
+func writeRangeDescriptors(
+	ctx context.Context, db engine.Engine, newDescs []roachpb.RangeDescriptor,
+) (engine.Batch, error) {
+	clock := hlc.NewClock(hlc.UnixNano, 0)
 	batch := db.NewBatch()
 	for _, desc := range newDescs {
 		key := keys.RangeDescriptorKey(desc.StartKey)
@@ -1292,15 +1388,20 @@ var debugCmds = append(DebugCmdsForRocksDB,
 	debugDecodeKeyCmd,
 	debugDecodeValueCmd,
 	debugRocksDBCmd,
+	debugPebbleCmd,
 	debugSSTDumpCmd,
 	debugGossipValuesCmd,
 	debugTimeSeriesDumpCmd,
 	debugSyncBenchCmd,
 	debugSyncTestCmd,
 	debugUnsafeRemoveDeadReplicasCmd,
+	debugRecoverCmd,
 	debugEnvCmd,
 	debugZipCmd,
+	debugListFilesCmd,
+	debugGetFilesCmd,
 	debugMergeLogsCommand,
+	debugDoctorCmd,
 )
 
 // DebugCmd is the root of all debug commands. Exported to allow modification by CCL code.
@@ -1318,6 +1419,15 @@ process that has failed and cannot restart.
 func init() {
 	DebugCmd.AddCommand(debugCmds...)
 
+	// To be able to read CockroachDB-written manifests, sstables and WAL
+	// entries, the Pebble tools need the same comparator and merge operator
+	// that were used to write them. The comparator also teaches the tools how
+	// to pretty-print MVCC keys.
+	pebbleTool := tool.New()
+	pebbleTool.RegisterComparer(engine.MVCCComparer)
+	pebbleTool.RegisterMerger(engine.MVCCMerger)
+	debugPebbleCmd.AddCommand(pebbleTool.Commands...)
+
 	f := debugSyncBenchCmd.Flags()
 	f.IntVarP(&syncBenchOpts.Concurrency, "concurrency", "c", syncBenchOpts.Concurrency,
 		"number of concurrent writers")
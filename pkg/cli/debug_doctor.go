@@ -0,0 +1,300 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	gohex "encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/doctor"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugDoctorCmd = &cobra.Command{
+	Use:   "doctor [command]",
+	Short: "check the consistency of the SQL schema of a cluster",
+	Long: `
+Check the consistency of the descriptors, namespace entries and jobs of a
+cluster, either live or as captured in a debug zip. Dangling descriptors and
+namespace entries, jobs referring to missing descriptors and invalid foreign
+key back-references are reported. When possible, SQL statements repairing
+the problems are printed as well; they modify system tables directly and
+must be reviewed before being run.
+
+The command exits with a non-zero status if problems were found.
+`,
+	RunE: usageAndErr,
+}
+
+var debugDoctorClusterCmd = &cobra.Command{
+	Use:   "cluster [options]",
+	Short: "check the consistency of the SQL schema of a live cluster",
+	Long: `
+Check the consistency of the SQL schema of the cluster the command connects
+to. The command must be run as root.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDebugDoctorCluster),
+}
+
+var debugDoctorZipCmd = &cobra.Command{
+	Use:   "zip <file>",
+	Short: "check the consistency of the SQL schema captured in a debug zip",
+	Long: `
+Check the consistency of the SQL schema captured in a debug zip produced by
+cockroach debug zip. Zips produced with --redact cannot be examined.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugDoctorZip,
+}
+
+var debugDoctorCmds = []*cobra.Command{
+	debugDoctorClusterCmd,
+	debugDoctorZipCmd,
+}
+
+func init() {
+	debugDoctorCmd.AddCommand(debugDoctorCmds...)
+}
+
+// The tables examined by the doctor, as named in debug zips.
+const (
+	doctorDescriptorTable = "system.descriptor"
+	doctorNamespaceTable  = "system.namespace"
+	doctorJobsTable       = "crdb_internal.jobs"
+)
+
+func runDebugDoctorCluster(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach debug doctor")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tables := make(map[string]doctorRows)
+	for _, table := range []string{doctorDescriptorTable, doctorNamespaceTable, doctorJobsTable} {
+		cols, rows, err := runQuery(conn, makeQuery(debugZipTableQuery(table)), true /* showMoreChars */)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", table)
+		}
+		tables[table] = doctorRows{cols: cols, rows: rows}
+	}
+	return runDoctor(tables)
+}
+
+func runDebugDoctorZip(cmd *cobra.Command, args []string) error {
+	r, err := zip.OpenReader(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	tables := make(map[string]doctorRows)
+	for _, f := range r.File {
+		for _, table := range []string{doctorDescriptorTable, doctorNamespaceTable, doctorJobsTable} {
+			if f.Name != "debug/"+table+".txt" {
+				continue
+			}
+			rows, err := readDoctorRowsFromZip(f)
+			if err != nil {
+				return errors.Wrapf(err, "reading %s", f.Name)
+			}
+			tables[table] = rows
+		}
+	}
+	for _, table := range []string{doctorDescriptorTable, doctorNamespaceTable, doctorJobsTable} {
+		if _, ok := tables[table]; !ok {
+			return errors.Errorf("%s not found in %s; was the zip produced by an older version?",
+				table, args[0])
+		}
+	}
+	return runDoctor(tables)
+}
+
+// doctorRows are the contents of a table, as formatted by the SQL shell.
+type doctorRows struct {
+	cols []string
+	rows [][]string
+}
+
+// colIdx returns the index of the given column.
+func (d doctorRows) colIdx(name string) (int, error) {
+	for i, c := range d.cols {
+		if c == name {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("column %s not found", name)
+}
+
+func readDoctorRowsFromZip(f *zip.File) (doctorRows, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return doctorRows{}, err
+	}
+	defer rc.Close()
+	reader := csv.NewReader(rc)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	var res doctorRows
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return doctorRows{}, err
+		}
+		if res.cols == nil {
+			res.cols = record
+			continue
+		}
+		if len(record) != len(res.cols) {
+			// Errors are appended to the files of the tables that could
+			// not be read entirely.
+			return doctorRows{}, errors.Errorf("unexpected line: %q", strings.Join(record, "\t"))
+		}
+		res.rows = append(res.rows, record)
+	}
+	return res, nil
+}
+
+func runDoctor(tables map[string]doctorRows) error {
+	descTable, err := parseDoctorDescriptors(tables[doctorDescriptorTable])
+	if err != nil {
+		return errors.Wrap(err, doctorDescriptorTable)
+	}
+	namespaceTable, err := parseDoctorNamespace(tables[doctorNamespaceTable])
+	if err != nil {
+		return errors.Wrap(err, doctorNamespaceTable)
+	}
+	jobsTable, err := parseDoctorJobs(tables[doctorJobsTable])
+	if err != nil {
+		return errors.Wrap(err, doctorJobsTable)
+	}
+
+	report := doctor.Examine(descTable, namespaceTable, jobsTable)
+	report.Print(os.Stdout, true /* verbose */)
+	if !report.OK() {
+		return errors.Errorf("found %d problems", len(report.Problems))
+	}
+	return nil
+}
+
+func parseDoctorDescriptors(t doctorRows) (doctor.DescriptorTable, error) {
+	idIdx, err := t.colIdx("id")
+	if err != nil {
+		return nil, err
+	}
+	descIdx, err := t.colIdx("descriptor")
+	if err != nil {
+		return nil, err
+	}
+	res := make(doctor.DescriptorTable, 0, len(t.rows))
+	for _, row := range t.rows {
+		id, err := strconv.ParseInt(row[idIdx], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		b, err := gohex.DecodeString(row[descIdx])
+		if err != nil {
+			return nil, errors.Wrapf(err, "descriptor %d", id)
+		}
+		res = append(res, doctor.DescriptorTableRow{ID: id, DescBytes: b})
+	}
+	return res, nil
+}
+
+func parseDoctorNamespace(t doctorRows) (doctor.NamespaceTable, error) {
+	parentIdx, err := t.colIdx("parentID")
+	if err != nil {
+		return nil, err
+	}
+	nameIdx, err := t.colIdx("name")
+	if err != nil {
+		return nil, err
+	}
+	idIdx, err := t.colIdx("id")
+	if err != nil {
+		return nil, err
+	}
+	res := make(doctor.NamespaceTable, 0, len(t.rows))
+	for _, row := range t.rows {
+		parentID, err := strconv.ParseInt(row[parentIdx], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.ParseInt(row[idIdx], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		name, err := unformatVal(row[nameIdx])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, doctor.NamespaceTableRow{ParentID: parentID, Name: name, ID: id})
+	}
+	return res, nil
+}
+
+func parseDoctorJobs(t doctorRows) (doctor.JobsTable, error) {
+	idIdx, err := t.colIdx("job_id")
+	if err != nil {
+		return nil, err
+	}
+	statusIdx, err := t.colIdx("status")
+	if err != nil {
+		return nil, err
+	}
+	descIDsIdx, err := t.colIdx("descriptor_ids")
+	if err != nil {
+		return nil, err
+	}
+	res := make(doctor.JobsTable, 0, len(t.rows))
+	for _, row := range t.rows {
+		id, err := strconv.ParseInt(row[idIdx], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		job := doctor.JobsTableRow{ID: id, Status: row[statusIdx]}
+		ids := strings.TrimSuffix(strings.TrimPrefix(row[descIDsIdx], "{"), "}")
+		if ids != "" && ids != "NULL" {
+			for _, s := range strings.Split(ids, ",") {
+				descID, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, errors.Wrapf(err, "job %d", id)
+				}
+				job.DescriptorIDs = append(job.DescriptorIDs, descID)
+			}
+		}
+		res = append(res, job)
+	}
+	return res, nil
+}
+
+// unformatVal reverses the escaping of the non-printable characters of a
+// string value by formatVal.
+func unformatVal(s string) (string, error) {
+	if !strings.ContainsRune(s, '\\') {
+		return s, nil
+	}
+	res, err := strconv.Unquote(`"` + s + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %q: %v", s, err)
+	}
+	return res, nil
+}
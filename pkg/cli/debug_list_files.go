@@ -0,0 +1,289 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugListFilesCmd = &cobra.Command{
+	Use:   "list-files",
+	Short: "list the log files, heap profiles and goroutine dumps of nodes",
+	Long: `
+List the log files, heap profiles and goroutine dumps available on the nodes
+of the cluster. The files are listed over the RPC interface of the node the
+command connects to, so no access to the machines running the nodes is
+needed.
+
+Use --nodes, --file-types and --files to restrict the files listed.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDebugListFiles),
+}
+
+var debugGetFilesCmd = &cobra.Command{
+	Use:   "get-files <directory>",
+	Short: "retrieve the log files, heap profiles and goroutine dumps of nodes",
+	Long: `
+Retrieve the log files, heap profiles and goroutine dumps of the nodes of the
+cluster into the given local directory, over the RPC interface of the node the
+command connects to. The files of each node are stored in
+<directory>/<node ID>/<file type>/. Existing files are not overwritten.
+
+Use --nodes, --file-types and --files to restrict the files retrieved.
+`,
+	Example: `  cockroach debug get-files ./diagnostics --nodes=1,3 --file-types=heap`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    MaybeDecorateGRPCError(runDebugGetFiles),
+}
+
+// Types of files that can be listed and retrieved by debug list-files and
+// debug get-files.
+const (
+	debugFileTypeLog        = "log"
+	debugFileTypeHeap       = "heap"
+	debugFileTypeGoroutines = "goroutines"
+)
+
+var debugFileTypes = []string{debugFileTypeLog, debugFileTypeHeap, debugFileTypeGoroutines}
+
+// debugFile describes a file of a node.
+type debugFile struct {
+	nodeID   string
+	fileType string
+	name     string
+	size     int64
+}
+
+func runDebugListFiles(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	status, finish, err := getStatusClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	files, err := listDebugFiles(ctx, status)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(files))
+	for i, f := range files {
+		rows[i] = []string{f.nodeID, f.fileType, f.name, humanizeutil.IBytes(f.size)}
+	}
+	return printQueryOutput(os.Stdout, []string{"node_id", "type", "file_name", "size"},
+		newRowSliceIter(rows, "rllr"))
+}
+
+func runDebugGetFiles(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	status, finish, err := getStatusClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	files, err := listDebugFiles(ctx, status)
+	if err != nil {
+		return err
+	}
+	dir := args[0]
+	for _, f := range files {
+		path := filepath.Join(dir, f.nodeID, f.fileType, f.name)
+		if err := getDebugFile(ctx, status, f, path); err != nil {
+			return errors.Wrapf(err, "retrieving %s of node %s", f.name, f.nodeID)
+		}
+		fmt.Printf("retrieved %s\n", path)
+	}
+	return nil
+}
+
+// getStatusClient returns a client for the status RPCs of the node the
+// command connects to.
+func getStatusClient(ctx context.Context) (serverpb.StatusClient, func(), error) {
+	conn, _, finish, err := getClientGRPCConn(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to connect to the node")
+	}
+	return serverpb.NewStatusClient(conn), finish, nil
+}
+
+// listDebugFiles returns the files selected by the flags of debug list-files
+// and debug get-files, ordered by node, type and name.
+func listDebugFiles(ctx context.Context, status serverpb.StatusClient) ([]debugFile, error) {
+	for _, t := range debugCtx.fileTypes {
+		if !isDebugFileType(t) {
+			return nil, errors.Errorf("unknown file type %q, expected one of: %s",
+				t, strings.Join(debugFileTypes, ", "))
+		}
+	}
+	if _, err := filepath.Match(debugCtx.filePattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %q", debugCtx.filePattern)
+	}
+
+	nodeIDs := debugCtx.nodes
+	if len(nodeIDs) == 0 {
+		nodes, err := status.Nodes(ctx, &serverpb.NodesRequest{})
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes.Nodes {
+			nodeIDs = append(nodeIDs, n.Desc.NodeID.String())
+		}
+	}
+
+	var files []debugFile
+	for _, nodeID := range nodeIDs {
+		for _, fileType := range debugFileTypes {
+			if !includesDebugFileType(fileType) {
+				continue
+			}
+			// The files of each node and type are ordered by name.
+			start := len(files)
+			if fileType == debugFileTypeLog {
+				logs, err := status.LogFilesList(ctx, &serverpb.LogFilesListRequest{NodeId: nodeID})
+				if err != nil {
+					return nil, errors.Wrapf(err, "listing log files of node %s", nodeID)
+				}
+				for _, f := range logs.Files {
+					if ok, _ := filepath.Match(debugCtx.filePattern, f.Name); ok {
+						files = append(files, debugFile{
+							nodeID: nodeID, fileType: fileType, name: f.Name, size: f.SizeBytes,
+						})
+					}
+				}
+				sortDebugFiles(files[start:])
+				continue
+			}
+			resp, err := status.GetFiles(ctx, &serverpb.GetFilesRequest{
+				NodeId:   nodeID,
+				ListOnly: true,
+				Type:     serverFileType(fileType),
+				Patterns: []string{debugCtx.filePattern},
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "listing %s files of node %s", fileType, nodeID)
+			}
+			for _, f := range resp.Files {
+				files = append(files, debugFile{
+					nodeID: nodeID, fileType: fileType, name: f.Name, size: f.FileSize,
+				})
+			}
+			sortDebugFiles(files[start:])
+		}
+	}
+	return files, nil
+}
+
+// getDebugFile retrieves the given file and writes it at path.
+func getDebugFile(
+	ctx context.Context, status serverpb.StatusClient, f debugFile, path string,
+) (retErr error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if f.fileType == debugFileTypeLog {
+		// Log files are served parsed into entries; they are formatted back
+		// into the log format.
+		entries, err := status.LogFile(ctx, &serverpb.LogFileRequest{NodeId: f.nodeID, File: f.name})
+		if err != nil {
+			return err
+		}
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := out.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+		w := bufio.NewWriter(out)
+		for _, e := range entries.Entries {
+			if err := e.Format(w); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+
+	resp, err := status.GetFiles(ctx, &serverpb.GetFilesRequest{
+		NodeId:   f.nodeID,
+		Type:     serverFileType(f.fileType),
+		Patterns: []string{f.name},
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Files) != 1 {
+		return errors.Errorf("file not found")
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(resp.Files[0].Contents); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func sortDebugFiles(files []debugFile) {
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+}
+
+// includesDebugFileType returns whether files of the given type are selected
+// by --file-types. All types are selected by default.
+func includesDebugFileType(t string) bool {
+	if len(debugCtx.fileTypes) == 0 {
+		return true
+	}
+	for _, ft := range debugCtx.fileTypes {
+		if t == ft {
+			return true
+		}
+	}
+	return false
+}
+
+func isDebugFileType(t string) bool {
+	for _, ft := range debugFileTypes {
+		if t == ft {
+			return true
+		}
+	}
+	return false
+}
+
+// serverFileType returns the serverpb.FileType of the given debug file type,
+// which must not be debugFileTypeLog.
+func serverFileType(t string) serverpb.FileType {
+	if t == debugFileTypeHeap {
+		return serverpb.FileType_HEAP
+	}
+	return serverpb.FileType_GOROUTINES
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDebugListFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{})
+	defer c.cleanup()
+
+	// The test server neither writes log files nor heap profiles, so only
+	// the listing itself is checked.
+	out, err := c.RunWithCapture("debug list-files --nodes=1 --file-types=heap,goroutines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "node_id\ttype\tfile_name\tsize\n") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	out, err = c.RunWithCapture("debug list-files --file-types=core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `unknown file type "core"`) {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	out, err = c.RunWithCapture("debug list-files --files=[")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `invalid pattern "["`) {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
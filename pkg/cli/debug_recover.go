@@ -0,0 +1,454 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugRecoverCmd = &cobra.Command{
+	Use:   "recover [command]",
+	Short: "commands to recover unavailable ranges after loss of quorum",
+	Long: `
+Commands to recover ranges which have lost a majority of their replicas.
+
+These commands are UNSAFE and should only be used with the supervision of
+a Cockroach Labs engineer. They are a last-resort option to recover data
+after multiple node failures. The recovered data is not guaranteed to be
+consistent.
+
+Recovery proceeds in three steps, all of which operate on stopped nodes:
+
+1. "collect-info" is run on every surviving node and reads information
+   about the replicas on its stores.
+2. "make-plan" reads the information collected from all surviving nodes
+   and decides, for each range which lost quorum, which surviving replica
+   should become the range's sole replica.
+3. "apply-plan" is run against every surviving store and rewrites the
+   range descriptors of the designated survivors.
+
+Once the plan has been applied, the nodes can be restarted and the
+recovered ranges will up-replicate from their survivors. The dead nodes
+must never rejoin the cluster; they should be decommissioned.
+`,
+	RunE: usageAndErr,
+}
+
+var debugRecoverCollectInfoCmd = &cobra.Command{
+	Use:   "collect-info [store directory...]",
+	Short: "collect information about the replicas on the given stores",
+	Long: `
+Reads the range descriptors and Raft state of all replicas on the given
+stores and writes them as JSON to stdout, or to the file given by --out.
+The node must not be running.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: MaybeDecorateGRPCError(runDebugRecoverCollectInfo),
+}
+
+var debugRecoverMakePlanCmd = &cobra.Command{
+	Use:   "make-plan [replica info file...]",
+	Short: "compute a recovery plan from the collected replica information",
+	Long: `
+Reads the replica information collected from all surviving nodes by
+collect-info and determines which ranges have lost quorum. For each such
+range, the surviving voter with the most recent range descriptor and,
+among those, the highest applied index is designated as the range's new
+sole replica. The plan is written as JSON to stdout, or to the file given
+by --out.
+
+Stores which are not present in any of the input files are considered
+dead. It is therefore crucial that information is collected from every
+surviving store.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: MaybeDecorateGRPCError(runDebugRecoverMakePlan),
+}
+
+var debugRecoverApplyPlanCmd = &cobra.Command{
+	Use:   "apply-plan --plan=<file> [store directory]",
+	Short: "apply a recovery plan to the given store",
+	Long: `
+Applies the recovery plan computed by make-plan to the given store,
+rewriting the range descriptors of all survivors designated by the plan
+that are located on the store. The node must not be running.
+
+This command will prompt for confirmation before committing its changes.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: MaybeDecorateGRPCError(runDebugRecoverApplyPlan),
+}
+
+var debugRecoverOpts struct {
+	outFile  string
+	planFile string
+}
+
+// recoveryReplicaInfo describes a replica found on a store by collect-info.
+type recoveryReplicaInfo struct {
+	NodeID             roachpb.NodeID          `json:"node_id"`
+	StoreID            roachpb.StoreID         `json:"store_id"`
+	Desc               roachpb.RangeDescriptor `json:"desc"`
+	RaftAppliedIndex   uint64                  `json:"raft_applied_index"`
+	RaftCommittedIndex uint64                  `json:"raft_committed_index"`
+}
+
+// recoveryReplicaInfos is the output of collect-info.
+type recoveryReplicaInfos struct {
+	Replicas []recoveryReplicaInfo `json:"replicas"`
+}
+
+// recoveryUpdate designates a surviving replica of a range which lost
+// quorum as the range's sole replica.
+type recoveryUpdate struct {
+	RangeID  roachpb.RangeID `json:"range_id"`
+	StartKey roachpb.RKey    `json:"start_key"`
+	NodeID   roachpb.NodeID  `json:"node_id"`
+	StoreID  roachpb.StoreID `json:"store_id"`
+	// OldReplicaID is the ID of the survivor before recovery.
+	OldReplicaID roachpb.ReplicaID `json:"old_replica_id"`
+	// NewReplicaID is the ID of the survivor after recovery. It is larger than
+	// any replica ID observed for the range, as an extra defense against one
+	// of the old replicas returning from the dead.
+	NewReplicaID roachpb.ReplicaID `json:"new_replica_id"`
+}
+
+// recoveryPlan is the output of make-plan.
+type recoveryPlan struct {
+	Updates []recoveryUpdate `json:"updates"`
+}
+
+func runDebugRecoverCollectInfo(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	var infos recoveryReplicaInfos
+	for _, dir := range args {
+		db, err := OpenExistingStore(dir, stopper, true /* readOnly */)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open store at %s", dir)
+		}
+		replicas, err := collectReplicaInfo(context.Background(), db)
+		if err != nil {
+			return errors.Wrapf(err, "failed to collect replica info from %s", dir)
+		}
+		infos.Replicas = append(infos.Replicas, replicas...)
+	}
+	return writeRecoveryJSON(cmd.OutOrStdout(), debugRecoverOpts.outFile, &infos)
+}
+
+// collectReplicaInfo returns information about all initialized replicas on
+// the given store.
+func collectReplicaInfo(ctx context.Context, db engine.Engine) ([]recoveryReplicaInfo, error) {
+	storeIdent, err := storage.ReadStoreIdent(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []recoveryReplicaInfo
+	err = storage.IterateRangeDescriptors(ctx, db, func(desc roachpb.RangeDescriptor) (bool, error) {
+		rsl := stateloader.Make(desc.RangeID)
+		appliedIndex, _, err := rsl.LoadAppliedIndex(ctx, db)
+		if err != nil {
+			return false, err
+		}
+		hs, err := rsl.LoadHardState(ctx, db)
+		if err != nil {
+			return false, err
+		}
+		replicas = append(replicas, recoveryReplicaInfo{
+			NodeID:             storeIdent.NodeID,
+			StoreID:            storeIdent.StoreID,
+			Desc:               desc,
+			RaftAppliedIndex:   appliedIndex,
+			RaftCommittedIndex: hs.Commit,
+		})
+		return false, nil
+	})
+	return replicas, err
+}
+
+func runDebugRecoverMakePlan(cmd *cobra.Command, args []string) error {
+	var replicas []recoveryReplicaInfo
+	for _, file := range args {
+		var infos recoveryReplicaInfos
+		if err := readRecoveryJSON(file, &infos); err != nil {
+			return err
+		}
+		replicas = append(replicas, infos.Replicas...)
+	}
+	plan, err := makeRecoveryPlan(replicas)
+	if err != nil {
+		return err
+	}
+	for _, u := range plan.Updates {
+		fmt.Fprintf(os.Stderr, "r%d: designating replica %d on s%d as survivor (new replica ID %d)\n",
+			u.RangeID, u.OldReplicaID, u.StoreID, u.NewReplicaID)
+	}
+	if len(plan.Updates) == 0 {
+		fmt.Fprintf(os.Stderr, "No ranges have lost quorum\n")
+	}
+	return writeRecoveryJSON(cmd.OutOrStdout(), debugRecoverOpts.outFile, &plan)
+}
+
+// makeRecoveryPlan determines the ranges which have lost quorum, given
+// information about the replicas on all surviving stores, and designates a
+// survivor for each of them. Stores which have no replicas in the input are
+// considered dead.
+func makeRecoveryPlan(replicas []recoveryReplicaInfo) (recoveryPlan, error) {
+	liveStores := map[roachpb.StoreID]struct{}{}
+	byRange := map[roachpb.RangeID][]recoveryReplicaInfo{}
+	for _, info := range replicas {
+		liveStores[info.StoreID] = struct{}{}
+		byRange[info.Desc.RangeID] = append(byRange[info.Desc.RangeID], info)
+	}
+
+	// Use the most recent descriptor of every range to determine the
+	// keyspace it covers and whether it has lost quorum.
+	latest := make([]roachpb.RangeDescriptor, 0, len(byRange))
+	for _, infos := range byRange {
+		desc := infos[0].Desc
+		for _, info := range infos[1:] {
+			if info.Desc.GetGeneration() > desc.GetGeneration() {
+				desc = info.Desc
+			}
+		}
+		latest = append(latest, desc)
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].StartKey.Less(latest[j].StartKey)
+	})
+	for i := 1; i < len(latest); i++ {
+		if latest[i].StartKey.Less(latest[i-1].EndKey) {
+			return recoveryPlan{}, errors.Errorf(
+				"range descriptors %s and %s overlap; the collected replica information is inconsistent",
+				&latest[i-1], &latest[i])
+		}
+	}
+
+	var plan recoveryPlan
+	for _, desc := range latest {
+		voters := desc.Replicas().Voters()
+		var live int
+		for _, rep := range voters {
+			if _, ok := liveStores[rep.StoreID]; ok {
+				live++
+			}
+		}
+		if live >= len(voters)/2+1 {
+			continue
+		}
+
+		// Pick the surviving voter with the most recent descriptor, so that
+		// the survivor doesn't resurrect a stale view of the range's bounds.
+		// Among those, prefer the highest applied index, breaking ties by
+		// store ID to make the plan deterministic.
+		var survivor *recoveryReplicaInfo
+		nextReplicaID := desc.NextReplicaID
+		for i := range byRange[desc.RangeID] {
+			info := &byRange[desc.RangeID][i]
+			if info.Desc.NextReplicaID > nextReplicaID {
+				nextReplicaID = info.Desc.NextReplicaID
+			}
+			rep, ok := info.Desc.GetReplicaDescriptor(info.StoreID)
+			if !ok || rep.GetType() != roachpb.ReplicaType_VOTER {
+				continue
+			}
+			if survivor == nil || betterRecoverySurvivor(info, survivor) {
+				survivor = info
+			}
+		}
+		if survivor == nil {
+			return recoveryPlan{}, errors.Errorf("range %s has no surviving voters and cannot be recovered", &desc)
+		}
+		rep, _ := survivor.Desc.GetReplicaDescriptor(survivor.StoreID)
+		plan.Updates = append(plan.Updates, recoveryUpdate{
+			RangeID:      desc.RangeID,
+			StartKey:     survivor.Desc.StartKey,
+			NodeID:       survivor.NodeID,
+			StoreID:      survivor.StoreID,
+			OldReplicaID: rep.ReplicaID,
+			NewReplicaID: nextReplicaID,
+		})
+	}
+	return plan, nil
+}
+
+// betterRecoverySurvivor returns whether a is a better choice than b as the
+// survivor of a range which lost quorum.
+func betterRecoverySurvivor(a, b *recoveryReplicaInfo) bool {
+	if genA, genB := a.Desc.GetGeneration(), b.Desc.GetGeneration(); genA != genB {
+		return genA > genB
+	}
+	if a.RaftAppliedIndex != b.RaftAppliedIndex {
+		return a.RaftAppliedIndex > b.RaftAppliedIndex
+	}
+	return a.StoreID < b.StoreID
+}
+
+func runDebugRecoverApplyPlan(cmd *cobra.Command, args []string) error {
+	if debugRecoverOpts.planFile == "" {
+		return errors.New("--plan must be specified")
+	}
+	var plan recoveryPlan
+	if err := readRecoveryJSON(debugRecoverOpts.planFile, &plan); err != nil {
+		return err
+	}
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := OpenExistingStore(args[0], stopper, false /* readOnly */)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	batch, err := applyRecoveryPlan(context.Background(), db, plan)
+	if err != nil {
+		return err
+	} else if batch == nil {
+		fmt.Printf("Nothing to do\n")
+		return nil
+	}
+	defer batch.Close()
+	return confirmAndCommitBatch(batch)
+}
+
+// applyRecoveryPlan returns a batch which rewrites the descriptors of all
+// survivors in the plan that are located on the given store, or nil if the
+// plan does not apply to the store. Applying a plan is idempotent.
+func applyRecoveryPlan(
+	ctx context.Context, db engine.Engine, plan recoveryPlan,
+) (engine.Batch, error) {
+	storeIdent, err := storage.ReadStoreIdent(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[roachpb.RangeID]recoveryUpdate{}
+	for _, u := range plan.Updates {
+		if u.StoreID == storeIdent.StoreID {
+			updates[u.RangeID] = u
+		}
+	}
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	var newDescs []roachpb.RangeDescriptor
+	err = storage.IterateRangeDescriptors(ctx, db, func(desc roachpb.RangeDescriptor) (bool, error) {
+		u, ok := updates[desc.RangeID]
+		if !ok {
+			return false, nil
+		}
+		delete(updates, desc.RangeID)
+		rep, ok := desc.GetReplicaDescriptor(storeIdent.StoreID)
+		if !ok {
+			return false, errors.Errorf("store %s is not a member of %s", storeIdent, &desc)
+		}
+		if rep.ReplicaID == u.NewReplicaID {
+			fmt.Printf("Replica %s already recovered\n", &desc)
+			return false, nil
+		}
+		if rep.ReplicaID != u.OldReplicaID {
+			return false, errors.Errorf("expected replica ID %d in %s, found %d; the plan is stale",
+				u.OldReplicaID, &desc, rep.ReplicaID)
+		}
+		newDesc := desc
+		replicas := []roachpb.ReplicaDescriptor{{
+			NodeID:    storeIdent.NodeID,
+			StoreID:   storeIdent.StoreID,
+			ReplicaID: u.NewReplicaID,
+		}}
+		newDesc.SetReplicas(roachpb.MakeReplicaDescriptors(&replicas))
+		newDesc.NextReplicaID = u.NewReplicaID + 1
+		fmt.Printf("Replica %s -> %s\n", &desc, &newDesc)
+		newDescs = append(newDescs, newDesc)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) > 0 {
+		var missing []roachpb.RangeID
+		for rangeID := range updates {
+			missing = append(missing, rangeID)
+		}
+		return nil, errors.Errorf("ranges %v designated as survivors on %s but not found on store",
+			missing, storeIdent)
+	}
+	if len(newDescs) == 0 {
+		return nil, nil
+	}
+	return writeRangeDescriptors(ctx, db, newDescs)
+}
+
+func readRecoveryJSON(file string, v interface{}) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(data, v), "failed to parse %s", file)
+}
+
+func writeRecoveryJSON(w io.Writer, file string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if file != "" {
+		return ioutil.WriteFile(file, data, 0600)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DebugRecoverCmdsForRocksDB lists the subcommands of debug recover that
+// access rocksdb through the engine and need encryption flags (injected by
+// CCL code).
+var DebugRecoverCmdsForRocksDB = []*cobra.Command{
+	debugRecoverCollectInfoCmd,
+	debugRecoverApplyPlanCmd,
+}
+
+func init() {
+	debugRecoverCmd.AddCommand(
+		debugRecoverCollectInfoCmd,
+		debugRecoverMakePlanCmd,
+		debugRecoverApplyPlanCmd,
+	)
+
+	f := debugRecoverCollectInfoCmd.Flags()
+	f.StringVar(&debugRecoverOpts.outFile, "out", "",
+		"file to write the replica information to (defaults to stdout)")
+
+	f = debugRecoverMakePlanCmd.Flags()
+	f.StringVar(&debugRecoverOpts.outFile, "out", "",
+		"file to write the recovery plan to (defaults to stdout)")
+
+	f = debugRecoverApplyPlanCmd.Flags()
+	f.StringVar(&debugRecoverOpts.planFile, "plan", "",
+		"file containing the recovery plan computed by make-plan")
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/gogo/protobuf/proto"
+)
+
+func makeRecoveryTestDesc(
+	rangeID roachpb.RangeID, start, end string, storeIDs ...roachpb.StoreID,
+) roachpb.RangeDescriptor {
+	var replicas []roachpb.ReplicaDescriptor
+	for i, storeID := range storeIDs {
+		replicas = append(replicas, roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(storeID),
+			StoreID:   storeID,
+			ReplicaID: roachpb.ReplicaID(i + 1),
+		})
+	}
+	desc := roachpb.RangeDescriptor{
+		RangeID:       rangeID,
+		StartKey:      roachpb.RKey(start),
+		EndKey:        roachpb.RKey(end),
+		NextReplicaID: roachpb.ReplicaID(len(storeIDs) + 1),
+	}
+	desc.SetReplicas(roachpb.MakeReplicaDescriptors(&replicas))
+	return desc
+}
+
+func makeRecoveryTestInfo(
+	desc roachpb.RangeDescriptor, storeID roachpb.StoreID, appliedIndex uint64,
+) recoveryReplicaInfo {
+	return recoveryReplicaInfo{
+		NodeID:           roachpb.NodeID(storeID),
+		StoreID:          storeID,
+		Desc:             desc,
+		RaftAppliedIndex: appliedIndex,
+	}
+}
+
+func TestMakeRecoveryPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// r1 retains a quorum on s1 and s2. r2 and r3 have lost quorum; r2's
+	// replica on s2 is the furthest ahead.
+	r1 := makeRecoveryTestDesc(1, "a", "b", 1, 2, 3)
+	r2 := makeRecoveryTestDesc(2, "b", "c", 1, 2, 3, 4, 5)
+	r3 := makeRecoveryTestDesc(3, "c", "d", 1, 3, 4)
+	plan, err := makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryTestInfo(r1, 1, 10),
+		makeRecoveryTestInfo(r1, 2, 10),
+		makeRecoveryTestInfo(r2, 1, 10),
+		makeRecoveryTestInfo(r2, 2, 12),
+		makeRecoveryTestInfo(r3, 1, 10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := recoveryPlan{Updates: []recoveryUpdate{
+		{
+			RangeID: 2, StartKey: roachpb.RKey("b"), NodeID: 2, StoreID: 2,
+			OldReplicaID: 2, NewReplicaID: 6,
+		},
+		{
+			RangeID: 3, StartKey: roachpb.RKey("c"), NodeID: 1, StoreID: 1,
+			OldReplicaID: 1, NewReplicaID: 4,
+		},
+	}}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Fatalf("expected plan\n%+v\nfound\n%+v", expected, plan)
+	}
+
+	// A replica with a stale descriptor is never chosen as the survivor, even
+	// if it is further ahead: r3's replica on s3 hasn't applied the split
+	// which shrank the range to [c, d), and would otherwise resurrect r3's old
+	// bounds.
+	r3Stale := makeRecoveryTestDesc(3, "c", "e", 1, 3, 4)
+	r3.Generation = proto.Int64(1)
+	plan, err = makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryTestInfo(r3, 1, 10),
+		makeRecoveryTestInfo(r3Stale, 3, 12),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = recoveryPlan{Updates: []recoveryUpdate{
+		{
+			RangeID: 3, StartKey: roachpb.RKey("c"), NodeID: 1, StoreID: 1,
+			OldReplicaID: 1, NewReplicaID: 4,
+		},
+	}}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Fatalf("expected plan\n%+v\nfound\n%+v", expected, plan)
+	}
+
+	// Overlapping descriptors indicate that information is missing or stale.
+	r4 := makeRecoveryTestDesc(4, "a", "c", 1, 2, 3)
+	if _, err := makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryTestInfo(r1, 1, 10),
+		makeRecoveryTestInfo(r4, 1, 10),
+	}); !testutils.IsError(err, "overlap") {
+		t.Fatalf("expected overlap error, found %v", err)
+	}
+}
@@ -126,6 +126,45 @@ func TestOpenReadOnlyStore(t *testing.T) {
 	}
 }
 
+func TestDebugCompactRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer initCLIDefaults()
+
+	baseDir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	storePath := filepath.Join(baseDir, "store")
+	createStore(t, storePath)
+
+	for _, test := range []struct {
+		from, to string
+		expErr   string
+	}{
+		{"", "", ""},
+		{"raw:a", "raw:c", ""},
+		{"raw:c", "", ""},
+		{"raw:c", "raw:a", "invalid key range"},
+	} {
+		t.Run(fmt.Sprintf("from=%s,to=%s", test.from, test.to), func(t *testing.T) {
+			initCLIDefaults()
+			if test.from != "" {
+				if err := (*mvccKey)(&debugCtx.startKey).Set(test.from); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if test.to != "" {
+				if err := (*mvccKey)(&debugCtx.endKey).Set(test.to); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := runDebugCompact(debugCompactCmd, []string{storePath})
+			if !testutils.IsError(err, test.expErr) {
+				t.Fatalf("wanted %s but got %v", test.expErr, err)
+			}
+		})
+	}
+}
+
 func TestRemoveDeadReplicas(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	ctx := context.Background()
@@ -257,7 +296,7 @@ func TestRemoveDeadReplicas(t *testing.T) {
 	adminClient := serverpb.NewAdminClient(grpcConn)
 
 	if err := runDecommissionNodeImpl(
-		ctx, adminClient, nodeDecommissionWaitNone, []string{"2", "3"},
+		ctx, adminClient, nodeDecommissionWaitNone, 0 /* stallTimeout */, []string{"2", "3"},
 	); err != nil {
 		t.Fatal(err)
 	}
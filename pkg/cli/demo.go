@@ -15,21 +15,27 @@ import (
 	gosql "database/sql"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/workload"
 	"github.com/cockroachdb/cockroach/pkg/workload/workloadsql"
 	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
 )
 
 var demoCmd = &cobra.Command{
@@ -41,7 +47,12 @@ interactive SQL prompt to it. Various datasets are available to be preloaded as
 subcommands: e.g. "cockroach demo startrek". See --help for a full list.
 
 By default, the 'movr' dataset is pre-loaded. You can also use --empty
-to avoid pre-loading a dataset.`,
+to avoid pre-loading a dataset.
+
+With --nodes, the demo cluster has multiple nodes. Their localities can be
+set with --demo-locality, and default to being spread across three regions.
+With --global, artificial latencies are added between the nodes in
+different regions, to simulate a geographically distributed cluster.`,
 	Example: `  cockroach demo`,
 	Args:    cobra.NoArgs,
 	RunE: MaybeDecorateGRPCError(func(cmd *cobra.Command, _ []string) error {
@@ -81,6 +92,106 @@ func init() {
 	}
 }
 
+// demoRegions are the regions of the default localities of the nodes of a
+// multi-node demo cluster.
+var demoRegions = []string{"us-east1", "us-west1", "europe-west1"}
+
+// demoRegionLatencies are the simulated one-way latencies between the pairs
+// of regions used with --global. They are half of the round-trip times
+// observed between the corresponding regions of a public cloud.
+var demoRegionLatencies = map[[2]string]time.Duration{
+	{"us-east1", "us-west1"}:     33 * time.Millisecond,
+	{"us-east1", "europe-west1"}: 32 * time.Millisecond,
+	{"us-west1", "europe-west1"}: 73 * time.Millisecond,
+}
+
+// defaultDemoLocalities returns the localities of the nodes of a demo cluster
+// of the given size when --demo-locality is not specified. The nodes are
+// spread across the regions in demoRegions, and across availability zones
+// within each region.
+func defaultDemoLocalities(nodes int) demoLocalityList {
+	localities := make(demoLocalityList, nodes)
+	for i := range localities {
+		region := demoRegions[i%len(demoRegions)]
+		localities[i] = roachpb.Locality{Tiers: []roachpb.Tier{
+			{Key: "region", Value: region},
+			{Key: "az", Value: fmt.Sprintf("%s-%c", region, 'b'+rune(i/len(demoRegions)))},
+		}}
+	}
+	return localities
+}
+
+// localityRegion returns the value of the region tier of a locality, or the
+// empty string if there is none.
+func localityRegion(loc roachpb.Locality) string {
+	for _, tier := range loc.Tiers {
+		if tier.Key == "region" {
+			return tier.Value
+		}
+	}
+	return ""
+}
+
+// demoLatency returns the simulated latency between two regions.
+func demoLatency(a, b string) time.Duration {
+	if l, ok := demoRegionLatencies[[2]string{a, b}]; ok {
+		return l
+	}
+	return demoRegionLatencies[[2]string{b, a}]
+}
+
+// demoLatencySimulator delays the RPCs between the nodes of a demo cluster
+// according to their regions.
+type demoLatencySimulator struct {
+	mu struct {
+		syncutil.Mutex
+		// regions maps the RPC address of each node to its region.
+		regions map[string]string
+	}
+}
+
+func (d *demoLatencySimulator) addNode(addr string, region string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.regions == nil {
+		d.mu.regions = make(map[string]string)
+	}
+	d.mu.regions[addr] = region
+}
+
+func (d *demoLatencySimulator) regionOf(addr string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mu.regions[addr]
+}
+
+// interceptor returns a function suitable for use as the
+// UnaryClientInterceptor testing knob of the RPC context of a node in the
+// given region. Only unary RPCs are delayed.
+func (d *demoLatencySimulator) interceptor(
+	region string,
+) func(target string, class rpc.ConnectionClass) grpc.UnaryClientInterceptor {
+	return func(target string, _ rpc.ConnectionClass) grpc.UnaryClientInterceptor {
+		return func(
+			ctx context.Context,
+			method string,
+			req, reply interface{},
+			cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			if latency := demoLatency(region, d.regionOf(target)); latency > 0 {
+				select {
+				case <-time.After(latency):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+}
+
 func setupTransientServers(
 	cmd *cobra.Command, gen workload.Generator,
 ) (connURL string, adminURL string, cleanup func(), err error) {
@@ -117,29 +228,44 @@ func setupTransientServers(
 		sysCfg.NumReplicas = proto.Int32(1)
 	}
 
-	// Create the first transient server. The others will join this one.
-	args := base.TestServerArgs{
-		PartOfCluster: true,
-		Insecure:      true,
-		Knobs: base.TestingKnobs{
-			Server: &server.TestingKnobs{
-				DefaultZoneConfigOverride:       &cfg,
-				DefaultSystemZoneConfigOverride: &sysCfg,
-			},
-		},
-		Stopper: stopper,
+	localities := demoCtx.localities
+	if localities == nil && demoCtx.nodes > 1 {
+		localities = defaultDemoLocalities(demoCtx.nodes)
 	}
+	var latencies demoLatencySimulator
+
+	// Create the first transient server. The others will join this one.
+	var s *server.TestServer
 	serverFactory := server.TestServerFactory
-	s := serverFactory.New(args).(*server.TestServer)
-	if err := s.Start(args); err != nil {
-		return connURL, adminURL, cleanup, err
-	}
-	args.JoinAddr = s.ServingRPCAddr()
-	for i := 0; i < demoCtx.nodes-1; i++ {
-		s := serverFactory.New(args).(*server.TestServer)
-		if err := s.Start(args); err != nil {
+	for i := 0; i < demoCtx.nodes; i++ {
+		knobs := &server.TestingKnobs{
+			DefaultZoneConfigOverride:       &cfg,
+			DefaultSystemZoneConfigOverride: &sysCfg,
+		}
+		args := base.TestServerArgs{
+			PartOfCluster: true,
+			Insecure:      true,
+			Knobs:         base.TestingKnobs{Server: knobs},
+			Stopper:       stopper,
+		}
+		if localities != nil {
+			args.Locality = localities[i]
+		}
+		if demoCtx.simulateLatency {
+			knobs.ContextTestingKnobs.UnaryClientInterceptor =
+				latencies.interceptor(localityRegion(args.Locality))
+		}
+		if s != nil {
+			args.JoinAddr = s.ServingRPCAddr()
+		}
+		serv := serverFactory.New(args).(*server.TestServer)
+		if err := serv.Start(args); err != nil {
 			return connURL, adminURL, cleanup, err
 		}
+		latencies.addNode(serv.ServingRPCAddr(), localityRegion(args.Locality))
+		if s == nil {
+			s = serv
+		}
 	}
 
 	// Prepare the URL for use by the SQL shell.
@@ -181,6 +307,14 @@ func setupTransientServers(
 }
 
 func runDemo(cmd *cobra.Command, gen workload.Generator) error {
+	if demoCtx.localities != nil && len(demoCtx.localities) != demoCtx.nodes {
+		return errors.Errorf("number of localities specified (%d) must equal number of nodes (%d)",
+			len(demoCtx.localities), demoCtx.nodes)
+	}
+	if demoCtx.simulateLatency && demoCtx.nodes < 3 {
+		return errors.New("--global requires at least 3 nodes")
+	}
+
 	if gen == nil && !demoCtx.useEmptyDatabase {
 		// Use a default dataset unless prevented by --empty.
 		gen = defaultGenerator
@@ -201,6 +335,10 @@ func runDemo(cmd *cobra.Command, gen workload.Generator) error {
 # You are connected to a temporary, in-memory CockroachDB cluster of %d node%s.
 `, demoCtx.nodes, util.Pluralize(int64(demoCtx.nodes)))
 
+		if demoCtx.simulateLatency {
+			fmt.Printf("# Latencies between the regions of the nodes are being simulated.\n")
+		}
+
 		if gen != nil {
 			fmt.Printf("# The cluster has been preloaded with the %q dataset\n# (%s).\n",
 				gen.Meta().Name, gen.Meta().Description)
@@ -0,0 +1,77 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDemoLocalityList(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var l demoLocalityList
+	if err := l.Set("region=us-east1,az=1:region=us-west1,az=2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 2 {
+		t.Fatalf("expected 2 localities, got %d", len(l))
+	}
+	if r := localityRegion(l[1]); r != "us-west1" {
+		t.Errorf("expected region us-west1, got %s", r)
+	}
+	if s := l.String(); s != "region=us-east1,az=1:region=us-west1,az=2" {
+		t.Errorf("unexpected string %s", s)
+	}
+	if err := l.Set("region"); err == nil {
+		t.Error("expected error for invalid locality")
+	}
+}
+
+func TestDefaultDemoLocalities(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l := defaultDemoLocalities(5)
+	expected := []string{
+		"region=us-east1,az=us-east1-b",
+		"region=us-west1,az=us-west1-b",
+		"region=europe-west1,az=europe-west1-b",
+		"region=us-east1,az=us-east1-c",
+		"region=us-west1,az=us-west1-c",
+	}
+	for i, loc := range l {
+		if s := loc.String(); s != expected[i] {
+			t.Errorf("%d: expected %s, got %s", i, expected[i], s)
+		}
+	}
+}
+
+func TestDemoLatency(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		a, b     string
+		expected time.Duration
+	}{
+		{"us-east1", "us-west1", 33 * time.Millisecond},
+		{"us-west1", "us-east1", 33 * time.Millisecond},
+		{"europe-west1", "us-west1", 73 * time.Millisecond},
+		{"us-east1", "us-east1", 0},
+		{"us-east1", "", 0},
+	}
+	for _, tc := range testCases {
+		if l := demoLatency(tc.a, tc.b); l != tc.expected {
+			t.Errorf("%s-%s: expected %s, got %s", tc.a, tc.b, tc.expected, l)
+		}
+	}
+}
@@ -81,23 +81,25 @@ func MaybeDecorateGRPCError(
 			const format = "cannot dial server.\n" +
 				"Is the server running?\n" +
 				"If the server is running, check --host client-side and --advertise server-side.\n\n%v"
-			return errors.Errorf(format, err)
+			return withExitCode(exitConnectionFailure, errors.Errorf(format, err))
 		}
 
 		connSecurityHint := func() error {
 			const format = "SSL authentication error while connecting.\n%s\n%v"
-			return errors.Errorf(format, extraInsecureHint(), err)
+			return withExitCode(exitAuthFailure, errors.Errorf(format, extraInsecureHint(), err))
 		}
 
 		connInsecureHint := func() error {
-			return errors.Errorf("cannot establish secure connection to insecure server.\n"+
-				"Maybe use --insecure?\n\n%v", err)
+			return withExitCode(exitConnectionFailure, errors.Errorf(
+				"cannot establish secure connection to insecure server.\n"+
+					"Maybe use --insecure?\n\n%v", err))
 		}
 
 		connRefused := func() error {
 			extra := extraInsecureHint()
-			return errors.Errorf("server closed the connection.\n"+
-				"Is this a CockroachDB node?\n"+extra+"\n%v", err)
+			return withExitCode(exitConnectionFailure, errors.Errorf(
+				"server closed the connection.\n"+
+					"Is this a CockroachDB node?\n"+extra+"\n%v", err))
 		}
 
 		// Is this an "unable to connect" type of error?
@@ -109,9 +111,9 @@ func MaybeDecorateGRPCError(
 		}
 
 		if wErr := (*security.Error)(nil); errors.As(err, &wErr) {
-			return errors.Errorf("cannot load certificates.\n"+
+			return withExitCode(exitAuthFailure, errors.Errorf("cannot load certificates.\n"+
 				"Check your certificate settings, set --certs-dir, or use --insecure for insecure clusters.\n\n%v",
-				err)
+				err))
 		}
 
 		if wErr := (*x509.UnknownAuthorityError)(nil); errors.As(err, &wErr) {
@@ -136,7 +138,7 @@ func MaybeDecorateGRPCError(
 				return connSecurityHint()
 			}
 			// Otherwise, there was a regular SQL error. Just report that.
-			return wErr
+			return withExitCode(sqlErrorExitCode(wErr), wErr)
 		}
 
 		if wErr := (*net.OpError)(nil); errors.As(err, &wErr) {
@@ -175,7 +177,7 @@ func MaybeDecorateGRPCError(
 		}
 
 		opTimeout := func() error {
-			return errors.Errorf("operation timed out.\n\n%v", err)
+			return withExitCode(exitTimeout, errors.Errorf("operation timed out.\n\n%v", err))
 		}
 
 		// Is it a plain context cancellation (i.e. timeout)?
@@ -195,14 +197,14 @@ func MaybeDecorateGRPCError(
 			return fmt.Errorf(
 				"incompatible client and server versions (likely server version: v1.0, required: >=v1.1)")
 		} else if grpcutil.IsClosedConnection(err) {
-			return errors.Errorf("connection lost.\n\n%v", err)
+			return withExitCode(exitConnectionFailure, errors.Errorf("connection lost.\n\n%v", err))
 		}
 
 		// Does the server require GSSAPI authentication?
 		if strings.Contains(err.Error(), "pq: unknown authentication response: 7") {
-			return fmt.Errorf(
-				"server requires GSSAPI authentication for this user.\n" +
-					"The CockroachDB CLI does not support GSSAPI authentication; use 'psql' instead")
+			return withExitCode(exitAuthFailure, fmt.Errorf(
+				"server requires GSSAPI authentication for this user.\n"+
+					"The CockroachDB CLI does not support GSSAPI authentication; use 'psql' instead"))
 		}
 
 		// Nothing we can special case, just return what we have.
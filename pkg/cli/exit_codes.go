@@ -0,0 +1,123 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq"
+)
+
+// Exit codes of the cockroach command. Scripts branch on them to handle the
+// different kinds of failures, so they must not be changed or reused.
+const (
+	exitSuccess = 0
+	// exitUnspecified is used for the errors that don't fall in any of the
+	// categories below.
+	exitUnspecified = 1
+	// Exit code 2 is used by the Go runtime when the process panics.
+
+	// exitConnectionFailure indicates that the server could not be reached,
+	// or closed the connection.
+	exitConnectionFailure = 3
+	// exitAuthFailure indicates that the client could not authenticate, or
+	// that the TLS configuration of the client and server don't match.
+	exitAuthFailure = 4
+	// exitSQLError indicates that the server returned a SQL error.
+	exitSQLError = 5
+	// exitTimeout indicates that an operation timed out.
+	exitTimeout = 6
+	// exitDiskFull indicates that a node could not start because the disk
+	// of one of its stores is full.
+	exitDiskFull = 7
+)
+
+// exitCodesHelp documents the exit codes in the help of the cockroach command.
+const exitCodesHelp = `Exit codes:
+  0  success
+  1  unspecified error
+  3  connection failure
+  4  authentication failure
+  5  SQL error
+  6  timeout
+  7  disk full`
+
+// withExitCode annotates an error with the exit code of the process.
+func withExitCode(exitCode int, err error) error {
+	return &cliError{exitCode: exitCode, severity: log.Severity_ERROR, cause: err}
+}
+
+// sqlErrorExitCode returns the exit code for an error returned by the server.
+func sqlErrorExitCode(err *pq.Error) int {
+	switch {
+	case strings.HasPrefix(string(err.Code), "28"):
+		// Class 28 - Invalid Authorization Specification.
+		return exitAuthFailure
+	case err.Code == pgcode.QueryCanceled && strings.Contains(err.Message, "timeout"):
+		return exitTimeout
+	default:
+		return exitSQLError
+	}
+}
+
+// errorExitCode returns the exit code for an error returned by a command.
+func errorExitCode(err error) int {
+	var cliErr *cliError
+	if errors.As(err, &cliErr) {
+		return cliErr.exitCode
+	}
+	if pqErr := (*pq.Error)(nil); errors.As(err, &pqErr) {
+		return sqlErrorExitCode(pqErr)
+	}
+	return exitUnspecified
+}
+
+// errorJSON is the envelope in which errors are reported when the output
+// format of the command is JSON.
+type errorJSON struct {
+	Error struct {
+		Message  string `json:"message"`
+		Code     string `json:"code,omitempty"`
+		Detail   string `json:"detail,omitempty"`
+		Hint     string `json:"hint,omitempty"`
+		Command  string `json:"command"`
+		ExitCode int    `json:"exit_code"`
+	} `json:"error"`
+}
+
+// reportErrorJSON writes the error envelope of a failed command on a single
+// line. The code is the SQL error code, if the error was returned by the
+// server.
+func reportErrorJSON(w io.Writer, err error, cmdName string, exitCode int) {
+	var e errorJSON
+	e.Error.Message = err.Error()
+	if pqErr := (*pq.Error)(nil); errors.As(err, &pqErr) {
+		e.Error.Code = string(pqErr.Code)
+		e.Error.Detail, e.Error.Hint = pqErr.Detail, pqErr.Hint
+	} else {
+		e.Error.Detail, e.Error.Hint = errors.FlattenDetails(err), errors.FlattenHints(err)
+	}
+	e.Error.Command = cmdName
+	e.Error.ExitCode = exitCode
+	out, jErr := json.Marshal(&e)
+	if jErr != nil {
+		// This can't happen: the envelope only contains strings and ints.
+		fmt.Fprintln(w, "Error:", err.Error())
+		return
+	}
+	fmt.Fprintln(w, string(out))
+}
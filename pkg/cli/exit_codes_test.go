@@ -0,0 +1,83 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq"
+	"github.com/spf13/cobra"
+)
+
+func TestErrorExitCode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{errors.New("boom"), exitUnspecified},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, exitConnectionFailure},
+		{&initialSQLConnectionError{errors.New("EOF")}, exitConnectionFailure},
+		{&pq.Error{Code: pgcode.ProtocolViolation}, exitAuthFailure},
+		{&pq.Error{Code: pgcode.InvalidPassword}, exitAuthFailure},
+		{errors.Wrap(&pq.Error{Code: pgcode.UndefinedTable}, "listing"), exitSQLError},
+		{&pq.Error{Code: pgcode.QueryCanceled, Message: "query execution canceled"}, exitSQLError},
+		{
+			&pq.Error{Code: pgcode.QueryCanceled, Message: "query execution canceled due to statement timeout"},
+			exitTimeout,
+		},
+		{errors.Wrap(context.DeadlineExceeded, "waiting"), exitTimeout},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			decorated := MaybeDecorateGRPCError(func(*cobra.Command, []string) error {
+				return tc.err
+			})(nil, nil)
+			if a := errorExitCode(decorated); a != tc.expected {
+				t.Fatalf("expected exit code %d, got %d (%v)", tc.expected, a, decorated)
+			}
+		})
+	}
+
+	// Errors that are not decorated are still classified.
+	if a := errorExitCode(&pq.Error{Code: pgcode.UndefinedTable}); a != exitSQLError {
+		t.Fatalf("expected exit code %d, got %d", exitSQLError, a)
+	}
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var buf bytes.Buffer
+	err := withExitCode(exitSQLError, &pq.Error{
+		Code:    pgcode.UndefinedTable,
+		Message: `relation "t" does not exist`,
+		Hint:    "check the name",
+	})
+	reportErrorJSON(&buf, err, "sql", errorExitCode(err))
+
+	var e errorJSON
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	if e.Error.Code != pgcode.UndefinedTable || e.Error.Hint != "check the name" ||
+		e.Error.Command != "sql" || e.Error.ExitCode != exitSQLError {
+		t.Fatalf("unexpected error envelope: %s", buf.String())
+	}
+}
@@ -131,6 +131,15 @@ func DurationFlag(
 	setFlagFromEnv(f, flagInfo)
 }
 
+// Float64Flag creates a float64 flag and registers it with the FlagSet.
+func Float64Flag(
+	f *pflag.FlagSet, valPtr *float64, flagInfo cliflags.FlagInfo, defaultVal float64,
+) {
+	f.Float64VarP(valPtr, flagInfo.Name, flagInfo.Shorthand, defaultVal, flagInfo.Usage())
+
+	setFlagFromEnv(f, flagInfo)
+}
+
 // VarFlag creates a custom-variable flag and registers it with the FlagSet.
 func VarFlag(f *pflag.FlagSet, value pflag.Value, flagInfo cliflags.FlagInfo) {
 	f.VarP(value, flagInfo.Name, flagInfo.Shorthand, flagInfo.Usage())
@@ -287,9 +296,13 @@ func init() {
 		case logflags.LogDirName,
 			logflags.LogFileMaxSizeName,
 			logflags.LogFilesCombinedMaxSizeName,
-			logflags.LogFileVerbosityThresholdName:
-			// The --log-dir* and --log-file* flags are specified only for the
-			// `start` and `demo` commands.
+			logflags.LogFileVerbosityThresholdName,
+			logflags.LogSinkName,
+			logflags.LogChannelName,
+			logflags.RedactableLogsName:
+			// The --log-dir*, --log-file*, --log-sink, --log-channel and
+			// --redactable-logs flags are specified only for the `start` and
+			// `demo` commands.
 			return
 		}
 		pf.AddFlag(flag)
@@ -389,6 +402,7 @@ func init() {
 
 		// Engine flags.
 		VarFlag(f, cacheSizeValue, cliflags.Cache)
+		VarFlag(f, &serverCfg.StorageEngine, cliflags.StorageEngine)
 		VarFlag(f, sqlSizeValue, cliflags.SQLMem)
 		// N.B. diskTempStorageSizeValue.ResolvePercentage() will be called after
 		// the stores flag has been parsed and the storage device that a percentage
@@ -398,6 +412,10 @@ func init() {
 		StringFlag(f, &startCtx.externalIODir, cliflags.ExternalIODir, startCtx.externalIODir)
 
 		VarFlag(f, serverCfg.SQLAuditLogDirName, cliflags.SQLAuditLogDirName)
+
+		// Drain flags.
+		DurationFlag(f, &serverCfg.DrainWait, cliflags.DrainWait, serverCfg.DrainWait)
+		DurationFlag(f, &serverCfg.QueryWait, cliflags.QueryWait, serverCfg.QueryWait)
 	}
 
 	// Log flags.
@@ -415,6 +433,16 @@ func init() {
 		VarFlag(f,
 			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogFileVerbosityThresholdName)).Value,
 			cliflags.LogFileVerbosity)
+		VarFlag(f,
+			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogSinkName)).Value,
+			cliflags.LogSink)
+		VarFlag(f,
+			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogChannelName)).Value,
+			cliflags.LogChannel)
+		VarFlag(f,
+			pflag.PFlagFromGoFlag(flag.Lookup(logflags.RedactableLogsName)).Value,
+			cliflags.RedactableLogs)
+		f.Lookup(cliflags.RedactableLogs.Name).NoOptDefVal = "true"
 	}
 
 	for _, cmd := range certCmds {
@@ -431,7 +459,7 @@ func init() {
 		BoolFlag(f, &allowCAKeyReuse, cliflags.AllowCAKeyReuse, false)
 	}
 
-	for _, cmd := range []*cobra.Command{createNodeCertCmd, createClientCertCmd} {
+	for _, cmd := range []*cobra.Command{createNodeCertCmd, createClientCertCmd, rotateCertsCmd} {
 		f := cmd.Flags()
 		DurationFlag(f, &certificateLifetime, cliflags.CertificateLifetime, defaultCertLifetime)
 	}
@@ -443,6 +471,9 @@ func init() {
 		IntFlag(f, &keySize, cliflags.KeySize, defaultKeySize)
 		BoolFlag(f, &overwriteFiles, cliflags.OverwriteFiles, false)
 	}
+	// Rotation always replaces the existing files.
+	StringFlag(rotateCertsCmd.Flags(), &baseCfg.SSLCAKey, cliflags.CAKey, baseCfg.SSLCAKey)
+	IntFlag(rotateCertsCmd.Flags(), &keySize, cliflags.KeySize, defaultKeySize)
 	// PKCS8 key format is only available for the client cert command.
 	BoolFlag(createClientCertCmd.Flags(), &generatePKCS8Key, cliflags.GeneratePKCS8Key, false)
 
@@ -452,6 +483,8 @@ func init() {
 		debugGossipValuesCmd,
 		debugTimeSeriesDumpCmd,
 		debugZipCmd,
+		debugListFilesCmd,
+		debugGetFilesCmd,
 		dumpCmd,
 		genHAProxyCmd,
 		quitCmd,
@@ -460,6 +493,12 @@ func init() {
 	}
 	clientCmds = append(clientCmds, userCmds...)
 	clientCmds = append(clientCmds, nodeCmds...)
+	clientCmds = append(clientCmds, nodeLocalCmds...)
+	clientCmds = append(clientCmds, userFileCmds...)
+	clientCmds = append(clientCmds, stmtDiagCmds...)
+	clientCmds = append(clientCmds, debugDoctorClusterCmd)
+	clientCmds = append(clientCmds, convertURLCmd)
+	clientCmds = append(clientCmds, genSettingsListCmd)
 	clientCmds = append(clientCmds, systemBenchCmds...)
 	clientCmds = append(clientCmds, initCmd)
 	for _, cmd := range clientCmds {
@@ -524,10 +563,28 @@ func init() {
 
 	// Decommission command.
 	VarFlag(decommissionNodeCmd.Flags(), &nodeCtx.nodeDecommissionWait, cliflags.Wait)
+	DurationFlag(decommissionNodeCmd.Flags(), &nodeCtx.nodeDecommissionStallTimeout,
+		cliflags.DecommissionStallTimeout, nodeCtx.nodeDecommissionStallTimeout)
 
 	// Quit command.
 	BoolFlag(quitCmd.Flags(), &quitCtx.serverDecommission, cliflags.Decommission, quitCtx.serverDecommission)
 
+	// Settings list command.
+	{
+		f := genSettingsListCmd.Flags()
+		VarFlag(f, &genSettingsListCtx.visibility, cliflags.SettingsVisibility)
+		StringSlice(f, &genSettingsListCtx.types, cliflags.SettingsType, genSettingsListCtx.types)
+		BoolFlag(f, &genSettingsListCtx.changed, cliflags.SettingsChanged, genSettingsListCtx.changed)
+	}
+
+	// Version command.
+	BoolFlag(versionCmd.Flags(), &versionCtx.detail, cliflags.VersionDetail, versionCtx.detail)
+
+	// Drain commands.
+	for _, cmd := range []*cobra.Command{quitCmd, drainNodeCmd} {
+		DurationFlag(cmd.Flags(), &quitCtx.drainTimeout, cliflags.DrainTimeout, quitCtx.drainTimeout)
+	}
+
 	for _, cmd := range append([]*cobra.Command{sqlShellCmd, demoCmd}, demoCmd.Commands()...) {
 		f := cmd.Flags()
 		VarFlag(f, &sqlCtx.setStmts, cliflags.Set)
@@ -536,12 +593,34 @@ func init() {
 		BoolFlag(f, &sqlCtx.debugMode, cliflags.CliDebugMode, sqlCtx.debugMode)
 	}
 
+	{
+		f := sqlShellCmd.Flags()
+		StringFlag(f, &sqlCtx.inputFile, cliflags.SQLFile, sqlCtx.inputFile)
+		IntFlag(f, &sqlCtx.batchSize, cliflags.SQLBatchSize, sqlCtx.batchSize)
+		IntFlag(f, &sqlCtx.skipBatches, cliflags.SQLSkipBatches, sqlCtx.skipBatches)
+		BoolFlag(f, &sqlCtx.continueOnError, cliflags.SQLContinueOnError, sqlCtx.continueOnError)
+	}
+
+	{
+		f := stmtDiagActivateCmd.Flags()
+		DurationFlag(f, &stmtDiagCtx.minLatency, cliflags.StmtDiagMinLatency, stmtDiagCtx.minLatency)
+		DurationFlag(f, &stmtDiagCtx.expiresAfter, cliflags.StmtDiagExpiresAfter, stmtDiagCtx.expiresAfter)
+		Float64Flag(f, &stmtDiagCtx.samplingProbability, cliflags.StmtDiagSamplingProbability,
+			stmtDiagCtx.samplingProbability)
+	}
+
 	VarFlag(dumpCmd.Flags(), &dumpCtx.dumpMode, cliflags.DumpMode)
 	StringFlag(dumpCmd.Flags(), &dumpCtx.asOf, cliflags.DumpTime, dumpCtx.asOf)
 
 	// Commands that establish a SQL connection.
 	sqlCmds := []*cobra.Command{sqlShellCmd, dumpCmd, demoCmd}
 	sqlCmds = append(sqlCmds, userCmds...)
+	sqlCmds = append(sqlCmds, nodeLocalCmds...)
+	sqlCmds = append(sqlCmds, userFileCmds...)
+	sqlCmds = append(sqlCmds, stmtDiagCmds...)
+	sqlCmds = append(sqlCmds, debugDoctorClusterCmd)
+	sqlCmds = append(sqlCmds, convertURLCmd)
+	sqlCmds = append(sqlCmds, genSettingsListCmd)
 	sqlCmds = append(sqlCmds, demoCmd.Commands()...)
 	for _, cmd := range sqlCmds {
 		f := cmd.Flags()
@@ -552,7 +631,7 @@ func init() {
 			StringFlag(f, &cliCtx.sqlConnUser, cliflags.User, cliCtx.sqlConnUser)
 		}
 
-		if cmd == sqlShellCmd {
+		if cmd == sqlShellCmd || cmd == convertURLCmd {
 			StringFlag(f, &cliCtx.sqlConnDBName, cliflags.Database, cliCtx.sqlConnDBName)
 		}
 	}
@@ -573,6 +652,7 @@ func init() {
 		demoCmd.Commands()...)
 	tableOutputCommands = append(tableOutputCommands, userCmds...)
 	tableOutputCommands = append(tableOutputCommands, nodeCmds...)
+	tableOutputCommands = append(tableOutputCommands, stmtDiagListCmd)
 
 	// By default, these commands print their output as pretty-formatted
 	// tables on terminals, and TSV when redirected to a file. The user
@@ -589,6 +669,8 @@ func init() {
 	// We add this command as a persistent flag so you can do stuff like
 	// ./cockroach demo movr --nodes=3.
 	IntFlag(demoFlags, &demoCtx.nodes, cliflags.DemoNodes, 1)
+	VarFlag(demoFlags, &demoCtx.localities, cliflags.DemoNodeLocality)
+	BoolFlag(demoFlags, &demoCtx.simulateLatency, cliflags.Global, false)
 	// The --empty flag is only valid for the top level demo command,
 	// so we use the regular flag set.
 	BoolFlag(demoCmd.Flags(), &demoCtx.useEmptyDatabase, cliflags.UseEmptyDatabase, false)
@@ -611,6 +693,11 @@ func init() {
 		BoolFlag(f, &debugCtx.values, cliflags.Values, debugCtx.values)
 		BoolFlag(f, &debugCtx.sizes, cliflags.Sizes, debugCtx.sizes)
 	}
+	{
+		f := debugCompactCmd.Flags()
+		VarFlag(f, (*mvccKey)(&debugCtx.startKey), cliflags.From)
+		VarFlag(f, (*mvccKey)(&debugCtx.endKey), cliflags.To)
+	}
 	{
 		f := debugRangeDataCmd.Flags()
 		BoolFlag(f, &debugCtx.replicated, cliflags.Replicated, debugCtx.replicated)
@@ -624,6 +711,23 @@ func init() {
 		f := debugBallastCmd.Flags()
 		VarFlag(f, &debugCtx.ballastSize, cliflags.Size)
 	}
+	{
+		f := debugZipCmd.Flags()
+		BoolFlag(f, &debugCtx.zipRedact, cliflags.ZipRedact, debugCtx.zipRedact)
+	}
+	for _, cmd := range []*cobra.Command{debugListFilesCmd, debugGetFilesCmd} {
+		f := cmd.Flags()
+		StringSlice(f, &debugCtx.nodes, cliflags.DebugFilesNodes, debugCtx.nodes)
+		StringSlice(f, &debugCtx.fileTypes, cliflags.DebugFileTypes, debugCtx.fileTypes)
+		StringFlag(f, &debugCtx.filePattern, cliflags.DebugFilePattern, debugCtx.filePattern)
+	}
+	{
+		f := debugTimeSeriesDumpCmd.Flags()
+		VarFlag(f, &debugCtx.tsDumpFormat, cliflags.TimeSeriesDumpFormat)
+		StringSlice(f, &debugCtx.tsDumpMetrics, cliflags.TimeSeriesDumpMetrics, debugCtx.tsDumpMetrics)
+		VarFlag(f, &debugCtx.tsDumpFrom, cliflags.TimeSeriesDumpFrom)
+		VarFlag(f, &debugCtx.tsDumpTo, cliflags.TimeSeriesDumpTo)
+	}
 }
 
 func extraServerFlagInit(cmd *cobra.Command) error {
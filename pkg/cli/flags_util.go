@@ -17,6 +17,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -81,6 +82,39 @@ func (l *localityList) Set(value string) error {
 // This file contains definitions for data types suitable for use by
 // the flag+pflag packages.
 
+// demoLocalityList is the list of the localities of the nodes of a demo
+// cluster. Each node's locality is specified in the same format as
+// --locality, and the localities of successive nodes are separated by
+// colons.
+type demoLocalityList []roachpb.Locality
+
+var _ pflag.Value = &demoLocalityList{}
+
+// Type implements the pflag.Value interface.
+func (l *demoLocalityList) Type() string { return "demoLocalityList" }
+
+// String implements the pflag.Value interface.
+func (l *demoLocalityList) String() string {
+	s := make([]string, len(*l))
+	for i, loc := range *l {
+		s[i] = loc.String()
+	}
+	return strings.Join(s, ":")
+}
+
+// Set implements the pflag.Value interface.
+func (l *demoLocalityList) Set(value string) error {
+	*l = []roachpb.Locality{}
+	for _, v := range strings.Split(value, ":") {
+		var loc roachpb.Locality
+		if err := loc.Set(v); err != nil {
+			return errors.Wrapf(err, "invalid value for --demo-locality: %s", value)
+		}
+		*l = append(*l, loc)
+	}
+	return nil
+}
+
 // statementsValue is an implementation of pflag.Value that appends any
 // argument to a slice.
 type statementsValue []string
@@ -297,6 +331,8 @@ const (
 	tableDisplaySQL
 	tableDisplayHTML
 	tableDisplayRaw
+	tableDisplayJSON
+	tableDisplayNDJSON
 	tableDisplayLastFormat
 )
 
@@ -320,6 +356,10 @@ func (f *tableDisplayFormat) String() string {
 		return "html"
 	case tableDisplayRaw:
 		return "raw"
+	case tableDisplayJSON:
+		return "json"
+	case tableDisplayNDJSON:
+		return "ndjson"
 	}
 	return ""
 }
@@ -341,10 +381,125 @@ func (f *tableDisplayFormat) Set(s string) error {
 		*f = tableDisplayHTML
 	case "raw":
 		*f = tableDisplayRaw
+	case "json":
+		*f = tableDisplayJSON
+	case "ndjson":
+		*f = tableDisplayNDJSON
 	default:
 		return fmt.Errorf("invalid table display format: %s "+
-			"(possible values: tsv, csv, table, records, sql, html, raw)", s)
+			"(possible values: tsv, csv, table, records, sql, html, raw, json, ndjson)", s)
+	}
+	return nil
+}
+
+type tsDumpFormat int
+
+const (
+	tsDumpRaw tsDumpFormat = iota
+	tsDumpCSV
+	tsDumpOpenMetrics
+)
+
+// Type implements the pflag.Value interface.
+func (f *tsDumpFormat) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (f *tsDumpFormat) String() string {
+	switch *f {
+	case tsDumpRaw:
+		return "raw"
+	case tsDumpCSV:
+		return "csv"
+	case tsDumpOpenMetrics:
+		return "openmetrics"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (f *tsDumpFormat) Set(s string) error {
+	switch s {
+	case "raw":
+		*f = tsDumpRaw
+	case "csv":
+		*f = tsDumpCSV
+	case "openmetrics":
+		*f = tsDumpOpenMetrics
+	default:
+		return fmt.Errorf("invalid time series dump format: %s "+
+			"(possible values: raw, csv, openmetrics)", s)
+	}
+	return nil
+}
+
+// settingsVisibility selects the cluster settings listed by gen
+// settings-list.
+type settingsVisibility int
+
+const (
+	settingsPublic settingsVisibility = iota
+	settingsReserved
+	settingsAll
+)
+
+// Type implements the pflag.Value interface.
+func (v *settingsVisibility) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (v *settingsVisibility) String() string {
+	switch *v {
+	case settingsPublic:
+		return "public"
+	case settingsReserved:
+		return "reserved"
+	case settingsAll:
+		return "all"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (v *settingsVisibility) Set(s string) error {
+	switch s {
+	case "public":
+		*v = settingsPublic
+	case "reserved":
+		*v = settingsReserved
+	case "all":
+		*v = settingsAll
+	default:
+		return fmt.Errorf("invalid setting visibility: %s "+
+			"(possible values: public, reserved, all)", s)
+	}
+	return nil
+}
+
+// timeValue is a flag that accepts a timestamp in RFC 3339 format. The
+// zero value, printed as the empty string, stands for an unset bound.
+type timeValue time.Time
+
+// Type implements the pflag.Value interface.
+func (t *timeValue) Type() string { return "timestamp" }
+
+// String implements the pflag.Value interface.
+func (t *timeValue) String() string {
+	if time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
+
+// Set implements the pflag.Value interface.
+func (t *timeValue) Set(s string) error {
+	if s == "" {
+		*t = timeValue{}
+		return nil
+	}
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp %q (expected format: 2006-01-02T15:04:05Z)", s)
 	}
+	*t = timeValue(v)
 	return nil
 }
 
@@ -12,6 +12,7 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html"
 	"io"
@@ -36,6 +37,9 @@ type rowStrIter interface {
 	Next() (row []string, err error)
 	ToSlice() (allRows [][]string, err error)
 	Align() []int
+	// ColumnTypeNames returns the database type names of the columns (e.g.
+	// INT8), or nil if they are not known.
+	ColumnTypeNames() []string
 }
 
 // rowSliceIter is an implementation of the rowStrIter interface and it is used
@@ -64,6 +68,10 @@ func (iter *rowSliceIter) Align() []int {
 	return iter.align
 }
 
+func (iter *rowSliceIter) ColumnTypeNames() []string {
+	return nil
+}
+
 func convertAlign(align string) []int {
 	result := make([]int, len(align))
 	for i, v := range align {
@@ -134,6 +142,15 @@ func (iter *rowIter) Align() []int {
 	return align
 }
 
+func (iter *rowIter) ColumnTypeNames() []string {
+	cols := iter.rows.Columns()
+	typs := make([]string, len(cols))
+	for i := range typs {
+		typs[i] = iter.rows.ColumnTypeDatabaseTypeName(i)
+	}
+	return typs
+}
+
 func newRowIter(rows *sqlRows, showMoreChars bool) *rowIter {
 	return &rowIter{
 		rows:          rows,
@@ -575,6 +592,118 @@ func (p *sqlReporter) doneRows(w io.Writer, seenRows int) error {
 	return nil
 }
 
+// jsonReporter renders each row as a JSON object keyed by column name. In
+// ndjson mode, the objects are printed one per line; otherwise they are
+// wrapped in a JSON array.
+type jsonReporter struct {
+	ndjson bool
+	cols   []string
+	typs   []string
+	buf    bytes.Buffer
+}
+
+func (p *jsonReporter) describe(w io.Writer, cols []string) error {
+	p.cols = cols
+	return nil
+}
+
+func (p *jsonReporter) beforeFirstRow(w io.Writer, iter rowStrIter) error {
+	p.typs = iter.ColumnTypeNames()
+	if !p.ndjson {
+		fmt.Fprintln(w, "[")
+	}
+	return nil
+}
+
+func (p *jsonReporter) iter(w io.Writer, rowIdx int, row []string) error {
+	if !p.ndjson {
+		if rowIdx > 0 {
+			fmt.Fprintln(w, ",")
+		}
+		fmt.Fprint(w, "  ")
+	}
+	p.buf.Reset()
+	p.buf.WriteByte('{')
+	for i, r := range row {
+		if i > 0 {
+			p.buf.WriteByte(',')
+		}
+		if err := writeJSONString(&p.buf, p.cols[i]); err != nil {
+			return err
+		}
+		p.buf.WriteByte(':')
+		var typ string
+		if i < len(p.typs) {
+			typ = p.typs[i]
+		}
+		if err := writeJSONValue(&p.buf, typ, r); err != nil {
+			return err
+		}
+	}
+	p.buf.WriteByte('}')
+	_, err := w.Write(p.buf.Bytes())
+	if p.ndjson {
+		fmt.Fprintln(w)
+	}
+	return err
+}
+
+func (p *jsonReporter) doneRows(w io.Writer, seenRows int) error {
+	if !p.ndjson {
+		if seenRows == 0 {
+			fmt.Fprintln(w, "[]")
+		} else {
+			fmt.Fprintln(w, "\n]")
+		}
+	}
+	return nil
+}
+
+func (p *jsonReporter) doneNoRows(_ io.Writer) error { return nil }
+
+// writeJSONString writes s to buf as a JSON string. Unlike json.Marshal, it
+// does not escape HTML characters.
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	// Encode terminates its output with a newline.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// writeJSONValue writes the formatted value of a column of the given database
+// type to buf. Values of numeric, boolean and JSON columns are emitted as the
+// corresponding JSON values when possible, and all other values as strings.
+func writeJSONValue(buf *bytes.Buffer, typ string, val string) error {
+	if val == "NULL" {
+		buf.WriteString("null")
+		return nil
+	}
+	switch typ {
+	case "INT2", "INT4", "INT8", "OID", "FLOAT4", "FLOAT8", "NUMERIC":
+		// NaN and infinities are not valid JSON numbers, and are emitted
+		// as strings below.
+		if val != "" && (val[0] == '-' || (val[0] >= '0' && val[0] <= '9')) && json.Valid([]byte(val)) {
+			buf.WriteString(val)
+			return nil
+		}
+	case "BOOL":
+		if val == "true" || val == "false" {
+			buf.WriteString(val)
+			return nil
+		}
+	case "JSON", "JSONB":
+		if json.Valid([]byte(val)) {
+			buf.WriteString(val)
+			return nil
+		}
+	}
+	return writeJSONString(buf, val)
+}
+
 // makeReporter instantiates a table formatter. It returns the
 // formatter and a cleanup function that must be called in all cases
 // when the formatting completes.
@@ -601,6 +730,12 @@ func makeReporter(w io.Writer) (rowReporter, func(), error) {
 	case tableDisplaySQL:
 		return &sqlReporter{}, nil, nil
 
+	case tableDisplayJSON:
+		return &jsonReporter{}, nil, nil
+
+	case tableDisplayNDJSON:
+		return &jsonReporter{ndjson: true}, nil, nil
+
 	default:
 		return nil, nil, errors.Errorf("unhandled display format: %d", cliCtx.tableDisplayFormat)
 	}
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 )
@@ -180,57 +181,145 @@ The resulting key file will be 32 bytes (random key ID) + key_size in bytes.
 }
 
 var genSettingsListCmd = &cobra.Command{
-	Use:   "settings-list <output-dir>",
+	Use:   "settings-list",
 	Short: "output a list of available cluster settings",
 	Long: `
 Output the list of cluster settings known to this binary.
+
+The list can be restricted to the settings of a given visibility with
+--visibility, and to settings of given types with --type. With --changed,
+the command connects to a cluster and only lists the public settings whose
+value differs from the default, along with their current value. Use --format
+to produce CSV or JSON output.
 `,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		wrapCode := func(s string) string {
-			if cliCtx.tableDisplayFormat == tableDisplayHTML {
-				return fmt.Sprintf("<code>%s</code>", s)
-			}
-			return s
+	Example: `  cockroach gen settings-list --type=duration,boolean --format=csv
+  cockroach gen settings-list --changed --format=json --host=localhost`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runGenSettingsList),
+}
+
+func runGenSettingsList(cmd *cobra.Command, args []string) error {
+	wrapCode := func(s string) string {
+		if cliCtx.tableDisplayFormat == tableDisplayHTML {
+			return fmt.Sprintf("<code>%s</code>", s)
 		}
+		return s
+	}
 
-		// Fill a Values struct with the defaults.
-		s := cluster.MakeTestingClusterSettings()
-		settings.NewUpdater(&s.SV).ResetRemaining()
+	types := make(map[string]struct{}, len(genSettingsListCtx.types))
+	for _, typ := range genSettingsListCtx.types {
+		if !isReadableSettingType(typ) {
+			return errors.Errorf("unknown setting type: %s", typ)
+		}
+		types[typ] = struct{}{}
+	}
 
-		var rows [][]string
-		for _, name := range settings.Keys() {
-			setting, ok := settings.Lookup(name)
-			if !ok {
-				panic(fmt.Sprintf("could not find setting %q", name))
-			}
+	var currentValues map[string]string
+	if genSettingsListCtx.changed {
+		if genSettingsListCtx.visibility != settingsPublic {
+			return errors.New("the values of reserved settings are not reported by the cluster; " +
+				"--changed requires --visibility=public")
+		}
+		var err error
+		if currentValues, err = getClusterSettingValues(); err != nil {
+			return err
+		}
+	}
 
-			typ, ok := settings.ReadableTypes[setting.Typ()]
-			if !ok {
-				panic(fmt.Sprintf("unknown setting type %q", setting.Typ()))
+	// Fill a Values struct with the defaults.
+	s := cluster.MakeTestingClusterSettings()
+	settings.NewUpdater(&s.SV).ResetRemaining()
+
+	var rows [][]string
+	for _, name := range settings.AllKeys() {
+		setting, ok := settings.Lookup(name)
+		if !ok {
+			panic(fmt.Sprintf("could not find setting %q", name))
+		}
+		switch genSettingsListCtx.visibility {
+		case settingsPublic:
+			if setting.Hidden() {
+				continue
 			}
-			defaultVal := setting.String(&s.SV)
-			if override, ok := sqlmigrations.SettingsDefaultOverrides[name]; ok {
-				defaultVal = override
+		case settingsReserved:
+			if !setting.Hidden() {
+				continue
 			}
-			row := []string{wrapCode(name), typ, wrapCode(defaultVal), setting.Description()}
-			rows = append(rows, row)
 		}
 
-		reporter, cleanup, err := makeReporter(os.Stdout)
-		if err != nil {
-			return err
+		typ, ok := settings.ReadableTypes[setting.Typ()]
+		if !ok {
+			panic(fmt.Sprintf("unknown setting type %q", setting.Typ()))
 		}
-		if cleanup != nil {
-			defer cleanup()
+		if _, ok := types[typ]; len(types) > 0 && !ok {
+			continue
 		}
-		if hr, ok := reporter.(*htmlReporter); ok {
-			hr.escape = false
-			hr.rowStats = false
+		defaultVal := setting.String(&s.SV)
+		if override, ok := sqlmigrations.SettingsDefaultOverrides[name]; ok {
+			defaultVal = override
 		}
-		cols := []string{"Setting", "Type", "Default", "Description"}
-		return render(reporter, os.Stdout,
-			cols, newRowSliceIter(rows, "dddd"), nil /* completedHook */, nil /* noRowsHook*/)
-	},
+		row := []string{wrapCode(name), typ, wrapCode(defaultVal), setting.Description()}
+		if currentValues != nil {
+			value, ok := currentValues[name]
+			if !ok || value == defaultVal {
+				continue
+			}
+			row = append(row, wrapCode(value))
+		}
+		rows = append(rows, row)
+	}
+
+	reporter, cleanup, err := makeReporter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if hr, ok := reporter.(*htmlReporter); ok {
+		hr.escape = false
+		hr.rowStats = false
+	}
+	cols := []string{"Setting", "Type", "Default", "Description"}
+	align := "dddd"
+	if currentValues != nil {
+		cols = append(cols, "Value")
+		align += "d"
+	}
+	return render(reporter, os.Stdout,
+		cols, newRowSliceIter(rows, align), nil /* completedHook */, nil /* noRowsHook*/)
+}
+
+// isReadableSettingType returns whether typ is one of the setting types
+// listed by gen settings-list.
+func isReadableSettingType(typ string) bool {
+	for _, t := range settings.ReadableTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// getClusterSettingValues retrieves the current values of the public
+// cluster settings from the cluster the command connects to.
+func getClusterSettingValues() (map[string]string, error) {
+	conn, err := getPasswordAndMakeSQLClient("cockroach gen settings-list")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, rows, err := runQuery(conn,
+		makeQuery(`SELECT variable, value FROM crdb_internal.cluster_settings`), true /* showMoreChars */)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		values[row[0]] = row[1]
+	}
+	return values, nil
 }
 
 var genCmd = &cobra.Command{
@@ -20,8 +20,10 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -306,11 +308,22 @@ func runDecommissionNode(cmd *cobra.Command, args []string) error {
 	}
 	defer finish()
 
-	return runDecommissionNodeImpl(ctx, c, nodeCtx.nodeDecommissionWait, args)
+	return runDecommissionNodeImpl(
+		ctx, c, nodeCtx.nodeDecommissionWait, nodeCtx.nodeDecommissionStallTimeout, args,
+	)
 }
 
+// runDecommissionNodeImpl marks the given nodes as decommissioning and, unless
+// wait is nodeDecommissionWaitNone, waits for their replicas to be moved
+// elsewhere, printing the per-node replica counts whenever they change. If
+// stallTimeout is nonzero, an error is returned when the total number of
+// replicas on the target nodes has not decreased for that long.
 func runDecommissionNodeImpl(
-	ctx context.Context, c serverpb.AdminClient, wait nodeDecommissionWaitType, args []string,
+	ctx context.Context,
+	c serverpb.AdminClient,
+	wait nodeDecommissionWaitType,
+	stallTimeout time.Duration,
+	args []string,
 ) error {
 	if wait == nodeDecommissionWaitLive {
 		fmt.Fprintln(stderr, "\n--wait=live is deprecated and is treated as --wait=all")
@@ -327,6 +340,7 @@ func runDecommissionNodeImpl(
 	}
 
 	prevResponse := serverpb.DecommissionStatusResponse{}
+	lastProgress := timeutil.Now()
 	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
 		req := &serverpb.DecommissionRequest{
 			NodeIDs:         nodeIDs,
@@ -363,7 +377,12 @@ func runDecommissionNodeImpl(
 		}
 		if replicaCount < minReplicaCount {
 			minReplicaCount = replicaCount
+			lastProgress = timeutil.Now()
 			r.Reset()
+		} else if stallTimeout > 0 && timeutil.Since(lastProgress) > stallTimeout {
+			fmt.Fprintln(stderr)
+			return errors.Errorf("decommissioning stalled: %d replicas remain on the target nodes "+
+				"and none have been moved in the last %s", replicaCount, stallTimeout)
 		}
 	}
 	return errors.New("maximum number of retries exceeded")
@@ -403,6 +422,58 @@ signaling the affected nodes to participate in the cluster again.
 	RunE: MaybeDecorateGRPCError(runRecommissionNode),
 }
 
+var drainNodeCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "drain a node without shutting it down",
+	Long: `
+Prepare the node for a shutdown: wait for the configured drain wait in an
+unready state, so that load balancers stop routing new connections to the
+node, then close the SQL client connections once their active queries finish,
+and transfer the range leases away from the node. The process keeps running and
+can then be stopped with cockroach quit or by a process manager.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDrain),
+}
+
+func runDrain(cmd *cobra.Command, args []string) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), quitCtx.drainTimeout)
+	defer cancel()
+
+	c, finish, err := getAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	if err := checkNodeRunning(ctx, c); err != nil {
+		if server.IsWaitingForInit(err) {
+			return fmt.Errorf("node cannot be drained before it has been initialized")
+		}
+		return err
+	}
+	onModes := make([]int32, len(server.GracefulDrainModes))
+	for i, m := range server.GracefulDrainModes {
+		onModes[i] = int32(m)
+	}
+	stream, err := c.Drain(ctx, &serverpb.DrainRequest{On: onModes})
+	if err != nil {
+		return errors.Wrap(err, "error sending drain request")
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Errorf("node did not drain within %s", quitCtx.drainTimeout)
+		}
+		return errors.Wrap(err, "error while draining")
+	}
+	if len(resp.On) != len(onModes) {
+		return errors.Errorf("node did not fully drain: active drain modes %v", resp.On)
+	}
+	fmt.Println("ok")
+	return nil
+}
+
 func printDecommissionStatus(resp serverpb.DecommissionStatusResponse) error {
 	return printQueryOutput(os.Stdout, decommissionNodesColumnHeaders,
 		newRowSliceIter(decommissionResponseValueToRows(resp.Status), decommissionResponseAlignment()))
@@ -440,12 +511,13 @@ var nodeCmds = []*cobra.Command{
 	statusNodeCmd,
 	decommissionNodeCmd,
 	recommissionNodeCmd,
+	drainNodeCmd,
 }
 
 var nodeCmd = &cobra.Command{
 	Use:   "node [command]",
-	Short: "list, inspect or remove nodes",
-	Long:  "List, inspect or remove nodes.",
+	Short: "list, inspect, drain or remove nodes",
+	Long:  "List, inspect, drain or remove nodes.",
 	RunE:  usageAndErr,
 }
 
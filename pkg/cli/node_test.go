@@ -0,0 +1,85 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"google.golang.org/grpc"
+)
+
+// fakeDecommissionClient is an AdminClient whose Decommission method reports
+// the replica counts returned by the counts function, which is passed the
+// number of calls made so far.
+type fakeDecommissionClient struct {
+	serverpb.AdminClient
+	calls  int
+	counts func(call int) int64
+}
+
+func (c *fakeDecommissionClient) Decommission(
+	ctx context.Context, req *serverpb.DecommissionRequest, _ ...grpc.CallOption,
+) (*serverpb.DecommissionStatusResponse, error) {
+	c.calls++
+	return &serverpb.DecommissionStatusResponse{
+		Status: []serverpb.DecommissionStatusResponse_Status{{
+			NodeID:          roachpb.NodeID(2),
+			IsLive:          true,
+			ReplicaCount:    c.counts(c.calls),
+			Decommissioning: true,
+		}},
+	}, nil
+}
+
+func TestDecommissionNodeStallTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+
+	// The replica count drops to zero, so decommissioning completes.
+	c := &fakeDecommissionClient{counts: func(call int) int64 {
+		if call >= 3 {
+			return 0
+		}
+		return int64(10 - call)
+	}}
+	if err := runDecommissionNodeImpl(
+		ctx, c, nodeDecommissionWaitAll, time.Minute, []string{"2"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// The replica count never drops, so decommissioning stalls.
+	c = &fakeDecommissionClient{counts: func(int) int64 { return 10 }}
+	err := runDecommissionNodeImpl(
+		ctx, c, nodeDecommissionWaitAll, time.Millisecond, []string{"2"},
+	)
+	if !testutils.IsError(err, "decommissioning stalled: 10 replicas remain") {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+
+	// With --wait=none, the command returns right away.
+	c = &fakeDecommissionClient{counts: func(int) int64 { return 10 }}
+	if err := runDecommissionNodeImpl(
+		ctx, c, nodeDecommissionWaitNone, time.Millisecond, []string{"2"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if c.calls != 1 {
+		t.Fatalf("expected a single call, got %d", c.calls)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// nodeLocalUploadChunkSize is the size of the chunks in which files are sent
+// to the server.
+const nodeLocalUploadChunkSize = 4 << 20 // 4 MiB
+
+var nodeLocalUploadCmd = &cobra.Command{
+	Use:   "upload <source> <destination>",
+	Short: "upload file from source to destination",
+	Long: `
+Upload a file to the external IO directory of the node the command connects
+to, so that it can be used with IMPORT from a nodelocal:// URI without access
+to the machine running the node. The destination is relative to the external
+IO directory of the node (see --external-io-dir), and must not exist yet.
+
+Requires the admin role.
+`,
+	Example: `  cockroach nodelocal upload ./data/customers.csv imports/customers.csv`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    MaybeDecorateGRPCError(runUpload),
+}
+
+func runUpload(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach nodelocal")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	source, destination := args[0], args[1]
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := uploadFile(conn, f, destination); err != nil {
+		return err
+	}
+	fmt.Printf("successfully uploaded to nodelocal://%s\n", destination)
+	return nil
+}
+
+// uploadFile streams the contents of reader to the destination in the external
+// IO directory of the node, using the COPY protocol. The driver only supports
+// COPY inside a transaction, which is why one is opened. It is not retried:
+// the reader can only be consumed once.
+func uploadFile(conn *sqlConn, reader io.Reader, destination string) (retErr error) {
+	if err := conn.Exec(`BEGIN`, nil); err != nil {
+		return err
+	}
+	stmt, err := conn.conn.Prepare(sql.CopyInFileStmt(destination))
+	if err != nil {
+		_ = conn.Exec(`ROLLBACK`, nil)
+		return err
+	}
+	defer func() {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+		if retErr != nil {
+			_ = conn.Exec(`ROLLBACK`, nil)
+		}
+	}()
+
+	chunk := make([]byte, nodeLocalUploadChunkSize)
+	for {
+		n, err := io.ReadFull(reader, chunk)
+		if n > 0 {
+			//lint:ignore SA1019 DriverConn doesn't support go 1.8 API
+			if _, err := stmt.Exec([]driver.Value{chunk[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading source file")
+		}
+	}
+	// Closing the statement completes the COPY.
+	err = stmt.Close()
+	stmt = nil
+	if err != nil {
+		return err
+	}
+	return conn.Exec(`COMMIT`, nil)
+}
+
+var nodeLocalCmds = []*cobra.Command{
+	nodeLocalUploadCmd,
+}
+
+var nodeLocalCmd = &cobra.Command{
+	Use:   "nodelocal [command]",
+	Short: "upload and manage files in the external IO directory of nodes",
+	RunE:  usageAndErr,
+}
+
+func init() {
+	nodeLocalCmd.AddCommand(nodeLocalCmds...)
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestNodeLocalFileUpload(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(context.Background())
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	conn := makeSQLConn(pgURL.String())
+	defer conn.Close()
+
+	// The contents span several chunks, and contain bytes that have a special
+	// meaning in the COPY text format.
+	contents := bytes.Repeat([]byte("a\tb\\c\nd\x00\xff"), nodeLocalUploadChunkSize/4)
+
+	if err := uploadFile(conn, bytes.NewReader(contents), "sub/test.csv"); err != nil {
+		t.Fatal(err)
+	}
+	uploaded, err := ioutil.ReadFile(filepath.Join(dir, "sub", "test.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(uploaded, contents) {
+		t.Fatalf("uploaded file differs from source (%d bytes, expected %d)",
+			len(uploaded), len(contents))
+	}
+
+	// Existing files are not overwritten.
+	if err := uploadFile(
+		conn, bytes.NewReader(contents), "sub/test.csv",
+	); !testutils.IsError(err, "already exists") {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	// Uploads can't escape the external IO directory.
+	if err := uploadFile(
+		conn, bytes.NewReader(contents), "../test.csv",
+	); !testutils.IsError(err, "outside of external-io-dir") {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	// Uploading requires the admin role.
+	sqlutils.MakeSQLRunner(db).Exec(t, `CREATE USER testuser`)
+	userURL, cleanupUser := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupUser()
+	userConn := makeSQLConn(userURL.String())
+	defer userConn.Close()
+	if err := uploadFile(
+		userConn, bytes.NewReader(contents), "other.csv",
+	); !testutils.IsError(err, "only users with the admin role") {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
@@ -57,6 +57,8 @@ Type:
   \set [NAME]       set a client-side flag or (without argument) print the current settings.
   \unset NAME       unset a flag.
   \show             during a multi-line statement or transaction, show the SQL entered so far.
+  \r                during a multi-line statement or transaction, erase all the SQL entered so far.
+  \s [PATTERN]      show the command-line history, or the entries that contain PATTERN.
   \h [NAME]         help on syntax of SQL commands.
   \hf [NAME]        help on SQL built-in functions.
   \l                list all databases in the CockroachDB cluster
@@ -159,6 +161,14 @@ type cliState struct {
 	// autoTrace, when non-empty, encloses the executed statements
 	// by suitable SET TRACING and SHOW TRACE FOR SESSION statements.
 	autoTrace string
+
+	// histFile is the path of the file where the command-line history
+	// is persisted, if any.
+	histFile string
+
+	// completions caches the table and column names used for tab
+	// completion.
+	completions completionCache
 }
 
 // cliStateEnum drives the CLI state machine in runInteractive().
@@ -482,6 +492,21 @@ func (c *cliState) handleHelp(cmd []string, nextState, errState cliStateEnum) cl
 	return nextState
 }
 
+// handleHistory prints the entries of the command-line history that
+// contain the given pattern, or the entire history if no pattern is given.
+func (c *cliState) handleHistory(cmd []string, nextState, errState cliStateEnum) cliStateEnum {
+	if c.histFile == "" {
+		fmt.Fprintln(stderr, "the command-line history is not saved in this session")
+		return errState
+	}
+	pattern := strings.TrimSpace(strings.Join(cmd, " "))
+	if err := searchHistory(os.Stdout, c.histFile, pattern); err != nil {
+		fmt.Fprintf(stderr, "cannot read the command-line history: %v\n", err)
+		return errState
+	}
+	return nextState
+}
+
 // handleFunctionHelp prints help about built-in functions.
 func (c *cliState) handleFunctionHelp(cmd []string, nextState, errState cliStateEnum) cliStateEnum {
 	funcName := strings.TrimSpace(strings.Join(cmd, " "))
@@ -744,22 +769,24 @@ func endsWithIncompleteTxn(stmts []string) bool {
 var cmdHistFile = envutil.EnvOrDefaultString("COCKROACH_SQL_CLI_HISTORY", ".cockroachsql_history")
 
 // GetCompletions implements the readline.CompletionGenerator interface.
-func (c *cliState) GetCompletions(_ string) []string {
+func (c *cliState) GetCompletions(word string) []string {
 	sql, _ := c.ins.GetLineInfo()
 
 	if !strings.HasSuffix(sql, "??") {
-		fmt.Fprintf(c.ins.Stdout(),
-			"\ntab completion not supported; append '??' and press tab for contextual help\n\n")
-	} else {
-		_, helpText, err := c.serverSideParse(sql)
-		if helpText != "" {
-			// We have a completion suggestion. Use that.
-			fmt.Fprintf(c.ins.Stdout(), "\nSuggestion:\n%s\n", helpText)
-		} else if err != nil {
-			// Some other error. Display it.
-			fmt.Fprintf(c.ins.Stdout(), "\n%v\n", err)
-			maybeShowErrorDetails(c.ins.Stdout(), err, false)
-		}
+		// Complete the current word, taking into account the previous
+		// lines of a multi-line statement for context.
+		lines := append(append([]string(nil), c.partialLines...), sql)
+		return completeInput(strings.Join(lines, "\n"), word, c.completionNames)
+	}
+
+	_, helpText, err := c.serverSideParse(sql)
+	if helpText != "" {
+		// We have a completion suggestion. Use that.
+		fmt.Fprintf(c.ins.Stdout(), "\nSuggestion:\n%s\n", helpText)
+	} else if err != nil {
+		// Some other error. Display it.
+		fmt.Fprintf(c.ins.Stdout(), "\n%v\n", err)
+		maybeShowErrorDetails(c.ins.Stdout(), err, false)
 	}
 
 	// After the suggestion or error, re-display the prompt and current entry.
@@ -813,6 +840,7 @@ func (c *cliState) doStart(nextState cliStateEnum) cliStateEnum {
 				log.Warning(context.TODO(), "history will not be saved")
 			} else {
 				histFile := filepath.Join(homeDir, cmdHistFile)
+				c.histFile = histFile
 				err = c.ins.LoadHistory(histFile)
 				if err != nil {
 					log.Warningf(context.TODO(), "cannot load the command-line history (file corrupted?): %v", err)
@@ -1018,6 +1046,15 @@ func (c *cliState) doHandleCliCmd(loopState, nextState cliStateEnum) cliStateEnu
 	case `\|`:
 		return c.pipeSyscmd(c.lastInputLine, nextState, errState)
 
+	case `\r`, `\reset`:
+		if len(c.partialLines) > 0 {
+			fmt.Fprintln(stderr, "Input buffer reset (cleared).")
+		}
+		return cliStartLine
+
+	case `\s`:
+		return c.handleHistory(cmd[1:], loopState, errState)
+
 	case `\h`:
 		return c.handleHelp(cmd[1:], loopState, errState)
 
@@ -1181,7 +1218,9 @@ func (c *cliState) doRunStatement(nextState cliStateEnum) cliStateEnum {
 		}
 	}
 
-	// Now run the statement/query.
+	// Now run the statement/query. The statement may change the schema or
+	// the current database, so forget the names used for tab completion.
+	c.completions = completionCache{}
 	c.exitErr = runQueryAndFormatResults(c.conn, os.Stdout, makeQuery(c.concatLines))
 	if c.exitErr != nil {
 		fmt.Fprintln(stderr, c.exitErr)
@@ -1301,6 +1340,10 @@ func runInteractive(conn *sqlConn) (exitErr error) {
 				return c.runStatements(sqlCtx.execStmts)
 			}
 
+			if sqlCtx.batchSize > 0 {
+				return c.runBatches(stdin)
+			}
+
 			if cliCtx.terminalOutput {
 				// If results are shown on a terminal also enable printing of
 				// times by default.
@@ -1424,11 +1467,50 @@ func checkInteractive() {
 	// and we'll also assume that there is no human if the standard
 	// input is not terminal-like -- likely redirected from a file,
 	// etc.
-	cliCtx.isInteractive = len(sqlCtx.execStmts) == 0 && isatty.IsTerminal(os.Stdin.Fd())
+	cliCtx.isInteractive = len(sqlCtx.execStmts) == 0 && sqlCtx.inputFile == "" &&
+		isatty.IsTerminal(os.Stdin.Fd())
+}
+
+// checkBatchFlags verifies that the flags controlling the input and its
+// execution in batches are consistent.
+func checkBatchFlags() error {
+	if sqlCtx.inputFile != "" && len(sqlCtx.execStmts) > 0 {
+		return errors.Errorf("--%s and --%s cannot be used together",
+			cliflags.SQLFile.Name, cliflags.Execute.Name)
+	}
+	if sqlCtx.batchSize < 0 || sqlCtx.skipBatches < 0 {
+		return errors.Errorf("--%s and --%s must not be negative",
+			cliflags.SQLBatchSize.Name, cliflags.SQLSkipBatches.Name)
+	}
+	if sqlCtx.batchSize == 0 {
+		if sqlCtx.skipBatches > 0 || sqlCtx.continueOnError {
+			return errors.Errorf("--%s and --%s require --%s",
+				cliflags.SQLSkipBatches.Name, cliflags.SQLContinueOnError.Name, cliflags.SQLBatchSize.Name)
+		}
+		return nil
+	}
+	if len(sqlCtx.execStmts) > 0 || cliCtx.isInteractive {
+		return errors.Errorf("--%s requires input from a file or from the redirected standard input",
+			cliflags.SQLBatchSize.Name)
+	}
+	return nil
 }
 
 func runTerm(cmd *cobra.Command, args []string) error {
 	checkInteractive()
+	if err := checkBatchFlags(); err != nil {
+		return err
+	}
+
+	if sqlCtx.inputFile != "" {
+		f, err := os.Open(sqlCtx.inputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		defer func(prev *os.File) { stdin = prev }(stdin)
+		stdin = f
+	}
 
 	if cliCtx.isInteractive {
 		// The user only gets to see the welcome message on interactive sessions.
@@ -0,0 +1,97 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/pkg/errors"
+)
+
+// splitStatements splits SQL input into its statements, without their
+// terminating semicolons. Empty statements, including those that only
+// contain comments, are dropped.
+func splitStatements(input string) []string {
+	var stmts []string
+	for len(input) > 0 {
+		stmt := input
+		pos, ok := parser.SplitFirstStatement(input)
+		if ok {
+			// Leave out the semicolon.
+			stmt = input[:pos-1]
+			input = input[pos:]
+		} else {
+			// The last statement may not be terminated by a semicolon. If the
+			// input cannot be scanned, the server reports the error.
+			input = ""
+		}
+		if _, ok := parser.LastLexicalToken(stmt); ok {
+			stmts = append(stmts, strings.TrimSpace(stmt))
+		}
+	}
+	return stmts
+}
+
+// runBatches executes the statements read from r in batches of
+// sqlCtx.batchSize statements, each batch in its own transaction. The first
+// sqlCtx.skipBatches batches are skipped, so that an interrupted script can
+// be resumed. A failed batch is rolled back; unless sqlCtx.continueOnError
+// is set, the execution then stops.
+func (c *cliState) runBatches(r io.Reader) error {
+	input, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	stmts := splitStatements(string(input))
+	batchSize := sqlCtx.batchSize
+	numBatches := (len(stmts) + batchSize - 1) / batchSize
+
+	var failed []int
+	for b := sqlCtx.skipBatches; b < numBatches; b++ {
+		start, end := b*batchSize, (b+1)*batchSize
+		if end > len(stmts) {
+			end = len(stmts)
+		}
+		if err := runBatch(c.conn, stmts[start:end]); err != nil {
+			fmt.Fprintf(stderr, "batch %d (statements %d to %d) failed and was rolled back: %v\n",
+				b+1, start+1, end, err)
+			maybeShowErrorDetails(stderr, err, false)
+			if !sqlCtx.continueOnError {
+				return errors.Errorf("batch %d of %d failed; batches 1 to %d were committed.\n"+
+					"To resume after fixing the problem, run the script again with --%s=%d.",
+					b+1, numBatches, b, cliflags.SQLSkipBatches.Name, b)
+			}
+			failed = append(failed, b+1)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("%d of %d batches failed and were rolled back: %s",
+			len(failed), numBatches, strings.Trim(fmt.Sprint(failed), "[]"))
+	}
+	return nil
+}
+
+// runBatch executes the given statements in a transaction.
+func runBatch(conn *sqlConn, stmts []string) error {
+	return conn.ExecTxn(func(conn *sqlConn) error {
+		for _, stmt := range stmts {
+			if err := conn.Exec(stmt, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSplitStatements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{``, nil},
+		{`;;  ; -- comment only;`, nil},
+		{`SELECT 1`, []string{`SELECT 1`}},
+		{"SELECT 1; SELECT 2;\n", []string{`SELECT 1`, `SELECT 2`}},
+		{`INSERT INTO t VALUES ('a;b'); SELECT 2`, []string{`INSERT INTO t VALUES ('a;b')`, `SELECT 2`}},
+		{"-- header\nCREATE TABLE t (x INT);\n-- trailer\n", []string{"-- header\nCREATE TABLE t (x INT)"}},
+	}
+	for _, tc := range testCases {
+		if actual := splitStatements(tc.input); !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("%q: expected %q, got %q", tc.input, tc.expected, actual)
+		}
+	}
+}
+
+func TestRunBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	script := filepath.Join(dir, "script.sql")
+	// The fourth statement fails, so the second batch is rolled back.
+	const input = `
+CREATE DATABASE IF NOT EXISTS batch;
+CREATE TABLE IF NOT EXISTS batch.t (x INT PRIMARY KEY);
+INSERT INTO batch.t VALUES (1);
+INSERT INTO batch.t VALUES (1);
+INSERT INTO batch.t VALUES (3);
+`
+	if err := ioutil.WriteFile(script, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRows := func(expected string) {
+		t.Helper()
+		out, err := c.RunWithCaptureArgs([]string{"sql", "--format=csv", "-e", "SELECT x FROM batch.t ORDER BY x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(out, "x\n"+expected) {
+			t.Fatalf("expected rows %q, got:\n%s", expected, out)
+		}
+	}
+
+	out, err := c.RunWithCaptureArgs([]string{"sql", "--file=" + script, "--batch-size=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "batch 2 of 3 failed; batches 1 to 1 were committed") ||
+		!strings.Contains(out, "--skip-batches=1") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	checkRows("")
+
+	out, err = c.RunWithCaptureArgs([]string{
+		"sql", "--file=" + script, "--batch-size=2", "--skip-batches=1", "--continue-on-error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "1 of 3 batches failed and were rolled back: 2") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	checkRows("3\n")
+
+	out, err = c.RunWithCaptureArgs([]string{"sql", "-e", "SELECT 1", "--batch-size=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "--batch-size requires input from a file") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+)
+
+// cliCommandNames lists the client-side commands, for tab completion.
+var cliCommandNames = []string{
+	`\?`, `\q`, `\!`, `\|`, `\set`, `\unset`, `\show`, `\r`, `\s`,
+	`\h`, `\hf`, `\l`, `\dt`, `\du`, `\d`,
+}
+
+// tableCompletionContext lists the keywords after which table names are
+// expected.
+var tableCompletionContext = map[string]struct{}{
+	"FROM":     {},
+	"JOIN":     {},
+	"INTO":     {},
+	"UPDATE":   {},
+	"TABLE":    {},
+	"TRUNCATE": {},
+	`\D`:       {},
+}
+
+// completionCache holds the schema names used for tab completion. They are
+// fetched lazily from the server upon the first completion that needs them,
+// and discarded after every statement executed, since the statement may
+// have changed the schema or the current database.
+type completionCache struct {
+	tables  []string
+	columns []string
+	valid   bool
+}
+
+// completionNames returns the names of the tables and columns in the current
+// database, fetching them from the server if necessary. Errors are ignored:
+// tab completion simply does not propose any names in that case.
+func (c *cliState) completionNames() (tables, columns []string) {
+	if !c.completions.valid && c.conn != nil {
+		c.completions.tables = c.completionQuery(
+			`SELECT table_name FROM information_schema.tables
+			  WHERE table_catalog = current_database() AND table_schema = 'public'`)
+		c.completions.columns = c.completionQuery(
+			`SELECT DISTINCT column_name FROM information_schema.columns
+			  WHERE table_catalog = current_database() AND table_schema = 'public'`)
+		c.completions.valid = true
+	}
+	return c.completions.tables, c.completions.columns
+}
+
+func (c *cliState) completionQuery(query string) []string {
+	_, rows, err := runQuery(c.conn, makeQuery(query), false /* showMoreChars */)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row[0])
+	}
+	return names
+}
+
+// completeInput returns the candidate completions of word, the last (possibly
+// empty) word of the input entered so far. The input includes the previous
+// lines of a multi-line statement. Table and column names are only requested
+// from the names function when they are relevant in the completion context.
+func completeInput(
+	input string, word string, names func() (tables, columns []string),
+) []string {
+	before := strings.TrimSuffix(input, word)
+	fields := strings.FieldsFunc(before, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '(' || r == ')' || r == ';'
+	})
+
+	var candidates []string
+	switch {
+	case len(fields) == 0 && strings.HasPrefix(word, `\`):
+		// A client-side command.
+		candidates = cliCommandNames
+
+	case len(fields) == 0:
+		// The first word of a statement is always a keyword.
+		candidates = keywordCandidates(word)
+
+	default:
+		tables, columns := names()
+		if _, ok := tableCompletionContext[strings.ToUpper(fields[len(fields)-1])]; ok {
+			candidates = tables
+		} else {
+			candidates = append(append(keywordCandidates(word), columns...), tables...)
+		}
+	}
+
+	lowerWord := strings.ToLower(word)
+	seen := make(map[string]struct{})
+	var res []string
+	for _, cand := range candidates {
+		if !strings.HasPrefix(strings.ToLower(cand), lowerWord) {
+			continue
+		}
+		if _, ok := seen[cand]; ok {
+			continue
+		}
+		seen[cand] = struct{}{}
+		res = append(res, cand)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// keywordCandidates returns the SQL keywords, in lowercase if the word being
+// completed is in lowercase and in uppercase otherwise.
+func keywordCandidates(word string) []string {
+	lower := word != "" && strings.ToLower(word) == word
+	res := make([]string, len(lex.KeywordNames))
+	for i, kw := range lex.KeywordNames {
+		if lower {
+			res[i] = kw
+		} else {
+			res[i] = strings.ToUpper(kw)
+		}
+	}
+	return res
+}
+
+// searchHistory prints the entries of the history file that contain pattern,
+// or all entries if pattern is empty, oldest first.
+func searchHistory(w io.Writer, histFile string, pattern string) error {
+	f, err := os.Open(histFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pattern = strings.ToLower(pattern)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == libeditHistoryHeader {
+			continue
+		}
+		entry := decodeHistoryEntry(line)
+		if strings.Contains(strings.ToLower(entry), pattern) {
+			if _, err := io.WriteString(w, entry+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// libeditHistoryHeader is the first line of the history files written by
+// libedit.
+const libeditHistoryHeader = "_HiStOrY_V2_"
+
+// decodeHistoryEntry decodes an entry of a libedit history file. libedit
+// encodes whitespace, non-printable characters and backslashes in history
+// entries using octal escapes (\NNN) and \\, in the style of vis(3).
+func decodeHistoryEntry(line string) string {
+	if !strings.Contains(line, `\`) {
+		return line
+	}
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == '\\' {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		if line[i] == '\\' && i+3 < len(line) &&
+			isOctal(line[i+1]) && isOctal(line[i+2]) && isOctal(line[i+3]) {
+			b.WriteByte((line[i+1]-'0')<<6 | (line[i+2]-'0')<<3 | (line[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(line[i])
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -230,6 +231,81 @@ func TestHandleCliCmdSlashDInvalidSyntax(t *testing.T) {
 	}
 }
 
+func TestHandleCliCmdReset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := setupTestCliState()
+	c.partialLines = []string{"SELECT", "1"}
+	c.lastInputLine = `\r`
+	assert.Equal(t, cliStartLine, c.doHandleCliCmd(cliStateEnum(0), cliStateEnum(1)))
+	assert.Equal(t, cliStartLine, c.doStartLine(cliStartLine))
+	assert.Empty(t, c.partialLines)
+}
+
+func TestCompleteInput(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	namesFetched := false
+	names := func() ([]string, []string) {
+		namesFetched = true
+		return []string{"orders", "users"}, []string{"id", "user_id", "order_date"}
+	}
+
+	testCases := []struct {
+		input, word string
+		expected    []string
+		fetchNames  bool
+	}{
+		{`\s`, `\s`, []string{`\s`, `\set`, `\show`}, false},
+		{`SEL`, `SEL`, []string{`SELECT`}, false},
+		{`sel`, `sel`, []string{`select`}, false},
+		{`SELECT * FROM `, ``, []string{`orders`, `users`}, true},
+		{`SELECT * FROM u`, `u`, []string{`users`}, true},
+		{"SELECT *\nFROM o", `o`, []string{`orders`}, true},
+		{`SELECT * FROM users JOIN o`, `o`, []string{`orders`}, true},
+		{`SELECT user_`, `user_`, []string{`user_id`}, true},
+		{`SELECT * FROM users WHERE or`, `or`, []string{`or`, `order`, `order_date`, `orders`, `ordinality`}, true},
+		{`\d u`, `u`, []string{`users`}, true},
+	}
+	for _, tc := range testCases {
+		namesFetched = false
+		assert.Equal(t, tc.expected, completeInput(tc.input, tc.word, names), tc.input)
+		assert.Equal(t, tc.fetchNames, namesFetched, tc.input)
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	f, err := ioutil.TempFile("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	_, err = f.WriteString(libeditHistoryHeader + "\n" +
+		`SELECT\0401;` + "\n" +
+		`SELECT\040'a\\b'\012FROM\040t;` + "\n" +
+		`\\dt` + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := searchHistory(&buf, f.Name(), ""); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "SELECT 1;\nSELECT 'a\\b'\nFROM t;\n\\dt\n", buf.String())
+
+	buf.Reset()
+	if err := searchHistory(&buf, f.Name(), "from"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "SELECT 'a\\b'\nFROM t;\n", buf.String())
+}
+
 func setupTestCliState() cliState {
 	c := cliState{}
 	c.ins = noLineEditor
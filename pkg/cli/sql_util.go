@@ -409,6 +409,7 @@ func (c *sqlConn) Close() {
 
 type sqlRowsI interface {
 	driver.RowsColumnTypeScanType
+	driver.RowsColumnTypeDatabaseTypeName
 	Result() driver.Result
 	Tag() string
 
@@ -474,6 +475,10 @@ func (r *sqlRows) ColumnTypeScanType(index int) reflect.Type {
 	return r.rows.ColumnTypeScanType(index)
 }
 
+func (r *sqlRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.rows.ColumnTypeDatabaseTypeName(index)
+}
+
 func makeSQLConn(url string) *sqlConn {
 	return &sqlConn{
 		url: url,
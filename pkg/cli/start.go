@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -93,6 +94,11 @@ Start a CockroachDB node, which will export data from one or more
 storage devices, specified via --store flags.
 The cluster will also be automatically initialized with
 replication disabled (replication factor = 1).
+
+This is suitable for local development and small deployments that do
+not need fault tolerance. The node does not try to contact other nodes
+and cannot be joined by other nodes; use 'cockroach start' to run a
+multi-node cluster instead.
 `,
 	Example: `  cockroach start-single-node --insecure --store=attrs=ssd,path=/mnt/ssd1`,
 	Args:    cobra.NoArgs,
@@ -271,28 +277,20 @@ func initCPUProfile(ctx context.Context, dir string) {
 
 func initBlockProfile() {
 	// Enable the block profile for a sample of mutex and channel operations.
-	// Smaller values provide more accurate profiles but are more
-	// expensive. 0 and 1 are special: 0 disables the block profile and
-	// 1 captures 100% of block events. For other values, the profiler
-	// will sample one event per X nanoseconds spent blocking.
+	// The rate can be overridden with the server.debug.block_profile_rate
+	// cluster setting.
 	//
 	// The block profile can be viewed with `pprof http://HOST:PORT/debug/pprof/block`
-	d := envutil.EnvOrDefaultInt64("COCKROACH_BLOCK_PROFILE_RATE",
-		10000000 /* 1 sample per 10 milliseconds spent blocking */)
-	runtime.SetBlockProfileRate(int(d))
+	runtime.SetBlockProfileRate(debug.DefaultBlockProfileRate())
 }
 
 func initMutexProfile() {
-	// Enable the mutex profile for a fraction of mutex contention events.
-	// Smaller values provide more accurate profiles but are more expensive. 0
-	// and 1 are special: 0 disables the mutex profile and 1 captures 100% of
-	// mutex contention events. For other values, the profiler will sample on
-	// average 1/X events.
+	// Enable the mutex profile for a fraction of mutex contention events. The
+	// fraction can be overridden with the server.debug.mutex_profile_fraction
+	// cluster setting.
 	//
 	// The mutex profile can be viewed with `pprof http://HOST:PORT/debug/pprof/mutex`
-	d := envutil.EnvOrDefaultInt("COCKROACH_MUTEX_PROFILE_RATE",
-		1000 /* 1 sample per 1000 mutex contention events */)
-	runtime.SetMutexProfileFraction(d)
+	runtime.SetMutexProfileFraction(debug.DefaultMutexProfileFraction())
 }
 
 var cacheSizeValue = newBytesOrPercentageValue(&serverCfg.CacheSize, memoryPercentResolver)
@@ -313,6 +311,38 @@ func initExternalIODir(ctx context.Context, firstStore base.StoreSpec) (string,
 	return externalIODir, nil
 }
 
+// checkDisksAndEstablishBallasts returns an error if the disk of any of the
+// given stores is full. Otherwise, it creates or resizes the emergency
+// ballast of every store, so that the space it reserves can be reclaimed
+// when the disk fills up.
+func checkDisksAndEstablishBallasts(ctx context.Context, specs []base.StoreSpec) error {
+	for _, spec := range specs {
+		if spec.InMemory {
+			continue
+		}
+		full, err := engine.IsDiskFull(spec)
+		if err != nil {
+			log.Warningf(ctx, "unable to check free space of store %s: %v", spec.Path, err)
+			continue
+		}
+		if full {
+			return withExitCode(exitDiskFull, errors.Errorf(
+				"the disk of store %s is full.\n\n"+
+					"To free up space and allow the node to start, remove the emergency ballast\n"+
+					"file %s; it is re-created automatically once enough space is available.\n"+
+					"Then free up space on the disk, for example by dropping or truncating\n"+
+					"tables, or by adding capacity to the cluster.",
+				spec.Path, engine.EmergencyBallastFile(spec.Path)))
+		}
+		if resized, err := engine.MaybeEstablishBallast(spec); err != nil {
+			log.Warningf(ctx, "unable to establish emergency ballast of store %s: %v", spec.Path, err)
+		} else if resized {
+			log.Infof(ctx, "established emergency ballast %s", engine.EmergencyBallastFile(spec.Path))
+		}
+	}
+	return nil
+}
+
 func initTempStorageConfig(
 	ctx context.Context,
 	st *cluster.Settings,
@@ -406,7 +436,7 @@ func initTempStorageConfig(
 	return tempStorageConfig, nil
 }
 
-var errCannotUseJoin = errors.New("cannot use --join with 'cockroach start-single-node' -- use 'cockrach start' instead")
+var errCannotUseJoin = errors.New("cannot use --join with 'cockroach start-single-node' -- use 'cockroach start' instead")
 
 func runStartSingleNode(cmd *cobra.Command, args []string) error {
 	joinFlag := cmd.Flags().Lookup(cliflags.Join.Name)
@@ -416,18 +446,19 @@ func runStartSingleNode(cmd *cobra.Command, args []string) error {
 	// Now actually set the flag as changed so that the start code
 	// doesn't warn that it was not set.
 	joinFlag.Changed = true
-	return runStart(cmd, args)
+	return runStart(cmd, args, true /* startSingleNode */)
 }
 
 func runStartJoin(cmd *cobra.Command, args []string) error {
-	return runStart(cmd, args)
+	return runStart(cmd, args, false /* startSingleNode */)
 }
 
 // runStart starts the cockroach node using --store as the list of
 // storage devices ("stores") on this machine and --join as the list
 // of other active nodes used to join this node to the cockroach
-// cluster, if this is its first time connecting.
-func runStart(cmd *cobra.Command, args []string) error {
+// cluster, if this is its first time connecting. startSingleNode is set
+// for 'cockroach start-single-node'.
+func runStart(cmd *cobra.Command, args []string, startSingleNode bool) error {
 	tBegin := timeutil.Now()
 
 	// First things first: if the user wants background processing,
@@ -523,6 +554,13 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if serverCfg.TempStorageConfig, err = initTempStorageConfig(ctx, serverCfg.Settings, stopper, useStore, specIdx); err != nil {
 		return err
 	}
+	// Refuse to start on a full disk, and otherwise make sure that the
+	// emergency ballasts are in place. This happens after the abandoned
+	// temporary directories were cleaned up above, since that may have freed
+	// up enough space.
+	if err := checkDisksAndEstablishBallasts(ctx, serverCfg.Stores.Specs); err != nil {
+		return err
+	}
 
 	// Initialize the node's configuration from startup parameters.
 	// This also reads the part of the configuration that comes from
@@ -606,8 +644,12 @@ func runStart(cmd *cobra.Command, args []string) error {
 	}
 
 	// DelayedBoostrapFn will be called if the boostrap process is
-	// taking a bit long.
+	// taking a bit long. A single-node cluster has no other nodes to
+	// contact, so the advice below does not apply to it.
 	serverCfg.DelayedBootstrapFn = func() {
+		if startSingleNode {
+			return
+		}
 		msg := `The server appears to be unable to contact the other nodes in the cluster. Please try:
 
 - starting the other nodes, if you haven't already;
@@ -1247,7 +1289,11 @@ var quitCmd = &cobra.Command{
 	Long: `
 Shutdown the server. The first stage is drain, where any new requests
 will be ignored by the server. When all extant requests have been
-completed, the server exits.
+completed, the server exits. If the drain does not complete within
+--drain-timeout, the server is shut down without draining.
+
+To sequence the shutdown with a load balancer, drain the node with
+cockroach node drain first.
 `,
 	Args: cobra.NoArgs,
 	RunE: MaybeDecorateGRPCError(runQuit),
@@ -1352,7 +1398,9 @@ func runQuit(cmd *cobra.Command, args []string) (err error) {
 
 	if quitCtx.serverDecommission {
 		var myself []string // will remain empty, which means target yourself
-		if err := runDecommissionNodeImpl(ctx, c, nodeDecommissionWaitAll, myself); err != nil {
+		if err := runDecommissionNodeImpl(
+			ctx, c, nodeDecommissionWaitAll, 0 /* stallTimeout */, myself,
+		); err != nil {
 			return err
 		}
 	}
@@ -1370,8 +1418,9 @@ func runQuit(cmd *cobra.Command, args []string) (err error) {
 			return err
 		}
 		return nil
-	case <-time.After(time.Minute):
-		log.Warningf(ctx, "timed out; proceeding with hard shutdown")
+	case <-time.After(quitCtx.drainTimeout):
+		log.Warningf(ctx, "drain did not complete within %s; proceeding with hard shutdown",
+			quitCtx.drainTimeout)
 	}
 	// Not passing drain modes tells the server to not bother and go
 	// straight to shutdown. We try two times just in case there is a transient error.
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -73,6 +74,24 @@ func TestInitInsecure(t *testing.T) {
 	}
 }
 
+func TestStartSingleNodeRejectsJoin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Avoid leaking configuration changes after the tests end.
+	defer initCLIDefaults()
+
+	f := startSingleNodeCmd.Flags()
+	joinFlag := f.Lookup(cliflags.Join.Name)
+	defer func() { joinFlag.Changed = false }()
+
+	if err := f.Parse([]string{"--join", "localhost:26257"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runStartSingleNode(startSingleNodeCmd, nil); err != errCannotUseJoin {
+		t.Fatalf("expected %v, got %v", errCannotUseJoin, err)
+	}
+}
+
 func TestStartArgChecking(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
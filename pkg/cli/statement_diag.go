@@ -0,0 +1,195 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var stmtDiagListCmd = &cobra.Command{
+	Use:   "list [options]",
+	Short: "list statement diagnostics requests and bundles",
+	Long: `
+List the statement diagnostics requests of the node the command connects to,
+including the completed ones whose bundle can be downloaded.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runStmtDiagList),
+}
+
+func runStmtDiagList(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach statement-diag")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return runQueryAndFormatResults(conn, os.Stdout, makeQuery(`
+SELECT id, statement_fingerprint, requested_at, min_execution_latency, expires_at,
+       completed, collected_at, statement
+  FROM crdb_internal.node_statement_diagnostics
+ ORDER BY id`))
+}
+
+var stmtDiagActivateCmd = &cobra.Command{
+	Use:   "activate [options] <statement fingerprint>",
+	Short: "request diagnostics for a statement fingerprint",
+	Long: `
+Request the collection of diagnostics for the next execution of a statement
+with the given fingerprint on the node the command connects to. Fingerprints
+are statements with their constants replaced by underscores, as shown in the
+Statements page of the admin UI and in crdb_internal.node_statement_statistics.
+
+With --min-latency, the diagnostics are only collected for an execution which
+is at least that slow, so that the bundle is representative of the slow
+executions of the statement.
+`,
+	Example: `  cockroach statement-diag activate 'SELECT * FROM t WHERE k = _'
+  cockroach statement-diag activate --min-latency=500ms --expires-after=1h 'SELECT * FROM t WHERE k = _'`,
+	Args: cobra.ExactArgs(1),
+	RunE: MaybeDecorateGRPCError(runStmtDiagActivate),
+}
+
+func runStmtDiagActivate(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach statement-diag")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	vals, err := conn.QueryRow(
+		`SELECT crdb_internal.request_statement_diagnostics($1, $2::INTERVAL, $3::INTERVAL, $4)`,
+		[]driver.Value{
+			args[0],
+			intervalString(stmtDiagCtx.minLatency),
+			intervalString(stmtDiagCtx.expiresAfter),
+			stmtDiagCtx.samplingProbability,
+		})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("statement diagnostics requested; request ID: %d\n", vals[0])
+	return nil
+}
+
+// intervalString formats a duration as a string which can be cast to an
+// INTERVAL.
+func intervalString(d time.Duration) string {
+	return fmt.Sprintf("%dus", d/time.Microsecond)
+}
+
+var stmtDiagDownloadCmd = &cobra.Command{
+	Use:   "download [options] <request ID> [<file>]",
+	Short: "download a statement diagnostics bundle",
+	Long: `
+Download the bundle collected for a statement diagnostics request to the
+given file, stmt-bundle-<request ID>.zip by default. The bundle is a zip
+archive with the statement, its plan and the trace of its execution.
+`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: MaybeDecorateGRPCError(runStmtDiagDownload),
+}
+
+func runStmtDiagDownload(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid request ID")
+	}
+	filename := fmt.Sprintf("stmt-bundle-%d.zip", id)
+	if len(args) > 1 {
+		filename = args[1]
+	}
+
+	conn, err := getPasswordAndMakeSQLClient("cockroach statement-diag")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	vals, err := conn.QueryRow(
+		`SELECT bundle FROM crdb_internal.node_statement_diagnostics WHERE id = $1`,
+		[]driver.Value{id})
+	if err == io.EOF {
+		return errors.Errorf("no statement diagnostics request with ID %d", id)
+	} else if err != nil {
+		return err
+	}
+	bundle, ok := vals[0].([]byte)
+	if !ok {
+		return errors.Errorf("the bundle of statement diagnostics request %d is not collected yet", id)
+	}
+	if err := ioutil.WriteFile(filename, bundle, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("downloaded statement diagnostics bundle to %s\n", filename)
+	return nil
+}
+
+var stmtDiagCancelCmd = &cobra.Command{
+	Use:   "cancel [options] <request ID>",
+	Short: "cancel a pending statement diagnostics request",
+	Args:  cobra.ExactArgs(1),
+	RunE:  MaybeDecorateGRPCError(runStmtDiagCancel),
+}
+
+func runStmtDiagCancel(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid request ID")
+	}
+
+	conn, err := getPasswordAndMakeSQLClient("cockroach statement-diag")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	vals, err := conn.QueryRow(
+		`SELECT crdb_internal.cancel_statement_diagnostics_request($1)`, []driver.Value{id})
+	if err != nil {
+		return err
+	}
+	if canceled, _ := vals[0].(bool); !canceled {
+		return errors.Errorf("no pending statement diagnostics request with ID %d", id)
+	}
+	fmt.Printf("canceled statement diagnostics request %d\n", id)
+	return nil
+}
+
+var stmtDiagCmds = []*cobra.Command{
+	stmtDiagListCmd,
+	stmtDiagActivateCmd,
+	stmtDiagDownloadCmd,
+	stmtDiagCancelCmd,
+}
+
+var stmtDiagCmd = &cobra.Command{
+	Use:   "statement-diag [command]",
+	Short: "list, request, download and cancel statement diagnostics bundles",
+	Long: `
+Manage the statement diagnostics bundles of the node the command connects to.
+Diagnostics are collected for the statements executed through that node.
+`,
+	RunE: usageAndErr,
+}
+
+func init() {
+	stmtDiagCmd.AddCommand(stmtDiagCmds...)
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestStatementDiag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := c.RunWithCaptureArgs(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	expect := func(out, expected string) {
+		t.Helper()
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+
+	expect(run("statement-diag", "activate", "SELECT _ + _"), "request ID: 1")
+	expect(run("statement-diag", "download", "1", filepath.Join(dir, "pending.zip")),
+		"is not collected yet")
+
+	run("sql", "-e", "SELECT 1 + 2")
+	expect(run("statement-diag", "list", "--format=csv"), "1,SELECT _ + _,")
+
+	bundlePath := filepath.Join(dir, "bundle.zip")
+	expect(run("statement-diag", "download", "1", bundlePath), "downloaded")
+	z, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+	var files []string
+	for _, f := range z.File {
+		files = append(files, f.Name)
+	}
+	if e, a := "statement.txt plan.txt trace.txt trace.json env.txt", strings.Join(files, " "); e != a {
+		t.Fatalf("expected bundle files %q, got %q", e, a)
+	}
+
+	expect(run("statement-diag", "activate", "SELECT _ - _"), "request ID: 2")
+	expect(run("statement-diag", "cancel", "2"), "canceled statement diagnostics request 2")
+	expect(run("statement-diag", "cancel", "2"), "no pending statement diagnostics request with ID 2")
+	expect(run("statement-diag", "download", "3"), "no statement diagnostics request with ID 3")
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+)
+
+// tsWriter emits the time series returned by the Dump RPC. The series
+// are streamed in key order: all the data of a metric name is received
+// before the data of the next one, but the sources of a name may be
+// interleaved.
+type tsWriter interface {
+	Emit(*tspb.TimeSeriesData) error
+	Flush() error
+}
+
+// makeTSWriter returns the tsWriter for the given format.
+func makeTSWriter(w io.Writer, format tsDumpFormat) tsWriter {
+	switch format {
+	case tsDumpCSV:
+		return &csvTSWriter{w: csv.NewWriter(w)}
+	case tsDumpOpenMetrics:
+		return &openMetricsTSWriter{w: bufio.NewWriter(w)}
+	default:
+		return &rawTSWriter{w: bufio.NewWriter(w)}
+	}
+}
+
+// tsDumpFilter restricts the data emitted by tsdump to the given metric
+// names and time range. Empty names and zero times are not restrictive.
+type tsDumpFilter struct {
+	names    map[string]struct{}
+	from, to time.Time
+}
+
+func makeTSDumpFilter(names []string, from, to time.Time) tsDumpFilter {
+	f := tsDumpFilter{from: from, to: to}
+	if len(names) > 0 {
+		f.names = make(map[string]struct{}, len(names))
+		for _, n := range names {
+			f.names[n] = struct{}{}
+		}
+	}
+	return f
+}
+
+// apply returns the series restricted to the datapoints that pass the
+// filter, or nil if there are none left.
+func (f tsDumpFilter) apply(data *tspb.TimeSeriesData) *tspb.TimeSeriesData {
+	if f.names != nil {
+		if _, ok := f.names[data.Name]; !ok {
+			return nil
+		}
+	}
+	if f.from.IsZero() && f.to.IsZero() {
+		return data
+	}
+	filtered := *data
+	filtered.Datapoints = nil
+	for _, d := range data.Datapoints {
+		if !f.from.IsZero() && d.TimestampNanos < f.from.UnixNano() {
+			continue
+		}
+		if !f.to.IsZero() && d.TimestampNanos >= f.to.UnixNano() {
+			continue
+		}
+		filtered.Datapoints = append(filtered.Datapoints, d)
+	}
+	if len(filtered.Datapoints) == 0 {
+		return nil
+	}
+	return &filtered
+}
+
+// rawTSWriter prints a line with the name and source of every series,
+// followed by one line per datapoint.
+type rawTSWriter struct {
+	w            *bufio.Writer
+	name, source string
+}
+
+func (r *rawTSWriter) Emit(data *tspb.TimeSeriesData) error {
+	if r.name != data.Name || r.source != data.Source {
+		r.name, r.source = data.Name, data.Source
+		fmt.Fprintf(r.w, "%s %s\n", data.Name, data.Source)
+	}
+	for _, d := range data.Datapoints {
+		fmt.Fprintf(r.w, "%d %v\n", d.TimestampNanos, d.Value)
+	}
+	return nil
+}
+
+func (r *rawTSWriter) Flush() error {
+	return r.w.Flush()
+}
+
+// csvTSWriter prints one record per datapoint, with the name, source,
+// timestamp and value of the datapoint.
+type csvTSWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvTSWriter) Emit(data *tspb.TimeSeriesData) error {
+	if !c.headerWritten {
+		c.headerWritten = true
+		if err := c.w.Write([]string{"name", "source", "timestamp", "value"}); err != nil {
+			return err
+		}
+	}
+	for _, d := range data.Datapoints {
+		if err := c.w.Write([]string{
+			data.Name,
+			data.Source,
+			formatTSNanos(d.TimestampNanos),
+			strconv.FormatFloat(d.Value, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvTSWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// openMetricsNameReplaceRE matches the characters that are not allowed in
+// OpenMetrics metric names.
+var openMetricsNameReplaceRE = regexp.MustCompile("^[^a-zA-Z_:]|[^a-zA-Z0-9_:]")
+
+// openMetricsTSWriter prints the datapoints in the OpenMetrics text
+// exposition format, with the source as the "source" label. Timestamps
+// are expressed in seconds.
+type openMetricsTSWriter struct {
+	w    *bufio.Writer
+	name string
+}
+
+func (o *openMetricsTSWriter) Emit(data *tspb.TimeSeriesData) error {
+	name := openMetricsNameReplaceRE.ReplaceAllString(data.Name, "_")
+	if name != o.name {
+		o.name = name
+		fmt.Fprintf(o.w, "# TYPE %s unknown\n", name)
+	}
+	for _, d := range data.Datapoints {
+		fmt.Fprintf(o.w, "%s{source=%q} %s %s\n", name, data.Source,
+			strconv.FormatFloat(d.Value, 'g', -1, 64),
+			strconv.FormatFloat(float64(d.TimestampNanos)/float64(time.Second), 'f', 3, 64))
+	}
+	return nil
+}
+
+func (o *openMetricsTSWriter) Flush() error {
+	fmt.Fprintln(o.w, "# EOF")
+	return o.w.Flush()
+}
+
+// formatTSNanos formats a timestamp in nanoseconds since the epoch
+// in RFC 3339 format, in UTC.
+func formatTSNanos(nanos int64) string {
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestTSDumpWriters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sec := int64(time.Second)
+	series := []*tspb.TimeSeriesData{
+		{Name: "cr.node.sql.conns", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 10 * sec, Value: 1},
+			{TimestampNanos: 20 * sec, Value: 2.5},
+		}},
+		{Name: "cr.node.sql.conns", Source: "2", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 10 * sec, Value: 3},
+		}},
+		{Name: "cr.store.ranges", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 30 * sec, Value: 20},
+		}},
+	}
+
+	testCases := []struct {
+		format   tsDumpFormat
+		names    []string
+		from, to time.Time
+		expected string
+	}{
+		{tsDumpRaw, nil, time.Time{}, time.Time{}, `cr.node.sql.conns 1
+10000000000 1
+20000000000 2.5
+cr.node.sql.conns 2
+10000000000 3
+cr.store.ranges 1
+30000000000 20
+`},
+		{tsDumpCSV, nil, time.Time{}, time.Time{}, `name,source,timestamp,value
+cr.node.sql.conns,1,1970-01-01T00:00:10Z,1
+cr.node.sql.conns,1,1970-01-01T00:00:20Z,2.5
+cr.node.sql.conns,2,1970-01-01T00:00:10Z,3
+cr.store.ranges,1,1970-01-01T00:00:30Z,20
+`},
+		{tsDumpOpenMetrics, nil, time.Time{}, time.Time{}, `# TYPE cr_node_sql_conns unknown
+cr_node_sql_conns{source="1"} 1 10.000
+cr_node_sql_conns{source="1"} 2.5 20.000
+cr_node_sql_conns{source="2"} 3 10.000
+# TYPE cr_store_ranges unknown
+cr_store_ranges{source="1"} 20 30.000
+# EOF
+`},
+		{tsDumpCSV, []string{"cr.store.ranges"}, time.Time{}, time.Time{}, `name,source,timestamp,value
+cr.store.ranges,1,1970-01-01T00:00:30Z,20
+`},
+		{tsDumpCSV, nil, time.Unix(20, 0), time.Unix(30, 0), `name,source,timestamp,value
+cr.node.sql.conns,1,1970-01-01T00:00:20Z,2.5
+`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			filter := makeTSDumpFilter(tc.names, tc.from, tc.to)
+			w := makeTSWriter(&buf, tc.format)
+			for _, data := range series {
+				if data = filter.apply(data); data == nil {
+					continue
+				}
+				if err := w.Emit(data); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+}
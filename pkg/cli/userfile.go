@@ -0,0 +1,314 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/filetable"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// userFileScheme is the scheme of the URIs of files stored with the userfile
+// commands.
+const userFileScheme = "userfile"
+
+// defaultUserFileTablePrefix is the prefix of the tables holding the files of
+// a user when no table is given explicitly.
+const defaultUserFileTablePrefix = "userfiles_"
+
+var userFileUploadCmd = &cobra.Command{
+	Use:   "upload <source> <destination>",
+	Short: "upload file from source to destination",
+	Long: `
+Upload a file to the user file storage of the cluster, so that it can be
+staged in the database without access to a cloud storage bucket. The
+destination is either a file name, in which case the file is stored in the
+tables defaultdb.public.userfiles_<user>_upload_files and
+defaultdb.public.userfiles_<user>_upload_payload, or a URI of the form
+userfile://<database>.<schema>.<prefix>/<file name> to use the tables
+<prefix>_upload_files and <prefix>_upload_payload instead. The tables are
+created if needed. The destination must not exist yet.
+`,
+	Example: `  cockroach userfile upload ./data/customers.csv customers.csv
+  cockroach userfile upload ./data/customers.csv userfile://db.public.staging/customers.csv`,
+	Args: cobra.ExactArgs(2),
+	RunE: MaybeDecorateGRPCError(runUserFileUpload),
+}
+
+var userFileListCmd = &cobra.Command{
+	Use:   "list [<pattern>]",
+	Short: "list files matching the given pattern",
+	Long: `
+List the files in the user file storage whose name matches the given pattern.
+The pattern is a file name or userfile:// URI which may contain the wildcards
+* and ? and character ranges, as in shell globs. Without a pattern, all the
+files in the default tables of the user are listed.
+`,
+	Example: `  cockroach userfile list '*.csv'`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    MaybeDecorateGRPCError(runUserFileList),
+}
+
+var userFileGetCmd = &cobra.Command{
+	Use:   "get <file> [<destination>]",
+	Short: "download a file from the user file storage",
+	Long: `
+Download a file from the user file storage. The file is written to the given
+local destination, or to the standard output if the destination is omitted
+or "-".
+`,
+	Example: `  cockroach userfile get customers.csv ./customers.csv`,
+	Args:    cobra.RangeArgs(1, 2),
+	RunE:    MaybeDecorateGRPCError(runUserFileGet),
+}
+
+var userFileDeleteCmd = &cobra.Command{
+	Use:   "delete <file>",
+	Short: "delete a file from the user file storage",
+	Args:  cobra.ExactArgs(1),
+	RunE:  MaybeDecorateGRPCError(runUserFileDelete),
+}
+
+func runUserFileUpload(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach userfile")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	source, destination := args[0], args[1]
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	uri, err := uploadUserFile(context.Background(), conn, f, destination)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("successfully uploaded to %s\n", uri)
+	return nil
+}
+
+func runUserFileList(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach userfile")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var pattern string
+	if len(args) > 0 {
+		pattern = args[0]
+	}
+	ctx := context.Background()
+	fs, pattern, err := openUserFileStorage(ctx, conn, pattern)
+	if err != nil {
+		return err
+	}
+	files, err := fs.ListFiles(ctx, pattern)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(files))
+	for i, f := range files {
+		rows[i] = []string{
+			f.Name,
+			humanizeutil.IBytes(f.Size),
+			f.UploadTime.Format("2006-01-02 15:04:05"),
+		}
+	}
+	return printQueryOutput(os.Stdout, []string{"file", "size", "uploaded"},
+		newRowSliceIter(rows, "lrl"))
+}
+
+func runUserFileGet(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach userfile")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	fs, name, err := openUserFileStorage(ctx, conn, args[0])
+	if err != nil {
+		return err
+	}
+	r, err := fs.ReadFile(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var w io.Writer = os.Stdout
+	if len(args) == 2 && args[1] != "-" {
+		// Only create the destination once the file is known to exist.
+		f, err := os.OpenFile(args[1], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func runUserFileDelete(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach userfile")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	fs, name, err := openUserFileStorage(ctx, conn, args[0])
+	if err != nil {
+		return err
+	}
+	if err := fs.DeleteFile(ctx, name); err != nil {
+		return err
+	}
+	fmt.Printf("successfully deleted %s\n", args[0])
+	return nil
+}
+
+// uploadUserFile stores the contents of reader in the user file storage at
+// the given destination and returns the URI of the file.
+func uploadUserFile(
+	ctx context.Context, conn *sqlConn, reader io.Reader, destination string,
+) (string, error) {
+	fs, name, err := openUserFileStorage(ctx, conn, destination)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fs.WriteFile(ctx, name, reader); err != nil {
+		return "", err
+	}
+	return fs.uri + name, nil
+}
+
+// userFileStorage is the user file storage designated by a userfile:// URI.
+type userFileStorage struct {
+	*filetable.FileToTableSystem
+	// uri is the URI of the storage, to which file names are appended.
+	uri string
+}
+
+// openUserFileStorage opens the user file storage designated by the given
+// file name or userfile:// URI, and returns it along with the name of the
+// file in it.
+func openUserFileStorage(
+	ctx context.Context, conn *sqlConn, file string,
+) (*userFileStorage, string, error) {
+	vals, err := conn.QueryRow(`SELECT current_user()`, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	username := formatVal(vals[0], false /* showPrintableUnicode */, false /* showNewLinesAndTabs */)
+
+	database, schema, prefix := "defaultdb", "public", defaultUserFileTablePrefix+username
+	if strings.HasPrefix(file, userFileScheme+"://") {
+		u, err := url.Parse(file)
+		if err != nil {
+			return nil, "", err
+		}
+		if u.Host != "" {
+			parts := strings.Split(u.Host, ".")
+			if len(parts) != 3 {
+				return nil, "", errors.Errorf(
+					"invalid table prefix %q in %s: expected <database>.<schema>.<prefix>", u.Host, file)
+			}
+			database, schema, prefix = parts[0], parts[1], parts[2]
+		}
+		file = strings.TrimPrefix(u.Path, "/")
+	}
+
+	fs, err := filetable.NewFileToTableSystem(
+		ctx, userFileExecutor{conn}, database, schema, prefix, username)
+	if err != nil {
+		return nil, "", err
+	}
+	uri := fmt.Sprintf("%s://%s.%s.%s/", userFileScheme, database, schema, prefix)
+	return &userFileStorage{FileToTableSystem: fs, uri: uri}, file, nil
+}
+
+// userFileExecutor implements filetable.SQLExecutor on top of a sqlConn.
+type userFileExecutor struct {
+	conn *sqlConn
+}
+
+var _ filetable.SQLExecutor = userFileExecutor{}
+
+func toDriverValues(args []interface{}) []driver.Value {
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a
+	}
+	return vals
+}
+
+// Exec implements filetable.SQLExecutor.
+func (e userFileExecutor) Exec(ctx context.Context, query string, args ...interface{}) error {
+	return e.conn.Exec(query, toDriverValues(args))
+}
+
+// QueryRows implements filetable.SQLExecutor.
+func (e userFileExecutor) QueryRows(
+	ctx context.Context, query string, args ...interface{},
+) ([][]interface{}, error) {
+	rows, err := e.conn.Query(query, toDriverValues(args))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var result [][]interface{}
+	for {
+		vals := make([]driver.Value, len(rows.Columns()))
+		if err := rows.Next(vals); err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(vals))
+		for i, v := range vals {
+			row[i] = v
+		}
+		result = append(result, row)
+	}
+}
+
+var userFileCmds = []*cobra.Command{
+	userFileUploadCmd,
+	userFileListCmd,
+	userFileGetCmd,
+	userFileDeleteCmd,
+}
+
+var userFileCmd = &cobra.Command{
+	Use:   "userfile [command]",
+	Short: "upload, list and manage files stored in the database",
+	RunE:  usageAndErr,
+}
+
+func init() {
+	userFileCmd.AddCommand(userFileCmds...)
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/filetable"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestUserFileUpload(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	conn := makeSQLConn(pgURL.String())
+	defer conn.Close()
+
+	// The contents span several chunks.
+	contents := bytes.Repeat([]byte("a,b\x00\xff\n"), filetable.ChunkSize/3)
+
+	for _, tc := range []struct {
+		destination, uri string
+	}{
+		{"test.csv", "userfile://defaultdb.public.userfiles_root/test.csv"},
+		{"userfile://defaultdb.public.staging/sub/test.csv",
+			"userfile://defaultdb.public.staging/sub/test.csv"},
+	} {
+		uri, err := uploadUserFile(ctx, conn, bytes.NewReader(contents), tc.destination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uri != tc.uri {
+			t.Fatalf("expected URI %s, got %s", tc.uri, uri)
+		}
+
+		fs, name, err := openUserFileStorage(ctx, conn, tc.destination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := fs.ReadFile(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uploaded, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(uploaded, contents) {
+			t.Fatalf("uploaded file differs from source (%d bytes, expected %d)",
+				len(uploaded), len(contents))
+		}
+
+		// Existing files are not overwritten.
+		if _, err := uploadUserFile(
+			ctx, conn, bytes.NewReader(contents), tc.destination,
+		); !testutils.IsError(err, "already exists") {
+			t.Fatalf("expected error, got %v", err)
+		}
+	}
+
+	if _, _, err := openUserFileStorage(
+		ctx, conn, "userfile://staging/test.csv",
+	); !testutils.IsError(err, "invalid table prefix") {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
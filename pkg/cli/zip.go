@@ -45,6 +45,9 @@ files, and SQL schema.
 Retrieval of per-node details (status, stack traces, range status, engine stats)
 requires the node to be live and operating properly. Retrieval of SQL data
 requires the cluster to be live.
+
+With --redact, keys, SQL constants and other user data are replaced by salted
+hashes in the collected logs, system tables and range data.
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: MaybeDecorateGRPCError(runDebugZip),
@@ -64,6 +67,25 @@ var debugZipTablesPerCluster = []string{
 	"crdb_internal.schema_changes",
 	"crdb_internal.partitions",
 	"crdb_internal.zones",
+
+	"system.descriptor",
+	"system.namespace",
+}
+
+// debugZipTableQueries overrides the queries used to collect some of the
+// tables in a debug zip. Descriptors are hex-encoded so that debug doctor
+// can decode them.
+var debugZipTableQueries = map[string]string{
+	"system.descriptor": `SELECT id, encode(descriptor, 'hex') AS descriptor FROM system.descriptor`,
+}
+
+// debugZipTableQuery returns the query used to collect a table in a debug
+// zip.
+func debugZipTableQuery(table string) string {
+	if query, ok := debugZipTableQueries[table]; ok {
+		return query
+	}
+	return fmt.Sprintf(`SELECT * FROM %s`, table)
 }
 
 // Tables collected from each node in a debug zip.
@@ -88,6 +110,9 @@ var debugZipTablesPerNode = []string{
 type zipper struct {
 	f *os.File
 	z *zip.Writer
+
+	// redactor, if set, is used to hide user data in the zip's contents.
+	redactor *redactor
 }
 
 func newZipper(f *os.File) *zipper {
@@ -127,6 +152,12 @@ func (z *zipper) createJSON(name string, m interface{}) error {
 	if !strings.HasSuffix(name, ".json") {
 		return errors.Errorf("%s does not have .json suffix", name)
 	}
+	if z.redactor != nil {
+		var err error
+		if m, err = z.redactor.redactJSON(m); err != nil {
+			return err
+		}
+	}
 	b, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
 		return err
@@ -230,6 +261,11 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 
 	z := newZipper(out)
 	defer z.close()
+	if debugCtx.zipRedact {
+		if z.redactor, err = newRedactor(); err != nil {
+			return err
+		}
+	}
 
 	timeout := 10 * time.Second
 	if cliCtx.cmdTimeout != 0 {
@@ -283,7 +319,7 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 	}
 
 	for _, table := range debugZipTablesPerCluster {
-		query := fmt.Sprintf(`SELECT * FROM %s`, table)
+		query := debugZipTableQuery(table)
 		if err := dumpTableDataForZip(z, sqlConn, query, base+"/"+table+".txt"); err != nil {
 			return errors.Wrap(err, table)
 		}
@@ -365,20 +401,34 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 					return err
 				}
 
-				var heapData []byte
-				err = contextutil.RunWithTimeout(baseCtx, "request heap profile", timeout,
-					func(ctx context.Context) error {
-						heap, err := status.Profile(ctx, &serverpb.ProfileRequest{
-							NodeId: id,
-							Type:   serverpb.ProfileRequest_HEAP,
+				// The mutex and block profiles are empty unless enabled by the
+				// server.debug.mutex_profile_fraction and
+				// server.debug.block_profile_rate cluster settings or the
+				// corresponding environment variables.
+				for _, p := range []struct {
+					name string
+					typ  serverpb.ProfileRequest_Type
+				}{
+					{"heap", serverpb.ProfileRequest_HEAP},
+					{"goroutine", serverpb.ProfileRequest_GOROUTINE},
+					{"mutex", serverpb.ProfileRequest_MUTEX},
+					{"block", serverpb.ProfileRequest_BLOCK},
+				} {
+					var profileData []byte
+					err = contextutil.RunWithTimeout(baseCtx, "request "+p.name+" profile", timeout,
+						func(ctx context.Context) error {
+							profile, err := status.Profile(ctx, &serverpb.ProfileRequest{
+								NodeId: id,
+								Type:   p.typ,
+							})
+							if err == nil {
+								profileData = profile.Data
+							}
+							return err
 						})
-						if err == nil {
-							heapData = heap.Data
-						}
+					if err := z.createRawOrError(prefix+"/"+p.name+".pprof", profileData, err); err != nil {
 						return err
-					})
-				if err := z.createRawOrError(prefix+"/heap.pprof", heapData, err); err != nil {
-					return err
+					}
 				}
 
 				var profiles *serverpb.GetFilesResponse
@@ -453,6 +503,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 							return err
 						}
 						for _, e := range entries.Entries {
+							if z.redactor != nil {
+								z.redactor.redactLogEntry(&e)
+							}
 							if err := e.Format(logOut); err != nil {
 								return err
 							}
@@ -534,7 +587,18 @@ func dumpTableDataForZip(z *zipper, conn *sqlConn, query string, name string) er
 	}
 	var buf bytes.Buffer
 
-	err := runQueryAndFormatResults(conn, &buf, makeQuery(query))
+	var err error
+	if z.redactor != nil {
+		var cols []string
+		var rows [][]string
+		cols, rows, err = runQuery(conn, makeQuery(query), true /* showMoreChars */)
+		if err == nil {
+			z.redactor.redactRows(cols, rows)
+			err = printQueryOutput(&buf, cols, newRowSliceIter(rows, strings.Repeat("l", len(cols))))
+		}
+	} else {
+		err = runQueryAndFormatResults(conn, &buf, makeQuery(query))
+	}
 	if err != nil {
 		return z.createError(name, err)
 	}
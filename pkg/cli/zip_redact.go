@@ -0,0 +1,236 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	gohex "encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// debugZipSQLColumns are the columns of the tables collected in a debug zip
+// that contain SQL statements. When redacting, the constants in these
+// statements are hidden.
+var debugZipSQLColumns = map[string]struct{}{
+	"query":             {},
+	"last_active_query": {},
+	"description":       {},
+	"statement":         {},
+}
+
+var (
+	// redactTableKeyRE matches pretty-printed table keys. The table and index
+	// IDs are preserved, and the remaining key components, which contain user
+	// data, are hidden.
+	redactTableKeyRE = regexp.MustCompile(
+		`(/Table/\d+/\d+)((?:/(?:"(?:[^"\\\n]|\\.)*"|[^/\s,;:\])"]+))+)`)
+	// redactKeyStringRE matches string components of pretty-printed keys.
+	redactKeyStringRE = regexp.MustCompile(`/"(?:[^"\\\n]|\\.)*"`)
+	// redactSQLStringRE matches SQL string literals.
+	redactSQLStringRE = regexp.MustCompile(`'(?:[^'\n]|'')*'`)
+	// redactBytesRE matches escaped byte sequences, as found in keys and
+	// values printed with %q.
+	redactBytesRE = regexp.MustCompile(`(?:\\x[0-9a-fA-F]{2})+`)
+)
+
+// redactor hides user data (keys, values and SQL constants) in the contents of
+// a debug zip. Rather than being removed, each piece of user data is replaced
+// by a salted hash, so that occurrences of the same value can be correlated
+// within a zip without revealing the value itself. The salt is chosen at
+// random for every zip.
+type redactor struct {
+	salt []byte
+}
+
+func newRedactor() (*redactor, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &redactor{salt: salt}, nil
+}
+
+// hash returns the placeholder for the given piece of user data.
+func (r *redactor) hash(data string) string {
+	h := sha256.New()
+	h.Write(r.salt)
+	h.Write([]byte(data))
+	return "<hidden:" + gohex.EncodeToString(h.Sum(nil)[:4]) + ">"
+}
+
+// redactText hides keys, SQL string literals and escaped byte sequences in
+// free-form text, such as log messages.
+func (r *redactor) redactText(s string) string {
+	s = redactTableKeyRE.ReplaceAllStringFunc(s, func(m string) string {
+		sub := redactTableKeyRE.FindStringSubmatch(m)
+		return sub[1] + "/" + r.hash(sub[2])
+	})
+	s = redactKeyStringRE.ReplaceAllStringFunc(s, func(m string) string {
+		return "/" + r.hash(m[1:])
+	})
+	s = redactSQLStringRE.ReplaceAllStringFunc(s, func(m string) string {
+		return "'" + r.hash(m) + "'"
+	})
+	return redactBytesRE.ReplaceAllStringFunc(s, r.hash)
+}
+
+// redactLogEntry hides the user data in a log entry. In the entries written
+// with --redactable-logs, each piece of data enclosed in redaction markers is
+// replaced; the messages of the other entries are redacted as free-form text.
+func (r *redactor) redactLogEntry(e *log.Entry) {
+	if e.Redactable {
+		log.RedactEntry(e, r.hash)
+		return
+	}
+	e.Message = r.redactText(e.Message)
+}
+
+// redactSQL hides the constants in the given SQL statements. If they cannot be
+// parsed, they are redacted as free-form text instead.
+func (r *redactor) redactSQL(sql string) string {
+	stmts, err := parser.Parse(sql)
+	if err != nil {
+		return r.redactText(sql)
+	}
+	return stmts.StringWithFlags(tree.FmtHideConstants)
+}
+
+// redactKey replaces the part of a key that contains user data with its hash.
+// Table keys retain their table and index IDs, and meta keys their prefix, so
+// that the redacted key can still be attributed to a table or index.
+func (r *redactor) redactKey(key []byte) []byte {
+	for _, prefix := range []roachpb.Key{keys.Meta1Prefix, keys.Meta2Prefix} {
+		if bytes.HasPrefix(key, prefix) {
+			rest := r.redactKey(key[len(prefix):])
+			return append(append([]byte(nil), prefix...), rest...)
+		}
+	}
+	if bytes.Compare(key, keys.TableDataMin) < 0 || bytes.Compare(key, keys.TableDataMax) >= 0 {
+		return key
+	}
+	rest, _, err := keys.DecodeTablePrefix(key)
+	if err != nil {
+		return key
+	}
+	if len(rest) > 0 {
+		if rest, _, err = encoding.DecodeUvarintAscending(rest); err != nil {
+			return key
+		}
+	}
+	if len(rest) == 0 {
+		return key
+	}
+	prefix := key[:len(key)-len(rest)]
+	return encoding.EncodeStringAscending(
+		append([]byte(nil), prefix...), r.hash(string(rest)))
+}
+
+// redactDesc redacts the start and end keys of a range descriptor in place.
+func (r *redactor) redactDesc(desc *roachpb.RangeDescriptor) {
+	if desc == nil {
+		return
+	}
+	desc.StartKey = r.redactKey(desc.StartKey)
+	desc.EndKey = r.redactKey(desc.EndKey)
+}
+
+// redactJSON returns a redacted copy of the given value, suitable for JSON
+// encoding. The keys in range descriptors are redacted, and all strings in the
+// JSON encoding of the value are redacted as free-form text.
+func (r *redactor) redactJSON(m interface{}) (interface{}, error) {
+	switch t := m.(type) {
+	case *serverpb.RangeInfo:
+		info := *t
+		if info.State.Desc != nil {
+			desc := *info.State.Desc
+			r.redactDesc(&desc)
+			info.State.Desc = &desc
+		}
+		m = &info
+	case *serverpb.RangeLogResponse:
+		resp := *t
+		resp.Events = append([]serverpb.RangeLogResponse_Event(nil), resp.Events...)
+		for i := range resp.Events {
+			if resp.Events[i].Event.Info == nil {
+				continue
+			}
+			info := *resp.Events[i].Event.Info
+			for _, desc := range []**roachpb.RangeDescriptor{
+				&info.UpdatedDesc, &info.NewDesc, &info.RemovedDesc,
+			} {
+				if *desc != nil {
+					d := **desc
+					r.redactDesc(&d)
+					*desc = &d
+				}
+			}
+			resp.Events[i].Event.Info = &info
+		}
+		m = &resp
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// Decode numbers as json.Number so that large integers, like timestamps,
+	// are preserved exactly.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return r.redactJSONValue(v), nil
+}
+
+func (r *redactor) redactJSONValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return r.redactText(t)
+	case []interface{}:
+		for i := range t {
+			t[i] = r.redactJSONValue(t[i])
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = r.redactJSONValue(t[k])
+		}
+	}
+	return v
+}
+
+// redactRows redacts the results of a query. The values of the columns listed
+// in debugZipSQLColumns are redacted as SQL statements, and all other values as
+// free-form text.
+func (r *redactor) redactRows(cols []string, rows [][]string) {
+	for _, row := range rows {
+		for i, val := range row {
+			if _, ok := debugZipSQLColumns[strings.ToLower(cols[i])]; ok {
+				row[i] = r.redactSQL(val)
+			} else {
+				row[i] = r.redactText(val)
+			}
+		}
+	}
+}
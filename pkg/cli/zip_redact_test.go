@@ -0,0 +1,165 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestRedactText(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &redactor{salt: []byte("salt")}
+	h := r.hash
+
+	testCases := []struct {
+		in, out string
+	}{
+		{`no user data here`, `no user data here`},
+		{`split at /Table/53/1/"secret"/0`, `split at /Table/53/1/` + h(`/"secret"/0`)},
+		{`split at /Table/53/1/"a b"`, `split at /Table/53/1/` + h(`/"a b"`)},
+		{`range [/Table/53/1/42, /Max)`, `range [/Table/53/1/` + h(`/42`) + `, /Max)`},
+		{`/Table/53/1 is untouched`, `/Table/53/1 is untouched`},
+		{`key /Local/Range/"foo"`, `key /Local/Range/` + h(`"foo"`)},
+		{`SELECT * FROM t WHERE a = 'secret'`, `SELECT * FROM t WHERE a = '` + h(`'secret'`) + `'`},
+		{`value "\x01\x02"`, `value "` + h(`\x01\x02`) + `"`},
+	}
+	for _, tc := range testCases {
+		if out := r.redactText(tc.in); out != tc.out {
+			t.Errorf("%s: expected %s, got %s", tc.in, tc.out, out)
+		}
+	}
+}
+
+func TestRedactLogEntry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &redactor{salt: []byte("salt")}
+	h := r.hash
+
+	// The marked data of redactable entries is replaced, wherever it is.
+	e := log.Entry{
+		Message:    `[n1,client=‹1.2.3.4:5678›] user ‹alice› ran ‹SELECT 1› in 3ms`,
+		Redactable: true,
+	}
+	r.redactLogEntry(&e)
+	if exp := `[n1,client=‹` + h(`1.2.3.4:5678`) + `›] user ‹` + h(`alice`) +
+		`› ran ‹` + h(`SELECT 1`) + `› in 3ms`; e.Message != exp {
+		t.Errorf("expected %s, got %s", exp, e.Message)
+	}
+
+	// Other entries are redacted as free-form text.
+	e = log.Entry{Message: `split at /Table/53/1/"secret"/0`}
+	r.redactLogEntry(&e)
+	if exp := `split at /Table/53/1/` + h(`/"secret"/0`); e.Message != exp {
+		t.Errorf("expected %s, got %s", exp, e.Message)
+	}
+}
+
+func TestRedactSQL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &redactor{salt: []byte("salt")}
+	out := r.redactSQL(`INSERT INTO t VALUES (1, 'secret')`)
+	if strings.Contains(out, "secret") || strings.Contains(out, "1") {
+		t.Errorf("constants not hidden: %s", out)
+	}
+	// Unparseable SQL is redacted as free-form text.
+	out = r.redactSQL(`SELEC 'secret'`)
+	if strings.Contains(out, "secret") {
+		t.Errorf("constants not hidden: %s", out)
+	}
+}
+
+func TestRedactKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &redactor{salt: []byte("salt")}
+
+	tableKey := encoding.EncodeUvarintAscending(
+		roachpb.Key(keys.MakeTablePrefix(53)), 1)
+	indexPrefix := append(roachpb.Key(nil), tableKey...)
+	tableKey = encoding.EncodeStringAscending(tableKey, "secret")
+
+	for _, key := range []roachpb.Key{
+		tableKey,
+		keys.RangeMetaKey(roachpb.RKey(tableKey)).AsRawKey(),
+	} {
+		redacted := roachpb.Key(r.redactKey(key))
+		if strings.Contains(string(redacted), "secret") {
+			t.Errorf("%s: user data not hidden: %s", key, redacted)
+		}
+		if !strings.Contains(string(redacted), string(indexPrefix)) {
+			t.Errorf("%s: index prefix not preserved: %s", key, redacted)
+		}
+	}
+
+	// Keys without user data are preserved.
+	for _, key := range []roachpb.Key{
+		keys.SystemConfigSpan.Key,
+		indexPrefix,
+		roachpb.KeyMax,
+	} {
+		if redacted := roachpb.Key(r.redactKey(key)); !redacted.Equal(key) {
+			t.Errorf("expected %s to be preserved, got %s", key, redacted)
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &redactor{salt: []byte("salt")}
+
+	startKey := encoding.EncodeStringAscending(
+		encoding.EncodeUvarintAscending(keys.MakeTablePrefix(53), 1), "secret")
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  5,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKeyMax,
+	}
+	info := &serverpb.RangeInfo{
+		Span: serverpb.PrettySpan{
+			StartKey: roachpb.Key(startKey).String(),
+			EndKey:   roachpb.KeyMax.String(),
+		},
+	}
+	info.State.Desc = desc
+
+	m, err := r.redactJSON(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("user data not hidden: %s", b)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatalf("redacted output is not valid JSON: %v", err)
+	}
+	// The input is not modified.
+	if !desc.StartKey.Equal(roachpb.RKey(startKey)) || info.State.Desc != desc {
+		t.Errorf("input was modified")
+	}
+}
@@ -383,7 +383,7 @@ func runCDCSchemaRegistry(ctx context.Context, t *test, c *cluster) {
 	var jobID string
 	if err := db.QueryRow(
 		`CREATE CHANGEFEED FOR foo INTO $1`+
-			`WITH updated, resolved, format=experimental_avro, confluent_schema_registry=$2`,
+			`WITH updated, resolved, format=avro, confluent_schema_registry=$2`,
 		kafka.sinkURL(ctx), kafka.schemaRegistryURL(ctx),
 	).Scan(&jobID); err != nil {
 		t.Fatal(err)
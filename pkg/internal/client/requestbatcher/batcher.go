@@ -109,6 +109,11 @@ type Config struct {
 	// If MaxMsgsPerBatch <= 0 then no limit is enforced.
 	MaxMsgsPerBatch int
 
+	// MaxMsgsPerBatchFunc, if set, is consulted in place of MaxMsgsPerBatch
+	// whenever a request is added to a batch, which allows the limit to be
+	// changed while the batcher is running.
+	MaxMsgsPerBatchFunc func() int
+
 	// MaxWait is the maximum amount of time a message should wait in a batch
 	// before being sent. If MaxWait is <= 0 then no wait timeout is enforced.
 	// It is inadvisable to disable both MaxIdle and MaxWait.
@@ -130,6 +135,11 @@ type Config struct {
 	// DefaultInFlightBackpressureLimit.
 	InFlightBackpressureLimit int
 
+	// InFlightBackpressureLimitFunc, if set, is consulted in place of
+	// InFlightBackpressureLimit whenever a batch is sent or completes, which
+	// allows the limit to be changed while the batcher is running.
+	InFlightBackpressureLimitFunc func() int
+
 	// NowFunc is used to determine the current time. It defaults to timeutil.Now.
 	NowFunc func() time.Time
 }
@@ -148,6 +158,25 @@ const (
 	backpressureRecoveryFraction = .8
 )
 
+// maxMsgsPerBatch returns the current maximum number of messages per batch.
+func (cfg *Config) maxMsgsPerBatch() int {
+	if cfg.MaxMsgsPerBatchFunc != nil {
+		return cfg.MaxMsgsPerBatchFunc()
+	}
+	return cfg.MaxMsgsPerBatch
+}
+
+// inFlightBackpressureLimit returns the current in-flight backpressure limit.
+func (cfg *Config) inFlightBackpressureLimit() int {
+	if cfg.InFlightBackpressureLimitFunc != nil {
+		if l := cfg.InFlightBackpressureLimitFunc(); l > 0 {
+			return l
+		}
+		return DefaultInFlightBackpressureLimit
+	}
+	return cfg.InFlightBackpressureLimit
+}
+
 func backpressureRecoveryThreshold(limit int) int {
 	if l := int(float64(limit) * backpressureRecoveryFraction); l > 0 {
 		return l
@@ -323,7 +352,8 @@ func addRequestToBatch(cfg *Config, now time.Time, ba *batch, r *request) (shoul
 			ba.deadline = waitDeadline
 		}
 	}
-	return (cfg.MaxMsgsPerBatch > 0 && len(ba.reqs) >= cfg.MaxMsgsPerBatch) ||
+	maxMsgs := cfg.maxMsgsPerBatch()
+	return (maxMsgs > 0 && len(ba.reqs) >= maxMsgs) ||
 		(cfg.MaxSizePerBatch > 0 && ba.size >= cfg.MaxSizePerBatch)
 }
 
@@ -346,11 +376,8 @@ func (b *RequestBatcher) run(ctx context.Context) {
 		// true.
 		inFlight = 0
 		// inBackPressure indicates whether the reqChan is enabled.
-		// It becomes true when inFlight exceeds b.cfg.InFlightBackpressureLimit.
+		// It becomes true when inFlight exceeds the in-flight backpressure limit.
 		inBackPressure = false
-		// recoveryThreshold is the number of in flight requests below which the
-		// the inBackPressure state should exit.
-		recoveryThreshold = backpressureRecoveryThreshold(b.cfg.InFlightBackpressureLimit)
 		// reqChan consults inBackPressure to determine whether the goroutine is
 		// accepting new requests.
 		reqChan = func() <-chan *request {
@@ -361,13 +388,16 @@ func (b *RequestBatcher) run(ctx context.Context) {
 		}
 		sendBatch = func(ba *batch) {
 			inFlight++
-			if inFlight >= b.cfg.InFlightBackpressureLimit {
+			if inFlight >= b.cfg.inFlightBackpressureLimit() {
 				inBackPressure = true
 			}
 			b.sendBatch(sendCtx, ba)
 		}
 		handleSendDone = func() {
 			inFlight--
+			// The in flight requests below which the inBackPressure state should
+			// exit.
+			recoveryThreshold := backpressureRecoveryThreshold(b.cfg.inFlightBackpressureLimit())
 			if inFlight < recoveryThreshold {
 				inBackPressure = false
 			}
@@ -237,6 +237,48 @@ func TestBatcherSend(t *testing.T) {
 	}
 }
 
+// TestBatcherSendMaxMsgsPerBatchFunc ensures that changes to the value
+// returned by MaxMsgsPerBatchFunc take effect for subsequent batches.
+func TestBatcherSendMaxMsgsPerBatchFunc(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	sc := make(chanSender)
+	maxMsgs := int64(1)
+	b := New(Config{
+		// The timeouts are long enough that batches are only sent due to their
+		// size.
+		MaxIdle: time.Hour,
+		MaxWait: time.Hour,
+		MaxMsgsPerBatchFunc: func() int {
+			return int(atomic.LoadInt64(&maxMsgs))
+		},
+		Sender:  sc,
+		Stopper: stopper,
+	})
+	var g errgroup.Group
+	sendRequest := func(rangeID roachpb.RangeID, request roachpb.Request) {
+		g.Go(func() error {
+			_, err := b.Send(context.Background(), rangeID, request)
+			return err
+		})
+	}
+	sendRequest(1, &roachpb.GetRequest{})
+	s := <-sc
+	assert.Len(t, s.ba.Requests, 1)
+	s.respChan <- batchResp{}
+
+	atomic.StoreInt64(&maxMsgs, 2)
+	sendRequest(1, &roachpb.GetRequest{})
+	sendRequest(1, &roachpb.GetRequest{})
+	s = <-sc
+	assert.Len(t, s.ba.Requests, 2)
+	s.respChan <- batchResp{}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("expected no errors, got %v", err)
+	}
+}
+
 func TestSendAfterStopped(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
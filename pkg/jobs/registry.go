@@ -111,6 +111,9 @@ type Registry struct {
 		// propagated to jobs via the .Progressed call. This function should not be
 		// used to cancel a job in that way.
 		jobs map[int64]context.CancelFunc
+		// runningSchedules holds the IDs of the schedules of
+		// system.scheduled_jobs being executed by this registry.
+		runningSchedules map[int64]struct{}
 	}
 
 	TestingResumerCreationKnobs map[jobspb.Type]func(Resumer) Resumer
@@ -156,6 +159,7 @@ func MakeRegistry(
 	}
 	r.mu.epoch = 1
 	r.mu.jobs = make(map[int64]context.CancelFunc)
+	r.mu.runningSchedules = make(map[int64]struct{})
 	r.metrics.InitHooks(histogramWindowInterval)
 	return r
 }
@@ -329,6 +333,10 @@ func (r *Registry) Start(
 			}
 		}
 	})
+
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		r.runSchedulerWorker(ctx, stopper, nl)
+	})
 	return nil
 }
 
@@ -784,6 +792,7 @@ func (r *Registry) cancelAll(ctx context.Context) {
 		cancel()
 	}
 	r.mu.jobs = make(map[int64]context.CancelFunc)
+	r.mu.runningSchedules = make(map[int64]struct{})
 }
 
 func (r *Registry) register(jobID int64, cancel func()) {
@@ -0,0 +1,319 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var (
+	schedulerEnabledSetting = settings.RegisterBoolSetting(
+		"jobs.scheduler.enabled",
+		"enable the execution of the schedules of system.scheduled_jobs",
+		true,
+	)
+	schedulerPaceSetting = settings.RegisterNonNegativeDurationSetting(
+		"jobs.scheduler.pace",
+		"how often nodes look for schedules of system.scheduled_jobs to execute",
+		time.Minute,
+	)
+	schedulerLogLimiter = log.Every(time.Minute)
+)
+
+// maxSchedulesPerPass is the maximum number of due schedules which a node
+// considers each time it looks for schedules to execute.
+const maxSchedulesPerPass = 10
+
+// The last_status values of system.scheduled_jobs, besides the errors of
+// failed executions.
+const (
+	// ScheduleStatusRunningFmt is the status of a schedule while the node
+	// which executes it is running its job.
+	ScheduleStatusRunningFmt = "running on node %d"
+	// ScheduleStatusSucceeded is the status of a schedule whose last
+	// execution succeeded.
+	ScheduleStatusSucceeded = "succeeded"
+)
+
+// ScheduledJob is a schedule of the system.scheduled_jobs table, which
+// periodically creates jobs.
+type ScheduledJob struct {
+	ID    int64
+	Name  string
+	Owner string
+	// NextRun is the next time at which the schedule executes, or zero if
+	// the schedule is paused.
+	NextRun time.Time
+	// ScheduleExpr is the crontab expression of the schedule.
+	ScheduleExpr string
+	// ExecutorType is the name under which the ScheduledJobExecutor of the
+	// schedule is registered.
+	ExecutorType string
+	// ExecutionArgs are the JSON-encoded arguments of the executor. They can
+	// be updated by the executor, e.g. to track the state kept across
+	// executions.
+	ExecutionArgs string
+}
+
+// ScheduledJobExecutor executes the schedules of a certain executor type.
+type ScheduledJobExecutor interface {
+	// ExecuteJob executes the schedule, on behalf of its owner, and returns
+	// the ID of the job it created. It can update the ExecutionArgs of the
+	// schedule, which are persisted once the execution completes.
+	ExecuteJob(ctx context.Context, ex sqlutil.InternalExecutor, schedule *ScheduledJob) (int64, error)
+}
+
+var scheduledJobExecutors = make(map[string]ScheduledJobExecutor)
+
+// RegisterScheduledJobExecutor registers the executor of the schedules of
+// the given executor type.
+func RegisterScheduledJobExecutor(executorType string, executor ScheduledJobExecutor) {
+	scheduledJobExecutors[executorType] = executor
+}
+
+// NextRunTime returns the first time after the given time at which the
+// crontab expression fires.
+func NextRunTime(scheduleExpr string, after time.Time) (time.Time, error) {
+	sched, err := cron.Parse(scheduleExpr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := sched.Next(after)
+	if next.IsZero() {
+		return time.Time{}, errors.Errorf("crontab expression %q never fires", scheduleExpr)
+	}
+	return next, nil
+}
+
+// CreateScheduledJob inserts the schedule into system.scheduled_jobs, and
+// returns its ID. If the next run of the schedule isn't set, it is computed
+// from its crontab expression.
+func CreateScheduledJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, schedule *ScheduledJob,
+) (int64, error) {
+	if schedule.NextRun.IsZero() {
+		next, err := NextRunTime(schedule.ScheduleExpr, timeutil.Now())
+		if err != nil {
+			return 0, err
+		}
+		schedule.NextRun = next
+	}
+	row, err := ex.QueryRow(ctx, "create-schedule", txn,
+		`INSERT INTO system.scheduled_jobs
+       (schedule_name, owner, next_run, schedule_expr, executor_type, execution_args)
+     VALUES ($1, $2, $3, $4, $5, $6::JSONB) RETURNING schedule_id`,
+		schedule.Name, schedule.Owner, schedule.NextRun, schedule.ScheduleExpr,
+		schedule.ExecutorType, schedule.ExecutionArgs,
+	)
+	if err != nil {
+		return 0, err
+	}
+	schedule.ID = int64(tree.MustBeDInt(row[0]))
+	return schedule.ID, nil
+}
+
+// runSchedulerWorker periodically executes the schedules which are due.
+func (r *Registry) runSchedulerWorker(ctx context.Context, stopper *stop.Stopper, nl NodeLiveness) {
+	ctx = r.ac.AnnotateCtx(ctx)
+	// Pick up changes of the pace without waiting for the current interval.
+	paceChanged := make(chan struct{}, 1)
+	schedulerPaceSetting.SetOnChange(&r.settings.SV, func() {
+		select {
+		case paceChanged <- struct{}{}:
+		default:
+		}
+	})
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(schedulerPaceSetting.Get(&r.settings.SV))
+	for {
+		select {
+		case <-paceChanged:
+			timer.Reset(schedulerPaceSetting.Get(&r.settings.SV))
+		case <-timer.C:
+			timer.Read = true
+			if schedulerEnabledSetting.Get(&r.settings.SV) {
+				if err := r.executeSchedules(ctx, stopper, nl); err != nil &&
+					schedulerLogLimiter.ShouldLog() {
+					log.Warningf(ctx, "error executing schedules: %s", err)
+				}
+			}
+			timer.Reset(schedulerPaceSetting.Get(&r.settings.SV))
+		case <-stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// executeSchedules claims the schedules which are due and starts their
+// executions. A schedule is claimed by advancing its next run, which only
+// one node can do for a given run.
+func (r *Registry) executeSchedules(
+	ctx context.Context, stopper *stop.Stopper, nl NodeLiveness,
+) error {
+	rows, err := r.ex.Query(ctx, "find-schedules", nil, /* txn */
+		`SELECT schedule_id, schedule_name, owner, next_run, schedule_expr, executor_type,
+            execution_args::STRING, last_status
+       FROM system.scheduled_jobs
+      WHERE next_run <= $1
+      ORDER BY next_run
+      LIMIT $2`,
+		timeutil.Now(), maxSchedulesPerPass,
+	)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		schedule := &ScheduledJob{
+			ID:            int64(tree.MustBeDInt(row[0])),
+			Name:          string(tree.MustBeDString(row[1])),
+			Owner:         string(tree.MustBeDString(row[2])),
+			NextRun:       tree.MustBeDTimestamp(row[3]).Time,
+			ScheduleExpr:  string(tree.MustBeDString(row[4])),
+			ExecutorType:  string(tree.MustBeDString(row[5])),
+			ExecutionArgs: string(tree.MustBeDString(row[6])),
+		}
+		var lastStatus string
+		if row[7] != tree.DNull {
+			lastStatus = string(tree.MustBeDString(row[7]))
+		}
+		if err := r.maybeExecuteSchedule(ctx, stopper, nl, schedule, row[3], lastStatus); err != nil {
+			log.Warningf(ctx, "error executing schedule %d: %s", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+// maybeExecuteSchedule claims a due schedule and, unless its previous
+// execution is still running, starts its execution.
+func (r *Registry) maybeExecuteSchedule(
+	ctx context.Context,
+	stopper *stop.Stopper,
+	nl NodeLiveness,
+	schedule *ScheduledJob,
+	prevNextRun tree.Datum,
+	lastStatus string,
+) error {
+	now := timeutil.Now()
+	var nextRun interface{} = tree.DNull
+	if next, err := NextRunTime(schedule.ScheduleExpr, now); err == nil {
+		nextRun = next
+	} else {
+		// The schedule won't fire again; pause it.
+		log.Warningf(ctx, "pausing schedule %d: %s", schedule.ID, err)
+	}
+	if r.scheduleStillRunning(nl, schedule.ID, lastStatus) {
+		// Skip this run, so that the executions of a schedule don't overlap.
+		n, err := r.ex.Exec(ctx, "skip-schedule", nil, /* txn */
+			`UPDATE system.scheduled_jobs SET next_run = $1 WHERE schedule_id = $2 AND next_run = $3`,
+			nextRun, schedule.ID, prevNextRun,
+		)
+		if err == nil && n > 0 {
+			log.Infof(ctx, "skipping schedule %d: previous execution still running", schedule.ID)
+		}
+		return err
+	}
+	n, err := r.ex.Exec(ctx, "claim-schedule", nil, /* txn */
+		`UPDATE system.scheduled_jobs
+        SET next_run = $1, last_run = $2, last_status = $3, last_job_id = NULL
+      WHERE schedule_id = $4 AND next_run = $5`,
+		nextRun, now, fmt.Sprintf(ScheduleStatusRunningFmt, r.nodeID.Get()), schedule.ID, prevNextRun,
+	)
+	if err != nil || n == 0 {
+		// Another node claimed this run of the schedule.
+		return err
+	}
+
+	r.mu.Lock()
+	r.mu.runningSchedules[schedule.ID] = struct{}{}
+	r.mu.Unlock()
+	taskName := fmt.Sprintf("jobs: executing schedule %d", schedule.ID)
+	if err := stopper.RunAsyncTask(ctx, taskName, func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		r.executeSchedule(ctx, schedule)
+	}); err != nil {
+		r.mu.Lock()
+		delete(r.mu.runningSchedules, schedule.ID)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// scheduleStillRunning returns whether the previous execution of the
+// schedule is still running, i.e. whether it has been started by a node which
+// is still running it.
+func (r *Registry) scheduleStillRunning(nl NodeLiveness, scheduleID int64, lastStatus string) bool {
+	var nodeID roachpb.NodeID
+	if _, err := fmt.Sscanf(lastStatus, ScheduleStatusRunningFmt, &nodeID); err != nil {
+		return false
+	}
+	if nodeID == r.nodeID.Get() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		_, ok := r.mu.runningSchedules[scheduleID]
+		return ok
+	}
+	// The execution is abandoned if the node which started it died.
+	for _, l := range nl.GetLivenesses() {
+		if l.NodeID == nodeID {
+			return l.IsLive(r.clock.Now(), r.clock.MaxOffset())
+		}
+	}
+	return false
+}
+
+// executeSchedule executes the schedule with its executor, and records the
+// outcome of the execution.
+func (r *Registry) executeSchedule(ctx context.Context, schedule *ScheduledJob) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.mu.runningSchedules, schedule.ID)
+		r.mu.Unlock()
+	}()
+
+	var jobID interface{} = tree.DNull
+	status := ScheduleStatusSucceeded
+	executor, ok := scheduledJobExecutors[schedule.ExecutorType]
+	if !ok {
+		status = fmt.Sprintf("failed: unknown executor type %q", schedule.ExecutorType)
+	} else if id, err := executor.ExecuteJob(ctx, r.ex, schedule); err != nil {
+		log.Warningf(ctx, "schedule %d failed: %s", schedule.ID, err)
+		status = fmt.Sprintf("failed: %s", err)
+		if id != 0 {
+			jobID = id
+		}
+	} else {
+		jobID = id
+	}
+	if _, err := r.ex.Exec(ctx, "record-schedule-execution", nil, /* txn */
+		`UPDATE system.scheduled_jobs
+        SET last_status = $1, last_job_id = $2, execution_args = $3::JSONB
+      WHERE schedule_id = $4`,
+		status, jobID, schedule.ExecutionArgs, schedule.ID,
+	); err != nil {
+		log.Warningf(ctx, "error recording the execution of schedule %d: %s", schedule.ID, err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// countingExecutor counts its executions in the execution arguments of the
+// schedules.
+type countingExecutor struct {
+	syncutil.Mutex
+	executions int
+}
+
+func (e *countingExecutor) ExecuteJob(
+	_ context.Context, _ sqlutil.InternalExecutor, schedule *jobs.ScheduledJob,
+) (int64, error) {
+	e.Lock()
+	defer e.Unlock()
+	e.executions++
+	schedule.ExecutionArgs = fmt.Sprintf(`{"executions": %d}`, e.executions)
+	return 42, nil
+}
+
+func TestScheduledJobs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	jobs.RegisterScheduledJobExecutor("test-counting", &countingExecutor{})
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.pace = '10ms'`)
+
+	ie := s.InternalExecutor().(sqlutil.InternalExecutor)
+	createSchedule := func(executorType string) int64 {
+		id, err := jobs.CreateScheduledJob(ctx, ie, nil /* txn */, &jobs.ScheduledJob{
+			Name:          executorType,
+			Owner:         "root",
+			NextRun:       timeutil.Now().Add(-time.Minute),
+			ScheduleExpr:  "@yearly",
+			ExecutorType:  executorType,
+			ExecutionArgs: `{}`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	waitForStatus := func(id int64) (status string, jobID int64, args string) {
+		testutils.SucceedsSoon(t, func() error {
+			sqlDB.QueryRow(t, `
+SELECT COALESCE(last_status, ''), COALESCE(last_job_id, 0), execution_args::STRING
+  FROM system.scheduled_jobs WHERE schedule_id = $1`, id,
+			).Scan(&status, &jobID, &args)
+			if status == "" || strings.HasPrefix(status, "running") {
+				return errors.Errorf("schedule %d not executed yet", id)
+			}
+			return nil
+		})
+		return status, jobID, args
+	}
+
+	t.Run("executes due schedules", func(t *testing.T) {
+		id := createSchedule("test-counting")
+		status, jobID, args := waitForStatus(id)
+		if status != jobs.ScheduleStatusSucceeded || jobID != 42 || args != `{"executions": 1}` {
+			t.Fatalf("unexpected outcome: status %q, job %d, args %s", status, jobID, args)
+		}
+
+		// The next run of the schedule moved to next year.
+		var nextRun time.Time
+		sqlDB.QueryRow(t, `SELECT next_run FROM system.scheduled_jobs WHERE schedule_id = $1`, id).Scan(&nextRun)
+		if !nextRun.After(timeutil.Now()) {
+			t.Fatalf("expected the next run to be in the future, got %s", nextRun)
+		}
+	})
+
+	t.Run("reports unknown executors", func(t *testing.T) {
+		id := createSchedule("test-unknown")
+		if status, _, _ := waitForStatus(id); !strings.Contains(status, `unknown executor type "test-unknown"`) {
+			t.Fatalf("unexpected status %q", status)
+		}
+	})
+}
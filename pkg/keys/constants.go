@@ -235,6 +235,9 @@ var (
 	StatusPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("status-")))
 	// StatusNodePrefix stores all status info for nodes.
 	StatusNodePrefix = roachpb.Key(makeKey(StatusPrefix, roachpb.RKey("node-")))
+	// StatusKeyVisualizerPrefix stores the samples of load across the keyspace
+	// recorded by each node for the key visualizer.
+	StatusKeyVisualizerPrefix = roachpb.Key(makeKey(StatusPrefix, roachpb.RKey("keyvis-")))
 
 	// TimeseriesPrefix is the key prefix for all timeseries data.
 	TimeseriesPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("tsd")))
@@ -320,6 +323,8 @@ const (
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
 	CommentsTableID        = 24
+	ScheduledJobsTableID   = 25
+	TxnRetryStatsTableID   = 26
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
@@ -127,6 +127,23 @@ func NodeStatusKey(nodeID roachpb.NodeID) roachpb.Key {
 	return key
 }
 
+// KeyVisualizerSamplePrefix returns the key prefix of the key visualizer
+// samples recorded by the specified node ID.
+func KeyVisualizerSamplePrefix(nodeID roachpb.NodeID) roachpb.Key {
+	key := make(roachpb.Key, 0, len(StatusKeyVisualizerPrefix)+18)
+	key = append(key, StatusKeyVisualizerPrefix...)
+	key = encoding.EncodeUvarintAscending(key, uint64(nodeID))
+	return key
+}
+
+// KeyVisualizerSampleKey returns the key of the key visualizer sample
+// recorded by the specified node ID at the specified wall time, in
+// nanoseconds since the Unix epoch. Keys of samples recorded by the same node
+// sort by wall time.
+func KeyVisualizerSampleKey(nodeID roachpb.NodeID, wallTime int64) roachpb.Key {
+	return encoding.EncodeVarintAscending(KeyVisualizerSamplePrefix(nodeID), wallTime)
+}
+
 func makePrefixWithRangeID(prefix []byte, rangeID roachpb.RangeID, infix roachpb.RKey) roachpb.Key {
 	// Size the key buffer so that it is large enough for most callers.
 	key := make(roachpb.Key, 0, 32)
@@ -110,6 +110,10 @@ var (
 				ppFunc: decodeKeyPrint,
 				psFunc: parseUnsupported,
 			},
+			{name: "/StatusKeyVisualizer", prefix: StatusKeyVisualizerPrefix,
+				ppFunc: decodeKeyPrint,
+				psFunc: parseUnsupported,
+			},
 			{name: "/tsd", prefix: TimeseriesPrefix,
 				ppFunc: decodeTimeseriesKey,
 				psFunc: parseUnsupported,
@@ -175,6 +179,8 @@ var constSubKeyDict = []struct {
 	{"/storeIdent", localStoreIdentSuffix},
 	{"/gossipBootstrap", localStoreGossipSuffix},
 	{"/clusterVersion", localStoreClusterVersionSuffix},
+	{"/lastUp", localStoreLastUpSuffix},
+	{"/hlcUpperBound", localHLCUpperBoundSuffix},
 	{"/suggestedCompaction", localStoreSuggestedCompactionSuffix},
 }
 
@@ -45,6 +45,8 @@ func TestPrettyPrint(t *testing.T) {
 		{StoreIdentKey(), "/Local/Store/storeIdent"},
 		{StoreGossipKey(), "/Local/Store/gossipBootstrap"},
 		{StoreClusterVersionKey(), "/Local/Store/clusterVersion"},
+		{StoreLastUpKey(), "/Local/Store/lastUp"},
+		{StoreHLCUpperBoundKey(), "/Local/Store/hlcUpperBound"},
 		{StoreSuggestedCompactionKey(MinKey, roachpb.Key("b")), `/Local/Store/suggestedCompaction/{/Min-"b"}`},
 		{StoreSuggestedCompactionKey(roachpb.Key("a"), roachpb.Key("b")), `/Local/Store/suggestedCompaction/{"a"-"b"}`},
 		{StoreSuggestedCompactionKey(roachpb.Key("a"), MaxKey), `/Local/Store/suggestedCompaction/{"a"-/Max}`},
@@ -83,6 +85,7 @@ func TestPrettyPrint(t *testing.T) {
 
 		{NodeLivenessKey(10033), "/System/NodeLiveness/10033"},
 		{NodeStatusKey(1111), "/System/StatusNode/1111"},
+		{KeyVisualizerSampleKey(1111, 1234), "/System/StatusKeyVisualizer/1111/1234"},
 
 		{SystemMax, "/System/Max"},
 
@@ -0,0 +1,185 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package keyvisualizer collects the history of load across the keyspace
+// which powers the key visualizer, a heatmap of keyspace activity over time.
+package keyvisualizer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer/keyvisualizerpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// sampleInterval is the interval at which samples are collected.
+var sampleInterval = settings.RegisterNonNegativeDurationSetting(
+	"keyvisualizer.sample_interval",
+	"interval at which the load across the keyspace is sampled for the key visualizer, "+
+		"or 0 to disable collection",
+	0,
+)
+
+// maxBuckets is the number of spans that a sample is downsampled to.
+var maxBuckets = settings.RegisterPositiveIntSetting(
+	"keyvisualizer.max_buckets",
+	"maximum number of spans recorded in each key visualizer sample",
+	256,
+)
+
+// retention is the duration for which samples are retained.
+var retention = settings.RegisterNonNegativeDurationSetting(
+	"keyvisualizer.retention",
+	"duration for which key visualizer samples are retained",
+	24*time.Hour,
+)
+
+// Source returns the current load on the spans for which the node is
+// responsible. The returned buckets must not overlap, but need not be sorted.
+type Source func(ctx context.Context) ([]keyvisualizerpb.Bucket, error)
+
+// Collector periodically samples the load across the keyspace from a Source
+// and persists a downsampled history of the samples. Each node's samples are
+// stored in the system keyspace under keys.KeyVisualizerSamplePrefix, so that
+// they survive restarts and can be read from any node.
+type Collector struct {
+	st     *cluster.Settings
+	db     *client.DB
+	nodeID *base.NodeIDContainer
+	source Source
+}
+
+// NewCollector returns a new Collector which samples the given Source on
+// behalf of the node with the given ID.
+func NewCollector(
+	st *cluster.Settings, db *client.DB, nodeID *base.NodeIDContainer, source Source,
+) *Collector {
+	return &Collector{st: st, db: db, nodeID: nodeID, source: source}
+}
+
+// Start starts a worker which periodically collects samples.
+func (c *Collector) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		// Check for changes to the sample interval at least once a minute, so
+		// that collection can be enabled without restarting the node.
+		const idleInterval = time.Minute
+		for {
+			interval := sampleInterval.Get(&c.st.SV)
+			if interval == 0 {
+				timer.Reset(idleInterval)
+			} else {
+				timer.Reset(interval)
+			}
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+			if sampleInterval.Get(&c.st.SV) == 0 {
+				continue
+			}
+			if err := c.collect(ctx, timeutil.Now()); err != nil {
+				log.Warningf(ctx, "failed to collect key visualizer sample: %s", err)
+			}
+		}
+	})
+}
+
+// collect persists a sample taken at the given time and deletes the node's
+// samples which have exceeded their retention.
+func (c *Collector) collect(ctx context.Context, now time.Time) error {
+	buckets, err := c.source(ctx)
+	if err != nil {
+		return err
+	}
+	sample := keyvisualizerpb.Sample{
+		Time:    now,
+		Buckets: downsample(buckets, int(maxBuckets.Get(&c.st.SV))),
+	}
+
+	nodeID := c.nodeID.Get()
+	cutoff := now.Add(-retention.Get(&c.st.SV))
+	b := &client.Batch{}
+	b.Put(keys.KeyVisualizerSampleKey(nodeID, now.UnixNano()), &sample)
+	b.DelRange(
+		keys.KeyVisualizerSamplePrefix(nodeID),
+		keys.KeyVisualizerSampleKey(nodeID, cutoff.UnixNano()),
+		false, /* returnKeys */
+	)
+	return c.db.Run(ctx, b)
+}
+
+// downsample sorts the buckets by key and merges adjacent buckets until at
+// most max remain. Each merged bucket covers an equal number of the input
+// buckets, and its load is the sum of their loads. The merged span also
+// covers any gaps between the input spans.
+func downsample(buckets []keyvisualizerpb.Bucket, max int) []keyvisualizerpb.Bucket {
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Span.Key.Compare(buckets[j].Span.Key) < 0
+	})
+	if len(buckets) <= max {
+		return buckets
+	}
+	merged := make([]keyvisualizerpb.Bucket, 0, max)
+	for i := 0; i < max; i++ {
+		start, end := i*len(buckets)/max, (i+1)*len(buckets)/max
+		b := keyvisualizerpb.Bucket{
+			Span: roachpb.Span{
+				Key:    buckets[start].Span.Key,
+				EndKey: buckets[end-1].Span.EndKey,
+			},
+		}
+		for _, in := range buckets[start:end] {
+			b.RequestsPerSecond += in.RequestsPerSecond
+			b.WritesPerSecond += in.WritesPerSecond
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// Samples returns the samples persisted by the node with the given ID which
+// are within their retention, ordered by time. Samples which have exceeded
+// their retention but have not been deleted yet, for example because the node
+// is down, are omitted.
+func (c *Collector) Samples(
+	ctx context.Context, nodeID roachpb.NodeID,
+) ([]keyvisualizerpb.Sample, error) {
+	cutoff := timeutil.Now().Add(-retention.Get(&c.st.SV))
+	kvs, err := c.db.Scan(
+		ctx,
+		keys.KeyVisualizerSampleKey(nodeID, cutoff.UnixNano()),
+		keys.KeyVisualizerSamplePrefix(nodeID).PrefixEnd(),
+		0, /* maxRows */
+	)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]keyvisualizerpb.Sample, len(kvs))
+	for i, kv := range kvs {
+		if err := kv.ValueProto(&samples[i]); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
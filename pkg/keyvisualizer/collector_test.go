@@ -0,0 +1,126 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package keyvisualizer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer/keyvisualizerpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func makeBucket(start, end string, requests, writes float64) keyvisualizerpb.Bucket {
+	return keyvisualizerpb.Bucket{
+		Span:              roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)},
+		RequestsPerSecond: requests,
+		WritesPerSecond:   writes,
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	buckets := []keyvisualizerpb.Bucket{
+		makeBucket("c", "d", 3, 30),
+		makeBucket("a", "b", 1, 10),
+		makeBucket("e", "f", 5, 50),
+		makeBucket("b", "c", 2, 20),
+		makeBucket("d", "e", 4, 40),
+	}
+	testCases := []struct {
+		max      int
+		expected []keyvisualizerpb.Bucket
+	}{
+		{10, []keyvisualizerpb.Bucket{
+			makeBucket("a", "b", 1, 10),
+			makeBucket("b", "c", 2, 20),
+			makeBucket("c", "d", 3, 30),
+			makeBucket("d", "e", 4, 40),
+			makeBucket("e", "f", 5, 50),
+		}},
+		{2, []keyvisualizerpb.Bucket{
+			makeBucket("a", "c", 3, 30),
+			makeBucket("c", "f", 12, 120),
+		}},
+		{1, []keyvisualizerpb.Bucket{
+			makeBucket("a", "f", 15, 150),
+		}},
+	}
+	for _, tc := range testCases {
+		input := append([]keyvisualizerpb.Bucket(nil), buckets...)
+		if actual := downsample(input, tc.max); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%d: expected %+v, found %+v", tc.max, tc.expected, actual)
+		}
+	}
+}
+
+func TestCollectorRetention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	st := s.ClusterSettings()
+	retention.Override(&st.SV, time.Hour)
+	maxBuckets.Override(&st.SV, 1)
+	var nodeID base.NodeIDContainer
+	nodeID.Set(ctx, s.NodeID())
+	c := NewCollector(st, kvDB, &nodeID, func(context.Context) ([]keyvisualizerpb.Bucket, error) {
+		return []keyvisualizerpb.Bucket{makeBucket("a", "b", 1, 2), makeBucket("b", "c", 3, 4)}, nil
+	})
+
+	start := timeutil.Now().Add(-80 * time.Minute)
+	for i := 0; i < 3; i++ {
+		if err := c.collect(ctx, start.Add(time.Duration(i)*40*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first sample is more than an hour older than the last one and has
+	// been deleted.
+	prefix := keys.KeyVisualizerSamplePrefix(s.NodeID())
+	if kvs, err := kvDB.Scan(ctx, prefix, prefix.PrefixEnd(), 0); err != nil {
+		t.Fatal(err)
+	} else if len(kvs) != 2 {
+		t.Fatalf("expected 2 persisted samples, found %d", len(kvs))
+	}
+	samples, err := c.Samples(ctx, s.NodeID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, found %+v", samples)
+	}
+	expected := []keyvisualizerpb.Bucket{makeBucket("a", "c", 4, 6)}
+	for i, sample := range samples {
+		if exp := start.Add(time.Duration(i+1) * 40 * time.Minute); !sample.Time.Equal(exp) {
+			t.Errorf("expected sample at %s, found %s", exp, sample.Time)
+		}
+		if !reflect.DeepEqual(sample.Buckets, expected) {
+			t.Errorf("expected %+v, found %+v", expected, sample.Buckets)
+		}
+	}
+
+	// Samples are only returned for the requested node.
+	if samples, err := c.Samples(ctx, s.NodeID()+1); err != nil {
+		t.Fatal(err)
+	} else if len(samples) != 0 {
+		t.Fatalf("expected no samples, found %+v", samples)
+	}
+}
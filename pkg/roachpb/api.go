@@ -600,6 +600,9 @@ func (*SubsumeRequest) Method() Method { return Subsume }
 // Method implements the Request interface.
 func (*RangeStatsRequest) Method() Method { return RangeStats }
 
+// Method implements the Request interface.
+func (*ProbeRequest) Method() Method { return Probe }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -864,6 +867,12 @@ func (r *RangeStatsRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *ProbeRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -1142,6 +1151,10 @@ func (r *RefreshRangeRequest) flags() int {
 func (*SubsumeRequest) flags() int    { return isRead | isAlone | updatesReadTSCache }
 func (*RangeStatsRequest) flags() int { return isRead }
 
+// ProbeRequest is proposed by any replica, including those which don't hold
+// the lease, and is never applied, so it skips the lease check.
+func (*ProbeRequest) flags() int { return isWrite | isAlone | skipLeaseCheck }
+
 // IsParallelCommit returns whether the EndTransaction request is attempting to
 // perform a parallel commit. See txn_interceptor_committer.go for a discussion
 // about parallel commits.
@@ -154,6 +154,16 @@ func (ba *BatchRequest) IsSingleSkipLeaseCheckRequest() bool {
 	return ba.IsSingleRequest() && ba.hasFlag(skipLeaseCheck)
 }
 
+// IsSingleProbeRequest returns true iff the batch contains a single request,
+// and that request is a ProbeRequest.
+func (ba *BatchRequest) IsSingleProbeRequest() bool {
+	if ba.IsSingleRequest() {
+		_, ok := ba.Requests[0].GetInner().(*ProbeRequest)
+		return ok
+	}
+	return false
+}
+
 // IsSinglePushTxnRequest returns true iff the batch contains a single
 // request, and that request is for a PushTxn.
 func (ba *BatchRequest) IsSinglePushTxnRequest() bool {
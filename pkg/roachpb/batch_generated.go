@@ -72,6 +72,8 @@ func (ru ErrorDetail) GetInner() error {
 		return t.RangefeedRetry
 	case *ErrorDetail_IndeterminateCommit:
 		return t.IndeterminateCommit
+	case *ErrorDetail_MinTimestampBoundUnsatisfiable:
+		return t.MinTimestampBoundUnsatisfiable
 	default:
 		return nil
 	}
@@ -168,6 +170,8 @@ func (ru RequestUnion) GetInner() Request {
 		return t.Subsume
 	case *RequestUnion_RangeStats:
 		return t.RangeStats
+	case *RequestUnion_Probe:
+		return t.Probe
 	default:
 		return nil
 	}
@@ -262,6 +266,8 @@ func (ru ResponseUnion) GetInner() Response {
 		return t.Subsume
 	case *ResponseUnion_RangeStats:
 		return t.RangeStats
+	case *ResponseUnion_Probe:
+		return t.Probe
 	default:
 		return nil
 	}
@@ -331,6 +337,8 @@ func (ru *ErrorDetail) SetInner(r error) bool {
 		union = &ErrorDetail_RangefeedRetry{t}
 	case *IndeterminateCommitError:
 		union = &ErrorDetail_IndeterminateCommit{t}
+	case *MinTimestampBoundUnsatisfiableError:
+		union = &ErrorDetail_MinTimestampBoundUnsatisfiable{t}
 	default:
 		return false
 	}
@@ -430,6 +438,8 @@ func (ru *RequestUnion) SetInner(r Request) bool {
 		union = &RequestUnion_Subsume{t}
 	case *RangeStatsRequest:
 		union = &RequestUnion_RangeStats{t}
+	case *ProbeRequest:
+		union = &RequestUnion_Probe{t}
 	default:
 		return false
 	}
@@ -527,6 +537,8 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 		union = &ResponseUnion_Subsume{t}
 	case *RangeStatsResponse:
 		union = &ResponseUnion_RangeStats{t}
+	case *ProbeResponse:
+		union = &ResponseUnion_Probe{t}
 	default:
 		return false
 	}
@@ -534,7 +546,7 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 	return true
 }
 
-type reqCounts [44]int32
+type reqCounts [45]int32
 
 // getReqCounts returns the number of times each
 // request type appears in the batch.
@@ -630,6 +642,8 @@ func (ba *BatchRequest) getReqCounts() reqCounts {
 			counts[42]++
 		case *RequestUnion_RangeStats:
 			counts[43]++
+		case *RequestUnion_Probe:
+			counts[44]++
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", ru))
 		}
@@ -682,6 +696,7 @@ var requestNames = []string{
 	"RefreshRng",
 	"Subsume",
 	"RngStats",
+	"Probe",
 }
 
 // Summary prints a short summary of the requests in a batch.
@@ -889,6 +904,10 @@ type rangeStatsResponseAlloc struct {
 	union ResponseUnion_RangeStats
 	resp  RangeStatsResponse
 }
+type probeResponseAlloc struct {
+	union ResponseUnion_Probe
+	resp  ProbeResponse
+}
 
 // CreateReply creates replies for each of the contained requests, wrapped in a
 // BatchResponse. The response objects are batch allocated to minimize
@@ -943,6 +962,7 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 	var buf41 []refreshRangeResponseAlloc
 	var buf42 []subsumeResponseAlloc
 	var buf43 []rangeStatsResponseAlloc
+	var buf44 []probeResponseAlloc
 
 	for i, r := range ba.Requests {
 		switch r.GetValue().(type) {
@@ -1254,6 +1274,13 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 			buf43[0].union.RangeStats = &buf43[0].resp
 			br.Responses[i].Value = &buf43[0].union
 			buf43 = buf43[1:]
+		case *RequestUnion_Probe:
+			if buf44 == nil {
+				buf44 = make([]probeResponseAlloc, counts[44])
+			}
+			buf44[0].union.Probe = &buf44[0].resp
+			br.Responses[i].Value = &buf44[0].union
+			buf44 = buf44[1:]
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
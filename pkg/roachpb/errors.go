@@ -883,3 +883,15 @@ func (e *IndeterminateCommitError) message(pErr *Error) string {
 }
 
 var _ ErrorDetailInterface = &IndeterminateCommitError{}
+
+func (e *MinTimestampBoundUnsatisfiableError) Error() string {
+	return e.message(nil)
+}
+
+func (e *MinTimestampBoundUnsatisfiableError) message(_ *Error) string {
+	return fmt.Sprintf("bounded staleness read with minimum timestamp bound of %s "+
+		"could not be satisfied by a local resolved timestamp of %s",
+		e.MinTimestampBound, e.ResolvedTimestamp)
+}
+
+var _ ErrorDetailInterface = &MinTimestampBoundUnsatisfiableError{}
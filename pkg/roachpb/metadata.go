@@ -270,6 +270,9 @@ func (r ReplicationTarget) String() string {
 	return fmt.Sprintf("n%d,s%d", r.NodeID, r.StoreID)
 }
 
+// SafeValue implements the log.SafeValue interface.
+func (r ReplicationTarget) SafeValue() {}
+
 func (r ReplicaDescriptor) String() string {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "(n%d,s%d):", r.NodeID, r.StoreID)
@@ -284,6 +287,9 @@ func (r ReplicaDescriptor) String() string {
 	return buf.String()
 }
 
+// SafeValue implements the log.SafeValue interface.
+func (r ReplicaDescriptor) SafeValue() {}
+
 // Validate performs some basic validation of the contents of a replica descriptor.
 func (r ReplicaDescriptor) Validate() error {
 	if r.NodeID == 0 {
@@ -158,4 +158,7 @@ const (
 	Subsume
 	// RangeStats returns the MVCC statistics for a range.
 	RangeStats
+	// Probe is a no-op write which is replicated through Raft, used to check
+	// that a range is able to replicate commands.
+	Probe
 )
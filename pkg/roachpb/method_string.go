@@ -52,11 +52,12 @@ func _() {
 	_ = x[RefreshRange-41]
 	_ = x[Subsume-42]
 	_ = x[RangeStats-43]
+	_ = x[Probe-44]
 }
 
-const _Method_name = "GetPutConditionalPutIncrementDeleteDeleteRangeClearRangeRevertRangeScanReverseScanBeginTransactionEndTransactionAdminSplitAdminUnsplitAdminMergeAdminTransferLeaseAdminChangeReplicasAdminRelocateRangeHeartbeatTxnGCPushTxnRecoverTxnQueryTxnQueryIntentResolveIntentResolveIntentRangeMergeTruncateLogRequestLeaseTransferLeaseLeaseInfoComputeChecksumCheckConsistencyInitPutWriteBatchExportImportAdminScatterAddSSTableRecomputeStatsRefreshRefreshRangeSubsumeRangeStats"
+const _Method_name = "GetPutConditionalPutIncrementDeleteDeleteRangeClearRangeRevertRangeScanReverseScanBeginTransactionEndTransactionAdminSplitAdminUnsplitAdminMergeAdminTransferLeaseAdminChangeReplicasAdminRelocateRangeHeartbeatTxnGCPushTxnRecoverTxnQueryTxnQueryIntentResolveIntentResolveIntentRangeMergeTruncateLogRequestLeaseTransferLeaseLeaseInfoComputeChecksumCheckConsistencyInitPutWriteBatchExportImportAdminScatterAddSSTableRecomputeStatsRefreshRefreshRangeSubsumeRangeStatsProbe"
 
-var _Method_index = [...]uint16{0, 3, 6, 20, 29, 35, 46, 56, 67, 71, 82, 98, 112, 122, 134, 144, 162, 181, 199, 211, 213, 220, 230, 238, 249, 262, 280, 285, 296, 308, 321, 330, 345, 361, 368, 378, 384, 390, 402, 412, 426, 433, 445, 452, 462}
+var _Method_index = [...]uint16{0, 3, 6, 20, 29, 35, 46, 56, 67, 71, 82, 98, 112, 122, 134, 144, 162, 181, 199, 211, 213, 220, 230, 238, 249, 262, 280, 285, 296, 308, 321, 330, 345, 361, 368, 378, 384, 390, 402, 412, 426, 433, 445, 452, 462, 467}
 
 func (i Method) String() string {
 	if i < 0 || i >= Method(len(_Method_index)-1) {
@@ -29,6 +29,8 @@ import (
 type RemoteClockMetrics struct {
 	ClockOffsetMeanNanos   *metric.Gauge
 	ClockOffsetStdDevNanos *metric.Gauge
+	ClockOffsetMaxAbsNanos *metric.Gauge
+	MaxOffsetNanos         *metric.Gauge
 	LatencyHistogramNanos  *metric.Histogram
 }
 
@@ -39,6 +41,21 @@ type RemoteClockMetrics struct {
 // minute old.
 const avgLatencyMeasurementAge = 20.0
 
+// ToleratedOffset returns the offset to another node above which the offset
+// is unhealthy. A node terminates once its offsets to at least half of the
+// known nodes are unhealthy.
+func ToleratedOffset(maxOffset time.Duration) time.Duration {
+	// Tolerate up to 80% of the maximum offset.
+	return maxOffset * 4 / 5
+}
+
+// WarnOffset returns the offset to another node above which the node warns
+// that its offset is approaching the tolerated offset, leaving time to fix
+// the clock synchronization of the node before it terminates.
+func WarnOffset(maxOffset time.Duration) time.Duration {
+	return maxOffset / 2
+}
+
 var (
 	metaClockOffsetMeanNanos = metric.Metadata{
 		Name:        "clock-offset.meannanos",
@@ -52,6 +69,18 @@ var (
 		Measurement: "Clock Offset",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaClockOffsetMaxAbsNanos = metric.Metadata{
+		Name:        "clock-offset.maxabsnanos",
+		Help:        "Maximum absolute clock offset with other nodes",
+		Measurement: "Clock Offset",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaMaxOffsetNanos = metric.Metadata{
+		Name:        "clock-offset.maxoffsetnanos",
+		Help:        "Maximum clock offset with other nodes tolerated by the cluster (--max-offset)",
+		Measurement: "Clock Offset",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
 	metaLatencyHistogramNanos = metric.Metadata{
 		Name:        "round-trip-latency",
 		Help:        "Distribution of round-trip latencies with other nodes",
@@ -73,6 +102,9 @@ type RemoteClockMonitor struct {
 	}
 
 	metrics RemoteClockMetrics
+	// warnEvery rate limits the warnings about the offsets approaching the
+	// maximum offset.
+	warnEvery log.EveryN
 }
 
 // newRemoteClockMonitor returns a monitor with the given server clock.
@@ -82,6 +114,7 @@ func newRemoteClockMonitor(
 	r := RemoteClockMonitor{
 		clock:     clock,
 		offsetTTL: offsetTTL,
+		warnEvery: log.Every(time.Minute),
 	}
 	r.mu.offsets = make(map[string]RemoteOffset)
 	r.mu.latenciesNanos = make(map[string]ewma.MovingAverage)
@@ -91,6 +124,8 @@ func newRemoteClockMonitor(
 	r.metrics = RemoteClockMetrics{
 		ClockOffsetMeanNanos:   metric.NewGauge(metaClockOffsetMeanNanos),
 		ClockOffsetStdDevNanos: metric.NewGauge(metaClockOffsetStdDevNanos),
+		ClockOffsetMaxAbsNanos: metric.NewGauge(metaClockOffsetMaxAbsNanos),
+		MaxOffsetNanos:         metric.NewGauge(metaMaxOffsetNanos),
 		LatencyHistogramNanos:  metric.NewLatency(metaLatencyHistogramNanos, histogramWindowInterval),
 	}
 	return &r
@@ -127,6 +162,21 @@ func (r *RemoteClockMonitor) AllLatencies() map[string]time.Duration {
 	return result
 }
 
+// AllOffsets returns a map of all the offset measurements which aren't stale,
+// by node address.
+func (r *RemoteClockMonitor) AllOffsets() map[string]RemoteOffset {
+	now := r.clock.PhysicalTime()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string]RemoteOffset, len(r.mu.offsets))
+	for addr, offset := range r.mu.offsets {
+		if !offset.isStale(r.offsetTTL, now) {
+			result[addr] = offset
+		}
+	}
+	return result
+}
+
 // UpdateOffset is a thread-safe way to update the remote clock and latency
 // measurements.
 //
@@ -183,7 +233,7 @@ func (r *RemoteClockMonitor) UpdateOffset(
 }
 
 // VerifyClockOffset calculates the number of nodes to which the known offset
-// is healthy (as defined by RemoteOffset.isHealthy). It returns nil iff more
+// is healthy (as defined by RemoteOffset.IsHealthy). It returns nil iff more
 // than half the known offsets are healthy, and an error otherwise. A non-nil
 // return indicates that this node's clock is unreliable, and that the node
 // should terminate.
@@ -198,6 +248,8 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 		now := r.clock.PhysicalTime()
 
 		healthyOffsetCount := 0
+		var maxAbsOffset time.Duration
+		var warnAddr string
 
 		r.mu.Lock()
 		// Each measurement is recorded as its minimum and maximum value.
@@ -209,9 +261,15 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 			}
 			offsets = append(offsets, float64(offset.Offset+offset.Uncertainty))
 			offsets = append(offsets, float64(offset.Offset-offset.Uncertainty))
-			if offset.isHealthy(ctx, maxOffset) {
+			if offset.IsHealthy(ctx, maxOffset) {
 				healthyOffsetCount++
 			}
+			if absOffset := offset.absOffset(); absOffset > maxAbsOffset {
+				maxAbsOffset = absOffset
+				if absOffset > WarnOffset(maxOffset) {
+					warnAddr = addr
+				}
+			}
 		}
 		numClocks := len(r.mu.offsets)
 		r.mu.Unlock()
@@ -226,6 +284,15 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 		}
 		r.metrics.ClockOffsetMeanNanos.Update(int64(mean))
 		r.metrics.ClockOffsetStdDevNanos.Update(int64(stdDev))
+		r.metrics.ClockOffsetMaxAbsNanos.Update(maxAbsOffset.Nanoseconds())
+		r.metrics.MaxOffsetNanos.Update(maxOffset.Nanoseconds())
+
+		if warnAddr != "" && r.warnEvery.ShouldLog() {
+			log.Warningf(ctx, "clock offset of %s to node at %s is approaching the maximum offset of %s; "+
+				"this node will terminate if its offset to at least half of the known nodes "+
+				"exceeds %s, check the clock synchronization (e.g. NTP) of the nodes",
+				maxAbsOffset, warnAddr, maxOffset, ToleratedOffset(maxOffset))
+		}
 
 		if numClocks > 0 && healthyOffsetCount <= numClocks/2 {
 			return errors.Errorf(
@@ -240,9 +307,10 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 	return nil
 }
 
-func (r RemoteOffset) isHealthy(ctx context.Context, maxOffset time.Duration) bool {
-	// Tolerate up to 80% of the maximum offset.
-	toleratedOffset := maxOffset * 4 / 5
+// IsHealthy returns whether the offset is within the offset tolerated for the
+// given maximum offset.
+func (r RemoteOffset) IsHealthy(ctx context.Context, maxOffset time.Duration) bool {
+	toleratedOffset := ToleratedOffset(maxOffset)
 
 	// Offset may be negative, but Uncertainty is always positive.
 	absOffset := r.Offset
@@ -271,6 +339,14 @@ func (r RemoteOffset) isHealthy(ctx context.Context, maxOffset time.Duration) bo
 	}
 }
 
+// absOffset returns the absolute value of the measured offset.
+func (r RemoteOffset) absOffset() time.Duration {
+	if r.Offset < 0 {
+		return time.Duration(-r.Offset)
+	}
+	return time.Duration(r.Offset)
+}
+
 func (r RemoteOffset) isStale(ttl time.Duration, now time.Time) bool {
 	return r.measuredAt().Add(ttl).Before(now)
 }
@@ -144,7 +144,7 @@ func TestIsHealthyOffsetInterval(t *testing.T) {
 		{RemoteOffset{Offset: 15, Uncertainty: 4}, false},
 		{RemoteOffset{Offset: math.MaxInt64, Uncertainty: 0}, false},
 	} {
-		if isHealthy := tc.offset.isHealthy(context.TODO(), maxOffset); tc.expectedHealthy {
+		if isHealthy := tc.offset.IsHealthy(context.TODO(), maxOffset); tc.expectedHealthy {
 			if !isHealthy {
 				t.Errorf("%d: expected remote offset %s for maximum offset %s to be healthy", i, tc.offset, maxOffset)
 			}
@@ -181,6 +181,31 @@ func TestClockOffsetMetrics(t *testing.T) {
 	if a, e := monitor.Metrics().ClockOffsetStdDevNanos.Value(), int64(7); a != e {
 		t.Errorf("stdDev %d != expected %d", a, e)
 	}
+	if a, e := monitor.Metrics().ClockOffsetMaxAbsNanos.Value(), int64(13); a != e {
+		t.Errorf("max abs %d != expected %d", a, e)
+	}
+	if a, e := monitor.Metrics().MaxOffsetNanos.Value(), int64(20); a != e {
+		t.Errorf("max offset %d != expected %d", a, e)
+	}
+}
+
+func TestAllOffsets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	clock := hlc.NewClock(hlc.NewManualClock(int64(time.Hour)).UnixNano, time.Nanosecond)
+	monitor := newRemoteClockMonitor(clock, time.Minute, 0)
+	fresh := RemoteOffset{Offset: -5, Uncertainty: 1, MeasuredAt: int64(time.Hour)}
+	monitor.mu.offsets = map[string]RemoteOffset{
+		"fresh": fresh,
+		"stale": {Offset: 5, Uncertainty: 1, MeasuredAt: 1},
+	}
+	offsets := monitor.AllOffsets()
+	if len(offsets) != 1 || offsets["fresh"] != fresh {
+		t.Fatalf("unexpected offsets %v", offsets)
+	}
+	if a, e := fresh.absOffset(), 5*time.Nanosecond; a != e {
+		t.Errorf("absolute offset %s != expected %s", a, e)
+	}
 }
 
 // TestLatencies tests the tracking of round-trip latency between nodes.
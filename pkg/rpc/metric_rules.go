@@ -0,0 +1,38 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// CreateAndAddRules adds the recommended rules over the clock offset metrics
+// to the registry, given the maximum clock offset of the cluster. There are
+// none for clockless clusters.
+func CreateAndAddRules(r *metric.RuleRegistry, maxOffset time.Duration) {
+	if maxOffset == timeutil.ClocklessMaxOffset {
+		return
+	}
+	r.AddRule(metric.NewAlertingRule(
+		"ClockOffsetNearMax",
+		fmt.Sprintf("abs(%%[1]s) > %d", WarnOffset(maxOffset).Nanoseconds()),
+		[]metric.Metadata{metaClockOffsetMeanNanos},
+		5*time.Minute,
+		"warning",
+		"Instance {{ $labels.instance }} has a mean clock offset of {{ $value }}ns",
+		fmt.Sprintf("Nodes whose clock offset exceeds the maximum offset of %s "+
+			"crash to preserve consistency.", maxOffset),
+	))
+}
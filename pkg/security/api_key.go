@@ -0,0 +1,73 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// API keys authenticate clients of the HTTP API without a web session. They
+// are stored in system.web_sessions like web sessions, with an auditInfo of
+// APIKeyAuditInfoPrefix followed by the scope of the key.
+const (
+	// APIKeyScopeRead allows only read-only (GET and HEAD) HTTP requests.
+	APIKeyScopeRead = "read"
+	// APIKeyScopeAll allows all HTTP requests.
+	APIKeyScopeAll = "all"
+
+	// APIKeyAuditInfoPrefix is the prefix of the auditInfo column of the rows
+	// of system.web_sessions that are API keys.
+	APIKeyAuditInfoPrefix = "api_key:"
+
+	apiKeySecretLength = 16
+)
+
+// GenerateAPIKeySecret returns a new random API key secret, along with its
+// hash as stored in system.web_sessions.
+func GenerateAPIKeySecret() (secret, hashedSecret []byte, _ error) {
+	secret = make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	hash := sha256.Sum256(secret)
+	return secret, hash[:], nil
+}
+
+// EncodeAPIKey returns the API key with the given ID and secret, as given to
+// clients.
+func EncodeAPIKey(id int64, secret []byte) string {
+	return fmt.Sprintf("%d.%s", id, hex.EncodeToString(secret))
+}
+
+// DecodeAPIKey returns the ID and secret of an API key encoded with
+// EncodeAPIKey.
+func DecodeAPIKey(key string) (int64, []byte, error) {
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) != 2 {
+		return 0, nil, errors.New("malformed API key")
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("malformed API key")
+	}
+	secret, err := hex.DecodeString(parts[1])
+	if err != nil || len(secret) == 0 {
+		return 0, nil, errors.New("malformed API key")
+	}
+	return id, secret, nil
+}
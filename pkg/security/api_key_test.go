@@ -0,0 +1,46 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAPIKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	secret, hashedSecret, err := security.GenerateAPIKeySecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash := sha256.Sum256(secret); !bytes.Equal(hash[:], hashedSecret) {
+		t.Fatalf("unexpected hashed secret %x", hashedSecret)
+	}
+
+	id, decoded, err := security.DecodeAPIKey(security.EncodeAPIKey(42, secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 || !bytes.Equal(decoded, secret) {
+		t.Fatalf("unexpected decoded API key: %d, %x", id, decoded)
+	}
+
+	for _, key := range []string{"", "42", "x.00", "42.", "42.xyz"} {
+		if _, _, err := security.DecodeAPIKey(key); err == nil {
+			t.Errorf("%q: expected error", key)
+		}
+	}
+}
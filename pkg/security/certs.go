@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -419,6 +421,150 @@ func CreateClientPair(
 	return nil
 }
 
+// RotateCertificates re-issues the node certificate and the client
+// certificates found in the certs directory, along with new keys. The new
+// certificates retain the principals of the ones they replace: the hosts of
+// the node certificate, and the user of each client certificate. As in
+// CreateClientPair, client certificates are signed by the client CA if one
+// exists. Each file is replaced atomically, so that a node reloading its
+// certificates concurrently never reads a partially written file.
+// Returns the paths of the certificates that were rotated.
+func RotateCertificates(
+	certsDir, caKeyPath string, keySize int, lifetime time.Duration,
+) ([]string, error) {
+	if len(caKeyPath) == 0 {
+		return nil, errors.New("the path to the CA key is required")
+	}
+	if len(certsDir) == 0 {
+		return nil, errors.New("the path to the certs directory is required")
+	}
+
+	// The certificate manager expands the env for the certs directory.
+	// For consistency, we need to do this for the key as well.
+	caKeyPath = os.ExpandEnv(caKeyPath)
+
+	cm, err := NewCertificateManager(certsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated []string
+	if nodeCert := cm.NodeCert(); nodeCert != nil {
+		if nodeCert.Error != nil {
+			return nil, errors.Wrap(nodeCert.Error, "error loading node certificate")
+		}
+		caCert, caPrivateKey, err := loadCACertAndKey(cm.CACertPath(), caKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		nodeKey, err := rsa.GenerateKey(rand.Reader, keySize)
+		if err != nil {
+			return nil, errors.Errorf("could not generate new node key: %v", err)
+		}
+		parsed := nodeCert.ParsedCertificates[0]
+		hosts := append([]string(nil), parsed.DNSNames...)
+		for _, ip := range parsed.IPAddresses {
+			hosts = append(hosts, ip.String())
+		}
+		cert, err := GenerateServerCert(caCert, caPrivateKey, nodeKey.Public(), lifetime, hosts)
+		if err != nil {
+			return nil, errors.Errorf("error creating node server certificate and key: %s", err)
+		}
+		if err := replaceCertAndKey(cm.NodeCertPath(), cert, cm.NodeKeyPath(), nodeKey); err != nil {
+			return nil, err
+		}
+		rotated = append(rotated, cm.NodeCertPath())
+	}
+
+	clientCerts := cm.ClientCerts()
+	if len(clientCerts) > 0 {
+		caCertPath := cm.CACertPath()
+		if cm.ClientCACert() != nil {
+			caCertPath = cm.ClientCACertPath()
+		}
+		caCert, caPrivateKey, err := loadCACertAndKey(caCertPath, caKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		users := make([]string, 0, len(clientCerts))
+		for user := range clientCerts {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+		for _, user := range users {
+			if err := clientCerts[user].Error; err != nil {
+				return nil, errors.Wrapf(err, "error loading client certificate for user %s", user)
+			}
+			clientKey, err := rsa.GenerateKey(rand.Reader, keySize)
+			if err != nil {
+				return nil, errors.Errorf("could not generate new client key: %v", err)
+			}
+			cert, err := GenerateClientCert(caCert, caPrivateKey, clientKey.Public(), lifetime, user)
+			if err != nil {
+				return nil, errors.Errorf("error creating client certificate and key: %s", err)
+			}
+			if err := replaceCertAndKey(
+				cm.ClientCertPath(user), cert, cm.ClientKeyPath(user), clientKey,
+			); err != nil {
+				return nil, err
+			}
+			rotated = append(rotated, cm.ClientCertPath(user))
+		}
+	}
+
+	return rotated, nil
+}
+
+// replaceCertAndKey atomically replaces a certificate and its key. The key is
+// replaced first: a node that reloads its certificates in between the two
+// replacements fails to load the mismatched pair and keeps using the
+// previous one until the next reload.
+func replaceCertAndKey(
+	certPath string, cert []byte, keyPath string, key crypto.PrivateKey,
+) error {
+	keyBlock, err := PrivateKeyToPEM(key)
+	if err != nil {
+		return err
+	}
+	if err := replacePEMFile(keyPath, keyFileMode, keyBlock); err != nil {
+		return errors.Errorf("error writing key to %s: %v", keyPath, err)
+	}
+	log.Infof(context.Background(), "Rotated key: %s", keyPath)
+
+	certBlock := &pem.Block{Type: "CERTIFICATE", Bytes: cert}
+	if err := replacePEMFile(certPath, certFileMode, certBlock); err != nil {
+		return errors.Errorf("error writing certificate to %s: %v", certPath, err)
+	}
+	log.Infof(context.Background(), "Rotated certificate: %s", certPath)
+	return nil
+}
+
+// replacePEMFile writes the PEM blocks to a temporary file in the directory of
+// path, and renames it to path.
+func replacePEMFile(path string, mode os.FileMode, blocks ...*pem.Block) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// The temporary file is created with mode 0600, which WritePEMToFile does
+	// not change since the file already exists.
+	err = WritePEMToFile(tmpPath, mode, true /* overwrite */, blocks...)
+	if err == nil {
+		err = os.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return err
+}
+
 // PEMContentsToX509 takes raw pem-encoded contents and attempts to parse into
 // x509.Certificate objects.
 func PEMContentsToX509(contents []byte) ([]*x509.Certificate, error) {
@@ -145,6 +145,84 @@ func TestGenerateNodeCerts(t *testing.T) {
 	}
 }
 
+func TestRotateCertificates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetAssetLoader()
+	defer ResetTest()
+
+	certsDir, err := ioutil.TempDir("", "certs_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(certsDir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := generateBaseCerts(certsDir); err != nil {
+		t.Fatal(err)
+	}
+	before, err := security.NewCertificateManager(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The CA key is required.
+	if _, err := security.RotateCertificates(certsDir, "", 512, time.Hour*48); !testutils.IsError(
+		err, "the path to the CA key is required",
+	) {
+		t.Fatalf("expected CA key error, got %v", err)
+	}
+
+	rotated, err := security.RotateCertificates(
+		certsDir, filepath.Join(certsDir, security.EmbeddedCAKey), 512, time.Hour*24,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(certsDir, security.EmbeddedNodeCert),
+		filepath.Join(certsDir, security.EmbeddedRootCert),
+	}
+	if fmt.Sprint(rotated) != fmt.Sprint(expected) {
+		t.Errorf("expected %s to be rotated, got %s", expected, rotated)
+	}
+
+	after, err := security.NewCertificateManager(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		before, after *security.CertInfo
+	}{
+		{before.NodeCert(), after.NodeCert()},
+		{before.ClientCerts()[security.RootUser], after.ClientCerts()[security.RootUser]},
+	} {
+		if tc.after.Error != nil {
+			t.Fatal(tc.after.Error)
+		}
+		oldCert, newCert := tc.before.ParsedCertificates[0], tc.after.ParsedCertificates[0]
+		if oldCert.SerialNumber.Cmp(newCert.SerialNumber) == 0 {
+			t.Errorf("%s: certificate was not re-issued", tc.after.Filename)
+		}
+		if string(tc.before.KeyFileContents) == string(tc.after.KeyFileContents) {
+			t.Errorf("%s: key was not re-generated", tc.after.Filename)
+		}
+		if !newCert.NotAfter.Before(oldCert.NotAfter) {
+			t.Errorf("%s: expected the new lifetime to be used", tc.after.Filename)
+		}
+		if oldCert.Subject.CommonName != newCert.Subject.CommonName ||
+			fmt.Sprint(oldCert.IPAddresses) != fmt.Sprint(newCert.IPAddresses) ||
+			fmt.Sprint(oldCert.DNSNames) != fmt.Sprint(newCert.DNSNames) {
+			t.Errorf("%s: principals changed: %s %s %s -> %s %s %s", tc.after.Filename,
+				oldCert.Subject.CommonName, oldCert.IPAddresses, oldCert.DNSNames,
+				newCert.Subject.CommonName, newCert.IPAddresses, newCert.DNSNames)
+		}
+	}
+}
+
 // Generate basic certs:
 // ca.crt: CA certificate
 // node.crt: dual-purpose node certificate
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -57,6 +58,37 @@ const (
 	// administrative interface to the cockroach cluster.
 	adminPrefix = "/_admin/v1/"
 
+	// adminStmtDiagnostics is the prefix of the statement diagnostics
+	// endpoints, which request, list and download statement diagnostics.
+	adminStmtDiagnostics = adminPrefix + "stmtdiagnostics"
+
+	// adminVModule reads and changes the vmodule setting of a node.
+	adminVModule = adminPrefix + "vmodule"
+
+	// adminClusterSettings is the prefix of the SetClusterSetting and
+	// ResetClusterSetting endpoints.
+	adminClusterSettings = adminPrefix + "cluster_settings/"
+
+	// adminDecommissionCheck dry-runs the decommissioning of nodes.
+	adminDecommissionCheck = adminPrefix + "decommission_check"
+
+	// adminDrainStatus reports the work remaining on a node before it can be
+	// terminated, and adminDrainPhases drains a node phase by phase.
+	adminDrainStatus = adminPrefix + "drain/status"
+	adminDrainPhases = adminPrefix + "drain/phases"
+
+	// adminExecutionInsights exposes the statement executions which a node
+	// detected as anomalous.
+	adminExecutionInsights = adminPrefix + "execution_insights"
+
+	// adminIndexRecommendations exposes the index recommendations derived
+	// from the statement statistics of a node.
+	adminIndexRecommendations = adminPrefix + "index_recommendations"
+
+	// adminJobs is the prefix of the PauseJob, ResumeJob and CancelJob
+	// endpoints.
+	adminJobs = adminPrefix + "jobs/"
+
 	// defaultAPIEventLimit is the default maximum number of events returned by any
 	// endpoints returning events.
 	defaultAPIEventLimit = 1000
@@ -124,6 +156,21 @@ func (s *adminServer) getUser(_ protoutil.Message) string {
 	return security.RootUser
 }
 
+// requireAdminUser returns a PermissionDenied error, which the gateway
+// translates to a 403, unless the user of the request has the admin role.
+// Otherwise, it returns the user, as whom the RPCs which run SQL statements
+// on behalf of the request run them.
+func (s *adminServer) requireAdminUser(ctx context.Context) (string, error) {
+	username, err := userFromContext(ctx)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, err.Error())
+	}
+	if !s.server.status.hasAdminRole(ctx, username) {
+		return "", status.Errorf(codes.PermissionDenied, "user %q does not have the admin role", username)
+	}
+	return username, nil
+}
+
 // serverError logs the provided error and returns an error that should be returned by
 // the RPC endpoint method.
 func (s *adminServer) serverError(err error) error {
@@ -871,7 +918,7 @@ func (s *adminServer) Events(
 
 // make a best-effort attempt at redacting the setting value.
 func redactSettingsChange(info string) string {
-	var s sql.EventLogSetClusterSettingDetail
+	var s eventpb.SetClusterSetting
 	if err := json.Unmarshal([]byte(info), &s); err != nil {
 		return ""
 	}
@@ -1202,6 +1249,9 @@ func (s *adminServer) Jobs(
 		// Don't show auto stats jobs in the overview page.
 		q.Append(" AND (job_type != $ OR job_type IS NULL)", jobspb.TypeAutoCreateStats.String())
 	}
+	if req.User != "" {
+		q.Append(" AND user_name = $", req.User)
+	}
 	q.Append("ORDER BY created DESC")
 	if req.Limit > 0 {
 		q.Append(" LIMIT $", tree.DInt(req.Limit))
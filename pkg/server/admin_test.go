@@ -13,6 +13,7 @@ package server
 import (
 	"bytes"
 	"context"
+	gosql "database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -103,12 +104,32 @@ func debugURL(s serverutils.TestServerInterface) string {
 	return s.AdminURL() + debug.Endpoint
 }
 
+// grantDebugViewer grants the debug viewer role to the user of the
+// authenticated HTTP client, so that it can access the debug endpoints.
+func grantDebugViewer(t *testing.T, db *gosql.DB) {
+	t.Helper()
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, fmt.Sprintf("CREATE USER IF NOT EXISTS %s", authenticatedUserName))
+	sqlDB.Exec(t, fmt.Sprintf("CREATE ROLE IF NOT EXISTS %s", debugViewerRole))
+	sqlDB.Exec(t, fmt.Sprintf("GRANT %s TO %s", debugViewerRole, authenticatedUserName))
+}
+
+// grantAdmin grants the admin role to the user of the web sessions of tests,
+// which the endpoints restricted to admin users require.
+func grantAdmin(t *testing.T, db *gosql.DB) {
+	t.Helper()
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, fmt.Sprintf("CREATE USER IF NOT EXISTS %s", authenticatedUserName))
+	sqlDB.Exec(t, fmt.Sprintf("GRANT admin TO %s", authenticatedUserName))
+}
+
 // TestAdminDebugExpVar verifies that cmdline and memstats variables are
 // available via the /debug/vars link.
 func TestAdminDebugExpVar(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(context.TODO())
+	grantDebugViewer(t, db)
 
 	jI, err := getJSON(s, debugURL(s)+"vars")
 	if err != nil {
@@ -127,8 +148,9 @@ func TestAdminDebugExpVar(t *testing.T) {
 // available via the /debug/metrics link.
 func TestAdminDebugMetrics(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(context.TODO())
+	grantDebugViewer(t, db)
 
 	jI, err := getJSON(s, debugURL(s)+"metrics")
 	if err != nil {
@@ -147,8 +169,9 @@ func TestAdminDebugMetrics(t *testing.T) {
 // via the /debug/pprof/* links.
 func TestAdminDebugPprof(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(context.TODO())
+	grantDebugViewer(t, db)
 
 	body, err := getText(s, debugURL(s)+"pprof/block?debug=1")
 	if err != nil {
@@ -163,8 +186,9 @@ func TestAdminDebugPprof(t *testing.T) {
 // via /debug/{requests,events}.
 func TestAdminDebugTrace(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(context.TODO())
+	grantDebugViewer(t, db)
 
 	tc := []struct {
 		segment, search string
@@ -188,14 +212,14 @@ func TestAdminDebugTrace(t *testing.T) {
 // incorrect /debug/ paths.
 func TestAdminDebugRedirect(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(context.TODO())
+	grantDebugViewer(t, db)
 
 	expURL := debugURL(s)
 	origURL := expURL + "incorrect"
 
-	// There are no particular permissions on admin endpoints, TestUser is fine.
-	client, err := testutils.NewTestBaseContext(TestUser).GetHTTPClient()
+	client, err := s.GetAuthenticatedHTTPClient()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -226,6 +250,99 @@ func TestAdminDebugRedirect(t *testing.T) {
 	}
 }
 
+// TestAdminDebugRequiresViewer verifies that the debug endpoints are only
+// available to the users with the admin role or the debug viewer role.
+func TestAdminDebugRequiresViewer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, fmt.Sprintf("CREATE USER %s", authenticatedUserName))
+
+	client, err := s.GetAuthenticatedHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus := func(expected int) {
+		t.Helper()
+		resp, err := client.Get(debugURL(s) + "pprof/goroutine?debug=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Fatalf("expected status code %d; got %d", expected, resp.StatusCode)
+		}
+	}
+
+	expectStatus(http.StatusForbidden)
+	sqlDB.Exec(t, fmt.Sprintf("CREATE ROLE %s", debugViewerRole))
+	sqlDB.Exec(t, fmt.Sprintf("GRANT %s TO %s", debugViewerRole, authenticatedUserName))
+	expectStatus(http.StatusOK)
+	sqlDB.Exec(t, fmt.Sprintf("REVOKE %s FROM %s", debugViewerRole, authenticatedUserName))
+	expectStatus(http.StatusForbidden)
+	sqlDB.Exec(t, fmt.Sprintf("GRANT admin TO %s", authenticatedUserName))
+	expectStatus(http.StatusOK)
+}
+
+// TestAdminAPIRequiresAdminRole verifies that the endpoints restricted to
+// admin users deny the requests of the other users.
+func TestAdminAPIRequiresAdminRole(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	for _, tc := range []struct {
+		path     string
+		response protoutil.Message
+	}{
+		{"stmtdiagnostics", &serverpb.StatementDiagnosticsReportsResponse{}},
+		{"stmtdiagnostics/1/bundle", &serverpb.StatementDiagnosticsBundleResponse{}},
+		{"stmtdiagnostics/1/trace", &serverpb.StatementDiagnosticsTraceResponse{}},
+		{"vmodule", &serverpb.VModuleResponse{}},
+		{"decommission_check?node_ids=1", &serverpb.DecommissionCheckResponse{}},
+		{"drain/status", &serverpb.DrainStatusResponse{}},
+		{"execution_insights", &serverpb.ExecutionInsightsResponse{}},
+		{"index_recommendations", &serverpb.IndexRecommendationsResponse{}},
+	} {
+		if err := getAdminJSONProto(s, tc.path, tc.response); !testutils.IsError(err, "403 Forbidden") {
+			t.Errorf("GET %s: expected a 403 error, got: %v", tc.path, err)
+		}
+	}
+	for _, tc := range []struct {
+		path     string
+		request  protoutil.Message
+		response protoutil.Message
+	}{
+		{"stmtdiagnostics", &serverpb.CreateStatementDiagnosticsReportRequest{
+			StatementFingerprint: "SELECT _",
+		}, &serverpb.CreateStatementDiagnosticsReportResponse{}},
+		{"stmtdiagnostics/1/cancel", &serverpb.CancelStatementDiagnosticsReportRequest{},
+			&serverpb.CancelStatementDiagnosticsReportResponse{}},
+		{"vmodule", &serverpb.SetVModuleRequest{VModule: "replica=2"}, &serverpb.VModuleResponse{}},
+		{"cluster_settings/sql.metrics.statement_details.enabled",
+			&serverpb.SetClusterSettingRequest{Value: "false"}, &serverpb.ClusterSettingResponse{}},
+		{"cluster_settings/sql.metrics.statement_details.enabled/reset",
+			&serverpb.ResetClusterSettingRequest{}, &serverpb.ClusterSettingResponse{}},
+		{"drain/phases", &serverpb.DrainPhasesRequest{}, &serverpb.DrainStatusResponse{}},
+		{"jobs/1/pause", &serverpb.ControlJobRequest{}, &serverpb.ControlJobResponse{}},
+		{"jobs/1/resume", &serverpb.ControlJobRequest{}, &serverpb.ControlJobResponse{}},
+		{"jobs/1/cancel", &serverpb.ControlJobRequest{}, &serverpb.ControlJobResponse{}},
+	} {
+		if err := postAdminJSONProto(
+			s, tc.path, tc.request, tc.response,
+		); !testutils.IsError(err, "403 Forbidden") {
+			t.Errorf("POST %s: expected a 403 error, got: %v", tc.path, err)
+		}
+	}
+
+	grantAdmin(t, db)
+	var resp serverpb.VModuleResponse
+	if err := getAdminJSONProto(s, "vmodule", &resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestAdminAPIDatabases(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
@@ -0,0 +1,164 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+const (
+	// apiVersion is the version of the admin and status APIs served by this
+	// binary. It must be bumped whenever a response of these APIs changes in a
+	// way which breaks the existing clients, e.g. when a field is removed or
+	// changes meaning. Adding fields or endpoints doesn't require a bump;
+	// clients discover the endpoints through adminCapabilities instead.
+	apiVersion = 1
+
+	// minAPIVersion is the oldest version of the admin and status APIs which
+	// this binary can still serve. It is kept at least one release behind
+	// apiVersion, so that the clients of the previous release keep working
+	// during rolling upgrades.
+	minAPIVersion = 1
+
+	// apiVersionHeader is the header of the responses of the admin and status
+	// APIs which holds apiVersion. Clients can set it in their requests to the
+	// version they expect, which fails the requests with 406 Not Acceptable
+	// if the node can't serve this version anymore.
+	apiVersionHeader = "Cockroach-API-Version"
+
+	// buildTagHeader is the header of the responses of the admin and status
+	// APIs which holds the build tag of the node.
+	buildTagHeader = "Cockroach-Build-Tag"
+
+	// clusterVersionHeader is the header of the responses of the admin and
+	// status APIs which holds the active cluster version, once known.
+	clusterVersionHeader = "Cockroach-Cluster-Version"
+)
+
+// adminCapabilities reports the versions of the node and the optional
+// endpoints of the admin and status APIs it serves.
+const adminCapabilities = adminPrefix + "capabilities"
+
+// apiFeature is an optional set of endpoints of the admin and status APIs.
+// Nodes running older binaries don't serve them, so clients must check that
+// a node reports a feature before using it, especially during rolling
+// upgrades.
+type apiFeature struct {
+	Name  string   `json:"name"`
+	Paths []string `json:"paths"`
+}
+
+// apiFeatures are the optional features served by this binary.
+var apiFeatures = []apiFeature{
+	{"cluster_hot_ranges", []string{statusClusterHotRanges}},
+	{"cluster_problem_ranges", []string{statusClusterProblemRanges}},
+	{"cluster_settings", []string{adminClusterSettings}},
+	{"clock_offsets", []string{statusClockOffsets}},
+	{"connectivity", []string{statusConnectivity}},
+	{"decommission_check", []string{adminDecommissionCheck}},
+	{"drain", []string{adminDrainStatus, adminDrainPhases}},
+	{"execution_insights", []string{adminExecutionInsights}},
+	{"index_recommendations", []string{adminIndexRecommendations}},
+	{"jobs_control", []string{adminJobs}},
+	{"key_visualizer", []string{statusKeyVisualizer}},
+	{"memory_monitors", []string{statusMemoryMonitors}},
+	{"metric_rules", []string{statusRules}},
+	{"reload_certificates", []string{statusReloadCertificates}},
+	{"replica_gc", []string{statusReplicaGC}},
+	{"snapshots", []string{statusSnapshots}},
+	{"statement_diagnostics", []string{adminStmtDiagnostics}},
+	{"vmodule", []string{adminVModule}},
+}
+
+// capabilitiesResponse is the response of adminCapabilities.
+type capabilitiesResponse struct {
+	NodeID   roachpb.NodeID `json:"node_id"`
+	BuildTag string         `json:"build_tag"`
+	// ServerVersion and MinSupportedVersion are the newest and oldest cluster
+	// versions supported by the binary of the node.
+	ServerVersion       string `json:"server_version"`
+	MinSupportedVersion string `json:"min_supported_version"`
+	// ClusterVersion is the active cluster version, if known. It lags behind
+	// ServerVersion during rolling upgrades.
+	ClusterVersion string `json:"cluster_version,omitempty"`
+	// APIVersion and MinAPIVersion are the newest and oldest versions of the
+	// admin and status APIs served by the node.
+	APIVersion    int          `json:"api_version"`
+	MinAPIVersion int          `json:"min_api_version"`
+	Features      []apiFeature `json:"features"`
+}
+
+// isVersionedAPIPath returns whether the path is one of the admin and status
+// APIs, whose responses are versioned.
+func isVersionedAPIPath(path string) bool {
+	return strings.HasPrefix(path, adminPrefix) || strings.HasPrefix(path, statusPrefix) ||
+		strings.HasPrefix(path, ts.URLPrefix)
+}
+
+// negotiateAPIVersion sets the version headers of the response to a request
+// to the admin and status APIs. It fails the request and returns false if the
+// request expects a version of the APIs which the node can't serve.
+func (s *Server) negotiateAPIVersion(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	h.Set(apiVersionHeader, strconv.Itoa(apiVersion))
+	h.Set(buildTagHeader, build.GetInfo().Tag)
+	if s.st.Version.IsInitialized() {
+		h.Set(clusterVersionHeader, s.st.Version.Version().Version.String())
+	}
+	if v := r.Header.Get(apiVersionHeader); v != "" {
+		requested, err := strconv.Atoi(v)
+		if err != nil || requested < minAPIVersion {
+			http.Error(w, fmt.Sprintf(
+				"API version %q is not supported; this node supports versions %d to %d",
+				v, minAPIVersion, apiVersion), http.StatusNotAcceptable)
+			return false
+		}
+	}
+	return true
+}
+
+// handleCapabilities reports the versions of the node and the optional
+// features of the admin and status APIs it serves, so that clients can
+// adapt to nodes running older or newer binaries during rolling upgrades.
+// Like the health endpoint, it doesn't require authentication.
+func (s *statusServer) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	srv := s.admin.server
+	resp := capabilitiesResponse{
+		NodeID:              s.gossip.NodeID.Get(),
+		BuildTag:            build.GetInfo().Tag,
+		ServerVersion:       srv.st.Version.ServerVersion.String(),
+		MinSupportedVersion: srv.st.Version.MinSupportedVersion.String(),
+		APIVersion:          apiVersion,
+		MinAPIVersion:       minAPIVersion,
+		Features:            apiFeatures,
+	}
+	if srv.st.Version.IsInitialized() {
+		resp.ClusterVersion = srv.st.Version.Version().Version.String()
+	}
+	writeJSONResponse(w, r, resp)
+}
+
+func writeJSONResponse(w http.ResponseWriter, r *http.Request, resp interface{}) {
+	w.Header().Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error(r.Context(), err)
+	}
+}
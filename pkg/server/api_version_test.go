@@ -0,0 +1,85 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAPIVersionNegotiation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// The capabilities endpoint doesn't require a web session.
+	client, err := s.GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path, version string, expected int) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, s.AdminURL()+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != "" {
+			req.Header.Set(apiVersionHeader, version)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != expected {
+			t.Fatalf("%s with version %q: expected status %d, found %d",
+				path, version, expected, resp.StatusCode)
+		}
+		return resp
+	}
+
+	resp := get(adminCapabilities, "", http.StatusOK)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var capabilities capabilitiesResponse
+	if err := json.Unmarshal(body, &capabilities); err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.APIVersion != apiVersion || capabilities.MinAPIVersion != minAPIVersion ||
+		capabilities.ServerVersion != cluster.BinaryServerVersion.String() ||
+		capabilities.ClusterVersion != cluster.BinaryServerVersion.String() ||
+		len(capabilities.Features) != len(apiFeatures) {
+		t.Fatalf("unexpected capabilities: %s", body)
+	}
+	if v := resp.Header.Get(apiVersionHeader); v != strconv.Itoa(apiVersion) {
+		t.Fatalf("expected API version %d, found %q", apiVersion, v)
+	}
+	if v := resp.Header.Get(clusterVersionHeader); v != capabilities.ClusterVersion {
+		t.Fatalf("expected cluster version %s, found %q", capabilities.ClusterVersion, v)
+	}
+
+	// Clients newer than the node are served, but not the ones older than the
+	// versions it supports.
+	get(adminPrefix+"health", strconv.Itoa(apiVersion+1), http.StatusOK).Body.Close()
+	get(adminPrefix+"health", strconv.Itoa(minAPIVersion-1), http.StatusNotAcceptable).Body.Close()
+	get(adminPrefix+"health", "v1", http.StatusNotAcceptable).Body.Close()
+}
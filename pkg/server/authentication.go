@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -45,6 +46,10 @@ const (
 	// secretLength is the number of random bytes generated for session secrets.
 	secretLength      = 16
 	sessionCookieName = "session"
+	// authorizationHeader carries the API key of requests authenticated by
+	// one, prefixed by apiKeyAuthScheme.
+	authorizationHeader = "Authorization"
+	apiKeyAuthScheme    = "Bearer "
 )
 
 var webSessionTimeout = settings.RegisterNonNegativeDurationSetting(
@@ -147,6 +152,32 @@ func (s *authenticationServer) UserLogin(
 	return &serverpb.UserLoginResponse{}, nil
 }
 
+// UserLoginFromSSO creates a web session for the given user, which has been
+// authenticated by a single sign-on provider, and returns the session cookie
+// to set on the response. The user must exist.
+func (s *authenticationServer) UserLoginFromSSO(
+	ctx context.Context, username string,
+) (*http.Cookie, error) {
+	exists, _, err := sql.GetUserHashedPassword(
+		ctx, s.server.execCfg.InternalExecutor, s.memMetrics, username,
+	)
+	if err != nil {
+		return nil, apiInternalError(ctx, err)
+	}
+	if !exists {
+		return nil, errors.Errorf("user %s does not exist", username)
+	}
+
+	id, secret, err := s.newAuthSession(ctx, username)
+	if err != nil {
+		return nil, apiInternalError(ctx, err)
+	}
+	return EncodeSessionCookie(&serverpb.SessionCookie{
+		ID:     id,
+		Secret: secret,
+	})
+}
+
 // UserLogout allows a user to terminate their currently active session.
 func (s *authenticationServer) UserLogout(
 	ctx context.Context, req *serverpb.UserLogoutRequest,
@@ -200,9 +231,44 @@ func (s *authenticationServer) UserLogout(
 func (s *authenticationServer) verifySession(
 	ctx context.Context, cookie *serverpb.SessionCookie,
 ) (bool, string, error) {
+	valid, username, auditInfo, err := s.lookupSession(ctx, cookie)
+	if !valid || err != nil {
+		return false, "", err
+	}
+	// API keys can't be used as session cookies, which would bypass their
+	// scope.
+	if strings.HasPrefix(auditInfo, security.APIKeyAuditInfoPrefix) {
+		return false, "", nil
+	}
+	return true, username, nil
+}
+
+// verifyAPIKey verifies the existence and validity of the API key claimed by
+// the supplied SessionCookie. In addition to the results of verifySession, it
+// returns the scope of the API key.
+func (s *authenticationServer) verifyAPIKey(
+	ctx context.Context, cookie *serverpb.SessionCookie,
+) (bool, string, string, error) {
+	valid, username, auditInfo, err := s.lookupSession(ctx, cookie)
+	if !valid || err != nil {
+		return false, "", "", err
+	}
+	if !strings.HasPrefix(auditInfo, security.APIKeyAuditInfoPrefix) {
+		return false, "", "", nil
+	}
+	return true, username, strings.TrimPrefix(auditInfo, security.APIKeyAuditInfoPrefix), nil
+}
+
+// lookupSession looks up the row of system.web_sessions claimed by the
+// supplied SessionCookie and verifies that it is unexpired, unrevoked and
+// matches the secret of the cookie. If so, it returns the username and audit
+// info of the row.
+func (s *authenticationServer) lookupSession(
+	ctx context.Context, cookie *serverpb.SessionCookie,
+) (bool, string, string, error) {
 	// Look up session in database and verify hashed secret value.
 	const sessionQuery = `
-SELECT "hashedSecret", "username", "expiresAt", "revokedAt"
+SELECT "hashedSecret", "username", "expiresAt", "revokedAt", "auditInfo"
 FROM system.web_sessions
 WHERE id = $1`
 
@@ -211,6 +277,7 @@ WHERE id = $1`
 		username     string
 		expiresAt    time.Time
 		isRevoked    bool
+		auditInfo    string
 	)
 
 	row, err := s.server.internalExecutor.QueryRow(
@@ -218,14 +285,14 @@ WHERE id = $1`
 		"lookup-auth-session",
 		nil /* txn */, sessionQuery, cookie.ID)
 	if row == nil || err != nil {
-		return false, "", err
+		return false, "", "", err
 	}
 
-	if row.Len() != 4 ||
+	if row.Len() != 5 ||
 		row[0].ResolvedType().Family() != types.BytesFamily ||
 		row[1].ResolvedType().Family() != types.StringFamily ||
 		row[2].ResolvedType().Family() != types.TimestampFamily {
-		return false, "", errors.Errorf("values returned from auth session lookup do not match expectation")
+		return false, "", "", errors.Errorf("values returned from auth session lookup do not match expectation")
 	}
 
 	// Extract datum values.
@@ -233,23 +300,26 @@ WHERE id = $1`
 	username = string(*row[1].(*tree.DString))
 	expiresAt = row[2].(*tree.DTimestamp).Time
 	isRevoked = row[3].ResolvedType().Family() != types.UnknownFamily
+	if info, ok := row[4].(*tree.DString); ok {
+		auditInfo = string(*info)
+	}
 
 	if isRevoked {
-		return false, "", nil
+		return false, "", "", nil
 	}
 
 	if now := s.server.clock.PhysicalTime(); !now.Before(expiresAt) {
-		return false, "", nil
+		return false, "", "", nil
 	}
 
 	hasher := sha256.New()
 	_, _ = hasher.Write(cookie.Secret)
 	hashedCookieSecret := hasher.Sum(nil)
 	if !bytes.Equal(hashedSecret, hashedCookieSecret) {
-		return false, "", nil
+		return false, "", "", nil
 	}
 
-	return true, username, nil
+	return true, username, auditInfo, nil
 }
 
 // verifyPassword verifies the passed username/password pair against the
@@ -396,9 +466,16 @@ func makeCookieWithValue(value string) *http.Cookie {
 // getSession decodes the cookie from the request, looks up the corresponding session, and
 // returns the logged in user name. If there's an error, it returns an error value and the
 // HTTP error code.
+//
+// Requests with an API key in their Authorization header are authenticated by
+// the key instead of a cookie.
 func (am *authenticationMux) getSession(
 	w http.ResponseWriter, req *http.Request,
 ) (string, *serverpb.SessionCookie, error) {
+	if auth := req.Header.Get(authorizationHeader); strings.HasPrefix(auth, apiKeyAuthScheme) {
+		return am.getAPIKeySession(req, strings.TrimPrefix(auth, apiKeyAuthScheme))
+	}
+
 	// Validate the returned cookie.
 	rawCookie, err := req.Cookie(sessionCookieName)
 	if err != nil {
@@ -424,6 +501,30 @@ func (am *authenticationMux) getSession(
 	return username, cookie, nil
 }
 
+// getAPIKeySession looks up the session of the given API key, and returns the
+// user name it authenticates. Keys with the read scope are only accepted for
+// read-only requests.
+func (am *authenticationMux) getAPIKeySession(
+	req *http.Request, key string,
+) (string, *serverpb.SessionCookie, error) {
+	id, secret, err := security.DecodeAPIKey(key)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "a valid API key is required")
+	}
+	cookie := &serverpb.SessionCookie{ID: id, Secret: secret}
+	valid, username, scope, err := am.server.verifyAPIKey(req.Context(), cookie)
+	if err != nil {
+		return "", nil, apiInternalError(req.Context(), err)
+	}
+	if !valid {
+		return "", nil, errors.New("the provided API key could not be validated")
+	}
+	if scope != security.APIKeyScopeAll && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return "", nil, errors.Errorf("the provided API key only allows read-only requests")
+	}
+	return username, cookie, nil
+}
+
 func decodeSessionCookie(encodedCookie *http.Cookie) (*serverpb.SessionCookie, error) {
 	// Cookie value should be a base64 encoded protobuf.
 	cookieBytes, err := base64.StdEncoding.DecodeString(encodedCookie.Value)
@@ -121,11 +121,11 @@ func TestSSLEnforcement(t *testing.T) {
 		{adminPrefix + "health", noCertsContext, http.StatusOK},
 		{adminPrefix + "health", insecureContext, http.StatusTemporaryRedirect},
 
-		// /debug/: server.adminServer: no auth.
-		{debug.Endpoint + "vars", rootCertsContext, http.StatusOK},
-		{debug.Endpoint + "vars", nodeCertsContext, http.StatusOK},
-		{debug.Endpoint + "vars", testCertsContext, http.StatusOK},
-		{debug.Endpoint + "vars", noCertsContext, http.StatusOK},
+		// /debug/: debug.Server: web session required.
+		{debug.Endpoint + "vars", rootCertsContext, http.StatusUnauthorized},
+		{debug.Endpoint + "vars", nodeCertsContext, http.StatusUnauthorized},
+		{debug.Endpoint + "vars", testCertsContext, http.StatusUnauthorized},
+		{debug.Endpoint + "vars", noCertsContext, http.StatusUnauthorized},
 		{debug.Endpoint + "vars", insecureContext, http.StatusTemporaryRedirect},
 
 		// /_status/nodes: server.statusServer: no auth.
@@ -764,3 +764,108 @@ func TestGRPCAuthentication(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	if _, err := db.Exec("CREATE USER apiuser"); err != nil {
+		t.Fatal(err)
+	}
+	createKey := func(scope string) string {
+		t.Helper()
+		var key string
+		if err := db.QueryRow(
+			"SELECT crdb_internal.create_api_key('apiuser', $1, '1h')", scope,
+		).Scan(&key); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	readKey := createKey(security.APIKeyScopeRead)
+	allKey := createKey(security.APIKeyScopeAll)
+
+	for _, tc := range []struct {
+		args     string
+		expected string
+	}{
+		{"'apiuser', 'write', '1h'", `unknown API key scope "write"`},
+		{"'apiuser', 'read', '-1h'", "time to live of an API key must be positive"},
+		{"'nobody', 'read', '1h'", "user nobody does not exist"},
+	} {
+		_, err := db.Exec(fmt.Sprintf("SELECT crdb_internal.create_api_key(%s)", tc.args))
+		if !testutils.IsError(err, tc.expected) {
+			t.Errorf("%s: expected error %q, got %v", tc.args, tc.expected, err)
+		}
+	}
+
+	httpClient, err := ts.GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, key string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.AdminURL()+adminPrefix+"databases", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			req.Header.Set(authorizationHeader, apiKeyAuthScheme+key)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := do(http.MethodGet, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without an API key, found %d", http.StatusUnauthorized, code)
+	}
+	if code := do(http.MethodGet, "1.0123"); code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d with an invalid API key, found %d", http.StatusUnauthorized, code)
+	}
+	if code := do(http.MethodGet, readKey); code != http.StatusOK {
+		t.Fatalf("expected status %d with a read API key, found %d", http.StatusOK, code)
+	}
+	// Read API keys are rejected for requests which aren't read-only, before
+	// reaching the handler of the request.
+	if code := do(http.MethodPost, readKey); code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d with a read API key, found %d", http.StatusUnauthorized, code)
+	}
+	if code := do(http.MethodPost, allKey); code == http.StatusUnauthorized {
+		t.Fatalf("unexpected status %d with an API key of scope all", code)
+	}
+
+	// API keys can't be used as session cookies.
+	id, secret, err := security.DecodeAPIKey(readKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, _, err := ts.authentication.verifySession(
+		context.TODO(), &serverpb.SessionCookie{ID: id, Secret: secret},
+	); err != nil || valid {
+		t.Fatalf("unexpected verification of an API key as a session: %t, %v", valid, err)
+	}
+
+	revoke := func() bool {
+		t.Helper()
+		var revoked bool
+		if err := db.QueryRow("SELECT crdb_internal.revoke_api_key($1)", id).Scan(&revoked); err != nil {
+			t.Fatal(err)
+		}
+		return revoked
+	}
+	if !revoke() {
+		t.Fatal("expected the API key to be revoked")
+	}
+	if revoke() {
+		t.Fatal("unexpected second revocation of the API key")
+	}
+	if code := do(http.MethodGet, readKey); code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d with a revoked API key, found %d", http.StatusUnauthorized, code)
+	}
+}
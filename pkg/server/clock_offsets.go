@@ -0,0 +1,109 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// ClockOffsets returns the clock offsets of the node to the other nodes of
+// the cluster, as measured by the RPC heartbeats, along with the maximum
+// offset of the cluster. It allows clock synchronization issues to be
+// detected before they cause the node to terminate.
+func (s *statusServer) ClockOffsets(
+	ctx context.Context, req *serverpb.ClockOffsetsRequest,
+) (*serverpb.ClockOffsetsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ClockOffsets(ctx, req)
+	}
+
+	maxOffset := s.rpcCtx.LocalClock.MaxOffset()
+	resp := &serverpb.ClockOffsetsResponse{
+		NodeID:               s.gossip.NodeID.Get(),
+		MaxOffsetNanos:       maxOffset.Nanoseconds(),
+		ToleratedOffsetNanos: rpc.ToleratedOffset(maxOffset).Nanoseconds(),
+		WarnOffsetNanos:      rpc.WarnOffset(maxOffset).Nanoseconds(),
+		Offsets:              []serverpb.ClockOffsetsResponse_Offset{},
+		Warnings:             []string{},
+	}
+
+	nodeIDs := make(map[string]roachpb.NodeID)
+	for id := range s.nodeLiveness.GetIsLiveMap() {
+		if addr, err := s.gossip.GetNodeIDAddress(id); err == nil {
+			nodeIDs[addr.String()] = id
+		}
+	}
+	// Like for VerifyClockOffset, the offsets aren't checked if the maximum
+	// offset is disabled.
+	checkOffsets := maxOffset != 0 && maxOffset != timeutil.ClocklessMaxOffset
+	latencies := s.rpcCtx.RemoteClocks.AllLatencies()
+	for addr, offset := range s.rpcCtx.RemoteClocks.AllOffsets() {
+		o := serverpb.ClockOffsetsResponse_Offset{
+			NodeID:           nodeIDs[addr],
+			Address:          addr,
+			OffsetNanos:      offset.Offset,
+			UncertaintyNanos: offset.Uncertainty,
+			MeasuredAt:       timeutil.Unix(0, offset.MeasuredAt),
+			LatencyNanos:     latencies[addr].Nanoseconds(),
+			Healthy:          true,
+		}
+		if checkOffsets {
+			absOffset := time.Duration(offset.Offset)
+			if absOffset < 0 {
+				absOffset = -absOffset
+			}
+			o.Healthy = offset.IsHealthy(ctx, maxOffset)
+			o.ApproachesMaximum = absOffset > rpc.WarnOffset(maxOffset)
+		}
+		resp.Offsets = append(resp.Offsets, o)
+	}
+	sort.Slice(resp.Offsets, func(i, j int) bool {
+		a, b := resp.Offsets[i], resp.Offsets[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		return a.Address < b.Address
+	})
+	for _, o := range resp.Offsets {
+		switch {
+		case !o.Healthy:
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf(
+				"offset of %s to n%d (%s) exceeds the tolerated offset of %s",
+				time.Duration(o.OffsetNanos), o.NodeID, o.Address, rpc.ToleratedOffset(maxOffset)))
+		case o.ApproachesMaximum:
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf(
+				"offset of %s to n%d (%s) approaches the tolerated offset of %s",
+				time.Duration(o.OffsetNanos), o.NodeID, o.Address, rpc.ToleratedOffset(maxOffset)))
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestStatusClockOffsets verifies that the clock offsets of the node are
+// available via the /_status/clock_offsets endpoint.
+func TestStatusClockOffsets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Record an offset approaching the maximum offset to a fake node.
+	maxOffset := s.Clock().MaxOffset()
+	ts := s.(*TestServer)
+	ts.RPCContext().RemoteClocks.UpdateOffset(context.TODO(), "fake:26257", rpc.RemoteOffset{
+		Offset:      (maxOffset * 3 / 5).Nanoseconds(),
+		Uncertainty: 1,
+		MeasuredAt:  s.Clock().PhysicalNow(),
+	}, time.Millisecond)
+
+	var resp serverpb.ClockOffsetsResponse
+	if err := getStatusJSONProto(s, "clock_offsets/local", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeID != 1 || resp.MaxOffsetNanos != maxOffset.Nanoseconds() ||
+		resp.ToleratedOffsetNanos != rpc.ToleratedOffset(maxOffset).Nanoseconds() {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	var found bool
+	for _, o := range resp.Offsets {
+		if o.Address != "fake:26257" {
+			continue
+		}
+		found = true
+		if !o.Healthy || !o.ApproachesMaximum || o.NodeID != 0 {
+			t.Errorf("unexpected offset %+v", o)
+		}
+	}
+	if !found || len(resp.Warnings) != 1 {
+		t.Fatalf("expected a warning about the fake node: %+v", resp)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetClusterSetting sets a cluster setting, so that tools which manage the
+// settings of a cluster (e.g. from a configuration repository) don't need a
+// SQL connection and SQL credentials on top of their HTTP ones.
+//
+// The change is deliberately made with SET CLUSTER SETTING rather than by
+// writing to system.settings: running the statement as the user of the
+// request reuses the validation of the values, the version gates and the
+// event log of SQL changes, in which the change is recorded with that user.
+func (s *adminServer) SetClusterSetting(
+	ctx context.Context, req *serverpb.SetClusterSettingRequest,
+) (*serverpb.ClusterSettingResponse, error) {
+	// The value is passed as a string literal, which SET CLUSTER SETTING
+	// converts to the type of the setting.
+	var buf bytes.Buffer
+	lex.EncodeSQLString(&buf, req.Value)
+	return s.changeClusterSetting(ctx, req.Name, func(name string) string {
+		return fmt.Sprintf("SET CLUSTER SETTING %s = %s", name, buf.String())
+	})
+}
+
+// ResetClusterSetting resets a cluster setting to its default value, like
+// RESET CLUSTER SETTING; see SetClusterSetting.
+func (s *adminServer) ResetClusterSetting(
+	ctx context.Context, req *serverpb.ResetClusterSettingRequest,
+) (*serverpb.ClusterSettingResponse, error) {
+	return s.changeClusterSetting(ctx, req.Name, func(name string) string {
+		return fmt.Sprintf("RESET CLUSTER SETTING %s", name)
+	})
+}
+
+// changeClusterSetting runs the statement returned by makeStmt for the
+// cluster setting with the given name, as the user of the request, and
+// returns the value of the setting once the change has been applied on this
+// node.
+func (s *adminServer) changeClusterSetting(
+	ctx context.Context, name string, makeStmt func(name string) string,
+) (*serverpb.ClusterSettingResponse, error) {
+	user, err := s.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.server.AnnotateCtx(ctx)
+	name = strings.ToLower(name)
+	// The name is interpolated in the statement, which is safe once it is
+	// known to be the name of a setting.
+	if _, ok := settings.Lookup(name); !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown cluster setting %q", name)
+	}
+	if _, err := s.server.internalExecutor.ExecWithUser(
+		ctx, "admin-set-cluster-setting", nil /* txn */, user, makeStmt(name),
+	); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	return &serverpb.ClusterSettingResponse{
+		Name:  name,
+		Value: settings.SanitizedValue(name, &s.server.st.SV),
+	}, nil
+}
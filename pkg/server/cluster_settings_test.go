@@ -0,0 +1,70 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAdminAPIClusterSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	grantAdmin(t, db)
+
+	const name = "sql.metrics.statement_details.enabled"
+	set := func(setting, value string, expectedErr string) serverpb.ClusterSettingResponse {
+		t.Helper()
+		var resp serverpb.ClusterSettingResponse
+		if err := postAdminJSONProto(s, "cluster_settings/"+setting,
+			&serverpb.SetClusterSettingRequest{Value: value}, &resp,
+		); !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%s = %s: expected error %q, got: %v", setting, value, expectedErr, err)
+		}
+		return resp
+	}
+
+	set("unknown.setting", "1", "404 Not Found")
+	set(name, "maybe", "400 Bad Request")
+	if resp := set(name, "false", ""); resp.Name != name || resp.Value != "false" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(`SHOW CLUSTER SETTING %s`, name), [][]string{{"false"}})
+
+	// The change is recorded in the event log with the user making it.
+	var info string
+	sqlDB.QueryRow(t, `SELECT info FROM system.eventlog WHERE "eventType" = 'set_cluster_setting'
+ORDER BY timestamp DESC LIMIT 1`).Scan(&info)
+	if !strings.Contains(info, fmt.Sprintf(`"SettingName":"%s"`, name)) ||
+		!strings.Contains(info, `"Value":"false"`) ||
+		!strings.Contains(info, fmt.Sprintf(`"User":"%s"`, authenticatedUserName)) {
+		t.Fatalf("unexpected event %s", info)
+	}
+
+	var resp serverpb.ClusterSettingResponse
+	if err := postAdminJSONProto(s, "cluster_settings/"+name+"/reset",
+		&serverpb.ResetClusterSettingRequest{}, &resp,
+	); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(`SHOW CLUSTER SETTING %s`, name), [][]string{{"true"}})
+}
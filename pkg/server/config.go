@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -35,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/elastic/gosigar"
+	pebblecache "github.com/petermattis/pebble/cache"
 	"github.com/pkg/errors"
 )
 
@@ -151,6 +153,10 @@ type Config struct {
 	// The value is split evenly between the stores if there are more than one.
 	CacheSize int64
 
+	// StorageEngine specifies the engine type (eg. rocksdb, pebble) to use to
+	// instantiate stores.
+	StorageEngine enginepb.EngineType
+
 	// TimeSeriesServerConfig contains configuration specific to the time series
 	// server.
 	TimeSeriesServerConfig ts.ServerConfig
@@ -268,7 +274,21 @@ type Config struct {
 	// the Admin API's HTTP endpoints.
 	EnableWebSessionAuthentication bool
 
+	// DrainWait is the amount of time the server waits in an unready state,
+	// failing health checks, before draining SQL clients. If zero, the
+	// server.shutdown.drain_wait cluster setting applies.
+	DrainWait time.Duration
+
+	// QueryWait is the amount of time the server waits for active queries to
+	// finish while draining SQL clients. If zero, the server.shutdown.query_wait
+	// cluster setting applies.
+	QueryWait time.Duration
+
 	enginesCreated bool
+
+	// diskStallDetector, if set, is passed to the engines created by
+	// CreateEngines.
+	diskStallDetector *engine.DiskStallDetector
 }
 
 // HistogramWindowInterval is used to determine the approximate length of time
@@ -430,6 +450,11 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 	details = append(details, fmt.Sprintf("RocksDB cache size: %s", humanizeutil.IBytes(cfg.CacheSize)))
 	cache := engine.NewRocksDBCache(cfg.CacheSize)
 	defer cache.Release()
+	var pebbleCache *pebblecache.Cache
+	if cfg.StorageEngine == enginepb.EngineTypePebble {
+		pebbleCache = pebblecache.New(cfg.CacheSize)
+		defer pebbleCache.Unref()
+	}
 
 	var physicalStores int
 	for _, spec := range cfg.Stores.Specs {
@@ -463,7 +488,19 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 			}
 			details = append(details, fmt.Sprintf("store %d: in-memory, size %s",
 				i, humanizeutil.IBytes(sizeInBytes)))
-			engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
+			if cfg.StorageEngine == enginepb.EngineTypePebble {
+				eng, err := engine.NewPebble(engine.PebbleConfig{
+					Attrs:        spec.Attributes,
+					MaxSizeBytes: sizeInBytes,
+					Settings:     cfg.Settings,
+				})
+				if err != nil {
+					return Engines{}, err
+				}
+				engines = append(engines, eng)
+			} else {
+				engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
+			}
 		} else {
 			if spec.Size.Percent > 0 {
 				fileSystemUsage := gosigar.FileSystemUsage{}
@@ -477,6 +514,26 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 					spec.Size.Percent, spec.Path, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
 
+			if cfg.StorageEngine == enginepb.EngineTypePebble {
+				details = append(details, fmt.Sprintf("store %d: Pebble, max size %s, max open file limit %d",
+					i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
+				opts := engine.DefaultPebbleOptions()
+				opts.Cache = pebbleCache
+				opts.MaxOpenFiles = int(openFileLimitPerStore)
+				eng, err := engine.NewPebble(engine.PebbleConfig{
+					Dir:          spec.Path,
+					Attrs:        spec.Attributes,
+					MaxSizeBytes: sizeInBytes,
+					Settings:     cfg.Settings,
+					Opts:         opts,
+				})
+				if err != nil {
+					return Engines{}, err
+				}
+				engines = append(engines, eng)
+				continue
+			}
+
 			details = append(details, fmt.Sprintf("store %d: RocksDB, max size %s, max open file limit %d",
 				i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
 			rocksDBConfig := engine.RocksDBConfig{
@@ -486,6 +543,7 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 				MaxOpenFiles:            openFileLimitPerStore,
 				WarnLargeBatchThreshold: 500 * time.Millisecond,
 				Settings:                cfg.Settings,
+				DiskStallDetector:       cfg.diskStallDetector,
 				UseFileRegistry:         spec.UseFileRegistry,
 				RocksDBOptions:          spec.RocksDBOptions,
 				ExtraOptions:            spec.ExtraOptions,
@@ -0,0 +1,103 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// The latencies between two nodes are reported as asymmetric when the
+// latency in one direction is both asymmetricLatencyRatio times and
+// minAsymmetricLatencyDiff more than the latency in the other direction.
+const (
+	asymmetricLatencyRatio   = 2
+	minAsymmetricLatencyDiff = 5 * time.Millisecond
+)
+
+// Connectivity returns the matrix of the round-trip latencies between all
+// the nodes of the cluster, for example to render a latency heatmap. The
+// latencies are those of the RPC heartbeats between the nodes, as last
+// recorded in the node statuses, so they may be a few seconds old.
+func (s *statusServer) Connectivity(
+	ctx context.Context, req *serverpb.ConnectivityRequest,
+) (*serverpb.ConnectivityResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	nodes, err := s.NodesWithLiveness(ctx)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	return makeConnectivity(nodes), nil
+}
+
+// makeConnectivity builds the latency matrix of the given nodes.
+func makeConnectivity(
+	nodes map[roachpb.NodeID]NodeStatusWithLiveness,
+) *serverpb.ConnectivityResponse {
+	resp := &serverpb.ConnectivityResponse{
+		Nodes:      []serverpb.ConnectivityResponse_Node{},
+		Latencies:  make(map[roachpb.NodeID]serverpb.ConnectivityResponse_Latencies),
+		Unmeasured: []serverpb.ConnectivityResponse_Pair{},
+		Asymmetric: []serverpb.ConnectivityResponse_Pair{},
+	}
+	nodeIDs := make([]roachpb.NodeID, 0, len(nodes))
+	for nodeID := range nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+
+	for _, from := range nodeIDs {
+		node := nodes[from]
+		resp.Nodes = append(resp.Nodes, serverpb.ConnectivityResponse_Node{
+			NodeID:    from,
+			Address:   node.Desc.Address.String(),
+			Locality:  node.Desc.Locality.String(),
+			Liveness:  node.LivenessStatus.String(),
+			UpdatedAt: node.UpdatedAt,
+		})
+		latencies := make(map[roachpb.NodeID]int64)
+		for _, to := range nodeIDs {
+			if to == from {
+				continue
+			}
+			if latency := node.Activity[to].Latency; latency > 0 {
+				latencies[to] = latency
+			} else if node.LivenessStatus == storagepb.NodeLivenessStatus_LIVE &&
+				nodes[to].LivenessStatus == storagepb.NodeLivenessStatus_LIVE {
+				resp.Unmeasured = append(resp.Unmeasured, serverpb.ConnectivityResponse_Pair{From: from, To: to})
+			}
+		}
+		resp.Latencies[from] = serverpb.ConnectivityResponse_Latencies{Latencies: latencies}
+	}
+
+	for _, from := range nodeIDs {
+		for _, to := range nodeIDs {
+			latency, ok := resp.Latencies[from].Latencies[to]
+			reverse, reverseOK := resp.Latencies[to].Latencies[from]
+			if !ok || !reverseOK {
+				continue
+			}
+			if latency > asymmetricLatencyRatio*reverse &&
+				latency-reverse > minAsymmetricLatencyDiff.Nanoseconds() {
+				resp.Asymmetric = append(resp.Asymmetric, serverpb.ConnectivityResponse_Pair{From: from, To: to})
+			}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestMakeConnectivity(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	node := func(
+		nodeID roachpb.NodeID, liveness storagepb.NodeLivenessStatus, latencies map[roachpb.NodeID]time.Duration,
+	) NodeStatusWithLiveness {
+		activity := make(map[roachpb.NodeID]statuspb.NodeStatus_NetworkActivity)
+		for to, latency := range latencies {
+			activity[to] = statuspb.NodeStatus_NetworkActivity{Latency: latency.Nanoseconds()}
+		}
+		return NodeStatusWithLiveness{
+			NodeStatus: statuspb.NodeStatus{
+				Desc:     roachpb.NodeDescriptor{NodeID: nodeID},
+				Activity: activity,
+			},
+			LivenessStatus: liveness,
+		}
+	}
+	live := storagepb.NodeLivenessStatus_LIVE
+	nodes := map[roachpb.NodeID]NodeStatusWithLiveness{
+		1: node(1, live, map[roachpb.NodeID]time.Duration{2: time.Millisecond, 3: 50 * time.Millisecond}),
+		2: node(2, live, map[roachpb.NodeID]time.Duration{1: 2 * time.Millisecond}),
+		3: node(3, live, map[roachpb.NodeID]time.Duration{1: 10 * time.Millisecond}),
+		4: node(4, storagepb.NodeLivenessStatus_DEAD, nil),
+	}
+
+	resp := makeConnectivity(nodes)
+	var nodeIDs []roachpb.NodeID
+	for _, n := range resp.Nodes {
+		nodeIDs = append(nodeIDs, n.NodeID)
+	}
+	if expected := []roachpb.NodeID{1, 2, 3, 4}; !reflect.DeepEqual(nodeIDs, expected) {
+		t.Errorf("expected nodes %v, got %v", expected, nodeIDs)
+	}
+	if latency := resp.Latencies[1].Latencies[3]; latency != (50 * time.Millisecond).Nanoseconds() {
+		t.Errorf("unexpected latency from n1 to n3: %d", latency)
+	}
+	if len(resp.Latencies[4].Latencies) != 0 {
+		t.Errorf("unexpected latencies from n4: %v", resp.Latencies[4])
+	}
+	type pair = serverpb.ConnectivityResponse_Pair
+	// The dead node isn't expected to have latency measurements.
+	if expected := []pair{{From: 2, To: 3}, {From: 3, To: 2}}; !reflect.DeepEqual(resp.Unmeasured, expected) {
+		t.Errorf("expected unmeasured pairs %v, got %v", expected, resp.Unmeasured)
+	}
+	// The latencies between n1 and n2 differ by less than the minimum
+	// difference.
+	if expected := []pair{{From: 1, To: 3}}; !reflect.DeepEqual(resp.Asymmetric, expected) {
+		t.Errorf("expected asymmetric pairs %v, got %v", expected, resp.Asymmetric)
+	}
+}
+
+// TestStatusConnectivity verifies that the latency matrix of the cluster is
+// available via the /_status/connectivity endpoint.
+func TestStatusConnectivity(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	testutils.SucceedsSoon(t, func() error {
+		var resp serverpb.ConnectivityResponse
+		if err := getStatusJSONProto(s, "connectivity", &resp); err != nil {
+			return err
+		}
+		if len(resp.Nodes) != 1 {
+			return errors.Errorf("expected a single node, got %+v", resp.Nodes)
+		}
+		if n := resp.Nodes[0]; n.NodeID != 1 {
+			t.Fatalf("unexpected node %+v", n)
+		} else if n.Liveness != storagepb.NodeLivenessStatus_LIVE.String() {
+			return errors.Errorf("expected n1 to be live, got %s", n.Liveness)
+		}
+		if len(resp.Latencies[1].Latencies) != 0 || len(resp.Unmeasured) != 0 || len(resp.Asymmetric) != 0 {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		return nil
+	})
+}
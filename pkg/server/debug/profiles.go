@@ -0,0 +1,78 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package debug
+
+import (
+	"runtime"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+)
+
+// BlockProfileRate overrides the sampling rate of the block profile, which
+// is otherwise set at startup by DefaultBlockProfileRate.
+var BlockProfileRate = settings.RegisterIntSetting(
+	"server.debug.block_profile_rate",
+	"if positive, the block profile samples one event per this many nanoseconds spent blocking; "+
+		"if negative, block profiling is disabled; "+
+		"if 0, the rate set by COCKROACH_BLOCK_PROFILE_RATE at startup is used",
+	0,
+)
+
+// MutexProfileFraction overrides the sampling fraction of the mutex profile,
+// which is otherwise set at startup by DefaultMutexProfileFraction.
+var MutexProfileFraction = settings.RegisterIntSetting(
+	"server.debug.mutex_profile_fraction",
+	"if positive, the mutex profile samples on average one in this many mutex contention events; "+
+		"if negative, mutex profiling is disabled; "+
+		"if 0, the fraction set by COCKROACH_MUTEX_PROFILE_RATE at startup is used",
+	0,
+)
+
+// DefaultBlockProfileRate returns the sampling rate of the block profile set
+// at startup. Smaller values provide more accurate profiles but are more
+// expensive. 0 and 1 are special: 0 disables the block profile and 1
+// captures 100% of block events. For other values, the profiler will sample
+// one event per X nanoseconds spent blocking.
+func DefaultBlockProfileRate() int {
+	return envutil.EnvOrDefaultInt("COCKROACH_BLOCK_PROFILE_RATE",
+		10000000 /* 1 sample per 10 milliseconds spent blocking */)
+}
+
+// DefaultMutexProfileFraction returns the sampling fraction of the mutex
+// profile set at startup. Smaller values provide more accurate profiles but
+// are more expensive. 0 and 1 are special: 0 disables the mutex profile and
+// 1 captures 100% of mutex contention events. For other values, the profiler
+// will sample on average 1/X events.
+func DefaultMutexProfileFraction() int {
+	return envutil.EnvOrDefaultInt("COCKROACH_MUTEX_PROFILE_RATE",
+		1000 /* 1 sample per 1000 mutex contention events */)
+}
+
+// profileRate returns the rate to use given the value of one of the above
+// settings and the default rate.
+func profileRate(setting int64, defaultRate int) int {
+	switch {
+	case setting > 0:
+		return int(setting)
+	case setting < 0:
+		return 0
+	default:
+		return defaultRate
+	}
+}
+
+// applyProfileRates sets the block and mutex profile rates of the process
+// according to the settings.
+func applyProfileRates(sv *settings.Values) {
+	runtime.SetBlockProfileRate(profileRate(BlockProfileRate.Get(sv), DefaultBlockProfileRate()))
+	runtime.SetMutexProfileFraction(profileRate(MutexProfileFraction.Get(sv), DefaultMutexProfileFraction()))
+}
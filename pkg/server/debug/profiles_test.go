@@ -0,0 +1,58 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package debug
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestProfileRates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// A negative fraction reads the current one without changing it. The
+	// block profile rate can't be read, and is disabled by default.
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(-1))
+	defer runtime.SetBlockProfileRate(0)
+
+	st := cluster.MakeTestingClusterSettings()
+	_ = NewServer(st)
+
+	MutexProfileFraction.Override(&st.SV, 7)
+	if f := runtime.SetMutexProfileFraction(-1); f != 7 {
+		t.Fatalf("expected mutex profile fraction 7, found %d", f)
+	}
+	MutexProfileFraction.Override(&st.SV, -1)
+	if f := runtime.SetMutexProfileFraction(-1); f != 0 {
+		t.Fatalf("expected mutex profiling to be disabled, found fraction %d", f)
+	}
+	MutexProfileFraction.Override(&st.SV, 0)
+	if f, e := runtime.SetMutexProfileFraction(-1), DefaultMutexProfileFraction(); f != e {
+		t.Fatalf("expected default mutex profile fraction %d, found %d", e, f)
+	}
+
+	for _, tc := range []struct {
+		setting     int64
+		defaultRate int
+		expected    int
+	}{
+		{0, 1000, 1000},
+		{5, 1000, 5},
+		{-1, 1000, 0},
+	} {
+		if r := profileRate(tc.setting, tc.defaultRate); r != tc.expected {
+			t.Errorf("profileRate(%d, %d) = %d, expected %d", tc.setting, tc.defaultRate, r, tc.expected)
+		}
+	}
+}
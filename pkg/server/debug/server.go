@@ -82,6 +82,11 @@ type Server struct {
 func NewServer(st *cluster.Settings) *Server {
 	mux := http.NewServeMux()
 
+	// Let the block and mutex profile rates set at startup be overridden, so
+	// that these profiles can be enabled on a live cluster.
+	BlockProfileRate.SetOnChange(&st.SV, func() { applyProfileRates(&st.SV) })
+	MutexProfileFraction.SetOnChange(&st.SV, func() { applyProfileRates(&st.SV) })
+
 	// Install a redirect to the UI's collection of debug tools.
 	mux.HandleFunc(Endpoint, handleLanding)
 
@@ -0,0 +1,103 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DecommissionCheck answers whether all ranges would still satisfy their
+// zone configs if the given nodes were decommissioned. For each range with
+// voting replicas on those nodes, the allocator of a local store chooses
+// replacement replicas on the other live nodes, without carrying out any
+// changes. Like the allocator itself, the check relies on the store
+// descriptors gossiped by the nodes, so it reflects the current capacity and
+// topology of the cluster.
+func (s *adminServer) DecommissionCheck(
+	ctx context.Context, req *serverpb.DecommissionCheckRequest,
+) (*serverpb.DecommissionCheckResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	ctx = s.server.AnnotateCtx(ctx)
+	if len(req.NodeIDs) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "node_ids must be specified")
+	}
+	decommissioning := make(map[roachpb.NodeID]bool)
+	for _, nodeID := range req.NodeIDs {
+		if nodeID <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid node ID %d", nodeID)
+		}
+		decommissioning[nodeID] = true
+	}
+
+	cfg := s.server.gossip.GetSystemConfig()
+	if cfg == nil {
+		return nil, status.Errorf(codes.Unavailable, "system config not yet available")
+	}
+	var store *storage.Store
+	_ = s.server.node.stores.VisitStores(func(st *storage.Store) error {
+		if store == nil {
+			store = st
+		}
+		return nil
+	})
+	if store == nil {
+		return nil, status.Errorf(codes.Unavailable, "no local store available")
+	}
+	kvs, err := s.server.db.Scan(ctx, keys.Meta2Prefix, keys.MetaMax, 0 /* maxRows */)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+
+	resp := &serverpb.DecommissionCheckResponse{
+		NodeIDs:       req.NodeIDs,
+		Unsatisfiable: []serverpb.DecommissionCheckResponse_Range{},
+	}
+	for _, kv := range kvs {
+		var desc roachpb.RangeDescriptor
+		if err := kv.ValueProto(&desc); err != nil {
+			return nil, s.serverError(err)
+		}
+		resp.RangesChecked++
+		affected := false
+		for _, repl := range desc.Replicas().Voters() {
+			affected = affected || decommissioning[repl.NodeID]
+		}
+		if !affected {
+			continue
+		}
+		resp.RangesAffected++
+		zone, err := cfg.GetZoneConfigForKey(desc.StartKey)
+		if err != nil {
+			return nil, s.serverError(err)
+		}
+		targets, err := store.AllocatorSimulateDecommission(ctx, &desc, zone, decommissioning)
+		if err != nil {
+			resp.Unsatisfiable = append(resp.Unsatisfiable, serverpb.DecommissionCheckResponse_Range{
+				RangeID:  desc.RangeID,
+				StartKey: desc.StartKey.String(),
+				Replicas: desc.Replicas().Voters(),
+				Targets:  targets,
+				Error:    err.Error(),
+			})
+		}
+	}
+	resp.OK = len(resp.Unsatisfiable) == 0
+	return resp, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAdminAPIDecommissionCheck(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	grantAdmin(t, db)
+
+	check := func(query string, expectedErr string) serverpb.DecommissionCheckResponse {
+		t.Helper()
+		var resp serverpb.DecommissionCheckResponse
+		err := getAdminJSONProto(s, "decommission_check"+query, &resp)
+		if !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%s: expected error %q, got: %v", query, expectedErr, err)
+		}
+		return resp
+	}
+
+	check("", "400 Bad Request")
+	check("?node_ids=1&node_ids=0", "400 Bad Request")
+
+	// No range has replicas on an unknown node.
+	resp := check("?node_ids=2", "")
+	if !resp.OK || resp.RangesChecked == 0 || resp.RangesAffected != 0 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// The only node of the cluster can't be decommissioned.
+	resp = check("?node_ids=1", "")
+	if resp.OK || resp.RangesAffected != resp.RangesChecked ||
+		len(resp.Unsatisfiable) != int(resp.RangesAffected) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if r := resp.Unsatisfiable[0]; len(r.Replicas) != 1 || r.Replicas[0].NodeID != 1 || r.Error == "" {
+		t.Fatalf("unexpected range %+v", r)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultLeaseDrainWait is the default timeout of the lease transfer phase of
+// DrainPhases.
+const defaultLeaseDrainWait = 10 * time.Second
+
+// The phases of a drain, in the order in which they are executed by
+// DrainPhases.
+const (
+	// drainPhaseUnready fails the health checks of the node, so that load
+	// balancers stop routing new SQL clients to it.
+	drainPhaseUnready = "unready"
+	// drainPhaseSQL stops accepting new SQL connections and waits for active
+	// queries to finish, canceling the connections which remain.
+	drainPhaseSQL = "sql"
+	// drainPhaseLeases transfers the range leases of the node away.
+	drainPhaseLeases = "leases"
+)
+
+// DrainStatus reports the drain state of the node and the work remaining
+// before it can be terminated.
+func (s *adminServer) DrainStatus(
+	ctx context.Context, req *serverpb.DrainStatusRequest,
+) (*serverpb.DrainStatusResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	return s.server.drainStatus(nil /* phases */), nil
+}
+
+// DrainPhases drains the node, executing and reporting the unready, sql and
+// leases phases in turn. A phase which times out doesn't prevent the next one
+// from being executed; its remaining work is reported instead. Draining is
+// idempotent, and can be undone with the Drain RPC.
+func (s *adminServer) DrainPhases(
+	ctx context.Context, req *serverpb.DrainPhasesRequest,
+) (*serverpb.DrainStatusResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	srv := s.server
+	waits := []struct {
+		wait *time.Duration
+		def  time.Duration
+	}{
+		{req.UnreadyWait, srv.drainWait()},
+		{req.QueryWait, srv.queryWait()},
+		{req.LeaseWait, defaultLeaseDrainWait},
+	}
+	var durations [3]time.Duration
+	for i, w := range waits {
+		durations[i] = w.def
+		if w.wait != nil {
+			if *w.wait < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "drain waits must not be negative")
+			}
+			durations[i] = *w.wait
+		}
+	}
+	ctx = srv.AnnotateCtx(ctx)
+	return srv.drainStatus(srv.drainPhases(ctx, durations[0], durations[1], durations[2])), nil
+}
+
+// drainStatus returns the drain state of the node, along with the given
+// phases executed by the request.
+func (s *Server) drainStatus(
+	phases []serverpb.DrainStatusResponse_Phase,
+) *serverpb.DrainStatusResponse {
+	resp := &serverpb.DrainStatusResponse{
+		DrainingSQL:    s.pgServer.IsDraining(),
+		DrainingLeases: s.node.IsDraining(),
+		Phases:         phases,
+		Remaining:      s.drainRemaining(),
+	}
+	resp.SafeToTerminate = resp.DrainingSQL && resp.DrainingLeases &&
+		resp.Remaining == serverpb.DrainStatusResponse_Remaining{}
+	return resp
+}
+
+// drainPhases drains the node phase by phase, and reports the outcome and
+// the remaining work of each phase.
+func (s *Server) drainPhases(
+	ctx context.Context, unreadyWait, queryWait, leaseWait time.Duration,
+) []serverpb.DrainStatusResponse_Phase {
+	log.Ops.Infof(ctx, "draining in phases")
+	phases := []struct {
+		name string
+		fn   func() (timedOut bool, _ error)
+	}{
+		{drainPhaseUnready, func() (bool, error) {
+			s.drainUnready(ctx, unreadyWait)
+			return false, nil
+		}},
+		{drainPhaseSQL, func() (bool, error) {
+			err := s.drainClients(ctx, queryWait)
+			return s.pgServer.NumOpenConns() > 0, err
+		}},
+		{drainPhaseLeases, func() (bool, error) {
+			return s.drainLeases(ctx, leaseWait)
+		}},
+	}
+	results := make([]serverpb.DrainStatusResponse_Phase, 0, len(phases))
+	for _, phase := range phases {
+		start := timeutil.Now()
+		timedOut, err := phase.fn()
+		res := serverpb.DrainStatusResponse_Phase{
+			Phase:     phase.name,
+			Duration:  timeutil.Since(start),
+			TimedOut:  timedOut,
+			Remaining: s.drainRemaining(),
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		log.Ops.Infof(ctx, "drain phase %s: %+v", phase.name, res)
+		results = append(results, res)
+	}
+	return results
+}
+
+// drainUnready fails the health checks of the node, and waits for the given
+// duration so that load balancers notice and client traffic moves off the
+// node.
+func (s *Server) drainUnready(ctx context.Context, wait time.Duration) {
+	s.grpc.setMode(modeDraining)
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+	}
+}
+
+// drainClients stops accepting new SQL connections, waits up to queryWait for
+// the active queries and DistSQL flows to finish, and cancels the remaining
+// connections.
+func (s *Server) drainClients(ctx context.Context, queryWait time.Duration) error {
+	// Since enabling the lease manager's draining mode will prevent the
+	// acquisition of new leases, the switch must be made after the pgServer
+	// has given sessions a chance to finish ongoing work.
+	defer s.leaseMgr.SetDraining(true)
+
+	if err := s.pgServer.Drain(queryWait); err != nil {
+		return err
+	}
+	s.distSQLServer.Drain(ctx, queryWait)
+	return nil
+}
+
+// drainLeases marks the node as draining in its liveness record and
+// transfers its range leases away, waiting up to leaseWait for the transfers.
+// It returns whether the transfers timed out; they proceed in the background
+// in that case.
+func (s *Server) drainLeases(ctx context.Context, leaseWait time.Duration) (bool, error) {
+	s.nodeLiveness.SetDraining(ctx, true)
+	errC := make(chan error, 1)
+	if err := s.stopper.RunAsyncTask(ctx, "drain-leases", func(context.Context) {
+		errC <- s.node.SetDraining(true)
+	}); err != nil {
+		return false, err
+	}
+	select {
+	case err := <-errC:
+		return false, err
+	case <-time.After(leaseWait):
+		return true, nil
+	case <-ctx.Done():
+		return false, errors.Wrap(ctx.Err(), "while transferring leases")
+	}
+}
+
+// drainRemaining returns the work remaining on the node before it can be
+// terminated without affecting clients.
+func (s *Server) drainRemaining() serverpb.DrainStatusResponse_Remaining {
+	r := serverpb.DrainStatusResponse_Remaining{SQLConnections: int32(s.pgServer.NumOpenConns())}
+	for _, session := range s.sessionRegistry.SerializeAll() {
+		r.ActiveQueries += int32(len(session.ActiveQueries))
+	}
+	_ = s.node.stores.VisitStores(func(store *storage.Store) error {
+		r.Leases += int32(store.NumTransferableLeases())
+		return nil
+	})
+	return r
+}
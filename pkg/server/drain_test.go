@@ -0,0 +1,88 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+// TestAdminAPIDrainPhases calls the RPCs directly, as root: once the node is
+// draining, it can't acquire the leases needed to authenticate web sessions
+// and check their roles.
+func TestAdminAPIDrainPhases(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+	ctx := context.Background()
+
+	status := func() *serverpb.DrainStatusResponse {
+		t.Helper()
+		resp, err := ts.admin.DrainStatus(ctx, &serverpb.DrainStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := status(); resp.DrainingSQL || resp.DrainingLeases || resp.SafeToTerminate ||
+		len(resp.Phases) != 0 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	negative := -time.Second
+	if _, err := ts.admin.DrainPhases(ctx, &serverpb.DrainPhasesRequest{
+		LeaseWait: &negative,
+	}); !testutils.IsError(err, "must not be negative") {
+		t.Fatalf("expected a negative wait to be rejected, got: %v", err)
+	}
+
+	unreadyWait, queryWait, leaseWait := time.Duration(0), time.Second, 10*time.Second
+	resp, err := ts.admin.DrainPhases(ctx, &serverpb.DrainPhasesRequest{
+		UnreadyWait: &unreadyWait,
+		QueryWait:   &queryWait,
+		LeaseWait:   &leaseWait,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.DrainingSQL || !resp.DrainingLeases {
+		t.Fatalf("expected the node to be draining: %+v", resp)
+	}
+	var phases []string
+	for _, p := range resp.Phases {
+		phases = append(phases, p.Phase)
+		if p.Error != "" {
+			t.Errorf("unexpected error in phase %s: %s", p.Phase, p.Error)
+		}
+	}
+	if e, a := []string{drainPhaseUnready, drainPhaseSQL, drainPhaseLeases}, phases; len(a) != len(e) ||
+		a[0] != e[0] || a[1] != e[1] || a[2] != e[2] {
+		t.Fatalf("expected phases %v, got %v", e, a)
+	}
+	// The leases of a single node cluster can't be transferred away.
+	if p := resp.Phases[2]; p.TimedOut || p.Remaining.Leases != 0 {
+		t.Fatalf("unexpected lease phase: %+v", p)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		if resp := status(); !resp.SafeToTerminate {
+			return errors.Errorf("expected the node to be safe to terminate: %+v", resp)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ExecutionInsights returns the execution insights of
+// crdb_internal.node_execution_insights, most recent first. They are queried
+// as the user of the request.
+func (s *adminServer) ExecutionInsights(
+	ctx context.Context, req *serverpb.ExecutionInsightsRequest,
+) (*serverpb.ExecutionInsightsResponse, error) {
+	user, err := s.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.server.AnnotateCtx(ctx)
+	rows, _ /* cols */, err := s.server.internalExecutor.QueryWithUser(
+		ctx, "admin-execution-insights", nil /* txn */, user,
+		`SELECT query_id, session_id, txn_id, app_name, user_name, database_name, fingerprint,
+       plan_gist, start, service_latency, baseline_latency, retries, rows_read, full_scans,
+       causes, details
+FROM crdb_internal.node_execution_insights
+ORDER BY start DESC`,
+	)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	resp := &serverpb.ExecutionInsightsResponse{
+		Insights: make([]serverpb.ExecutionInsightsResponse_Insight, 0, len(rows)),
+	}
+	for _, row := range rows {
+		insight := serverpb.ExecutionInsightsResponse_Insight{
+			QueryID:        string(tree.MustBeDString(row[0])),
+			SessionID:      string(tree.MustBeDString(row[1])),
+			AppName:        string(tree.MustBeDString(row[3])),
+			User:           string(tree.MustBeDString(row[4])),
+			Database:       string(tree.MustBeDString(row[5])),
+			Fingerprint:    string(tree.MustBeDString(row[6])),
+			PlanGist:       string(tree.MustBeDString(row[7])),
+			Start:          row[8].(*tree.DTimestamp).Time,
+			ServiceLatency: time.Duration(row[9].(*tree.DInterval).Nanos()),
+			Retries:        int64(tree.MustBeDInt(row[11])),
+			RowsRead:       int64(tree.MustBeDInt(row[12])),
+			FullScans:      stringsFromDArray(row[13]),
+			Causes:         stringsFromDArray(row[14]),
+			Details:        string(tree.MustBeDString(row[15])),
+		}
+		if row[2] != tree.DNull {
+			insight.TxnID = row[2].(*tree.DUuid).UUID.String()
+		}
+		if row[10] != tree.DNull {
+			insight.BaselineLatency = time.Duration(row[10].(*tree.DInterval).Nanos())
+		}
+		resp.Insights = append(resp.Insights, insight)
+	}
+	return resp, nil
+}
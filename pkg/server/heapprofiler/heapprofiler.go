@@ -18,11 +18,14 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
@@ -39,12 +42,63 @@ var (
 			"Profiles with lower score are GC'ed, but latest profile is always kept.",
 		5,
 	)
+
+	rssThresholds = settings.RegisterValidatedStringSetting(
+		"server.heap_profile.rss_thresholds",
+		"comma-separated list of process RSS thresholds, as sizes or percentages of the "+
+			"system memory, above which a heap profile is taken when the RSS crosses them",
+		"75%,90%",
+		func(_ *settings.Values, s string) error {
+			_, err := parseRSSThresholds(s, 1 /* totalMemory */)
+			return err
+		},
+	)
+
+	maxRSSProfiles = settings.RegisterIntSetting(
+		"server.heap_profile.max_rss_profiles",
+		"maximum number of profiles taken when the RSS crosses a threshold to be kept. "+
+			"The oldest profiles are GC'ed.",
+		5,
+	)
 )
 
+// parseRSSThresholds parses a comma-separated list of RSS thresholds, which
+// are either sizes or percentages of totalMemory, into a sorted list of sizes.
+func parseRSSThresholds(s string, totalMemory int64) ([]uint64, error) {
+	var thresholds []uint64
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		var threshold int64
+		if strings.HasSuffix(t, "%") {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(t, "%"), 64)
+			if err != nil || percent <= 0 || percent > 100 {
+				return nil, errors.Errorf("invalid RSS threshold %q: percentages must be in (0, 100]", t)
+			}
+			threshold = int64(float64(totalMemory) * percent / 100)
+		} else {
+			var err error
+			threshold, err = humanizeutil.ParseBytes(t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid RSS threshold %q", t)
+			}
+			if threshold <= 0 {
+				return nil, errors.Errorf("invalid RSS threshold %q: sizes must be positive", t)
+			}
+		}
+		thresholds = append(thresholds, uint64(threshold))
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	return thresholds, nil
+}
+
 type testingKnobs struct {
-	dontWriteProfiles    bool
-	maybeTakeProfileHook func(willTakeProfile bool)
-	now                  func() time.Time
+	dontWriteProfiles       bool
+	maybeTakeProfileHook    func(willTakeProfile bool)
+	maybeTakeRSSProfileHook func(willTakeProfile bool)
+	now                     func() time.Time
 }
 
 // HeapProfiler is used to take heap profiles.
@@ -63,25 +117,36 @@ type HeapProfiler struct {
 	// highwaterMarkBytes represents the maximum heap size that we've seen since
 	// resetting the filed (which happens periodically).
 	highwaterMarkBytes uint64
+	// totalMemory is the system memory, to which percentage RSS thresholds
+	// are relative.
+	totalMemory int64
+	// rssThreshold is the highest RSS threshold exceeded at the last call to
+	// MaybeTakeProfile, or zero. A profile is taken when a higher threshold is
+	// exceeded.
+	rssThreshold uint64
 
 	knobs testingKnobs
 }
 
 // NewHeapProfiler creates a HeapProfiler. dir is the directory in which
-// profiles are to be stored.
-func NewHeapProfiler(dir string, st *cluster.Settings) (*HeapProfiler, error) {
+// profiles are to be stored. totalMemory is the system memory.
+func NewHeapProfiler(dir string, st *cluster.Settings, totalMemory int64) (*HeapProfiler, error) {
 	if dir == "" {
 		return nil, errors.Errorf("need to specify dir for NewHeapProfiler")
 	}
 	hp := &HeapProfiler{
-		dir: dir,
-		st:  st,
+		dir:         dir,
+		st:          st,
+		totalMemory: totalMemory,
 	}
 	return hp, nil
 }
 
-// MaybeTakeProfile takes a heap profile if the heap is big enough.
-func (o *HeapProfiler) MaybeTakeProfile(ctx context.Context, ms runtime.MemStats) {
+// MaybeTakeProfile takes a heap profile if the heap is big enough, or if the
+// process RSS crossed one of the thresholds of server.heap_profile.rss_thresholds
+// since the last call.
+func (o *HeapProfiler) MaybeTakeProfile(ctx context.Context, ms runtime.MemStats, rss uint64) {
+	o.maybeTakeRSSProfile(ctx, rss)
 	// If it's been too long since we took a profile, make sure we'll take one now.
 	if o.now().Sub(o.lastProfileTime) > resetHighWaterMarkInterval {
 		o.highwaterMarkBytes = 0
@@ -107,9 +172,47 @@ func (o *HeapProfiler) MaybeTakeProfile(ctx context.Context, ms runtime.MemStats
 	fileName := fmt.Sprintf("%s%018d_%s", filePrefix, curHeap, o.now().Format(format))
 	path := filepath.Join(o.dir, fileName)
 	takeHeapProfile(ctx, path)
-	o.gcProfiles(ctx, o.dir, filePrefix)
+	gcProfiles(ctx, o.dir, filePrefix, maxProfiles.Get(&o.st.SV))
+}
+
+// maybeTakeRSSProfile takes a heap profile if the RSS exceeds a higher
+// threshold than at the last call. Falling below a threshold and crossing it
+// again takes another profile.
+func (o *HeapProfiler) maybeTakeRSSProfile(ctx context.Context, rss uint64) {
+	thresholds, err := parseRSSThresholds(rssThresholds.Get(&o.st.SV), o.totalMemory)
+	if err != nil {
+		// The setting is validated.
+		log.Warning(ctx, err)
+		return
+	}
+	var threshold uint64
+	for _, t := range thresholds {
+		// Percentage thresholds are zero when the system memory is unknown.
+		if t > 0 && rss >= t {
+			threshold = t
+		}
+	}
+	takeProfile := threshold > o.rssThreshold
+	o.rssThreshold = threshold
+	if hook := o.knobs.maybeTakeRSSProfileHook; hook != nil {
+		hook(takeProfile)
+	}
+	if !takeProfile || o.knobs.dontWriteProfiles {
+		return
+	}
+	log.Health.Warningf(ctx, "process RSS of %s exceeds %s, taking a heap profile",
+		humanizeutil.IBytes(int64(rss)), humanizeutil.IBytes(int64(threshold)))
+	// The file names sort by time, so that the oldest profiles are GC'ed.
+	const format = "2006-01-02T15_04_05.000"
+	fileName := fmt.Sprintf("%s%s_%018d", rssProfilePrefix, o.now().Format(format), rss)
+	takeHeapProfile(ctx, filepath.Join(o.dir, fileName))
+	gcProfiles(ctx, o.dir, rssProfilePrefix, maxRSSProfiles.Get(&o.st.SV))
 }
 
+// rssProfilePrefix is the prefix of the names of the profiles taken when the
+// RSS crosses a threshold.
+const rssProfilePrefix = "memprof_rss."
+
 func (o *HeapProfiler) now() time.Time {
 	if o.knobs.now != nil {
 		return o.knobs.now()
@@ -122,8 +225,7 @@ func (o *HeapProfiler) now() time.Time {
 // the profiles indicates score such that sorting the filenames corresponds to
 // ordering the profiles from least to max score.
 // Latest profile in the directory is not considered for GC.
-func (o *HeapProfiler) gcProfiles(ctx context.Context, dir, prefix string) {
-	maxCount := maxProfiles.Get(&o.st.SV)
+func gcProfiles(ctx context.Context, dir, prefix string, maxCount int64) {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
 		log.Warning(ctx, err)
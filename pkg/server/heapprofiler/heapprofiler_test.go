@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,7 +41,7 @@ func TestHeapProfiler(t *testing.T) {
 	}
 
 	var tookProfile bool
-	hp, err := NewHeapProfiler("dummy_dir", cluster.MakeTestingClusterSettings())
+	hp, err := NewHeapProfiler("dummy_dir", cluster.MakeTestingClusterSettings(), 1000 /* totalMemory */)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -59,7 +60,62 @@ func TestHeapProfiler(t *testing.T) {
 		var ms runtime.MemStats
 		ms.HeapAlloc = r.heapBytes
 
-		hp.MaybeTakeProfile(ctx, ms)
+		hp.MaybeTakeProfile(ctx, ms, 0 /* rss */)
 		assert.Equal(t, r.expProfile, tookProfile, i)
 	}
 }
+
+func TestHeapProfilerRSSThresholds(t *testing.T) {
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	if err := st.MakeUpdater().Set(
+		"server.heap_profile.rss_thresholds", "50%,800", "s",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	var tookProfile bool
+	hp, err := NewHeapProfiler("dummy_dir", st, 1000 /* totalMemory */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hp.knobs = testingKnobs{
+		now:               timeutil.Now,
+		dontWriteProfiles: true,
+		maybeTakeRSSProfileHook: func(willTakeProfile bool) {
+			tookProfile = willTakeProfile
+		},
+	}
+
+	tests := []struct {
+		rss        uint64
+		expProfile bool
+	}{
+		{100, false}, // below all thresholds
+		{500, true},  // crossed 50%
+		{600, false}, // still above 50%
+		{900, true},  // crossed 800 bytes
+		{700, false}, // dropped below 800 bytes
+		{850, true},  // crossed 800 bytes again
+		{100, false}, // dropped below all thresholds
+		{1000, true}, // crossed both thresholds at once
+	}
+	for i, r := range tests {
+		hp.MaybeTakeProfile(ctx, runtime.MemStats{}, r.rss)
+		assert.Equal(t, r.expProfile, tookProfile, i)
+	}
+}
+
+func TestParseRSSThresholds(t *testing.T) {
+	thresholds, err := parseRSSThresholds("90%, 1KiB,25%", 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{500, 1024, 1800}, thresholds)
+
+	for _, s := range []string{"0%", "101%", "abc%", "abc", "0"} {
+		if _, err := parseRSSThresholds(s, 2000); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// defaultHotRangesLimit is the number of hot ranges returned by
+// ClusterHotRanges when no limit is given.
+const defaultHotRangesLimit = 100
+
+// ClusterHotRanges returns the hottest replicas of the stores of the cluster,
+// ordered by decreasing queries per second. Unlike HotRanges, which returns
+// the hottest replicas of each store separately, the replicas of all the
+// stores are merged into a single ranking, so that the hottest ranges of the
+// cluster can be found directly.
+func (s *statusServer) ClusterHotRanges(
+	ctx context.Context, req *serverpb.ClusterHotRangesRequest,
+) (*serverpb.ClusterHotRangesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	limit, offset := int(req.Limit), int(req.Offset)
+	if limit < 0 {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, "limit must be a positive integer")
+	}
+	if limit == 0 {
+		limit = defaultHotRangesLimit
+	}
+	if offset < 0 {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, "offset must be a non-negative integer")
+	}
+	var nodeIDs map[roachpb.NodeID]struct{}
+	if len(req.NodeIDs) > 0 {
+		nodeIDs = make(map[roachpb.NodeID]struct{})
+		for _, id := range req.NodeIDs {
+			nodeID, _, err := s.parseNodeID(id)
+			if err != nil {
+				return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+			}
+			nodeIDs[nodeID] = struct{}{}
+		}
+	}
+
+	hotRangesReq := &serverpb.HotRangesRequest{}
+	if len(nodeIDs) == 1 {
+		// Only ask the one node for its hot ranges.
+		for nodeID := range nodeIDs {
+			hotRangesReq.NodeID = nodeID.String()
+		}
+	}
+	hotRanges, err := s.HotRanges(ctx, hotRangesReq)
+	if err != nil {
+		return nil, err
+	}
+	return makeClusterHotRanges(hotRanges, nodeIDs, limit, offset), nil
+}
+
+// makeClusterHotRanges ranks the hot ranges of the given nodes, or of all
+// nodes if nodeIDs is nil, and returns the requested page of the ranking.
+func makeClusterHotRanges(
+	hotRanges *serverpb.HotRangesResponse, nodeIDs map[roachpb.NodeID]struct{}, limit, offset int,
+) *serverpb.ClusterHotRangesResponse {
+	resp := &serverpb.ClusterHotRangesResponse{}
+	ranges := []serverpb.ClusterHotRangesResponse_HotRange{}
+	for nodeID, nodeResp := range hotRanges.HotRangesByNodeID {
+		if _, ok := nodeIDs[nodeID]; nodeIDs != nil && !ok {
+			continue
+		}
+		if nodeResp.ErrorMessage != "" {
+			if resp.Errors == nil {
+				resp.Errors = make(map[roachpb.NodeID]string)
+			}
+			resp.Errors[nodeID] = nodeResp.ErrorMessage
+		}
+		for _, store := range nodeResp.Stores {
+			for _, hr := range store.HotRanges {
+				r := serverpb.ClusterHotRangesResponse_HotRange{
+					NodeID:           nodeID,
+					StoreID:          store.StoreID,
+					RangeID:          hr.Desc.RangeID,
+					QueriesPerSecond: hr.QueriesPerSecond,
+				}
+				// The keys are omitted if the remote debugging mode
+				// doesn't allow them.
+				if len(hr.Desc.StartKey) > 0 || len(hr.Desc.EndKey) > 0 {
+					r.StartKey = hr.Desc.StartKey.String()
+					r.EndKey = hr.Desc.EndKey.String()
+				}
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].QueriesPerSecond != ranges[j].QueriesPerSecond {
+			return ranges[i].QueriesPerSecond > ranges[j].QueriesPerSecond
+		}
+		if ranges[i].RangeID != ranges[j].RangeID {
+			return ranges[i].RangeID < ranges[j].RangeID
+		}
+		return ranges[i].StoreID < ranges[j].StoreID
+	})
+
+	resp.Total = int32(len(ranges))
+	if offset > len(ranges) {
+		offset = len(ranges)
+	}
+	end := offset + limit
+	if end < len(ranges) {
+		resp.NextOffset = int32(end)
+	} else {
+		end = len(ranges)
+	}
+	resp.Ranges = ranges[offset:end]
+	return resp
+}
@@ -0,0 +1,122 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMakeClusterHotRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hotRange := func(rangeID roachpb.RangeID, qps float64) serverpb.HotRangesResponse_HotRange {
+		return serverpb.HotRangesResponse_HotRange{
+			Desc:             roachpb.RangeDescriptor{RangeID: rangeID},
+			QueriesPerSecond: qps,
+		}
+	}
+	hotRanges := &serverpb.HotRangesResponse{
+		HotRangesByNodeID: map[roachpb.NodeID]serverpb.HotRangesResponse_NodeResponse{
+			1: {Stores: []*serverpb.HotRangesResponse_StoreResponse{
+				{StoreID: 1, HotRanges: []serverpb.HotRangesResponse_HotRange{
+					hotRange(1, 10), hotRange(2, 5),
+				}},
+				{StoreID: 2, HotRanges: []serverpb.HotRangesResponse_HotRange{
+					hotRange(3, 20),
+				}},
+			}},
+			2: {Stores: []*serverpb.HotRangesResponse_StoreResponse{
+				{StoreID: 3, HotRanges: []serverpb.HotRangesResponse_HotRange{
+					hotRange(4, 15), hotRange(1, 1),
+				}},
+			}},
+			3: {ErrorMessage: "unreachable"},
+		},
+	}
+
+	rangeIDs := func(resp *serverpb.ClusterHotRangesResponse) []roachpb.RangeID {
+		ids := []roachpb.RangeID{}
+		for _, r := range resp.Ranges {
+			ids = append(ids, r.RangeID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		nodeIDs        map[roachpb.NodeID]struct{}
+		limit, offset  int
+		expected       []roachpb.RangeID
+		expectedTotal  int32
+		expectedNext   int32
+		expectedErrors int
+	}{
+		{nil, 10, 0, []roachpb.RangeID{3, 4, 1, 2, 1}, 5, 0, 1},
+		{nil, 2, 0, []roachpb.RangeID{3, 4}, 5, 2, 1},
+		{nil, 2, 2, []roachpb.RangeID{1, 2}, 5, 4, 1},
+		{nil, 2, 4, []roachpb.RangeID{1}, 5, 0, 1},
+		{nil, 2, 10, []roachpb.RangeID{}, 5, 0, 1},
+		{map[roachpb.NodeID]struct{}{2: {}}, 10, 0, []roachpb.RangeID{4, 1}, 2, 0, 0},
+	}
+	for i, tc := range testCases {
+		resp := makeClusterHotRanges(hotRanges, tc.nodeIDs, tc.limit, tc.offset)
+		if actual := rangeIDs(resp); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%d: expected ranges %v, got %v", i, tc.expected, actual)
+		}
+		if resp.Total != tc.expectedTotal || resp.NextOffset != tc.expectedNext {
+			t.Errorf("%d: expected total %d and next offset %d, got %d and %d",
+				i, tc.expectedTotal, tc.expectedNext, resp.Total, resp.NextOffset)
+		}
+		if len(resp.Errors) != tc.expectedErrors {
+			t.Errorf("%d: expected %d errors, got %v", i, tc.expectedErrors, resp.Errors)
+		}
+	}
+}
+
+// TestStatusClusterHotRanges verifies that the hot ranges of the cluster are
+// available via the /_status/hotranges_cluster endpoint.
+func TestStatusClusterHotRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	var resp serverpb.ClusterHotRangesResponse
+	if err := getStatusJSONProto(s, "hotranges_cluster?node_ids=local&limit=3", &resp); err != nil {
+		t.Fatal(err)
+	}
+	// The hot ranges of the stores are only tracked once their replicas are
+	// ranked, so the ranking may still be short.
+	if len(resp.Ranges) > 3 || (resp.Total > 3) != (resp.NextOffset == 3) {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	for i, r := range resp.Ranges {
+		if r.NodeID != 1 || r.RangeID == 0 {
+			t.Errorf("unexpected hot range: %+v", r)
+		}
+		if i > 0 && r.QueriesPerSecond > resp.Ranges[i-1].QueriesPerSecond {
+			t.Errorf("hot ranges are not ordered by QPS: %+v", resp)
+		}
+	}
+
+	if err := getStatusJSONProto(
+		s, "hotranges_cluster?limit=-1", &resp,
+	); !testutils.IsError(err, "limit must be a positive integer") {
+		t.Fatalf("expected an error, got: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// IndexRecommendations returns the index recommendations of
+// crdb_internal.node_index_recommendations, which are derived from the
+// statement statistics of the node. They are computed as the user of the
+// request, and only cover the tables visible to it.
+func (s *adminServer) IndexRecommendations(
+	ctx context.Context, req *serverpb.IndexRecommendationsRequest,
+) (*serverpb.IndexRecommendationsResponse, error) {
+	user, err := s.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.server.AnnotateCtx(ctx)
+	rows, _ /* cols */, err := s.server.internalExecutor.QueryWithUser(
+		ctx, "admin-index-recommendations", nil /* txn */, user,
+		`SELECT type, table_name, index_name, columns, statement, reason, fingerprints, execution_count
+FROM crdb_internal.node_index_recommendations`,
+	)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	resp := &serverpb.IndexRecommendationsResponse{
+		Recommendations: make([]serverpb.IndexRecommendationsResponse_Recommendation, 0, len(rows)),
+	}
+	for _, row := range rows {
+		rec := serverpb.IndexRecommendationsResponse_Recommendation{
+			Type:           string(tree.MustBeDString(row[0])),
+			Table:          string(tree.MustBeDString(row[1])),
+			Columns:        stringsFromDArray(row[3]),
+			Statement:      string(tree.MustBeDString(row[4])),
+			Reason:         string(tree.MustBeDString(row[5])),
+			Fingerprints:   stringsFromDArray(row[6]),
+			ExecutionCount: int64(tree.MustBeDInt(row[7])),
+		}
+		if row[2] != tree.DNull {
+			rec.Index = string(tree.MustBeDString(row[2]))
+		}
+		resp.Recommendations = append(resp.Recommendations, rec)
+	}
+	return resp, nil
+}
+
+// stringsFromDArray returns the elements of a STRING[] datum.
+func stringsFromDArray(d tree.Datum) []string {
+	arr := tree.MustBeDArray(d)
+	strs := make([]string, len(arr.Array))
+	for i, e := range arr.Array {
+		strs[i] = string(tree.MustBeDString(e))
+	}
+	return strs
+}
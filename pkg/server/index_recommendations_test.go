@@ -0,0 +1,52 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAdminAPIIndexRecommendations(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	grantAdmin(t, db)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (a INT PRIMARY KEY, b INT, INDEX b_idx (b), INDEX b_a_idx (b, a))`)
+
+	var resp serverpb.IndexRecommendationsResponse
+	if err := getAdminJSONProto(s, "index_recommendations", &resp); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, rec := range resp.Recommendations {
+		if rec.Table != "d.public.t" {
+			continue
+		}
+		if found || rec.Type != "DROP" || rec.Index != "b_idx" ||
+			rec.Statement != "DROP INDEX d.public.t@b_idx" {
+			t.Fatalf("unexpected recommendation: %+v", rec)
+		}
+		found = true
+	}
+	if !found {
+		t.Fatalf("expected b_idx to be recommended to be dropped: %+v", resp.Recommendations)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PauseJob pauses a job, like PAUSE JOB.
+func (s *adminServer) PauseJob(
+	ctx context.Context, req *serverpb.ControlJobRequest,
+) (*serverpb.ControlJobResponse, error) {
+	return s.controlJob(ctx, req, tree.PauseJob)
+}
+
+// ResumeJob resumes a paused job, like RESUME JOB.
+func (s *adminServer) ResumeJob(
+	ctx context.Context, req *serverpb.ControlJobRequest,
+) (*serverpb.ControlJobResponse, error) {
+	return s.controlJob(ctx, req, tree.ResumeJob)
+}
+
+// CancelJob cancels a job, like CANCEL JOB.
+func (s *adminServer) CancelJob(
+	ctx context.Context, req *serverpb.ControlJobRequest,
+) (*serverpb.ControlJobResponse, error) {
+	return s.controlJob(ctx, req, tree.CancelJob)
+}
+
+// controlJob applies the given job control command to a job, and returns
+// the status of the job once the command has been applied. The command is
+// run as the user of the request, like SQL job control statements.
+func (s *adminServer) controlJob(
+	ctx context.Context, req *serverpb.ControlJobRequest, command tree.JobCommand,
+) (*serverpb.ControlJobResponse, error) {
+	user, err := s.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.server.AnnotateCtx(ctx)
+	if req.JobID <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "job_id must be a positive integer")
+	}
+	ie := s.server.internalExecutor
+	if _, err := ie.ExecWithUser(
+		ctx, "admin-control-job", nil /* txn */, user,
+		fmt.Sprintf("%s JOB $1", tree.JobCommandToStatement[command]), req.JobID,
+	); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	rows, _ /* cols */, err := ie.QueryWithUser(
+		ctx, "admin-control-job-status", nil /* txn */, user,
+		"SELECT status FROM crdb_internal.jobs WHERE job_id = $1", req.JobID,
+	)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	resp := &serverpb.ControlJobResponse{JobID: req.JobID}
+	if len(rows) > 0 {
+		resp.Status = string(tree.MustBeDString(rows[0][0]))
+	}
+	return resp, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// controllableResumer makes import jobs controllable. Resumed jobs run until
+// they are canceled.
+type controllableResumer struct{}
+
+func (controllableResumer) Resume(ctx context.Context, _ interface{}, _ chan<- tree.Datums) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (controllableResumer) OnSuccess(context.Context, *client.Txn) error                { return nil }
+func (controllableResumer) OnTerminal(context.Context, jobs.Status, chan<- tree.Datums) {}
+func (controllableResumer) OnFailOrCancel(context.Context, *client.Txn) error           { return nil }
+
+func TestAdminAPIControlJobs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	jobs.RegisterConstructor(jobspb.TypeImport, func(*jobs.Job, *cluster.Settings) jobs.Resumer {
+		return controllableResumer{}
+	})
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	grantAdmin(t, db)
+
+	registry := s.JobRegistry().(*jobs.Registry)
+	for _, user := range []string{"alice", "bob"} {
+		job := registry.NewJob(jobs.Record{
+			Description: "import by " + user,
+			Username:    user,
+			Details:     jobspb.ImportDetails{},
+			Progress:    jobspb.ImportProgress{},
+		})
+		if err := job.Created(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var list serverpb.JobsResponse
+	if err := getAdminJSONProto(s, fmt.Sprintf("jobs?type=%d&status=pending&user=bob",
+		jobspb.TypeImport), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Jobs) != 1 || list.Jobs[0].Description != "import by bob" {
+		t.Fatalf("unexpected jobs: %+v", list.Jobs)
+	}
+	id := list.Jobs[0].ID
+
+	control := func(command string, id int64, expectedErr string) serverpb.ControlJobResponse {
+		t.Helper()
+		var resp serverpb.ControlJobResponse
+		if err := postAdminJSONProto(s, fmt.Sprintf("jobs/%d/%s", id, command),
+			&serverpb.ControlJobRequest{}, &resp,
+		); !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%s job %d: expected error %q, got: %v", command, id, expectedErr, err)
+		}
+		return resp
+	}
+	control("pause", 12345, "412 Precondition Failed")
+	for _, tc := range []struct {
+		command string
+		status  jobs.Status
+	}{
+		{"pause", jobs.StatusPaused},
+		{"resume", jobs.StatusRunning},
+		{"cancel", jobs.StatusCanceled},
+	} {
+		if resp := control(tc.command, id, ""); resp.JobID != id || resp.Status != string(tc.status) {
+			t.Fatalf("expected job %d to be %s, got %+v", id, tc.status, resp)
+		}
+	}
+	// Canceled jobs can't be paused.
+	control("pause", id, "412 Precondition Failed")
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// MemoryMonitors returns the tree of the SQL memory monitors of the node
+// (root, SQL, session, transaction, query and operator monitors) with their
+// current and peak usage, so that the allocations which cause a "memory
+// budget exceeded" error can be attributed.
+func (s *statusServer) MemoryMonitors(
+	ctx context.Context, req *serverpb.MemoryMonitorsRequest,
+) (*serverpb.MemoryMonitorsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.MemoryMonitors(ctx, req)
+	}
+
+	resp := &serverpb.MemoryMonitorsResponse{
+		NodeID:   s.gossip.NodeID.Get(),
+		Monitors: []serverpb.MemoryMonitorsResponse_Monitor{},
+	}
+	if err := s.rootSQLMemory.TraverseTree(func(m mon.MonitorState) error {
+		resp.Monitors = append(resp.Monitors, serverpb.MemoryMonitorsResponse_Monitor{
+			Level:               int32(m.Level),
+			Name:                m.Name,
+			ID:                  m.ID,
+			ParentID:            m.ParentID,
+			UsedBytes:           m.Used,
+			PeakBytes:           m.Peak,
+			PoolBudgetBytes:     m.PoolBudget,
+			ReservedBudgetBytes: m.ReservedBudget,
+			LimitBytes:          m.Limit,
+		})
+		return nil
+	}); err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
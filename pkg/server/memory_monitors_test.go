@@ -0,0 +1,66 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestStatusMemoryMonitors verifies that the tree of memory monitors of the
+// node is available via the /_status/memory_monitors endpoint.
+func TestStatusMemoryMonitors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Open a session; its connection is kept in the pool of db, so that the
+	// monitors of the session are part of the tree.
+	if _, err := db.Exec(`SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp serverpb.MemoryMonitorsResponse
+	if err := getStatusJSONProto(s, "memory_monitors/local", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeID != 1 || len(resp.Monitors) == 0 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if root := resp.Monitors[0]; root.Name != "root" || root.Level != 0 || root.ParentID != 0 {
+		t.Fatalf("unexpected root monitor %+v", root)
+	}
+	names := make(map[int64]string)
+	for _, m := range resp.Monitors {
+		names[m.ID] = m.Name
+	}
+	var foundSQL, foundSession bool
+	for _, m := range resp.Monitors[1:] {
+		parent, ok := names[m.ParentID]
+		if !ok {
+			t.Errorf("monitor %+v listed without its parent", m)
+		}
+		switch {
+		case m.Name == "sql" && parent == "root":
+			foundSQL = true
+		case m.Name == "session root" && parent == "sql":
+			foundSession = true
+		}
+	}
+	if !foundSQL || !foundSession {
+		t.Fatalf("expected the sql and session monitors: %+v", resp.Monitors)
+	}
+}
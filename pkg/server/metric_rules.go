@@ -0,0 +1,31 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// statusRules exposes the Prometheus alerting and aggregation rules
+// recommended for the metrics of statusVars, as a Prometheus rules file.
+const statusRules = statusPrefix + "rules"
+
+// handleRules writes the rules of the rule registry as YAML.
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(httputil.ContentTypeHeader, httputil.PlaintextContentType)
+	if err := s.ruleRegistry.PrintAsYAML(w); err != nil {
+		log.Error(r.Context(), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -814,7 +815,7 @@ func (n *Node) writeNodeStatus(ctx context.Context, alertTTL time.Duration) erro
 			}
 			if numNodes > 1 {
 				// Avoid this warning on single-node clusters, which require special UX.
-				log.Warningf(ctx, "health alerts detected: %+v", result)
+				log.Health.Warningf(ctx, "health alerts detected: %+v", result)
 			}
 			if err := n.storeCfg.Gossip.AddInfoProto(
 				gossip.MakeNodeHealthAlertKey(n.Descriptor.NodeID), &result, alertTTL,
@@ -842,11 +843,24 @@ func (n *Node) recordJoinEvent() {
 		return
 	}
 
-	logEventType := sql.EventLogNodeRestart
-	lastUp := n.lastUp
+	var logEventType sql.EventLogType
+	var info eventpb.EventPayload
 	if n.initialBoot {
 		logEventType = sql.EventLogNodeJoin
-		lastUp = n.startedAt
+		info = &eventpb.NodeJoin{
+			NodeDescriptor: n.Descriptor,
+			ClusterID:      n.clusterID.Get(),
+			StartedAt:      n.startedAt,
+			LastUp:         n.startedAt,
+		}
+	} else {
+		logEventType = sql.EventLogNodeRestart
+		info = &eventpb.NodeRestart{
+			NodeDescriptor: n.Descriptor,
+			ClusterID:      n.clusterID.Get(),
+			StartedAt:      n.startedAt,
+			LastUp:         n.lastUp,
+		}
 	}
 
 	n.stopper.RunWorker(context.Background(), func(bgCtx context.Context) {
@@ -862,12 +876,7 @@ func (n *Node) recordJoinEvent() {
 					logEventType,
 					int32(n.Descriptor.NodeID),
 					int32(n.Descriptor.NodeID),
-					struct {
-						Descriptor roachpb.NodeDescriptor
-						ClusterID  uuid.UUID
-						StartedAt  int64
-						LastUp     int64
-					}{n.Descriptor, n.clusterID.Get(), n.startedAt, lastUp},
+					info,
 				)
 			}); err != nil {
 				log.Warningf(ctx, "%s: unable to log %s event: %s", n, logEventType, err)
@@ -0,0 +1,48 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// OIDC is the OpenID Connect single sign-on support of the admin UI, which
+// is an enterprise feature configured by ConfigureOIDC.
+type OIDC interface {
+	// LoginEnabled returns whether the login page of the admin UI offers to
+	// log in with OIDC.
+	LoginEnabled() bool
+}
+
+// noOIDCConfigured is the OIDC of binaries built without OIDC support.
+type noOIDCConfigured struct{}
+
+func (noOIDCConfigured) LoginEnabled() bool { return false }
+
+// ConfigureOIDC is a hook for the oidcccl package to add OIDC login support
+// to the admin UI. It is called during server startup to register the
+// handlers of the OIDC login flow with handle. userLoginFromSSO creates a web
+// session for an existing SQL user and returns the session cookie to set.
+var ConfigureOIDC = func(
+	ctx context.Context,
+	st *cluster.Settings,
+	handle func(pattern string, handler http.Handler),
+	userLoginFromSSO func(ctx context.Context, username string) (*http.Cookie, error),
+	ambientCtx log.AmbientContext,
+	clusterID uuid.UUID,
+) (OIDC, error) {
+	return noOIDCConfigured{}, nil
+}
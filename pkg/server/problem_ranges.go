@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"go.etcd.io/etcd/raft/tracker"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -146,3 +147,163 @@ func (s *statusServer) ProblemRanges(
 
 	return response, nil
 }
+
+// The problems reported by ClusterProblemRanges.
+const (
+	problemUnavailable            = "unavailable"
+	problemUnderreplicated        = "underreplicated"
+	problemOverreplicated         = "overreplicated"
+	problemNoRaftLeader           = "no_raft_leader"
+	problemNoLease                = "no_lease"
+	problemLeaderNotLeaseHolder   = "raft_leader_not_lease_holder"
+	problemQuiescentEqualsTicking = "quiescent_equals_ticking"
+	problemRaftLogTooLarge        = "raft_log_too_large"
+	// problemSlowRaft is reported by the Raft leader of a range when one of
+	// the followers is probed, i.e. can't keep up with the replication of the
+	// log.
+	problemSlowRaft = "slow_raft"
+	// problemPendingSnapshot is reported by the Raft leader of a range when
+	// one of the followers is waiting for a snapshot.
+	problemPendingSnapshot = "pending_snapshot"
+)
+
+// ClusterProblemRanges returns the ranges with problems across the cluster,
+// ordered by range ID. Unlike ProblemRanges, which lists the problem ranges
+// of each node separately, the problems reported by the replicas of a range
+// on all the nodes are merged into a single entry, so that a range can be
+// diagnosed without polling each node.
+func (s *statusServer) ClusterProblemRanges(
+	ctx context.Context, req *serverpb.ClusterProblemRangesRequest,
+) (*serverpb.ClusterProblemRangesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	rangesByNodeID := make(map[roachpb.NodeID][]serverpb.RangeInfo)
+	errs := make(map[roachpb.NodeID]string)
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.Ranges(ctx, &serverpb.RangesRequest{NodeId: "local"})
+	}
+	responseFn := func(nodeID roachpb.NodeID, resp interface{}) {
+		rangesByNodeID[nodeID] = resp.(*serverpb.RangesResponse).Ranges
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errs[nodeID] = err.Error()
+	}
+	if err := s.iterateNodes(ctx, "ranges", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	return makeClusterProblemRanges(rangesByNodeID, errs), nil
+}
+
+// makeClusterProblemRanges merges the problems of the replicas of each node
+// into a single entry per range.
+func makeClusterProblemRanges(
+	rangesByNodeID map[roachpb.NodeID][]serverpb.RangeInfo, errs map[roachpb.NodeID]string,
+) *serverpb.ClusterProblemRangesResponse {
+	resp := &serverpb.ClusterProblemRangesResponse{
+		Ranges: []serverpb.ClusterProblemRangesResponse_ProblemRange{},
+	}
+	for nodeID, err := range errs {
+		if resp.Errors == nil {
+			resp.Errors = make(map[roachpb.NodeID]string)
+		}
+		resp.Errors[nodeID] = err
+	}
+	byRangeID := make(map[roachpb.RangeID]*serverpb.ClusterProblemRangesResponse_ProblemRange)
+	for nodeID, ranges := range rangesByNodeID {
+		for _, info := range ranges {
+			if len(info.ErrorMessage) != 0 {
+				if resp.Errors == nil {
+					resp.Errors = make(map[roachpb.NodeID]string)
+				}
+				resp.Errors[nodeID] = info.ErrorMessage
+				continue
+			}
+			problems := replicaProblems(info)
+			if len(problems) == 0 {
+				continue
+			}
+			rangeID := info.State.Desc.RangeID
+			r, ok := byRangeID[rangeID]
+			if !ok {
+				r = &serverpb.ClusterProblemRangesResponse_ProblemRange{
+					RangeID:  rangeID,
+					StartKey: info.Span.StartKey,
+					EndKey:   info.Span.EndKey,
+				}
+				byRangeID[rangeID] = r
+			}
+		nextProblem:
+			for _, problem := range problems {
+				for i := range r.Problems {
+					if r.Problems[i].Problem == problem {
+						r.Problems[i].NodeIDs = append(r.Problems[i].NodeIDs, nodeID)
+						continue nextProblem
+					}
+				}
+				r.Problems = append(r.Problems, serverpb.ClusterProblemRangesResponse_Problem{
+					Problem: problem,
+					NodeIDs: []roachpb.NodeID{nodeID},
+				})
+			}
+		}
+	}
+	for _, r := range byRangeID {
+		sort.Slice(r.Problems, func(i, j int) bool {
+			return r.Problems[i].Problem < r.Problems[j].Problem
+		})
+		for _, p := range r.Problems {
+			nodeIDs := p.NodeIDs
+			sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+		}
+		resp.Ranges = append(resp.Ranges, *r)
+	}
+	sort.Slice(resp.Ranges, func(i, j int) bool {
+		return resp.Ranges[i].RangeID < resp.Ranges[j].RangeID
+	})
+	return resp
+}
+
+// replicaProblems returns the problems of a replica.
+func replicaProblems(info serverpb.RangeInfo) []string {
+	var problems []string
+	for _, p := range []struct {
+		problem string
+		ok      bool
+	}{
+		{problemUnavailable, info.Problems.Unavailable},
+		{problemUnderreplicated, info.Problems.Underreplicated},
+		{problemOverreplicated, info.Problems.Overreplicated},
+		{problemNoRaftLeader, info.Problems.NoRaftLeader},
+		{problemNoLease, info.Problems.NoLease},
+		{problemLeaderNotLeaseHolder, info.Problems.LeaderNotLeaseHolder},
+		{problemQuiescentEqualsTicking, info.Problems.QuiescentEqualsTicking},
+		{problemRaftLogTooLarge, info.Problems.RaftLogTooLarge},
+	} {
+		if p.ok {
+			problems = append(problems, p.problem)
+		}
+	}
+	// Only the leader tracks the progress of the followers.
+	var slow, pendingSnapshot bool
+	for _, progress := range info.RaftState.Progress {
+		switch progress.State {
+		case tracker.StateProbe.String():
+			slow = true
+		case tracker.StateSnapshot.String():
+			pendingSnapshot = true
+		}
+	}
+	if slow {
+		problems = append(problems, problemSlowRaft)
+	}
+	if pendingSnapshot {
+		problems = append(problems, problemPendingSnapshot)
+	}
+	return problems
+}
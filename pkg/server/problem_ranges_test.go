@@ -0,0 +1,107 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"go.etcd.io/etcd/raft/tracker"
+)
+
+func TestMakeClusterProblemRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rangeInfo := func(
+		rangeID roachpb.RangeID, problems serverpb.RangeProblems, followerStates ...string,
+	) serverpb.RangeInfo {
+		info := serverpb.RangeInfo{
+			Span: serverpb.PrettySpan{StartKey: "/a", EndKey: "/b"},
+			State: storagepb.RangeInfo{
+				ReplicaState: storagepb.ReplicaState{Desc: &roachpb.RangeDescriptor{RangeID: rangeID}},
+			},
+			Problems: problems,
+		}
+		if len(followerStates) > 0 {
+			info.RaftState.Progress = make(map[uint64]serverpb.RaftState_Progress)
+			for i, state := range followerStates {
+				info.RaftState.Progress[uint64(i+1)] = serverpb.RaftState_Progress{State: state}
+			}
+		}
+		return info
+	}
+	rangesByNodeID := map[roachpb.NodeID][]serverpb.RangeInfo{
+		1: {
+			rangeInfo(1, serverpb.RangeProblems{}),
+			rangeInfo(3, serverpb.RangeProblems{Underreplicated: true},
+				tracker.StateReplicate.String(), tracker.StateSnapshot.String()),
+			rangeInfo(2, serverpb.RangeProblems{}, tracker.StateProbe.String()),
+		},
+		2: {
+			rangeInfo(3, serverpb.RangeProblems{Underreplicated: true, NoLease: true}),
+		},
+		3: {
+			{ErrorMessage: "boom"},
+		},
+	}
+	errs := map[roachpb.NodeID]string{4: "unreachable"}
+
+	resp := makeClusterProblemRanges(rangesByNodeID, errs)
+	type problem = serverpb.ClusterProblemRangesResponse_Problem
+	expected := &serverpb.ClusterProblemRangesResponse{
+		Ranges: []serverpb.ClusterProblemRangesResponse_ProblemRange{
+			{RangeID: 2, StartKey: "/a", EndKey: "/b", Problems: []problem{
+				{Problem: problemSlowRaft, NodeIDs: []roachpb.NodeID{1}},
+			}},
+			{RangeID: 3, StartKey: "/a", EndKey: "/b", Problems: []problem{
+				{Problem: problemNoLease, NodeIDs: []roachpb.NodeID{2}},
+				{Problem: problemPendingSnapshot, NodeIDs: []roachpb.NodeID{1}},
+				{Problem: problemUnderreplicated, NodeIDs: []roachpb.NodeID{1, 2}},
+			}},
+		},
+		Errors: map[roachpb.NodeID]string{3: "boom", 4: "unreachable"},
+	}
+	if !reflect.DeepEqual(resp, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, resp)
+	}
+}
+
+// TestStatusClusterProblemRanges verifies that the problem ranges of the
+// cluster are available via the /_status/problemranges_cluster endpoint.
+func TestStatusClusterProblemRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	var resp serverpb.ClusterProblemRangesResponse
+	if err := getStatusJSONProto(s, "problemranges_cluster", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	// A single node cluster has no under-replicated ranges to report, as its
+	// replication factor is lowered to one.
+	for _, r := range resp.Ranges {
+		for _, p := range r.Problems {
+			if p.Problem == problemUnderreplicated {
+				t.Errorf("unexpected problem range: %+v", r)
+			}
+		}
+	}
+}
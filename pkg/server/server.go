@@ -33,6 +33,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer/keyvisualizerpb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
@@ -47,16 +49,20 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventwebhook"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/container"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/ts"
@@ -70,7 +76,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/netutil"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
-	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -168,6 +173,7 @@ type Server struct {
 	distSQLServer    *distsqlrun.ServerImpl
 	node             *Node
 	registry         *metric.Registry
+	ruleRegistry     *metric.RuleRegistry
 	recorder         *status.MetricsRecorder
 	runtime          *status.RuntimeStatSampler
 	admin            *adminServer
@@ -186,6 +192,7 @@ type Server struct {
 	sessionRegistry    *sql.SessionRegistry
 	jobRegistry        *jobs.Registry
 	statsRefresher     *stats.Refresher
+	keyVisualizer      *keyvisualizer.Collector
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
 	adminMemMetrics    sql.MemoryMetrics
@@ -206,6 +213,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 
 	clock := hlc.NewClock(hlc.UnixNano, time.Duration(cfg.MaxOffset))
+	cfg.diskStallDetector = engine.NewDiskStallDetector(st)
 	s := &Server{
 		st:       st,
 		clock:    clock,
@@ -250,8 +258,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// and after ValidateAddrs().
 	s.cfg.CheckCertificateAddrs(ctx)
 
-	s.rpcContext = rpc.NewContext(s.cfg.AmbientCtx, s.cfg.Config, s.clock, s.stopper,
-		&cfg.Settings.Version)
+	var rpcKnobs rpc.ContextTestingKnobs
+	if k := s.cfg.TestingKnobs.Server; k != nil {
+		rpcKnobs = k.(*TestingKnobs).ContextTestingKnobs
+	}
+	s.rpcContext = rpc.NewContextWithTestingKnobs(s.cfg.AmbientCtx, s.cfg.Config, s.clock, s.stopper,
+		&cfg.Settings.Version, rpcKnobs)
 	s.rpcContext.HeartbeatCB = func() {
 		if err := s.rpcContext.RemoteClocks.VerifyClockOffset(ctx); err != nil {
 			log.Fatal(ctx, err)
@@ -290,7 +302,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		clientTestingKnobs = *kvKnobs.(*kv.ClientTestingKnobs)
 	}
 	retryOpts := s.cfg.RetryOptions
-	if retryOpts == (retry.Options{}) {
+	if retryOpts.IsZero() {
 		retryOpts = base.DefaultRetryOptions()
 	}
 	retryOpts.Closer = s.stopper.ShouldQuiesce()
@@ -373,7 +385,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// Set up the DistSQL temp engine.
 
 	useStoreSpec := cfg.Stores.Specs[s.cfg.TempStorageConfig.SpecIdx]
-	tempEngine, err := engine.NewTempEngine(s.cfg.TempStorageConfig, useStoreSpec)
+	tempEngine, err := engine.NewTempEngineWithDiskStallDetector(
+		s.cfg.TempStorageConfig, useStoreSpec, s.cfg.diskStallDetector)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create temp storage")
 	}
@@ -420,6 +433,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// Similarly for execCfg.
 	var execCfg sql.ExecutorConfig
 
+	// The contention registry is populated by the stores and exposed to SQL.
+	contentionRegistry := contention.NewRegistry(st)
+
 	// TODO(bdarnell): make StoreConfig configurable.
 	storeCfg := storage.StoreConfig{
 		DefaultZoneConfig:       &s.cfg.DefaultZoneConfig,
@@ -442,7 +458,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		SQLExecutor:             internalExecutor,
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
+		ContentionRegistry:      contentionRegistry,
 		TimeSeriesDataStore:     s.tsDB,
+		RaftLogSizes:            storage.NewRaftLogSizeTracker(),
 
 		// Initialize the closed timestamp subsystem. Note that it won't
 		// be ready until it is .Start()ed, but the grpc server can be
@@ -471,12 +489,19 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		storeCfg.TestingKnobs = *storeTestingKnobs.(*storage.StoreTestingKnobs)
 	}
 
+	s.registry.AddMetricStruct(storeCfg.ClosedTimestamp.Metrics)
+
 	s.recorder = status.NewMetricsRecorder(s.clock, s.nodeLiveness, s.rpcContext, s.gossip, st)
 	s.registry.AddMetricStruct(s.rpcContext.RemoteClocks.Metrics())
 
 	s.runtime = status.NewRuntimeStatSampler(ctx, s.clock)
 	s.registry.AddMetricStruct(s.runtime)
 
+	s.ruleRegistry = metric.NewRuleRegistry()
+	storage.CreateAndAddRules(s.ruleRegistry)
+	rpc.CreateAndAddRules(s.ruleRegistry, s.clock.MaxOffset())
+	status.CreateAndAddRules(s.ruleRegistry)
+
 	s.node = NewNode(
 		storeCfg, s.recorder, s.registry, s.stopper,
 		txnMetrics, nil /* execCfg */, &s.rpcContext.ClusterID)
@@ -484,6 +509,24 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	storage.RegisterPerReplicaServer(s.grpc.Server, s.node.perReplicaServer)
 	s.node.storeCfg.ClosedTimestamp.RegisterClosedTimestampServer(s.grpc.Server)
 
+	// Unless disk stalls are fatal, shed the leases of the node's stores while
+	// one of its disks is stalled, so that the stall doesn't hold up the
+	// requests to the ranges of the node.
+	s.cfg.diskStallDetector.OnStall(func(stalled bool) {
+		// Don't undo the draining of a node which is shutting down.
+		if !stalled && s.grpc.mode.get() == modeDraining {
+			return
+		}
+		ctx := s.AnnotateCtx(context.Background())
+		if err := s.stopper.RunAsyncTask(ctx, "disk-stall-drain", func(context.Context) {
+			if err := s.node.SetDraining(stalled); err != nil {
+				log.Warningf(ctx, "failed to set draining to %t: %s", stalled, err)
+			}
+		}); err != nil {
+			log.Warningf(ctx, "failed to set draining to %t: %s", stalled, err)
+		}
+	})
+
 	s.sessionRegistry = sql.NewSessionRegistry()
 	s.jobRegistry = jobs.MakeRegistry(
 		s.cfg.AmbientCtx,
@@ -559,6 +602,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	distsqlpb.RegisterDistSQLServer(s.grpc.Server, s.distSQLServer)
 
 	s.admin = newAdminServer(s)
+	s.keyVisualizer = keyvisualizer.NewCollector(st, s.db, &s.nodeIDContainer, s.keyVisualizerBuckets)
 	s.status = newStatusServer(
 		s.cfg.AmbientCtx,
 		st,
@@ -573,6 +617,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.node.stores,
 		s.stopper,
 		s.sessionRegistry,
+		&rootSQLMemoryMonitor,
+		s.keyVisualizer,
 	)
 	s.authentication = newAuthenticationServer(s)
 	for _, gw := range []grpcGatewayServer{s.admin, s.status, s.authentication, &s.tsServer} {
@@ -610,6 +656,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 
 	loggerCtx, _ := s.stopper.WithCancelOnStop(ctx)
+	// The loggers of the SQLExec and SensitiveAccess channels are created
+	// below for the SQL executor.
+	for _, ch := range []log.Channel{log.Ops, log.Health, log.Storage, log.SQLPerf} {
+		log.NewChannelLogger(loggerCtx, nil /* dirName */, ch, true /* enableGc */, false /* forceSyncWrites */)
+	}
 
 	execCfg = sql.ExecutorConfig{
 		Settings:                s.st,
@@ -632,6 +683,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
+		ContentionRegistry:      contentionRegistry,
+		RootMemoryMonitor:       &rootSQLMemoryMonitor,
+		StmtDiagnosticsRegistry: stmtdiagnostics.NewRegistry(),
+		EventWebhookSender:      eventwebhook.NewSender(s.st),
 		TestingKnobs:            sqlExecutorTestingKnobs,
 
 		DistSQLPlanner: sql.NewDistSQLPlanner(
@@ -656,12 +711,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 			internalExecutor,
 		),
 
-		ExecLogger: log.NewSecondaryLogger(
-			loggerCtx, nil /* dirName */, "sql-exec", true /* enableGc */, false, /*forceSyncWrites*/
+		ExecLogger: log.NewChannelLogger(
+			loggerCtx, nil /* dirName */, log.SQLExec, true /* enableGc */, false, /*forceSyncWrites*/
 		),
 
-		AuditLogger: log.NewSecondaryLogger(
-			loggerCtx, s.cfg.SQLAuditLogDirName, "sql-audit", true /*enableGc*/, true, /*forceSyncWrites*/
+		AuditLogger: log.NewChannelLogger(
+			loggerCtx, s.cfg.SQLAuditLogDirName, log.SensitiveAccess, true /*enableGc*/, true, /*forceSyncWrites*/
 		),
 
 		QueryCache: querycache.New(s.cfg.SQLQueryCacheSize),
@@ -1151,11 +1206,14 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	// Enable the debug endpoints first to provide an earlier window into what's
-	// going on with the node in advance of exporting node functionality.
-	//
-	// TODO(marc): when cookie-based authentication exists, apply it to all web
-	// endpoints.
-	s.mux.Handle(debug.Endpoint, debug.NewServer(s.st))
+	// going on with the node in advance of exporting node functionality. When
+	// web sessions are required, they are only available to the users with the
+	// admin role or the debug viewer role.
+	var debugHandler http.Handler = debug.NewServer(s.st)
+	if s.cfg.RequireWebSession() {
+		debugHandler = newAuthenticationMux(s.authentication, s.status.requireDebugViewer(debugHandler))
+	}
+	s.mux.Handle(debug.Endpoint, debugHandler)
 
 	// Initialize grpc-gateway mux and context in order to get the /health
 	// endpoint working even before the node has fully initialized.
@@ -1430,6 +1488,9 @@ func (s *Server) Start(ctx context.Context) error {
 	// Begin recording runtime statistics.
 	s.startSampleEnvironment(ctx, DefaultMetricsSampleInterval)
 
+	// Begin sampling the load across the keyspace for the key visualizer.
+	s.keyVisualizer.Start(s.AnnotateCtx(ctx), s.stopper)
+
 	// Begin recording time series data collected by the status monitor.
 	s.tsDB.PollSource(
 		s.cfg.AmbientCtx, s.recorder, DefaultMetricsSampleInterval, ts.Resolution10s, s.stopper,
@@ -1550,6 +1611,17 @@ func (s *Server) Start(ctx context.Context) error {
 	// Start garbage collecting system events.
 	s.startSystemLogsGC(ctx)
 
+	// Start delivering system events to the event log webhook.
+	s.execCfg.EventWebhookSender.Start(ctx, s.stopper)
+
+	// Register the handlers of the OIDC login flow, if OIDC is supported.
+	oidc, err := ConfigureOIDC(
+		ctx, s.st, s.mux.Handle, s.authentication.UserLoginFromSSO, s.cfg.AmbientCtx, s.ClusterID(),
+	)
+	if err != nil {
+		return err
+	}
+
 	// Serve UI assets.
 	//
 	// The authentication mux used here is created in "allow anonymous" mode so that the UI
@@ -1561,6 +1633,7 @@ func (s *Server) Start(ctx context.Context) error {
 		ui.Handler(ui.Config{
 			ExperimentalUseLogin: s.cfg.EnableWebSessionAuthentication,
 			LoginEnabled:         s.cfg.RequireWebSession(),
+			OIDCLoginEnabled:     oidc.LoginEnabled,
 			NodeID:               &s.nodeIDContainer,
 			GetUser: func(ctx context.Context) *string {
 				if u, ok := ctx.Value(webSessionUserKey{}).(string); ok {
@@ -1579,13 +1652,15 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	s.mux.Handle(adminPrefix, authHandler)
-	// Exempt the health check endpoint from authentication.
+	// Exempt the health check and capabilities endpoints from authentication.
 	s.mux.Handle("/_admin/v1/health", gwMux)
+	s.mux.Handle(adminCapabilities, http.HandlerFunc(s.status.handleCapabilities))
 	s.mux.Handle(ts.URLPrefix, authHandler)
 	s.mux.Handle(statusPrefix, authHandler)
 	s.mux.Handle(loginPath, gwMux)
 	s.mux.Handle(logoutPath, authHandler)
 	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
+	s.mux.Handle(statusRules, http.HandlerFunc(s.handleRules))
 	log.Event(ctx, "added http endpoints")
 
 	// Attempt to upgrade cluster version.
@@ -1859,39 +1934,19 @@ func (s *Server) bootstrapCluster(ctx context.Context) error {
 func (s *Server) doDrain(
 	ctx context.Context, modes []serverpb.DrainMode, setTo bool,
 ) ([]serverpb.DrainMode, error) {
+	log.Ops.Infof(ctx, "setting drain modes %v to %t", modes, setTo)
 	for _, mode := range modes {
 		switch mode {
 		case serverpb.DrainMode_CLIENT:
-			if setTo {
-				s.grpc.setMode(modeDraining)
-				// Wait for drainUnreadyWait. This will fail load balancer checks and
-				// delay draining so that client traffic can move off this node.
-				time.Sleep(drainWait.Get(&s.st.SV))
+			if !setTo {
+				s.distSQLServer.Undrain(ctx)
+				s.pgServer.Undrain()
+				s.leaseMgr.SetDraining(false)
+				s.grpc.setMode(modeOperational)
+				break
 			}
-			if err := func() error {
-				if !setTo {
-					// Execute this last.
-					defer func() { s.grpc.setMode(modeOperational) }()
-				}
-				// Since enabling the lease manager's draining mode will prevent
-				// the acquisition of new leases, the switch must be made after
-				// the pgServer has given sessions a chance to finish ongoing
-				// work.
-				defer s.leaseMgr.SetDraining(setTo)
-
-				if !setTo {
-					s.distSQLServer.Undrain(ctx)
-					s.pgServer.Undrain()
-					return nil
-				}
-
-				drainMaxWait := queryWait.Get(&s.st.SV)
-				if err := s.pgServer.Drain(drainMaxWait); err != nil {
-					return err
-				}
-				s.distSQLServer.Drain(ctx, drainMaxWait)
-				return nil
-			}(); err != nil {
+			s.drainUnready(ctx, s.drainWait())
+			if err := s.drainClients(ctx, s.queryWait()); err != nil {
 				return nil, err
 			}
 		case serverpb.DrainMode_LEASES:
@@ -1913,6 +1968,24 @@ func (s *Server) doDrain(
 	return nowOn, nil
 }
 
+// drainWait returns the amount of time the server spends in an unready
+// state before draining SQL clients.
+func (s *Server) drainWait() time.Duration {
+	if s.cfg.DrainWait != 0 {
+		return s.cfg.DrainWait
+	}
+	return drainWait.Get(&s.st.SV)
+}
+
+// queryWait returns the maximum amount of time the server waits for active
+// queries to finish while draining SQL clients.
+func (s *Server) queryWait() time.Duration {
+	if s.cfg.QueryWait != 0 {
+		return s.cfg.QueryWait
+	}
+	return queryWait.Get(&s.st.SV)
+}
+
 // Drain idempotently activates the given DrainModes on the Server in the order
 // in which they are supplied.
 // For example, Drain is typically called with [CLIENT,LEADERSHIP] before
@@ -1939,8 +2012,10 @@ func (s *Server) Undrain(ctx context.Context, off []serverpb.DrainMode) []server
 func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb.NodeID) error {
 	eventLogger := sql.MakeEventLogger(s.execCfg)
 	eventType := sql.EventLogNodeDecommissioned
+	var info eventpb.EventPayload = &eventpb.NodeDecommissioned{}
 	if !setTo {
 		eventType = sql.EventLogNodeRecommissioned
+		info = &eventpb.NodeRecommissioned{}
 	}
 	for _, nodeID := range nodeIDs {
 		changeCommitted, err := s.nodeLiveness.SetDecommissioning(ctx, nodeID, setTo)
@@ -1956,7 +2031,7 @@ func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb
 			// than to slow down future node liveness transactions.
 			if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 				return eventLogger.InsertEventRecord(
-					ctx, txn, eventType, int32(nodeID), int32(s.NodeID()), struct{}{},
+					ctx, txn, eventType, int32(nodeID), int32(s.NodeID()), info,
 				)
 			}); err != nil {
 				log.Errorf(ctx, "unable to record %s event for node %d: %s", eventType, nodeID, err)
@@ -1966,6 +2041,28 @@ func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb
 	return nil
 }
 
+// keyVisualizerBuckets returns the load on the ranges for which the node's
+// stores hold a valid lease. It is the key visualizer's source of samples.
+func (s *Server) keyVisualizerBuckets(ctx context.Context) ([]keyvisualizerpb.Bucket, error) {
+	now := s.clock.Now()
+	var buckets []keyvisualizerpb.Bucket
+	err := s.node.stores.VisitStores(func(store *storage.Store) error {
+		store.VisitReplicas(func(repl *storage.Replica) bool {
+			if !repl.OwnsValidLease(now) {
+				return true
+			}
+			buckets = append(buckets, keyvisualizerpb.Bucket{
+				Span:              repl.Desc().RSpan().AsRawSpanWithNoLocals(),
+				RequestsPerSecond: repl.QueriesPerSecond(),
+				WritesPerSecond:   repl.WritesPerSecond(),
+			})
+			return true
+		})
+		return nil
+	})
+	return buckets, err
+}
+
 // startSampleEnvironment begins the heap profiler worker.
 func (s *Server) startSampleEnvironment(ctx context.Context, frequency time.Duration) {
 	// Immediately record summaries once on server startup.
@@ -1990,8 +2087,13 @@ func (s *Server) startSampleEnvironment(ctx context.Context, frequency time.Dura
 		if err := os.MkdirAll(s.cfg.HeapProfileDirName, 0755); err != nil {
 			log.Fatalf(ctx, "Could not create heap profiles dir: %s", err)
 		}
+		totalMemory, err := status.GetTotalMemory(ctx)
+		if err != nil {
+			log.Warningf(ctx, "could not determine the system memory, "+
+				"percentage RSS thresholds of heap profiles are disabled: %v", err)
+		}
 		heapProfiler, err = heapprofiler.NewHeapProfiler(
-			s.cfg.HeapProfileDirName, s.ClusterSettings())
+			s.cfg.HeapProfileDirName, s.ClusterSettings(), totalMemory)
 		if err != nil {
 			log.Fatalf(ctx, "Could not start heap profiler worker due to: %s", err)
 		}
@@ -2049,7 +2151,7 @@ func (s *Server) startSampleEnvironment(ctx context.Context, frequency time.Dura
 					goroutineDumper.MaybeDump(ctx, s.ClusterSettings(), s.runtime.Goroutines.Value())
 				}
 				if heapProfiler != nil {
-					heapProfiler.MaybeTakeProfile(ctx, curStats.MemStats)
+					heapProfiler.MaybeTakeProfile(ctx, curStats.MemStats, uint64(s.runtime.RSSBytes.Value()))
 				}
 
 			}
@@ -2070,6 +2172,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Disable caching of responses.
 	w.Header().Set("Cache-control", "no-cache")
 
+	if isVersionedAPIPath(r.URL.Path) && !s.negotiateAPIVersion(w, r) {
+		return
+	}
+
 	ae := r.Header.Get(httputil.AcceptEncodingHeader)
 	switch {
 	case strings.Contains(ae, httputil.GzipEncoding):
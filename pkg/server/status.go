@@ -28,6 +28,7 @@ import (
 	"regexp"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -47,18 +49,21 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
+	"github.com/prometheus/common/expfmt"
 	"go.etcd.io/etcd/raft"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -80,6 +85,43 @@ const (
 	// statusVars exposes prometheus metrics for monitoring consumption.
 	statusVars = statusPrefix + "vars"
 
+	// statusSnapshots is the prefix of the Snapshots endpoint, which exposes
+	// the snapshots being sent and received by a node's stores.
+	statusSnapshots = statusPrefix + "snapshots/"
+
+	// statusKeyVisualizer is the prefix of the KeyVisualizerSamples endpoint,
+	// which exposes the samples of load across the keyspace collected by a
+	// node for the key visualizer.
+	statusKeyVisualizer = statusPrefix + "keyvisualizer/"
+
+	// statusClusterHotRanges exposes the hottest ranges of the cluster,
+	// merged into a single ranking.
+	statusClusterHotRanges = statusPrefix + "hotranges_cluster"
+
+	// statusClusterProblemRanges exposes the ranges with problems across the
+	// cluster, keyed by range ID.
+	statusClusterProblemRanges = statusPrefix + "problemranges_cluster"
+
+	// statusConnectivity exposes the round-trip latencies between all the
+	// nodes of the cluster.
+	statusConnectivity = statusPrefix + "connectivity"
+
+	// statusMemoryMonitors is the prefix of the MemoryMonitors endpoint,
+	// which exposes the tree of the SQL memory monitors of a node.
+	statusMemoryMonitors = statusPrefix + "memory_monitors/"
+
+	// statusClockOffsets is the prefix of the ClockOffsets endpoint, which
+	// exposes the clock offsets of a node to the other nodes.
+	statusClockOffsets = statusPrefix + "clock_offsets/"
+
+	// statusReplicaGC is the prefix of the ReplicaGC endpoint, which forces
+	// replica GC of a range on one of a node's stores.
+	statusReplicaGC = statusPrefix + "replicagc/"
+
+	// statusReloadCertificates is the prefix of the ReloadCertificates
+	// endpoint, which reloads a node's certificates like SIGHUP.
+	statusReloadCertificates = statusPrefix + "reload_certificates/"
+
 	// raftStateDormant is used when there is no known raft state.
 	raftStateDormant = "StateDormant"
 
@@ -109,6 +151,7 @@ var (
 type metricMarshaler interface {
 	json.Marshaler
 	PrintAsText(io.Writer) error
+	PrintAs(io.Writer, expfmt.Format) error
 }
 
 func propagateGatewayMetadata(ctx context.Context) context.Context {
@@ -134,6 +177,8 @@ type statusServer struct {
 	stores          *storage.Stores
 	stopper         *stop.Stopper
 	sessionRegistry *sql.SessionRegistry
+	rootSQLMemory   *mon.BytesMonitor
+	keyVisualizer   *keyvisualizer.Collector
 	si              systemInfoOnce
 }
 
@@ -152,6 +197,8 @@ func newStatusServer(
 	stores *storage.Stores,
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
+	rootSQLMemory *mon.BytesMonitor,
+	keyVisualizer *keyvisualizer.Collector,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
@@ -168,6 +215,8 @@ func newStatusServer(
 		stores:          stores,
 		stopper:         stopper,
 		sessionRegistry: sessionRegistry,
+		rootSQLMemory:   rootSQLMemory,
+		keyVisualizer:   keyVisualizer,
 	}
 
 	return server
@@ -937,21 +986,28 @@ func (s *statusServer) Profile(
 		return status.Profile(ctx, req)
 	}
 
+	var name string
 	switch req.Type {
 	case serverpb.ProfileRequest_HEAP:
-		p := pprof.Lookup("heap")
-		if p == nil {
-			return nil, grpcstatus.Errorf(codes.InvalidArgument, "unable to find profile: heap")
-		}
-		var buf bytes.Buffer
-		if err := p.WriteTo(&buf, 0); err != nil {
-			return nil, grpcstatus.Errorf(codes.Internal, err.Error())
-		}
-		return &serverpb.JSONResponse{Data: buf.Bytes()}, nil
-
+		name = "heap"
+	case serverpb.ProfileRequest_GOROUTINE:
+		name = "goroutine"
+	case serverpb.ProfileRequest_MUTEX:
+		name = "mutex"
+	case serverpb.ProfileRequest_BLOCK:
+		name = "block"
 	default:
 		return nil, grpcstatus.Errorf(codes.InvalidArgument, "unknown profile: %s", req.Type)
 	}
+	p := pprof.Lookup(name)
+	if p == nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, "unable to find profile: %s", name)
+	}
+	var buf bytes.Buffer
+	if err := p.WriteTo(&buf, 0); err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	return &serverpb.JSONResponse{Data: buf.Bytes()}, nil
 }
 
 // Nodes returns all node statuses.
@@ -1157,14 +1213,173 @@ func (s *statusServer) RaftDebug(
 }
 
 func (s *statusServer) handleVars(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set(httputil.ContentTypeHeader, httputil.PlaintextContentType)
-	err := s.metricSource.PrintAsText(w)
+	// Scrapers asking for the protobuf format get histograms as such rather
+	// than as separate bucket, sum and count series. Others get the text
+	// format.
+	format := expfmt.Negotiate(r.Header)
+	if format == expfmt.FmtText {
+		w.Header().Set(httputil.ContentTypeHeader, httputil.PlaintextContentType)
+	} else {
+		w.Header().Set(httputil.ContentTypeHeader, string(format))
+	}
+	err := s.metricSource.PrintAs(w, format)
 	if err != nil {
 		log.Error(r.Context(), err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+func makeSnapshotInfos(infos []storage.SnapshotInfo) []serverpb.SnapshotsResponse_SnapshotInfo {
+	res := make([]serverpb.SnapshotsResponse_SnapshotInfo, len(infos))
+	for i, info := range infos {
+		res[i] = serverpb.SnapshotsResponse_SnapshotInfo{
+			RangeID:  info.RangeID,
+			Type:     info.Type.String(),
+			Priority: info.Priority.String(),
+			Size_:    info.Size,
+			From:     info.From,
+			To:       info.To,
+			Queued:   info.Queued,
+			Age:      info.Age,
+		}
+	}
+	return res
+}
+
+// Snapshots returns the snapshots being sent, received or waiting for a
+// reservation on each of the node's stores.
+func (s *statusServer) Snapshots(
+	ctx context.Context, req *serverpb.SnapshotsRequest,
+) (*serverpb.SnapshotsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.Snapshots(ctx, req)
+	}
+
+	resp := &serverpb.SnapshotsResponse{}
+	if err := s.stores.VisitStores(func(store *storage.Store) error {
+		resp.Stores = append(resp.Stores, serverpb.SnapshotsResponse_StoreSnapshots{
+			StoreID:  store.StoreID(),
+			Incoming: makeSnapshotInfos(store.IncomingSnapshots()),
+			Outgoing: makeSnapshotInfos(store.OutgoingSnapshots()),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// KeyVisualizerSamples returns the samples of load across the keyspace
+// collected by the node for the key visualizer. Since the samples are
+// persisted, they are read without forwarding the request to the node, and
+// remain available while the node is down.
+func (s *statusServer) KeyVisualizerSamples(
+	ctx context.Context, req *serverpb.KeyVisualizerSamplesRequest,
+) (*serverpb.KeyVisualizerSamplesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	nodeID, _, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	samples, err := s.keyVisualizer.Samples(ctx, nodeID)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	return &serverpb.KeyVisualizerSamplesResponse{Samples: samples}, nil
+}
+
+// ReplicaGC forces the replica GC of a range on one of the node's stores.
+// The replica is removed only if it is no longer a member of its range. It
+// runs even while the replica GC queue is paused. It requires the admin role.
+func (s *statusServer) ReplicaGC(
+	ctx context.Context, req *serverpb.ReplicaGCRequest,
+) (*serverpb.ReplicaGCResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	if err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ReplicaGC(ctx, req)
+	}
+
+	if req.StoreID <= 0 || req.RangeID <= 0 {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument,
+			"store_id and range_id must be positive integers")
+	}
+	store, err := s.stores.GetStore(req.StoreID)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.NotFound, err.Error())
+	}
+	if err := store.ForceReplicaGC(ctx, req.RangeID); err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	return &serverpb.ReplicaGCResponse{}, nil
+}
+
+// ReloadCertificates reloads the node's certificates from disk, so that
+// certificates rotated with `cockroach cert rotate` take effect without
+// restarting the node. It is the equivalent of sending SIGHUP to the node,
+// and requires the admin role.
+func (s *statusServer) ReloadCertificates(
+	ctx context.Context, req *serverpb.ReloadCertificatesRequest,
+) (*serverpb.ReloadCertificatesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+	if err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ReloadCertificates(ctx, req)
+	}
+
+	if s.cfg.Insecure {
+		return nil, grpcstatus.Errorf(codes.FailedPrecondition,
+			"cannot reload certificates of an insecure node")
+	}
+	cm, err := s.cfg.GetCertificateManager()
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	if err := cm.LoadCertificates(); err != nil {
+		log.Warningf(ctx, "could not reload certificates: %v", err)
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	log.Info(ctx, "successfully reloaded certificates")
+	return &serverpb.ReloadCertificatesResponse{}, nil
+}
+
 // Ranges returns range info for the specified node.
 func (s *statusServer) Ranges(
 	ctx context.Context, req *serverpb.RangesRequest,
@@ -1271,6 +1486,10 @@ func (s *statusServer) Ranges(
 	isLiveMap := s.nodeLiveness.GetIsLiveMap()
 	clusterNodes := s.storePool.ClusterNodeCount()
 
+	if req.Limit > 0 || req.Offset > 0 {
+		return s.paginatedRanges(ctx, req, constructRangeInfo, isLiveMap, clusterNodes)
+	}
+
 	err = s.stores.VisitStores(func(store *storage.Store) error {
 		timestamp := store.Clock().Now()
 		if len(req.RangeIDs) == 0 {
@@ -1323,6 +1542,65 @@ func (s *statusServer) Ranges(
 	return &output, nil
 }
 
+// paginatedRanges returns the page of the local ranges requested by the limit
+// and offset of req. The replicas are ordered by range ID, then by store ID,
+// and range infos are only constructed for the replicas in the page.
+func (s *statusServer) paginatedRanges(
+	ctx context.Context,
+	req *serverpb.RangesRequest,
+	constructRangeInfo func(
+		roachpb.RangeDescriptor, *storage.Replica, roachpb.StoreID, storage.ReplicaMetrics,
+	) serverpb.RangeInfo,
+	isLiveMap storage.IsLiveMap,
+	clusterNodes int,
+) (*serverpb.RangesResponse, error) {
+	type storeReplica struct {
+		store *storage.Store
+		rep   *storage.Replica
+	}
+	var requested map[roachpb.RangeID]struct{}
+	if len(req.RangeIDs) > 0 {
+		requested = make(map[roachpb.RangeID]struct{}, len(req.RangeIDs))
+		for _, rangeID := range req.RangeIDs {
+			requested[rangeID] = struct{}{}
+		}
+	}
+	var replicas []storeReplica
+	if err := s.stores.VisitStores(func(store *storage.Store) error {
+		store.VisitReplicas(func(rep *storage.Replica) bool {
+			if _, ok := requested[rep.RangeID]; requested == nil || ok {
+				replicas = append(replicas, storeReplica{store: store, rep: rep})
+			}
+			return true // continue
+		})
+		return nil
+	}); err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		if replicas[i].rep.RangeID != replicas[j].rep.RangeID {
+			return replicas[i].rep.RangeID < replicas[j].rep.RangeID
+		}
+		return replicas[i].store.StoreID() < replicas[j].store.StoreID()
+	})
+
+	start, end, next := paginate(len(replicas), req.Limit, req.Offset)
+	output := &serverpb.RangesResponse{
+		Ranges:     make([]serverpb.RangeInfo, 0, end-start),
+		NextOffset: next,
+	}
+	for _, r := range replicas[start:end] {
+		timestamp := r.store.Clock().Now()
+		output.Ranges = append(output.Ranges, constructRangeInfo(
+			*r.rep.Desc(),
+			r.rep,
+			r.store.StoreID(),
+			r.rep.Metrics(ctx, timestamp, isLiveMap, clusterNodes),
+		))
+	}
+	return output, nil
+}
+
 // HotRanges returns the hottest ranges on each store on the requested node(s).
 func (s *statusServer) HotRanges(
 	ctx context.Context, req *serverpb.HotRangesRequest,
@@ -1508,7 +1786,39 @@ func (s *statusServer) ListLocalSessions(
 		userSessions = append(userSessions, session)
 	}
 
-	return &serverpb.ListSessionsResponse{Sessions: userSessions}, nil
+	resp := &serverpb.ListSessionsResponse{Sessions: userSessions}
+	if req.Limit > 0 || req.Offset > 0 {
+		sortSessions(resp.Sessions)
+		start, end, next := paginate(len(resp.Sessions), req.Limit, req.Offset)
+		resp.Sessions, resp.NextOffset = resp.Sessions[start:end], next
+	}
+	return resp, nil
+}
+
+// sortSessions orders sessions by node ID, then by session ID, which is the
+// order in which they are paginated.
+func sortSessions(sessions []serverpb.Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].NodeID != sessions[j].NodeID {
+			return sessions[i].NodeID < sessions[j].NodeID
+		}
+		return bytes.Compare(sessions[i].ID, sessions[j].ID) < 0
+	})
+}
+
+// paginate returns the bounds of the page of the given limit and offset in a
+// list of the given length, and the offset of the next page, or zero if
+// there are no more elements. A zero limit means no limit.
+func paginate(length int, limit, offset int32) (start, end int, next int32) {
+	start, end = int(offset), length
+	if start > length {
+		start = length
+	}
+	if limit > 0 && start+int(limit) < length {
+		end = start + int(limit)
+		next = int32(end)
+	}
+	return start, end, next
 }
 
 // iterateNodes iterates nodeFn over all non-removed nodes concurrently.
@@ -1609,13 +1919,21 @@ func (s *statusServer) ListSessions(
 		client, err := s.dialNode(ctx, nodeID)
 		return client, err
 	}
+	// When paginating, each node returns at most the sessions up to the end
+	// of the requested page, which bounds the size of the merged response.
+	nodeReq := &serverpb.ListSessionsRequest{Username: req.Username}
+	if req.Limit > 0 {
+		nodeReq.Limit = req.Offset + req.Limit
+	}
+	var truncated bool
 	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
 		status := client.(serverpb.StatusClient)
-		return status.ListLocalSessions(ctx, req)
+		return status.ListLocalSessions(ctx, nodeReq)
 	}
 	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
 		sessions := nodeResp.(*serverpb.ListSessionsResponse)
 		response.Sessions = append(response.Sessions, sessions.Sessions...)
+		truncated = truncated || sessions.NextOffset != 0
 	}
 	errorFn := func(nodeID roachpb.NodeID, err error) {
 		errResponse := serverpb.ListSessionsError{NodeID: nodeID, Message: err.Error()}
@@ -1626,6 +1944,15 @@ func (s *statusServer) ListSessions(
 		err := serverpb.ListSessionsError{Message: err.Error()}
 		response.Errors = append(response.Errors, err)
 	}
+	if req.Limit > 0 || req.Offset > 0 {
+		sortSessions(response.Sessions)
+		start, end, next := paginate(len(response.Sessions), req.Limit, req.Offset)
+		if next == 0 && truncated {
+			// A node had more sessions than it returned.
+			next = int32(end)
+		}
+		response.Sessions, response.NextOffset = response.Sessions[start:end], next
+	}
 	return response, nil
 }
 
@@ -1863,6 +2190,61 @@ type superUserChecker interface {
 	RequireAdminRole(ctx context.Context, action string) error
 }
 
+type roleMembershipChecker interface {
+	MemberOfWithAdminOption(ctx context.Context, member string) (map[string]bool, error)
+}
+
+// debugViewerRole is the role whose members, along with the members of the
+// admin role, may access the /debug endpoints.
+const debugViewerRole = "viewdebug"
+
+// hasDebugViewerRole returns whether the user is a member, directly or
+// indirectly, of the admin role or of the debug viewer role.
+func (s *statusServer) hasDebugViewerRole(ctx context.Context, username string) bool {
+	if username == security.RootUser {
+		return true
+	}
+	planner, cleanup := sql.NewInternalPlanner(
+		"check-debug-viewer",
+		client.NewTxn(ctx, s.db, s.gossip.NodeID.Get(), client.RootTxn),
+		username,
+		&sql.MemoryMetrics{},
+		s.admin.server.execCfg)
+	defer cleanup()
+	roles, err := planner.(roleMembershipChecker).MemberOfWithAdminOption(ctx, username)
+	if err != nil {
+		log.Warningf(ctx, "unable to look up the roles of user %q: %v", username, err)
+		return false
+	}
+	_, isAdmin := roles[sqlbase.AdminRole]
+	_, isDebugViewer := roles[debugViewerRole]
+	return isAdmin || isDebugViewer
+}
+
+// requireDebugViewer returns a handler which only serves the requests of the
+// users with the admin role or the debug viewer role. Requests without a web
+// session are treated as made by root.
+func (s *statusServer) requireDebugViewer(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username := httpRequestUser(r)
+		if !s.hasDebugViewerRole(r.Context(), username) {
+			http.Error(w, fmt.Sprintf("user %q does not have the %s or %s role",
+				username, sqlbase.AdminRole, debugViewerRole), http.StatusForbidden)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// httpRequestUser returns the user of the web session of the request, or root
+// if the request has no web session.
+func httpRequestUser(r *http.Request) string {
+	if user, ok := r.Context().Value(webSessionUserKey{}).(string); ok {
+		return user
+	}
+	return security.RootUser
+}
+
 func (s *statusServer) hasAdminRole(ctx context.Context, username string) bool {
 	if username == security.RootUser {
 		return true
@@ -1880,6 +2262,19 @@ func (s *statusServer) hasAdminRole(ctx context.Context, username string) bool {
 	return true
 }
 
+// requireAdminUser returns a PermissionDenied error, which the gateway
+// translates to a 403, unless the user of the request has the admin role.
+func (s *statusServer) requireAdminUser(ctx context.Context) error {
+	username, err := userFromContext(ctx)
+	if err != nil {
+		return grpcstatus.Errorf(codes.Internal, err.Error())
+	}
+	if !s.hasAdminRole(ctx, username) {
+		return grpcstatus.Errorf(codes.PermissionDenied, "user %q does not have the admin role", username)
+	}
+	return nil
+}
+
 type systemInfoOnce struct {
 	once sync.Once
 	info serverpb.SystemInfo
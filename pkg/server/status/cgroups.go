@@ -0,0 +1,167 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package status
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// cgroupRoot is where the cgroup hierarchies are mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupCPUStats are the CPU statistics of the cgroup of the process.
+type cgroupCPUStats struct {
+	// limit is the number of CPUs which the cgroup is allowed to use by its
+	// CPU quota, or zero if the cgroup is unlimited.
+	limit float64
+	// throttledTime is the total time for which the cgroup was throttled
+	// because it exhausted its CPU quota.
+	throttledTime time.Duration
+}
+
+// getCgroupCPUStats returns the CPU statistics of the cgroup of the process,
+// using either the cgroup v1 cpu controller or the cgroup v2 unified
+// hierarchy. It fails on hosts without cgroups.
+func getCgroupCPUStats() (cgroupCPUStats, error) {
+	procCgroup, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return cgroupCPUStats{}, err
+	}
+	path, v2, ok := parseProcCgroup(string(procCgroup))
+	if !ok {
+		return cgroupCPUStats{}, errors.New("no cpu cgroup found in /proc/self/cgroup")
+	}
+	var dirs []string
+	quotaFile := "cpu.cfs_quota_us"
+	if v2 {
+		quotaFile = "cpu.max"
+		dirs = []string{filepath.Join(cgroupRoot, path), cgroupRoot}
+	} else {
+		for _, mount := range []string{"cpu,cpuacct", "cpu"} {
+			// Within containers, the cgroup of the process is usually mounted
+			// at the root of the hierarchy.
+			dirs = append(dirs,
+				filepath.Join(cgroupRoot, mount, path), filepath.Join(cgroupRoot, mount))
+		}
+	}
+	for _, dir := range dirs {
+		quota, err := ioutil.ReadFile(filepath.Join(dir, quotaFile))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return cgroupCPUStats{}, err
+		}
+		var stats cgroupCPUStats
+		if v2 {
+			stats.limit, err = parseCgroupV2CPUMax(string(quota))
+		} else {
+			var period []byte
+			period, err = ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_period_us"))
+			if err != nil {
+				return cgroupCPUStats{}, err
+			}
+			stats.limit, err = parseCgroupV1CPUQuota(string(quota), string(period))
+		}
+		if err != nil {
+			return cgroupCPUStats{}, err
+		}
+		if cpuStat, err := ioutil.ReadFile(filepath.Join(dir, "cpu.stat")); err == nil {
+			stats.throttledTime = parseCgroupCPUStatThrottledTime(string(cpuStat), v2)
+		}
+		return stats, nil
+	}
+	return cgroupCPUStats{}, errors.Errorf("no %s file found for cgroup %s", quotaFile, path)
+}
+
+// parseProcCgroup returns the path of the cgroup of the cpu controller in the
+// given /proc/self/cgroup contents, and whether it belongs to the cgroup v2
+// unified hierarchy. Lines have the form hierarchy-ID:controllers:path, where
+// the controllers of the unified hierarchy are empty.
+func parseProcCgroup(procCgroup string) (path string, v2 bool, ok bool) {
+	for _, line := range strings.Split(procCgroup, "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			path, v2, ok = fields[2], true, true
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller == "cpu" {
+				// The v1 cpu controller takes precedence over the unified
+				// hierarchy in hybrid setups.
+				return fields[2], false, true
+			}
+		}
+	}
+	return path, v2, ok
+}
+
+// parseCgroupV1CPUQuota returns the number of CPUs allowed by the
+// cpu.cfs_quota_us and cpu.cfs_period_us values of a cgroup v1, or zero if
+// the quota is unlimited.
+func parseCgroupV1CPUQuota(quota, period string) (float64, error) {
+	q, err := strconv.ParseInt(strings.TrimSpace(quota), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing cpu.cfs_quota_us")
+	}
+	p, err := strconv.ParseInt(strings.TrimSpace(period), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing cpu.cfs_period_us")
+	}
+	if q <= 0 || p <= 0 {
+		return 0, nil
+	}
+	return float64(q) / float64(p), nil
+}
+
+// parseCgroupV2CPUMax returns the number of CPUs allowed by the cpu.max value
+// of a cgroup v2, which has the form "$MAX $PERIOD", or zero if the maximum is
+// "max".
+func parseCgroupV2CPUMax(cpuMax string) (float64, error) {
+	fields := strings.Fields(cpuMax)
+	if len(fields) != 2 {
+		return 0, errors.Errorf("unexpected cpu.max contents %q", cpuMax)
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+	return parseCgroupV1CPUQuota(fields[0], fields[1])
+}
+
+// parseCgroupCPUStatThrottledTime returns the throttled time in the given
+// cpu.stat contents, which is expressed in nanoseconds by cgroups v1 and in
+// microseconds by cgroups v2.
+func parseCgroupCPUStatThrottledTime(cpuStat string, v2 bool) time.Duration {
+	key, unit := "throttled_time", time.Nanosecond
+	if v2 {
+		key, unit = "throttled_usec", time.Microsecond
+	}
+	for _, line := range strings.Split(cpuStat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			v, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return time.Duration(v) * unit
+		}
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseProcCgroup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		procCgroup string
+		path       string
+		v2         bool
+		ok         bool
+	}{
+		{"", "", false, false},
+		{"12:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n", "/docker/abc", false, true},
+		{"0::/system.slice/cockroach.service\n", "/system.slice/cockroach.service", true, true},
+		// Hybrid hierarchy: the v1 cpu controller takes precedence.
+		{"0::/user.slice\n3:cpuacct,cpu:/kubepods/pod1\n", "/kubepods/pod1", false, true},
+		{"5:memory:/a\n", "", false, false},
+	}
+	for _, tc := range testCases {
+		path, v2, ok := parseProcCgroup(tc.procCgroup)
+		if path != tc.path || v2 != tc.v2 || ok != tc.ok {
+			t.Errorf("%q: expected (%q, %t, %t), got (%q, %t, %t)",
+				tc.procCgroup, tc.path, tc.v2, tc.ok, path, v2, ok)
+		}
+	}
+}
+
+func TestParseCgroupCPULimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		quota, period string
+		expected      float64
+	}{
+		{"-1\n", "100000\n", 0},
+		{"150000\n", "100000\n", 1.5},
+		{"50000", "100000", 0.5},
+	} {
+		limit, err := parseCgroupV1CPUQuota(tc.quota, tc.period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limit != tc.expected {
+			t.Errorf("quota %q, period %q: expected %f, got %f", tc.quota, tc.period, tc.expected, limit)
+		}
+	}
+	if _, err := parseCgroupV1CPUQuota("abc", "100000"); err == nil {
+		t.Error("expected an error for an invalid quota")
+	}
+
+	for _, tc := range []struct {
+		cpuMax   string
+		expected float64
+	}{
+		{"max 100000\n", 0},
+		{"200000 100000\n", 2},
+	} {
+		limit, err := parseCgroupV2CPUMax(tc.cpuMax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limit != tc.expected {
+			t.Errorf("cpu.max %q: expected %f, got %f", tc.cpuMax, tc.expected, limit)
+		}
+	}
+	if _, err := parseCgroupV2CPUMax("max"); err == nil {
+		t.Error("expected an error for an invalid cpu.max")
+	}
+}
+
+func TestParseCgroupCPUStatThrottledTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	v1 := "nr_periods 10\nnr_throttled 2\nthrottled_time 1500\n"
+	if d := parseCgroupCPUStatThrottledTime(v1, false /* v2 */); d != 1500*time.Nanosecond {
+		t.Errorf("expected 1.5µs, got %s", d)
+	}
+	v2 := "usage_usec 100\nnr_throttled 2\nthrottled_usec 7\n"
+	if d := parseCgroupCPUStatThrottledTime(v2, true /* v2 */); d != 7*time.Microsecond {
+		t.Errorf("expected 7µs, got %s", d)
+	}
+	if d := parseCgroupCPUStatThrottledTime("", true /* v2 */); d != 0 {
+		t.Errorf("expected 0, got %s", d)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package status
+
+import "github.com/cockroachdb/cockroach/pkg/util/metric"
+
+// CreateAndAddRules adds the recommended rules over the runtime metrics of
+// the node to the registry.
+func CreateAndAddRules(r *metric.RuleRegistry) {
+	r.AddRule(metric.NewAlertingRule(
+		"FrequentRestarts",
+		"resets(%[1]s[10m]) > 1",
+		[]metric.Metadata{metaUptime},
+		0, /* holdDuration */
+		"warning",
+		"Instance {{ $labels.instance }} restarted {{ $value }} times in 10 minutes",
+		"A node which restarts repeatedly may be crashing.",
+	))
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -45,6 +46,8 @@ import (
 	humanize "github.com/dustin/go-humanize"
 	"github.com/elastic/gosigar"
 	"github.com/pkg/errors"
+	prometheusgo "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 const (
@@ -59,8 +62,48 @@ const (
 	advertiseAddrLabelKey = "advertise-addr"
 	httpAddrLabelKey      = "http-addr"
 	sqlAddrLabelKey       = "sql-addr"
+	nodeIDLabelKey        = "node_id"
 )
 
+// latencyHistogramBuckets configures the buckets of the latency histograms
+// exported to Prometheus. The buckets are the same on all the nodes, so that
+// the histograms can be aggregated across the cluster.
+var latencyHistogramBuckets = settings.RegisterValidatedStringSetting(
+	"server.prometheus.latency_histogram_buckets",
+	"comma-separated list of the upper bounds, as durations, of the buckets of the "+
+		"latency histograms exported to Prometheus; if empty, the buckets of the "+
+		"underlying HDR histograms, which vary with the recorded values, are exported",
+	"100us,250us,500us,1ms,2.5ms,5ms,10ms,25ms,50ms,100ms,250ms,500ms,1s,2.5s,5s,10s",
+	func(_ *settings.Values, s string) error {
+		_, err := parseHistogramBuckets(s)
+		return err
+	},
+)
+
+// parseHistogramBuckets parses a comma-separated list of increasing
+// durations.
+func parseHistogramBuckets(s string) ([]time.Duration, error) {
+	var buckets []time.Duration
+	for _, b := range strings.Split(s, ",") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		d, err := time.ParseDuration(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid histogram bucket %q", b)
+		}
+		if d <= 0 {
+			return nil, errors.Errorf("invalid histogram bucket %q: must be positive", b)
+		}
+		if len(buckets) > 0 && d <= buckets[len(buckets)-1] {
+			return nil, errors.Errorf("histogram buckets must be increasing: %s", s)
+		}
+		buckets = append(buckets, d)
+	}
+	return buckets, nil
+}
+
 type quantile struct {
 	suffix   string
 	quantile float64
@@ -236,13 +279,16 @@ func (mr *MetricsRecorder) MarshalJSON() ([]byte, error) {
 }
 
 // scrapePrometheusLocked updates the prometheusExporter's metrics snapshot.
+// The metrics are labeled with the node ID, in addition to the store ID of
+// store metrics, so that they can be aggregated across the nodes of the
+// cluster.
 func (mr *MetricsRecorder) scrapePrometheusLocked() {
-	mr.scrapeIntoPrometheus(&mr.promMu.prometheusExporter)
+	mr.scrapeIntoPrometheus(&mr.promMu.prometheusExporter, true /* withNodeID */)
 }
 
 // scrapeIntoPrometheus updates the passed-in prometheusExporter's metrics
-// snapshot.
-func (mr *MetricsRecorder) scrapeIntoPrometheus(pm *metric.PrometheusExporter) {
+// snapshot. withNodeID labels the metrics with the node ID.
+func (mr *MetricsRecorder) scrapeIntoPrometheus(pm *metric.PrometheusExporter, withNodeID bool) {
 	mr.mu.RLock()
 	defer mr.mu.RUnlock()
 	if mr.mu.nodeRegistry == nil {
@@ -250,11 +296,16 @@ func (mr *MetricsRecorder) scrapeIntoPrometheus(pm *metric.PrometheusExporter) {
 		if log.V(1) {
 			log.Warning(context.TODO(), "MetricsRecorder asked to scrape metrics before NodeID allocation")
 		}
+		return
 	}
 
-	pm.ScrapeRegistry(mr.mu.nodeRegistry)
+	var labels []*prometheusgo.LabelPair
+	if withNodeID {
+		labels = append(labels, metric.MakeLabelPair(nodeIDLabelKey, mr.mu.desc.NodeID.String()))
+	}
+	pm.ScrapeRegistryWithLabels(mr.mu.nodeRegistry, labels...)
 	for _, reg := range mr.mu.storeRegistries {
-		pm.ScrapeRegistry(reg)
+		pm.ScrapeRegistryWithLabels(reg, labels...)
 	}
 }
 
@@ -262,21 +313,34 @@ func (mr *MetricsRecorder) scrapeIntoPrometheus(pm *metric.PrometheusExporter) {
 // We write metrics to a temporary buffer which is then copied to the writer.
 // This is to avoid hanging requests from holding the lock.
 func (mr *MetricsRecorder) PrintAsText(w io.Writer) error {
+	return mr.PrintAs(w, expfmt.FmtText)
+}
+
+// PrintAs is like PrintAsText, but writes the metrics in the given prometheus
+// exposition format.
+func (mr *MetricsRecorder) PrintAs(w io.Writer, format expfmt.Format) error {
 	var buf bytes.Buffer
-	if err := mr.lockAndPrintAsText(&buf); err != nil {
+	if err := mr.lockAndPrintAs(&buf, format); err != nil {
 		return err
 	}
 	_, err := buf.WriteTo(w)
 	return err
 }
 
-// lockAndPrintAsText grabs the recorder lock and generates the prometheus
+// lockAndPrintAs grabs the recorder lock and generates the prometheus
 // metrics page.
-func (mr *MetricsRecorder) lockAndPrintAsText(w io.Writer) error {
+func (mr *MetricsRecorder) lockAndPrintAs(w io.Writer, format expfmt.Format) error {
 	mr.promMu.Lock()
 	defer mr.promMu.Unlock()
+	// The setting is validated, but is parsed again on every scrape so that
+	// changes are picked up.
+	buckets, err := parseHistogramBuckets(latencyHistogramBuckets.Get(&mr.settings.SV))
+	if err != nil {
+		return err
+	}
+	mr.promMu.prometheusExporter.SetLatencyBuckets(buckets)
 	mr.scrapePrometheusLocked()
-	return mr.promMu.prometheusExporter.PrintAsText(w)
+	return mr.promMu.prometheusExporter.PrintAs(w, format)
 }
 
 // ExportToGraphite sends the current metric values to a Graphite server.
@@ -286,7 +350,8 @@ func (mr *MetricsRecorder) lockAndPrintAsText(w io.Writer) error {
 func (mr *MetricsRecorder) ExportToGraphite(
 	ctx context.Context, endpoint string, pm *metric.PrometheusExporter,
 ) error {
-	mr.scrapeIntoPrometheus(pm)
+	// The node and store IDs are part of the names of the graphite metrics.
+	mr.scrapeIntoPrometheus(pm, false /* withNodeID */)
 	graphiteExporter := metric.MakeGraphiteExporter(pm)
 	return graphiteExporter.Push(ctx, endpoint)
 }
@@ -11,13 +11,16 @@
 package status
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"io/ioutil"
 	"os"
 	"reflect"
 	"runtime"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -27,12 +30,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/kr/pretty"
+	prometheusgo "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 // byTimeAndName is a slice of tspb.TimeSeriesData.
@@ -385,3 +391,112 @@ func TestMetricsRecorder(t *testing.T) {
 	wg.Wait()
 	recorder.mu.RUnlock()
 }
+
+// TestMetricsRecorderPrometheusLabels verifies that the prometheus metrics are
+// labeled with the node and store IDs, in both the text and protobuf formats.
+func TestMetricsRecorderPrometheusLabels(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	nodeReg := metric.NewRegistry()
+	nodeReg.AddMetric(metric.NewGauge(metric.Metadata{Name: "node.gauge"}))
+	store := fakeStore{
+		storeID:  roachpb.StoreID(3),
+		registry: metric.NewRegistry(),
+	}
+	hist := metric.NewHistogram(metric.Metadata{Name: "store.histogram"}, time.Minute, 1000, 1)
+	hist.RecordValue(10)
+	store.registry.AddMetric(hist)
+
+	st := cluster.MakeTestingClusterSettings()
+	recorder := NewMetricsRecorder(hlc.NewClock(hlc.UnixNano, time.Nanosecond), nil, nil, nil, st)
+	var buf bytes.Buffer
+	if err := recorder.PrintAsText(&buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected no metrics before the node is added, got %q (%v)", buf.String(), err)
+	}
+	recorder.AddStore(store)
+	recorder.AddNode(nodeReg, roachpb.NodeDescriptor{NodeID: 2}, 50, "foo:26257", "foo:26258", "foo:5432")
+
+	if err := recorder.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`node_gauge{node_id="2"} 0`,
+		`store_histogram_bucket{node_id="2",store="3",le="+Inf"} 1`,
+		`store_histogram_count{node_id="2",store="3"} 1`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := recorder.PrintAs(&buf, expfmt.FmtProtoDelim); err != nil {
+		t.Fatal(err)
+	}
+	families := make(map[string]*prometheusgo.MetricFamily)
+	for dec := expfmt.NewDecoder(&buf, expfmt.FmtProtoDelim); ; {
+		var family prometheusgo.MetricFamily
+		if err := dec.Decode(&family); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		families[family.GetName()] = &family
+	}
+	family, ok := families["store_histogram"]
+	if !ok || family.GetType() != prometheusgo.MetricType_HISTOGRAM || len(family.Metric) != 1 {
+		t.Fatalf("unexpected histogram family %+v", family)
+	}
+	if count := family.Metric[0].GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected a single sample, got %d", count)
+	}
+	var labels []string
+	for _, l := range family.Metric[0].Label {
+		labels = append(labels, l.GetName()+"="+l.GetValue())
+	}
+	if expected := []string{"node_id=2", "store=3"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+
+	// The latency histograms are exported with the configured buckets.
+	latency := metric.NewLatency(
+		metric.Metadata{Name: "store.latency", Unit: metric.Unit_NANOSECONDS}, time.Minute)
+	latency.RecordValue(time.Millisecond.Nanoseconds())
+	store.registry.AddMetric(latency)
+	latencyHistogramBuckets.Override(&st.SV, "2ms,1s")
+	buf.Reset()
+	if err := recorder.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`store_latency_bucket{node_id="2",store="3",le="2e+06"} 1`,
+		`store_latency_bucket{node_id="2",store="3",le="1e+09"} 1`,
+		`store_latency_bucket{node_id="2",store="3",le="+Inf"} 1`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestParseHistogramBuckets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, tc := range []struct {
+		buckets string
+		exp     []time.Duration
+		expErr  string
+	}{
+		{"", nil, ""},
+		{"1ms, 10ms,1s", []time.Duration{time.Millisecond, 10 * time.Millisecond, time.Second}, ""},
+		{"1ms,x", nil, "invalid histogram bucket"},
+		{"0s", nil, "must be positive"},
+		{"1s,1ms", nil, "must be increasing"},
+	} {
+		buckets, err := parseHistogramBuckets(tc.buckets)
+		if !testutils.IsError(err, tc.expErr) {
+			t.Errorf("%q: expected error %q, got %v", tc.buckets, tc.expErr, err)
+		} else if err == nil && !reflect.DeepEqual(buckets, tc.exp) {
+			t.Errorf("%q: expected %v, got %v", tc.buckets, tc.exp, buckets)
+		}
+	}
+}
@@ -112,6 +112,18 @@ var (
 		Measurement: "CPU Time",
 		Unit:        metric.Unit_PERCENT,
 	}
+	metaCPUCgroupLimit = metric.Metadata{
+		Name:        "sys.cpu.cgroup.limit",
+		Help:        "Number of CPUs allowed by the CPU quota of the cgroup of the process, or 0 if unlimited",
+		Measurement: "CPUs",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaCPUCgroupThrottledNS = metric.Metadata{
+		Name:        "sys.cpu.cgroup.throttled.ns",
+		Help:        "Total time for which the cgroup of the process was throttled by its CPU quota",
+		Measurement: "CPU Time",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
 	metaRSSBytes = metric.Metadata{
 		Name:        "sys.rss",
 		Help:        "Current process RSS",
@@ -195,6 +207,30 @@ var (
 		Measurement: "Operations",
 		Help:        "IO operations currently in progress on this host",
 	}
+	metaHostDiskReadIOPS = metric.Metadata{
+		Name:        "sys.host.disk.read.iops",
+		Unit:        metric.Unit_COUNT,
+		Measurement: "Operations/Sec",
+		Help:        "Current rate of disk read operations across all disks",
+	}
+	metaHostDiskReadThroughput = metric.Metadata{
+		Name:        "sys.host.disk.read.throughput",
+		Unit:        metric.Unit_BYTES,
+		Measurement: "Bytes/Sec",
+		Help:        "Current rate of bytes read from all disks",
+	}
+	metaHostDiskWriteIOPS = metric.Metadata{
+		Name:        "sys.host.disk.write.iops",
+		Unit:        metric.Unit_COUNT,
+		Measurement: "Operations/Sec",
+		Help:        "Current rate of disk write operations across all disks",
+	}
+	metaHostDiskWriteThroughput = metric.Metadata{
+		Name:        "sys.host.disk.write.throughput",
+		Unit:        metric.Unit_BYTES,
+		Measurement: "Bytes/Sec",
+		Help:        "Current rate of bytes written to all disks",
+	}
 	metaHostNetRecvBytes = metric.Metadata{
 		Name:        "sys.host.net.recv.bytes",
 		Unit:        metric.Unit_BYTES,
@@ -255,6 +291,8 @@ type RuntimeStatSampler struct {
 
 	// Only show "not implemented" errors once, we don't need the log spam.
 	fdUsageNotImplemented bool
+	// Likewise, only warn once on hosts without cgroups.
+	cgroupStatsUnavailable bool
 
 	// Metric gauges maintained by the sampler.
 	// Go runtime stats.
@@ -273,25 +311,31 @@ type RuntimeStatSampler struct {
 	CPUSysNS               *metric.Gauge
 	CPUSysPercent          *metric.GaugeFloat64
 	CPUCombinedPercentNorm *metric.GaugeFloat64
+	CPUCgroupLimit         *metric.GaugeFloat64
+	CPUCgroupThrottledNS   *metric.Gauge
 	// Memory stats.
 	RSSBytes *metric.Gauge
 	// File descriptor stats.
 	FDOpen      *metric.Gauge
 	FDSoftLimit *metric.Gauge
 	// Disk and network stats.
-	HostDiskReadBytes      *metric.Gauge
-	HostDiskReadCount      *metric.Gauge
-	HostDiskReadTime       *metric.Gauge
-	HostDiskWriteBytes     *metric.Gauge
-	HostDiskWriteCount     *metric.Gauge
-	HostDiskWriteTime      *metric.Gauge
-	HostDiskIOTime         *metric.Gauge
-	HostDiskWeightedIOTime *metric.Gauge
-	IopsInProgress         *metric.Gauge
-	HostNetRecvBytes       *metric.Gauge
-	HostNetRecvPackets     *metric.Gauge
-	HostNetSendBytes       *metric.Gauge
-	HostNetSendPackets     *metric.Gauge
+	HostDiskReadBytes       *metric.Gauge
+	HostDiskReadCount       *metric.Gauge
+	HostDiskReadTime        *metric.Gauge
+	HostDiskWriteBytes      *metric.Gauge
+	HostDiskWriteCount      *metric.Gauge
+	HostDiskWriteTime       *metric.Gauge
+	HostDiskIOTime          *metric.Gauge
+	HostDiskWeightedIOTime  *metric.Gauge
+	IopsInProgress          *metric.Gauge
+	HostDiskReadIOPS        *metric.GaugeFloat64
+	HostDiskReadThroughput  *metric.GaugeFloat64
+	HostDiskWriteIOPS       *metric.GaugeFloat64
+	HostDiskWriteThroughput *metric.GaugeFloat64
+	HostNetRecvBytes        *metric.Gauge
+	HostNetRecvPackets      *metric.Gauge
+	HostNetSendBytes        *metric.Gauge
+	HostNetSendPackets      *metric.Gauge
 	// Uptime and build.
 	Uptime         *metric.Gauge // We use a gauge to be able to call Update.
 	BuildTimestamp *metric.Gauge
@@ -331,42 +375,48 @@ func NewRuntimeStatSampler(ctx context.Context, clock *hlc.Clock) *RuntimeStatSa
 	}
 
 	rsr := &RuntimeStatSampler{
-		clock:                  clock,
-		startTimeNanos:         clock.PhysicalNow(),
-		initialNetCounters:     netCounters,
-		initialDiskCounters:    diskCounters,
-		CgoCalls:               metric.NewGauge(metaCgoCalls),
-		Goroutines:             metric.NewGauge(metaGoroutines),
-		GoAllocBytes:           metric.NewGauge(metaGoAllocBytes),
-		GoTotalBytes:           metric.NewGauge(metaGoTotalBytes),
-		CgoAllocBytes:          metric.NewGauge(metaCgoAllocBytes),
-		CgoTotalBytes:          metric.NewGauge(metaCgoTotalBytes),
-		GcCount:                metric.NewGauge(metaGCCount),
-		GcPauseNS:              metric.NewGauge(metaGCPauseNS),
-		GcPausePercent:         metric.NewGaugeFloat64(metaGCPausePercent),
-		CPUUserNS:              metric.NewGauge(metaCPUUserNS),
-		CPUUserPercent:         metric.NewGaugeFloat64(metaCPUUserPercent),
-		CPUSysNS:               metric.NewGauge(metaCPUSysNS),
-		CPUSysPercent:          metric.NewGaugeFloat64(metaCPUSysPercent),
-		CPUCombinedPercentNorm: metric.NewGaugeFloat64(metaCPUCombinedPercentNorm),
-		RSSBytes:               metric.NewGauge(metaRSSBytes),
-		HostDiskReadBytes:      metric.NewGauge(metaHostDiskReadBytes),
-		HostDiskReadCount:      metric.NewGauge(metaHostDiskReadCount),
-		HostDiskReadTime:       metric.NewGauge(metaHostDiskReadTime),
-		HostDiskWriteBytes:     metric.NewGauge(metaHostDiskWriteBytes),
-		HostDiskWriteCount:     metric.NewGauge(metaHostDiskWriteCount),
-		HostDiskWriteTime:      metric.NewGauge(metaHostDiskWriteTime),
-		HostDiskIOTime:         metric.NewGauge(metaHostDiskIOTime),
-		HostDiskWeightedIOTime: metric.NewGauge(metaHostDiskWeightedIOTime),
-		IopsInProgress:         metric.NewGauge(metaHostIopsInProgress),
-		HostNetRecvBytes:       metric.NewGauge(metaHostNetRecvBytes),
-		HostNetRecvPackets:     metric.NewGauge(metaHostNetRecvPackets),
-		HostNetSendBytes:       metric.NewGauge(metaHostNetSendBytes),
-		HostNetSendPackets:     metric.NewGauge(metaHostNetSendPackets),
-		FDOpen:                 metric.NewGauge(metaFDOpen),
-		FDSoftLimit:            metric.NewGauge(metaFDSoftLimit),
-		Uptime:                 metric.NewGauge(metaUptime),
-		BuildTimestamp:         buildTimestamp,
+		clock:                   clock,
+		startTimeNanos:          clock.PhysicalNow(),
+		initialNetCounters:      netCounters,
+		initialDiskCounters:     diskCounters,
+		CgoCalls:                metric.NewGauge(metaCgoCalls),
+		Goroutines:              metric.NewGauge(metaGoroutines),
+		GoAllocBytes:            metric.NewGauge(metaGoAllocBytes),
+		GoTotalBytes:            metric.NewGauge(metaGoTotalBytes),
+		CgoAllocBytes:           metric.NewGauge(metaCgoAllocBytes),
+		CgoTotalBytes:           metric.NewGauge(metaCgoTotalBytes),
+		GcCount:                 metric.NewGauge(metaGCCount),
+		GcPauseNS:               metric.NewGauge(metaGCPauseNS),
+		GcPausePercent:          metric.NewGaugeFloat64(metaGCPausePercent),
+		CPUUserNS:               metric.NewGauge(metaCPUUserNS),
+		CPUUserPercent:          metric.NewGaugeFloat64(metaCPUUserPercent),
+		CPUSysNS:                metric.NewGauge(metaCPUSysNS),
+		CPUSysPercent:           metric.NewGaugeFloat64(metaCPUSysPercent),
+		CPUCombinedPercentNorm:  metric.NewGaugeFloat64(metaCPUCombinedPercentNorm),
+		CPUCgroupLimit:          metric.NewGaugeFloat64(metaCPUCgroupLimit),
+		CPUCgroupThrottledNS:    metric.NewGauge(metaCPUCgroupThrottledNS),
+		RSSBytes:                metric.NewGauge(metaRSSBytes),
+		HostDiskReadBytes:       metric.NewGauge(metaHostDiskReadBytes),
+		HostDiskReadCount:       metric.NewGauge(metaHostDiskReadCount),
+		HostDiskReadTime:        metric.NewGauge(metaHostDiskReadTime),
+		HostDiskWriteBytes:      metric.NewGauge(metaHostDiskWriteBytes),
+		HostDiskWriteCount:      metric.NewGauge(metaHostDiskWriteCount),
+		HostDiskWriteTime:       metric.NewGauge(metaHostDiskWriteTime),
+		HostDiskIOTime:          metric.NewGauge(metaHostDiskIOTime),
+		HostDiskWeightedIOTime:  metric.NewGauge(metaHostDiskWeightedIOTime),
+		IopsInProgress:          metric.NewGauge(metaHostIopsInProgress),
+		HostDiskReadIOPS:        metric.NewGaugeFloat64(metaHostDiskReadIOPS),
+		HostDiskReadThroughput:  metric.NewGaugeFloat64(metaHostDiskReadThroughput),
+		HostDiskWriteIOPS:       metric.NewGaugeFloat64(metaHostDiskWriteIOPS),
+		HostDiskWriteThroughput: metric.NewGaugeFloat64(metaHostDiskWriteThroughput),
+		HostNetRecvBytes:        metric.NewGauge(metaHostNetRecvBytes),
+		HostNetRecvPackets:      metric.NewGauge(metaHostNetRecvPackets),
+		HostNetSendBytes:        metric.NewGauge(metaHostNetSendBytes),
+		HostNetSendPackets:      metric.NewGauge(metaHostNetSendPackets),
+		FDOpen:                  metric.NewGauge(metaFDOpen),
+		FDSoftLimit:             metric.NewGauge(metaFDSoftLimit),
+		Uptime:                  metric.NewGauge(metaUptime),
+		BuildTimestamp:          buildTimestamp,
 	}
 	rsr.last.disk = rsr.initialDiskCounters
 	rsr.last.net = rsr.initialNetCounters
@@ -425,10 +475,12 @@ func (rsr *RuntimeStatSampler) SampleEnvironment(ctx context.Context, ms GoMemSt
 	}
 
 	var deltaDisk diskStats
+	haveDiskStats := false
 	diskCounters, err := getSummedDiskCounters(ctx)
 	if err != nil {
 		log.Warningf(ctx, "problem fetching disk stats: %s; disk stats will be empty.", err)
 	} else {
+		haveDiskStats = true
 		deltaDisk = diskCounters
 		subtractDiskCounters(&deltaDisk, rsr.last.disk)
 		rsr.last.disk = diskCounters
@@ -478,6 +530,25 @@ func (rsr *RuntimeStatSampler) SampleEnvironment(ctx context.Context, ms GoMemSt
 	rsr.last.stime = stime
 	rsr.last.gcPauseTime = uint64(gc.PauseTotal)
 
+	if haveDiskStats && dur > 0 {
+		perSec := float64(time.Second) / dur
+		rsr.HostDiskReadIOPS.Update(float64(deltaDisk.readCount) * perSec)
+		rsr.HostDiskReadThroughput.Update(float64(deltaDisk.readBytes) * perSec)
+		rsr.HostDiskWriteIOPS.Update(float64(deltaDisk.writeCount) * perSec)
+		rsr.HostDiskWriteThroughput.Update(float64(deltaDisk.writeBytes) * perSec)
+	}
+
+	cgroupStats, err := getCgroupCPUStats()
+	if err != nil {
+		if !rsr.cgroupStatsUnavailable {
+			rsr.cgroupStatsUnavailable = true
+			log.Warningf(ctx, "unable to get cgroup CPU stats (will not warn again): %s", err)
+		}
+	} else {
+		rsr.CPUCgroupLimit.Update(cgroupStats.limit)
+		rsr.CPUCgroupThrottledNS.Update(cgroupStats.throttledTime.Nanoseconds())
+	}
+
 	var cgoAllocated, cgoTotal uint
 	if getCgoMemStats != nil {
 		var err error
@@ -495,15 +566,16 @@ func (rsr *RuntimeStatSampler) SampleEnvironment(ctx context.Context, ms GoMemSt
 		staleMsg = "(stale)"
 	}
 	goTotal := ms.Sys - ms.HeapReleased
-	log.Infof(ctx, "runtime stats: %s RSS, %d goroutines, %s/%s/%s GO alloc/idle/total%s, "+
+	log.Health.Infof(ctx, "runtime stats: %s RSS, %d goroutines, %s/%s/%s GO alloc/idle/total%s, "+
 		"%s/%s CGO alloc/total, %.1f CGO/sec, %.1f/%.1f %%(u/s)time, %.1f %%gc (%dx), "+
-		"%s/%s (r/w)net",
+		"%s/%s (r/w)net, %s/%s (r/w)disk",
 		humanize.IBytes(mem.Resident), numGoroutine,
 		humanize.IBytes(ms.HeapAlloc), humanize.IBytes(ms.HeapIdle), humanize.IBytes(goTotal),
 		staleMsg,
 		humanize.IBytes(uint64(cgoAllocated)), humanize.IBytes(uint64(cgoTotal)),
 		cgoRate, 100*uPerc, 100*sPerc, 100*gcPausePercent, gc.NumGC-rsr.last.gcCount,
 		humanize.IBytes(deltaNet.BytesRecv), humanize.IBytes(deltaNet.BytesSent),
+		humanize.IBytes(uint64(deltaDisk.readBytes)), humanize.IBytes(uint64(deltaDisk.writeBytes)),
 	)
 	rsr.last.cgoCall = numCgoCall
 	rsr.last.gcCount = gc.NumGC
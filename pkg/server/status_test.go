@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -30,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/keyvisualizer/keyvisualizerpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -54,6 +56,10 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/kr/pretty"
 	"github.com/pkg/errors"
+	prometheusgo "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 func getStatusJSONProto(
@@ -842,6 +848,75 @@ func TestRangesResponse(t *testing.T) {
 	}
 }
 
+func TestRangesResponsePagination(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stopper().Stop(context.TODO())
+
+	var all serverpb.RangesResponse
+	if err := getStatusJSONProto(ts, "ranges/local", &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Ranges) < 5 || all.NextOffset != 0 {
+		t.Fatalf("unexpected unpaginated response: %d ranges, next offset %d",
+			len(all.Ranges), all.NextOffset)
+	}
+
+	// Page through the ranges, and check that each one is seen exactly once
+	// and in order.
+	var rangeIDs []roachpb.RangeID
+	for offset := int32(0); ; {
+		var page serverpb.RangesResponse
+		if err := getStatusJSONProto(
+			ts, fmt.Sprintf("ranges/local?limit=2&offset=%d", offset), &page,
+		); err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Ranges) > 2 {
+			t.Fatalf("expected at most 2 ranges, got %d", len(page.Ranges))
+		}
+		for _, ri := range page.Ranges {
+			rangeIDs = append(rangeIDs, ri.State.Desc.RangeID)
+		}
+		if page.NextOffset == 0 {
+			break
+		}
+		offset = page.NextOffset
+	}
+	if len(rangeIDs) != len(all.Ranges) {
+		t.Fatalf("expected %d ranges, got %d", len(all.Ranges), len(rangeIDs))
+	}
+	if !sort.SliceIsSorted(rangeIDs, func(i, j int) bool { return rangeIDs[i] < rangeIDs[j] }) {
+		t.Fatalf("ranges are not ordered by range ID: %v", rangeIDs)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		length           int
+		limit, offset    int32
+		expStart, expEnd int
+		expNext          int32
+	}{
+		{10, 0, 0, 0, 10, 0},
+		{10, 3, 0, 0, 3, 3},
+		{10, 3, 9, 9, 10, 0},
+		{10, 5, 5, 5, 10, 0},
+		{10, 0, 4, 4, 10, 0},
+		{10, 3, 20, 10, 10, 0},
+		{0, 3, 0, 0, 0, 0},
+	}
+	for _, tc := range testCases {
+		start, end, next := paginate(tc.length, tc.limit, tc.offset)
+		if start != tc.expStart || end != tc.expEnd || next != tc.expNext {
+			t.Errorf("paginate(%d, %d, %d): expected (%d, %d, %d), got (%d, %d, %d)",
+				tc.length, tc.limit, tc.offset, tc.expStart, tc.expEnd, tc.expNext, start, end, next)
+		}
+	}
+}
+
 func TestRaftDebug(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s := startServer(t)
@@ -902,6 +977,181 @@ func TestStatusVars(t *testing.T) {
 		t.Fatal(err)
 	} else if !bytes.Contains(body, []byte("# TYPE sql_bytesout counter\nsql_bytesout")) {
 		t.Errorf("expected sql_bytesout, got: %s", body)
+	} else if !bytes.Contains(body, []byte(`sql_bytesout{node_id="1"}`)) {
+		t.Errorf("expected sql_bytesout to be labeled with the node ID, got: %s", body)
+	}
+
+	// The protobuf format is served to the scrapers asking for it.
+	httpClient, err := s.GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("GET", s.AdminURL()+statusPrefix+"vars", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if format := expfmt.ResponseFormat(resp.Header); format != expfmt.FmtProtoDelim {
+		t.Fatalf("expected format %s, got %s", expfmt.FmtProtoDelim, format)
+	}
+	var family prometheusgo.MetricFamily
+	if err := expfmt.NewDecoder(resp.Body, expfmt.FmtProtoDelim).Decode(&family); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStatusRules verifies that the recommended rules are available via the
+// /_status/rules endpoint, and that their metrics are exported by the
+// /_status/vars endpoint.
+func TestStatusRules(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	body, err := getText(s, s.AdminURL()+statusRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"- alert: UnavailableRanges\n",
+		"- record: cluster:capacity_available:sum\n",
+		"expr: capacity_available / capacity < 0.15\n",
+	} {
+		if !bytes.Contains(body, []byte(expected)) {
+			t.Errorf("expected %q, got: %s", expected, body)
+		}
+	}
+
+	vars, err := getText(s, s.AdminURL()+statusVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := s.(*TestServer).ruleRegistry
+	if err := rules.CheckMetrics(func(name string) bool {
+		return bytes.Contains(vars, []byte("# TYPE "+name+" "))
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStatusSnapshots verifies that the snapshots of each store are available
+// via the /_status/snapshots endpoint.
+func TestStatusSnapshots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	var resp serverpb.SnapshotsResponse
+	if err := getStatusJSONProto(s, "snapshots/local", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Stores) != 1 || resp.Stores[0].StoreID != s.GetFirstStoreID() {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+// TestStatusKeyVisualizerSamples verifies that the samples persisted for the
+// key visualizer are available via the /_status/keyvisualizer endpoint.
+func TestStatusKeyVisualizerSamples(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Collection is disabled by default, so no samples are retained.
+	var resp serverpb.KeyVisualizerSamplesResponse
+	if err := getStatusJSONProto(s, "keyvisualizer/local", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Samples) != 0 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	sample := keyvisualizerpb.Sample{
+		Time: timeutil.Now().Round(time.Second).UTC(),
+		Buckets: []keyvisualizerpb.Bucket{{
+			Span:              roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+			RequestsPerSecond: 1,
+			WritesPerSecond:   2,
+		}},
+	}
+	if err := kvDB.Put(
+		context.TODO(), keys.KeyVisualizerSampleKey(s.NodeID(), sample.Time.UnixNano()), &sample,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := getStatusJSONProto(s, "keyvisualizer/local", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Samples) != 1 || !resp.Samples[0].Time.Equal(sample.Time) ||
+		!reflect.DeepEqual(resp.Samples[0].Buckets, sample.Buckets) {
+		t.Fatalf("expected %+v, found %+v", sample, resp.Samples)
+	}
+}
+
+func TestStatusReplicaGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	// The user of the authenticated HTTP client doesn't have the admin role.
+	req := &serverpb.ReplicaGCRequest{StoreID: s.GetFirstStoreID(), RangeID: 1}
+	var resp serverpb.ReplicaGCResponse
+	if err := serverutils.PostJSONProto(
+		s, statusReplicaGC+"local", req, &resp,
+	); !testutils.IsError(err, "403 Forbidden") {
+		t.Fatalf("expected a 403 error, got: %v", err)
+	}
+
+	// Requests made over gRPC are made by root.
+	for _, tc := range []struct {
+		req      serverpb.ReplicaGCRequest
+		expected codes.Code
+	}{
+		{serverpb.ReplicaGCRequest{NodeId: "x", StoreID: s.GetFirstStoreID(), RangeID: 1}, codes.InvalidArgument},
+		{serverpb.ReplicaGCRequest{StoreID: s.GetFirstStoreID()}, codes.InvalidArgument},
+		{serverpb.ReplicaGCRequest{StoreID: 1000, RangeID: 1}, codes.NotFound},
+		// The replica is still a member of its range, so it is retained.
+		{serverpb.ReplicaGCRequest{StoreID: s.GetFirstStoreID(), RangeID: 1}, codes.OK},
+	} {
+		_, err := ts.status.ReplicaGC(context.TODO(), &tc.req)
+		if code := grpcstatus.Code(err); code != tc.expected {
+			t.Errorf("%+v: expected code %s, found %v", tc.req, tc.expected, err)
+		}
+	}
+	store, err := s.GetStores().(*storage.Stores).GetStore(s.GetFirstStoreID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetReplica(1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatusReloadCertificates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	// The user of the authenticated HTTP client doesn't have the admin role.
+	var resp serverpb.ReloadCertificatesResponse
+	if err := serverutils.PostJSONProto(
+		s, statusReloadCertificates+"local", &serverpb.ReloadCertificatesRequest{}, &resp,
+	); !testutils.IsError(err, "403 Forbidden") {
+		t.Fatalf("expected a 403 error, got: %v", err)
+	}
+
+	// Requests made over gRPC are made by root.
+	if _, err := ts.status.ReloadCertificates(
+		context.TODO(), &serverpb.ReloadCertificatesRequest{NodeId: "local"},
+	); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -0,0 +1,209 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxStmtDiagnosticsTraceWait is the maximum duration for which
+// StatementDiagnosticsTrace waits for a request to be completed.
+const maxStmtDiagnosticsTraceWait = 10 * time.Minute
+
+func makeStatementDiagnosticsReport(
+	req stmtdiagnostics.Request,
+) serverpb.StatementDiagnosticsReport {
+	r := serverpb.StatementDiagnosticsReport{
+		ID:                   req.ID,
+		StatementFingerprint: req.Fingerprint,
+		SessionID:            req.SessionID,
+		RequestedAt:          req.RequestedAt,
+		MinExecutionLatency:  req.MinExecutionLatency,
+		SamplingProbability:  req.SamplingProbability,
+		Completed:            req.Completed(),
+	}
+	if !req.ExpiresAt.IsZero() {
+		expiresAt := req.ExpiresAt
+		r.ExpiresAt = &expiresAt
+	}
+	if r.Completed {
+		collectedAt := req.CollectedAt
+		r.CollectedAt = &collectedAt
+		r.Statement = req.Statement
+		r.BundleSize = int64(len(req.Bundle))
+	}
+	return r
+}
+
+// StatementDiagnosticsReports lists the statement diagnostics requests of
+// the node.
+func (s *adminServer) StatementDiagnosticsReports(
+	ctx context.Context, req *serverpb.StatementDiagnosticsReportsRequest,
+) (*serverpb.StatementDiagnosticsReportsResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	reqs := s.server.execCfg.StmtDiagnosticsRegistry.Requests()
+	resp := &serverpb.StatementDiagnosticsReportsResponse{
+		Reports: make([]serverpb.StatementDiagnosticsReport, len(reqs)),
+	}
+	for i := range reqs {
+		resp.Reports[i] = makeStatementDiagnosticsReport(reqs[i])
+	}
+	return resp, nil
+}
+
+// CreateStatementDiagnosticsReport requests the diagnostics of a statement
+// fingerprint or of the next statement of a session. The session must be
+// connected to this node.
+func (s *adminServer) CreateStatementDiagnosticsReport(
+	ctx context.Context, req *serverpb.CreateStatementDiagnosticsReportRequest,
+) (*serverpb.CreateStatementDiagnosticsReportResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	if (req.StatementFingerprint == "") == (req.SessionID == "") {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"either statement_fingerprint or session_id must be specified")
+	}
+	registry := s.server.execCfg.StmtDiagnosticsRegistry
+	var id int64
+	var err error
+	if req.StatementFingerprint != "" {
+		id, err = registry.InsertConditionalRequest(
+			req.StatementFingerprint, req.MinExecutionLatency, req.ExpiresAfter,
+			req.SamplingProbability)
+	} else {
+		if !s.server.status.hasLocalSession(req.SessionID) {
+			return nil, status.Errorf(codes.NotFound, "no session %s on this node", req.SessionID)
+		}
+		id, err = registry.InsertSessionRequest(req.SessionID)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.AlreadyExists, err.Error())
+	}
+	r, err := s.findStatementDiagnosticsRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	return &serverpb.CreateStatementDiagnosticsReportResponse{
+		Report: makeStatementDiagnosticsReport(r),
+	}, nil
+}
+
+// CancelStatementDiagnosticsReport cancels a pending statement diagnostics
+// request.
+func (s *adminServer) CancelStatementDiagnosticsReport(
+	ctx context.Context, req *serverpb.CancelStatementDiagnosticsReportRequest,
+) (*serverpb.CancelStatementDiagnosticsReportResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	canceled, err := s.server.execCfg.StmtDiagnosticsRegistry.CancelRequest(req.ID)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	if !canceled {
+		return nil, status.Errorf(codes.NotFound, "no pending request with ID %d", req.ID)
+	}
+	return &serverpb.CancelStatementDiagnosticsReportResponse{}, nil
+}
+
+// StatementDiagnosticsBundle returns the zip archive collected for a
+// statement diagnostics request.
+func (s *adminServer) StatementDiagnosticsBundle(
+	ctx context.Context, req *serverpb.StatementDiagnosticsBundleRequest,
+) (*serverpb.StatementDiagnosticsBundleResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	r, err := s.findStatementDiagnosticsRequest(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Completed() {
+		return nil, status.Errorf(codes.NotFound,
+			"the bundle of request %d has not been collected yet", req.ID)
+	}
+	return &serverpb.StatementDiagnosticsBundleResponse{Bundle: r.Bundle}, nil
+}
+
+// StatementDiagnosticsTrace returns the spans recorded for a statement
+// diagnostics request. If wait_seconds is set, it waits up to that duration
+// for the statement to be executed, which allows the trace of a session
+// request to be captured with a single call once the statement is
+// reproduced.
+func (s *adminServer) StatementDiagnosticsTrace(
+	ctx context.Context, req *serverpb.StatementDiagnosticsTraceRequest,
+) (*serverpb.StatementDiagnosticsTraceResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	if req.WaitSeconds < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "wait_seconds must not be negative")
+	}
+	wait := time.Duration(req.WaitSeconds) * time.Second
+	if wait > maxStmtDiagnosticsTraceWait {
+		wait = maxStmtDiagnosticsTraceWait
+	}
+	var r stmtdiagnostics.Request
+	if wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		var err error
+		if r, err = s.server.execCfg.StmtDiagnosticsRegistry.WaitForRequest(waitCtx, req.ID); err != nil {
+			return nil, status.Errorf(codes.NotFound, err.Error())
+		}
+	} else {
+		var err error
+		if r, err = s.findStatementDiagnosticsRequest(req.ID); err != nil {
+			return nil, err
+		}
+		if !r.Completed() {
+			return nil, status.Errorf(codes.NotFound,
+				"the trace of request %d has not been collected yet", req.ID)
+		}
+	}
+	return &serverpb.StatementDiagnosticsTraceResponse{
+		ID:        r.ID,
+		Statement: r.Statement,
+		Spans:     r.Trace,
+	}, nil
+}
+
+// findStatementDiagnosticsRequest returns the statement diagnostics request
+// with the given ID, or a NotFound error.
+func (s *adminServer) findStatementDiagnosticsRequest(id int64) (stmtdiagnostics.Request, error) {
+	for _, r := range s.server.execCfg.StmtDiagnosticsRegistry.Requests() {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return stmtdiagnostics.Request{}, status.Errorf(codes.NotFound, "no request with ID %d", id)
+}
+
+// hasLocalSession returns whether the session with the given ID, as found in
+// crdb_internal.node_sessions, is connected to this node.
+func (s *statusServer) hasLocalSession(sessionID string) bool {
+	for _, session := range s.sessionRegistry.SerializeAll() {
+		if len(session.ID) == 16 && sql.BytesToClusterWideID(session.ID).String() == sessionID {
+			return true
+		}
+	}
+	return false
+}
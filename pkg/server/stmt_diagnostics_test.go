@@ -0,0 +1,181 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAdminAPIStatementDiagnostics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	grantAdmin(t, db)
+
+	create := func(req serverpb.CreateStatementDiagnosticsReportRequest, expectedErr string) int64 {
+		t.Helper()
+		var resp serverpb.CreateStatementDiagnosticsReportResponse
+		err := postAdminJSONProto(s, "stmtdiagnostics", &req, &resp)
+		if !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%+v: expected error %q, got: %v", req, expectedErr, err)
+		}
+		return resp.Report.ID
+	}
+	list := func() []serverpb.StatementDiagnosticsReport {
+		t.Helper()
+		var resp serverpb.StatementDiagnosticsReportsResponse
+		if err := getAdminJSONProto(s, "stmtdiagnostics", &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Reports
+	}
+	cancel := func(id int64, expectedErr string) {
+		t.Helper()
+		var resp serverpb.CancelStatementDiagnosticsReportResponse
+		err := postAdminJSONProto(s, fmt.Sprintf("stmtdiagnostics/%d/cancel", id),
+			&serverpb.CancelStatementDiagnosticsReportRequest{}, &resp)
+		if !testutils.IsError(err, expectedErr) {
+			t.Fatalf("cancel %d: expected error %q, got: %v", id, expectedErr, err)
+		}
+	}
+	bundle := func(id int64, expectedErr string) []byte {
+		t.Helper()
+		var resp serverpb.StatementDiagnosticsBundleResponse
+		err := getAdminJSONProto(s, fmt.Sprintf("stmtdiagnostics/%d/bundle", id), &resp)
+		if !testutils.IsError(err, expectedErr) {
+			t.Fatalf("bundle %d: expected error %q, got: %v", id, expectedErr, err)
+		}
+		return resp.Bundle
+	}
+	trace := func(path string, expectedErr string) serverpb.StatementDiagnosticsTraceResponse {
+		t.Helper()
+		var resp serverpb.StatementDiagnosticsTraceResponse
+		err := getAdminJSONProto(s, "stmtdiagnostics/"+path, &resp)
+		if !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%s: expected error %q, got: %v", path, expectedErr, err)
+		}
+		return resp
+	}
+
+	create(serverpb.CreateStatementDiagnosticsReportRequest{}, "400 Bad Request")
+	if id := create(serverpb.CreateStatementDiagnosticsReportRequest{
+		StatementFingerprint: "SELECT _ + _",
+	}, ""); id != 1 {
+		t.Fatalf("expected request 1, got %d", id)
+	}
+	create(serverpb.CreateStatementDiagnosticsReportRequest{
+		StatementFingerprint: "SELECT _ + _",
+	}, "409 Conflict")
+	if reports := list(); len(reports) != 1 || reports[0].StatementFingerprint != "SELECT _ + _" ||
+		reports[0].Completed {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	bundle(1, "404 Not Found")
+
+	if _, err := db.Exec("SELECT 1 + 2"); err != nil {
+		t.Fatal(err)
+	}
+	if reports := list(); len(reports) != 1 || !reports[0].Completed ||
+		reports[0].Statement != "SELECT 1 + 2" {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	b := bundle(1, "")
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, f := range z.File {
+		files = append(files, f.Name)
+	}
+	if e, a := "statement.txt plan.txt trace.txt trace.json env.txt", strings.Join(files, " "); e != a {
+		t.Fatalf("expected bundle files %q, got %q", e, a)
+	}
+
+	create(serverpb.CreateStatementDiagnosticsReportRequest{
+		StatementFingerprint: "SELECT _ - _",
+	}, "")
+	cancel(2, "")
+	cancel(2, "404 Not Found")
+	bundle(3, "404 Not Found")
+
+	// The trace of the first request is available.
+	if tr := trace("1/trace", ""); tr.Statement != "SELECT 1 + 2" || len(tr.Spans) == 0 ||
+		tr.Spans[0].Operation != "traced statement" {
+		t.Fatalf("unexpected trace: %+v", tr)
+	}
+	trace("1/trace?wait_seconds=-1", "400 Bad Request")
+
+	// Request the trace of the next statement of a session.
+	conn, err := db.Conn(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var sessionID string
+	if err := conn.QueryRowContext(context.TODO(),
+		"SELECT session_id FROM crdb_internal.node_sessions WHERE active_queries LIKE 'SELECT session_id%'",
+	).Scan(&sessionID); err != nil {
+		t.Fatal(err)
+	}
+	create(serverpb.CreateStatementDiagnosticsReportRequest{SessionID: "abc"}, "404 Not Found")
+	create(serverpb.CreateStatementDiagnosticsReportRequest{
+		SessionID: sessionID, StatementFingerprint: "SELECT _",
+	}, "400 Bad Request")
+	create(serverpb.CreateStatementDiagnosticsReportRequest{SessionID: sessionID}, "")
+	if reports := list(); len(reports) != 2 || reports[1].SessionID != sessionID ||
+		reports[1].Completed {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	trace("3/trace", "404 Not Found")
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := conn.ExecContext(context.TODO(), "SELECT 3")
+		errC <- err
+	}()
+	tr := trace("3/trace?wait_seconds=60", "")
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	if tr.ID != 3 || tr.Statement != "SELECT 3" || len(tr.Spans) == 0 {
+		t.Fatalf("unexpected trace: %+v", tr)
+	}
+
+	// Conditional requests are reported with their conditions, and aren't
+	// completed by faster executions.
+	create(serverpb.CreateStatementDiagnosticsReportRequest{
+		StatementFingerprint: "SELECT _ * _",
+		MinExecutionLatency:  time.Hour,
+		ExpiresAfter:         time.Hour,
+		SamplingProbability:  1,
+	}, "")
+	if _, err := db.Exec("SELECT 2 * 3"); err != nil {
+		t.Fatal(err)
+	}
+	reports := list()
+	if r := reports[len(reports)-1]; r.ID != 4 || r.Completed || r.MinExecutionLatency != time.Hour ||
+		r.ExpiresAt == nil || r.SamplingProbability != 1 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VModule returns the vmodule setting of the node.
+func (s *adminServer) VModule(
+	ctx context.Context, req *serverpb.VModuleRequest,
+) (*serverpb.VModuleResponse, error) {
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+	return makeVModuleResponse(), nil
+}
+
+// SetVModule sets the vmodule setting of the node. If a duration is given,
+// the setting preceding the change is restored after that duration, so that
+// verbose logging can be enabled briefly for the component under
+// investigation without a restart.
+func (s *adminServer) SetVModule(
+	ctx context.Context, req *serverpb.SetVModuleRequest,
+) (*serverpb.VModuleResponse, error) {
+	user, err := s.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Duration < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "duration must not be negative")
+	}
+	if req.Duration > 0 {
+		err = log.SetVModuleFor(req.VModule, req.Duration)
+	} else {
+		err = log.SetVModule(req.VModule)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	log.Ops.Infof(s.server.AnnotateCtx(ctx), "vmodule set to %q by %s for %s",
+		req.VModule, user, req.Duration)
+	return makeVModuleResponse(), nil
+}
+
+func makeVModuleResponse() *serverpb.VModuleResponse {
+	resp := &serverpb.VModuleResponse{VModule: log.GetVModule()}
+	if prev, at, ok := log.GetVModuleRestore(); ok {
+		resp.RestoreVModule = prev
+		resp.RestoreAt = &at
+	}
+	return resp
+}
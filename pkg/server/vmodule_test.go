@@ -0,0 +1,68 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestAdminAPIVModule(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	defer func() { _ = log.SetVModule("") }()
+	grantAdmin(t, db)
+
+	set := func(req serverpb.SetVModuleRequest, expectedErr string) serverpb.VModuleResponse {
+		t.Helper()
+		var resp serverpb.VModuleResponse
+		if err := postAdminJSONProto(s, "vmodule", &req, &resp); !testutils.IsError(err, expectedErr) {
+			t.Fatalf("%+v: expected error %q, got: %v", req, expectedErr, err)
+		}
+		return resp
+	}
+
+	set(serverpb.SetVModuleRequest{VModule: "replica"}, "400 Bad Request")
+	set(serverpb.SetVModuleRequest{VModule: "replica=2", Duration: -time.Second}, "400 Bad Request")
+
+	if r := set(serverpb.SetVModuleRequest{VModule: "replica=2"}, ""); r.VModule != "replica=2" ||
+		r.RestoreAt != nil {
+		t.Fatalf("unexpected response %+v", r)
+	}
+	r := set(serverpb.SetVModuleRequest{VModule: "replica=3,queue=1", Duration: time.Hour}, "")
+	if r.VModule != "replica=3,queue=1" || r.RestoreVModule != "replica=2" || r.RestoreAt == nil {
+		t.Fatalf("unexpected response %+v", r)
+	}
+	if vmodule := log.GetVModule(); vmodule != "replica=3,queue=1" {
+		t.Fatalf("unexpected vmodule %q", vmodule)
+	}
+	var resp serverpb.VModuleResponse
+	if err := getAdminJSONProto(s, "vmodule", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.VModule != "replica=3,queue=1" || resp.RestoreVModule != "replica=2" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// An empty setting disables vmodule, and cancels the restoration.
+	if r := set(serverpb.SetVModuleRequest{}, ""); r.VModule != "" || r.RestoreAt != nil {
+		t.Fatalf("unexpected response %+v", r)
+	}
+}
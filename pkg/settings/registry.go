@@ -80,6 +80,17 @@ func Keys() (res []string) {
 	return res
 }
 
+// AllKeys returns a sorted string array with all the known keys, including
+// those of the hidden settings.
+func AllKeys() (res []string) {
+	res = make([]string, 0, len(Registry))
+	for k := range Registry {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
 // Lookup returns a Setting by name along with its description.
 func Lookup(name string) (Setting, bool) {
 	v, ok := Registry[name]
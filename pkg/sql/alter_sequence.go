@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -63,11 +64,11 @@ func (n *alterSequenceNode) startExec(params runParams) error {
 		EventLogAlterSequence,
 		int32(n.seqDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{params.p.ResolvedName(n.n.Name).FQString(), n.n.String(), params.SessionData().User},
+		&eventpb.AlterSequence{
+			SequenceName: params.p.ResolvedName(n.n.Name).FQString(),
+			Statement:    n.n.String(),
+			User:         params.SessionData().User,
+		},
 	)
 }
 
@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -693,14 +694,13 @@ func (n *alterTableNode) startExec(params runParams) error {
 		EventLogAlterTable,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{params.p.ResolvedName(n.n.Table).FQString(), n.n.String(),
-			params.SessionData().User, uint32(mutationID), droppedViews},
+		&eventpb.AlterTable{
+			TableName:           params.p.ResolvedName(n.n.Table).FQString(),
+			Statement:           n.n.String(),
+			User:                params.SessionData().User,
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	)
 }
 
@@ -120,7 +120,7 @@ func (a *appStats) recordStatement(
 	numRows int,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
-	bytesRead, rowsRead int64,
+	queryStats *topLevelQueryStats,
 ) {
 	if a == nil || !stmtStatsEnable.Get(&a.st.SV) {
 		return
@@ -155,8 +155,10 @@ func (a *appStats) recordStatement(
 	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
 	s.data.OverheadLat.Record(s.data.Count, ovhLat)
-	s.data.BytesRead = bytesRead
-	s.data.RowsRead = rowsRead
+	s.data.BytesRead = queryStats.bytesRead
+	s.data.RowsRead = queryStats.rowsRead
+	s.data.TempStorageBytesWritten = queryStats.tempStorageBytesWritten
+	s.data.TempStorageBytesRead = queryStats.tempStorageBytesRead
 	s.Unlock()
 }
 
@@ -209,6 +211,10 @@ type sqlStats struct {
 	lastReset time.Time
 	// apps is the container for all the per-application statistics objects.
 	apps map[string]*appStats
+
+	// insights are the statement executions detected as anomalous. They are
+	// not cleared when the statistics are reset.
+	insights executionInsights
 }
 
 func (s *sqlStats) getStatsForApplication(appName string) *appStats {
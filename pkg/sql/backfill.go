@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
 
@@ -608,6 +609,14 @@ func (sc *SchemaChanger) distBackfill(
 	}
 	chunkSize := sc.getChunkSize(backfillChunkSize)
 
+	opName := "index backfill"
+	if backfillType == columnBackfill {
+		opName = "column backfill"
+	}
+	ctx, op := tracing.StartOperation(ctx, sc.execCfg.AmbientCtx.Tracer,
+		fmt.Sprintf("%s of table %d, mutation %d", opName, sc.tableID, sc.mutationID))
+	defer op.Finish()
+
 	if err := sc.ExtendLease(ctx, lease); err != nil {
 		return err
 	}
@@ -702,8 +711,10 @@ func (sc *SchemaChanger) distBackfill(
 					origNRanges = nRanges
 				}
 
+				op.SetDetails(fmt.Sprintf("%d of %d ranges remaining", nRanges, origNRanges))
 				if nRanges < origNRanges {
 					fractionRangesFinished := float32(origNRanges-nRanges) / float32(origNRanges)
+					op.SetProgress(float64(fractionRangesFinished))
 					fractionCompleted := origFractionCompleted + fractionLeft*fractionRangesFinished
 					if err := sc.job.FractionProgressed(ctx, jobs.FractionUpdater(fractionCompleted)); err != nil {
 						return jobs.SimplifyInvalidStatusError(err)
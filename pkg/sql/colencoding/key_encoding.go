@@ -233,6 +233,60 @@ func decodeTableKeyToCol(
 	return rkey, err
 }
 
+// DecodeTableKeysToCol decodes n consecutive values encoded by
+// EncodeTableKey, writing the results to the positions idx to idx+n-1 of the
+// input exec.Vec. Runs of non-NULL integers and decimals are decoded in one
+// call, directly into the vector.
+func DecodeTableKeysToCol(
+	vec coldata.Vec,
+	idx uint16,
+	n int,
+	valType *types.T,
+	key []byte,
+	dir sqlbase.IndexDescriptor_Direction,
+) ([]byte, error) {
+	if (dir != sqlbase.IndexDescriptor_ASC) && (dir != sqlbase.IndexDescriptor_DESC) {
+		return nil, errors.AssertionFailedf("invalid direction: %d", log.Safe(dir))
+	}
+	for n > 0 {
+		var decoded int
+		var err error
+		switch valType.Family() {
+		case types.IntFamily, types.DateFamily, types.OidFamily:
+			if valType.Family() == types.IntFamily && valType.Width() != 0 && valType.Width() != 64 {
+				break
+			}
+			dst := vec.Int64()[idx : int(idx)+n]
+			if dir == sqlbase.IndexDescriptor_ASC {
+				key, decoded, err = encoding.DecodeVarintsAscending(key, dst)
+			} else {
+				key, decoded, err = encoding.DecodeVarintsDescending(key, dst)
+			}
+		case types.DecimalFamily:
+			dst := vec.Decimal()[idx : int(idx)+n]
+			if dir == sqlbase.IndexDescriptor_ASC {
+				key, decoded, err = encoding.DecodeDecimalsAscending(key, dst)
+			} else {
+				key, decoded, err = encoding.DecodeDecimalsDescending(key, dst)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if decoded == 0 {
+			// The next value is a NULL, or of a type which isn't decoded in
+			// batches.
+			if key, err = decodeTableKeyToCol(vec, idx, valType, key, dir); err != nil {
+				return nil, err
+			}
+			decoded = 1
+		}
+		idx += uint16(decoded)
+		n -= decoded
+	}
+	return key, nil
+}
+
 // skipTableKey skips a value of type valType in key, returning the remainder
 // of the key.
 // TODO(jordan): each type could be optimized here.
@@ -0,0 +1,87 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colencoding
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	semtypes "github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestDecodeTableKeysToCol(t *testing.T) {
+	rng, _ := randutil.NewPseudoRand()
+	const n = 100
+	for _, tc := range []struct {
+		semTyp *semtypes.T
+		typ    types.T
+	}{
+		{semtypes.Int, types.Int64},
+		{semtypes.Decimal, types.Decimal},
+		{semtypes.Float, types.Float64},
+	} {
+		for _, dir := range []sqlbase.IndexDescriptor_Direction{
+			sqlbase.IndexDescriptor_ASC, sqlbase.IndexDescriptor_DESC,
+		} {
+			encDir, err := dir.ToEncodingDirection()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Values and NULLs are mixed, so that runs of values are decoded
+			// in batches between the NULLs.
+			datums := make([]tree.Datum, n)
+			var key []byte
+			for i := range datums {
+				datums[i] = sqlbase.RandDatum(rng, tc.semTyp, true /* nullOk */)
+				key, err = sqlbase.EncodeTableKey(key, datums[i], encDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			vec := coldata.NewMemColumn(tc.typ, n+1)
+			rem, err := DecodeTableKeysToCol(vec, 1 /* idx */, n, tc.semTyp, key, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rem) != 0 {
+				t.Fatalf("leftover bytes %x", rem)
+			}
+			for i, d := range datums {
+				idx := uint16(i + 1)
+				if vec.Nulls().NullAt(idx) != (d == tree.DNull) {
+					t.Fatalf("%s %s: expected NULL at %d to be %t", tc.semTyp, dir, i, d == tree.DNull)
+				}
+				if d == tree.DNull {
+					continue
+				}
+				var ok bool
+				switch tc.typ {
+				case types.Int64:
+					ok = vec.Int64()[idx] == int64(*d.(*tree.DInt))
+				case types.Decimal:
+					dec := vec.Decimal()[idx]
+					ok = dec.Cmp(&d.(*tree.DDecimal).Decimal) == 0
+				case types.Float64:
+					f := float64(*d.(*tree.DFloat))
+					ok = vec.Float64()[idx] == f || (f != f && vec.Float64()[idx] != vec.Float64()[idx])
+				}
+				if !ok {
+					t.Fatalf("%s %s: unexpected value at %d for %s", tc.semTyp, dir, i, d)
+				}
+			}
+		}
+	}
+}
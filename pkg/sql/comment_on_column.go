@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
@@ -75,24 +76,22 @@ func (n *commentOnColumnNode) startExec(params runParams) error {
 		}
 	}
 
+	info := &eventpb.CommentOnColumn{
+		TableName:  n.tableDesc.Name,
+		ColumnName: string(n.n.ColumnItem.ColumnName),
+		Statement:  n.n.String(),
+		User:       params.SessionData().User,
+	}
+	if n.n.Comment != nil {
+		info.Comment = *n.n.Comment
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnColumn,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName  string
-			ColumnName string
-			Statement  string
-			User       string
-			Comment    *string
-		}{
-			n.tableDesc.Name,
-			string(n.n.ColumnItem.ColumnName),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
+		info,
 	)
 }
 
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -68,22 +69,21 @@ func (n *commentOnDatabaseNode) startExec(params runParams) error {
 		}
 	}
 
+	info := &eventpb.CommentOnDatabase{
+		DatabaseName: n.n.Name.String(),
+		Statement:    n.n.String(),
+		User:         params.SessionData().User,
+	}
+	if n.n.Comment != nil {
+		info.Comment = *n.n.Comment
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnDatabase,
 		int32(n.dbDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			DatabaseName string
-			Statement    string
-			User         string
-			Comment      *string
-		}{
-			n.n.Name.String(),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
+		info,
 	)
 }
 
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
@@ -66,23 +67,21 @@ func (n *commentOnTableNode) startExec(params runParams) error {
 		}
 	}
 
+	info := &eventpb.CommentOnTable{
+		TableName: params.p.ResolvedName(n.n.Table).FQString(),
+		Statement: n.n.String(),
+		User:      params.SessionData().User,
+	}
+	if n.n.Comment != nil {
+		info.Comment = *n.n.Comment
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnTable,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName string
-			Statement string
-			User      string
-			Comment   *string
-		}{
-			params.p.ResolvedName(n.n.Table).FQString(),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment,
-		},
+		info,
 	)
 }
 
@@ -238,6 +238,10 @@ type Server struct {
 	// node.
 	sqlStats sqlStats
 
+	// txnRetryStats accumulates the transaction retry statistics of this node
+	// until they are flushed to system.transaction_retry_statistics.
+	txnRetryStats *txnRetryStatsContainer
+
 	reCache *tree.RegexpCache
 
 	// pool is the parent monitor for all session monitors except "internal" ones.
@@ -280,10 +284,11 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		Metrics:         makeMetrics(false /*internal*/),
 		InternalMetrics: makeMetrics(true /*internal*/),
 		// dbCache will be updated on Start().
-		dbCache:  newDatabaseCacheHolder(newDatabaseCache(systemCfg)),
-		pool:     pool,
-		sqlStats: sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
-		reCache:  tree.NewRegexpCache(512),
+		dbCache:       newDatabaseCacheHolder(newDatabaseCache(systemCfg)),
+		pool:          pool,
+		sqlStats:      sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
+		txnRetryStats: newTxnRetryStatsContainer(cfg.Settings),
+		reCache:       tree.NewRegexpCache(512),
 	}
 }
 
@@ -329,6 +334,7 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearStmtStats(ctx, stopper)
+	s.PeriodicallyFlushTxnRetryStats(ctx, stopper)
 }
 
 // ResetStatementStats resets the executor's collected statement statistics.
@@ -1593,19 +1599,23 @@ func (ex *connExecutor) execCopyIn(
 		ex.state.mon.Start(ctx, ex.sessionMon, mon.BoundAccount{} /* reserved */)
 		monToStop = ex.state.mon
 	}
-	cm, err := newCopyMachine(
-		ctx, cmd.Conn, cmd.Stmt, txnOpt, ex.server.cfg,
-		// resetPlanner
-		func(p *planner, txn *client.Txn, txnTS time.Time, stmtTS time.Time) {
-			// HACK: We're reaching inside ex.state and changing sqlTimestamp by hand.
-			// It is used by resetPlanner. Normally sqlTimestamp is updated by the
-			// state machine, but the copyMachine manages its own transactions without
-			// going through the state machine.
-			ex.state.sqlTimestamp = txnTS
-			ex.initPlanner(ctx, p)
-			ex.resetPlanner(ctx, p, txn, stmtTS, 0 /* numAnnotations */)
-		},
-	)
+	// resetPlanner is used by the copy machine to prepare a planner.
+	resetPlanner := func(p *planner, txn *client.Txn, txnTS time.Time, stmtTS time.Time) {
+		// HACK: We're reaching inside ex.state and changing sqlTimestamp by hand.
+		// It is used by resetPlanner. Normally sqlTimestamp is updated by the
+		// state machine, but the copyMachine manages its own transactions without
+		// going through the state machine.
+		ex.state.sqlTimestamp = txnTS
+		ex.initPlanner(ctx, p)
+		ex.resetPlanner(ctx, p, txn, stmtTS, 0 /* numAnnotations */)
+	}
+	var cm copyMachineInterface
+	var err error
+	if isFileUploadTable(&cmd.Stmt.Table) {
+		cm, err = newFileUploadMachine(ctx, cmd.Conn, cmd.Stmt, txnOpt, ex.server.cfg, resetPlanner)
+	} else {
+		cm, err = newCopyMachine(ctx, cmd.Conn, cmd.Stmt, txnOpt, ex.server.cfg, resetPlanner)
+	}
 	if err != nil {
 		ev := eventNonRetriableErr{IsCommit: fsm.False}
 		payload := eventNonRetriableErrPayload{err: err}
@@ -1795,19 +1805,20 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 
 	*evalCtx = extendedEvalContext{
 		EvalContext: tree.EvalContext{
-			Planner:          p,
-			Sequence:         p,
-			SessionData:      ex.sessionData,
-			SessionAccessor:  p,
-			Settings:         ex.server.cfg.Settings,
-			TestingKnobs:     ex.server.cfg.EvalContextTestingKnobs,
-			ClusterID:        ex.server.cfg.ClusterID(),
-			ClusterName:      ex.server.cfg.RPCContext.ClusterName(),
-			NodeID:           ex.server.cfg.NodeID.Get(),
-			Locality:         ex.server.cfg.Locality,
-			ReCache:          ex.server.reCache,
-			InternalExecutor: ie,
-			DB:               ex.server.cfg.DB,
+			Planner:                  p,
+			Sequence:                 p,
+			SessionData:              ex.sessionData,
+			SessionAccessor:          p,
+			StmtDiagnosticsRequester: ex.server.cfg.StmtDiagnosticsRegistry,
+			Settings:                 ex.server.cfg.Settings,
+			TestingKnobs:             ex.server.cfg.EvalContextTestingKnobs,
+			ClusterID:                ex.server.cfg.ClusterID(),
+			ClusterName:              ex.server.cfg.RPCContext.ClusterName(),
+			NodeID:                   ex.server.cfg.NodeID.Get(),
+			Locality:                 ex.server.cfg.Locality,
+			ReCache:                  ex.server.reCache,
+			InternalExecutor:         ie,
+			DB:                       ex.server.cfg.DB,
 		},
 		SessionMutator:  ex.dataMutator,
 		VirtualSchemas:  ex.server.cfg.VirtualSchemas,
@@ -1933,6 +1944,9 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 	if advInfo.code == rewind {
 		ex.extraTxnState.autoRetryCounter++
 	}
+	if p, ok := payload.(eventRetriableErrPayload); ok {
+		ex.recordTxnRetry(p.err, advInfo.code == rewind)
+	}
 
 	// Handle transaction events which cause updates to txnState.
 	switch advInfo.txnEvent {
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
@@ -34,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 // RestartSavepointName is the only savepoint ident that we accept.
@@ -413,7 +415,28 @@ func (ex *connExecutor) execStmtInOpenState(
 	p.cancelChecker = sqlbase.NewCancelChecker(ctx)
 
 	p.autoCommit = os.ImplicitTxn.Get() && !ex.server.cfg.TestingKnobs.DisableAutoCommit
-	if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+
+	// If diagnostics were requested for this statement, trace its execution.
+	// The span is only used for the execution of the statement; the
+	// auto-commit above happens in the context of the transaction.
+	stmtCtx := ctx
+	p.collectStmtDiagnostics, p.stmtDiagnosticsPlan = false, ""
+	if reqID, ok := ex.server.cfg.StmtDiagnosticsRegistry.ShouldCollectDiagnostics(
+		stmt.AST, &ex.sessionID,
+	); ok {
+		var sp opentracing.Span
+		stmtCtx, sp, _ = tracing.StartSnowballTrace(ctx, ex.server.cfg.AmbientCtx.Tracer, "traced statement")
+		p.collectStmtDiagnostics = true
+		start := timeutil.Now()
+		defer func() {
+			latency := timeutil.Since(start)
+			trace := tracing.GetRecording(sp)
+			sp.Finish()
+			ex.finishStmtDiagnostics(ctx, reqID, &stmt, p.stmtDiagnosticsPlan, trace, latency)
+		}()
+	}
+
+	if err := ex.dispatchToExecutionEngine(stmtCtx, p, res); err != nil {
 		return nil, nil, err
 	}
 	if err := res.Err(); err != nil {
@@ -609,10 +632,15 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	stmt := planner.stmt
 	ex.sessionTracing.TracePlanStart(ctx, stmt.AST.StatementTag())
 	planner.statsCollector.PhaseTimes()[plannerStartLogicalPlan] = timeutil.Now()
+	ex.recordTxnStatement(planner)
 
 	// Prepare the plan. Note, the error is processed below. Everything
 	// between here and there needs to happen even if there's an error.
 	err := ex.makeExecPlan(ctx, planner)
+	if err == nil && planner.collectStmtDiagnostics {
+		planner.stmtDiagnosticsPlan = planToString(
+			ctx, planner.curPlan.plan, planner.curPlan.subqueryPlans, planner.curPlan.postqueryPlans)
+	}
 	// We'll be closing the plan manually below after execution; this
 	// defer is a catch-all in case some other return path is taken.
 	defer planner.curPlan.close(ctx)
@@ -634,8 +662,13 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		}
 	}
 
+	// queryStats is populated once the statement has been executed, so that it
+	// can be included in the slow query log.
+	var queryStats topLevelQueryStats
 	defer func() {
-		planner.maybeLogStatement(ctx, "exec", ex.extraTxnState.autoRetryCounter, res.RowsAffected(), res.Err())
+		planner.maybeLogStatement(
+			ctx, "exec", ex.extraTxnState.autoRetryCounter, res.RowsAffected(), res.Err(), &queryStats,
+		)
 	}()
 
 	planner.statsCollector.PhaseTimes()[plannerEndLogicalPlan] = timeutil.Now()
@@ -647,6 +680,16 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		return nil
 	}
 
+	// The plan is walked before execution, since DistSQL physical planning
+	// modifies it.
+	insights := insightsEnabled.Get(&ex.server.cfg.Settings.SV)
+	if insights || slowQueryLogThreshold.Get(&ex.server.cfg.Settings.SV) != 0 {
+		planner.curPlan.gist = planGist(ctx, &planner.curPlan)
+	}
+	if insights {
+		planner.curPlan.fullScans = planFullScans(ctx, &planner.curPlan)
+	}
+
 	var cols sqlbase.ResultColumns
 	if stmt.AST.StatementType() == tree.Rows {
 		cols = planColumns(planner.curPlan.plan)
@@ -697,7 +740,7 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		planner.curPlan.flags.Set(planFlagDistSQLLocal)
 	}
 	ex.sessionTracing.TraceExecStart(ctx, "distributed")
-	bytesRead, rowsRead, err := ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), res, distributePlan)
+	queryStats, err = ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), res, distributePlan)
 	ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 	planner.statsCollector.PhaseTimes()[plannerEndExecStmt] = timeutil.Now()
 
@@ -705,7 +748,7 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	// plan has not been closed earlier.
 	ex.recordStatementSummary(
 		ctx, planner,
-		ex.extraTxnState.autoRetryCounter, res.RowsAffected(), res.Err(), bytesRead, rowsRead,
+		ex.extraTxnState.autoRetryCounter, res.RowsAffected(), res.Err(), &queryStats,
 	)
 	if ex.server.cfg.TestingKnobs.AfterExecute != nil {
 		ex.server.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res.Err())
@@ -734,6 +777,33 @@ func (ex *connExecutor) makeExecPlan(ctx context.Context, planner *planner) erro
 	return nil
 }
 
+// finishStmtDiagnostics builds the diagnostics bundle of a statement for
+// which diagnostics were collected and records it in the registry. If the
+// execution was faster than required by the request, the request is released
+// instead, to wait for a slower execution.
+func (ex *connExecutor) finishStmtDiagnostics(
+	ctx context.Context,
+	reqID int64,
+	stmt *Statement,
+	plan string,
+	trace []tracing.RecordedSpan,
+	latency time.Duration,
+) {
+	registry := ex.server.cfg.StmtDiagnosticsRegistry
+	if !registry.ShouldRetainBundle(reqID, latency) {
+		registry.ReleaseRequest(reqID)
+		return
+	}
+	bundle, err := stmtdiagnostics.BuildBundle(
+		stmt.String(), plan, trace, &ex.server.cfg.Settings.SV)
+	if err != nil {
+		log.Warningf(ctx, "failed to build statement diagnostics bundle: %v", err)
+		registry.ReleaseRequest(reqID)
+		return
+	}
+	registry.CompleteRequest(reqID, stmt.String(), bundle, trace)
+}
+
 // saveLogicalPlanDescription returns whether we should save this as a sample logical plan
 // for its corresponding fingerprint. We use `logicalPlanCollectionPeriod`
 // to assess how frequently to sample logical plans.
@@ -764,7 +834,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	stmtType tree.StatementType,
 	res RestrictedCommandResult,
 	distribute bool,
-) (bytesRead, rowsRead int64, _ error) {
+) (topLevelQueryStats, error) {
 	recv := MakeDistSQLReceiver(
 		ctx, res, stmtType,
 		ex.server.cfg.RangeDescriptorCache, ex.server.cfg.LeaseHolderCache,
@@ -803,7 +873,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 		if !ex.server.cfg.DistSQLPlanner.PlanAndRunSubqueries(
 			ctx, planner, evalCtxFactory, planner.curPlan.subqueryPlans, recv, distribute,
 		) {
-			return recv.bytesRead, recv.rowsRead, recv.commErr
+			return recv.stats, recv.commErr
 		}
 	}
 	recv.discardRows = planner.discardRows
@@ -816,7 +886,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	// need to have access to the main query tree.
 	defer cleanup()
 	if recv.commErr != nil {
-		return recv.bytesRead, recv.rowsRead, recv.commErr
+		return recv.stats, recv.commErr
 	}
 
 	if len(planner.curPlan.postqueryPlans) != 0 {
@@ -825,7 +895,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 		)
 	}
 
-	return recv.bytesRead, recv.rowsRead, recv.commErr
+	return recv.stats, recv.commErr
 }
 
 // beginTransactionTimestampsAndReadMode computes the timestamps and
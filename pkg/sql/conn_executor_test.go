@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
@@ -390,3 +391,29 @@ func TestAppNameStatisticsInitialization(t *testing.T) {
 		t.Fatalf("query was not counted properly: %+v", counts)
 	}
 }
+
+func TestTempStorageStatistics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	// Force the processors to spill to disk.
+	params.Knobs.DistSQL = &distsqlrun.TestingKnobs{MemoryLimitBytes: 1}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE t; CREATE TABLE t.a (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t.a SELECT i, -i FROM generate_series(1, 100) AS g(i)`)
+	sqlDB.Exec(t, `SELECT * FROM t.a ORDER BY v`)
+
+	var written, read int64
+	sqlDB.QueryRow(t, `
+SELECT temp_storage_bytes_written, temp_storage_bytes_read
+  FROM crdb_internal.node_statement_statistics
+ WHERE key = 'SELECT * FROM t.a ORDER BY v'`,
+	).Scan(&written, &read)
+	if written == 0 || read == 0 {
+		t.Fatalf("expected the sort to report temp storage usage, got %d bytes written and %d bytes read",
+			written, read)
+	}
+}
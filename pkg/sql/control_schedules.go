@@ -0,0 +1,150 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+type controlSchedulesNode struct {
+	rows    planNode
+	command tree.ScheduleCommand
+	numRows int
+}
+
+// ControlSchedules pauses, resumes or drops the schedules of
+// system.scheduled_jobs.
+// Privileges: owner of the schedules or admin.
+func (p *planner) ControlSchedules(
+	ctx context.Context, n *tree.ControlSchedules,
+) (planNode, error) {
+	rows, err := p.newPlan(ctx, n.Schedules, []*types.T{types.Int})
+	if err != nil {
+		return nil, err
+	}
+	cols := planColumns(rows)
+	if len(cols) != 1 {
+		return nil, pgerror.Newf(pgcode.Syntax,
+			"%s SCHEDULES expects a single column source, got %d columns",
+			tree.ScheduleCommandToStatement[n.Command], len(cols))
+	}
+	if cols[0].Typ.Family() != types.IntFamily {
+		return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+			"%s SCHEDULES requires int values, not type %s",
+			tree.ScheduleCommandToStatement[n.Command], cols[0].Typ)
+	}
+
+	return &controlSchedulesNode{
+		rows:    rows,
+		command: n.Command,
+	}, nil
+}
+
+// FastPathResults implements the planNodeFastPath inteface.
+func (n *controlSchedulesNode) FastPathResults() (int, bool) {
+	return n.numRows, true
+}
+
+func (n *controlSchedulesNode) startExec(params runParams) error {
+	isAdmin, err := params.p.HasAdminRole(params.ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		ok, err := n.rows.Next(params)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		scheduleIDDatum := n.rows.Values()[0]
+		if scheduleIDDatum == tree.DNull {
+			continue
+		}
+
+		scheduleID, ok := tree.AsDInt(scheduleIDDatum)
+		if !ok {
+			return errors.AssertionFailedf("%q: expected *DInt, found %T", scheduleIDDatum, scheduleIDDatum)
+		}
+		if err := n.controlSchedule(params, int64(scheduleID), isAdmin); err != nil {
+			return err
+		}
+		n.numRows++
+	}
+	return nil
+}
+
+// controlSchedule applies the command of the node to a single schedule.
+func (n *controlSchedulesNode) controlSchedule(
+	params runParams, scheduleID int64, isAdmin bool,
+) error {
+	ie := params.p.ExecCfg().InternalExecutor
+	row, err := ie.QueryRow(params.ctx, "load-schedule", params.p.txn,
+		`SELECT owner, schedule_expr, next_run FROM system.scheduled_jobs WHERE schedule_id = $1`,
+		scheduleID,
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return pgerror.Newf(pgcode.UndefinedObject, "schedule with ID %d does not exist", scheduleID)
+	}
+	if owner := string(tree.MustBeDString(row[0])); owner != params.p.User() && !isAdmin {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"only the owner of schedule %d or an admin can %s it",
+			scheduleID, tree.ScheduleCommandToStatement[n.command])
+	}
+
+	switch n.command {
+	case tree.PauseSchedule:
+		_, err = ie.Exec(params.ctx, "pause-schedule", params.p.txn,
+			`UPDATE system.scheduled_jobs SET next_run = NULL WHERE schedule_id = $1`, scheduleID,
+		)
+	case tree.ResumeSchedule:
+		if row[2] != tree.DNull {
+			// The schedule isn't paused.
+			return nil
+		}
+		next, err := jobs.NextRunTime(string(tree.MustBeDString(row[1])), timeutil.Now())
+		if err != nil {
+			return err
+		}
+		_, err = ie.Exec(params.ctx, "resume-schedule", params.p.txn,
+			`UPDATE system.scheduled_jobs SET next_run = $1 WHERE schedule_id = $2`, next, scheduleID,
+		)
+		return err
+	case tree.DropSchedule:
+		_, err = ie.Exec(params.ctx, "drop-schedule", params.p.txn,
+			`DELETE FROM system.scheduled_jobs WHERE schedule_id = $1`, scheduleID,
+		)
+	default:
+		err = errors.AssertionFailedf("unhandled command %v", n.command)
+	}
+	return err
+}
+
+func (*controlSchedulesNode) Next(runParams) (bool, error) { return false, nil }
+
+func (*controlSchedulesNode) Values() tree.Datums { return nil }
+
+func (n *controlSchedulesNode) Close(ctx context.Context) {
+	n.rows.Close(ctx)
+}
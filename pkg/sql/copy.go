@@ -31,6 +31,12 @@ import (
 	"github.com/cockroachdb/errors"
 )
 
+// copyMachineInterface is the interface of the machines that handle the
+// Copy-in pgwire subprotocol.
+type copyMachineInterface interface {
+	run(ctx context.Context) error
+}
+
 // copyMachine supports the Copy-in pgwire subprotocol (COPY...FROM STDIN). The
 // machine is created by the Executor when that statement is executed; from that
 // moment on, the machine takes control of the pgwire connection until
@@ -56,6 +62,9 @@ type copyMachine struct {
 	// insertedRows keeps track of the total number of rows inserted by the
 	// machine.
 	insertedRows int
+	// processRows is called to consume the accumulated rows. It inserts them
+	// in the destination table, unless the machine is used to upload a file.
+	processRows func(ctx context.Context) error
 	// rowsMemAcc accounts for memory used by `rows`.
 	rowsMemAcc mon.BoundAccount
 	// bufMemAcc accounts for memory used by `buf`; it is kept in sync with
@@ -99,6 +108,7 @@ func newCopyMachine(
 		p:            planner{execCfg: execCfg},
 		resetPlanner: resetPlanner,
 	}
+	c.processRows = c.insertRows
 	c.resetPlanner(&c.p, nil /* txn */, time.Time{} /* txnTS */, time.Time{} /* stmtTS */)
 	c.parsingEvalCtx = c.p.EvalContext()
 
@@ -259,7 +269,7 @@ func (c *copyMachine) processCopyData(
 	if ln := len(c.rows); ln == 0 || (ln < copyBatchRowSize && !final) {
 		return nil
 	}
-	return c.insertRows(ctx)
+	return c.processRows(ctx)
 }
 
 // preparePlanner resets the planner so that it can be used for execution.
@@ -0,0 +1,198 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// NodelocalFileUploadTable is the name of the virtual table that is the
+	// target of the COPY statements used to upload files to the external IO
+	// directory of a node. It lives in the crdb_internal schema.
+	NodelocalFileUploadTable = "file_upload"
+
+	copyOptionDestination = "destination"
+)
+
+// CopyInFileStmt returns the COPY statement that uploads a file to the given
+// destination, relative to the external IO directory of the node that
+// executes it. The contents of the file are sent as BYTES values of a single
+// column, one chunk per row.
+func CopyInFileStmt(destination string) string {
+	return "COPY " + crdbInternalName + "." + NodelocalFileUploadTable +
+		" FROM STDIN WITH " + copyOptionDestination + " = " + lex.EscapeSQLString(destination)
+}
+
+// isFileUploadTable returns whether a COPY statement targets the virtual file
+// upload table.
+func isFileUploadTable(tn *tree.TableName) bool {
+	return tn.ExplicitSchema && tn.Schema() == crdbInternalName &&
+		tn.Table() == NodelocalFileUploadTable
+}
+
+// fileUploadMachine supports uploading a file to the external IO directory of
+// the node with the Copy-in pgwire subprotocol. It reuses the copyMachine to
+// handle the protocol, but writes the chunks of the file to a temporary file
+// instead of inserting rows. The temporary file is renamed to its destination
+// once the copy completes successfully, so that a partially uploaded file is
+// never visible to IMPORT. Uploading files requires the admin role.
+type fileUploadMachine struct {
+	c *copyMachine
+	// path is the destination of the file.
+	path string
+	// f is the temporary file in which the chunks are written.
+	f *os.File
+}
+
+var _ copyMachineInterface = &fileUploadMachine{}
+
+func newFileUploadMachine(
+	ctx context.Context,
+	conn pgwirebase.Conn,
+	n *tree.CopyFrom,
+	txnOpt copyTxnOpt,
+	execCfg *ExecutorConfig,
+	resetPlanner func(p *planner, txn *client.Txn, txnTS time.Time, stmtTS time.Time),
+) (_ *fileUploadMachine, retErr error) {
+	if len(n.Columns) != 0 {
+		return nil, pgerror.New(pgcode.Syntax, "file uploads do not accept a column list")
+	}
+	c := &copyMachine{
+		conn:   conn,
+		txnOpt: txnOpt,
+		// The planner will be prepared before use.
+		p:            planner{execCfg: execCfg},
+		resetPlanner: resetPlanner,
+		resultColumns: sqlbase.ResultColumns{
+			{Name: "data", Typ: types.Bytes},
+		},
+	}
+	f := &fileUploadMachine{c: c}
+	c.processRows = f.writeRows
+	c.resetPlanner(&c.p, nil /* txn */, time.Time{} /* txnTS */, time.Time{} /* stmtTS */)
+	c.parsingEvalCtx = c.p.EvalContext()
+
+	cleanup := c.preparePlanner(ctx)
+	defer func() {
+		retErr = cleanup(ctx, retErr)
+	}()
+
+	if err := c.p.RequireAdminRole(ctx, "upload files"); err != nil {
+		return nil, err
+	}
+
+	var destination string
+	for _, opt := range n.Options {
+		if opt.Key != copyOptionDestination {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unsupported file upload option %q", opt.Key)
+		}
+		destFn, err := c.p.TypeAsString(opt.Value, "COPY")
+		if err != nil {
+			return nil, err
+		}
+		if destination, err = destFn(); err != nil {
+			return nil, err
+		}
+	}
+	if destination == "" {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"the %s option is required", copyOptionDestination)
+	}
+	path, err := fileUploadPath(execCfg.Settings.ExternalIODir, destination)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, pgerror.Newf(pgcode.DuplicateFile, "destination file %s already exists", destination)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "creating destination directory")
+	}
+	f.path = path
+	if f.f, err = ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp"); err != nil {
+		return nil, errors.Wrap(err, "creating temporary file")
+	}
+
+	c.rowsMemAcc = c.p.extendedEvalCtx.Mon.MakeBoundAccount()
+	c.bufMemAcc = c.p.extendedEvalCtx.Mon.MakeBoundAccount()
+	return f, nil
+}
+
+// fileUploadPath returns the path of the destination of an upload in the
+// external IO directory. Like nodelocal storage, it does not allow escaping the
+// external IO directory.
+func fileUploadPath(externalIODir, destination string) (string, error) {
+	if externalIODir == "" {
+		return "", pgerror.New(pgcode.InsufficientPrivilege, "local file access is disabled")
+	}
+	path := filepath.Clean(filepath.Join(externalIODir, destination))
+	if !strings.HasPrefix(path, filepath.Clean(externalIODir)+string(filepath.Separator)) {
+		return "", pgerror.New(pgcode.InsufficientPrivilege,
+			"local file access to paths outside of external-io-dir is not allowed")
+	}
+	return path, nil
+}
+
+// run implements the copyMachineInterface.
+func (f *fileUploadMachine) run(ctx context.Context) (retErr error) {
+	tmpPath := f.f.Name()
+	defer func() {
+		if err := f.f.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+		if retErr == nil {
+			retErr = errors.Wrap(os.Rename(tmpPath, f.path), "renaming uploaded file")
+		}
+		if retErr != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	if err := f.c.run(ctx); err != nil {
+		return err
+	}
+	return f.f.Sync()
+}
+
+// writeRows writes the accumulated chunks of the file.
+func (f *fileUploadMachine) writeRows(ctx context.Context) error {
+	for _, row := range f.c.rows {
+		chunk, ok := row[0].(*tree.DBytes)
+		if !ok {
+			return pgerror.Newf(pgcode.InvalidParameterValue, "unexpected file chunk %s", row[0])
+		}
+		if _, err := f.f.Write([]byte(*chunk)); err != nil {
+			return errors.Wrap(err, "writing uploaded file")
+		}
+	}
+	f.c.insertedRows += len(f.c.rows)
+	f.c.rows = f.c.rows[:0]
+	f.c.rowsMemAcc.Clear(ctx)
+	return nil
+}
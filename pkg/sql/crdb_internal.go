@@ -31,17 +31,22 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/indexrec"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 )
 
@@ -61,40 +66,47 @@ const crdbInternalName = "crdb_internal"
 var crdbInternal = virtualSchema{
 	name: crdbInternalName,
 	tableDefs: map[sqlbase.ID]virtualSchemaDef{
-		sqlbase.CrdbInternalBackwardDependenciesTableID: crdbInternalBackwardDependenciesTable,
-		sqlbase.CrdbInternalBuildInfoTableID:            crdbInternalBuildInfoTable,
-		sqlbase.CrdbInternalBuiltinFunctionsTableID:     crdbInternalBuiltinFunctionsTable,
-		sqlbase.CrdbInternalClusterQueriesTableID:       crdbInternalClusterQueriesTable,
-		sqlbase.CrdbInternalClusterSessionsTableID:      crdbInternalClusterSessionsTable,
-		sqlbase.CrdbInternalClusterSettingsTableID:      crdbInternalClusterSettingsTable,
-		sqlbase.CrdbInternalCreateStmtsTableID:          crdbInternalCreateStmtsTable,
-		sqlbase.CrdbInternalFeatureUsageID:              crdbInternalFeatureUsage,
-		sqlbase.CrdbInternalForwardDependenciesTableID:  crdbInternalForwardDependenciesTable,
-		sqlbase.CrdbInternalGossipNodesTableID:          crdbInternalGossipNodesTable,
-		sqlbase.CrdbInternalGossipAlertsTableID:         crdbInternalGossipAlertsTable,
-		sqlbase.CrdbInternalGossipLivenessTableID:       crdbInternalGossipLivenessTable,
-		sqlbase.CrdbInternalGossipNetworkTableID:        crdbInternalGossipNetworkTable,
-		sqlbase.CrdbInternalIndexColumnsTableID:         crdbInternalIndexColumnsTable,
-		sqlbase.CrdbInternalJobsTableID:                 crdbInternalJobsTable,
-		sqlbase.CrdbInternalKVNodeStatusTableID:         crdbInternalKVNodeStatusTable,
-		sqlbase.CrdbInternalKVStoreStatusTableID:        crdbInternalKVStoreStatusTable,
-		sqlbase.CrdbInternalLeasesTableID:               crdbInternalLeasesTable,
-		sqlbase.CrdbInternalLocalQueriesTableID:         crdbInternalLocalQueriesTable,
-		sqlbase.CrdbInternalLocalSessionsTableID:        crdbInternalLocalSessionsTable,
-		sqlbase.CrdbInternalLocalMetricsTableID:         crdbInternalLocalMetricsTable,
-		sqlbase.CrdbInternalPartitionsTableID:           crdbInternalPartitionsTable,
-		sqlbase.CrdbInternalPredefinedCommentsTableID:   crdbInternalPredefinedCommentsTable,
-		sqlbase.CrdbInternalRangesNoLeasesTableID:       crdbInternalRangesNoLeasesTable,
-		sqlbase.CrdbInternalRangesViewID:                crdbInternalRangesView,
-		sqlbase.CrdbInternalRuntimeInfoTableID:          crdbInternalRuntimeInfoTable,
-		sqlbase.CrdbInternalSchemaChangesTableID:        crdbInternalSchemaChangesTable,
-		sqlbase.CrdbInternalSessionTraceTableID:         crdbInternalSessionTraceTable,
-		sqlbase.CrdbInternalSessionVariablesTableID:     crdbInternalSessionVariablesTable,
-		sqlbase.CrdbInternalStmtStatsTableID:            crdbInternalStmtStatsTable,
-		sqlbase.CrdbInternalTableColumnsTableID:         crdbInternalTableColumnsTable,
-		sqlbase.CrdbInternalTableIndexesTableID:         crdbInternalTableIndexesTable,
-		sqlbase.CrdbInternalTablesTableID:               crdbInternalTablesTable,
-		sqlbase.CrdbInternalZonesTableID:                crdbInternalZonesTable,
+		sqlbase.CrdbInternalBackwardDependenciesTableID:      crdbInternalBackwardDependenciesTable,
+		sqlbase.CrdbInternalBuildInfoTableID:                 crdbInternalBuildInfoTable,
+		sqlbase.CrdbInternalBuiltinFunctionsTableID:          crdbInternalBuiltinFunctionsTable,
+		sqlbase.CrdbInternalClusterQueriesTableID:            crdbInternalClusterQueriesTable,
+		sqlbase.CrdbInternalClusterSessionsTableID:           crdbInternalClusterSessionsTable,
+		sqlbase.CrdbInternalClusterSettingsTableID:           crdbInternalClusterSettingsTable,
+		sqlbase.CrdbInternalCreateStmtsTableID:               crdbInternalCreateStmtsTable,
+		sqlbase.CrdbInternalEventLogTableID:                  crdbInternalEventLogTable,
+		sqlbase.CrdbInternalFeatureUsageID:                   crdbInternalFeatureUsage,
+		sqlbase.CrdbInternalForwardDependenciesTableID:       crdbInternalForwardDependenciesTable,
+		sqlbase.CrdbInternalGossipNodesTableID:               crdbInternalGossipNodesTable,
+		sqlbase.CrdbInternalGossipAlertsTableID:              crdbInternalGossipAlertsTable,
+		sqlbase.CrdbInternalGossipLivenessTableID:            crdbInternalGossipLivenessTable,
+		sqlbase.CrdbInternalGossipNetworkTableID:             crdbInternalGossipNetworkTable,
+		sqlbase.CrdbInternalIndexColumnsTableID:              crdbInternalIndexColumnsTable,
+		sqlbase.CrdbInternalJobsTableID:                      crdbInternalJobsTable,
+		sqlbase.CrdbInternalKVNodeStatusTableID:              crdbInternalKVNodeStatusTable,
+		sqlbase.CrdbInternalKVStoreStatusTableID:             crdbInternalKVStoreStatusTable,
+		sqlbase.CrdbInternalLeasesTableID:                    crdbInternalLeasesTable,
+		sqlbase.CrdbInternalLocalQueriesTableID:              crdbInternalLocalQueriesTable,
+		sqlbase.CrdbInternalLocalSessionsTableID:             crdbInternalLocalSessionsTable,
+		sqlbase.CrdbInternalLocalMetricsTableID:              crdbInternalLocalMetricsTable,
+		sqlbase.CrdbInternalLocalStmtDiagnosticsTableID:      crdbInternalLocalStmtDiagnosticsTable,
+		sqlbase.CrdbInternalLocalExecutionInsightsTableID:    crdbInternalLocalExecutionInsightsTable,
+		sqlbase.CrdbInternalLocalIndexRecommendationsTableID: crdbInternalLocalIndexRecommendationsTable,
+		sqlbase.CrdbInternalLocalMemoryMonitorsTableID:       crdbInternalLocalMemoryMonitorsTable,
+		sqlbase.CrdbInternalLocalOperationsTableID:           crdbInternalLocalOperationsTable,
+		sqlbase.CrdbInternalLocalTxnContentionTableID:        crdbInternalLocalTxnContentionTable,
+		sqlbase.CrdbInternalPartitionsTableID:                crdbInternalPartitionsTable,
+		sqlbase.CrdbInternalPredefinedCommentsTableID:        crdbInternalPredefinedCommentsTable,
+		sqlbase.CrdbInternalRangesNoLeasesTableID:            crdbInternalRangesNoLeasesTable,
+		sqlbase.CrdbInternalRangesViewID:                     crdbInternalRangesView,
+		sqlbase.CrdbInternalRuntimeInfoTableID:               crdbInternalRuntimeInfoTable,
+		sqlbase.CrdbInternalSchemaChangesTableID:             crdbInternalSchemaChangesTable,
+		sqlbase.CrdbInternalSessionTraceTableID:              crdbInternalSessionTraceTable,
+		sqlbase.CrdbInternalSessionVariablesTableID:          crdbInternalSessionVariablesTable,
+		sqlbase.CrdbInternalStmtStatsTableID:                 crdbInternalStmtStatsTable,
+		sqlbase.CrdbInternalTableColumnsTableID:              crdbInternalTableColumnsTable,
+		sqlbase.CrdbInternalTableIndexesTableID:              crdbInternalTableIndexesTable,
+		sqlbase.CrdbInternalTablesTableID:                    crdbInternalTablesTable,
+		sqlbase.CrdbInternalZonesTableID:                     crdbInternalZonesTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -421,6 +433,90 @@ CREATE TABLE crdb_internal.leases (
 	},
 }
 
+// crdbInternalEventLogTable exposes the events of system.eventlog along with
+// the user and statement recorded in their payloads, so that events can be
+// filtered on them.
+var crdbInternalEventLogTable = virtualSchemaTable{
+	comment: `events from system.eventlog with decoded payloads (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.eventlog (
+	timestamp    TIMESTAMP NOT NULL,
+	event_type   STRING NOT NULL,
+	target_id    INT NOT NULL,
+	reporting_id INT NOT NULL,
+	user_name    STRING,
+	statement    STRING,
+	info         JSONB,
+	error        STRING,
+	unique_id    BYTES NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		query := `SELECT timestamp, "eventType", "targetID", "reportingID", info, "uniqueID"
+FROM system.eventlog ORDER BY timestamp`
+		rows, _ /* cols */, err :=
+			p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryWithUser(
+				ctx, "crdb-internal-eventlog-table", p.txn,
+				p.SessionData().User, query)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rows {
+			timestamp, eventType, targetID, reportingID, infoStr, uniqueID :=
+				r[0], r[1], r[2], r[3], r[4], r[5]
+
+			var userName, statement, info, errorStr = tree.DNull, tree.DNull, tree.DNull, tree.DNull
+			if infoStr != tree.DNull {
+				infoText := string(tree.MustBeDString(infoStr))
+				// Report payloads which don't match the type of their event.
+				if _, err := DecodeEventPayload(
+					EventLogType(tree.MustBeDString(eventType)), infoText,
+				); err != nil {
+					errorStr = tree.NewDString(fmt.Sprintf("error decoding payload: %v", err))
+				}
+				if j, err := json.ParseJSON(infoText); err == nil {
+					info = tree.NewDJSON(j)
+					if userName, err = jsonStringField(j, "User"); err != nil {
+						return err
+					}
+					if statement, err = jsonStringField(j, "Statement"); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := addRow(
+				timestamp,
+				eventType,
+				targetID,
+				reportingID,
+				userName,
+				statement,
+				info,
+				errorStr,
+				uniqueID,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// jsonStringField returns the text of the given field of a JSON object, or
+// NULL if the field is not set.
+func jsonStringField(j json.JSON, key string) (tree.Datum, error) {
+	v, err := j.FetchValKey(key)
+	if err != nil || v == nil {
+		return tree.DNull, err
+	}
+	text, err := v.AsText()
+	if err != nil || text == nil {
+		return tree.DNull, err
+	}
+	return tree.NewDString(*text), nil
+}
+
 func tsOrNull(micros int64) tree.Datum {
 	if micros == 0 {
 		return tree.DNull
@@ -592,6 +688,8 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   overhead_lat_var    FLOAT NOT NULL,
   bytes_read          INT NOT NULL,
   rows_read           INT NOT NULL,
+  temp_storage_bytes_written INT NOT NULL,
+  temp_storage_bytes_read    INT NOT NULL,
   implicit_txn        BOOL NOT NULL
 )`,
 	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
@@ -670,6 +768,8 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.GetVariance(s.data.Count))),
 					tree.NewDInt(tree.DInt(s.data.BytesRead)),
 					tree.NewDInt(tree.DInt(s.data.RowsRead)),
+					tree.NewDInt(tree.DInt(s.data.TempStorageBytesWritten)),
+					tree.NewDInt(tree.DInt(s.data.TempStorageBytesRead)),
 					tree.MakeDBool(tree.DBool(stmtKey.implicitTxn)),
 				)
 				s.Unlock()
@@ -1063,6 +1163,378 @@ var crdbInternalLocalMetricsTable = virtualSchemaTable{
 	},
 }
 
+// crdbInternalLocalTxnContentionTable exposes the transaction contention
+// events recorded by the stores on the current node.
+var crdbInternalLocalTxnContentionTable = virtualSchemaTable{
+	comment: "transaction contention events (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_txn_contention (
+  node_id           INT NOT NULL,       -- the node on which the contention was observed
+  timestamp         TIMESTAMP NOT NULL, -- the time at which the pusher encountered the intent
+  key               BYTES NOT NULL,     -- the key of the conflicting intent
+  pretty_key        STRING NOT NULL,    -- the key of the conflicting intent, pretty-printed
+  pusher_txn_id     UUID,               -- the ID of the pushing txn; NULL for non-transactional requests
+  pusher_txn_name   STRING,             -- the name of the pushing txn
+  pusher_statements STRING[] NOT NULL,  -- the fingerprints of the statements of the pushing txn, if it was started on this node and kv.contention.record_statements.enabled is set
+  pushee_txn_id     UUID NOT NULL,      -- the ID of the pushed txn
+  pushee_statements STRING[] NOT NULL,  -- the fingerprints of the statements of the pushed txn, likewise
+  push_type         STRING NOT NULL,    -- the type of the push
+  duration          INTERVAL NOT NULL,  -- the time spent waiting for and pushing the pushee
+  pushee_status     STRING NOT NULL,    -- the status of the pushee after the push
+  error             STRING              -- the error returned by the push, if any
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_txn_contention"); err != nil {
+			return err
+		}
+
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		for _, ev := range p.ExecCfg().ContentionRegistry.Events() {
+			pusherTxnID, pusherTxnName := tree.DNull, tree.DNull
+			if ev.PusherTxnID != uuid.Nil {
+				pusherTxnID = tree.NewDUuid(tree.DUuid{UUID: ev.PusherTxnID})
+				pusherTxnName = tree.NewDString(ev.PusherTxnName)
+			}
+			errDatum := tree.DNull
+			if ev.Err != "" {
+				errDatum = tree.NewDString(ev.Err)
+			}
+			pusherStmts := tree.NewDArray(types.String)
+			for _, f := range ev.PusherStatements {
+				if err := pusherStmts.Append(tree.NewDString(f)); err != nil {
+					return err
+				}
+			}
+			pusheeStmts := tree.NewDArray(types.String)
+			for _, f := range ev.PusheeStatements {
+				if err := pusheeStmts.Append(tree.NewDString(f)); err != nil {
+					return err
+				}
+			}
+			if err := addRow(
+				nodeID,
+				tree.MakeDTimestamp(ev.Time, time.Microsecond),
+				tree.NewDBytes(tree.DBytes(ev.Key)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, ev.Key)),
+				pusherTxnID,
+				pusherTxnName,
+				pusherStmts,
+				tree.NewDUuid(tree.DUuid{UUID: ev.PusheeTxnID}),
+				pusheeStmts,
+				tree.NewDString(ev.PushType.String()),
+				&tree.DInterval{Duration: duration.MakeDuration(ev.Duration.Nanoseconds(), 0, 0)},
+				tree.NewDString(ev.PusheeStatus.String()),
+				errDatum,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalLocalStmtDiagnosticsTable exposes the statement diagnostics
+// requests of the current node and the bundles collected for them.
+var crdbInternalLocalStmtDiagnosticsTable = virtualSchemaTable{
+	comment: "statement diagnostics requests and bundles (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_statement_diagnostics (
+  id                    INT NOT NULL,       -- the ID of the request
+  statement_fingerprint STRING NOT NULL,    -- the fingerprint of the statements to collect diagnostics for
+  requested_at          TIMESTAMP NOT NULL, -- the time at which the request was made
+  min_execution_latency INTERVAL NOT NULL,  -- the minimum latency of the execution to collect diagnostics for
+  expires_at            TIMESTAMP,          -- the time after which the request is discarded if not completed
+  sampling_probability  FLOAT NOT NULL,     -- the fraction of the matching executions which are traced, or 0 for all
+  completed             BOOL NOT NULL,      -- whether the bundle has been collected
+  collected_at          TIMESTAMP,          -- the time at which the bundle was collected
+  statement             STRING,             -- the statement for which the bundle was collected
+  bundle                BYTES               -- the bundle, a zip archive
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_statement_diagnostics"); err != nil {
+			return err
+		}
+
+		for _, req := range p.ExecCfg().StmtDiagnosticsRegistry.Requests() {
+			expiresAt := tree.DNull
+			if !req.ExpiresAt.IsZero() {
+				expiresAt = tree.MakeDTimestamp(req.ExpiresAt, time.Microsecond)
+			}
+			collectedAt, statement, bundle := tree.DNull, tree.DNull, tree.DNull
+			if req.Completed() {
+				collectedAt = tree.MakeDTimestamp(req.CollectedAt, time.Microsecond)
+				statement = tree.NewDString(req.Statement)
+				bundle = tree.NewDBytes(tree.DBytes(req.Bundle))
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(req.ID)),
+				tree.NewDString(req.Fingerprint),
+				tree.MakeDTimestamp(req.RequestedAt, time.Microsecond),
+				&tree.DInterval{
+					Duration: duration.MakeDuration(req.MinExecutionLatency.Nanoseconds(), 0, 0),
+				},
+				expiresAt,
+				tree.NewDFloat(tree.DFloat(req.SamplingProbability)),
+				tree.MakeDBool(tree.DBool(req.Completed())),
+				collectedAt,
+				statement,
+				bundle,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalLocalIndexRecommendationsTable exposes the index
+// recommendations derived from the statement statistics of the current node.
+var crdbInternalLocalIndexRecommendationsTable = virtualSchemaTable{
+	comment: "index recommendations derived from statement statistics (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_index_recommendations (
+  type            STRING NOT NULL,   -- CREATE or DROP
+  table_name      STRING NOT NULL,   -- the fully qualified name of the table
+  index_name      STRING,            -- the index to drop
+  columns         STRING[] NOT NULL, -- the key columns of the index
+  statement       STRING NOT NULL,   -- the statement applying the recommendation
+  reason          STRING NOT NULL,   -- why the recommendation is made
+  fingerprints    STRING[] NOT NULL, -- the statements which would benefit from the index to create
+  execution_count INT NOT NULL       -- the number of executions of these statements
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_index_recommendations"); err != nil {
+			return err
+		}
+
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return errors.AssertionFailedf(
+				"cannot access sql statistics from this context")
+		}
+		var tables []indexrec.Table
+		if err := forEachTableDesc(ctx, p, nil /* dbContext */, hideVirtual,
+			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
+				if !table.IsPhysicalTable() || table.IsSequence() {
+					return nil
+				}
+				tables = append(tables, indexrec.Table{
+					Name: tree.MakeTableNameWithSchema(tree.Name(db.Name), tree.Name(scName), tree.Name(table.Name)),
+					Desc: table,
+				})
+				return nil
+			}); err != nil {
+			return err
+		}
+
+		stats := sqlStats.getUnscrubbedStmtStats(p.ExecCfg().VirtualSchemas)
+		minExecutions := indexrec.MinExecutions.Get(&p.ExecCfg().Settings.SV)
+		for _, rec := range indexrec.Recommend(stats, tables, minExecutions) {
+			indexName := tree.DNull
+			if rec.Index != "" {
+				indexName = tree.NewDString(rec.Index)
+			}
+			columns := tree.NewDArray(types.String)
+			for _, c := range rec.Columns {
+				if err := columns.Append(tree.NewDString(c)); err != nil {
+					return err
+				}
+			}
+			fingerprints := tree.NewDArray(types.String)
+			for _, f := range rec.Fingerprints {
+				if err := fingerprints.Append(tree.NewDString(f)); err != nil {
+					return err
+				}
+			}
+			if err := addRow(
+				tree.NewDString(string(rec.Type)),
+				tree.NewDString(rec.Table),
+				indexName,
+				columns,
+				tree.NewDString(rec.SQL),
+				tree.NewDString(rec.Reason),
+				fingerprints,
+				tree.NewDInt(tree.DInt(rec.ExecutionCount)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalLocalMemoryMonitorsTable exposes the tree of the SQL memory
+// monitors of the current node, so that the allocations which cause a
+// "memory budget exceeded" error can be attributed.
+var crdbInternalLocalMemoryMonitorsTable = virtualSchemaTable{
+	comment: "memory monitors and their current and peak usage (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_memory_monitors (
+  level           INT NOT NULL,    -- the depth of the monitor in the tree, 0 for the root
+  name            STRING NOT NULL, -- the name of the monitor, e.g. "sql" or "session"
+  id              INT NOT NULL,    -- identifies the monitor while it is running
+  parent_id       INT,             -- the id of the monitor's pool; NULL for the root
+  used            INT NOT NULL,    -- the bytes currently allocated through the monitor
+  peak            INT NOT NULL,    -- the maximum of used since the monitor was started
+  pool_budget     INT NOT NULL,    -- the bytes currently held from the pool
+  reserved_budget INT NOT NULL,    -- the bytes pre-reserved for the monitor
+  limit_bytes     INT              -- the local limit of the monitor, if any
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_memory_monitors"); err != nil {
+			return err
+		}
+
+		root := p.ExecCfg().RootMemoryMonitor
+		if root == nil {
+			return errors.AssertionFailedf(
+				"cannot access memory monitors from this context")
+		}
+		return root.TraverseTree(func(s mon.MonitorState) error {
+			parentID, limit := tree.DNull, tree.DNull
+			if s.ParentID != 0 {
+				parentID = tree.NewDInt(tree.DInt(s.ParentID))
+			}
+			if s.Limit != 0 {
+				limit = tree.NewDInt(tree.DInt(s.Limit))
+			}
+			return addRow(
+				tree.NewDInt(tree.DInt(s.Level)),
+				tree.NewDString(s.Name),
+				tree.NewDInt(tree.DInt(s.ID)),
+				parentID,
+				tree.NewDInt(tree.DInt(s.Used)),
+				tree.NewDInt(tree.DInt(s.Peak)),
+				tree.NewDInt(tree.DInt(s.PoolBudget)),
+				tree.NewDInt(tree.DInt(s.ReservedBudget)),
+				limit,
+			)
+		})
+	},
+}
+
+// crdbInternalLocalOperationsTable exposes the long-running internal
+// operations, e.g. schema change backfills, GC runs and snapshot sends,
+// which are currently running on the current node.
+var crdbInternalLocalOperationsTable = virtualSchemaTable{
+	comment: "long-running internal operations in progress (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_operations (
+  id        INT NOT NULL,       -- identifies the operation while it is running
+  operation STRING NOT NULL,    -- the description of the operation
+  start     TIMESTAMP NOT NULL, -- the time at which the operation started
+  progress  FLOAT,              -- the fraction of the operation which is completed, if reported
+  details   STRING NOT NULL,    -- the current state of the operation, if reported
+  trace_id  INT NOT NULL,       -- the ID of the trace of the operation's span
+  span_id   INT NOT NULL        -- the ID of the operation's span
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_operations"); err != nil {
+			return err
+		}
+
+		tr, ok := p.ExecCfg().AmbientCtx.Tracer.(*tracing.Tracer)
+		if !ok {
+			return errors.AssertionFailedf("cannot access the operations from this context")
+		}
+		for _, op := range tr.Operations() {
+			progress := tree.DNull
+			if op.Progress >= 0 {
+				progress = tree.NewDFloat(tree.DFloat(op.Progress))
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(op.ID)),
+				tree.NewDString(op.Name),
+				tree.MakeDTimestamp(op.StartTime, time.Microsecond),
+				progress,
+				tree.NewDString(op.Details),
+				tree.NewDInt(tree.DInt(int64(op.TraceID))),
+				tree.NewDInt(tree.DInt(int64(op.SpanID))),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalLocalExecutionInsightsTable exposes the statement executions
+// which the current node detected as anomalous, along with the causes of the
+// anomalies. See execution_insights.go.
+var crdbInternalLocalExecutionInsightsTable = virtualSchemaTable{
+	comment: "statement executions detected as anomalous (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_execution_insights (
+  query_id         STRING NOT NULL,    -- the ID of the execution of the statement
+  session_id       STRING NOT NULL,    -- the ID of the session which executed the statement
+  txn_id           UUID,               -- the ID of the transaction of the execution
+  app_name         STRING NOT NULL,    -- the application name of the session
+  user_name        STRING NOT NULL,    -- the user of the session
+  database_name    STRING NOT NULL,    -- the current database of the session
+  fingerprint      STRING NOT NULL,    -- the fingerprint of the statement
+  plan_gist        STRING NOT NULL,    -- the shape of the plan of the execution
+  start            TIMESTAMP NOT NULL, -- the time at which the statement was received
+  service_latency  INTERVAL NOT NULL,  -- the service latency of the execution
+  baseline_latency INTERVAL,           -- the mean service latency of the fingerprint, if known
+  retries          INT NOT NULL,       -- the number of automatic retries of the execution
+  rows_read        INT NOT NULL,       -- the number of rows read by the execution
+  full_scans       STRING[] NOT NULL,  -- the tables and indexes scanned in full, as table@index
+  causes           STRING[] NOT NULL,  -- the reasons for which the execution was reported
+  details          STRING NOT NULL     -- a description of the causes
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_execution_insights"); err != nil {
+			return err
+		}
+
+		for _, insight := range p.statsCollector.SQLStats().insights.get() {
+			txnID := tree.DNull
+			if insight.txnID != (uuid.UUID{}) {
+				txnID = tree.NewDUuid(tree.DUuid{UUID: insight.txnID})
+			}
+			baselineLat := tree.DNull
+			if insight.baselineLat != 0 {
+				baselineLat = &tree.DInterval{
+					Duration: duration.MakeDuration(insight.baselineLat.Nanoseconds(), 0, 0),
+				}
+			}
+			fullScans := tree.NewDArray(types.String)
+			for _, scan := range insight.fullScans {
+				if err := fullScans.Append(tree.NewDString(scan)); err != nil {
+					return err
+				}
+			}
+			causes := tree.NewDArray(types.String)
+			for _, cause := range insight.causes {
+				if err := causes.Append(tree.NewDString(string(cause))); err != nil {
+					return err
+				}
+			}
+			if err := addRow(
+				tree.NewDString(insight.queryID.String()),
+				tree.NewDString(insight.sessionID.String()),
+				txnID,
+				tree.NewDString(insight.appName),
+				tree.NewDString(insight.user),
+				tree.NewDString(insight.database),
+				tree.NewDString(insight.fingerprint),
+				tree.NewDString(insight.planGist),
+				tree.MakeDTimestamp(insight.start, time.Microsecond),
+				&tree.DInterval{Duration: duration.MakeDuration(insight.serviceLat.Nanoseconds(), 0, 0)},
+				baselineLat,
+				tree.NewDInt(tree.DInt(insight.retries)),
+				tree.NewDInt(tree.DInt(insight.rowsRead)),
+				fullScans,
+				causes,
+				tree.NewDString(strings.Join(insight.details, "; ")),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalBuiltinFunctionsTable exposes the built-in function
 // metadata.
 var crdbInternalBuiltinFunctionsTable = virtualSchemaTable{
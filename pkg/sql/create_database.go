@@ -14,6 +14,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
@@ -88,11 +89,11 @@ func (n *createDatabaseNode) startExec(params runParams) error {
 			EventLogCreateDatabase,
 			int32(desc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				DatabaseName string
-				Statement    string
-				User         string
-			}{n.n.Name.String(), n.n.String(), params.SessionData().User},
+			&eventpb.CreateDatabase{
+				DatabaseName: n.n.Name.String(),
+				Statement:    n.n.String(),
+				User:         params.SessionData().User,
+			},
 		); err != nil {
 			return err
 		}
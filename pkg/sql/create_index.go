@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -152,15 +153,12 @@ func (n *createIndexNode) startExec(params runParams) error {
 		EventLogCreateIndex,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName  string
-			IndexName  string
-			Statement  string
-			User       string
-			MutationID uint32
-		}{
-			n.n.Table.FQString(), indexName, n.n.String(),
-			params.SessionData().User, uint32(mutationID),
+		&eventpb.CreateIndex{
+			TableName:  n.n.Table.FQString(),
+			IndexName:  indexName,
+			Statement:  n.n.String(),
+			User:       params.SessionData().User,
+			MutationID: uint32(mutationID),
 		},
 	)
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -486,10 +487,10 @@ func (r *createStatsResumer) OnSuccess(ctx context.Context, _ *client.Txn) error
 			EventLogCreateStatistics,
 			int32(details.Table.ID),
 			int32(r.evalCtx.NodeID),
-			struct {
-				TableName string
-				Statement string
-			}{details.FQTableName, details.Statement},
+			&eventpb.CreateStatistics{
+				TableName: details.FQTableName,
+				Statement: details.Statement,
+			},
 		)
 	})
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -247,11 +248,11 @@ func (n *createTableNode) startExec(params runParams) error {
 		EventLogCreateTable,
 		int32(desc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName string
-			Statement string
-			User      string
-		}{n.n.Table.FQString(), n.n.String(), params.SessionData().User},
+		&eventpb.CreateTable{
+			TableName: n.n.Table.FQString(),
+			Statement: n.n.String(),
+			User:      params.SessionData().User,
+		},
 	); err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -187,11 +188,7 @@ func (n *createViewNode) startExec(params runParams) error {
 		EventLogCreateView,
 		int32(desc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			ViewName  string
-			ViewQuery string
-			User      string
-		}{
+		&eventpb.CreateView{
 			ViewName:  tn.FQString(),
 			ViewQuery: n.viewQuery,
 			User:      params.SessionData().User,
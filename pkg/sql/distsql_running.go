@@ -425,10 +425,24 @@ type DistSQLReceiver struct {
 	// this node's clock.
 	updateClock func(observedTs hlc.Timestamp)
 
-	// bytesRead and rowsRead track the corresponding metrics while executing the
+	// stats accumulates the metrics reported by the flows while executing the
 	// statement.
+	stats topLevelQueryStats
+}
+
+// topLevelQueryStats contains some basic statistics about the run of a query,
+// accumulated from the metrics metadata reported by its processors.
+type topLevelQueryStats struct {
+	// bytesRead is the number of bytes read from the KV layer.
 	bytesRead int64
-	rowsRead  int64
+	// rowsRead is the number of rows read from the KV layer.
+	rowsRead int64
+	// tempStorageBytesWritten is the number of bytes spilled to temporary
+	// storage by processors that ran out of memory.
+	tempStorageBytesWritten int64
+	// tempStorageBytesRead is the number of bytes read back from temporary
+	// storage.
+	tempStorageBytesRead int64
 }
 
 // errWrap is a container for an error, for use with atomic.Value, which
@@ -613,8 +627,10 @@ func (r *DistSQLReceiver) Push(
 			}
 		}
 		if meta.Metrics != nil {
-			r.bytesRead += meta.Metrics.BytesRead
-			r.rowsRead += meta.Metrics.RowsRead
+			r.stats.bytesRead += meta.Metrics.BytesRead
+			r.stats.rowsRead += meta.Metrics.RowsRead
+			r.stats.tempStorageBytesWritten += meta.Metrics.TempStorageBytesWritten
+			r.stats.tempStorageBytesRead += meta.Metrics.TempStorageBytesRead
 			meta.Metrics.Release()
 			meta.Release()
 		}
@@ -741,7 +741,7 @@ func (h *hashJoiner) initStoredRows() error {
 			h.evalCtx,
 			h.MemMonitor,
 			h.diskMonitor,
-			h.flowCtx.Cfg.TempStorage,
+			h.tempStorage(h.flowCtx),
 		)
 		h.storedRows = &hrc
 	} else {
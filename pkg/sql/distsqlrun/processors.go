@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -552,6 +553,11 @@ type ProcessorBase struct {
 	// one by one (in stateDraining, inputsToDrain[0] is the one currently being
 	// drained).
 	inputsToDrain []RowSource
+
+	// tempStorageStats accounts for the disk usage of the maps produced by
+	// tempStorage(). If the processor spilled to disk, the usage is reported in
+	// the trailing metadata.
+	tempStorageStats diskmap.Stats
 }
 
 // Reset resets this ProcessorBase, retaining allocated memory in slices.
@@ -756,6 +762,22 @@ func (pb *ProcessorBase) moveToTrailingMeta() {
 	} else {
 		pb.InternalClose()
 	}
+	written, read := pb.tempStorageStats.BytesWritten(), pb.tempStorageStats.BytesRead()
+	if written != 0 || read != 0 {
+		meta := distsqlpb.GetProducerMeta()
+		meta.Metrics = distsqlpb.GetMetricsMeta()
+		meta.Metrics.TempStorageBytesWritten, meta.Metrics.TempStorageBytesRead = written, read
+		pb.trailingMeta = append(pb.trailingMeta, *meta)
+	}
+}
+
+// tempStorage returns the diskmap.Factory that the processor should use to
+// spill to disk, so that its disk usage is reported to the gateway as part of
+// the statement's statistics. The flowCtx is passed explicitly because some
+// processors set up their row containers before initializing the
+// ProcessorBase.
+func (pb *ProcessorBase) tempStorage(flowCtx *FlowCtx) diskmap.Factory {
+	return diskmap.NewCountingFactory(flowCtx.Cfg.TempStorage, &pb.tempStorageStats)
 }
 
 // ProcessRowHelper is a wrapper on top of ProcOutputHelper.ProcessRow(). It
@@ -90,7 +90,7 @@ func (s *sorterBase) init(
 			ordering,
 			input.OutputTypes(),
 			s.evalCtx,
-			flowCtx.Cfg.TempStorage,
+			s.tempStorage(flowCtx),
 			memMonitor,
 			s.diskMonitor,
 			0, /* rowCapacity */
@@ -337,8 +337,16 @@ func TestSorter(t *testing.T) {
 						}
 
 						var retRows sqlbase.EncDatumRows
+						var tempStorageBytesWritten int64
 						for {
-							row := out.NextNoMeta(t)
+							row, meta := out.Next()
+							if meta != nil {
+								if meta.Metrics == nil {
+									t.Fatalf("unexpected metadata: %v", meta)
+								}
+								tempStorageBytesWritten += meta.Metrics.TempStorageBytesWritten
+								continue
+							}
 							if row == nil {
 								break
 							}
@@ -357,6 +365,10 @@ func TestSorter(t *testing.T) {
 						if memLimit.expSpill != spilled {
 							t.Errorf("expected spill to disk=%t, found %t", memLimit.expSpill, spilled)
 						}
+						if spilled != (tempStorageBytesWritten > 0) {
+							t.Errorf("spilled to disk=%t but reported %d bytes written to temp storage",
+								spilled, tempStorageBytesWritten)
+						}
 						if spilled {
 							if scp, ok := s.(*sortChunksProcessor); ok {
 								if scp.rows.(*rowcontainer.DiskBackedRowContainer).UsingDisk() {
@@ -175,7 +175,7 @@ func newWindower(
 		evalCtx,
 		memMonitor,
 		w.diskMonitor,
-		flowCtx.Cfg.TempStorage,
+		w.tempStorage(flowCtx),
 	)
 	w.allRowsPartitioned = &allRowsPartitioned
 	if err := w.allRowsPartitioned.Init(
@@ -667,7 +667,7 @@ func (w *windower) computeWindowFunctions(ctx context.Context, evalCtx *tree.Eva
 		ordering,
 		w.inputTypes,
 		w.evalCtx,
-		w.flowCtx.Cfg.TempStorage,
+		w.tempStorage(w.flowCtx),
 		w.MemMonitor,
 		w.diskMonitor,
 		0, /* rowCapacity */
@@ -0,0 +1,340 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package doctor provides utilities for checking the consistency of the
+// descriptors, namespace entries and jobs of a cluster. The checks run on
+// copies of the system tables, so that they can be performed either against
+// a live cluster or against the contents of a debug zip.
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// DescriptorTableRow represents a row of the system.descriptor table.
+type DescriptorTableRow struct {
+	ID        int64
+	DescBytes []byte
+}
+
+// DescriptorTable represents the contents of the system.descriptor table.
+type DescriptorTable []DescriptorTableRow
+
+// NamespaceTableRow represents a row of the system.namespace table.
+type NamespaceTableRow struct {
+	ParentID int64
+	Name     string
+	ID       int64
+}
+
+// NamespaceTable represents the contents of the system.namespace table.
+type NamespaceTable []NamespaceTableRow
+
+// JobsTableRow represents the columns of crdb_internal.jobs that are
+// examined by the doctor.
+type JobsTableRow struct {
+	ID            int64
+	Status        string
+	DescriptorIDs []int64
+}
+
+// JobsTable represents the contents of the crdb_internal.jobs table.
+type JobsTable []JobsTableRow
+
+// Report is the outcome of an examination.
+type Report struct {
+	// Descriptors is the number of descriptors that were examined.
+	Descriptors int
+	// Problems lists the inconsistencies that were found, one per line.
+	Problems []string
+	// Repairs lists the SQL statements that fix some of the problems. Not
+	// every problem can be repaired automatically, and the statements must
+	// be reviewed before being run: they modify system tables directly.
+	Repairs []string
+}
+
+// OK returns true if no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) problemf(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) repairf(format string, args ...interface{}) {
+	r.Repairs = append(r.Repairs, fmt.Sprintf(format, args...))
+}
+
+// Print writes the report to w. If verbose is set, the number of descriptors
+// that were examined is printed as well.
+func (r *Report) Print(w io.Writer, verbose bool) {
+	if verbose {
+		fmt.Fprintf(w, "Examined %d descriptors.\n", r.Descriptors)
+	}
+	for _, p := range r.Problems {
+		fmt.Fprintln(w, p)
+	}
+	if len(r.Repairs) > 0 {
+		fmt.Fprintln(w, "-- The following statements may repair some of the problems above.")
+		fmt.Fprintln(w, "-- Review them carefully before running them as root.")
+		for _, s := range r.Repairs {
+			fmt.Fprintln(w, s)
+		}
+	}
+	if r.OK() {
+		fmt.Fprintln(w, "No problems found!")
+	}
+}
+
+// descEntry is a decoded descriptor.
+type descEntry struct {
+	table    *sqlbase.TableDescriptor
+	database *sqlbase.DatabaseDescriptor
+}
+
+func (d descEntry) name() string {
+	if d.table != nil {
+		return d.table.Name
+	}
+	return d.database.Name
+}
+
+func (d descEntry) parentID() sqlbase.ID {
+	if d.table != nil {
+		return d.table.ParentID
+	}
+	return keys.RootNamespaceID
+}
+
+func (d descEntry) dropped() bool {
+	return d.table != nil && d.table.Dropped()
+}
+
+// Examine runs all the consistency checks on the given copies of the system
+// tables and returns the problems found along with the statements that
+// repair them, where possible.
+func Examine(descTable DescriptorTable, namespaceTable NamespaceTable, jobsTable JobsTable) Report {
+	r := Report{Descriptors: len(descTable)}
+	descs := make(map[sqlbase.ID]descEntry, len(descTable))
+	ids := make([]sqlbase.ID, 0, len(descTable))
+
+	for _, row := range descTable {
+		var desc sqlbase.Descriptor
+		if err := protoutil.Unmarshal(row.DescBytes, &desc); err != nil {
+			r.problemf("Descriptor %d: cannot decode: %v", row.ID, err)
+			continue
+		}
+		var e descEntry
+		if t := desc.GetTable(); t != nil {
+			e.table = t
+		} else if db := desc.GetDatabase(); db != nil {
+			e.database = db
+		} else {
+			r.problemf("Descriptor %d: unknown descriptor type", row.ID)
+			continue
+		}
+		if id := desc.GetID(); int64(id) != row.ID {
+			r.problemf("Descriptor %d: stored under ID %d but has ID %d", row.ID, row.ID, id)
+			continue
+		}
+		descs[sqlbase.ID(row.ID)] = e
+		ids = append(ids, sqlbase.ID(row.ID))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	examineNamespace(&r, descs, ids, namespaceTable)
+	for _, id := range ids {
+		e := descs[id]
+		if e.database != nil {
+			if err := e.database.Validate(); err != nil {
+				r.problemf("Database %3d: %s: %v", id, e.database.Name, err)
+			}
+			continue
+		}
+		examineTable(&r, descs, e.table)
+	}
+	examineJobs(&r, descs, jobsTable)
+	return r
+}
+
+// examineNamespace checks that every namespace entry points to a descriptor
+// with the same name and parent, and that every descriptor that is not
+// being dropped has a namespace entry.
+func examineNamespace(
+	r *Report, descs map[sqlbase.ID]descEntry, ids []sqlbase.ID, namespaceTable NamespaceTable,
+) {
+	type nsKey struct {
+		parentID sqlbase.ID
+		name     string
+	}
+	entries := make(map[nsKey]sqlbase.ID, len(namespaceTable))
+	for _, row := range namespaceTable {
+		key := nsKey{parentID: sqlbase.ID(row.ParentID), name: row.Name}
+		id := sqlbase.ID(row.ID)
+		entries[key] = id
+		e, ok := descs[id]
+		if !ok {
+			r.problemf("Namespace entry (%d, %q): descriptor %d does not exist",
+				row.ParentID, row.Name, row.ID)
+			r.repairf(`DELETE FROM system.namespace WHERE "parentID" = %d AND name = %s;`,
+				row.ParentID, lex.EscapeSQLString(row.Name))
+			continue
+		}
+		if e.name() != row.Name || e.parentID() != key.parentID {
+			r.problemf("Namespace entry (%d, %q): descriptor %d has parent %d and name %q",
+				row.ParentID, row.Name, row.ID, e.parentID(), e.name())
+		}
+	}
+
+	for _, id := range ids {
+		e := descs[id]
+		if e.dropped() {
+			continue
+		}
+		key := nsKey{parentID: e.parentID(), name: e.name()}
+		nsID, ok := entries[key]
+		if !ok {
+			r.problemf("Descriptor %3d: %s: no namespace entry", id, e.name())
+			r.repairf(`INSERT INTO system.namespace ("parentID", name, id) VALUES (%d, %s, %d);`,
+				key.parentID, lex.EscapeSQLString(key.name), id)
+		} else if nsID != id {
+			r.problemf("Descriptor %3d: %s: namespace entry points to descriptor %d", id, e.name(), nsID)
+		}
+	}
+}
+
+// examineTable checks a table descriptor on its own, and the references to
+// its parent database and to the tables its foreign keys point to or are
+// referenced by.
+func examineTable(r *Report, descs map[sqlbase.ID]descEntry, table *sqlbase.TableDescriptor) {
+	prefix := fmt.Sprintf("Table %3d: %s", table.ID, table.Name)
+	if err := table.ValidateTable(nil /* st */); err != nil {
+		r.problemf("%s: %v", prefix, err)
+	}
+	if table.Dropped() {
+		return
+	}
+	if parent, ok := descs[table.ParentID]; !ok || parent.database == nil {
+		r.problemf("%s: parent database %d does not exist", prefix, table.ParentID)
+	}
+
+	getTable := func(id sqlbase.ID) *sqlbase.TableDescriptor {
+		if e, ok := descs[id]; ok {
+			return e.table
+		}
+		return nil
+	}
+
+	for i := range table.OutboundFKs {
+		fk := &table.OutboundFKs[i]
+		ref := getTable(fk.ReferencedTableID)
+		if ref == nil {
+			r.problemf("%s: foreign key %q references missing table %d", prefix, fk.Name, fk.ReferencedTableID)
+			continue
+		}
+		found := false
+		for j := range ref.InboundFKs {
+			if in := &ref.InboundFKs[j]; in.OriginTableID == table.ID && in.Name == fk.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.problemf("%s: foreign key %q has no back-reference on table %d (%s)",
+				prefix, fk.Name, ref.ID, ref.Name)
+		}
+	}
+	for i := range table.InboundFKs {
+		fk := &table.InboundFKs[i]
+		origin := getTable(fk.OriginTableID)
+		if origin == nil {
+			r.problemf("%s: foreign key back-reference %q from missing table %d",
+				prefix, fk.Name, fk.OriginTableID)
+			continue
+		}
+		found := false
+		for j := range origin.OutboundFKs {
+			if out := &origin.OutboundFKs[j]; out.ReferencedTableID == table.ID && out.Name == fk.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.problemf("%s: foreign key back-reference %q from table %d (%s) has no foreign key",
+				prefix, fk.Name, origin.ID, origin.Name)
+		}
+	}
+
+	// Descriptors that were not upgraded yet keep their foreign keys on the
+	// indexes.
+	for _, idx := range table.AllNonDropIndexes() {
+		if idx.ForeignKey.IsSet() {
+			ref := getTable(idx.ForeignKey.Table)
+			if ref == nil {
+				r.problemf("%s: index %q references missing table %d", prefix, idx.Name, idx.ForeignKey.Table)
+			} else if refIdx, err := ref.FindIndexByID(idx.ForeignKey.Index); err != nil {
+				r.problemf("%s: index %q references missing index %d of table %d (%s)",
+					prefix, idx.Name, idx.ForeignKey.Index, ref.ID, ref.Name)
+			} else {
+				found := false
+				for _, backref := range refIdx.ReferencedBy {
+					if backref.Table == table.ID && backref.Index == idx.ID {
+						found = true
+						break
+					}
+				}
+				if !found {
+					r.problemf("%s: index %q has no back-reference on index %q of table %d (%s)",
+						prefix, idx.Name, refIdx.Name, ref.ID, ref.Name)
+				}
+			}
+		}
+		for _, backref := range idx.ReferencedBy {
+			origin := getTable(backref.Table)
+			if origin == nil {
+				r.problemf("%s: index %q is referenced by missing table %d", prefix, idx.Name, backref.Table)
+				continue
+			}
+			originIdx, err := origin.FindIndexByID(backref.Index)
+			if err != nil || originIdx.ForeignKey.Table != table.ID || originIdx.ForeignKey.Index != idx.ID {
+				r.problemf("%s: index %q has a back-reference from index %d of table %d (%s) "+
+					"without foreign key", prefix, idx.Name, backref.Index, origin.ID, origin.Name)
+			}
+		}
+	}
+}
+
+// examineJobs checks that the jobs that are still running only refer to
+// existing descriptors.
+func examineJobs(r *Report, descs map[sqlbase.ID]descEntry, jobsTable JobsTable) {
+	for _, job := range jobsTable {
+		switch jobs.Status(job.Status) {
+		case jobs.StatusPending, jobs.StatusRunning, jobs.StatusPaused:
+		default:
+			continue
+		}
+		for _, id := range job.DescriptorIDs {
+			if _, ok := descs[sqlbase.ID(id)]; !ok {
+				r.problemf("Job %d: %s job refers to missing descriptor %d", job.ID, job.Status, id)
+				r.repairf("CANCEL JOB %d;", job.ID)
+				break
+			}
+		}
+	}
+}
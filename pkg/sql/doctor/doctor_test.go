@@ -0,0 +1,125 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package doctor_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/doctor"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func descRow(t *testing.T, desc sqlbase.DescriptorProto) doctor.DescriptorTableRow {
+	b, err := protoutil.Marshal(sqlbase.WrapDescriptor(desc))
+	require.NoError(t, err)
+	return doctor.DescriptorTableRow{ID: int64(desc.GetID()), DescBytes: b}
+}
+
+func TestExamine(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	systemDB := sqlbase.MakeSystemDatabaseDesc()
+	namespace := sqlbase.NamespaceTable
+
+	// fkTable is a copy of system.namespace with a foreign key to a table
+	// that doesn't exist.
+	fkTable := protoutil.Clone(&sqlbase.NamespaceTable).(*sqlbase.TableDescriptor)
+	fkTable.ID = 100
+	fkTable.Name = "fk"
+	fkTable.OutboundFKs = []sqlbase.ForeignKeyConstraint{{
+		OriginTableID:       100,
+		OriginColumnIDs:     []sqlbase.ColumnID{3},
+		ReferencedTableID:   101,
+		ReferencedColumnIDs: []sqlbase.ColumnID{1},
+		Name:                "fk_id_ref_missing",
+	}}
+
+	validNamespace := doctor.NamespaceTable{
+		{ParentID: keys.RootNamespaceID, Name: "system", ID: keys.SystemDatabaseID},
+		{ParentID: keys.SystemDatabaseID, Name: "namespace", ID: keys.NamespaceTableID},
+	}
+
+	testCases := []struct {
+		name      string
+		descTable doctor.DescriptorTable
+		nsTable   doctor.NamespaceTable
+		jobsTable doctor.JobsTable
+		expected  string
+	}{
+		{
+			name:      "valid",
+			descTable: doctor.DescriptorTable{descRow(t, &systemDB), descRow(t, &namespace)},
+			nsTable:   validNamespace,
+			jobsTable: doctor.JobsTable{{ID: 1, Status: "succeeded", DescriptorIDs: []int64{52}}},
+			expected: `Examined 2 descriptors.
+No problems found!
+`,
+		},
+		{
+			name:      "dangling namespace entry and descriptor",
+			descTable: doctor.DescriptorTable{descRow(t, &systemDB), descRow(t, &namespace)},
+			nsTable: doctor.NamespaceTable{
+				{ParentID: keys.RootNamespaceID, Name: "system", ID: keys.SystemDatabaseID},
+				{ParentID: keys.SystemDatabaseID, Name: "foo", ID: 52},
+			},
+			expected: `Examined 2 descriptors.
+Namespace entry (1, "foo"): descriptor 52 does not exist
+Descriptor   2: namespace: no namespace entry
+-- The following statements may repair some of the problems above.
+-- Review them carefully before running them as root.
+DELETE FROM system.namespace WHERE "parentID" = 1 AND name = 'foo';
+INSERT INTO system.namespace ("parentID", name, id) VALUES (1, 'namespace', 2);
+`,
+		},
+		{
+			name: "invalid foreign key",
+			descTable: doctor.DescriptorTable{
+				descRow(t, &systemDB), descRow(t, &namespace), descRow(t, fkTable),
+			},
+			nsTable: append(validNamespace,
+				doctor.NamespaceTableRow{ParentID: keys.SystemDatabaseID, Name: "fk", ID: 100}),
+			expected: `Examined 3 descriptors.
+Table 100: fk: foreign key "fk_id_ref_missing" references missing table 101
+`,
+		},
+		{
+			name:      "orphaned job",
+			descTable: doctor.DescriptorTable{descRow(t, &systemDB), descRow(t, &namespace)},
+			nsTable:   validNamespace,
+			jobsTable: doctor.JobsTable{{ID: 1, Status: "running", DescriptorIDs: []int64{52}}},
+			expected: `Examined 2 descriptors.
+Job 1: running job refers to missing descriptor 52
+-- The following statements may repair some of the problems above.
+-- Review them carefully before running them as root.
+CANCEL JOB 1;
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := doctor.Examine(tc.descTable, tc.nsTable, tc.jobsTable)
+			var buf bytes.Buffer
+			report.Print(&buf, true /* verbose */)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("undecodable descriptor", func(t *testing.T) {
+		report := doctor.Examine(doctor.DescriptorTable{{ID: 52, DescBytes: []byte("foo")}}, nil, nil)
+		require.False(t, report.OK())
+		require.Contains(t, report.Problems[0], "Descriptor 52: cannot decode")
+	})
+}
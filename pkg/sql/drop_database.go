@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -198,12 +199,12 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 		EventLogDropDatabase,
 		int32(n.dbDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			DatabaseName         string
-			Statement            string
-			User                 string
-			DroppedSchemaObjects []string
-		}{n.n.Name.String(), n.n.String(), p.SessionData().User, tbNameStrings},
+		&eventpb.DropDatabase{
+			DatabaseName:         n.n.Name.String(),
+			Statement:            n.n.String(),
+			User:                 p.SessionData().User,
+			DroppedSchemaObjects: tbNameStrings,
+		},
 	)
 }
 
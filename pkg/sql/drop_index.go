@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -297,14 +298,13 @@ func (p *planner) dropIndexByName(
 		EventLogDropIndex,
 		int32(tableDesc.ID),
 		int32(p.extendedEvalCtx.NodeID),
-		struct {
-			TableName           string
-			IndexName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{tn.FQString(), string(idxName), jobDesc, p.SessionData().User, uint32(mutationID),
-			droppedViews},
+		&eventpb.DropIndex{
+			TableName:           tn.FQString(),
+			IndexName:           string(idxName),
+			Statement:           jobDesc,
+			User:                p.SessionData().User,
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	)
 }
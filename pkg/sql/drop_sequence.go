@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -71,11 +72,11 @@ func (n *dropSequenceNode) startExec(params runParams) error {
 			EventLogDropSequence,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				SequenceName string
-				Statement    string
-				User         string
-			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User},
+			&eventpb.DropSequence{
+				SequenceName: toDel.tn.FQString(),
+				Statement:    n.n.String(),
+				User:         params.SessionData().User,
+			},
 		); err != nil {
 			return err
 		}
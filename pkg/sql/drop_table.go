@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -127,13 +128,12 @@ func (n *dropTableNode) startExec(params runParams) error {
 			EventLogDropTable,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				TableName           string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{toDel.tn.FQString(), n.n.String(),
-				params.SessionData().User, droppedViews},
+			&eventpb.DropTable{
+				TableName:           toDel.tn.FQString(),
+				Statement:           n.n.String(),
+				User:                params.SessionData().User,
+				CascadeDroppedViews: droppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -90,12 +91,12 @@ func (n *dropViewNode) startExec(params runParams) error {
 			EventLogDropView,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				ViewName            string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User, cascadeDroppedViews},
+			&eventpb.DropView{
+				ViewName:            toDel.tn.FQString(),
+				Statement:           n.n.String(),
+				User:                params.SessionData().User,
+				CascadeDroppedViews: cascadeDroppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -13,17 +13,22 @@ package sql
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventwebhook"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
-	"github.com/pkg/errors"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
 )
 
 // EventLogType represents an event type that can be recorded in the event log.
 type EventLogType string
 
 // NOTE: When you add a new event type here. Please manually add it to
-// pkg/ui/src/util/eventTypes.ts so that it will be recognized in the UI.
+// pkg/ui/src/util/eventTypes.ts so that it will be recognized in the UI, and
+// define its payload in pkg/sql/eventpb/events.proto and eventLogPayloads.
 const (
 	// EventLogCreateDatabase is recorded when a database is created.
 	EventLogCreateDatabase EventLogType = "create_database"
@@ -99,21 +104,65 @@ const (
 	EventLogCreateStatistics EventLogType = "create_statistics"
 )
 
-// EventLogSetClusterSettingDetail is the json details for a settings change.
-type EventLogSetClusterSettingDetail struct {
-	SettingName string
-	Value       string
-	User        string
+// eventLogPayloads maps each event type to a constructor for the typed
+// payload recorded in the info column of its events.
+var eventLogPayloads = map[EventLogType]func() eventpb.EventPayload{
+	EventLogCreateDatabase:       func() eventpb.EventPayload { return &eventpb.CreateDatabase{} },
+	EventLogDropDatabase:         func() eventpb.EventPayload { return &eventpb.DropDatabase{} },
+	EventLogCreateTable:          func() eventpb.EventPayload { return &eventpb.CreateTable{} },
+	EventLogDropTable:            func() eventpb.EventPayload { return &eventpb.DropTable{} },
+	EventLogTruncateTable:        func() eventpb.EventPayload { return &eventpb.TruncateTable{} },
+	EventLogAlterTable:           func() eventpb.EventPayload { return &eventpb.AlterTable{} },
+	EventLogCommentOnColumn:      func() eventpb.EventPayload { return &eventpb.CommentOnColumn{} },
+	EventLogCommentOnDatabase:    func() eventpb.EventPayload { return &eventpb.CommentOnDatabase{} },
+	EventLogCommentOnTable:       func() eventpb.EventPayload { return &eventpb.CommentOnTable{} },
+	EventLogCreateIndex:          func() eventpb.EventPayload { return &eventpb.CreateIndex{} },
+	EventLogDropIndex:            func() eventpb.EventPayload { return &eventpb.DropIndex{} },
+	EventLogAlterIndex:           func() eventpb.EventPayload { return &eventpb.AlterIndex{} },
+	EventLogCreateView:           func() eventpb.EventPayload { return &eventpb.CreateView{} },
+	EventLogDropView:             func() eventpb.EventPayload { return &eventpb.DropView{} },
+	EventLogCreateSequence:       func() eventpb.EventPayload { return &eventpb.CreateSequence{} },
+	EventLogDropSequence:         func() eventpb.EventPayload { return &eventpb.DropSequence{} },
+	EventLogAlterSequence:        func() eventpb.EventPayload { return &eventpb.AlterSequence{} },
+	EventLogReverseSchemaChange:  func() eventpb.EventPayload { return &eventpb.ReverseSchemaChange{} },
+	EventLogFinishSchemaChange:   func() eventpb.EventPayload { return &eventpb.FinishSchemaChange{} },
+	EventLogFinishSchemaRollback: func() eventpb.EventPayload { return &eventpb.FinishSchemaChangeRollback{} },
+	EventLogNodeJoin:             func() eventpb.EventPayload { return &eventpb.NodeJoin{} },
+	EventLogNodeRestart:          func() eventpb.EventPayload { return &eventpb.NodeRestart{} },
+	EventLogNodeDecommissioned:   func() eventpb.EventPayload { return &eventpb.NodeDecommissioned{} },
+	EventLogNodeRecommissioned:   func() eventpb.EventPayload { return &eventpb.NodeRecommissioned{} },
+	EventLogSetClusterSetting:    func() eventpb.EventPayload { return &eventpb.SetClusterSetting{} },
+	EventLogSetZoneConfig:        func() eventpb.EventPayload { return &eventpb.SetZoneConfig{} },
+	EventLogRemoveZoneConfig:     func() eventpb.EventPayload { return &eventpb.RemoveZoneConfig{} },
+	EventLogCreateStatistics:     func() eventpb.EventPayload { return &eventpb.CreateStatistics{} },
+}
+
+// DecodeEventPayload decodes the info column of an event of the given type
+// into its typed payload.
+func DecodeEventPayload(eventType EventLogType, info string) (eventpb.EventPayload, error) {
+	newPayload, ok := eventLogPayloads[eventType]
+	if !ok {
+		return nil, errors.Errorf("unknown event type %q", eventType)
+	}
+	payload := newPayload()
+	if err := json.Unmarshal([]byte(info), payload); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s event", eventType)
+	}
+	return payload, nil
 }
 
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	*InternalExecutor
+	webhook *eventwebhook.Sender
 }
 
 // MakeEventLogger constructs a new EventLogger.
 func MakeEventLogger(execCfg *ExecutorConfig) EventLogger {
-	return EventLogger{InternalExecutor: execCfg.InternalExecutor}
+	return EventLogger{
+		InternalExecutor: execCfg.InternalExecutor,
+		webhook:          execCfg.EventWebhookSender,
+	}
 }
 
 // InsertEventRecord inserts a single event into the event log as part of the
@@ -123,9 +172,24 @@ func (ev EventLogger) InsertEventRecord(
 	txn *client.Txn,
 	eventType EventLogType,
 	targetID, reportingID int32,
-	info interface{},
+	info eventpb.EventPayload,
 ) error {
-	// Record event record insertion in local log output.
+	newPayload, ok := eventLogPayloads[eventType]
+	if !ok {
+		return errors.AssertionFailedf("unknown event type %q", eventType)
+	}
+	if expected := newPayload(); reflect.TypeOf(info) != reflect.TypeOf(expected) {
+		return errors.AssertionFailedf(
+			"event %q expects a %T payload, got %T", eventType, expected, info)
+	}
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	// Record event record insertion in local log output, and deliver it to
+	// the event log webhook, if any.
+	timestamp := timeutil.Now()
 	txn.AddCommitTrigger(func(ctx context.Context) {
 		log.Infof(
 			ctx, "Event: %q, target: %d, info: %+v",
@@ -133,6 +197,13 @@ func (ev EventLogger) InsertEventRecord(
 			targetID,
 			info,
 		)
+		ev.webhook.Enqueue(ctx, eventwebhook.Event{
+			Timestamp:   timestamp,
+			EventType:   string(eventType),
+			TargetID:    targetID,
+			ReportingID: reportingID,
+			Info:        infoBytes,
+		})
 	})
 
 	const insertEventTableStmt = `
@@ -147,14 +218,7 @@ VALUES(
 		eventType,
 		targetID,
 		reportingID,
-		nil, // info
-	}
-	if info != nil {
-		infoBytes, err := json.Marshal(info)
-		if err != nil {
-			return err
-		}
-		args[3] = string(infoBytes)
+		string(infoBytes),
 	}
 
 	rows, err := ev.Exec(ctx, "log-event", txn, insertEventTableStmt, args...)
@@ -0,0 +1,51 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package eventpb defines the typed payloads of the events recorded in the
+// system.eventlog table.
+package eventpb
+
+import "github.com/cockroachdb/cockroach/pkg/util/protoutil"
+
+// EventPayload is implemented by the payloads of all the events recorded in
+// system.eventlog.
+type EventPayload interface {
+	protoutil.Message
+	eventPayload()
+}
+
+func (*CreateDatabase) eventPayload()             {}
+func (*DropDatabase) eventPayload()               {}
+func (*CommentOnDatabase) eventPayload()          {}
+func (*CreateTable) eventPayload()                {}
+func (*DropTable) eventPayload()                  {}
+func (*TruncateTable) eventPayload()              {}
+func (*AlterTable) eventPayload()                 {}
+func (*CommentOnTable) eventPayload()             {}
+func (*CommentOnColumn) eventPayload()            {}
+func (*CreateIndex) eventPayload()                {}
+func (*DropIndex) eventPayload()                  {}
+func (*AlterIndex) eventPayload()                 {}
+func (*CreateView) eventPayload()                 {}
+func (*DropView) eventPayload()                   {}
+func (*CreateSequence) eventPayload()             {}
+func (*DropSequence) eventPayload()               {}
+func (*AlterSequence) eventPayload()              {}
+func (*ReverseSchemaChange) eventPayload()        {}
+func (*FinishSchemaChange) eventPayload()         {}
+func (*FinishSchemaChangeRollback) eventPayload() {}
+func (*NodeJoin) eventPayload()                   {}
+func (*NodeRestart) eventPayload()                {}
+func (*NodeDecommissioned) eventPayload()         {}
+func (*NodeRecommissioned) eventPayload()         {}
+func (*SetClusterSetting) eventPayload()          {}
+func (*SetZoneConfig) eventPayload()              {}
+func (*RemoveZoneConfig) eventPayload()           {}
+func (*CreateStatistics) eventPayload()           {}
@@ -0,0 +1,235 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package eventwebhook delivers the events recorded in the event log
+// (system.eventlog) to a webhook, so that external systems can react to
+// cluster changes without polling the event log. Events are POSTed as JSON,
+// one per request, in the order in which they were committed on the node, and
+// are retried with exponential backoff. If a secret is configured, requests
+// are signed with HMAC-SHA256.
+package eventwebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader is the header holding the hex-encoded HMAC-SHA256 of the
+// body of webhook requests, prefixed with "sha256=", when a secret is
+// configured.
+const SignatureHeader = "X-Cockroach-Signature"
+
+// queueSize is the number of events which can be waiting for delivery. Events
+// are dropped when the queue is full, e.g. because the webhook is down.
+const queueSize = 1024
+
+// requestTimeout is the timeout of a single webhook request.
+const requestTimeout = 10 * time.Second
+
+var webhookURL = settings.RegisterValidatedStringSetting(
+	"server.eventlog.webhook.url",
+	"if set, events recorded in the event log are POSTed as JSON to this http or https URL",
+	"",
+	func(_ *settings.Values, s string) error {
+		if s == "" {
+			return nil
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("unsupported webhook URL scheme %q", u.Scheme)
+		}
+		return nil
+	},
+)
+
+var webhookSecret = func() *settings.StringSetting {
+	s := settings.RegisterStringSetting(
+		"server.eventlog.webhook.secret",
+		"if set, event log webhook requests are signed with an HMAC-SHA256 of their body "+
+			"keyed by this secret, in the "+SignatureHeader+" header",
+		"",
+	)
+	s.SetConfidential()
+	return s
+}()
+
+var webhookMaxRetries = settings.RegisterNonNegativeIntSetting(
+	"server.eventlog.webhook.max_retries",
+	"the number of times the delivery of an event to the event log webhook is retried "+
+		"before the event is dropped",
+	5,
+)
+
+// Event is the payload of webhook requests.
+type Event struct {
+	Timestamp   time.Time `json:"timestamp"`
+	EventType   string    `json:"event_type"`
+	TargetID    int32     `json:"target_id"`
+	ReportingID int32     `json:"reporting_id"`
+	// Info holds the JSON details of the event, as found in system.eventlog.
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+// Sender delivers events to the webhook configured by the
+// server.eventlog.webhook.url setting. A nil Sender drops all events.
+type Sender struct {
+	st     *cluster.Settings
+	client http.Client
+	events chan Event
+	// retryOpts is the backoff between delivery attempts of an event. The
+	// number of retries is controlled by server.eventlog.webhook.max_retries.
+	retryOpts retry.Options
+
+	dropEvery log.EveryN
+}
+
+// NewSender returns a Sender, which delivers events once it is started.
+func NewSender(st *cluster.Settings) *Sender {
+	return &Sender{
+		st:     st,
+		client: http.Client{Timeout: requestTimeout},
+		events: make(chan Event, queueSize),
+		retryOpts: retry.Options{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+			// Decorrelate the retries of the nodes delivering to the same
+			// webhook.
+			DecorrelatedJitter: true,
+		},
+		dropEvery: log.Every(time.Minute),
+	}
+}
+
+// Enqueue queues an event for delivery, unless no webhook is configured. It
+// doesn't block; the event is dropped if the queue is full.
+func (s *Sender) Enqueue(ctx context.Context, ev Event) {
+	if s == nil || webhookURL.Get(&s.st.SV) == "" {
+		return
+	}
+	select {
+	case s.events <- ev:
+	default:
+		if s.dropEvery.ShouldLog() {
+			log.Warningf(ctx, "event log webhook queue is full, dropping %s event", ev.EventType)
+		}
+	}
+}
+
+// Start starts the delivery of the queued events, until the stopper
+// quiesces.
+func (s *Sender) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		for {
+			select {
+			case ev := <-s.events:
+				if err := s.deliver(ctx, ev); err != nil && ctx.Err() == nil {
+					log.Warningf(ctx, "dropping %s event after failing to deliver it to the event log webhook: %v",
+						ev.EventType, err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// deliver POSTs an event to the webhook, retrying on failures.
+func (s *Sender) deliver(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	maxRetries := webhookMaxRetries.Get(&s.st.SV)
+	opts := s.retryOpts
+	opts.MaxRetries = int(maxRetries)
+	opts.OnRetry = func(ctx context.Context, attempt int, backoff time.Duration) {
+		log.VEventf(ctx, 2, "failed to deliver %s event to the event log webhook, retry %d in %s: %v",
+			ev.EventType, attempt, backoff, err)
+	}
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		// The webhook may have been disabled or changed since the event was
+		// queued.
+		u := webhookURL.Get(&s.st.SV)
+		if u == "" {
+			return nil
+		}
+		var retryable bool
+		// A MaxRetries of 0 retries indefinitely, so no retries are handled
+		// here.
+		if retryable, err = s.post(ctx, u, body); err == nil || !retryable || maxRetries == 0 {
+			return err
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// post makes a single webhook request. It returns whether a failed request
+// may be retried.
+func (s *Sender) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	if secret := webhookSecret.Get(&s.st.SV); secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the body keyed by the secret,
+// as sent in the SignatureHeader of webhook requests.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package eventwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestSender(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	type request struct {
+		body      []byte
+		signature string
+	}
+	reqC := make(chan request, 10)
+	// The first request fails, and the following ones get the given status.
+	failFirst, status := int32(1), int32(http.StatusOK)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		reqC <- request{body: body, signature: r.Header.Get(SignatureHeader)}
+		if atomic.SwapInt32(&failFirst, 0) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+
+	st := cluster.MakeTestingClusterSettings()
+	u := st.MakeUpdater()
+	set := func(name, value, typ string) {
+		t.Helper()
+		if err := u.Set(name, value, typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := u.Set("server.eventlog.webhook.url", "ftp://example.com", "s"); !testutils.IsError(
+		err, "unsupported webhook URL scheme",
+	) {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	s := NewSender(st)
+	s.retryOpts.InitialBackoff = time.Millisecond
+	s.retryOpts.MaxBackoff = time.Millisecond
+	s.Start(ctx, stopper)
+
+	// Events aren't queued unless a webhook is configured.
+	ev := Event{
+		Timestamp:   timeutil.Unix(0, 123),
+		EventType:   "set_cluster_setting",
+		ReportingID: 1,
+		Info:        json.RawMessage(`{"SettingName":"foo"}`),
+	}
+	s.Enqueue(ctx, ev)
+	var nilSender *Sender
+	nilSender.Enqueue(ctx, ev)
+
+	set("server.eventlog.webhook.url", ts.URL, "s")
+	set("server.eventlog.webhook.secret", "sekret", "s")
+	s.Enqueue(ctx, ev)
+
+	// The first attempt fails, and the event is delivered again.
+	var reqs []request
+	for i := 0; i < 2; i++ {
+		select {
+		case req := <-reqC:
+			reqs = append(reqs, req)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for webhook requests")
+		}
+	}
+	for _, req := range reqs {
+		var delivered Event
+		if err := json.Unmarshal(req.body, &delivered); err != nil {
+			t.Fatal(err)
+		}
+		if delivered.EventType != ev.EventType || string(delivered.Info) != string(ev.Info) ||
+			!delivered.Timestamp.Equal(ev.Timestamp) {
+			t.Errorf("unexpected event %s", req.body)
+		}
+		if e := "sha256=" + Sign("sekret", req.body); req.signature != e {
+			t.Errorf("expected signature %s, got %s", e, req.signature)
+		}
+	}
+	select {
+	case req := <-reqC:
+		t.Fatalf("unexpected request %s", req.body)
+	default:
+	}
+
+	// Client errors aren't retried.
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	if err := s.deliver(ctx, ev); !testutils.IsError(err, "400 Bad Request") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if n := len(reqC); n != 1 {
+		t.Fatalf("expected a single attempt, got %d", n)
+	}
+	<-reqC
+
+	// Server errors are retried up to the maximum number of retries.
+	set("server.eventlog.webhook.max_retries", "2", "i")
+	atomic.StoreInt32(&status, http.StatusInternalServerError)
+	if err := s.deliver(ctx, ev); !testutils.IsError(err, "500 Internal Server Error") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if n := len(reqC); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
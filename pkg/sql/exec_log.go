@@ -79,6 +79,20 @@ import (
 //    message upon error). Needed for auditing and troubleshooting.
 //  - the number of times the statement was retried automatically
 //    by the server so far.
+//
+// Example slow query log, written to the SQL_PERF log channel:
+// I180211 07:30:48.832004 317 sql/exec_log.go:90  [client=127.0.0.1:62503,user=root,n1] 14 exec "cockroach" {} "SELECT * FROM ab ORDER BY b" {} 2345.670 12 "" 0 "sort(scan[ab@primary])" 1200.000 42949672960 42949672960
+//
+// In addition to the fields above, the slow query log includes:
+//
+//  - the gist of the query plan, i.e. the names of its nodes and the
+//    tables and indexes it scans, but not the constants of the query.
+//  - the time in milliseconds that the transaction spent waiting on the
+//    intents of other transactions since the statement started, as
+//    observed by this node.
+//  - the number of bytes the statement spilled to and read back from
+//    temporary storage, so that queries which used the disk heavily can
+//    be identified without correlating with node-level metrics.
 
 // logStatementsExecuteEnabled causes the Executor to log executed
 // statements and, if any, resulting errors.
@@ -88,17 +102,35 @@ var logStatementsExecuteEnabled = settings.RegisterBoolSetting(
 	false,
 )
 
+// slowQueryLogThreshold causes the Executor to log statements whose
+// service latency exceeds the threshold to the SQL_PERF log channel.
+var slowQueryLogThreshold = settings.RegisterNonNegativeDurationSetting(
+	"sql.log.slow_query.latency_threshold",
+	"when set to non-zero, log statements whose service latency exceeds "+
+		"the threshold to the SQL_PERF log channel",
+	0,
+)
+
 // maybeLogStatement conditionally records the current statement
-// (p.curPlan) to the exec / audit logs.
+// (p.curPlan) to the exec / audit / slow query logs.
 func (p *planner) maybeLogStatement(
-	ctx context.Context, lbl string, numRetries, rows int, err error,
+	ctx context.Context,
+	lbl string,
+	numRetries, rows int,
+	err error,
+	queryStats *topLevelQueryStats,
 ) {
 	p.maybeLogStatementInternal(
-		ctx, lbl, numRetries, rows, err, p.statsCollector.PhaseTimes()[sessionQueryReceived])
+		ctx, lbl, numRetries, rows, err, p.statsCollector.PhaseTimes()[sessionQueryReceived], queryStats)
 }
 
 func (p *planner) maybeLogStatementInternal(
-	ctx context.Context, lbl string, numRetries, rows int, err error, startTime time.Time,
+	ctx context.Context,
+	lbl string,
+	numRetries, rows int,
+	err error,
+	startTime time.Time,
+	queryStats *topLevelQueryStats,
 ) {
 	// Note: if you find the code below crashing because p.execCfg == nil,
 	// do not add a test "if p.execCfg == nil { do nothing }" !
@@ -108,8 +140,10 @@ func (p *planner) maybeLogStatementInternal(
 	logV := log.V(2)
 	logExecuteEnabled := logStatementsExecuteEnabled.Get(&p.execCfg.Settings.SV)
 	auditEventsDetected := len(p.curPlan.auditEvents) != 0
+	slowQueryThreshold := slowQueryLogThreshold.Get(&p.execCfg.Settings.SV)
+	slowQueryLogEnabled := slowQueryThreshold != 0
 
-	if !logV && !logExecuteEnabled && !auditEventsDetected {
+	if !logV && !logExecuteEnabled && !auditEventsDetected && !slowQueryLogEnabled {
 		return
 	}
 
@@ -140,7 +174,8 @@ func (p *planner) maybeLogStatementInternal(
 
 	plStr := p.extendedEvalCtx.Placeholders.Values.String()
 
-	age := float64(timeutil.Now().Sub(startTime).Nanoseconds()) / 1e6
+	elapsed := timeutil.Now().Sub(startTime)
+	age := float64(elapsed.Nanoseconds()) / 1e6
 
 	// rows passed as argument.
 
@@ -162,6 +197,16 @@ func (p *planner) maybeLogStatementInternal(
 		logger.Logf(ctx, "%s %q %s %q %s %.3f %d %q %d",
 			lbl, appName, logTrigger, stmtStr, plStr, age, rows, execErrStr, numRetries)
 	}
+	if slowQueryLogEnabled && elapsed >= slowQueryThreshold {
+		var contentionTime time.Duration
+		if p.txn != nil {
+			contentionTime = p.execCfg.ContentionRegistry.ContentionTime(p.txn.ID(), startTime)
+		}
+		log.SQLPerf.Infof(ctx, "%s %q %s %q %s %.3f %d %q %d %q %.3f %d %d",
+			lbl, appName, logTrigger, stmtStr, plStr, age, rows, execErrStr, numRetries,
+			p.curPlan.gist, float64(contentionTime.Nanoseconds())/1e6,
+			queryStats.tempStorageBytesWritten, queryStats.tempStorageBytesRead)
+	}
 	if logV {
 		// Copy to the main log.
 		log.VEventf(ctx, 2, "%s %q %s %q %s %.3f %d %q %d",
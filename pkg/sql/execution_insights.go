@@ -0,0 +1,262 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ring"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// Execution insights are the statement executions which a node detected as
+// anomalous, along with the causes of the anomaly. They are retained in
+// memory by each node and exposed by crdb_internal.node_execution_insights.
+//
+// An execution is reported if:
+// - its service latency is far above the mean latency of its fingerprint,
+//   as recorded by the statement statistics of the node,
+// - it scanned a whole table or index and read many rows, or
+// - it was retried automatically many times.
+
+var insightsEnabled = settings.RegisterBoolSetting(
+	"sql.insights.enabled",
+	"detect anomalous statement executions and record them in "+
+		"crdb_internal.node_execution_insights",
+	true,
+)
+
+var insightsLatencyThreshold = settings.RegisterNonNegativeDurationSetting(
+	"sql.insights.latency_threshold",
+	"minimum service latency of a statement execution for it to be reported "+
+		"as slower than the baseline of its fingerprint",
+	100*time.Millisecond,
+)
+
+var insightsFullScanMinRows = settings.RegisterNonNegativeIntSetting(
+	"sql.insights.full_scan.min_rows",
+	"minimum number of rows read by a statement execution with a full table "+
+		"or index scan for it to be reported",
+	10000,
+)
+
+var insightsHighRetryCount = settings.RegisterPositiveIntSetting(
+	"sql.insights.high_retry_count.threshold",
+	"minimum number of automatic retries of a statement execution for it to "+
+		"be reported",
+	10,
+)
+
+var insightsCapacity = settings.RegisterPositiveIntSetting(
+	"sql.insights.capacity",
+	"maximum number of execution insights retained by each node",
+	1000,
+)
+
+const (
+	// insightsMinBaselineExecutions is the number of executions of a
+	// fingerprint needed for its mean latency to be used as a baseline.
+	insightsMinBaselineExecutions = 10
+	// insightsLatencyMeanFactor and insightsLatencyStdDevs define how far
+	// above the baseline the latency of an execution must be to be reported:
+	// it must be at least insightsLatencyMeanFactor times the mean, and above
+	// the mean by insightsLatencyStdDevs standard deviations.
+	insightsLatencyMeanFactor = 2
+	insightsLatencyStdDevs    = 3
+)
+
+// insightCause is a reason for which an execution is reported.
+type insightCause string
+
+const (
+	insightCauseSlowerThanBaseline insightCause = "slower_than_baseline"
+	insightCauseFullScan           insightCause = "full_scan"
+	insightCauseHighRetryCount     insightCause = "high_retry_count"
+)
+
+// executionInsight is a statement execution detected as anomalous.
+type executionInsight struct {
+	queryID     ClusterWideID
+	sessionID   ClusterWideID
+	txnID       uuid.UUID
+	appName     string
+	user        string
+	database    string
+	fingerprint string
+	planGist    string
+	start       time.Time
+	serviceLat  time.Duration
+	// baselineLat is the mean service latency of the previous executions of
+	// the fingerprint, or zero if there are too few of them.
+	baselineLat time.Duration
+	retries     int
+	rowsRead    int64
+	// fullScans are the tables and indexes, as table@index, which the
+	// execution scanned in full.
+	fullScans []string
+
+	causes  []insightCause
+	details []string
+}
+
+// executionBaseline summarizes the service latency of the previous
+// executions of a fingerprint.
+type executionBaseline struct {
+	count int64
+	// mean and stdDev are in seconds.
+	mean, stdDev float64
+}
+
+// detectCauses sets the causes for which the execution is reported, if any.
+func (e *executionInsight) detectCauses(sv *settings.Values, baseline executionBaseline) {
+	if baseline.count >= insightsMinBaselineExecutions {
+		e.baselineLat = time.Duration(baseline.mean * float64(time.Second))
+		svcLat := e.serviceLat.Seconds()
+		if e.serviceLat >= insightsLatencyThreshold.Get(sv) &&
+			svcLat >= insightsLatencyMeanFactor*baseline.mean &&
+			svcLat > baseline.mean+insightsLatencyStdDevs*baseline.stdDev {
+			e.causes = append(e.causes, insightCauseSlowerThanBaseline)
+			e.details = append(e.details, fmt.Sprintf(
+				"service latency %s is %.1fx the mean latency %s of %d executions",
+				e.serviceLat, svcLat/baseline.mean, e.baselineLat, baseline.count))
+		}
+	}
+	if len(e.fullScans) > 0 && e.rowsRead >= insightsFullScanMinRows.Get(sv) {
+		e.causes = append(e.causes, insightCauseFullScan)
+		e.details = append(e.details, fmt.Sprintf(
+			"full scan of %s read %d rows", strings.Join(e.fullScans, ", "), e.rowsRead))
+	}
+	if int64(e.retries) >= insightsHighRetryCount.Get(sv) {
+		e.causes = append(e.causes, insightCauseHighRetryCount)
+		e.details = append(e.details, fmt.Sprintf("retried automatically %d times", e.retries))
+	}
+}
+
+// executionInsights retains the most recent execution insights of a node.
+type executionInsights struct {
+	syncutil.Mutex
+	buf ring.Buffer
+}
+
+// record adds an insight, evicting the oldest ones past the capacity.
+func (c *executionInsights) record(sv *settings.Values, insight *executionInsight) {
+	capacity := int(insightsCapacity.Get(sv))
+	c.Lock()
+	defer c.Unlock()
+	c.buf.AddLast(insight)
+	for c.buf.Len() > capacity {
+		c.buf.RemoveFirst()
+	}
+}
+
+// get returns the retained insights, oldest first.
+func (c *executionInsights) get() []*executionInsight {
+	c.Lock()
+	defer c.Unlock()
+	insights := make([]*executionInsight, c.buf.Len())
+	for i := range insights {
+		insights[i] = c.buf.Get(i).(*executionInsight)
+	}
+	return insights
+}
+
+// planFullScans returns the tables and indexes, as table@index, which the
+// plan scans in full.
+func planFullScans(ctx context.Context, top *planTop) []string {
+	var scans []string
+	observer := planObserver{
+		followRowSourceToPlanNode: true,
+		enterNode: func(ctx context.Context, nodeName string, plan planNode) (bool, error) {
+			if n, ok := plan.(*scanNode); ok && sqlbase.PrettySpans(n.index, n.spans, 2) == "-" {
+				scans = append(scans, fmt.Sprintf("%s@%s", n.desc.Name, n.index.Name))
+			}
+			return true, nil
+		},
+		expr: func(_ observeVerbosity, nodeName, fieldName string, n int, expr tree.Expr) {},
+	}
+	if err := populateEntriesForObserver(
+		ctx, top.plan, top.subqueryPlans, top.postqueryPlans, observer, true /* returnError */, sampledLogicalPlanFmtFlags,
+	); err != nil {
+		return nil
+	}
+	return scans
+}
+
+// recordExecutionInsight records the statement execution in the insights of
+// the node if it is detected as anomalous. It must be called before the
+// execution is added to the statement statistics, which provide the baseline
+// of its fingerprint.
+func (ex *connExecutor) recordExecutionInsight(
+	planner *planner,
+	distSQLUsed bool,
+	automaticRetryCount int,
+	err error,
+	svcLat time.Duration,
+	queryStats *topLevelQueryStats,
+) {
+	sv := &ex.server.cfg.Settings.SV
+	appName := ex.applicationName.Load().(string)
+	if !insightsEnabled.Get(sv) || strings.HasPrefix(appName, sqlbase.InternalAppNamePrefix) {
+		return
+	}
+
+	stmt := planner.stmt
+	flags := planner.curPlan.flags
+	var baseline executionBaseline
+	if s := ex.appStats.getStatsForStmt(
+		stmt, distSQLUsed, flags.IsSet(planFlagOptUsed), flags.IsSet(planFlagImplicitTxn), err,
+		false, /* createIfNonexistent */
+	); s != nil {
+		s.Lock()
+		baseline = executionBaseline{
+			count:  s.data.Count,
+			mean:   s.data.ServiceLat.Mean,
+			stdDev: math.Sqrt(s.data.ServiceLat.GetVariance(s.data.Count)),
+		}
+		s.Unlock()
+	}
+
+	insight := &executionInsight{
+		serviceLat: svcLat,
+		retries:    automaticRetryCount,
+		rowsRead:   queryStats.rowsRead,
+		fullScans:  planner.curPlan.fullScans,
+	}
+	insight.detectCauses(sv, baseline)
+	if len(insight.causes) == 0 {
+		return
+	}
+
+	insight.queryID = stmt.queryID
+	insight.sessionID = ex.sessionID
+	if planner.txn != nil {
+		insight.txnID = planner.txn.ID()
+	}
+	insight.appName = appName
+	insight.user = ex.sessionData.User
+	insight.database = ex.sessionData.Database
+	if stmt.AnonymizedStr != "" {
+		insight.fingerprint = stmt.AnonymizedStr
+	} else {
+		insight.fingerprint = anonymizeStmt(stmt.AST)
+	}
+	insight.planGist = planner.curPlan.gist
+	insight.start = planner.statsCollector.PhaseTimes()[sessionQueryReceived]
+	ex.server.sqlStats.insights.record(sv, insight)
+}
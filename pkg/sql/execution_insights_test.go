@@ -0,0 +1,110 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestExecutionInsightCauses(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	sv := &st.SV
+	// The mean latency of the fingerprint is 100ms, with a standard deviation
+	// of 20ms.
+	baseline := executionBaseline{count: 50, mean: 0.1, stdDev: 0.02}
+
+	testCases := []struct {
+		insight  executionInsight
+		baseline executionBaseline
+		expected []insightCause
+	}{
+		{executionInsight{serviceLat: 150 * time.Millisecond}, baseline, nil},
+		{executionInsight{serviceLat: 500 * time.Millisecond}, baseline,
+			[]insightCause{insightCauseSlowerThanBaseline}},
+		// Too few executions for a baseline.
+		{executionInsight{serviceLat: 500 * time.Millisecond}, executionBaseline{count: 5, mean: 0.1}, nil},
+		// Fast executions aren't reported, even if slower than the baseline.
+		{executionInsight{serviceLat: 50 * time.Millisecond}, executionBaseline{count: 50, mean: 0.001}, nil},
+		{executionInsight{fullScans: []string{"t@primary"}, rowsRead: 100}, baseline, nil},
+		{executionInsight{fullScans: []string{"t@primary"}, rowsRead: 20000}, baseline,
+			[]insightCause{insightCauseFullScan}},
+		{executionInsight{rowsRead: 20000}, baseline, nil},
+		{executionInsight{retries: 20, serviceLat: time.Second}, baseline,
+			[]insightCause{insightCauseSlowerThanBaseline, insightCauseHighRetryCount}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			tc.insight.detectCauses(sv, tc.baseline)
+			if !reflect.DeepEqual(tc.insight.causes, tc.expected) {
+				t.Errorf("expected causes %v, got %v", tc.expected, tc.insight.causes)
+			}
+			if len(tc.insight.details) != len(tc.insight.causes) {
+				t.Errorf("expected a detail per cause, got %q", tc.insight.details)
+			}
+		})
+	}
+
+	// The oldest insights are evicted past the capacity.
+	insightsCapacity.Override(sv, 2)
+	var insights executionInsights
+	for i := 0; i < 3; i++ {
+		insights.record(sv, &executionInsight{retries: i})
+	}
+	if got := insights.get(); len(got) != 2 || got[0].retries != 1 || got[1].retries != 2 {
+		t.Fatalf("unexpected insights: %+v", got)
+	}
+}
+
+func TestExecutionInsights(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	// Use a single connection so that the application name applies to all
+	// the statements below.
+	db.SetMaxOpenConns(1)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.insights.full_scan.min_rows = 10`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.insights.high_retry_count.threshold = 1`)
+	sqlDB.Exec(t, `SET application_name = 'insights'`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t SELECT i, i FROM generate_series(1, 100) AS g(i)`)
+
+	// A point lookup isn't reported, while a full scan is.
+	sqlDB.Exec(t, `SELECT v FROM t WHERE k = 1`)
+	sqlDB.Exec(t, `SELECT sum(v) FROM t`)
+	// force_retry returns a retriable error until the given interval has
+	// elapsed since the start of the transaction. The implicit transaction is
+	// retried automatically.
+	sqlDB.Exec(t, `SELECT crdb_internal.force_retry('50ms')`)
+
+	sqlDB.CheckQueryResults(t, `
+SELECT fingerprint, full_scans, causes
+  FROM crdb_internal.node_execution_insights
+ WHERE app_name = 'insights'
+ ORDER BY start`, [][]string{
+		{`SELECT sum(v) FROM t`, `{t@primary}`, `{full_scan}`},
+		{`SELECT crdb_internal.force_retry(_)`, `{}`, `{high_retry_count}`},
+	})
+}
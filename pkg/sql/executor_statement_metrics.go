@@ -120,8 +120,7 @@ func (ex *connExecutor) recordStatementSummary(
 	automaticRetryCount int,
 	rowsAffected int,
 	err error,
-	bytesRead int64,
-	rowsRead int64,
+	queryStats *topLevelQueryStats,
 ) {
 	phaseTimes := planner.statsCollector.PhaseTimes()
 
@@ -162,12 +161,18 @@ func (ex *connExecutor) recordStatementSummary(
 		m.SQLServiceLatency.RecordValue(svcLatRaw.Nanoseconds())
 	}
 
+	// The insights are recorded first, so that the baseline of the fingerprint
+	// doesn't include this execution.
+	ex.recordExecutionInsight(
+		planner, flags.IsSet(planFlagDistributed), automaticRetryCount, err, svcLatRaw, queryStats,
+	)
 	planner.statsCollector.RecordStatement(
 		stmt, planner.curPlan.savedPlanForStats,
 		flags.IsSet(planFlagDistributed), flags.IsSet(planFlagOptUsed), flags.IsSet(planFlagImplicitTxn),
 		automaticRetryCount, rowsAffected, err,
-		parseLat, planLat, runLat, svcLat, execOverhead, bytesRead, rowsRead,
+		parseLat, planLat, runLat, svcLat, execOverhead, queryStats,
 	)
+	ex.recordTxnContention(planner, phaseTimes[sessionQueryReceived])
 
 	if log.V(2) {
 		// ages since significant epochs
@@ -191,6 +196,23 @@ func (ex *connExecutor) recordStatementSummary(
 	}
 }
 
+// recordTxnStatement records the fingerprint of the statement about to be
+// executed by the given planner with the ID of its transaction, so that the
+// contention events involving the transaction can be attributed to its
+// statements. This is a no-op unless kv.contention.record_statements.enabled
+// is set.
+func (ex *connExecutor) recordTxnStatement(planner *planner) {
+	registry := ex.server.cfg.ContentionRegistry
+	if planner.txn == nil || !registry.RecordingStatements() {
+		return
+	}
+	fingerprint := planner.stmt.AnonymizedStr
+	if fingerprint == "" {
+		fingerprint = anonymizeStmt(planner.stmt.AST)
+	}
+	registry.RecordStatement(planner.txn.ID(), fingerprint)
+}
+
 func (ex *connExecutor) updateOptCounters(planFlags planFlags) {
 	m := &ex.metrics.EngineMetrics
 	if planFlags.IsSet(planFlagOptUsed) {
@@ -349,6 +349,9 @@ func doExpandPlan(
 	case *controlJobsNode:
 		n.rows, err = doExpandPlan(ctx, p, noParams, n.rows)
 
+	case *controlSchedulesNode:
+		n.rows, err = doExpandPlan(ctx, p, noParams, n.rows)
+
 	case *projectSetNode:
 		n.source, err = doExpandPlan(ctx, p, noParams, n.source)
 
@@ -876,6 +879,9 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *controlJobsNode:
 		n.rows = p.simplifyOrderings(n.rows, nil)
 
+	case *controlSchedulesNode:
+		n.rows = p.simplifyOrderings(n.rows, nil)
+
 	case *errorIfRowsNode:
 		n.plan = p.simplifyOrderings(n.plan, nil)
 
@@ -11,6 +11,7 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
@@ -117,6 +118,58 @@ func planToTree(ctx context.Context, top *planTop) *roachpb.ExplainTreePlanNode
 	return nodeStack.peek()
 }
 
+// planGist returns a compact, single-line representation of the shape of
+// the plan, made of the names of its nodes and the tables and indexes that
+// it scans, e.g.
+//
+//   sort(render(scan[orders@primary]))
+//
+// Like planToTree, it doesn't include the constants of the query, so that
+// it can be logged along with a statement without revealing its data. An
+// empty string is returned if the plan cannot be walked.
+func planGist(ctx context.Context, top *planTop) string {
+	var buf bytes.Buffer
+	// numChildren holds, for each node on the path from the root to the
+	// current node, the number of children that have been entered so far.
+	var numChildren []int
+	observer := planObserver{
+		followRowSourceToPlanNode: true,
+		enterNode: func(ctx context.Context, nodeName string, plan planNode) (bool, error) {
+			if n := len(numChildren); n > 0 {
+				if numChildren[n-1] == 0 {
+					buf.WriteByte('(')
+				} else {
+					buf.WriteByte(',')
+				}
+				numChildren[n-1]++
+			}
+			buf.WriteString(nodeName)
+			numChildren = append(numChildren, 0)
+			return true, nil
+		},
+		expr: func(_ observeVerbosity, nodeName, fieldName string, n int, expr tree.Expr) {},
+		attr: func(nodeName, fieldName, attr string) {
+			if fieldName == "table" && numChildren[len(numChildren)-1] == 0 {
+				fmt.Fprintf(&buf, "[%s]", attr)
+			}
+		},
+		leaveNode: func(nodeName string, plan planNode) error {
+			if numChildren[len(numChildren)-1] > 0 {
+				buf.WriteByte(')')
+			}
+			numChildren = numChildren[:len(numChildren)-1]
+			return nil
+		},
+	}
+
+	if err := populateEntriesForObserver(
+		ctx, top.plan, top.subqueryPlans, top.postqueryPlans, observer, true /* returnError */, sampledLogicalPlanFmtFlags,
+	); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
 type planNodeStack struct {
 	stack []*roachpb.ExplainTreePlanNode
 }
@@ -33,7 +33,8 @@ func TestPlanToTreeAndPlanToString(t *testing.T) {
 	plansToTest := []*TestData{
 		{
 			// Test span anonymization.
-			SQL: `SELECT oid FROM t.orders WHERE oid = 123`,
+			SQL:              `SELECT oid FROM t.orders WHERE oid = 123`,
+			ExpectedPlanGist: `render(scan[orders@primary])`,
 			// In the string version, the constants are not anonimized.
 			ExpectedPlanString: `0 render  (oid int) oid=CONST; key()
 0 .render 0 (@1)[int] (oid int) oid=CONST; key()
@@ -69,7 +70,8 @@ func TestPlanToTreeAndPlanToString(t *testing.T) {
 		},
 		{
 			// Test select statement 1.
-			SQL: "SELECT CId, Date, Value FROM t.Orders",
+			SQL:              "SELECT CId, Date, Value FROM t.Orders",
+			ExpectedPlanGist: `render(scan[orders@primary])`,
 			ExpectedPlanString: `0 render  (cid int, date date, value decimal) 
 0 .render 0 (@2)[int] (cid int, date date, value decimal) 
 0 .render 1 (@4)[date] (cid int, date date, value decimal) 
@@ -568,6 +570,11 @@ func assertExpectedPlansForTests(t *testing.T, sqlSetup string, plansToTest []*T
 		actualPlanString := planToString(ctx, p.curPlan.plan, p.curPlan.subqueryPlans, p.curPlan.postqueryPlans)
 		assert.Equal(t, test.ExpectedPlanString, actualPlanString,
 			"planToString for %s:\nexpected:%s\nactual:%s", test.SQL, test.ExpectedPlanString, actualPlanString)
+		if test.ExpectedPlanGist != "" {
+			actualPlanGist := planGist(ctx, &p.curPlan)
+			assert.Equal(t, test.ExpectedPlanGist, actualPlanGist,
+				"planGist for %s:\nexpected:%s\nactual:%s", test.SQL, test.ExpectedPlanGist, actualPlanGist)
+		}
 	}
 }
 
@@ -575,4 +582,6 @@ type TestData struct {
 	SQL                string
 	ExpectedPlanString string
 	ExpectedPlanTree   *roachpb.ExplainTreePlanNode
+	// ExpectedPlanGist is only checked if set.
+	ExpectedPlanGist string
 }
@@ -0,0 +1,316 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package filetable implements a simple blob storage on top of SQL tables,
+// so that users without access to a cloud storage bucket can stage files,
+// such as the input of an IMPORT, in the database itself.
+//
+// The files of a FileToTableSystem are stored in two tables: a file table
+// that maps file names to their metadata, and a payload table that holds
+// the contents of the files, split in chunks. Both tables are regular SQL
+// tables created and owned by the user of the FileToTableSystem, so that the
+// usual privileges apply to them.
+package filetable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// ChunkSize is the size of the chunks in which the contents of files are
+// stored in the payload table.
+const ChunkSize = 1 << 20 // 1 MiB
+
+// SQLExecutor executes the statements of a FileToTableSystem.
+type SQLExecutor interface {
+	// Exec executes a statement that does not return rows.
+	Exec(ctx context.Context, query string, args ...interface{}) error
+	// QueryRows executes a query and returns the rows of its result.
+	QueryRows(ctx context.Context, query string, args ...interface{}) ([][]interface{}, error)
+}
+
+// FileInfo describes a file stored in a FileToTableSystem.
+type FileInfo struct {
+	Name       string
+	Size       int64
+	UploadTime time.Time
+}
+
+// FileToTableSystem stores files in SQL tables.
+type FileToTableSystem struct {
+	executor     SQLExecutor
+	username     string
+	fileTable    string
+	payloadTable string
+}
+
+// NewFileToTableSystem returns a FileToTableSystem storing its files in the
+// tables <prefix>_upload_files and <prefix>_upload_payload of the given
+// database and schema, and creates these tables if needed.
+func NewFileToTableSystem(
+	ctx context.Context, executor SQLExecutor, database, schema, prefix, username string,
+) (*FileToTableSystem, error) {
+	qualify := func(suffix string) string {
+		return fmt.Sprintf("%s.%s.%s", tree.NameString(database), tree.NameString(schema),
+			tree.NameString(prefix+suffix))
+	}
+	f := &FileToTableSystem{
+		executor:     executor,
+		username:     username,
+		fileTable:    qualify("_upload_files"),
+		payloadTable: qualify("_upload_payload"),
+	}
+	if err := executor.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  filename    STRING PRIMARY KEY,
+  file_id     UUID UNIQUE NOT NULL,
+  file_size   INT8 NOT NULL,
+  username    STRING NOT NULL,
+  upload_time TIMESTAMP NOT NULL DEFAULT now()
+)`, f.fileTable)); err != nil {
+		return nil, errors.Wrap(err, "creating file table")
+	}
+	if err := executor.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  file_id     UUID,
+  byte_offset INT8,
+  payload     BYTES,
+  PRIMARY KEY (file_id, byte_offset)
+)`, f.payloadTable)); err != nil {
+		return nil, errors.Wrap(err, "creating payload table")
+	}
+	return f, nil
+}
+
+// WriteFile stores the contents read from r under the given file name and
+// returns the size of the file. The file must not exist yet. The file only
+// becomes visible once all its contents were written, so that a failed
+// upload never leaves a partial file behind.
+func (f *FileToTableSystem) WriteFile(
+	ctx context.Context, filename string, r io.Reader,
+) (int64, error) {
+	if filename == "" {
+		return 0, errors.New("file name must not be empty")
+	}
+	if _, err := f.lookup(ctx, filename); err == nil {
+		return 0, errors.Errorf("file %s already exists", filename)
+	} else if !IsNotExist(err) {
+		return 0, err
+	}
+
+	fileID := uuid.MakeV4().String()
+	insertChunk := fmt.Sprintf(`INSERT INTO %s (file_id, byte_offset, payload) VALUES ($1::UUID, $2, $3)`,
+		f.payloadTable)
+	var size int64
+	err := func() error {
+		chunk := make([]byte, ChunkSize)
+		for {
+			n, err := io.ReadFull(r, chunk)
+			if n > 0 {
+				if err := f.executor.Exec(ctx, insertChunk, fileID, size, chunk[:n]); err != nil {
+					return err
+				}
+				size += int64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return errors.Wrap(err, "reading source")
+			}
+		}
+		return f.executor.Exec(ctx, fmt.Sprintf(
+			`INSERT INTO %s (filename, file_id, file_size, username) VALUES ($1, $2::UUID, $3, $4)`,
+			f.fileTable), filename, fileID, size, f.username)
+	}()
+	if err != nil {
+		// Remove the chunks that were written, on a best-effort basis.
+		_ = f.executor.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE file_id = $1::UUID`, f.payloadTable),
+			fileID)
+		return 0, err
+	}
+	return size, nil
+}
+
+// ReadFile returns a reader for the contents of the given file. The contents
+// are fetched one chunk at a time.
+func (f *FileToTableSystem) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	fileID, err := f.lookup(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{ctx: ctx, f: f, fileID: fileID}, nil
+}
+
+// FileSize returns the size of the given file in bytes.
+func (f *FileToTableSystem) FileSize(ctx context.Context, filename string) (int64, error) {
+	rows, err := f.executor.QueryRows(ctx, fmt.Sprintf(
+		`SELECT file_size FROM %s WHERE filename = $1`, f.fileTable), filename)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, &notExistError{filename: filename}
+	}
+	return toInt(rows[0][0])
+}
+
+// ListFiles returns the files whose name matches the given pattern, in the
+// syntax of path.Match, ordered by name. An empty pattern matches all files.
+func (f *FileToTableSystem) ListFiles(ctx context.Context, pattern string) ([]FileInfo, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+	}
+	rows, err := f.executor.QueryRows(ctx, fmt.Sprintf(
+		`SELECT filename, file_size, upload_time FROM %s ORDER BY filename`, f.fileTable))
+	if err != nil {
+		return nil, err
+	}
+	var files []FileInfo
+	for _, row := range rows {
+		name := toString(row[0])
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, name); !ok {
+				continue
+			}
+		}
+		size, err := toInt(row[1])
+		if err != nil {
+			return nil, err
+		}
+		info := FileInfo{Name: name, Size: size}
+		if t, ok := row[2].(time.Time); ok {
+			info.UploadTime = t
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
+
+// DeleteFile removes the given file.
+func (f *FileToTableSystem) DeleteFile(ctx context.Context, filename string) error {
+	fileID, err := f.lookup(ctx, filename)
+	if err != nil {
+		return err
+	}
+	// The file is removed from the file table first, so that it disappears
+	// at once even if removing its contents fails.
+	if err := f.executor.Exec(ctx, fmt.Sprintf(
+		`DELETE FROM %s WHERE filename = $1`, f.fileTable), filename); err != nil {
+		return err
+	}
+	return f.executor.Exec(ctx, fmt.Sprintf(
+		`DELETE FROM %s WHERE file_id = $1::UUID`, f.payloadTable), fileID)
+}
+
+// lookup returns the ID of the given file.
+func (f *FileToTableSystem) lookup(ctx context.Context, filename string) (string, error) {
+	rows, err := f.executor.QueryRows(ctx, fmt.Sprintf(
+		`SELECT file_id::STRING FROM %s WHERE filename = $1`, f.fileTable), filename)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", &notExistError{filename: filename}
+	}
+	return toString(rows[0][0]), nil
+}
+
+// chunkReader reads the contents of a file from the payload table.
+type chunkReader struct {
+	ctx    context.Context
+	f      *FileToTableSystem
+	fileID string
+	// offset is the offset in the file of the next chunk to fetch.
+	offset int64
+	buf    []byte
+	eof    bool
+}
+
+// Read implements io.Reader.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		rows, err := r.f.executor.QueryRows(r.ctx, fmt.Sprintf(
+			`SELECT payload FROM %s WHERE file_id = $1::UUID AND byte_offset = $2`, r.f.payloadTable),
+			r.fileID, r.offset)
+		if err != nil {
+			return 0, err
+		}
+		if len(rows) == 0 {
+			r.eof = true
+			continue
+		}
+		r.buf = toBytes(rows[0][0])
+		r.offset += int64(len(r.buf))
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (r *chunkReader) Close() error {
+	return nil
+}
+
+type notExistError struct {
+	filename string
+}
+
+func (e *notExistError) Error() string {
+	return fmt.Sprintf("file %s does not exist", e.filename)
+}
+
+// IsNotExist returns whether err indicates that a file does not exist.
+func IsNotExist(err error) bool {
+	_, ok := errors.Cause(err).(*notExistError)
+	return ok
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	}
+	return fmt.Sprint(v)
+}
+
+func toBytes(v interface{}) []byte {
+	switch t := v.(type) {
+	case []byte:
+		return t
+	case string:
+		return []byte(t)
+	}
+	return nil
+}
+
+func toInt(v interface{}) (int64, error) {
+	switch t := v.(type) {
+	case int64:
+		return t, nil
+	case []byte, string:
+		var i int64
+		_, err := fmt.Sscan(strings.TrimSpace(toString(t)), &i)
+		return i, err
+	}
+	return 0, errors.Errorf("unexpected value %v of type %T", v, v)
+}
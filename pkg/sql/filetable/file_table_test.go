@@ -0,0 +1,161 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package filetable_test
+
+import (
+	"bytes"
+	"context"
+	gosql "database/sql"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/filetable"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// dbExecutor implements filetable.SQLExecutor on top of a gosql.DB.
+type dbExecutor struct {
+	db *gosql.DB
+}
+
+func (e dbExecutor) Exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := e.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (e dbExecutor) QueryRows(
+	ctx context.Context, query string, args ...interface{},
+) ([][]interface{}, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func TestFileToTableSystem(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	fs, err := filetable.NewFileToTableSystem(
+		ctx, dbExecutor{db}, "defaultdb", "public", "test", "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"empty.csv":   nil,
+		"small.csv":   []byte("a,b\n1,2\n"),
+		"large.csv":   bytes.Repeat([]byte{0, 1, 2, 0xff}, filetable.ChunkSize/2+1),
+		"sub/abc.txt": []byte("abc"),
+	}
+	for name, contents := range files {
+		size, err := fs.WriteFile(ctx, name, bytes.NewReader(contents))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != int64(len(contents)) {
+			t.Fatalf("%s: expected size %d, got %d", name, len(contents), size)
+		}
+	}
+	if _, err := fs.WriteFile(
+		ctx, "small.csv", bytes.NewReader(nil),
+	); !testutils.IsError(err, "already exists") {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	for name, contents := range files {
+		r, err := fs.ReadFile(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, contents) {
+			t.Fatalf("%s: read %d bytes that differ from the %d bytes written",
+				name, len(read), len(contents))
+		}
+		size, err := fs.FileSize(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != int64(len(contents)) {
+			t.Fatalf("%s: expected size %d, got %d", name, len(contents), size)
+		}
+	}
+
+	checkList := func(pattern string, expected ...string) {
+		t.Helper()
+		list, err := fs.ListFiles(ctx, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range list {
+			names = append(names, f.Name)
+		}
+		if len(names) != len(expected) {
+			t.Fatalf("%q: expected %q, got %q", pattern, expected, names)
+		}
+		for i := range names {
+			if names[i] != expected[i] {
+				t.Fatalf("%q: expected %q, got %q", pattern, expected, names)
+			}
+		}
+	}
+	checkList("", "empty.csv", "large.csv", "small.csv", "sub/abc.txt")
+	checkList("*.csv", "empty.csv", "large.csv", "small.csv")
+	checkList("sub/*", "sub/abc.txt")
+
+	if err := fs.DeleteFile(ctx, "large.csv"); err != nil {
+		t.Fatal(err)
+	}
+	checkList("*.csv", "empty.csv", "small.csv")
+	if _, err := fs.ReadFile(ctx, "large.csv"); !filetable.IsNotExist(err) {
+		t.Fatalf("expected file not to exist, got %v", err)
+	}
+	if err := fs.DeleteFile(ctx, "large.csv"); !filetable.IsNotExist(err) {
+		t.Fatalf("expected file not to exist, got %v", err)
+	}
+	var chunks int
+	if err := db.QueryRow(`SELECT count(*) FROM defaultdb.test_upload_payload`).Scan(&chunks); err != nil {
+		t.Fatal(err)
+	}
+	// The remaining files take one chunk each, except for the empty one.
+	if chunks != 2 {
+		t.Fatalf("expected 2 chunks, got %d", chunks)
+	}
+}
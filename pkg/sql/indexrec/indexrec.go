@@ -0,0 +1,414 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package indexrec recommends schema changes from the statement statistics
+// collected by a node. Indexes are recommended for the columns filtered by
+// the full table scans of the sampled plans of frequently executed
+// statements, and redundant indexes, whose columns prefix the columns of
+// another index of the same table, are recommended to be dropped.
+package indexrec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// MinExecutions is the minimum number of executions of a statement
+// fingerprint for its sampled plan to be used to recommend indexes.
+var MinExecutions = settings.RegisterNonNegativeIntSetting(
+	"sql.index_recommendations.min_executions",
+	"the minimum number of executions of a statement fingerprint for its plan to be used to recommend indexes",
+	10,
+)
+
+// Type is the type of a Recommendation.
+type Type string
+
+const (
+	// TypeCreate recommends creating an index.
+	TypeCreate Type = "CREATE"
+	// TypeDrop recommends dropping an index.
+	TypeDrop Type = "DROP"
+)
+
+// Table is a table for which indexes can be recommended.
+type Table struct {
+	// Name is the fully qualified name of the table.
+	Name tree.TableName
+	Desc *sqlbase.TableDescriptor
+}
+
+// Recommendation is a recommended schema change.
+type Recommendation struct {
+	Type Type
+	// Table is the fully qualified name of the table.
+	Table string
+	// Index is the name of the index to drop, for TypeDrop.
+	Index string
+	// Columns are the key columns of the index.
+	Columns []string
+	// SQL is the statement applying the recommendation.
+	SQL    string
+	Reason string
+	// Fingerprints are the statement fingerprints which would benefit from
+	// the index to create, and ExecutionCount their number of executions.
+	Fingerprints   []string
+	ExecutionCount int64
+}
+
+// Recommend returns the recommendations for the given tables, using the
+// sampled plans of the statements executed at least minExecutions times.
+// Index creations are ordered by decreasing execution count, and precede the
+// index drops, which are ordered by table and index.
+func Recommend(
+	stats []roachpb.CollectedStatementStatistics, tables []Table, minExecutions int64,
+) []Recommendation {
+	var recs []Recommendation
+	byKey := make(map[string]int)
+	for i := range stats {
+		s := &stats[i]
+		if s.Stats.Count < minExecutions || s.Key.Failed {
+			continue
+		}
+		for _, scan := range fullScans(&s.Stats.SensitiveInfo.MostRecentPlanDescription, "") {
+			for _, t := range tables {
+				if t.Desc.Name != scan.table {
+					continue
+				}
+				cols := indexColumns(t.Desc, scan.filter)
+				if len(cols) == 0 || hasIndexPrefixedBy(t.Desc, cols) {
+					continue
+				}
+				table := t.Name.String()
+				colNames := make([]string, len(cols))
+				for j, c := range cols {
+					colNames[j] = c.Name
+				}
+				key := table + "\x00" + strings.Join(colNames, "\x00")
+				idx, ok := byKey[key]
+				if !ok {
+					idx = len(recs)
+					byKey[key] = idx
+					quoted := make([]string, len(colNames))
+					for j, c := range colNames {
+						quoted[j] = tree.NameString(c)
+					}
+					recs = append(recs, Recommendation{
+						Type:    TypeCreate,
+						Table:   table,
+						Columns: colNames,
+						SQL:     fmt.Sprintf("CREATE INDEX ON %s (%s)", table, strings.Join(quoted, ", ")),
+						Reason:  "statements filtering on these columns scan the whole table",
+					})
+				}
+				rec := &recs[idx]
+				if !containsString(rec.Fingerprints, s.Key.Query) {
+					rec.Fingerprints = append(rec.Fingerprints, s.Key.Query)
+				}
+				rec.ExecutionCount += s.Stats.Count
+			}
+		}
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].ExecutionCount > recs[j].ExecutionCount
+	})
+
+	sorted := append([]Table(nil), tables...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name.String() < sorted[j].Name.String()
+	})
+	for _, t := range sorted {
+		recs = append(recs, redundantIndexes(t)...)
+	}
+	return recs
+}
+
+// scan is a full table scan of a sampled plan.
+type scan struct {
+	table  string
+	filter string
+}
+
+// fullScans returns the full table scans of the plan, along with their
+// filters. The filter of a scan is either its own, or that of a filter node
+// directly above it.
+func fullScans(node *roachpb.ExplainTreePlanNode, parentFilter string) []scan {
+	var scans []scan
+	switch node.Name {
+	case "scan", "revscan":
+		var table, filter string
+		full := false
+		for _, a := range node.Attrs {
+			switch a.Key {
+			case "table":
+				table = a.Value
+			case "spans":
+				full = a.Value == "ALL"
+			case "filter":
+				filter = a.Value
+			}
+		}
+		if filter == "" {
+			filter = parentFilter
+		}
+		if full && filter != "" {
+			if i := strings.LastIndexByte(table, '@'); i >= 0 {
+				table = table[:i]
+			}
+			scans = append(scans, scan{table: table, filter: filter})
+		}
+		return scans
+	case "filter":
+		for _, a := range node.Attrs {
+			if a.Key == "filter" {
+				parentFilter = a.Value
+			}
+		}
+	default:
+		parentFilter = ""
+	}
+	for _, c := range node.Children {
+		scans = append(scans, fullScans(c, parentFilter)...)
+	}
+	return scans
+}
+
+// indexColumns returns the key columns of an index which would let the given
+// filter be evaluated without a full scan of the table: the columns compared
+// for equality in the conjuncts of the filter, followed by the first column
+// compared with an inequality.
+func indexColumns(desc *sqlbase.TableDescriptor, filter string) []*sqlbase.ColumnDescriptor {
+	expr, err := parser.ParseExpr(filter)
+	if err != nil {
+		return nil
+	}
+	var eq, ineq []*sqlbase.ColumnDescriptor
+	var visit func(tree.Expr)
+	visit = func(e tree.Expr) {
+		switch t := e.(type) {
+		case *tree.AndExpr:
+			visit(t.Left)
+			visit(t.Right)
+		case *tree.ParenExpr:
+			visit(t.Expr)
+		case *tree.ComparisonExpr:
+			col := comparedColumn(desc, t.Left, t.Right)
+			if col == nil {
+				col = comparedColumn(desc, t.Right, t.Left)
+			}
+			if col == nil {
+				return
+			}
+			switch t.Operator {
+			case tree.EQ, tree.In, tree.IsNotDistinctFrom:
+				if !containsColumn(eq, col) {
+					eq = append(eq, col)
+				}
+			case tree.LT, tree.GT, tree.LE, tree.GE:
+				ineq = append(ineq, col)
+			}
+		}
+	}
+	visit(expr)
+	cols := eq
+	for _, col := range ineq {
+		if !containsColumn(cols, col) {
+			cols = append(cols, col)
+			break
+		}
+	}
+	return cols
+}
+
+// comparedColumn returns the indexable column of the table referenced by
+// expr, provided that it is compared to a value which doesn't reference any
+// column. Constants are hidden in sampled plans, and appear as _.
+func comparedColumn(
+	desc *sqlbase.TableDescriptor, expr, other tree.Expr,
+) *sqlbase.ColumnDescriptor {
+	name, ok := expr.(*tree.UnresolvedName)
+	if !ok || name.Star || referencesColumn(other) {
+		return nil
+	}
+	col, dropped, err := desc.FindColumnByName(tree.Name(name.Parts[0]))
+	if err != nil || dropped || sqlbase.MustBeValueEncoded(col.Type.Family()) {
+		return nil
+	}
+	return col
+}
+
+// referencesColumn returns whether the expression references a column,
+// ignoring the hidden constants of sampled plans.
+func referencesColumn(expr tree.Expr) bool {
+	switch t := expr.(type) {
+	case *tree.UnresolvedName:
+		return t.Star || t.Parts[0] != "_"
+	case *tree.Tuple:
+		for _, e := range t.Exprs {
+			if referencesColumn(e) {
+				return true
+			}
+		}
+		return false
+	case *tree.ParenExpr:
+		return referencesColumn(t.Expr)
+	case *tree.CastExpr:
+		return referencesColumn(t.Expr)
+	case *tree.Placeholder, tree.Datum, tree.Constant:
+		return false
+	}
+	return true
+}
+
+// hasIndexPrefixedBy returns whether the table has an index whose key columns
+// start with the given columns.
+func hasIndexPrefixedBy(desc *sqlbase.TableDescriptor, cols []*sqlbase.ColumnDescriptor) bool {
+	for _, idx := range desc.AllNonDropIndexes() {
+		if len(idx.ColumnIDs) < len(cols) {
+			continue
+		}
+		prefixed := true
+		for i, col := range cols {
+			if idx.ColumnIDs[i] != col.ID {
+				prefixed = false
+				break
+			}
+		}
+		if prefixed {
+			return true
+		}
+	}
+	return false
+}
+
+// redundantIndexes returns the recommendations to drop the non-unique
+// secondary indexes of the table whose key columns prefix the key columns of
+// another index which stores at least the same columns. Of two identical
+// indexes, the most recent one is recommended to be dropped. Interleaved
+// indexes and the indexes which may back a foreign key are left alone.
+func redundantIndexes(t Table) []Recommendation {
+	desc := t.Desc
+	var recs []Recommendation
+	for i := range desc.Indexes {
+		idx := &desc.Indexes[i]
+		if idx.Unique || idx.Type != sqlbase.IndexDescriptor_FORWARD ||
+			len(idx.Interleave.Ancestors) > 0 || len(idx.InterleavedBy) > 0 ||
+			mayBackForeignKey(desc, idx) {
+			continue
+		}
+		for _, other := range desc.AllNonDropIndexes() {
+			if other.ID == idx.ID || other.Type != sqlbase.IndexDescriptor_FORWARD ||
+				!isKeyPrefix(idx, other) || !stores(desc, other, idx.StoreColumnIDs) {
+				continue
+			}
+			if len(other.ColumnIDs) == len(idx.ColumnIDs) && !other.Unique &&
+				other.ID != desc.PrimaryIndex.ID && other.ID > idx.ID {
+				// Identical indexes: keep the oldest one.
+				continue
+			}
+			cols := make([]string, len(idx.ColumnNames))
+			copy(cols, idx.ColumnNames)
+			recs = append(recs, Recommendation{
+				Type:    TypeDrop,
+				Table:   t.Name.String(),
+				Index:   idx.Name,
+				Columns: cols,
+				SQL: fmt.Sprintf("DROP INDEX %s@%s",
+					t.Name.String(), tree.NameString(idx.Name)),
+				Reason: fmt.Sprintf("the index is redundant with index %s", other.Name),
+			})
+			break
+		}
+	}
+	return recs
+}
+
+// isKeyPrefix returns whether the key columns of idx, along with their
+// directions, prefix the key columns of other.
+func isKeyPrefix(idx, other *sqlbase.IndexDescriptor) bool {
+	if len(idx.ColumnIDs) > len(other.ColumnIDs) {
+		return false
+	}
+	for i := range idx.ColumnIDs {
+		if idx.ColumnIDs[i] != other.ColumnIDs[i] ||
+			idx.ColumnDirections[i] != other.ColumnDirections[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stores returns whether the index stores the given columns.
+func stores(
+	desc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor, cols []sqlbase.ColumnID,
+) bool {
+	if idx.ID == desc.PrimaryIndex.ID {
+		return true
+	}
+	for _, c := range cols {
+		if !idx.ContainsColumnID(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayBackForeignKey returns whether the key columns of the index start with
+// the columns of one of the foreign keys of the table, or of one of the
+// foreign keys referencing the table.
+func mayBackForeignKey(desc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor) bool {
+	startsWith := func(cols []sqlbase.ColumnID) bool {
+		if len(cols) > len(idx.ColumnIDs) {
+			return false
+		}
+		for i, c := range cols {
+			if idx.ColumnIDs[i] != c {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range desc.OutboundFKs {
+		if startsWith(desc.OutboundFKs[i].OriginColumnIDs) {
+			return true
+		}
+	}
+	for i := range desc.InboundFKs {
+		if startsWith(desc.InboundFKs[i].ReferencedColumnIDs) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsColumn(cols []*sqlbase.ColumnDescriptor, col *sqlbase.ColumnDescriptor) bool {
+	for _, c := range cols {
+		if c.ID == col.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
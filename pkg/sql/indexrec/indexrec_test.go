@@ -0,0 +1,97 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package indexrec_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/indexrec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// fullScanStats returns the statistics of a statement whose sampled plan
+// filters a full scan of t.
+func fullScanStats(query, filter string, count int64) roachpb.CollectedStatementStatistics {
+	var s roachpb.CollectedStatementStatistics
+	s.Key.Query = query
+	s.Stats.Count = count
+	s.Stats.SensitiveInfo.MostRecentPlanDescription = roachpb.ExplainTreePlanNode{
+		Name: "render",
+		Children: []*roachpb.ExplainTreePlanNode{{
+			Name:  "filter",
+			Attrs: []*roachpb.ExplainTreePlanNode_Attr{{Key: "filter", Value: filter}},
+			Children: []*roachpb.ExplainTreePlanNode{{
+				Name: "scan",
+				Attrs: []*roachpb.ExplainTreePlanNode_Attr{
+					{Key: "table", Value: "t@primary"},
+					{Key: "spans", Value: "ALL"},
+				},
+			}},
+		}},
+	}
+	return s
+}
+
+func TestRecommend(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	desc, err := sql.CreateTestTableDescriptor(context.TODO(), 1, 53,
+		`CREATE TABLE t (a INT PRIMARY KEY, b INT, c INT, d STRING, INDEX c_idx (c), INDEX c_b_idx (c, b))`,
+		sqlbase.NewDefaultPrivilegeDescriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []indexrec.Table{{
+		Name: tree.MakeTableNameWithSchema("db", "public", "t"),
+		Desc: &desc,
+	}}
+	stats := []roachpb.CollectedStatementStatistics{
+		fullScanStats("SELECT * FROM t WHERE (b = _) AND (d > _)", "(b = _) AND (d > _)", 20),
+		fullScanStats("SELECT * FROM t WHERE (d > $1) AND (b IN (_, _))", "(d > $1) AND (b IN (_, _))", 5),
+		// Indexed by c_idx.
+		fullScanStats("SELECT * FROM t WHERE c = _", "c = _", 20),
+		// Not executed often enough.
+		fullScanStats("SELECT * FROM t WHERE d = _", "d = _", 1),
+		// Compares two columns.
+		fullScanStats("SELECT * FROM t WHERE b = d::INT", "b = d::INT", 20),
+	}
+
+	expected := []indexrec.Recommendation{
+		{
+			Type:    indexrec.TypeCreate,
+			Table:   "db.public.t",
+			Columns: []string{"b", "d"},
+			SQL:     "CREATE INDEX ON db.public.t (b, d)",
+			Reason:  "statements filtering on these columns scan the whole table",
+			Fingerprints: []string{
+				"SELECT * FROM t WHERE (b = _) AND (d > _)",
+				"SELECT * FROM t WHERE (d > $1) AND (b IN (_, _))",
+			},
+			ExecutionCount: 25,
+		},
+		{
+			Type:    indexrec.TypeDrop,
+			Table:   "db.public.t",
+			Index:   "c_idx",
+			Columns: []string{"c"},
+			SQL:     "DROP INDEX db.public.t@c_idx",
+			Reason:  "the index is redundant with index c_b_idx",
+		},
+	}
+	if recs := indexrec.Recommend(stats, tables, 2 /* minExecutions */); !reflect.DeepEqual(recs, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, recs)
+	}
+}
@@ -187,6 +187,7 @@ func init() {
 		&tree.Backup{},
 		&tree.ShowBackup{},
 		&tree.Restore{},
+		&tree.ScheduledBackup{},
 		&tree.CreateChangefeed{},
 		&tree.CreateRole{},
 		&tree.DropRole{},
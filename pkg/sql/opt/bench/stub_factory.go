@@ -333,6 +333,12 @@ func (f *stubFactory) ConstructControlJobs(
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructControlSchedules(
+	command tree.ScheduleCommand, input exec.Node,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) ConstructCancelQueries(input exec.Node, ifExists bool) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -250,6 +250,22 @@ func (b *Builder) buildControlJobs(ctl *memo.ControlJobsExpr) (execPlan, error)
 	return execPlan{root: node}, nil
 }
 
+func (b *Builder) buildControlSchedules(ctl *memo.ControlSchedulesExpr) (execPlan, error) {
+	input, err := b.buildRelational(ctl.Input)
+	if err != nil {
+		return execPlan{}, err
+	}
+	node, err := b.factory.ConstructControlSchedules(
+		ctl.Command,
+		input.root,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	// ControlSchedules returns no columns.
+	return execPlan{root: node}, nil
+}
+
 func (b *Builder) buildCancelQueries(cancel *memo.CancelQueriesExpr) (execPlan, error) {
 	input, err := b.buildRelational(cancel.Input)
 	if err != nil {
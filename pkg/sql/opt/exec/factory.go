@@ -455,6 +455,10 @@ type Factory interface {
 	// JOBS.
 	ConstructControlJobs(command tree.JobCommand, input Node) (Node, error)
 
+	// ConstructControlSchedules creates a node that implements
+	// PAUSE/RESUME/DROP SCHEDULES.
+	ConstructControlSchedules(command tree.ScheduleCommand, input Node) (Node, error)
+
 	// ConstructCancelQueries creates a node that implements CANCEL QUERIES.
 	ConstructCancelQueries(input Node, ifExists bool) (Node, error)
 
@@ -195,8 +195,8 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		*InsertExpr, *UpdateExpr, *UpsertExpr, *DeleteExpr, *SequenceSelectExpr,
 		*WindowExpr, *OpaqueRelExpr, *OpaqueMutationExpr, *OpaqueDDLExpr,
 		*AlterTableSplitExpr, *AlterTableUnsplitExpr, *AlterTableUnsplitAllExpr,
-		*AlterTableRelocateExpr, *ControlJobsExpr, *CancelQueriesExpr,
-		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr:
+		*AlterTableRelocateExpr, *ControlJobsExpr, *ControlSchedulesExpr,
+		*CancelQueriesExpr, *CancelSessionsExpr, *CreateViewExpr, *ExportExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -1064,6 +1064,9 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 	case *ControlJobsPrivate:
 		fmt.Fprintf(f.Buffer, " (%s)", tree.JobCommandToStatement[t.Command])
 
+	case *ControlSchedulesPrivate:
+		fmt.Fprintf(f.Buffer, " (%s)", tree.ScheduleCommandToStatement[t.Command])
+
 	case *CancelPrivate:
 		if t.IfExists {
 			f.Buffer.WriteString(" [if-exists]")
@@ -498,6 +498,10 @@ func (h *hasher) HashJobCommand(val tree.JobCommand) {
 	h.HashInt(int(val))
 }
 
+func (h *hasher) HashScheduleCommand(val tree.ScheduleCommand) {
+	h.HashInt(int(val))
+}
+
 func (h *hasher) HashIndexOrdinal(val cat.IndexOrdinal) {
 	h.HashInt(val)
 }
@@ -812,6 +816,10 @@ func (h *hasher) IsJobCommandEqual(l, r tree.JobCommand) bool {
 	return l == r
 }
 
+func (h *hasher) IsScheduleCommandEqual(l, r tree.ScheduleCommand) bool {
+	return l == r
+}
+
 func (h *hasher) IsIndexOrdinalEqual(l, r cat.IndexOrdinal) bool {
 	return l == r
 }
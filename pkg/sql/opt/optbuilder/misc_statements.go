@@ -43,6 +43,33 @@ func (b *Builder) buildControlJobs(n *tree.ControlJobs, inScope *scope) (outScop
 	return outScope
 }
 
+func (b *Builder) buildControlSchedules(
+	n *tree.ControlSchedules, inScope *scope,
+) (outScope *scope) {
+	// We don't allow the input statement to reference outer columns, so we
+	// pass a "blank" scope rather than inScope.
+	emptyScope := &scope{builder: b}
+	colTypes := []*types.T{types.Int}
+	inputScope := b.buildStmt(n.Schedules, colTypes, emptyScope)
+
+	checkInputColumns(
+		fmt.Sprintf("%s SCHEDULES", tree.ScheduleCommandToStatement[n.Command]),
+		inputScope,
+		[]string{"schedule_id"},
+		colTypes,
+		1, /* minPrefix */
+	)
+	outScope = inScope.push()
+	outScope.expr = b.factory.ConstructControlSchedules(
+		inputScope.expr.(memo.RelExpr),
+		&memo.ControlSchedulesPrivate{
+			Props:   inputScope.makePhysicalProps(),
+			Command: n.Command,
+		},
+	)
+	return outScope
+}
+
 func (b *Builder) buildCancelQueries(n *tree.CancelQueries, inScope *scope) (outScope *scope) {
 	// We don't allow the input statement to reference outer columns, so we
 	// pass a "blank" scope rather than inScope.
@@ -355,6 +355,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *controlSchedulesNode:
+		if n.rows, err = p.triggerFilterPropagation(ctx, n.rows); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *projectSetNode:
 		// TODO(knz): we can propagate the part of the filter that applies
 		// to the source columns.
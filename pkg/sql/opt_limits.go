@@ -195,6 +195,9 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *controlJobsNode:
 		p.setUnlimited(n.rows)
 
+	case *controlSchedulesNode:
+		p.setUnlimited(n.rows)
+
 	case *errorIfRowsNode:
 		p.setUnlimited(n.plan)
 
@@ -253,6 +253,9 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *controlJobsNode:
 		setNeededColumns(n.rows, allColumns(n.rows))
 
+	case *controlSchedulesNode:
+		setNeededColumns(n.rows, allColumns(n.rows))
+
 	case *errorIfRowsNode:
 		setNeededColumns(n.plan, allColumns(n.plan))
 
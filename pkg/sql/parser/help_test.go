@@ -111,6 +111,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
 
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE FOR BACKUP`},
+		{`CREATE SCHEDULE FOR BACKUP foo INTO 'bar' RECURRING '@daily' ??`, `CREATE SCHEDULE FOR BACKUP`},
+
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x, y) AS ??`, `CREATE TABLE`},
@@ -139,6 +142,10 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP ROLE IF ??`, `DROP ROLE`},
 		{`DROP ROLE IF EXISTS bluh ??`, `DROP ROLE`},
 
+		{`DROP SCHEDULE ??`, `DROP SCHEDULES`},
+		{`DROP SCHEDULES ??`, `DROP SCHEDULES`},
+		{`DROP SCHEDULE 123 ??`, `DROP SCHEDULES`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
@@ -204,8 +211,14 @@ func TestContextualHelp(t *testing.T) {
 		{`GRANT ALL ON foo TO bar ??`, `GRANT`},
 
 		{`PAUSE ??`, `PAUSE JOBS`},
+		{`PAUSE SCHEDULE ??`, `PAUSE SCHEDULES`},
+		{`PAUSE SCHEDULES ??`, `PAUSE SCHEDULES`},
+		{`PAUSE SCHEDULE 123 ??`, `PAUSE SCHEDULES`},
 
 		{`RESUME ??`, `RESUME JOBS`},
+		{`RESUME SCHEDULE ??`, `RESUME SCHEDULES`},
+		{`RESUME SCHEDULES ??`, `RESUME SCHEDULES`},
+		{`RESUME SCHEDULE 123 ??`, `RESUME SCHEDULES`},
 
 		{`REVOKE ALL ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM ??`, `REVOKE`},
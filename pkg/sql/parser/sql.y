@@ -425,6 +425,9 @@ func (u *sqlSymUnion) kvOptions() []tree.KVOption {
     }
     return nil
 }
+func (u *sqlSymUnion) fullBackupClause() *tree.FullBackupClause {
+    return u.val.(*tree.FullBackupClause)
+}
 func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
     return u.val.(tree.TransactionModes)
 }
@@ -481,7 +484,7 @@ func newNameFromStr(s string) *tree.Name {
 
 // Ordinary key words in alphabetical order.
 %token <str> ABORT ACTION ADD ADMIN AGGREGATE
-%token <str> ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
+%token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT AUTOMATIC
 
 %token <str> BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
@@ -542,13 +545,13 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
 %token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
@@ -671,6 +674,9 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 
+%type <tree.Statement> create_schedule_for_backup_stmt
+%type <*tree.FullBackupClause> opt_full_backup_clause
+
 %type <tree.Statement> create_stats_stmt
 %type <*tree.CreateStatsOptions> opt_create_stats_options
 %type <*tree.CreateStatsOptions> create_stats_option_list
@@ -685,6 +691,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
+%type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
@@ -701,9 +708,13 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt
+%type <tree.Statement> pause_jobs_stmt
+%type <tree.Statement> pause_schedules_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
+%type <tree.Statement> resume_jobs_stmt
+%type <tree.Statement> resume_schedules_stmt
 %type <tree.Statement> restore_stmt
 %type <tree.PartitionedBackup> partitioned_backup
 %type <[]tree.PartitionedBackup> partitioned_backup_list
@@ -767,6 +778,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.Select> select_no_parens
 %type <tree.SelectStatement> select_clause select_with_parens simple_select values_clause table_clause simple_select_clause
 %type <tree.SelectStatement> set_operation
+%type <tree.SelectStatement> changefeed_select
 
 %type <tree.Expr> alter_column_default
 %type <tree.Direction> opt_asc_desc
@@ -916,6 +928,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UpdateExpr> single_set_clause
 %type <tree.AsOfClause> as_of_clause opt_as_of_clause
 %type <tree.Expr> opt_changefeed_sink
+%type <tree.Expr> opt_description
 
 %type <str> explain_option_name
 %type <[]string> explain_option_list
@@ -945,6 +958,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <str> non_reserved_word_or_sconst
 %type <tree.Expr> zone_value
 %type <tree.Expr> string_or_placeholder
+%type <tree.Expr> sconst_or_placeholder
 %type <tree.Expr> string_or_placeholder_list
 
 %type <str> unreserved_keyword type_func_name_keyword cockroachdb_extra_type_func_name_keyword
@@ -1720,7 +1734,7 @@ opt_validate_behavior:
 // %Help: BACKUP - back up data to external storage
 // %Category: CCL
 // %Text:
-// BACKUP <targets...> TO <location...>
+// BACKUP [<targets...>] TO <location...>
 //        [ AS OF SYSTEM TIME <expr> ]
 //        [ INCREMENTAL FROM <location...> ]
 //        [ WITH <option> [= <value>] [, ...] ]
@@ -1728,13 +1742,18 @@ opt_validate_behavior:
 // Targets:
 //    TABLE <pattern> [, ...]
 //    DATABASE <databasename> [, ...]
+//    Without targets, the whole cluster is backed up, including the
+//    users, roles, zone configurations, settings and jobs.
 //
 // Location:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
 //
 // Options:
-//    INTO_DB
-//    SKIP_MISSING_FOREIGN_KEYS
+//    REVISION_HISTORY
+//    ENCRYPTION_PASSPHRASE = <passphrase>
+//    KMS = '<kms uri> [...]'
+//    COMPRESSION = 'snappy' | 'none'
+//    TARGET_FILE_SIZE = '<size>'
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
@@ -1742,25 +1761,96 @@ backup_stmt:
   {
     $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.partitionedBackup(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
   }
+| BACKUP TO partitioned_backup opt_as_of_clause opt_incremental opt_with_options
+  {
+    $$.val = &tree.Backup{DescriptorCoverage: tree.AllDescriptors, To: $3.partitionedBackup(), IncrementalFrom: $5.exprs(), AsOf: $4.asOfClause(), Options: $6.kvOptions()}
+  }
 | BACKUP error // SHOW HELP: BACKUP
 
+// %Help: CREATE SCHEDULE FOR BACKUP - back up data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<description>]
+// FOR BACKUP <targets...> INTO <location>
+// [ WITH <option> [= <value>] [, ...] ]
+// RECURRING <crontab>
+// [ FULL BACKUP <crontab> | FULL BACKUP ALWAYS ]
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//    DATABASE <databasename> [, ...]
+//
+// Location:
+//    "[scheme]://[host]/[path to backups]?[parameters]"
+//
+// Crontab:
+//    "<minute> <hour> <day of month> <month> <day of week>"
+//    @hourly, @daily, @weekly, @monthly, @yearly
+//
+// Incremental backups are taken on the recurring schedule, on top of full
+// backups taken on the FULL BACKUP schedule. By default, full backups are
+// taken daily for schedules which recur at least hourly, weekly for
+// schedules which recur at least daily, and always otherwise.
+//
+// %SeeAlso: BACKUP, PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
+create_schedule_for_backup_stmt:
+  CREATE SCHEDULE opt_description FOR BACKUP targets INTO string_or_placeholder opt_with_options RECURRING sconst_or_placeholder opt_full_backup_clause
+  {
+    $$.val = &tree.ScheduledBackup{
+      ScheduleLabel: $3.expr(),
+      Targets:       $6.targetList(),
+      To:            $8.expr(),
+      BackupOptions: $9.kvOptions(),
+      Recurrence:    $11.expr(),
+      FullBackup:    $12.fullBackupClause(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE FOR BACKUP
+
+opt_description:
+  string_or_placeholder
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+opt_full_backup_clause:
+  FULL BACKUP sconst_or_placeholder
+  {
+    $$.val = &tree.FullBackupClause{Recurrence: $3.expr()}
+  }
+| FULL BACKUP ALWAYS
+  {
+    $$.val = &tree.FullBackupClause{AlwaysFull: true}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.FullBackupClause)(nil)
+  }
+
 // %Help: RESTORE - restore data from external storage
 // %Category: CCL
 // %Text:
-// RESTORE <targets...> FROM <location...>
+// RESTORE [<targets...>] FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
 //    DATABASE <databasename> [, ...]
+//    Without targets, a full cluster backup is restored into a new cluster.
 //
 // Locations:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
 //
 // Options:
-//    INTO_DB
+//    INTO_DB = <database>
+//    NEW_DB_NAME = <database>
 //    SKIP_MISSING_FOREIGN_KEYS
+//    SKIP_MISSING_SEQUENCES
+//    SKIP_MISSING_VIEWS
+//    ENCRYPTION_PASSPHRASE = <passphrase>
+//    KMS = '<kms uri> [...]'
 //
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
@@ -1772,6 +1862,14 @@ restore_stmt:
   {
     $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.partitionedBackups(), AsOf: $5.asOfClause(), Options: $6.kvOptions()}
   }
+| RESTORE FROM partitioned_backup_list opt_with_options
+  {
+    $$.val = &tree.Restore{DescriptorCoverage: tree.AllDescriptors, From: $3.partitionedBackups(), Options: $4.kvOptions()}
+  }
+| RESTORE FROM partitioned_backup_list as_of_clause opt_with_options
+  {
+    $$.val = &tree.Restore{DescriptorCoverage: tree.AllDescriptors, From: $3.partitionedBackups(), AsOf: $4.asOfClause(), Options: $5.kvOptions()}
+  }
 | RESTORE error // SHOW HELP: RESTORE
 
 partitioned_backup:
@@ -1821,6 +1919,7 @@ import_format:
 //    MYSQLDUMP
 //    PGCOPY
 //    PGDUMP
+//    AVRO
 //
 // Options:
 //    distributed = '...'
@@ -1829,6 +1928,11 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    max_row_errors = '...' [CSV-specific]
+//    save_rejected          [CSV-specific]
+//    data_as_binary_records [AVRO-specific]
+//    schema = '...'         [AVRO-specific]
+//    strict_validation      [AVRO-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -1905,6 +2009,18 @@ string_or_placeholder:
     $$.val = p
   }
 
+sconst_or_placeholder:
+  SCONST
+  {
+    $$.val = tree.NewStrVal($1)
+  }
+| PLACEHOLDER
+  {
+    p := $1.placeholder()
+    sqllex.(*lexer).UpdateNumPlaceholders(p)
+    $$.val = p
+  }
+
 string_or_placeholder_list:
   string_or_placeholder
   {
@@ -1968,13 +2084,14 @@ opt_with_options:
   }
 
 copy_from_stmt:
-  COPY table_name opt_column_list FROM STDIN
+  COPY table_name opt_column_list FROM STDIN opt_with_options
   {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.CopyFrom{
        Table: name,
        Columns: $3.nameList(),
        Stdin: true,
+       Options: $6.kvOptions(),
     }
   }
 
@@ -2134,6 +2251,7 @@ create_stmt:
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_for_backup_stmt // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2310,6 +2428,16 @@ create_changefeed_stmt:
       Options: $6.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS changefeed_select
+  {
+    sel := $6.selectStmt().(*tree.SelectClause)
+    $$.val = &tree.CreateChangefeed{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{sel.From.Tables[0].(*tree.TableName)}},
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select:  sel,
+    }
+  }
 | EXPERIMENTAL CHANGEFEED FOR changefeed_targets opt_with_options
   {
     /* SKIP DOC */
@@ -2318,6 +2446,16 @@ create_changefeed_stmt:
       Options: $5.kvOptions(),
     }
   }
+| EXPERIMENTAL CHANGEFEED opt_with_options AS changefeed_select
+  {
+    /* SKIP DOC */
+    sel := $5.selectStmt().(*tree.SelectClause)
+    $$.val = &tree.CreateChangefeed{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{sel.From.Tables[0].(*tree.TableName)}},
+      Options: $3.kvOptions(),
+      Select:  sel,
+    }
+  }
 
 changefeed_targets:
   single_table_pattern_list
@@ -2340,6 +2478,17 @@ single_table_pattern_list:
   }
 
 
+changefeed_select:
+  SELECT target_list FROM table_name opt_where_clause
+  {
+    name := $4.unresolvedObjectName().ToTableName()
+    $$.val = &tree.SelectClause{
+      Exprs: $2.selExprs(),
+      From:  tree.From{Tables: tree.TableExprs{&name}},
+      Where: tree.NewWhere(tree.AstWhere, $5.expr()),
+    }
+  }
+
 opt_changefeed_sink:
   INTO string_or_placeholder
   {
@@ -2395,6 +2544,7 @@ drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULES
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
 
@@ -2405,6 +2555,29 @@ drop_ddl_stmt:
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 
+// %Help: DROP SCHEDULES - remove backup schedules
+// %Category: Misc
+// %Text:
+// DROP SCHEDULES <selectclause>
+// DROP SCHEDULE <scheduleid>
+// %SeeAlso: CREATE SCHEDULE FOR BACKUP, PAUSE SCHEDULES, RESUME SCHEDULES
+drop_schedule_stmt:
+  DROP SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.DropSchedule,
+    }
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULES
+| DROP SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.DropSchedule}
+  }
+| DROP SCHEDULES error // SHOW HELP: DROP SCHEDULES
+
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
 // %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -2588,10 +2761,10 @@ preparable_stmt:
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
-| pause_stmt        // EXTEND WITH HELP: PAUSE JOBS
+| pause_stmt        // help texts in sub-rule
 | reset_stmt        // help texts in sub-rule
 | restore_stmt      // EXTEND WITH HELP: RESTORE
-| resume_stmt       // EXTEND WITH HELP: RESUME JOBS
+| resume_stmt       // help texts in sub-rule
 | export_stmt       // EXTEND WITH HELP: EXPORT
 | scrub_stmt        // help texts in sub-rule
 | select_stmt       // help texts in sub-rule
@@ -3318,38 +3491,49 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location> [WITH <option> [= <value>] [, ...]]
+//
+// Options:
+//    CHECK_FILES
+//    ENCRYPTION_PASSPHRASE = <passphrase>
+//    KMS = '<kms uri> [...]'
+//
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
-  SHOW BACKUP string_or_placeholder
+  SHOW BACKUP string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
       Path:    $3.expr(),
+      Options: $4.kvOptions(),
     }
   }
-| SHOW BACKUP SCHEMAS string_or_placeholder
+| SHOW BACKUP SCHEMAS string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
       ShouldIncludeSchemas: true,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP RANGES string_or_placeholder
+| SHOW BACKUP RANGES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupRangeDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP FILES string_or_placeholder
+| SHOW BACKUP FILES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupFileDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
@@ -4018,7 +4202,7 @@ for_grantee_clause:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
-pause_stmt:
+pause_jobs_stmt:
   PAUSE JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -4032,7 +4216,34 @@ pause_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
-| PAUSE error // SHOW HELP: PAUSE JOBS
+
+pause_stmt:
+  pause_jobs_stmt      // EXTEND WITH HELP: PAUSE JOBS
+| pause_schedules_stmt // EXTEND WITH HELP: PAUSE SCHEDULES
+| PAUSE error          // SHOW HELP: PAUSE JOBS
+
+// %Help: PAUSE SCHEDULES - pause backup schedules
+// %Category: Misc
+// %Text:
+// PAUSE SCHEDULES <selectclause>
+// PAUSE SCHEDULE <scheduleid>
+// %SeeAlso: RESUME SCHEDULES, DROP SCHEDULES, CREATE SCHEDULE FOR BACKUP
+pause_schedules_stmt:
+  PAUSE SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.PauseSchedule,
+    }
+  }
+| PAUSE SCHEDULE error // SHOW HELP: PAUSE SCHEDULES
+| PAUSE SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.PauseSchedule}
+  }
+| PAUSE SCHEDULES error // SHOW HELP: PAUSE SCHEDULES
 
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
@@ -5150,7 +5361,7 @@ release_stmt:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
-resume_stmt:
+resume_jobs_stmt:
   RESUME JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -5164,7 +5375,34 @@ resume_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
-| RESUME error // SHOW HELP: RESUME JOBS
+
+resume_stmt:
+  resume_jobs_stmt      // EXTEND WITH HELP: RESUME JOBS
+| resume_schedules_stmt // EXTEND WITH HELP: RESUME SCHEDULES
+| RESUME error          // SHOW HELP: RESUME JOBS
+
+// %Help: RESUME SCHEDULES - resume paused backup schedules
+// %Category: Misc
+// %Text:
+// RESUME SCHEDULES <selectclause>
+// RESUME SCHEDULE <scheduleid>
+// %SeeAlso: PAUSE SCHEDULES, DROP SCHEDULES, CREATE SCHEDULE FOR BACKUP
+resume_schedules_stmt:
+  RESUME SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.ResumeSchedule,
+    }
+  }
+| RESUME SCHEDULE error // SHOW HELP: RESUME SCHEDULES
+| RESUME SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.ResumeSchedule}
+  }
+| RESUME SCHEDULES error // SHOW HELP: RESUME SCHEDULES
 
 // %Help: SAVEPOINT - start a retryable block
 // %Category: Txn
@@ -9083,6 +9321,7 @@ unreserved_keyword:
 | ADMIN
 | AGGREGATE
 | ALTER
+| ALWAYS
 | AT
 | AUTOMATIC
 | BACKUP
@@ -9232,6 +9471,7 @@ unreserved_keyword:
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
@@ -9259,6 +9499,8 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
 | SCHEMA
 | SCHEMAS
 | SCRUB
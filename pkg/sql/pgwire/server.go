@@ -285,6 +285,14 @@ func (s *Server) IsDraining() bool {
 	return s.mu.draining
 }
 
+// NumOpenConns returns the number of open connections which were started
+// before the server started draining.
+func (s *Server) NumOpenConns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.mu.connCancelMap)
+}
+
 // Metrics returns the set of metrics structs.
 func (s *Server) Metrics() (res []interface{}) {
 	return []interface{}{
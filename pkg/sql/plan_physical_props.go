@@ -106,6 +106,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *commentOnColumnNode:
 	case *commentOnDatabaseNode:
 	case *controlJobsNode:
+	case *controlSchedulesNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
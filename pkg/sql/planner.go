@@ -141,6 +141,12 @@ type planner struct {
 	// See EXECUTE .. DISCARD ROWS.
 	discardRows bool
 
+	// collectStmtDiagnostics is set if diagnostics are being collected for the
+	// current statement, in which case its plan is recorded in
+	// stmtDiagnosticsPlan.
+	collectStmtDiagnostics bool
+	stmtDiagnosticsPlan    string
+
 	// cancelChecker is used by planNodes to check for cancellation of the associated
 	// query.
 	cancelChecker *sqlbase.CancelChecker
@@ -615,7 +621,7 @@ type sqlStatsCollector interface {
 		numRows int,
 		err error,
 		parseLat, planLat, runLat, svcLat, ovhLat float64,
-		bytesRead, rowsRead int64,
+		queryStats *topLevelQueryStats,
 	)
 
 	// SQLStats provides access to the global sqlStats object.
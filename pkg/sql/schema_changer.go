@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -1342,8 +1343,12 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 		}
 
 		schemaChangeEventType := EventLogFinishSchemaChange
+		var info eventpb.EventPayload = &eventpb.FinishSchemaChange{
+			MutationID: uint32(sc.mutationID),
+		}
 		if isRollback {
 			schemaChangeEventType = EventLogFinishSchemaRollback
+			info = &eventpb.FinishSchemaChangeRollback{MutationID: uint32(sc.mutationID)}
 		}
 
 		// Log "Finish Schema Change" or "Finish Schema Change Rollback"
@@ -1356,9 +1361,7 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 			schemaChangeEventType,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				MutationID uint32
-			}{uint32(sc.mutationID)},
+			info,
 		)
 	})
 	if err != nil {
@@ -1566,10 +1569,10 @@ func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError erro
 			EventLogReverseSchemaChange,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				Error      string
-				MutationID uint32
-			}{fmt.Sprintf("%+v", causingError), uint32(sc.mutationID)},
+			&eventpb.ReverseSchemaChange{
+				Error:      fmt.Sprintf("%+v", causingError),
+				MutationID: uint32(sc.mutationID),
+			},
 		)
 	})
 	if err != nil {
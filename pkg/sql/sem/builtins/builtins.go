@@ -16,6 +16,7 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	gojson "encoding/json"
 	"fmt"
 	"hash"
 	"hash/crc32"
@@ -3034,6 +3035,222 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.recompute_range_stats": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			Impure:   true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"start_key", types.Bytes}, {"dry_run", types.Bool}},
+			ReturnType: tree.FixedReturnType(types.Jsonb),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				b := &client.Batch{}
+				b.AddRawRequest(&roachpb.RecomputeStatsRequest{
+					RequestHeader: roachpb.RequestHeader{
+						Key: []byte(tree.MustBeDBytes(args[0])),
+					},
+					DryRun: bool(tree.MustBeDBool(args[1])),
+				})
+				// RecomputeStats is a non-transactional request.
+				if err := ctx.Txn.DB().Run(ctx.Context, b); err != nil {
+					return nil, pgerror.Newf(pgcode.InvalidParameterValue, "message: %s", err)
+				}
+				resp := b.RawResponse().Responses[0].GetInner().(*roachpb.RecomputeStatsResponse)
+				delta, err := gojson.Marshal(resp.AddedDelta)
+				if err != nil {
+					return nil, err
+				}
+				j, err := json.ParseJSON(string(delta))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDJSON(j), nil
+			},
+			Info: "Recomputes the MVCC stats of the range starting at the given key and " +
+				"returns the nonzero fields of their difference from the stored stats. " +
+				"Unless dry_run is true, the recomputed stats are persisted.",
+		},
+	),
+
+	"crdb_internal.request_statement_diagnostics": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+			Impure:           true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"fingerprint", types.String}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				if ctx.StmtDiagnosticsRequester == nil {
+					return nil, errors.AssertionFailedf("statement diagnostics are not available")
+				}
+				id, err := ctx.StmtDiagnosticsRequester.InsertRequest(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+				}
+				return tree.NewDInt(tree.DInt(id)), nil
+			},
+			Info: "Requests the collection of diagnostics for the next execution on the " +
+				"current node of a statement with the given fingerprint, and returns the " +
+				"ID of the request. The collected bundle can be retrieved from " +
+				"crdb_internal.node_statement_diagnostics.",
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"fingerprint", types.String},
+				{"min_execution_latency", types.Interval},
+				{"expires_after", types.Interval},
+				{"sampling_probability", types.Float},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				if ctx.StmtDiagnosticsRequester == nil {
+					return nil, errors.AssertionFailedf("statement diagnostics are not available")
+				}
+				minLatency := args[1].(*tree.DInterval).Duration.AsFloat64()
+				expiresAfter := args[2].(*tree.DInterval).Duration.AsFloat64()
+				id, err := ctx.StmtDiagnosticsRequester.InsertConditionalRequest(
+					string(tree.MustBeDString(args[0])),
+					time.Duration(minLatency*float64(time.Second)),
+					time.Duration(expiresAfter*float64(time.Second)),
+					float64(*args[3].(*tree.DFloat)))
+				if err != nil {
+					return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+				}
+				return tree.NewDInt(tree.DInt(id)), nil
+			},
+			Info: "Requests the collection of diagnostics for the first execution on the " +
+				"current node of a statement with the given fingerprint which takes at " +
+				"least min_execution_latency, and returns the ID of the request. The faster " +
+				"executions are traced but their bundles are discarded. Unless zero, the " +
+				"request expires after expires_after, and only the given fraction of the " +
+				"matching executions is traced.",
+		},
+	),
+
+	"crdb_internal.cancel_statement_diagnostics_request": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+			Impure:           true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"request_id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				if ctx.StmtDiagnosticsRequester == nil {
+					return nil, errors.AssertionFailedf("statement diagnostics are not available")
+				}
+				canceled, err := ctx.StmtDiagnosticsRequester.CancelRequest(int64(tree.MustBeDInt(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(canceled)), nil
+			},
+			Info: "Cancels a pending statement diagnostics request on the current node. " +
+				"Returns false if there is no pending request with the given ID.",
+		},
+	),
+
+	"crdb_internal.create_api_key": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+			Impure:           true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"username", types.String}, {"scope", types.String}, {"ttl", types.Interval},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				username := string(tree.MustBeDString(args[0]))
+				scope := string(tree.MustBeDString(args[1]))
+				if scope != security.APIKeyScopeRead && scope != security.APIKeyScopeAll {
+					return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+						"unknown API key scope %q, expected %q or %q",
+						scope, security.APIKeyScopeRead, security.APIKeyScopeAll)
+				}
+				if ttl := tree.MustBeDInterval(args[2]); ttl.Compare(duration.Duration{}) <= 0 {
+					return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+						"the time to live of an API key must be positive")
+				}
+				user, err := ctx.InternalExecutor.QueryRow(
+					ctx.Ctx(), "create-api-key-check-user", ctx.Txn,
+					`SELECT 1 FROM system.users WHERE username = $1 AND NOT "isRole"`, username)
+				if err != nil {
+					return nil, err
+				}
+				if user == nil {
+					return nil, pgerror.Newf(pgcode.UndefinedObject, "user %s does not exist", username)
+				}
+				secret, hashedSecret, err := security.GenerateAPIKeySecret()
+				if err != nil {
+					return nil, err
+				}
+				row, err := ctx.InternalExecutor.QueryRow(
+					ctx.Ctx(), "create-api-key", ctx.Txn,
+					`INSERT INTO system.web_sessions ("hashedSecret", username, "expiresAt", "auditInfo")
+VALUES ($1, $2, now() + $3, $4) RETURNING id`,
+					hashedSecret, username, args[2], security.APIKeyAuditInfoPrefix+scope)
+				if err != nil {
+					return nil, err
+				}
+				id := int64(tree.MustBeDInt(row[0]))
+				return tree.NewDString(security.EncodeAPIKey(id, secret)), nil
+			},
+			Info: "Creates an API key for the given user, which authenticates requests to the " +
+				"HTTP API as that user until it expires after the given time to live. The " +
+				"scope 'read' only allows GET requests, the scope 'all' allows all requests. " +
+				"Returns the key, which is only shown once; its ID is the number before the " +
+				"period. API keys are listed in system.web_sessions.",
+		},
+	),
+
+	"crdb_internal.revoke_api_key": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+			Impure:           true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				row, err := ctx.InternalExecutor.QueryRow(
+					ctx.Ctx(), "revoke-api-key", ctx.Txn,
+					`UPDATE system.web_sessions SET "revokedAt" = now()
+WHERE id = $1 AND "auditInfo" LIKE $2 AND "revokedAt" IS NULL RETURNING id`,
+					args[0], security.APIKeyAuditInfoPrefix+"%")
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(row != nil), nil
+			},
+			Info: "Revokes the API key with the given ID. Returns false if there is no " +
+				"unrevoked API key with the given ID.",
+		},
+	),
+
 	// Identity function which is marked as impure to avoid constant folding.
 	"crdb_internal.no_constant_folding": makeBuiltin(
 		tree.FunctionProperties{
@@ -10,13 +10,30 @@
 
 package tree
 
+// DescriptorCoverage specifies whether a BACKUP or RESTORE covers the
+// descriptors it was given as targets or every descriptor of the cluster.
+type DescriptorCoverage int32
+
+const (
+	// RequestedDescriptors is the coverage of a BACKUP or RESTORE of the
+	// tables and databases it was given as targets. Such a backup is not
+	// guaranteed to have all the data of the cluster, even if the targets
+	// happen to name every table and database.
+	RequestedDescriptors DescriptorCoverage = iota
+	// AllDescriptors is the coverage of a full cluster BACKUP or RESTORE,
+	// which is written without targets. It includes every user table and
+	// database as well as the system tables which hold cluster metadata.
+	AllDescriptors
+)
+
 // Backup represents a BACKUP statement.
 type Backup struct {
-	Targets         TargetList
-	To              PartitionedBackup
-	IncrementalFrom Exprs
-	AsOf            AsOfClause
-	Options         KVOptions
+	Targets            TargetList
+	DescriptorCoverage DescriptorCoverage
+	To                 PartitionedBackup
+	IncrementalFrom    Exprs
+	AsOf               AsOfClause
+	Options            KVOptions
 }
 
 var _ Statement = &Backup{}
@@ -24,8 +41,11 @@ var _ Statement = &Backup{}
 // Format implements the NodeFormatter interface.
 func (node *Backup) Format(ctx *FmtCtx) {
 	ctx.WriteString("BACKUP ")
-	ctx.FormatNode(&node.Targets)
-	ctx.WriteString(" TO ")
+	if node.DescriptorCoverage == RequestedDescriptors {
+		ctx.FormatNode(&node.Targets)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("TO ")
 	ctx.FormatNode(&node.To)
 	if node.AsOf.Expr != nil {
 		ctx.WriteString(" ")
@@ -43,10 +63,11 @@ func (node *Backup) Format(ctx *FmtCtx) {
 
 // Restore represents a RESTORE statement.
 type Restore struct {
-	Targets TargetList
-	From    []PartitionedBackup
-	AsOf    AsOfClause
-	Options KVOptions
+	Targets            TargetList
+	DescriptorCoverage DescriptorCoverage
+	From               []PartitionedBackup
+	AsOf               AsOfClause
+	Options            KVOptions
 }
 
 var _ Statement = &Restore{}
@@ -54,8 +75,11 @@ var _ Statement = &Restore{}
 // Format implements the NodeFormatter interface.
 func (node *Restore) Format(ctx *FmtCtx) {
 	ctx.WriteString("RESTORE ")
-	ctx.FormatNode(&node.Targets)
-	ctx.WriteString(" FROM ")
+	if node.DescriptorCoverage == RequestedDescriptors {
+		ctx.FormatNode(&node.Targets)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("FROM ")
 	for i := range node.From {
 		if i > 0 {
 			ctx.WriteString(", ")
@@ -15,6 +15,7 @@ type CopyFrom struct {
 	Table   TableName
 	Columns NameList
 	Stdin   bool
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -30,4 +31,8 @@ func (node *CopyFrom) Format(ctx *FmtCtx) {
 	if node.Stdin {
 		ctx.WriteString("STDIN")
 	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
 }
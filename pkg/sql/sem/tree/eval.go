@@ -2468,6 +2468,29 @@ type EvalSessionAccessor interface {
 	GetSessionVar(ctx context.Context, settingName string, missingOk bool) (bool, string, error)
 }
 
+// StmtDiagnosticsRequester is a limited interface to the registry of statement
+// diagnostics requests of the node.
+type StmtDiagnosticsRequester interface {
+	// InsertRequest requests the diagnostics of the next execution of a
+	// statement with the given fingerprint and returns the ID of the request.
+	InsertRequest(fingerprint string) (int64, error)
+
+	// InsertConditionalRequest requests the diagnostics of the first
+	// execution of a statement with the given fingerprint which takes at least
+	// minExecutionLatency. The request expires after expiresAfter unless it is
+	// zero, and only samplingProbability of the matching executions are traced
+	// unless it is zero.
+	InsertConditionalRequest(
+		fingerprint string,
+		minExecutionLatency, expiresAfter time.Duration,
+		samplingProbability float64,
+	) (int64, error)
+
+	// CancelRequest cancels a pending request. It returns false if there is no
+	// pending request with the given ID.
+	CancelRequest(id int64) (bool, error)
+}
+
 // SessionBoundInternalExecutor is a subset of sqlutil.InternalExecutor used by
 // this sem/tree package which can't even import sqlutil. Executor used through
 // this interface are always "session-bound" - they inherit session variables
@@ -2613,6 +2636,10 @@ type EvalContext struct {
 
 	Sequence SequenceOperators
 
+	// StmtDiagnosticsRequester is used to request the collection of statement
+	// diagnostics on the local node.
+	StmtDiagnosticsRequester StmtDiagnosticsRequester
+
 	// The transaction in which the statement is executing.
 	Txn *client.Txn
 	// A handle to the database.
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -120,6 +121,11 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 				return err
 			}
 			reportedValue = tree.AsStringWithFlags(value, tree.FmtBareStrings)
+			if n.setting.Hidden() {
+				// The values of confidential settings, e.g. secrets, must not be
+				// exposed in the event log, nor delivered to the event log webhook.
+				reportedValue = "<redacted>"
+			}
 			var prev tree.Datum
 			if _, ok := n.setting.(*settings.StateMachineSetting); ok {
 				datums, err := execCfg.InternalExecutor.QueryRow(
@@ -175,7 +181,11 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 			EventLogSetClusterSetting,
 			0, /* no target */
 			int32(params.extendedEvalCtx.NodeID),
-			EventLogSetClusterSettingDetail{n.name, reportedValue, params.SessionData().User},
+			&eventpb.SetClusterSetting{
+				SettingName: n.name,
+				Value:       reportedValue,
+				User:        params.SessionData().User,
+			},
 		)
 	}); err != nil {
 		return err
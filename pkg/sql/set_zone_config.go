@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -487,21 +488,22 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 
 	// Record that the change has occurred for auditing.
 	var eventLogType EventLogType
-	info := struct {
-		Target  string
-		Config  string `json:",omitempty"`
-		Options string `json:",omitempty"`
-		User    string
-	}{
-		Target:  tree.AsStringWithFQNames(&n.zoneSpecifier, params.Ann()),
-		Config:  strings.TrimSpace(yamlConfig),
-		Options: optionStr.String(),
-		User:    params.SessionData().User,
-	}
+	var info eventpb.EventPayload
+	target := tree.AsStringWithFQNames(&n.zoneSpecifier, params.Ann())
 	if deleteZone {
 		eventLogType = EventLogRemoveZoneConfig
+		info = &eventpb.RemoveZoneConfig{
+			Target: target,
+			User:   params.SessionData().User,
+		}
 	} else {
 		eventLogType = EventLogSetZoneConfig
+		info = &eventpb.SetZoneConfig{
+			Target:  target,
+			Config:  strings.TrimSpace(yamlConfig),
+			Options: optionStr.String(),
+			User:    params.SessionData().User,
+		}
 	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
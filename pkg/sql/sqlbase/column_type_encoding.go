@@ -46,12 +46,21 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 	if (dir != encoding.Ascending) && (dir != encoding.Descending) {
 		return nil, errors.Errorf("invalid direction: %d", dir)
 	}
+	return EncodeKeyColumn(b, val, encoding.DefaultKeyColumn(dir))
+}
+
+// EncodeKeyColumn is like EncodeTableKey, but encodes `val` as a column of a
+// composite key ordered by the given direction and NULLs order.
+func EncodeKeyColumn(b []byte, val tree.Datum, col encoding.KeyColumn) ([]byte, error) {
+	if err := col.Validate(); err != nil {
+		return nil, err
+	}
+	return encodeKeyColumn(b, val, col)
+}
 
+func encodeKeyColumn(b []byte, val tree.Datum, col encoding.KeyColumn) ([]byte, error) {
 	if val == tree.DNull {
-		if dir == encoding.Ascending {
-			return encoding.EncodeNullAscending(b), nil
-		}
-		return encoding.EncodeNullDescending(b), nil
+		return col.EncodeNull(b), nil
 	}
 
 	switch t := tree.UnwrapDatum(nil, val).(type) {
@@ -62,104 +71,55 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 		} else {
 			x = 0
 		}
-		if dir == encoding.Ascending {
-			return encoding.EncodeVarintAscending(b, x), nil
-		}
-		return encoding.EncodeVarintDescending(b, x), nil
+		return col.EncodeVarint(b, x), nil
 	case *tree.DInt:
-		if dir == encoding.Ascending {
-			return encoding.EncodeVarintAscending(b, int64(*t)), nil
-		}
-		return encoding.EncodeVarintDescending(b, int64(*t)), nil
+		return col.EncodeVarint(b, int64(*t)), nil
 	case *tree.DFloat:
-		if dir == encoding.Ascending {
-			return encoding.EncodeFloatAscending(b, float64(*t)), nil
-		}
-		return encoding.EncodeFloatDescending(b, float64(*t)), nil
+		return col.EncodeFloat(b, float64(*t)), nil
 	case *tree.DDecimal:
-		if dir == encoding.Ascending {
-			return encoding.EncodeDecimalAscending(b, &t.Decimal), nil
-		}
-		return encoding.EncodeDecimalDescending(b, &t.Decimal), nil
+		return col.EncodeDecimal(b, &t.Decimal), nil
 	case *tree.DString:
-		if dir == encoding.Ascending {
-			return encoding.EncodeStringAscending(b, string(*t)), nil
-		}
-		return encoding.EncodeStringDescending(b, string(*t)), nil
+		return col.EncodeString(b, string(*t)), nil
 	case *tree.DBytes:
-		if dir == encoding.Ascending {
-			return encoding.EncodeStringAscending(b, string(*t)), nil
-		}
-		return encoding.EncodeStringDescending(b, string(*t)), nil
+		return col.EncodeString(b, string(*t)), nil
 	case *tree.DDate:
-		if dir == encoding.Ascending {
-			return encoding.EncodeVarintAscending(b, t.UnixEpochDaysWithOrig()), nil
-		}
-		return encoding.EncodeVarintDescending(b, t.UnixEpochDaysWithOrig()), nil
+		return col.EncodeVarint(b, t.UnixEpochDaysWithOrig()), nil
 	case *tree.DTime:
-		if dir == encoding.Ascending {
-			return encoding.EncodeVarintAscending(b, int64(*t)), nil
-		}
-		return encoding.EncodeVarintDescending(b, int64(*t)), nil
+		return col.EncodeVarint(b, int64(*t)), nil
 	case *tree.DTimestamp:
-		if dir == encoding.Ascending {
-			return encoding.EncodeTimeAscending(b, t.Time), nil
-		}
-		return encoding.EncodeTimeDescending(b, t.Time), nil
+		return col.EncodeTime(b, t.Time), nil
 	case *tree.DTimestampTZ:
-		if dir == encoding.Ascending {
-			return encoding.EncodeTimeAscending(b, t.Time), nil
-		}
-		return encoding.EncodeTimeDescending(b, t.Time), nil
+		return col.EncodeTime(b, t.Time), nil
 	case *tree.DInterval:
-		if dir == encoding.Ascending {
-			return encoding.EncodeDurationAscending(b, t.Duration)
-		}
-		return encoding.EncodeDurationDescending(b, t.Duration)
+		return col.EncodeDuration(b, t.Duration)
 	case *tree.DUuid:
-		if dir == encoding.Ascending {
-			return encoding.EncodeBytesAscending(b, t.GetBytes()), nil
-		}
-		return encoding.EncodeBytesDescending(b, t.GetBytes()), nil
+		return col.EncodeBytes(b, t.GetBytes()), nil
 	case *tree.DIPAddr:
-		data := t.ToBuffer(nil)
-		if dir == encoding.Ascending {
-			return encoding.EncodeBytesAscending(b, data), nil
-		}
-		return encoding.EncodeBytesDescending(b, data), nil
+		return col.EncodeBytes(b, t.ToBuffer(nil)), nil
 	case *tree.DTuple:
 		for _, datum := range t.D {
 			var err error
-			b, err = EncodeTableKey(b, datum, dir)
+			b, err = encodeKeyColumn(b, datum, col)
 			if err != nil {
 				return nil, err
 			}
 		}
 		return b, nil
 	case *tree.DCollatedString:
-		if dir == encoding.Ascending {
-			return encoding.EncodeBytesAscending(b, t.Key), nil
-		}
-		return encoding.EncodeBytesDescending(b, t.Key), nil
+		return col.EncodeBytes(b, t.Key), nil
 	case *tree.DBitArray:
-		if dir == encoding.Ascending {
-			return encoding.EncodeBitArrayAscending(b, t.BitArray), nil
-		}
-		return encoding.EncodeBitArrayDescending(b, t.BitArray), nil
+		return col.EncodeBitArray(b, t.BitArray), nil
 	case *tree.DArray:
 		for _, datum := range t.Array {
 			var err error
-			b, err = EncodeTableKey(b, datum, dir)
+			b, err = encodeKeyColumn(b, datum, col)
 			if err != nil {
 				return nil, err
 			}
 		}
 		return b, nil
 	case *tree.DOid:
-		if dir == encoding.Ascending {
-			return encoding.EncodeVarintAscending(b, int64(t.DInt)), nil
-		}
-		return encoding.EncodeVarintDescending(b, int64(t.DInt)), nil
+		return col.EncodeVarint(b, int64(t.DInt)), nil
 	}
 	return nil, errors.Errorf("unable to encode table key: %T", val)
 }
@@ -171,6 +131,16 @@ func DecodeTableKey(
 	if (dir != encoding.Ascending) && (dir != encoding.Descending) {
 		return nil, nil, errors.Errorf("invalid direction: %d", dir)
 	}
+	return DecodeKeyColumn(a, valType, key, encoding.DefaultKeyColumn(dir))
+}
+
+// DecodeKeyColumn decodes a value encoded by EncodeKeyColumn.
+func DecodeKeyColumn(
+	a *DatumAlloc, valType *types.T, key []byte, col encoding.KeyColumn,
+) (tree.Datum, []byte, error) {
+	if err := col.Validate(); err != nil {
+		return nil, nil, err
+	}
 	var isNull bool
 	if key, isNull = encoding.DecodeIfNull(key); isNull {
 		return tree.DNull, key, nil
@@ -181,54 +151,30 @@ func DecodeTableKey(
 	switch valType.Family() {
 	case types.BitFamily:
 		var r bitarray.BitArray
-		if dir == encoding.Ascending {
-			rkey, r, err = encoding.DecodeBitArrayAscending(key)
-		} else {
-			rkey, r, err = encoding.DecodeBitArrayDescending(key)
-		}
+		rkey, r, err = col.DecodeBitArray(key)
 		return a.NewDBitArray(tree.DBitArray{BitArray: r}), rkey, err
 	case types.BoolFamily:
 		var i int64
-		if dir == encoding.Ascending {
-			rkey, i, err = encoding.DecodeVarintAscending(key)
-		} else {
-			rkey, i, err = encoding.DecodeVarintDescending(key)
-		}
+		rkey, i, err = col.DecodeVarint(key)
 		// No need to chunk allocate DBool as MakeDBool returns either
 		// tree.DBoolTrue or tree.DBoolFalse.
 		return tree.MakeDBool(tree.DBool(i != 0)), rkey, err
 	case types.IntFamily:
 		var i int64
-		if dir == encoding.Ascending {
-			rkey, i, err = encoding.DecodeVarintAscending(key)
-		} else {
-			rkey, i, err = encoding.DecodeVarintDescending(key)
-		}
+		rkey, i, err = col.DecodeVarint(key)
 		return a.NewDInt(tree.DInt(i)), rkey, err
 	case types.FloatFamily:
 		var f float64
-		if dir == encoding.Ascending {
-			rkey, f, err = encoding.DecodeFloatAscending(key)
-		} else {
-			rkey, f, err = encoding.DecodeFloatDescending(key)
-		}
+		rkey, f, err = col.DecodeFloat(key)
 		return a.NewDFloat(tree.DFloat(f)), rkey, err
 	case types.DecimalFamily:
 		var d apd.Decimal
-		if dir == encoding.Ascending {
-			rkey, d, err = encoding.DecodeDecimalAscending(key, nil)
-		} else {
-			rkey, d, err = encoding.DecodeDecimalDescending(key, nil)
-		}
+		rkey, d, err = col.DecodeDecimal(key, nil)
 		dd := a.NewDDecimal(tree.DDecimal{Decimal: d})
 		return dd, rkey, err
 	case types.StringFamily:
 		var r string
-		if dir == encoding.Ascending {
-			rkey, r, err = encoding.DecodeUnsafeStringAscending(key, nil)
-		} else {
-			rkey, r, err = encoding.DecodeUnsafeStringDescending(key, nil)
-		}
+		rkey, r, err = col.DecodeUnsafeString(key, nil)
 		if valType.Oid() == oid.T_name {
 			return a.NewDName(tree.DString(r)), rkey, err
 		}
@@ -244,59 +190,31 @@ func DecodeTableKey(
 		return tree.DNull, []byte{}, nil
 	case types.BytesFamily:
 		var r []byte
-		if dir == encoding.Ascending {
-			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
-		} else {
-			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
-		}
+		rkey, r, err = col.DecodeBytes(key, nil)
 		return a.NewDBytes(tree.DBytes(r)), rkey, err
 	case types.DateFamily:
 		var t int64
-		if dir == encoding.Ascending {
-			rkey, t, err = encoding.DecodeVarintAscending(key)
-		} else {
-			rkey, t, err = encoding.DecodeVarintDescending(key)
-		}
+		rkey, t, err = col.DecodeVarint(key)
 		return a.NewDDate(tree.MakeDDate(pgdate.MakeCompatibleDateFromDisk(t))), rkey, err
 	case types.TimeFamily:
 		var t int64
-		if dir == encoding.Ascending {
-			rkey, t, err = encoding.DecodeVarintAscending(key)
-		} else {
-			rkey, t, err = encoding.DecodeVarintDescending(key)
-		}
+		rkey, t, err = col.DecodeVarint(key)
 		return a.NewDTime(tree.DTime(t)), rkey, err
 	case types.TimestampFamily:
 		var t time.Time
-		if dir == encoding.Ascending {
-			rkey, t, err = encoding.DecodeTimeAscending(key)
-		} else {
-			rkey, t, err = encoding.DecodeTimeDescending(key)
-		}
+		rkey, t, err = col.DecodeTime(key)
 		return a.NewDTimestamp(tree.DTimestamp{Time: t}), rkey, err
 	case types.TimestampTZFamily:
 		var t time.Time
-		if dir == encoding.Ascending {
-			rkey, t, err = encoding.DecodeTimeAscending(key)
-		} else {
-			rkey, t, err = encoding.DecodeTimeDescending(key)
-		}
+		rkey, t, err = col.DecodeTime(key)
 		return a.NewDTimestampTZ(tree.DTimestampTZ{Time: t}), rkey, err
 	case types.IntervalFamily:
 		var d duration.Duration
-		if dir == encoding.Ascending {
-			rkey, d, err = encoding.DecodeDurationAscending(key)
-		} else {
-			rkey, d, err = encoding.DecodeDurationDescending(key)
-		}
+		rkey, d, err = col.DecodeDuration(key)
 		return a.NewDInterval(tree.DInterval{Duration: d}), rkey, err
 	case types.UuidFamily:
 		var r []byte
-		if dir == encoding.Ascending {
-			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
-		} else {
-			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
-		}
+		rkey, r, err = col.DecodeBytes(key, nil)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -304,11 +222,7 @@ func DecodeTableKey(
 		return a.NewDUuid(tree.DUuid{UUID: u}), rkey, err
 	case types.INetFamily:
 		var r []byte
-		if dir == encoding.Ascending {
-			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
-		} else {
-			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
-		}
+		rkey, r, err = col.DecodeBytes(key, nil)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -317,11 +231,7 @@ func DecodeTableKey(
 		return a.NewDIPAddr(tree.DIPAddr{IPAddr: ipAddr}), rkey, err
 	case types.OidFamily:
 		var i int64
-		if dir == encoding.Ascending {
-			rkey, i, err = encoding.DecodeVarintAscending(key)
-		} else {
-			rkey, i, err = encoding.DecodeVarintDescending(key)
-		}
+		rkey, i, err = col.DecodeVarint(key)
 		return a.NewDOid(tree.MakeDOid(tree.DInt(i))), rkey, err
 	default:
 		return nil, nil, errors.Errorf("unable to decode table key: %s", valType)
@@ -152,5 +152,12 @@ const (
 	PgCatalogStatActivityTableID
 	PgCatalogSecurityLabelTableID
 	PgCatalogSharedSecurityLabelTableID
-	MinVirtualID = PgCatalogSharedSecurityLabelTableID
+	CrdbInternalLocalTxnContentionTableID
+	CrdbInternalLocalStmtDiagnosticsTableID
+	CrdbInternalLocalIndexRecommendationsTableID
+	CrdbInternalEventLogTableID
+	CrdbInternalLocalMemoryMonitorsTableID
+	CrdbInternalLocalOperationsTableID
+	CrdbInternalLocalExecutionInsightsTableID
+	MinVirtualID = CrdbInternalLocalExecutionInsightsTableID
 )
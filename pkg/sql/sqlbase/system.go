@@ -224,6 +224,46 @@ CREATE TABLE system.comments (
    comment   STRING NOT NULL, -- the comment
    PRIMARY KEY (type, object_id, sub_id)
 );`
+
+	// scheduled_jobs holds the schedules which periodically create jobs, e.g.
+	// scheduled backups. next_run is NULL while the schedule is paused. The
+	// columns updated on each run are kept in their own column family.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	schedule_id    INT8      DEFAULT unique_rowid() PRIMARY KEY,
+	schedule_name  STRING    NOT NULL,
+	created        TIMESTAMP NOT NULL DEFAULT now(),
+	owner          STRING    NOT NULL,
+	next_run       TIMESTAMP,
+	schedule_expr  STRING    NOT NULL,
+	executor_type  STRING    NOT NULL,
+	execution_args JSONB     NOT NULL,
+	last_run       TIMESTAMP,
+	last_status    STRING,
+	last_job_id    INT8,
+	INDEX (next_run),
+	FAMILY sched (schedule_id, next_run, last_run, last_status, last_job_id),
+	FAMILY other (schedule_name, created, owner, schedule_expr, executor_type, execution_args)
+);`
+
+	// transaction_retry_statistics accumulates, per application and statement
+	// fingerprint, the transaction retries, aborts and contention observed at
+	// that statement. Each node flushes its own rows, which are keyed by its
+	// node ID to avoid contending with the other nodes.
+	TxnRetryStatsTableSchema = `
+CREATE TABLE system.transaction_retry_statistics (
+	app_name        STRING    NOT NULL,
+	fingerprint     STRING    NOT NULL,
+	node_id         INT8      NOT NULL,
+	auto_retries    INT8      NOT NULL,
+	client_retries  INT8      NOT NULL,
+	aborts          INT8      NOT NULL,
+	abort_reasons   JSONB     NOT NULL,
+	contention_time INTERVAL  NOT NULL,
+	last_updated    TIMESTAMP NOT NULL DEFAULT now(),
+	PRIMARY KEY (app_name, fingerprint, node_id),
+	FAMILY "primary" (app_name, fingerprint, node_id, auto_retries, client_retries, aborts, abort_reasons, contention_time, last_updated)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -264,6 +304,8 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
 	keys.CommentsTableID:        privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:   privilege.ReadWriteData,
+	keys.TxnRetryStatsTableID:   privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -860,6 +902,106 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: *types.String},
+			{Name: "created", ID: 3, Type: *types.Timestamp, DefaultExpr: &nowString},
+			{Name: "owner", ID: 4, Type: *types.String},
+			{Name: "next_run", ID: 5, Type: *types.Timestamp, Nullable: true},
+			{Name: "schedule_expr", ID: 6, Type: *types.String},
+			{Name: "executor_type", ID: 7, Type: *types.String},
+			{Name: "execution_args", ID: 8, Type: *types.Jsonb},
+			{Name: "last_run", ID: 9, Type: *types.Timestamp, Nullable: true},
+			{Name: "last_status", ID: 10, Type: *types.String, Nullable: true},
+			{Name: "last_job_id", ID: 11, Type: *types.Int, Nullable: true},
+		},
+		NextColumnID: 12,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "sched",
+				ID:          0,
+				ColumnNames: []string{"schedule_id", "next_run", "last_run", "last_status", "last_job_id"},
+				ColumnIDs:   []ColumnID{1, 5, 9, 10, 11},
+			},
+			{
+				Name: "other",
+				ID:   1,
+				ColumnNames: []string{
+					"schedule_name", "created", "owner", "schedule_expr", "executor_type", "execution_args",
+				},
+				ColumnIDs: []ColumnID{2, 3, 4, 6, 7, 8},
+			},
+		},
+		NextFamilyID: 2,
+		PrimaryIndex: pk("schedule_id"),
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "scheduled_jobs_next_run_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"next_run"},
+				ColumnDirections: singleASC,
+				ColumnIDs:        []ColumnID{5},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// TxnRetryStatsTable is the descriptor for the transaction retry
+	// statistics table.
+	TxnRetryStatsTable = TableDescriptor{
+		Name:     "transaction_retry_statistics",
+		ID:       keys.TxnRetryStatsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "app_name", ID: 1, Type: *types.String},
+			{Name: "fingerprint", ID: 2, Type: *types.String},
+			{Name: "node_id", ID: 3, Type: *types.Int},
+			{Name: "auto_retries", ID: 4, Type: *types.Int},
+			{Name: "client_retries", ID: 5, Type: *types.Int},
+			{Name: "aborts", ID: 6, Type: *types.Int},
+			{Name: "abort_reasons", ID: 7, Type: *types.Jsonb},
+			{Name: "contention_time", ID: 8, Type: *types.Interval},
+			{Name: "last_updated", ID: 9, Type: *types.Timestamp, DefaultExpr: &nowString},
+		},
+		NextColumnID: 10,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"app_name", "fingerprint", "node_id", "auto_retries", "client_retries",
+					"aborts", "abort_reasons", "contention_time", "last_updated",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"app_name", "fingerprint", "node_id"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2, 3},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.TxnRetryStatsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -905,6 +1047,14 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// The CommentsTable has been introduced in 2.2. It was added here since it
 	// was introduced, but it's also created as a migration for older clusters.
 	target.AddDescriptor(keys.SystemDatabaseID, &CommentsTable)
+
+	// The ScheduledJobsTable has been introduced in 19.2. It is also created as
+	// a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &ScheduledJobsTable)
+
+	// The TxnRetryStatsTable has been introduced in 19.2. It is also created
+	// as a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &TxnRetryStatsTable)
 }
 
 // addSystemDatabaseToSchema populates the supplied MetadataSchema with the
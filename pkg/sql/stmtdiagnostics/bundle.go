@@ -0,0 +1,85 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stmtdiagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// BuildBundle returns a zip archive with the diagnostics of a statement: its
+// text, its plan, the recording of its trace, both rendered for human
+// consumption and as JSON, and the environment it was executed in.
+func BuildBundle(
+	statement, plan string, trace []tracing.RecordedSpan, sv *settings.Values,
+) ([]byte, error) {
+	// The first span of the recording is the one of the statement, which is a
+	// child of the span of its transaction. Render it as a root span so that
+	// the times in the formatted trace are relative to the start of the
+	// statement.
+	if len(trace) > 0 {
+		trace = append([]tracing.RecordedSpan(nil), trace...)
+		trace[0].ParentSpanID = 0
+	}
+	traceJSON, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for _, f := range []struct {
+		name     string
+		contents []byte
+	}{
+		{"statement.txt", []byte(statement)},
+		{"plan.txt", []byte(plan)},
+		{"trace.txt", []byte(tracing.FormatRecordedSpans(trace))},
+		{"trace.json", traceJSON},
+		{"env.txt", []byte(formatEnv(sv))},
+	} {
+		w, err := z.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.contents); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatEnv describes the environment of a statement: the build of the node
+// and the cluster settings which differ from their defaults. The values of
+// settings which may contain sensitive information are redacted.
+func formatEnv(sv *settings.Values) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "build: %s\n", build.GetInfo().Short())
+	fmt.Fprintf(&buf, "non-default cluster settings:\n")
+	for _, k := range settings.Keys() {
+		setting, _ := settings.Lookup(k)
+		if setting.Encoded(sv) == setting.EncodedDefault() {
+			continue
+		}
+		fmt.Fprintf(&buf, "  %s = %s\n", k, settings.SanitizedValue(k, sv))
+	}
+	return buf.String()
+}
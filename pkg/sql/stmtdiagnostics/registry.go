@@ -0,0 +1,420 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package stmtdiagnostics collects diagnostics bundles for statements. An
+// operator requests diagnostics for a statement fingerprint or for a session;
+// the next execution of a matching statement on the node is traced, and a
+// bundle with the statement, its plan, its trace and its environment is
+// retained in memory until it is downloaded.
+//
+// Fingerprint requests can be conditional: they can require a minimum
+// execution latency, so that a bundle is only retained for an execution
+// which is representative of a slow statement, they can expire, and they can
+// be sampled, so that not every execution of a frequent statement is traced
+// while waiting for a slow one.
+package stmtdiagnostics
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// maxCompletedRequests is the number of completed requests, along with their
+// bundles, retained by the Registry. Once it is exceeded, the oldest ones are
+// discarded.
+const maxCompletedRequests = 20
+
+// Request is a request for the diagnostics of a statement fingerprint or of
+// the next statement of a session.
+type Request struct {
+	ID int64
+	// Fingerprint is the statement fingerprint, with constants replaced by
+	// underscores, as found in crdb_internal.node_statement_statistics. It is
+	// empty for session requests.
+	Fingerprint string
+	// SessionID is the ID of the session, as found in
+	// crdb_internal.node_sessions, for session requests.
+	SessionID   string
+	RequestedAt time.Time
+	// MinExecutionLatency is the minimum latency of an execution of the
+	// statement for its bundle to be retained. The executions which are faster
+	// are traced, but their bundles are discarded and the request remains
+	// pending. Zero means that the first execution is retained.
+	MinExecutionLatency time.Duration
+	// ExpiresAt is the time after which the request is discarded if it hasn't
+	// been completed, or zero if the request doesn't expire.
+	ExpiresAt time.Time
+	// SamplingProbability is the probability with which a matching execution
+	// is traced. Zero means that every matching execution is traced.
+	SamplingProbability float64
+	// CollectedAt is the time at which the bundle was collected, or zero if
+	// the request is not completed yet.
+	CollectedAt time.Time
+	// Statement is the statement for which the bundle was collected.
+	Statement string
+	// Bundle is a zip archive with the diagnostics of the statement. It must
+	// not be modified.
+	Bundle []byte
+	// Trace holds the spans recorded for the statement. It must not be
+	// modified.
+	Trace []tracing.RecordedSpan
+
+	// done is closed once the request is completed or canceled.
+	done chan struct{}
+}
+
+// Completed returns whether the bundle of the request has been collected.
+func (r *Request) Completed() bool {
+	return !r.CollectedAt.IsZero()
+}
+
+// Conditional returns whether the request only retains the bundle of an
+// execution which is slow enough.
+func (r *Request) Conditional() bool {
+	return r.MinExecutionLatency > 0
+}
+
+func (r *Request) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Registry keeps track of the statement diagnostics requests of a node and of
+// the bundles collected for them. A nil Registry never collects diagnostics.
+type Registry struct {
+	// numPending is the number of pending requests. It allows checking for
+	// requests without locking the mutex on every statement execution.
+	numPending int32
+
+	mu struct {
+		syncutil.Mutex
+		lastID int64
+		// pending maps fingerprints to the requests waiting for a matching
+		// statement to be executed.
+		pending map[string]*Request
+		// pendingSessions maps session IDs to the requests waiting for a
+		// statement of the session to be executed.
+		pendingSessions map[string]*Request
+		// inFlight contains the requests for which a statement is being traced.
+		inFlight map[int64]*Request
+		// completed contains the completed requests, oldest first.
+		completed []*Request
+	}
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.mu.pending = make(map[string]*Request)
+	r.mu.pendingSessions = make(map[string]*Request)
+	r.mu.inFlight = make(map[int64]*Request)
+	return r
+}
+
+// InsertRequest registers a request for the diagnostics of the given statement
+// fingerprint and returns its ID.
+func (r *Registry) InsertRequest(fingerprint string) (int64, error) {
+	return r.InsertConditionalRequest(
+		fingerprint, 0 /* minExecutionLatency */, 0 /* expiresAfter */, 0 /* samplingProbability */)
+}
+
+// InsertConditionalRequest registers a request for the diagnostics of the
+// first execution of the given statement fingerprint which takes at least
+// minExecutionLatency, and returns its ID. The request is discarded after
+// expiresAfter, unless it is zero. If samplingProbability is not zero, only
+// this fraction of the matching executions is traced.
+func (r *Registry) InsertConditionalRequest(
+	fingerprint string,
+	minExecutionLatency, expiresAfter time.Duration,
+	samplingProbability float64,
+) (int64, error) {
+	if r == nil {
+		return 0, errors.New("statement diagnostics are not available")
+	}
+	if fingerprint == "" {
+		return 0, errors.New("statement fingerprint must not be empty")
+	}
+	if minExecutionLatency < 0 {
+		return 0, errors.Errorf("minimum execution latency must not be negative: %s", minExecutionLatency)
+	}
+	if expiresAfter < 0 {
+		return 0, errors.Errorf("expiration must not be negative: %s", expiresAfter)
+	}
+	if samplingProbability < 0 || samplingProbability > 1 {
+		return 0, errors.Errorf(
+			"sampling probability must be between 0 and 1: %g", samplingProbability)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req, ok := r.mu.pending[fingerprint]; ok {
+		if !req.expired(timeutil.Now()) {
+			return 0, errors.Errorf(
+				"a diagnostics request for statement fingerprint %q is already pending", fingerprint)
+		}
+		r.removePendingLocked(r.mu.pending, fingerprint)
+	}
+	for _, req := range r.mu.inFlight {
+		if req.Fingerprint == fingerprint {
+			return 0, errors.Errorf(
+				"diagnostics for statement fingerprint %q are being collected", fingerprint)
+		}
+	}
+	req := r.newRequestLocked()
+	req.Fingerprint = fingerprint
+	req.MinExecutionLatency = minExecutionLatency
+	if expiresAfter > 0 {
+		req.ExpiresAt = req.RequestedAt.Add(expiresAfter)
+	}
+	req.SamplingProbability = samplingProbability
+	r.mu.pending[fingerprint] = req
+	atomic.AddInt32(&r.numPending, 1)
+	return req.ID, nil
+}
+
+// InsertSessionRequest registers a request for the diagnostics of the next
+// statement executed by the given session and returns its ID. The session is
+// identified by its ID, as found in crdb_internal.node_sessions.
+func (r *Registry) InsertSessionRequest(sessionID string) (int64, error) {
+	if r == nil {
+		return 0, errors.New("statement diagnostics are not available")
+	}
+	if sessionID == "" {
+		return 0, errors.New("session ID must not be empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.mu.pendingSessions[sessionID]; ok {
+		return 0, errors.Errorf(
+			"a diagnostics request for session %s is already pending", sessionID)
+	}
+	for _, req := range r.mu.inFlight {
+		if req.SessionID == sessionID {
+			return 0, errors.Errorf(
+				"diagnostics for session %s are being collected", sessionID)
+		}
+	}
+	req := r.newRequestLocked()
+	req.SessionID = sessionID
+	r.mu.pendingSessions[sessionID] = req
+	atomic.AddInt32(&r.numPending, 1)
+	return req.ID, nil
+}
+
+func (r *Registry) newRequestLocked() *Request {
+	r.mu.lastID++
+	return &Request{
+		ID:          r.mu.lastID,
+		RequestedAt: timeutil.Now(),
+		done:        make(chan struct{}),
+	}
+}
+
+// CancelRequest cancels a pending request. It returns false if there is no
+// pending request with the given ID.
+func (r *Registry) CancelRequest(id int64) (bool, error) {
+	if r == nil {
+		return false, errors.New("statement diagnostics are not available")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pending := range []map[string]*Request{r.mu.pending, r.mu.pendingSessions} {
+		for key, req := range pending {
+			if req.ID == id {
+				r.removePendingLocked(pending, key)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// removePendingLocked discards a pending request, which is then reported as
+// canceled to the waiters.
+func (r *Registry) removePendingLocked(pending map[string]*Request, key string) {
+	req := pending[key]
+	delete(pending, key)
+	atomic.AddInt32(&r.numPending, -1)
+	close(req.done)
+}
+
+// ShouldCollectDiagnostics returns whether diagnostics should be collected for
+// the execution of the given statement by the given session. If so, the
+// matching request is claimed, and the caller must call either
+// CompleteRequest or ReleaseRequest with the returned ID once the statement
+// has been executed; ShouldRetainBundle tells which one. Session requests
+// take precedence over fingerprint requests. Expired requests are discarded,
+// and sampled requests are only claimed with their sampling probability.
+func (r *Registry) ShouldCollectDiagnostics(
+	ast tree.Statement, sessionID fmt.Stringer,
+) (int64, bool) {
+	if r == nil || atomic.LoadInt32(&r.numPending) == 0 {
+		return 0, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending, key := r.mu.pendingSessions, sessionID.String()
+	req, ok := pending[key]
+	if !ok {
+		pending, key = r.mu.pending, tree.AsStringWithFlags(ast, tree.FmtHideConstants)
+		if req, ok = pending[key]; !ok {
+			return 0, false
+		}
+		if req.expired(timeutil.Now()) {
+			r.removePendingLocked(pending, key)
+			return 0, false
+		}
+		if req.SamplingProbability > 0 && rand.Float64() >= req.SamplingProbability {
+			return 0, false
+		}
+	}
+	delete(pending, key)
+	atomic.AddInt32(&r.numPending, -1)
+	r.mu.inFlight[req.ID] = req
+	return req.ID, true
+}
+
+// ShouldRetainBundle returns whether the bundle of the execution of a
+// statement for a claimed request, which took the given latency, satisfies
+// the request. If it doesn't, the caller should release the request with
+// ReleaseRequest rather than building the bundle.
+func (r *Registry) ShouldRetainBundle(id int64, latency time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.mu.inFlight[id]
+	return ok && latency >= req.MinExecutionLatency
+}
+
+// CompleteRequest records the bundle and the trace collected for a claimed
+// request.
+func (r *Registry) CompleteRequest(
+	id int64, statement string, bundle []byte, trace []tracing.RecordedSpan,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.mu.inFlight[id]
+	if !ok {
+		return
+	}
+	delete(r.mu.inFlight, id)
+	req.CollectedAt = timeutil.Now()
+	req.Statement = statement
+	req.Bundle = bundle
+	req.Trace = trace
+	close(req.done)
+	r.mu.completed = append(r.mu.completed, req)
+	if n := len(r.mu.completed) - maxCompletedRequests; n > 0 {
+		r.mu.completed = append(r.mu.completed[:0], r.mu.completed[n:]...)
+	}
+}
+
+// ReleaseRequest makes a claimed request pending again, for example because
+// its bundle couldn't be built or because the execution was faster than the
+// minimum latency of the request.
+func (r *Registry) ReleaseRequest(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.mu.inFlight[id]
+	if !ok {
+		return
+	}
+	delete(r.mu.inFlight, id)
+	if req.SessionID != "" {
+		r.mu.pendingSessions[req.SessionID] = req
+	} else {
+		r.mu.pending[req.Fingerprint] = req
+	}
+	atomic.AddInt32(&r.numPending, 1)
+}
+
+// WaitForRequest waits for the request with the given ID to be completed and
+// returns it. It returns an error if there is no such request, if the request
+// is canceled, or if the context is canceled first.
+func (r *Registry) WaitForRequest(ctx context.Context, id int64) (Request, error) {
+	if r == nil {
+		return Request{}, errors.New("statement diagnostics are not available")
+	}
+	req := r.findRequest(id)
+	if req == nil {
+		return Request{}, errors.Errorf("no request with ID %d", id)
+	}
+	select {
+	case <-req.done:
+	case <-ctx.Done():
+		return Request{}, ctx.Err()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !req.Completed() {
+		return Request{}, errors.Errorf("request %d was canceled", id)
+	}
+	return *req, nil
+}
+
+func (r *Registry) findRequest(id int64) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req, ok := r.mu.inFlight[id]; ok {
+		return req
+	}
+	for _, pending := range []map[string]*Request{r.mu.pending, r.mu.pendingSessions} {
+		for _, req := range pending {
+			if req.ID == id {
+				return req
+			}
+		}
+	}
+	for _, req := range r.mu.completed {
+		if req.ID == id {
+			return req
+		}
+	}
+	return nil
+}
+
+// Requests returns the pending, in-flight and retained completed requests,
+// ordered by ID. The expired requests are discarded.
+func (r *Registry) Requests() []Request {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := timeutil.Now()
+	for key, req := range r.mu.pending {
+		if req.expired(now) {
+			r.removePendingLocked(r.mu.pending, key)
+		}
+	}
+	reqs := make([]Request, 0,
+		len(r.mu.pending)+len(r.mu.pendingSessions)+len(r.mu.inFlight)+len(r.mu.completed))
+	for _, req := range r.mu.pending {
+		reqs = append(reqs, *req)
+	}
+	for _, req := range r.mu.pendingSessions {
+		reqs = append(reqs, *req)
+	}
+	for _, req := range r.mu.inFlight {
+		reqs = append(reqs, *req)
+	}
+	for _, req := range r.mu.completed {
+		reqs = append(reqs, *req)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].ID < reqs[j].ID })
+	return reqs
+}
@@ -0,0 +1,332 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stmtdiagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+type testSessionID string
+
+func (s testSessionID) String() string { return string(s) }
+
+func TestRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := NewRegistry()
+	stmt := func(sql string) (int64, bool) {
+		s, err := parser.ParseOne(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.ShouldCollectDiagnostics(s.AST, testSessionID("session"))
+	}
+
+	if _, ok := stmt(`SELECT 1`); ok {
+		t.Fatal("unexpected collection without requests")
+	}
+
+	id, err := r.InsertRequest(`SELECT _`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.InsertRequest(`SELECT _`); !testutils.IsError(err, "already pending") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if _, ok := stmt(`SELECT 1, 2`); ok {
+		t.Fatal("unexpected collection for a different fingerprint")
+	}
+
+	// The first matching statement claims the request.
+	claimed, ok := stmt(`SELECT 1`)
+	if !ok || claimed != id {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+	}
+	if _, ok := stmt(`SELECT 2`); ok {
+		t.Fatal("request claimed twice")
+	}
+	if _, err := r.InsertRequest(`SELECT _`); !testutils.IsError(err, "being collected") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if canceled, err := r.CancelRequest(id); err != nil || canceled {
+		t.Fatalf("unexpected cancellation of an in-flight request: %t, %v", canceled, err)
+	}
+
+	// A released request can be claimed again.
+	r.ReleaseRequest(id)
+	if claimed, ok := stmt(`SELECT 3`); !ok || claimed != id {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+	}
+	r.CompleteRequest(id, `SELECT 3`, []byte("bundle"), nil /* trace */)
+	reqs := r.Requests()
+	if len(reqs) != 1 || !reqs[0].Completed() || reqs[0].Statement != `SELECT 3` ||
+		string(reqs[0].Bundle) != "bundle" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	// Pending requests can be canceled.
+	id, err = r.InsertRequest(`SELECT _`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled, err := r.CancelRequest(id); err != nil || !canceled {
+		t.Fatalf("expected cancellation, got %t, %v", canceled, err)
+	}
+	if _, ok := stmt(`SELECT 1`); ok {
+		t.Fatal("unexpected collection for a canceled request")
+	}
+
+	// Only the most recent completed requests are retained.
+	for i := 0; i < maxCompletedRequests; i++ {
+		id, err := r.InsertRequest(fmt.Sprintf(`SELECT _ FROM t%d`, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		claimed, ok := stmt(fmt.Sprintf(`SELECT 1 FROM t%d`, i))
+		if !ok || claimed != id {
+			t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+		}
+		r.CompleteRequest(id, "", nil /* bundle */, nil /* trace */)
+	}
+	reqs = r.Requests()
+	if len(reqs) != maxCompletedRequests {
+		t.Fatalf("expected %d requests, got %d", maxCompletedRequests, len(reqs))
+	}
+	if reqs[0].Fingerprint != `SELECT _ FROM t0` {
+		t.Fatalf("unexpected oldest request: %+v", reqs[0])
+	}
+}
+
+func TestRegistrySessionRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := NewRegistry()
+	stmt := func(sql string, sessionID string) (int64, bool) {
+		s, err := parser.ParseOne(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.ShouldCollectDiagnostics(s.AST, testSessionID(sessionID))
+	}
+
+	fingerprintID, err := r.InsertRequest(`SELECT _`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := r.InsertSessionRequest("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.InsertSessionRequest("a"); !testutils.IsError(err, "already pending") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if _, ok := stmt(`SELECT 1, 2`, "b"); ok {
+		t.Fatal("unexpected collection for a different session")
+	}
+
+	// Waiting for a pending request blocks until it is completed.
+	type result struct {
+		req Request
+		err error
+	}
+	resC := make(chan result, 1)
+	go func() {
+		req, err := r.WaitForRequest(context.Background(), id)
+		resC <- result{req, err}
+	}()
+
+	// The session request takes precedence over the fingerprint request.
+	if claimed, ok := stmt(`SELECT 1`, "a"); !ok || claimed != id {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+	}
+	if _, err := r.InsertSessionRequest("a"); !testutils.IsError(err, "being collected") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	trace := []tracing.RecordedSpan{{SpanID: 1, Operation: "traced statement"}}
+	r.CompleteRequest(id, `SELECT 1`, []byte("bundle"), trace)
+	select {
+	case res := <-resC:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		if res.req.SessionID != "a" || res.req.Statement != `SELECT 1` || len(res.req.Trace) != 1 {
+			t.Fatalf("unexpected request: %+v", res.req)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the request")
+	}
+	if claimed, ok := stmt(`SELECT 2`, "a"); !ok || claimed != fingerprintID {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", fingerprintID, claimed, ok)
+	}
+
+	// Waiting for a canceled request fails.
+	id, err = r.InsertSessionRequest("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled, err := r.CancelRequest(id); err != nil || !canceled {
+		t.Fatalf("expected cancellation, got %t, %v", canceled, err)
+	}
+	if _, err := r.WaitForRequest(context.Background(), id); !testutils.IsError(err, "canceled") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if _, err := r.WaitForRequest(context.Background(), 12345); !testutils.IsError(err, "no request") {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
+
+func TestRegistryConditionalRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := NewRegistry()
+	stmt := func(sql string) (int64, bool) {
+		s, err := parser.ParseOne(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.ShouldCollectDiagnostics(s.AST, testSessionID("session"))
+	}
+
+	for _, tc := range []struct {
+		minLatency, expiresAfter time.Duration
+		samplingProbability      float64
+		expErr                   string
+	}{
+		{-time.Second, 0, 0, "latency must not be negative"},
+		{0, -time.Second, 0, "expiration must not be negative"},
+		{0, 0, 1.5, "sampling probability must be between 0 and 1"},
+	} {
+		_, err := r.InsertConditionalRequest(
+			`SELECT _`, tc.minLatency, tc.expiresAfter, tc.samplingProbability)
+		if !testutils.IsError(err, tc.expErr) {
+			t.Errorf("expected error %q, got %v", tc.expErr, err)
+		}
+	}
+
+	// The bundles of the executions faster than the minimum latency aren't
+	// retained.
+	id, err := r.InsertConditionalRequest(`SELECT _`, time.Second, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed, ok := stmt(`SELECT 1`); !ok || claimed != id {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+	}
+	if r.ShouldRetainBundle(id, time.Millisecond) {
+		t.Fatal("unexpected bundle retained for a fast execution")
+	}
+	r.ReleaseRequest(id)
+	if claimed, ok := stmt(`SELECT 2`); !ok || claimed != id {
+		t.Fatalf("expected request %d to be claimed, got %d, %t", id, claimed, ok)
+	}
+	if !r.ShouldRetainBundle(id, 2*time.Second) {
+		t.Fatal("expected bundle retained for a slow execution")
+	}
+	r.CompleteRequest(id, `SELECT 2`, nil /* bundle */, nil /* trace */)
+
+	// Expired requests are discarded.
+	id, err = r.InsertConditionalRequest(`SELECT _, _`, 0, time.Nanosecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, ok := stmt(`SELECT 1, 2`); ok {
+		t.Fatal("unexpected collection for an expired request")
+	}
+	if _, err := r.WaitForRequest(context.Background(), id); !testutils.IsError(err, "no request") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if _, err := r.InsertConditionalRequest(`SELECT _, _, _`, 0, time.Nanosecond, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if reqs := r.Requests(); len(reqs) != 1 || !reqs[0].Completed() {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	// Sampled requests are only claimed by some of the executions.
+	id, err = r.InsertConditionalRequest(`SELECT _`, time.Second, 0, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claimed int
+	for i := 0; i < 1000; i++ {
+		if _, ok := stmt(`SELECT 1`); ok {
+			claimed++
+			r.ReleaseRequest(id)
+		}
+	}
+	if claimed == 0 || claimed == 1000 {
+		t.Fatalf("expected some executions to be sampled, got %d out of 1000", claimed)
+	}
+}
+
+func TestBuildBundle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	trace := []tracing.RecordedSpan{
+		{SpanID: 2, ParentSpanID: 1, Operation: "traced statement"},
+		{SpanID: 3, ParentSpanID: 2, Operation: "flow"},
+	}
+	st := cluster.MakeTestingClusterSettings()
+	bundle, err := BuildBundle(`SELECT 1`, `0 values`, trace, &st.SV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace[0].ParentSpanID != 1 {
+		t.Fatal("the recording was modified")
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(contents)
+	}
+	if len(files) != 5 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	if files["statement.txt"] != `SELECT 1` || files["plan.txt"] != `0 values` {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	if !strings.HasPrefix(files["env.txt"], "build: ") {
+		t.Fatalf("unexpected environment: %s", files["env.txt"])
+	}
+	for _, op := range []string{"traced statement", "flow"} {
+		if !strings.Contains(files["trace.txt"], "operation:"+op) ||
+			!strings.Contains(files["trace.json"], op) {
+			t.Fatalf("operation %q missing from trace: %v", op, files)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -158,11 +159,11 @@ func (t *truncateNode) startExec(params runParams) error {
 			EventLogTruncateTable,
 			int32(id),
 			int32(p.extendedEvalCtx.NodeID),
-			struct {
-				TableName string
-				Statement string
-				User      string
-			}{name, n.String(), p.SessionData().User},
+			&eventpb.TruncateTable{
+				TableName: name,
+				Statement: n.String(),
+				User:      p.SessionData().User,
+			},
 		); err != nil {
 			return err
 		}
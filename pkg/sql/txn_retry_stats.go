@@ -0,0 +1,316 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the collection of the transaction retry statistics,
+// which are persisted in system.transaction_retry_statistics so that the
+// applications and code paths causing retry storms can be identified.
+//
+// The retries, aborts and contention of a transaction are attributed to the
+// fingerprint of the statement at which they occurred, e.g. the COMMIT of
+// an explicit transaction when the transaction fails to commit. Each node
+// accumulates its statistics in memory and periodically adds them to its own
+// rows of the table; the statistics of the cluster are obtained by summing
+// the rows of all the nodes:
+//
+//   SELECT app_name, fingerprint, sum(auto_retries), sum(client_retries),
+//          sum(aborts), sum(contention_time)
+//     FROM system.transaction_retry_statistics
+//    GROUP BY app_name, fingerprint
+
+var txnRetryStatsEnable = settings.RegisterBoolSetting(
+	"sql.metrics.transaction_retries.enabled",
+	"collect per-application transaction retry, abort and contention statistics "+
+		"in system.transaction_retry_statistics",
+	true,
+)
+
+var txnRetryStatsFlushInterval = settings.RegisterValidatedDurationSetting(
+	"sql.metrics.transaction_retries.flush_interval",
+	"the interval at which each node adds the transaction retry statistics it "+
+		"collected to system.transaction_retry_statistics",
+	time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf(
+				"cannot set sql.metrics.transaction_retries.flush_interval to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// txnRetryStatsMaxFingerprints is the maximum number of distinct application
+// and fingerprint pairs for which statistics are accumulated between two
+// flushes. Further pairs are ignored until the next flush.
+const txnRetryStatsMaxFingerprints = 5000
+
+type txnRetryStatsKey struct {
+	appName     string
+	fingerprint string
+}
+
+// txnRetryStats holds the retries, aborts and contention observed at the
+// statements with a given fingerprint.
+type txnRetryStats struct {
+	// autoRetries is the number of retries performed automatically by the
+	// server.
+	autoRetries int64
+	// clientRetries is the number of retriable errors returned to the client,
+	// which it is expected to retry.
+	clientRetries int64
+	// aborts is the number of retriable errors caused by the transaction being
+	// aborted, for example by a higher priority transaction, and abortReasons
+	// breaks them down by reason.
+	aborts       int64
+	abortReasons map[string]int64
+	// contention is the time spent waiting on the intents of other
+	// transactions.
+	contention time.Duration
+}
+
+func (s *txnRetryStats) add(other *txnRetryStats) {
+	s.autoRetries += other.autoRetries
+	s.clientRetries += other.clientRetries
+	s.aborts += other.aborts
+	for reason, n := range other.abortReasons {
+		if s.abortReasons == nil {
+			s.abortReasons = make(map[string]int64)
+		}
+		s.abortReasons[reason] += n
+	}
+	s.contention += other.contention
+}
+
+// txnRetryStatsContainer accumulates the transaction retry statistics of a
+// node between two flushes.
+type txnRetryStatsContainer struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		stats map[txnRetryStatsKey]*txnRetryStats
+	}
+}
+
+func newTxnRetryStatsContainer(st *cluster.Settings) *txnRetryStatsContainer {
+	c := &txnRetryStatsContainer{st: st}
+	c.mu.stats = make(map[txnRetryStatsKey]*txnRetryStats)
+	return c
+}
+
+// enabled returns whether statistics should be recorded for the given
+// application. The statements run internally by CockroachDB are not
+// recorded.
+func (c *txnRetryStatsContainer) enabled(appName string) bool {
+	return txnRetryStatsEnable.Get(&c.st.SV) &&
+		!strings.HasPrefix(appName, sqlbase.InternalAppNamePrefix)
+}
+
+// record adds stats to the statistics of the given key.
+func (c *txnRetryStatsContainer) record(key txnRetryStatsKey, stats txnRetryStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.mu.stats[key]
+	if !ok {
+		if len(c.mu.stats) >= txnRetryStatsMaxFingerprints {
+			return
+		}
+		s = &txnRetryStats{}
+		c.mu.stats[key] = s
+	}
+	s.add(&stats)
+}
+
+// recordRetry records a retriable error encountered by a statement. autoRetry
+// is set if the server retries the transaction automatically.
+func (c *txnRetryStatsContainer) recordRetry(
+	appName string, stmt tree.Statement, err error, autoRetry bool,
+) {
+	if stmt == nil || !c.enabled(appName) {
+		return
+	}
+	var stats txnRetryStats
+	if autoRetry {
+		stats.autoRetries = 1
+	} else {
+		stats.clientRetries = 1
+	}
+	if retryErr, ok := errors.UnwrapAll(err).(*roachpb.TransactionRetryWithProtoRefreshError); ok &&
+		retryErr.PrevTxnAborted() {
+		stats.aborts = 1
+		stats.abortReasons = map[string]int64{txnAbortReason(retryErr): 1}
+	}
+	c.record(txnRetryStatsKey{appName: appName, fingerprint: anonymizeStmt(stmt)}, stats)
+}
+
+// recordContention records the time that a statement spent waiting on the
+// intents of other transactions.
+func (c *txnRetryStatsContainer) recordContention(
+	appName string, stmt *Statement, contention time.Duration,
+) {
+	if contention == 0 {
+		return
+	}
+	fingerprint := stmt.AnonymizedStr
+	if fingerprint == "" {
+		fingerprint = anonymizeStmt(stmt.AST)
+	}
+	c.record(txnRetryStatsKey{appName: appName, fingerprint: fingerprint},
+		txnRetryStats{contention: contention})
+}
+
+// txnAbortReason returns the reason for which the transaction which
+// encountered the given error was aborted. The reason is only available
+// through the message of the error.
+func txnAbortReason(retryErr *roachpb.TransactionRetryWithProtoRefreshError) string {
+	for _, reason := range roachpb.TransactionAbortedReason_name {
+		if strings.Contains(retryErr.Msg, reason) {
+			return reason
+		}
+	}
+	return roachpb.ABORT_REASON_UNKNOWN.String()
+}
+
+// flush adds the accumulated statistics to the rows of the given node in
+// system.transaction_retry_statistics. The statistics which could not be
+// written are kept for the next flush.
+func (c *txnRetryStatsContainer) flush(
+	ctx context.Context, db *client.DB, ie *InternalExecutor, nodeID roachpb.NodeID,
+) error {
+	c.mu.Lock()
+	stats := c.mu.stats
+	c.mu.stats = make(map[txnRetryStatsKey]*txnRetryStats)
+	c.mu.Unlock()
+
+	var err error
+	for key, s := range stats {
+		if err == nil {
+			err = db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				return addTxnRetryStats(ctx, ie, txn, nodeID, key, *s)
+			})
+		}
+		if err != nil {
+			c.record(key, *s)
+		}
+	}
+	return err
+}
+
+// addTxnRetryStats adds s to the row of system.transaction_retry_statistics
+// for the given key and node.
+func addTxnRetryStats(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *client.Txn,
+	nodeID roachpb.NodeID,
+	key txnRetryStatsKey,
+	s txnRetryStats,
+) error {
+	row, err := ie.QueryRow(ctx, "get-txn-retry-stats", txn, `
+SELECT auto_retries, client_retries, aborts, abort_reasons, contention_time
+  FROM system.transaction_retry_statistics
+ WHERE app_name = $1 AND fingerprint = $2 AND node_id = $3`,
+		key.appName, key.fingerprint, nodeID)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		prev := txnRetryStats{
+			autoRetries:   int64(tree.MustBeDInt(row[0])),
+			clientRetries: int64(tree.MustBeDInt(row[1])),
+			aborts:        int64(tree.MustBeDInt(row[2])),
+			contention:    time.Duration(row[4].(*tree.DInterval).Nanos()),
+		}
+		if err := json.Unmarshal(
+			[]byte(tree.MustBeDJSON(row[3]).JSON.String()), &prev.abortReasons,
+		); err != nil {
+			return errors.Wrap(err, "decoding abort reasons")
+		}
+		s.add(&prev)
+	}
+	abortReasons, err := json.Marshal(s.abortReasons)
+	if err != nil {
+		return err
+	}
+	if s.abortReasons == nil {
+		abortReasons = []byte("{}")
+	}
+	_, err = ie.Exec(ctx, "upsert-txn-retry-stats", txn, `
+UPSERT INTO system.transaction_retry_statistics
+  (app_name, fingerprint, node_id, auto_retries, client_retries, aborts, abort_reasons,
+   contention_time, last_updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7::JSONB, $8, now())`,
+		key.appName, key.fingerprint, nodeID, s.autoRetries, s.clientRetries, s.aborts,
+		string(abortReasons), s.contention)
+	return err
+}
+
+// PeriodicallyFlushTxnRetryStats runs a loop which periodically adds the
+// transaction retry statistics collected by this node to
+// system.transaction_retry_statistics.
+func (s *Server) PeriodicallyFlushTxnRetryStats(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(txnRetryStatsFlushInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := s.txnRetryStats.flush(
+				ctx, s.cfg.DB, s.cfg.InternalExecutor, s.cfg.NodeID.Get(),
+			); err != nil {
+				log.Warningf(ctx, "failed to flush transaction retry statistics: %v", err)
+			}
+		}
+	})
+}
+
+// recordTxnRetry records a retriable error encountered by the current
+// statement in the transaction retry statistics.
+func (ex *connExecutor) recordTxnRetry(err error, autoRetry bool) {
+	ex.server.txnRetryStats.recordRetry(
+		ex.applicationName.Load().(string), ex.curStmt, err, autoRetry)
+}
+
+// recordTxnContention records the time that the statement being planned by
+// the given planner spent waiting on the intents of other transactions in
+// the transaction retry statistics.
+func (ex *connExecutor) recordTxnContention(planner *planner, stmtStart time.Time) {
+	appName := ex.applicationName.Load().(string)
+	if planner.txn == nil || !ex.server.txnRetryStats.enabled(appName) {
+		return
+	}
+	contention := ex.server.cfg.ContentionRegistry.ContentionTime(planner.txn.ID(), stmtStart)
+	ex.server.txnRetryStats.recordContention(appName, planner.stmt, contention)
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestTxnRetryStatistics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	// Use a single connection so that the application name applies to all
+	// the statements below.
+	db.SetMaxOpenConns(1)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.metrics.transaction_retries.flush_interval = '10ms'`)
+	sqlDB.Exec(t, `SET application_name = 'retrier'`)
+
+	// force_retry returns a retriable error until the given interval has
+	// elapsed since the start of the transaction. The implicit transaction is
+	// retried automatically.
+	sqlDB.Exec(t, `SELECT crdb_internal.force_retry('50ms')`)
+
+	// In an explicit transaction whose results have been returned to the
+	// client, the error is returned to the client.
+	sqlDB.Exec(t, `BEGIN; SAVEPOINT cockroach_restart`)
+	sqlDB.Exec(t, `SELECT 1`)
+	sqlDB.ExpectErr(t, "forced by crdb_internal.force_retry", `SELECT crdb_internal.force_retry('1h')`)
+	sqlDB.Exec(t, `ROLLBACK`)
+
+	const query = `
+SELECT sum(auto_retries), sum(client_retries), sum(aborts)
+  FROM system.transaction_retry_statistics
+ WHERE app_name = 'retrier' AND fingerprint = 'SELECT crdb_internal.force_retry(_)'`
+	testutils.SucceedsSoon(t, func() error {
+		var autoRetries, clientRetries, aborts int64
+		if err := db.QueryRow(query).Scan(&autoRetries, &clientRetries, &aborts); err != nil {
+			return err
+		}
+		if autoRetries == 0 || clientRetries == 0 {
+			return errors.Errorf("expected automatic and client retries, found %d and %d",
+				autoRetries, clientRetries)
+		}
+		if aborts != 0 {
+			return errors.Errorf("expected no aborts, found %d", aborts)
+		}
+		return nil
+	})
+}
@@ -631,6 +631,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *controlJobsNode:
 		n.rows = v.visit(n.rows)
 
+	case *controlSchedulesNode:
+		n.rows = v.visit(n.rows)
+
 	case *setZoneConfigNode:
 		if v.observer.expr != nil {
 			v.metadataExpr(name, "yaml", -1, n.yamlConfig)
@@ -767,6 +770,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnDatabaseNode{}):    "comment on database",
 	reflect.TypeOf(&commentOnTableNode{}):       "comment on table",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
+	reflect.TypeOf(&controlSchedulesNode{}):     "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
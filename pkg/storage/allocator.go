@@ -523,6 +523,50 @@ func (a Allocator) simulateRemoveTarget(
 	return a.RemoveTarget(ctx, zone, candidates, existingReplicas)
 }
 
+// SimulateDecommission returns the stores which the allocator would choose to
+// replace the voting replicas of the range on the given nodes if they were
+// decommissioned. The stores of the decommissioned nodes are not considered
+// as targets. It returns an error if no store satisfying the zone config could
+// be found for one of the replicas.
+func (a *Allocator) SimulateDecommission(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	zone *config.ZoneConfig,
+	decommissioning map[roachpb.NodeID]bool,
+) ([]roachpb.ReplicaDescriptor, error) {
+	sl, _, _ := a.storePool.getStoreList(desc.RangeID, storeFilterNone)
+	var stores []roachpb.StoreDescriptor
+	for _, store := range sl.stores {
+		if !decommissioning[store.Node.NodeID] {
+			stores = append(stores, store)
+		}
+	}
+	sl = makeStoreList(stores)
+
+	var remaining []roachpb.ReplicaDescriptor
+	var removed int
+	for _, r := range desc.Replicas().Voters() {
+		if decommissioning[r.NodeID] {
+			removed++
+		} else {
+			remaining = append(remaining, r)
+		}
+	}
+	var targets []roachpb.ReplicaDescriptor
+	for i := 0; i < removed; i++ {
+		target, _ := a.allocateTargetFromList(ctx, sl, zone, remaining, a.scorerOptions())
+		if target == nil {
+			return targets, errors.Errorf(
+				"none of the %d remaining live stores can receive a replica of r%d "+
+					"while satisfying its zone config", len(sl.stores), desc.RangeID)
+		}
+		replica := roachpb.ReplicaDescriptor{NodeID: target.Node.NodeID, StoreID: target.StoreID}
+		remaining = append(remaining, replica)
+		targets = append(targets, replica)
+	}
+	return targets, nil
+}
+
 // RemoveTarget returns a suitable replica to remove from the provided replica
 // set. It first attempts to randomly select a target from the set of stores
 // that have greater than the average number of replicas. Failing that, it
@@ -775,7 +819,7 @@ func (a *Allocator) TransferLeaseTarget(
 	// is the current one and checkTransferLeaseSource is false).
 	var preferred []roachpb.ReplicaDescriptor
 	if checkTransferLeaseSource {
-		preferred = a.preferredLeaseholders(zone, existing)
+		preferred = a.preferredLeaseholders(zone, a.leasePreferenceCandidates(rangeID, leaseStoreID, existing))
 	} else {
 		// TODO(a-robinson): Should we just always remove the source store from
 		// existing when checkTransferLeaseSource is false? I'd do it now, but
@@ -786,7 +830,7 @@ func (a *Allocator) TransferLeaseTarget(
 				candidates = append(candidates, repl)
 			}
 		}
-		preferred = a.preferredLeaseholders(zone, candidates)
+		preferred = a.preferredLeaseholders(zone, a.leasePreferenceCandidates(rangeID, leaseStoreID, candidates))
 	}
 	if len(preferred) == 1 {
 		if preferred[0].StoreID == leaseStoreID {
@@ -890,7 +934,7 @@ func (a *Allocator) ShouldTransferLease(
 	// Determine which store(s) is preferred based on user-specified preferences.
 	// If any stores match, only consider those stores as options. If only one
 	// store matches, it's where the lease should be.
-	preferred := a.preferredLeaseholders(zone, existing)
+	preferred := a.preferredLeaseholders(zone, a.leasePreferenceCandidates(rangeID, leaseStoreID, existing))
 	if len(preferred) == 1 {
 		return preferred[0].StoreID != leaseStoreID
 	} else if len(preferred) > 1 {
@@ -1180,6 +1224,27 @@ func (a Allocator) shouldTransferLeaseWithoutStats(
 	return false
 }
 
+// leasePreferenceCandidates filters the supplied replicas down to those that
+// are eligible to be matched against the zone's lease preferences. Replicas on
+// stores that are dead or of unknown status are removed so that a preference
+// which only matches unavailable stores falls through to the next preference
+// instead of pinning the lease to a store that cannot acquire it. The current
+// leaseholder is always retained.
+func (a Allocator) leasePreferenceCandidates(
+	rangeID roachpb.RangeID, leaseStoreID roachpb.StoreID, existing []roachpb.ReplicaDescriptor,
+) []roachpb.ReplicaDescriptor {
+	live, _ := a.storePool.liveAndDeadReplicas(rangeID, existing)
+	if storeHasReplica(leaseStoreID, existing) && !storeHasReplica(leaseStoreID, live) {
+		for _, repl := range existing {
+			if repl.StoreID == leaseStoreID {
+				live = append(live, repl)
+				break
+			}
+		}
+	}
+	return live
+}
+
 func (a Allocator) preferredLeaseholders(
 	zone *config.ZoneConfig, existing []roachpb.ReplicaDescriptor,
 ) []roachpb.ReplicaDescriptor {
@@ -419,6 +419,37 @@ func TestAllocatorNoAvailableDisks(t *testing.T) {
 	}
 }
 
+func TestAllocatorSimulateDecommission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper, g, _, a, _ := createTestAllocator(1, false /* deterministic */)
+	defer stopper.Stop(context.Background())
+	gossiputil.NewStoreGossiper(g).GossipStores(sameDCStores, t)
+	desc := &roachpb.RangeDescriptor{
+		RangeID:          firstRangeID,
+		InternalReplicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1, ReplicaID: 1}},
+	}
+	simulate := func(nodeIDs ...roachpb.NodeID) ([]roachpb.ReplicaDescriptor, error) {
+		decommissioning := make(map[roachpb.NodeID]bool)
+		for _, nodeID := range nodeIDs {
+			decommissioning[nodeID] = true
+		}
+		return a.SimulateDecommission(context.Background(), desc, &simpleZoneConfig, decommissioning)
+	}
+
+	// The range has no replica on node 3.
+	if targets, err := simulate(3); err != nil || len(targets) != 0 {
+		t.Fatalf("unexpected targets %+v, err %v", targets, err)
+	}
+	// Store 2 is the only other store satisfying the constraints.
+	if targets, err := simulate(1); err != nil || len(targets) != 1 || targets[0].StoreID != 2 {
+		t.Fatalf("unexpected targets %+v, err %v", targets, err)
+	}
+	if _, err := simulate(1, 2); !testutils.IsError(err, "none of the 3 remaining live stores") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 func TestAllocatorTwoDatacenters(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1950,6 +1981,103 @@ func TestAllocatorLeasePreferencesMultipleStoresPerLocality(t *testing.T) {
 	}
 }
 
+// TestAllocatorLeasePreferencesDeadStore verifies that a lease preference
+// matching only dead stores is skipped in favor of the next preference, rather
+// than pinning the lease to a store that cannot acquire it.
+func TestAllocatorLeasePreferencesDeadStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, storePool, nl := createTestStorePool(
+		TestTimeUntilStoreDeadOff, true, /* deterministic */
+		func() int { return 10 }, /* nodeCount */
+		storagepb.NodeLivenessStatus_LIVE)
+	a := MakeAllocator(storePool, func(string) (time.Duration, bool) {
+		return 0, true
+	})
+	defer stopper.Stop(context.Background())
+
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 4; i++ {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node: roachpb.NodeDescriptor{
+				NodeID: roachpb.NodeID(i),
+				Locality: roachpb.Locality{
+					Tiers: []roachpb.Tier{
+						{Key: "dc", Value: strconv.Itoa(i)},
+					},
+				},
+			},
+			Capacity: roachpb.StoreCapacity{LeaseCount: int32(100 * i)},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	nl.setNodeStatus(4, storagepb.NodeLivenessStatus_DEAD)
+
+	preferDC4Then3 := []config.LeasePreference{
+		{Constraints: []config.Constraint{{Key: "dc", Value: "4", Type: config.Constraint_REQUIRED}}},
+		{Constraints: []config.Constraint{{Key: "dc", Value: "3", Type: config.Constraint_REQUIRED}}},
+	}
+
+	testCases := []struct {
+		leaseholder        roachpb.StoreID
+		existing           []roachpb.ReplicaDescriptor
+		expectedCheckTrue  roachpb.StoreID /* checkTransferLeaseSource = true */
+		expectedCheckFalse roachpb.StoreID /* checkTransferLeaseSource = false */
+	}{
+		{1, replicas(1, 2, 3, 4), 3, 3},
+		{2, replicas(2, 3, 4), 3, 3},
+		{3, replicas(1, 3, 4), 0, 1},
+	}
+
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			zone := &config.ZoneConfig{NumReplicas: proto.Int32(0), LeasePreferences: preferDC4Then3}
+			result := a.ShouldTransferLease(
+				context.Background(),
+				zone,
+				c.existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+			)
+			expectTransfer := c.expectedCheckTrue != 0
+			if expectTransfer != result {
+				t.Errorf("expected %v, but found %v", expectTransfer, result)
+			}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				zone,
+				c.existing,
+				c.leaseholder,
+				0,
+				nil,   /* replicaStats */
+				true,  /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* alwaysAllowDecisionWithoutStats */
+			)
+			if c.expectedCheckTrue != target.StoreID {
+				t.Errorf("expected s%d for check=true, but found %v", c.expectedCheckTrue, target)
+			}
+			target = a.TransferLeaseTarget(
+				context.Background(),
+				zone,
+				c.existing,
+				c.leaseholder,
+				0,
+				nil,   /* replicaStats */
+				false, /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* alwaysAllowDecisionWithoutStats */
+			)
+			if c.expectedCheckFalse != target.StoreID {
+				t.Errorf("expected s%d for check=false, but found %v", c.expectedCheckFalse, target)
+			}
+		})
+	}
+}
+
 func TestAllocatorRemoveTargetLocality(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
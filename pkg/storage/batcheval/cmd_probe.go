@@ -0,0 +1,40 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+)
+
+func init() {
+	RegisterCommand(roachpb.Probe, declareKeysProbe, Probe)
+}
+
+func declareKeysProbe(
+	_ *roachpb.RangeDescriptor, _ roachpb.Header, _ roachpb.Request, _ *spanset.SpanSet,
+) {
+	// Probes neither read nor write any keys.
+}
+
+// Probe marks the command as a probe, which is rejected below Raft without
+// being applied. See roachpb.ProbeRequest.
+func Probe(
+	ctx context.Context, _ engine.ReadWriter, _ CommandArgs, _ roachpb.Response,
+) (result.Result, error) {
+	var pd result.Result
+	pd.Replicated.IsProbe = true
+	return pd, nil
+}
@@ -32,7 +32,9 @@ import (
 type Limiters struct {
 	BulkIOWriteRate              *rate.Limiter
 	ConcurrentImportRequests     limit.ConcurrentRequestLimiter
+	ImportRequestRate            *rate.Limiter
 	ConcurrentExportRequests     limit.ConcurrentRequestLimiter
+	ExportRequestRate            *rate.Limiter
 	AddSSTableRequestRate        *rate.Limiter
 	ConcurrentAddSSTableRequests limit.ConcurrentRequestLimiter
 	// concurrentRangefeedIters is a semaphore used to limit the number of
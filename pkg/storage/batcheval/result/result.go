@@ -296,6 +296,9 @@ func (p *Result) MergeAndDestroy(q Result) error {
 	}
 	q.Replicated.PrevLeaseProposal = nil
 
+	p.Replicated.IsProbe = p.Replicated.IsProbe || q.Replicated.IsProbe
+	q.Replicated.IsProbe = false
+
 	if q.Local.Intents != nil {
 		if p.Local.Intents == nil {
 			p.Local.Intents = q.Local.Intents
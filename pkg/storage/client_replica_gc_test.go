@@ -183,3 +183,42 @@ func TestReplicaGCQueueDropReplicaGCOnScan(t *testing.T) {
 		return nil
 	})
 }
+
+// TestReplicaGCQueuePaused verifies that a paused replica GC queue does not
+// remove replicas, but that replicas can still be GC'ed explicitly.
+func TestReplicaGCQueuePaused(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	mtc := &multiTestContext{}
+	defer mtc.Stop()
+	mtc.Start(t, 3)
+	store := mtc.stores[1]
+	store.SetReplicaGCQueuePaused(true)
+
+	rangeID := roachpb.RangeID(1)
+	mtc.replicateRange(rangeID, 1, 2)
+	mtc.unreplicateRange(rangeID, 1)
+
+	// Neither the direct replica GC nor a scan removes the replica.
+	mtc.advanceClock(ctx)
+	mtc.manualClock.Increment(int64(storage.ReplicaGCQueueInactivityThreshold + 1))
+	store.MustForceReplicaGCScanAndProcess()
+	if _, err := store.GetReplica(rangeID); err != nil {
+		t.Fatalf("unexpected range removal: %v", err)
+	}
+	if err := store.ComputeMetrics(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if paused := store.Metrics().ReplicaGCQueuePaused.Value(); paused != 1 {
+		t.Fatalf("expected paused gauge to be 1, found %d", paused)
+	}
+
+	// Forcing replica GC removes the replica despite the pause.
+	if err := store.ForceReplicaGC(ctx, rangeID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetReplica(rangeID); !testutils.IsError(err, "r[0-9]+ was not found") {
+		t.Fatalf("expected range removal: %v", err)
+	}
+}
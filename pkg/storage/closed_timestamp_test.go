@@ -95,6 +95,91 @@ func TestClosedTimestampCanServe(t *testing.T) {
 	}
 }
 
+// TestClosedTimestampBoundedStalenessRead verifies that every replica of a
+// range can serve bounded staleness reads from its local data once the closed
+// timestamp has caught up to the minimum timestamp bound, that such reads are
+// served below unresolved intents instead of blocking on them, and that the
+// read fails when the bound cannot be satisfied.
+func TestClosedTimestampBoundedStalenessRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if util.RaceEnabled {
+		t.Skip("skipping under race")
+	}
+
+	ctx := context.Background()
+	tc, db0, desc, repls := setupTestClusterForClosedTimestampTesting(ctx, t, testingTargetDuration)
+	defer tc.Stopper().Stop(ctx)
+
+	if _, err := db0.Exec(`INSERT INTO cttest.kv VALUES(1, $1)`, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	minTS := tc.Server(0).Clock().Now()
+
+	for _, repl := range repls {
+		testutils.SucceedsSoon(t, func() error {
+			br, pErr := repl.Send(ctx, makeBoundedStalenessBatchRequestForDesc(desc, minTS))
+			if pErr != nil {
+				return pErr.GoError()
+			}
+			if br.Timestamp.Less(minTS) {
+				t.Fatalf("read at %s below minimum timestamp bound %s", br.Timestamp, minTS)
+			}
+			if rows := br.Responses[0].GetInner().(*roachpb.ScanResponse).Rows; len(rows) != 1 {
+				return errors.Errorf("expected 1 row, got %d", len(rows))
+			}
+			return nil
+		})
+	}
+
+	// Leave an intent behind. Once the closed timestamp has passed it, a
+	// regular read at intentTS runs into it, while a bounded staleness read
+	// is served below it.
+	tx, err := db0.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`INSERT INTO cttest.kv VALUES(2, $1)`, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	intentTS := tc.Server(0).Clock().Now()
+	baRead := makeReadBatchRequestForDesc(desc, intentTS)
+	for _, repl := range repls {
+		testutils.SucceedsSoon(t, func() error {
+			_, pErr := repl.Send(ctx, baRead)
+			if _, ok := pErr.GetDetail().(*roachpb.WriteIntentError); !ok {
+				return errors.Errorf("expected WriteIntentError, got %v", pErr)
+			}
+			return nil
+		})
+
+		br, pErr := repl.Send(ctx, makeBoundedStalenessBatchRequestForDesc(desc, minTS))
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if !br.Timestamp.Less(intentTS) || br.Timestamp.Less(minTS) {
+			t.Fatalf("expected read between %s and %s, got %s", minTS, intentTS, br.Timestamp)
+		}
+		if rows := br.Responses[0].GetInner().(*roachpb.ScanResponse).Rows; len(rows) != 1 {
+			t.Fatalf("expected 1 row, got %d", len(rows))
+		}
+
+		// The intent prevents reading at or above intentTS.
+		_, pErr = repl.Send(ctx, makeBoundedStalenessBatchRequestForDesc(desc, intentTS))
+		if _, ok := pErr.GetDetail().(*roachpb.MinTimestampBoundUnsatisfiableError); !ok {
+			t.Fatalf("expected MinTimestampBoundUnsatisfiableError, got %v", pErr)
+		}
+
+		// Neither can a bound in the future be satisfied.
+		future := tc.Server(0).Clock().Now().Add(time.Hour.Nanoseconds(), 0)
+		_, pErr = repl.Send(ctx, makeBoundedStalenessBatchRequestForDesc(desc, future))
+		if _, ok := pErr.GetDetail().(*roachpb.MinTimestampBoundUnsatisfiableError); !ok {
+			t.Fatalf("expected MinTimestampBoundUnsatisfiableError, got %v", pErr)
+		}
+	}
+}
+
 // TestClosedTimestampCanServerThroughoutLeaseTransfer verifies that lease
 // transfers does not prevent reading a value from a follower that was
 // previously readable.
@@ -622,3 +707,14 @@ func makeReadBatchRequestForDesc(
 	baRead.Timestamp = ts
 	return baRead
 }
+
+func makeBoundedStalenessBatchRequestForDesc(
+	desc roachpb.RangeDescriptor, minTimestampBound hlc.Timestamp,
+) roachpb.BatchRequest {
+	// The timestamp is replaced by the replica serving the read.
+	baRead := makeReadBatchRequestForDesc(desc, minTimestampBound)
+	baRead.BoundedStaleness = &roachpb.BoundedStalenessHeader{
+		MinTimestampBound: minTimestampBound,
+	}
+	return baRead
+}
@@ -47,6 +47,9 @@ type Container struct {
 	Provider closedts.Provider
 	Server   ctpb.Server
 	Clients  closedts.ClientRegistry
+	// Metrics is populated by the Provider once the Container is started. It
+	// is available (and can be registered) before then.
+	Metrics *provider.Metrics
 
 	nodeID        roachpb.NodeID
 	delayedServer *delayedServer
@@ -76,7 +79,8 @@ const (
 // the Container is started.
 func NewContainer(cfg Config) *Container {
 	return &Container{
-		Config: cfg,
+		Config:  cfg,
+		Metrics: provider.MakeMetrics(),
 	}
 }
 
@@ -125,6 +129,7 @@ func (c *Container) Start(nodeID roachpb.NodeID) {
 		Storage:  storage,
 		Clock:    cfg.Clock,
 		Close:    closedts.AsCloseFn(tracker),
+		Metrics:  c.Metrics,
 	}
 
 	provider := provider.NewProvider(&pConf)
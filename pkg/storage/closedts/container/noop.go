@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/ctpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts/provider"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 )
@@ -47,6 +48,7 @@ func NoopContainer() *Container {
 		Provider: noopEverything{},
 		Server:   noopEverything{},
 		Clients:  noopEverything{},
+		Metrics:  provider.MakeMetrics(),
 		noop:     true,
 	}
 }
@@ -0,0 +1,50 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package provider
+
+import "github.com/cockroachdb/cockroach/pkg/util/metric"
+
+var (
+	metaClosedTimestampLag = metric.Metadata{
+		Name:        "kv.closed_timestamp.lag_nanos",
+		Help:        "Amount by which the most recently closed timestamp of this node trails the current time",
+		Measurement: "Lag",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaClosedTimestampCloseFailures = metric.Metadata{
+		Name:        "kv.closed_timestamp.close_failures",
+		Help:        "Number of failed attempts to advance the closed timestamp of this node",
+		Measurement: "Attempts",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// Metrics holds the metrics exported by the Provider.
+type Metrics struct {
+	// Lag tracks how far the closed timestamp most recently published by the
+	// local node trails the current time. In steady state, this is close to
+	// kv.closed_timestamp.target_duration.
+	Lag *metric.Gauge
+	// CloseFailures counts attempts to close out a timestamp which failed,
+	// for instance due to the node's liveness record being unavailable.
+	CloseFailures *metric.Counter
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (*Metrics) MetricStruct() {}
+
+// MakeMetrics initializes a new Metrics struct.
+func MakeMetrics() *Metrics {
+	return &Metrics{
+		Lag:           metric.NewGauge(metaClosedTimestampLag),
+		CloseFailures: metric.NewCounter(metaClosedTimestampCloseFailures),
+	}
+}
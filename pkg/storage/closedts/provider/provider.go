@@ -37,6 +37,8 @@ type Config struct {
 	Storage  closedts.Storage
 	Clock    closedts.LiveClockFn
 	Close    closedts.CloseFn
+	// Metrics, if set, is updated as timestamps are closed out.
+	Metrics *Metrics
 }
 
 type subscriber struct {
@@ -67,6 +69,9 @@ var _ closedts.Provider = (*Provider)(nil)
 
 // NewProvider initializes a Provider, that has yet to be started.
 func NewProvider(cfg *Config) *Provider {
+	if cfg.Metrics == nil {
+		cfg.Metrics = MakeMetrics()
+	}
 	p := &Provider{
 		cfg:           cfg,
 		everyClockLog: log.Every(time.Minute),
@@ -144,8 +149,10 @@ func (p *Provider) runCloser(ctx context.Context) {
 		}
 
 		next, liveAtEpoch, err := p.cfg.Clock(p.cfg.NodeID)
+		now := next
 		next.WallTime -= int64(targetDuration)
 		if err != nil {
+			p.cfg.Metrics.CloseFailures.Inc(1)
 			if p.everyClockLog.ShouldLog() {
 				log.Warningf(ctx, "unable to move closed timestamp forward: %+v", err)
 			}
@@ -157,6 +164,7 @@ func (p *Provider) runCloser(ctx context.Context) {
 			// current liveAtEpoch.
 			closed, m, ok := p.cfg.Close(next, liveAtEpoch)
 			if !ok {
+				p.cfg.Metrics.CloseFailures.Inc(1)
 				if log.V(1) {
 					log.Infof(ctx, "failed to close %v due to liveness epoch mismatch at %v",
 						next, liveAtEpoch)
@@ -167,6 +175,7 @@ func (p *Provider) runCloser(ctx context.Context) {
 				log.Infof(ctx, "closed ts=%s with %+v, next closed timestamp should be %s",
 					closed, m, next)
 			}
+			p.cfg.Metrics.Lag.Update(now.WallTime - closed.WallTime)
 			entry := ctpb.Entry{
 				Epoch:           liveAtEpoch,
 				ClosedTimestamp: closed,
@@ -286,3 +286,54 @@ func TestProviderSubscribeConcurrent(t *testing.T) {
 	stopper.Stop(context.Background())
 	wg.Wait()
 }
+
+// TestProviderMetrics verifies that the Provider reports the lag of the closed
+// timestamps it produces as well as failed attempts to close out timestamps.
+func TestProviderMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	closedts.TargetDuration.Override(&st.SV, time.Millisecond)
+	closedts.CloseFraction.Override(&st.SV, 1.0)
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	const now = 10 * int64(time.Second)
+	var failClock int32 // atomic
+	metrics := provider.MakeMetrics()
+	cfg := &provider.Config{
+		NodeID:   1,
+		Settings: st,
+		Stopper:  stopper,
+		Storage:  &providertestutils.TestStorage{},
+		Clock: func(roachpb.NodeID) (hlc.Timestamp, ctpb.Epoch, error) {
+			if atomic.LoadInt32(&failClock) == 1 {
+				return hlc.Timestamp{}, 0, errors.New("injected clock error")
+			}
+			return hlc.Timestamp{WallTime: now}, 1, nil
+		},
+		Close: func(next hlc.Timestamp, expCurEpoch ctpb.Epoch) (hlc.Timestamp, map[roachpb.RangeID]ctpb.LAI, bool) {
+			return next, nil, true
+		},
+		Metrics: metrics,
+	}
+
+	p := provider.NewProvider(cfg)
+	p.Start()
+
+	testutils.SucceedsSoon(t, func() error {
+		if lag := metrics.Lag.Value(); lag != int64(time.Millisecond) {
+			return errors.Errorf("expected lag of %s, found %s", time.Millisecond, time.Duration(lag))
+		}
+		return nil
+	})
+
+	atomic.StoreInt32(&failClock, 1)
+	testutils.SucceedsSoon(t, func() error {
+		if n := metrics.CloseFailures.Count(); n == 0 {
+			return errors.New("expected failed attempts to close timestamps")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package contention records transaction contention events, in which a
+// transaction is blocked by another transaction's intent and pushes it, along
+// with the fingerprints of the statements executed by the transactions
+// involved.
+package contention
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// registrySize is the number of events retained by the Registry.
+var registrySize = settings.RegisterNonNegativeIntSetting(
+	"kv.contention.registry_size",
+	"number of transaction contention events retained in memory on each node, "+
+		"or 0 to disable recording",
+	1024,
+)
+
+// recordStatements controls whether the statements executed by transactions
+// are retained so that the events can be attributed to them. This adds work
+// to the execution of every statement, so it is disabled by default.
+var recordStatements = settings.RegisterBoolSetting(
+	"kv.contention.record_statements.enabled",
+	"retain the fingerprints of the statements executed by transactions started on each node "+
+		"to attribute transaction contention events to them",
+	false,
+)
+
+// maxStatementsPerTxn bounds the number of distinct statement fingerprints
+// retained for each transaction.
+const maxStatementsPerTxn = 16
+
+// Event describes a transaction (the pusher) which encountered an intent of
+// another transaction (the pushee) and pushed it.
+type Event struct {
+	// Time is the time at which the pusher encountered the intent.
+	Time time.Time
+	// Key is the key of the conflicting intent.
+	Key roachpb.Key
+	// PusherTxnID and PusherTxnName identify the pusher. The ID is nil for
+	// non-transactional requests.
+	PusherTxnID   uuid.UUID
+	PusherTxnName string
+	// PusherStatements are the fingerprints of the statements executed by the
+	// pusher up to the push. They are only known if statement recording is
+	// enabled and the pusher's gateway is the node which recorded the event,
+	// i.e. the leaseholder of the range containing Key.
+	PusherStatements []string
+	// PusheeTxnID identifies the pushee.
+	PusheeTxnID uuid.UUID
+	// PusheeStatements are the fingerprints of the statements executed by the
+	// pushee up to the push. Like PusherStatements, they are only known if the
+	// pushee's gateway is the node which recorded the event.
+	PusheeStatements []string
+	// PushType is the type of the push.
+	PushType roachpb.PushTxnType
+	// Duration is the time spent waiting for and pushing the pushee.
+	Duration time.Duration
+	// PusheeStatus is the status of the pushee after the push. It is
+	// ABORTED if the pusher aborted the pushee.
+	PusheeStatus roachpb.TransactionStatus
+	// Err is the error returned by the push, if any.
+	Err string
+}
+
+// Registry is a bounded, in-memory log of contention events. Once it is
+// full, the oldest events are discarded. A nil Registry discards all events.
+//
+// If kv.contention.record_statements.enabled is set, the registry also
+// retains the statement fingerprints of the most recent transactions which
+// executed statements through the SQL layer of this node, so that the events
+// can be attributed to statements. Events are recorded by the leaseholder of
+// the range on which the contention occurred, so the statements are only known
+// for the transactions whose gateway is that leaseholder.
+type Registry struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		// events is a ring buffer; next is the index at which the next event
+		// is written.
+		events []Event
+		next   int
+		full   bool
+
+		// txnStatements maps transaction IDs to the fingerprints of the
+		// statements they executed. txnOrder holds the same IDs in the order in
+		// which they were first seen, so that the oldest ones can be discarded
+		// once there are more than registrySize of them.
+		txnStatements map[uuid.UUID][]string
+		txnOrder      []uuid.UUID
+	}
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry(st *cluster.Settings) *Registry {
+	r := &Registry{st: st}
+	r.mu.txnStatements = make(map[uuid.UUID][]string)
+	return r
+}
+
+// Enabled returns whether the registry records anything. It can be used to
+// avoid computing statement fingerprints which would be discarded.
+func (r *Registry) Enabled() bool {
+	return r != nil && registrySize.Get(&r.st.SV) > 0
+}
+
+// RecordingStatements returns whether statements passed to RecordStatement
+// are retained. It can be used to avoid computing statement fingerprints which
+// would be discarded.
+func (r *Registry) RecordingStatements() bool {
+	return r.Enabled() && recordStatements.Get(&r.st.SV)
+}
+
+// RecordStatement records that the given transaction executed a statement
+// with the given fingerprint. Events involving the transaction which are
+// subsequently added are annotated with its statements.
+func (r *Registry) RecordStatement(txnID uuid.UUID, fingerprint string) {
+	if !r.RecordingStatements() {
+		return
+	}
+	size := int(registrySize.Get(&r.st.SV))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stmts, ok := r.mu.txnStatements[txnID]
+	if !ok {
+		r.mu.txnOrder = append(r.mu.txnOrder, txnID)
+	}
+	for _, s := range stmts {
+		if s == fingerprint {
+			return
+		}
+	}
+	if len(stmts) < maxStatementsPerTxn {
+		r.mu.txnStatements[txnID] = append(stmts, fingerprint)
+	}
+	for len(r.mu.txnOrder) > size {
+		delete(r.mu.txnStatements, r.mu.txnOrder[0])
+		r.mu.txnOrder = r.mu.txnOrder[1:]
+	}
+}
+
+// Add records an event.
+func (r *Registry) Add(ev Event) {
+	if r == nil {
+		return
+	}
+	size := int(registrySize.Get(&r.st.SV))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if size != len(r.mu.events) {
+		r.resizeLocked(size)
+	}
+	if size == 0 {
+		return
+	}
+	ev.PusherStatements = r.txnStatementsLocked(ev.PusherTxnID)
+	ev.PusheeStatements = r.txnStatementsLocked(ev.PusheeTxnID)
+	r.mu.events[r.mu.next] = ev
+	r.mu.next++
+	if r.mu.next == len(r.mu.events) {
+		r.mu.next = 0
+		r.mu.full = true
+	}
+}
+
+// resizeLocked changes the capacity of the ring buffer, retaining the most
+// recent events.
+func (r *Registry) resizeLocked(size int) {
+	events := r.eventsLocked()
+	if len(events) > size {
+		events = events[len(events)-size:]
+	}
+	r.mu.events = make([]Event, size)
+	r.mu.next = copy(r.mu.events, events)
+	r.mu.full = r.mu.next == size && size > 0
+	if r.mu.full {
+		r.mu.next = 0
+	}
+}
+
+// Events returns the retained events, oldest first.
+func (r *Registry) Events() []Event {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.eventsLocked()
+}
+
+// ContentionTime returns the time that the given transaction spent waiting
+// on the intents of other transactions since the given time, as observed by
+// the intent resolver of this node. The events recorded for the several
+// intents resolved by a single push are only counted once.
+func (r *Registry) ContentionTime(txnID uuid.UUID, since time.Time) time.Duration {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total time.Duration
+	var last *Event
+	// The events are visited in the order in which they were recorded, so
+	// that the events of a single push are adjacent.
+	n := len(r.mu.events)
+	start, count := 0, r.mu.next
+	if r.mu.full {
+		start, count = r.mu.next, n
+	}
+	for i := 0; i < count; i++ {
+		ev := &r.mu.events[(start+i)%n]
+		if ev.PusherTxnID != txnID || ev.Time.Before(since) {
+			continue
+		}
+		if last != nil && ev.Time.Equal(last.Time) && ev.Duration == last.Duration {
+			continue
+		}
+		total += ev.Duration
+		last = ev
+	}
+	return total
+}
+
+// txnStatementsLocked returns a copy of the statement fingerprints recorded
+// for the given transaction.
+func (r *Registry) txnStatementsLocked(txnID uuid.UUID) []string {
+	if txnID == uuid.Nil {
+		return nil
+	}
+	return append([]string(nil), r.mu.txnStatements[txnID]...)
+}
+
+func (r *Registry) eventsLocked() []Event {
+	if !r.mu.full {
+		return append([]Event(nil), r.mu.events[:r.mu.next]...)
+	}
+	events := make([]Event, 0, len(r.mu.events))
+	events = append(events, r.mu.events[r.mu.next:]...)
+	return append(events, r.mu.events[:r.mu.next]...)
+}
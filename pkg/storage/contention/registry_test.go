@@ -0,0 +1,138 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	registrySize.Override(&st.SV, 3)
+	r := NewRegistry(st)
+
+	keys := func() []string {
+		var res []string
+		for _, ev := range r.Events() {
+			res = append(res, string(ev.Key))
+		}
+		return res
+	}
+	add := func(from, to int) {
+		for i := from; i < to; i++ {
+			r.Add(Event{Key: roachpb.Key(fmt.Sprint(i))})
+		}
+	}
+	check := func(expected ...string) {
+		t.Helper()
+		if actual := keys(); fmt.Sprint(actual) != fmt.Sprint(expected) {
+			t.Fatalf("expected %v, found %v", expected, actual)
+		}
+	}
+
+	add(0, 2)
+	check("0", "1")
+	// The oldest events are discarded once the registry is full.
+	add(2, 5)
+	check("2", "3", "4")
+
+	// Growing the registry retains the existing events.
+	registrySize.Override(&st.SV, 4)
+	add(5, 6)
+	check("2", "3", "4", "5")
+
+	// Shrinking the registry retains the most recent events.
+	registrySize.Override(&st.SV, 2)
+	add(6, 7)
+	check("5", "6")
+
+	// A size of zero disables recording.
+	registrySize.Override(&st.SV, 0)
+	add(7, 8)
+	check()
+}
+
+func TestRegistryContentionTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	r := NewRegistry(st)
+
+	txn1, txn2 := uuid.MakeV4(), uuid.MakeV4()
+	start := timeutil.Unix(100, 0)
+	// An event before the start time.
+	r.Add(Event{Time: start.Add(-time.Second), PusherTxnID: txn1, Duration: time.Minute})
+	// A push of txn1 which resolved two intents.
+	r.Add(Event{Time: start, Key: roachpb.Key("a"), PusherTxnID: txn1, Duration: time.Second})
+	r.Add(Event{Time: start, Key: roachpb.Key("b"), PusherTxnID: txn1, Duration: time.Second})
+	// A push of another transaction.
+	r.Add(Event{Time: start.Add(time.Second), PusherTxnID: txn2, Duration: time.Hour})
+	// Another push of txn1.
+	r.Add(Event{Time: start.Add(2 * time.Second), PusherTxnID: txn1, Duration: 3 * time.Second})
+
+	if d := r.ContentionTime(txn1, start); d != 4*time.Second {
+		t.Fatalf("expected 4s, found %s", d)
+	}
+	if d := r.ContentionTime(txn2, start); d != time.Hour {
+		t.Fatalf("expected 1h, found %s", d)
+	}
+	if d := (*Registry)(nil).ContentionTime(txn1, start); d != 0 {
+		t.Fatalf("expected 0, found %s", d)
+	}
+}
+
+func TestRegistryStatements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	registrySize.Override(&st.SV, 2)
+	r := NewRegistry(st)
+
+	pusher, pushee := uuid.MakeV4(), uuid.MakeV4()
+	// Statements are not recorded by default.
+	r.RecordStatement(pusher, "SELECT 1")
+	r.Add(Event{PusherTxnID: pusher})
+	if stmts := r.Events()[0].PusherStatements; len(stmts) != 0 {
+		t.Fatalf("expected no statements, found %v", stmts)
+	}
+
+	recordStatements.Override(&st.SV, true)
+	r.RecordStatement(pushee, "UPDATE t SET v = _ WHERE k = _")
+	r.RecordStatement(pusher, "SELECT v FROM t WHERE k = _")
+	r.RecordStatement(pusher, "UPDATE t SET v = _ WHERE k = _")
+	// Fingerprints are only recorded once per transaction.
+	r.RecordStatement(pusher, "SELECT v FROM t WHERE k = _")
+	r.Add(Event{PusherTxnID: pusher, PusheeTxnID: pushee})
+
+	// Only the statements of the two most recent transactions are retained.
+	r.RecordStatement(uuid.MakeV4(), "SELECT 1")
+	r.Add(Event{PusherTxnID: pusher, PusheeTxnID: pushee})
+
+	events := r.Events()[1:]
+	expected := "[[SELECT v FROM t WHERE k = _ UPDATE t SET v = _ WHERE k = _] " +
+		"[UPDATE t SET v = _ WHERE k = _] [SELECT v FROM t WHERE k = _ UPDATE t SET v = _ WHERE k = _] []]"
+	if actual := fmt.Sprint([][]string{
+		events[0].PusherStatements, events[0].PusheeStatements,
+		events[1].PusherStatements, events[1].PusheeStatements,
+	}); actual != expected {
+		t.Fatalf("expected %s, found %s", expected, actual)
+	}
+}
@@ -17,8 +17,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
@@ -47,6 +49,8 @@ func SprintKeyValue(kv engine.MVCCKeyValue, printKey bool) string {
 		sb.WriteString(SprintKey(kv.Key))
 	}
 	decoders := []func(kv engine.MVCCKeyValue) (string, error){
+		tryStoreLocalKey,
+		tryQueueLastProcessed,
 		tryRaftLogEntry,
 		tryRangeDescriptor,
 		tryMeta,
@@ -298,6 +302,55 @@ func tryRangeIDKey(kv engine.MVCCKeyValue) (string, error) {
 	return msg.String(), nil
 }
 
+func tryStoreLocalKey(kv engine.MVCCKeyValue) (string, error) {
+	if _, _, err := keys.DecodeStoreKey(kv.Key.Key); err != nil {
+		return "", err
+	}
+
+	// Suggested compactions are written directly to the engine, all the
+	// other store-local keys are stored inline on the metadata.
+	if bytes.HasPrefix(kv.Key.Key, keys.LocalStoreSuggestedCompactionsMin) {
+		var c storagepb.Compaction
+		if err := protoutil.Unmarshal(kv.Value, &c); err != nil {
+			return "", err
+		}
+		return c.String(), nil
+	}
+
+	var msg protoutil.Message
+	switch {
+	case kv.Key.Key.Equal(keys.StoreIdentKey()):
+		msg = &roachpb.StoreIdent{}
+	case kv.Key.Key.Equal(keys.StoreGossipKey()):
+		msg = &gossip.BootstrapInfo{}
+	case kv.Key.Key.Equal(keys.StoreClusterVersionKey()):
+		msg = &cluster.ClusterVersion{}
+	case kv.Key.Key.Equal(keys.StoreLastUpKey()), kv.Key.Key.Equal(keys.StoreHLCUpperBoundKey()):
+		msg = &hlc.Timestamp{}
+	default:
+		return "", fmt.Errorf("unknown store-local key %s", kv.Key.Key)
+	}
+	if err := maybeUnmarshalInline(kv.Value, msg); err != nil {
+		return "", err
+	}
+	return msg.String(), nil
+}
+
+func tryQueueLastProcessed(kv engine.MVCCKeyValue) (string, error) {
+	_, suffix, _, err := keys.DecodeRangeKey(kv.Key.Key)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(suffix, keys.LocalQueueLastProcessedSuffix) {
+		return "", fmt.Errorf("wrong suffix: %s", suffix)
+	}
+	var ts hlc.Timestamp
+	if err := maybeUnmarshalInline(kv.Value, &ts); err != nil {
+		return "", err
+	}
+	return ts.String(), nil
+}
+
 func tryMeta(kv engine.MVCCKeyValue) (string, error) {
 	if !bytes.HasPrefix(kv.Key.Key, keys.Meta1Prefix) && !bytes.HasPrefix(kv.Key.Key, keys.Meta2Prefix) {
 		return "", errors.New("not a meta key")
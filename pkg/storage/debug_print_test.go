@@ -14,11 +14,14 @@ import (
 	"math"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
 func TestStringifyWriteBatch(t *testing.T) {
@@ -41,3 +44,52 @@ func TestStringifyWriteBatch(t *testing.T) {
 		t.Errorf("expected %q for stringified write batch; got %q", expStr, str)
 	}
 }
+
+func TestSprintLocalKeyValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	inline := func(msg protoutil.Message) []byte {
+		var v roachpb.Value
+		if err := v.SetProto(msg); err != nil {
+			t.Fatal(err)
+		}
+		data, err := protoutil.Marshal(&enginepb.MVCCMetadata{RawBytes: v.RawBytes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	ts := hlc.Timestamp{WallTime: 123, Logical: 4}
+	ident := roachpb.StoreIdent{NodeID: 1, StoreID: 2}
+	compaction := storagepb.Compaction{Bytes: 10, SuggestedAtNanos: 5}
+	compactionData, err := protoutil.Marshal(&compaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		key   roachpb.Key
+		value []byte
+		exp   string
+	}{
+		{keys.StoreIdentKey(), inline(&ident), ident.String()},
+		{keys.StoreLastUpKey(), inline(&ts), ts.String()},
+		{keys.StoreHLCUpperBoundKey(), inline(&ts), ts.String()},
+		{
+			keys.StoreSuggestedCompactionKey(roachpb.Key("a"), roachpb.Key("b")),
+			compactionData, compaction.String(),
+		},
+		{
+			keys.QueueLastProcessedKey(roachpb.RKey("a"), "gc"),
+			inline(&ts), ts.String(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key.String(), func(t *testing.T) {
+			kv := engine.MVCCKeyValue{Key: engine.MVCCKey{Key: tc.key}, Value: tc.value}
+			if str := SprintKeyValue(kv, false /* printKey */); str != tc.exp {
+				t.Errorf("expected %q, got %q", tc.exp, str)
+			}
+		})
+	}
+}
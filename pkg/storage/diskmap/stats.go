@@ -0,0 +1,150 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package diskmap
+
+import "sync/atomic"
+
+// Stats tracks the number of bytes written to and read from a set of
+// SortedDiskMaps. It is safe for concurrent use.
+type Stats struct {
+	bytesWritten int64
+	bytesRead    int64
+}
+
+// BytesWritten returns the number of key and value bytes written so far.
+func (s *Stats) BytesWritten() int64 {
+	return atomic.LoadInt64(&s.bytesWritten)
+}
+
+// BytesRead returns the number of key and value bytes read so far.
+func (s *Stats) BytesRead() int64 {
+	return atomic.LoadInt64(&s.bytesRead)
+}
+
+func (s *Stats) recordWrite(k, v []byte) {
+	atomic.AddInt64(&s.bytesWritten, int64(len(k)+len(v)))
+}
+
+func (s *Stats) recordRead(k, v []byte) {
+	atomic.AddInt64(&s.bytesRead, int64(len(k)+len(v)))
+}
+
+// NewCountingFactory returns a Factory which produces the SortedDiskMaps of the
+// wrapped Factory and accounts for the bytes written to and read from them in
+// stats.
+//
+// The returned Factory does not own the wrapped one: closing it is a no-op.
+func NewCountingFactory(f Factory, stats *Stats) Factory {
+	return &countingFactory{Factory: f, stats: stats}
+}
+
+type countingFactory struct {
+	Factory
+	stats *Stats
+}
+
+// Close implements the Factory interface.
+func (f *countingFactory) Close() {}
+
+// NewSortedDiskMap implements the Factory interface.
+func (f *countingFactory) NewSortedDiskMap() SortedDiskMap {
+	return &countingMap{SortedDiskMap: f.Factory.NewSortedDiskMap(), stats: f.stats}
+}
+
+// NewSortedDiskMultiMap implements the Factory interface.
+func (f *countingFactory) NewSortedDiskMultiMap() SortedDiskMap {
+	return &countingMap{SortedDiskMap: f.Factory.NewSortedDiskMultiMap(), stats: f.stats}
+}
+
+type countingMap struct {
+	SortedDiskMap
+	stats *Stats
+}
+
+// Put implements the SortedDiskMap interface.
+func (m *countingMap) Put(k []byte, v []byte) error {
+	m.stats.recordWrite(k, v)
+	return m.SortedDiskMap.Put(k, v)
+}
+
+// Get implements the SortedDiskMap interface.
+func (m *countingMap) Get(k []byte) ([]byte, error) {
+	v, err := m.SortedDiskMap.Get(k)
+	if err == nil {
+		m.stats.recordRead(k, v)
+	}
+	return v, err
+}
+
+// NewIterator implements the SortedDiskMap interface.
+func (m *countingMap) NewIterator() SortedDiskMapIterator {
+	return &countingIterator{SortedDiskMapIterator: m.SortedDiskMap.NewIterator(), stats: m.stats}
+}
+
+// NewBatchWriter implements the SortedDiskMap interface.
+func (m *countingMap) NewBatchWriter() SortedDiskMapBatchWriter {
+	return &countingBatchWriter{SortedDiskMapBatchWriter: m.SortedDiskMap.NewBatchWriter(), stats: m.stats}
+}
+
+// NewBatchWriterCapacity implements the SortedDiskMap interface.
+func (m *countingMap) NewBatchWriterCapacity(capacityBytes int) SortedDiskMapBatchWriter {
+	return &countingBatchWriter{
+		SortedDiskMapBatchWriter: m.SortedDiskMap.NewBatchWriterCapacity(capacityBytes),
+		stats:                    m.stats,
+	}
+}
+
+type countingBatchWriter struct {
+	SortedDiskMapBatchWriter
+	stats *Stats
+}
+
+// Put implements the SortedDiskMapBatchWriter interface.
+func (b *countingBatchWriter) Put(k []byte, v []byte) error {
+	b.stats.recordWrite(k, v)
+	return b.SortedDiskMapBatchWriter.Put(k, v)
+}
+
+// countingIterator accounts for each key/value pair it is positioned on once,
+// no matter how many times the pair is accessed.
+type countingIterator struct {
+	SortedDiskMapIterator
+	stats   *Stats
+	counted bool
+}
+
+// Seek implements the SortedDiskMapIterator interface.
+func (i *countingIterator) Seek(key []byte) {
+	i.counted = false
+	i.SortedDiskMapIterator.Seek(key)
+}
+
+// Rewind implements the SortedDiskMapIterator interface.
+func (i *countingIterator) Rewind() {
+	i.counted = false
+	i.SortedDiskMapIterator.Rewind()
+}
+
+// Next implements the SortedDiskMapIterator interface.
+func (i *countingIterator) Next() {
+	i.counted = false
+	i.SortedDiskMapIterator.Next()
+}
+
+// Valid implements the SortedDiskMapIterator interface.
+func (i *countingIterator) Valid() (bool, error) {
+	ok, err := i.SortedDiskMapIterator.Valid()
+	if ok && err == nil && !i.counted {
+		i.counted = true
+		i.stats.recordRead(i.SortedDiskMapIterator.UnsafeKey(), i.SortedDiskMapIterator.UnsafeValue())
+	}
+	return ok, err
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
+	"github.com/pkg/errors"
+)
+
+// An emergency ballast is a file of a fixed size that a node keeps in the
+// auxiliary directory of each of its stores. When the disk fills up, for
+// example because of a large backup or of spilling queries, the node refuses
+// to start until the operator removes the ballast. Removing it frees up
+// enough space for the node to start and to rebalance or to delete data,
+// after which the ballast is re-created on the next start.
+
+// defaultMaxBallastSize is the size of the emergency ballast when it is not
+// configured explicitly and 1% of the disk is larger than that.
+const defaultMaxBallastSize = 1 << 30 // 1 GiB
+
+// EmergencyBallastFile returns the path of the emergency ballast file of the
+// store with the given data directory.
+func EmergencyBallastFile(dataDir string) string {
+	return filepath.Join(dataDir, "auxiliary", "EMERGENCY_BALLAST")
+}
+
+// BallastSizeBytes returns the size of the emergency ballast of the given
+// store, on a disk with the given total size. Unless configured otherwise
+// through the ballast-size field of the store spec, the ballast takes 1% of
+// the disk, up to 1 GiB.
+func BallastSizeBytes(spec base.StoreSpec, totalBytes int64) int64 {
+	if spec.BallastSize != nil {
+		if spec.BallastSize.Percent > 0 {
+			return int64(float64(totalBytes) * spec.BallastSize.Percent / 100)
+		}
+		return spec.BallastSize.InBytes
+	}
+	size := totalBytes / 100
+	if size > defaultMaxBallastSize {
+		size = defaultMaxBallastSize
+	}
+	return size
+}
+
+// IsDiskFull returns true if the disk holding the given store is considered
+// full: the space available to it is less than half of its ballast. A store
+// whose directory doesn't exist yet is never full.
+func IsDiskFull(spec base.StoreSpec) (bool, error) {
+	if spec.InMemory {
+		return false, nil
+	}
+	fs, err := sysutil.StatFS(spec.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to stat filesystem of %s", spec.Path)
+	}
+	ballastSize := BallastSizeBytes(spec, fs.TotalBlocks*fs.BlockSize)
+	return fs.AvailBlocks*fs.BlockSize < ballastSize/2, nil
+}
+
+// MaybeEstablishBallast creates or resizes the emergency ballast of the given
+// store, using its configured size. The ballast is only created or grown if
+// the disk would still have at least as much space available as the ballast
+// afterwards, so that establishing it never fills up the disk; it returns
+// whether the ballast file was changed.
+func MaybeEstablishBallast(spec base.StoreSpec) (bool, error) {
+	if spec.InMemory {
+		return false, nil
+	}
+	if err := os.MkdirAll(spec.Path, 0755); err != nil {
+		return false, err
+	}
+	fs, err := sysutil.StatFS(spec.Path)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to stat filesystem of %s", spec.Path)
+	}
+	ballastSize := BallastSizeBytes(spec, fs.TotalBlocks*fs.BlockSize)
+	return ResizeBallast(EmergencyBallastFile(spec.Path), ballastSize, fs.AvailBlocks*fs.BlockSize)
+}
+
+// ResizeBallast creates, grows or shrinks the ballast file at the given path
+// so that it is exactly size bytes long, given the number of bytes currently
+// available on the disk. A zero size removes the ballast. Growing the ballast
+// fails if less than size bytes would remain available afterwards. It returns
+// whether the ballast file was changed.
+func ResizeBallast(path string, size, availBytes int64) (bool, error) {
+	var current int64
+	if info, err := os.Stat(path); err == nil {
+		current = info.Size()
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	switch {
+	case size == current:
+		return false, nil
+	case size == 0:
+		return true, os.Remove(path)
+	case size < current:
+		return true, os.Truncate(path, size)
+	}
+	if availBytes-(size-current) < size {
+		return false, errors.Errorf(
+			"not enough space available to create a ballast of %d bytes at %s: %d bytes available",
+			size, path, availBytes)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	// CreateLargeFile truncates an existing file. The space it occupied is
+	// accounted for above.
+	if err := sysutil.CreateLargeFile(path, size); err != nil {
+		return false, errors.Wrapf(err, "failed to create ballast %s", path)
+	}
+	return true, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestBallastSizeBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		ballastSize *base.SizeSpec
+		total       int64
+		expected    int64
+	}{
+		{nil, 10 << 30, 10 << 30 / 100},
+		{nil, 1 << 40, 1 << 30},
+		{&base.SizeSpec{InBytes: 5 << 20}, 1 << 40, 5 << 20},
+		{&base.SizeSpec{Percent: 20}, 1000, 200},
+		{&base.SizeSpec{}, 1 << 40, 0},
+	}
+	for i, tc := range testCases {
+		spec := base.StoreSpec{Path: "/mnt/data1", BallastSize: tc.ballastSize}
+		if actual := BallastSizeBytes(spec, tc.total); actual != tc.expected {
+			t.Errorf("%d: expected %d, got %d", i, tc.expected, actual)
+		}
+	}
+}
+
+func TestResizeBallast(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	path := EmergencyBallastFile(dir)
+
+	checkSize := func(expected int64) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if expected == 0 {
+			if !os.IsNotExist(err) {
+				t.Fatalf("expected no ballast, got %v", err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != expected {
+			t.Fatalf("expected ballast of %d bytes, got %d", expected, info.Size())
+		}
+	}
+
+	// Not enough space for the ballast.
+	if _, err := ResizeBallast(path, 1<<20, 1<<20); !testutils.IsError(err, "not enough space") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkSize(0)
+
+	for _, tc := range []struct {
+		size            int64
+		expectedResized bool
+	}{
+		{1 << 20, true},
+		{1 << 20, false},
+		{2 << 20, true},
+		{1 << 10, true},
+		{0, true},
+		{0, false},
+	} {
+		resized, err := ResizeBallast(path, tc.size, 1<<30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resized != tc.expectedResized {
+			t.Fatalf("%d: expected resized=%t, got %t", tc.size, tc.expectedResized, resized)
+		}
+		checkSize(tc.size)
+	}
+}
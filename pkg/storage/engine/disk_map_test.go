@@ -379,6 +379,67 @@ func TestRocksDBStore(t *testing.T) {
 	}
 }
 
+// TestCountingDiskMap tests that the maps produced by a counting factory
+// account for the bytes written to and read from them.
+func TestCountingDiskMap(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tempEngine, err := NewTempEngine(base.TempStorageConfig{Path: dir}, base.DefaultTestStoreSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tempEngine.Close()
+
+	var stats diskmap.Stats
+	factory := diskmap.NewCountingFactory(tempEngine, &stats)
+	// Closing the counting factory must not close the temp engine.
+	defer factory.Close()
+
+	diskMap := factory.NewSortedDiskMap()
+	defer diskMap.Close(ctx)
+
+	if err := diskMap.Put([]byte("k1"), []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	batchWriter := diskMap.NewBatchWriter()
+	if err := batchWriter.Put([]byte("k2"), []byte("value2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchWriter.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if w := stats.BytesWritten(); w != 12 {
+		t.Fatalf("expected 12 bytes written, got %d", w)
+	}
+
+	i := diskMap.NewIterator()
+	for i.Rewind(); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			break
+		}
+		// Accessing the same pair several times only counts it once.
+		_, _ = i.Key(), i.Value()
+		if _, err := i.Valid(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	i.Close()
+	if r := stats.BytesRead(); r != 12 {
+		t.Fatalf("expected 12 bytes read, got %d", r)
+	}
+
+	if _, err := diskMap.Get([]byte("k1")); err != nil {
+		t.Fatal(err)
+	}
+	if r := stats.BytesRead(); r != 16 {
+		t.Fatalf("expected 16 bytes read, got %d", r)
+	}
+}
+
 func BenchmarkRocksDBMapWrite(b *testing.B) {
 	dir, err := ioutil.TempDir("", "BenchmarkRocksDBMapWrite")
 	if err != nil {
@@ -0,0 +1,141 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// MaxSyncDuration is the threshold above which a write or sync operation on
+// a store or the temp engine is considered stalled.
+var MaxSyncDuration = settings.RegisterNonNegativeDurationSetting(
+	"storage.max_sync_duration",
+	"maximum duration for disk operations; any operations that take longer"+
+		" than this setting trigger lease shedding or a process crash (0 to disable)",
+	60*time.Second,
+)
+
+// MaxSyncDurationFatalEnabled controls whether a disk stall crashes the
+// process or only causes the node to shed its leases until the disk recovers.
+var MaxSyncDurationFatalEnabled = settings.RegisterBoolSetting(
+	"storage.max_sync_duration.fatal.enabled",
+	"if true, disk operations that exceed storage.max_sync_duration crash the process;"+
+		" otherwise the node sheds its range leases until the operations complete",
+	true,
+)
+
+// A DiskStallDetector watches the write and sync operations of the engines
+// it is passed to and reacts when one of them takes longer than
+// storage.max_sync_duration. A stalled disk otherwise goes unnoticed while
+// the leaseholders on the node hold up every request to their ranges.
+//
+// A nil *DiskStallDetector is valid and watches nothing.
+type DiskStallDetector struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		// stalled is the number of operations which exceeded the threshold and
+		// have not completed yet.
+		stalled  int
+		handlers []func(stalled bool)
+	}
+}
+
+// NewDiskStallDetector creates a DiskStallDetector configured by the given
+// cluster settings.
+func NewDiskStallDetector(st *cluster.Settings) *DiskStallDetector {
+	return &DiskStallDetector{st: st}
+}
+
+// OnStall registers a function which is called with true when an operation
+// is detected as stalled while no other operation is, and with false once
+// all the stalled operations have completed. It is only used if
+// storage.max_sync_duration.fatal.enabled is false.
+func (d *DiskStallDetector) OnStall(fn func(stalled bool)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.handlers = append(d.mu.handlers, fn)
+}
+
+// Stalled returns whether an operation is currently stalled.
+func (d *DiskStallDetector) Stalled() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mu.stalled > 0
+}
+
+// watch runs fn, the given write or sync operation on the directory dir,
+// and reacts if it does not complete within storage.max_sync_duration.
+func (d *DiskStallDetector) watch(dir, op string, fn func() error) error {
+	if d == nil {
+		return fn()
+	}
+	threshold := MaxSyncDuration.Get(&d.st.SV)
+	if threshold == 0 {
+		return fn()
+	}
+	start := timeutil.Now()
+	var stalled bool
+	var stalledMu syncutil.Mutex
+	t := time.AfterFunc(threshold, func() {
+		ctx := context.Background()
+		if MaxSyncDurationFatalEnabled.Get(&d.st.SV) {
+			// Note that this may itself hang if the log directory is on the
+			// stalled disk, though the node is unavailable either way.
+			log.Fatalf(ctx, "disk stall detected: %s on %q exceeded %s", op, dir, threshold)
+		}
+		log.Errorf(ctx, "disk stall detected: %s on %q exceeded %s; shedding leases", op, dir, threshold)
+		stalledMu.Lock()
+		defer stalledMu.Unlock()
+		stalled = true
+		d.setStalled(true)
+	})
+	err := fn()
+	t.Stop()
+	stalledMu.Lock()
+	defer stalledMu.Unlock()
+	if stalled {
+		log.Infof(context.Background(), "disk stall cleared: %s on %q completed after %s",
+			op, dir, timeutil.Since(start))
+		d.setStalled(false)
+	}
+	return err
+}
+
+// setStalled records the start or the end of a stalled operation, and calls
+// the handlers if the stalled state of the detector changes.
+func (d *DiskStallDetector) setStalled(stalled bool) {
+	d.mu.Lock()
+	if stalled {
+		d.mu.stalled++
+	} else {
+		d.mu.stalled--
+	}
+	changed := (stalled && d.mu.stalled == 1) || (!stalled && d.mu.stalled == 0)
+	handlers := d.mu.handlers
+	d.mu.Unlock()
+	if changed {
+		for _, fn := range handlers {
+			fn(stalled)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+func TestDiskStallDetector(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	MaxSyncDuration.Override(&st.SV, 10*time.Millisecond)
+	MaxSyncDurationFatalEnabled.Override(&st.SV, false)
+	d := NewDiskStallDetector(st)
+
+	var calls []bool
+	d.OnStall(func(stalled bool) { calls = append(calls, stalled) })
+
+	// Fast operations aren't reported, and their errors are returned.
+	expErr := errors.New("boom")
+	if err := d.watch("dir", "write", func() error { return expErr }); err != expErr {
+		t.Fatalf("expected %v, got %v", expErr, err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+
+	// A stalled operation is reported once it exceeds the threshold, and
+	// again when it completes.
+	if err := d.watch("dir", "sync", func() error {
+		deadline := timeutil.Now().Add(5 * time.Second)
+		for !d.Stalled() {
+			if timeutil.Now().After(deadline) {
+				return errors.New("stall not detected")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if d.Stalled() {
+		t.Fatal("expected stall to be cleared")
+	}
+	if len(calls) != 2 || !calls[0] || calls[1] {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+
+	// The detector can be disabled, and the nil detector watches nothing.
+	MaxSyncDuration.Override(&st.SV, 0)
+	for _, d := range []*DiskStallDetector{d, nil} {
+		if err := d.watch("dir", "sync", func() error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(calls) != 2 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestRocksDBDiskStallDetector(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	d := NewDiskStallDetector(st)
+	db := NewInMem(roachpb.Attributes{}, 1<<20)
+	defer db.Close()
+	db.cfg.DiskStallDetector = d
+
+	// Batch commits go through the detector without being reported.
+	if err := db.Put(mvccKey("a"), []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	b := db.NewBatch()
+	defer b.Close()
+	if err := b.Put(mvccKey("c"), []byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Commit(true /* sync */); err != nil {
+		t.Fatal(err)
+	}
+	if d.Stalled() {
+		t.Fatal("unexpected stall")
+	}
+	if v, err := db.Get(mvccKey("c")); err != nil || string(v) != "d" {
+		t.Fatalf("unexpected value %q: %v", v, err)
+	}
+}
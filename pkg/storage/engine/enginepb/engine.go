@@ -0,0 +1,51 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package enginepb
+
+import "fmt"
+
+// EngineType specifies the storage engine implementation backing a store.
+type EngineType int
+
+const (
+	// EngineTypeRocksDB is the RocksDB storage engine. It is the default.
+	EngineTypeRocksDB EngineType = iota
+	// EngineTypePebble is the Pebble storage engine.
+	EngineTypePebble
+)
+
+// Type implements the pflag.Value interface.
+func (e *EngineType) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (e *EngineType) String() string {
+	switch *e {
+	case EngineTypeRocksDB:
+		return "rocksdb"
+	case EngineTypePebble:
+		return "pebble"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (e *EngineType) Set(s string) error {
+	switch s {
+	case "rocksdb":
+		*e = EngineTypeRocksDB
+	case "pebble":
+		*e = EngineTypePebble
+	default:
+		return fmt.Errorf("invalid storage engine: %s "+
+			"(possible values: rocksdb, pebble)", s)
+	}
+	return nil
+}
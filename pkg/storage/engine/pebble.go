@@ -0,0 +1,847 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/petermattis/pebble/cache"
+	"github.com/petermattis/pebble/vfs"
+	"github.com/pkg/errors"
+)
+
+// MVCCComparer is a pebble.Comparer object that implements MVCC-specific
+// comparator settings for use with Pebble. Its ordering must match
+// engine/db.cc:DBComparator.
+var MVCCComparer = &pebble.Comparer{
+	Compare: MVCCKeyCompare,
+	AbbreviatedKey: func(k []byte) uint64 {
+		key, _, ok := enginepb.SplitMVCCKey(k)
+		if !ok {
+			return 0
+		}
+		return pebble.DefaultComparer.AbbreviatedKey(key)
+	},
+
+	Separator: func(dst, a, b []byte) []byte {
+		return append(dst, a...)
+	},
+
+	Successor: func(dst, a []byte) []byte {
+		return append(dst, a...)
+	},
+	Split: func(k []byte) int {
+		if len(k) == 0 {
+			return len(k)
+		}
+		// This is similar to what enginepb.SplitMVCCKey does.
+		tsLen := int(k[len(k)-1])
+		keyPartEnd := len(k) - 1 - tsLen
+		if keyPartEnd < 0 {
+			return len(k)
+		}
+		return keyPartEnd
+	},
+
+	FormatKey: func(k []byte) fmt.Formatter {
+		decoded, err := DecodeMVCCKey(k)
+		if err != nil {
+			return mvccKeyFormatter{err: err}
+		}
+		return mvccKeyFormatter{key: decoded}
+	},
+
+	Name: "cockroach_comparator",
+}
+
+// mvccKeyFormatter pretty-prints encoded MVCC keys, e.g. in the output of the
+// Pebble introspection tools.
+type mvccKeyFormatter struct {
+	key MVCCKey
+	err error
+}
+
+var _ fmt.Formatter = mvccKeyFormatter{}
+
+// Format implements the fmt.Formatter interface.
+func (m mvccKeyFormatter) Format(f fmt.State, c rune) {
+	if m.err != nil {
+		fmt.Fprintf(f, "<invalid key: %v>", m.err)
+		return
+	}
+	fmt.Fprint(f, m.key.String())
+}
+
+// MVCCMerger is a pebble.Merger object that implements the merge operator used
+// by Cockroach. The merge logic is shared with RocksDB (see db.cc).
+var MVCCMerger = &pebble.Merger{
+	Name: "cockroach_merge_operator",
+
+	Merge: func(key, oldValue, newValue, buf []byte) []byte {
+		res, err := goMerge(oldValue, newValue)
+		if err != nil {
+			// The Pebble merge interface has no way to surface an error. A merge
+			// failure indicates corrupt data, so crash rather than persisting a
+			// bogus value.
+			log.Fatalf(context.Background(), "merge failed for key %s: %s", key, err)
+		}
+		return append(buf, res...)
+	},
+}
+
+// pebbleTimeBoundPropCollector implements a property collector for MVCC
+// Timestamps. Its behavior matches TimeBoundTblPropCollector in
+// table_props.cc.
+type pebbleTimeBoundPropCollector struct {
+	min, max  []byte
+	lastValue []byte
+}
+
+var _ pebble.TablePropertyCollector = &pebbleTimeBoundPropCollector{}
+
+func (t *pebbleTimeBoundPropCollector) Add(key pebble.InternalKey, value []byte) error {
+	_, ts, ok := enginepb.SplitMVCCKey(key.UserKey)
+	if !ok {
+		return nil
+	}
+	if len(ts) > 0 {
+		t.lastValue = t.lastValue[:0]
+		t.updateBounds(ts)
+	} else {
+		t.lastValue = append(t.lastValue[:0], value...)
+	}
+	return nil
+}
+
+func (t *pebbleTimeBoundPropCollector) Finish(userProps map[string]string) error {
+	if len(t.lastValue) > 0 {
+		// Check to see if an intent was the last key in the SSTable. If it was,
+		// we need to extract the timestamp from the intent and update the bounds
+		// to include that timestamp.
+		var meta enginepb.MVCCMetadata
+		if err := protoutil.Unmarshal(t.lastValue, &meta); err != nil {
+			// We're unable to parse the MVCCMetadata. Fail open by not setting the
+			// min/max timestamp properties.
+			return nil
+		}
+		if meta.Txn != nil {
+			t.updateBounds(encodeTimestamp(hlc.Timestamp(meta.Timestamp)))
+		}
+	}
+
+	userProps["crdb.ts.min"] = string(t.min)
+	userProps["crdb.ts.max"] = string(t.max)
+	return nil
+}
+
+func (t *pebbleTimeBoundPropCollector) updateBounds(ts []byte) {
+	if len(t.min) == 0 || bytes.Compare(ts, t.min) < 0 {
+		t.min = append(t.min[:0], ts...)
+	}
+	if len(t.max) == 0 || bytes.Compare(ts, t.max) > 0 {
+		t.max = append(t.max[:0], ts...)
+	}
+}
+
+func (t *pebbleTimeBoundPropCollector) Name() string {
+	// This constant needs to match the one used by the RocksDB version of this
+	// table property collector. DO NOT CHANGE.
+	return "TimeBoundTblPropCollectorFactory"
+}
+
+// pebbleDeleteRangeCollector is the equivalent table collector as the RocksDB
+// DeleteRangeTblPropCollector. Pebble does not require it because Pebble will
+// prioritize its own compactions of range tombstones. It is installed so that
+// sstables written by Pebble claim the same properties as those written by
+// RocksDB.
+type pebbleDeleteRangeCollector struct{}
+
+var _ pebble.TablePropertyCollector = &pebbleDeleteRangeCollector{}
+
+func (pebbleDeleteRangeCollector) Add(key pebble.InternalKey, value []byte) error {
+	return nil
+}
+
+func (pebbleDeleteRangeCollector) Finish(userProps map[string]string) error {
+	return nil
+}
+
+func (pebbleDeleteRangeCollector) Name() string {
+	// This constant needs to match the one used by the RocksDB version of this
+	// table property collector. DO NOT CHANGE.
+	return "DeleteRangeTblPropCollectorFactory"
+}
+
+// PebbleTablePropertyCollectors is the list of Pebble TablePropertyCollectors.
+var PebbleTablePropertyCollectors = []func() pebble.TablePropertyCollector{
+	func() pebble.TablePropertyCollector { return &pebbleTimeBoundPropCollector{} },
+	func() pebble.TablePropertyCollector { return &pebbleDeleteRangeCollector{} },
+}
+
+// DefaultPebbleOptions returns the default pebble options.
+func DefaultPebbleOptions() *pebble.Options {
+	opts := &pebble.Options{
+		Comparer:                    MVCCComparer,
+		L0CompactionThreshold:       2,
+		L0StopWritesThreshold:       400,
+		LBaseMaxBytes:               64 << 20, // 64 MB
+		Levels:                      []pebble.LevelOptions{{BlockSize: 32 << 10}},
+		MemTableSize:                64 << 20, // 64 MB
+		MemTableStopWritesThreshold: 4,
+		Merger:                      MVCCMerger,
+		MinFlushRate:                4 << 20, // 4 MB/sec
+		TablePropertyCollectors:     PebbleTablePropertyCollectors,
+	}
+	opts.EnsureDefaults()
+	return opts
+}
+
+// PebbleConfig holds all configuration parameters and knobs used in setting
+// up a new Pebble instance.
+type PebbleConfig struct {
+	// Dir is the data directory for the Pebble instance. An empty Dir creates
+	// an in-memory instance.
+	Dir string
+	// Attrs is the set of attributes for this store.
+	Attrs roachpb.Attributes
+	// MaxSizeBytes is used for calculating free space and making rebalancing
+	// decisions. Zero indicates that there is no maximum size.
+	MaxSizeBytes int64
+	// Settings instance for cluster-wide knobs.
+	Settings *cluster.Settings
+	// Opts is the set of options passed to pebble.Open. If nil,
+	// DefaultPebbleOptions is used.
+	Opts *pebble.Options
+}
+
+// Pebble is a wrapper around a Pebble database instance.
+type Pebble struct {
+	db *pebble.DB
+
+	closed   bool
+	path     string
+	auxDir   string
+	maxSize  int64
+	attrs    roachpb.Attributes
+	settings *cluster.Settings
+
+	// fs is the filesystem that the engine's files live on. All file
+	// operations exposed through the Engine interface go through it so that
+	// in-memory instances work transparently.
+	fs vfs.FS
+	// cache is the block cache used by the instance, on which it holds a
+	// reference until it is closed.
+	cache *cache.Cache
+}
+
+var _ Engine = &Pebble{}
+
+// NewPebble creates a new Pebble instance, at the specified path.
+func NewPebble(cfg PebbleConfig) (*Pebble, error) {
+	opts := cfg.Opts
+	if opts == nil {
+		opts = DefaultPebbleOptions()
+	}
+	if cfg.Dir == "" && opts.FS == nil {
+		opts.FS = vfs.NewMem()
+	}
+	// Take a reference on a cache supplied by the caller, which may share it
+	// between several instances. A cache created by EnsureDefaults is owned
+	// by this instance alone.
+	if opts.Cache != nil {
+		opts.Cache.Ref()
+	}
+	opts.EnsureDefaults()
+
+	auxDir := filepath.Join(cfg.Dir, "auxiliary")
+	if err := opts.FS.MkdirAll(auxDir, 0755); err != nil {
+		opts.Cache.Unref()
+		return nil, err
+	}
+
+	db, err := pebble.Open(cfg.Dir, opts)
+	if err != nil {
+		opts.Cache.Unref()
+		return nil, err
+	}
+
+	return &Pebble{
+		db:       db,
+		path:     cfg.Dir,
+		auxDir:   auxDir,
+		maxSize:  cfg.MaxSizeBytes,
+		attrs:    cfg.Attrs,
+		settings: cfg.Settings,
+		fs:       opts.FS,
+		cache:    opts.Cache,
+	}, nil
+}
+
+// newPebbleInMem creates a new in-memory Pebble instance for testing.
+func newPebbleInMem(attrs roachpb.Attributes, cacheSize int64) *Pebble {
+	opts := DefaultPebbleOptions()
+	if cacheSize > 0 {
+		// Pebble doesn't support 0-size caches; leave the default cache in
+		// place in that case.
+		opts.Cache = cache.New(cacheSize)
+		defer opts.Cache.Unref()
+	}
+	db, err := NewPebble(PebbleConfig{
+		Attrs: attrs,
+		// TODO(bdarnell): The hard-coded 512 MiB is wrong; see
+		// https://github.com/cockroachdb/cockroach/issues/16750
+		MaxSizeBytes: 512 << 20,
+		Opts:         opts,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// String implements the stringer interface.
+func (p *Pebble) String() string {
+	dir := p.path
+	if dir == "" {
+		dir = "<in-mem>"
+	}
+	attrs := p.attrs.String()
+	if attrs == "" {
+		attrs = "<no-attributes>"
+	}
+	return fmt.Sprintf("%s=%s", attrs, dir)
+}
+
+// Close implements the Engine interface.
+func (p *Pebble) Close() {
+	if p.closed {
+		log.Errorf(context.TODO(), "closing unopened pebble instance")
+		return
+	}
+	p.closed = true
+	if err := p.db.Close(); err != nil {
+		log.Errorf(context.TODO(), "error closing pebble instance: %s", err)
+	}
+	p.cache.Unref()
+}
+
+// Closed implements the Engine interface.
+func (p *Pebble) Closed() bool {
+	return p.closed
+}
+
+// Get implements the Engine interface.
+func (p *Pebble) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.db, key)
+}
+
+// GetProto implements the Engine interface.
+func (p *Pebble) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.db, key, msg)
+}
+
+// Iterate implements the Engine interface.
+func (p *Pebble) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (stop bool, err error)) error {
+	return iterateOnReader(p, start, end, f)
+}
+
+// NewIterator implements the Engine interface.
+func (p *Pebble) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.db, opts)
+}
+
+// ApplyBatchRepr implements the Engine interface.
+func (p *Pebble) ApplyBatchRepr(repr []byte, sync bool) error {
+	batch := p.db.NewBatch()
+	if err := batch.SetRepr(repr); err != nil {
+		return err
+	}
+
+	opts := pebble.NoSync
+	if sync {
+		opts = pebble.Sync
+	}
+	return batch.Commit(opts)
+}
+
+// Clear implements the Engine interface.
+func (p *Pebble) Clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Delete(EncodeKey(key), pebble.NoSync)
+}
+
+// SingleClear implements the Engine interface. Pebble does not support
+// SingleDelete, so this is a regular deletion, which is a valid (if more
+// conservative) implementation of the SingleClear contract.
+func (p *Pebble) SingleClear(key MVCCKey) error {
+	return p.Clear(key)
+}
+
+// ClearRange implements the Engine interface.
+func (p *Pebble) ClearRange(start, end MVCCKey) error {
+	return p.db.DeleteRange(EncodeKey(start), EncodeKey(end), pebble.NoSync)
+}
+
+// ClearIterRange implements the Engine interface.
+func (p *Pebble) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	// Write all the tombstones in one batch.
+	batch := p.NewWriteOnlyBatch()
+	defer batch.Close()
+
+	if err := batch.ClearIterRange(iter, start, end); err != nil {
+		return err
+	}
+	return batch.Commit(true)
+}
+
+// Merge implements the Engine interface.
+func (p *Pebble) Merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Merge(EncodeKey(key), value, pebble.NoSync)
+}
+
+// Put implements the Engine interface.
+func (p *Pebble) Put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Set(EncodeKey(key), value, pebble.NoSync)
+}
+
+// LogData implements the Engine interface.
+func (p *Pebble) LogData(data []byte) error {
+	return p.db.LogData(data, pebble.Sync)
+}
+
+// LogLogicalOp implements the Engine interface.
+func (p *Pebble) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+// Attrs implements the Engine interface.
+func (p *Pebble) Attrs() roachpb.Attributes {
+	return p.attrs
+}
+
+// Capacity implements the Engine interface.
+func (p *Pebble) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(p.path, p.maxSize)
+}
+
+// Flush implements the Engine interface.
+func (p *Pebble) Flush() error {
+	return p.db.Flush()
+}
+
+// GetStats implements the Engine interface.
+func (p *Pebble) GetStats() (*Stats, error) {
+	m := p.db.Metrics()
+	return &Stats{
+		BlockCacheHits:                 m.BlockCache.Hits,
+		BlockCacheMisses:               m.BlockCache.Misses,
+		BlockCacheUsage:                m.BlockCache.Size,
+		MemtableTotalSize:              int64(m.MemTable.Size),
+		Flushes:                        m.Flush.Count,
+		Compactions:                    m.Compact.Count,
+		TableReadersMemEstimate:        m.TableCache.Size,
+		PendingCompactionBytesEstimate: int64(m.Compact.EstimatedDebt),
+		L0FileCount:                    m.Levels[0].NumFiles,
+	}, nil
+}
+
+// GetEnvStats implements the Engine interface.
+func (p *Pebble) GetEnvStats() (*EnvStats, error) {
+	// Pebble does not support encryption at rest, so only the file counts are
+	// reported.
+	m := p.db.Metrics()
+	var stats EnvStats
+	for _, lm := range m.Levels {
+		stats.TotalFiles += uint64(lm.NumFiles)
+		stats.TotalBytes += lm.Size
+	}
+	return &stats, nil
+}
+
+// GetAuxiliaryDir implements the Engine interface.
+func (p *Pebble) GetAuxiliaryDir() string {
+	return p.auxDir
+}
+
+// NewBatch implements the Engine interface.
+func (p *Pebble) NewBatch() Batch {
+	return newPebbleBatch(p.db, p.db.NewIndexedBatch(), false /* writeOnly */)
+}
+
+// NewReadOnly implements the Engine interface.
+func (p *Pebble) NewReadOnly() ReadWriter {
+	return &pebbleReadOnly{parent: p}
+}
+
+// NewWriteOnlyBatch implements the Engine interface.
+func (p *Pebble) NewWriteOnlyBatch() Batch {
+	return newPebbleBatch(p.db, p.db.NewBatch(), true /* writeOnly */)
+}
+
+// NewSnapshot implements the Engine interface.
+func (p *Pebble) NewSnapshot() Reader {
+	return &pebbleSnapshot{
+		snapshot: p.db.NewSnapshot(),
+	}
+}
+
+// IngestExternalFiles implements the Engine interface. Pebble never modifies
+// the ingested files (the global sequence number is recorded in the
+// manifest), so skipWritingSeqNo and allowFileModifications are ignored.
+func (p *Pebble) IngestExternalFiles(
+	ctx context.Context, paths []string, skipWritingSeqNo, allowFileModifications bool,
+) error {
+	return p.db.Ingest(paths)
+}
+
+// PreIngestDelay implements the Engine interface. See
+// (*RocksDB).PreIngestDelay for a description of the delay calculation.
+func (p *Pebble) PreIngestDelay(ctx context.Context) {
+	preIngestDelay(ctx, p, p.settings)
+}
+
+// ApproximateDiskBytes implements the Engine interface.
+func (p *Pebble) ApproximateDiskBytes(from, to roachpb.Key) (uint64, error) {
+	// TODO(itsbilal): Add functionality in Pebble to do this count internally,
+	// instead of iterating over the range.
+	count := uint64(0)
+	_ = p.Iterate(MVCCKey{Key: from}, MVCCKey{Key: to}, func(kv MVCCKeyValue) (bool, error) {
+		count += uint64(kv.Key.EncodedSize() + len(kv.Value))
+		return false, nil
+	})
+	return count, nil
+}
+
+// CompactRange implements the Engine interface.
+func (p *Pebble) CompactRange(start, end roachpb.Key, forceBottommost bool) error {
+	return p.db.Compact(EncodeKey(MVCCKey{Key: start}), EncodeKey(MVCCKey{Key: end}))
+}
+
+// OpenFile implements the Engine interface.
+func (p *Pebble) OpenFile(filename string) (DBFile, error) {
+	file, err := p.fs.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleFile{file: file}, nil
+}
+
+// ReadFile implements the Engine interface.
+func (p *Pebble) ReadFile(filename string) ([]byte, error) {
+	file, err := p.fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+// DeleteFile implements the Engine interface.
+func (p *Pebble) DeleteFile(filename string) error {
+	return p.fs.Remove(filename)
+}
+
+// DeleteDirAndFiles implements the Engine interface.
+func (p *Pebble) DeleteDirAndFiles(dir string) error {
+	files, err := p.fs.List(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Note that we don't recurse into subdirectories; this matches the RocksDB
+	// implementation.
+	for _, filename := range files {
+		path := filepath.Join(dir, filename)
+		stat, err := p.fs.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !stat.IsDir() {
+			if err := p.fs.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	if err := p.fs.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LinkFile implements the Engine interface.
+func (p *Pebble) LinkFile(oldname, newname string) error {
+	return p.fs.Link(oldname, newname)
+}
+
+// CreateCheckpoint implements the Engine interface.
+func (p *Pebble) CreateCheckpoint(dir string) error {
+	return errors.New("checkpoints are not supported by the pebble engine")
+}
+
+// pebbleFile implements the DBFile interface on top of a vfs.File.
+type pebbleFile struct {
+	file vfs.File
+}
+
+var _ DBFile = &pebbleFile{}
+
+// Append implements the DBFile interface.
+func (f *pebbleFile) Append(data []byte) error {
+	_, err := f.file.Write(data)
+	return err
+}
+
+// Close implements the DBFile interface.
+func (f *pebbleFile) Close() error {
+	return f.file.Close()
+}
+
+// Sync implements the DBFile interface.
+func (f *pebbleFile) Sync() error {
+	return f.file.Sync()
+}
+
+// pebbleReadOnly is a read-only view of a Pebble instance. Write operations
+// panic.
+type pebbleReadOnly struct {
+	parent *Pebble
+	closed bool
+}
+
+var _ ReadWriter = &pebbleReadOnly{}
+
+func (p *pebbleReadOnly) Close() {
+	if p.closed {
+		panic("closing an already-closed pebbleReadOnly")
+	}
+	p.closed = true
+}
+
+func (p *pebbleReadOnly) Closed() bool {
+	return p.closed
+}
+
+func (p *pebbleReadOnly) Get(key MVCCKey) ([]byte, error) {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return p.parent.Get(key)
+}
+
+func (p *pebbleReadOnly) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return p.parent.GetProto(key, msg)
+}
+
+func (p *pebbleReadOnly) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return iterateOnReader(p, start, end, f)
+}
+
+func (p *pebbleReadOnly) NewIterator(opts IterOptions) Iterator {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return p.parent.NewIterator(opts)
+}
+
+// Writer methods are not implemented for pebbleReadOnly. Ideally, the code
+// could be refactored so that a Reader could be supplied to evaluateBatch.
+
+func (p *pebbleReadOnly) ApplyBatchRepr(repr []byte, sync bool) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Clear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) SingleClear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) ClearRange(start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Merge(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Put(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) LogData(data []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	panic("not implemented")
+}
+
+// pebbleSnapshot represents a snapshot created using Pebble.NewSnapshot().
+type pebbleSnapshot struct {
+	snapshot *pebble.Snapshot
+	closed   bool
+}
+
+var _ Reader = &pebbleSnapshot{}
+
+// Close implements the Reader interface.
+func (p *pebbleSnapshot) Close() {
+	_ = p.snapshot.Close()
+	p.closed = true
+}
+
+// Closed implements the Reader interface.
+func (p *pebbleSnapshot) Closed() bool {
+	return p.closed
+}
+
+// Get implements the Reader interface.
+func (p *pebbleSnapshot) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.snapshot, key)
+}
+
+// GetProto implements the Reader interface.
+func (p *pebbleSnapshot) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.snapshot, key, msg)
+}
+
+// Iterate implements the Reader interface.
+func (p *pebbleSnapshot) Iterate(
+	start, end MVCCKey, f func(MVCCKeyValue) (stop bool, err error),
+) error {
+	return iterateOnReader(p, start, end, f)
+}
+
+// NewIterator implements the Reader interface.
+func (p *pebbleSnapshot) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.snapshot, opts)
+}
+
+// pebbleGet looks up the given key in a Pebble reader (a DB, snapshot or
+// indexed batch). It returns nil if the key is not present.
+func pebbleGet(r pebble.Reader, key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	ret, err := r.Get(EncodeKey(key))
+	if err == pebble.ErrNotFound || len(ret) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The returned value is only valid until the next call into Pebble, so
+	// copy it out.
+	return append([]byte(nil), ret...), nil
+}
+
+// pebbleGetProto is the Pebble equivalent of dbGetProto.
+func pebbleGetProto(
+	r pebble.Reader, key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if len(key.Key) == 0 {
+		return false, 0, 0, emptyKeyError()
+	}
+	val, err := r.Get(EncodeKey(key))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return false, 0, 0, nil
+		}
+		return false, 0, 0, err
+	}
+	if len(val) == 0 {
+		msg.Reset()
+		return false, 0, 0, nil
+	}
+	if msg != nil {
+		if err := protoutil.Unmarshal(val, msg); err != nil {
+			return true, 0, 0, err
+		}
+	}
+	keyBytes = int64(key.EncodedSize())
+	valBytes = int64(len(val))
+	return true, keyBytes, valBytes, nil
+}
+
+// iterateOnReader implements Reader.Iterate on top of Reader.NewIterator.
+func iterateOnReader(
+	reader Reader, start, end MVCCKey, f func(MVCCKeyValue) (stop bool, err error),
+) error {
+	if reader.Closed() {
+		return errors.New("cannot call Iterate on a closed reader")
+	}
+	if !start.Less(end) {
+		return nil
+	}
+
+	it := reader.NewIterator(IterOptions{UpperBound: end.Key})
+	defer it.Close()
+
+	it.Seek(start)
+	for ; ; it.Next() {
+		ok, err := it.Valid()
+		if err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		k := it.Key()
+		if !k.Less(end) {
+			break
+		}
+		if done, err := f(MVCCKeyValue{Key: k, Value: it.Value()}); done || err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+)
+
+// pebbleBatch wraps a pebble Batch.
+type pebbleBatch struct {
+	db    *pebble.DB
+	batch *pebble.Batch
+	// writeOnly is set for batches created via NewWriteOnlyBatch, which are
+	// not indexed and do not support reads.
+	writeOnly bool
+	// parentBatch is set for the view returned by Distinct. Pebble indexed
+	// batches are cheap to read from, so a distinct batch shares the parent's
+	// underlying batch; the parent may not be used while it is open.
+	parentBatch  *pebbleBatch
+	distinctOpen bool
+	closed       bool
+}
+
+var _ Batch = &pebbleBatch{}
+
+// newPebbleBatch creates a new batch wrapping the given pebble batch.
+func newPebbleBatch(db *pebble.DB, batch *pebble.Batch, writeOnly bool) *pebbleBatch {
+	return &pebbleBatch{
+		db:        db,
+		batch:     batch,
+		writeOnly: writeOnly,
+	}
+}
+
+// Close implements the Batch interface.
+func (p *pebbleBatch) Close() {
+	if p.closed {
+		panic("closing an already-closed pebbleBatch")
+	}
+	p.closed = true
+	if p.parentBatch != nil {
+		// Distinct batches don't own the underlying batch.
+		p.parentBatch.distinctOpen = false
+		return
+	}
+	_ = p.batch.Close()
+	p.batch = nil
+}
+
+// Closed implements the Batch interface.
+func (p *pebbleBatch) Closed() bool {
+	return p.closed
+}
+
+// reader returns the pebble.Reader that reads on this batch should be served
+// from.
+func (p *pebbleBatch) reader() pebble.Reader {
+	if p.distinctOpen {
+		panic("distinct batch open")
+	}
+	if p.writeOnly {
+		if p.parentBatch == nil {
+			panic("write-only batch")
+		}
+		// A distinct batch on top of a write-only batch reads from the
+		// underlying engine.
+		return p.db
+	}
+	return p.batch
+}
+
+// Get implements the Batch interface.
+func (p *pebbleBatch) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.reader(), key)
+}
+
+// GetProto implements the Batch interface.
+func (p *pebbleBatch) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.reader(), key, msg)
+}
+
+// Iterate implements the Batch interface.
+func (p *pebbleBatch) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return iterateOnReader(p, start, end, f)
+}
+
+// NewIterator implements the Batch interface.
+func (p *pebbleBatch) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.reader(), opts)
+}
+
+// writer returns the batch that writes should be applied to.
+func (p *pebbleBatch) writer() *pebble.Batch {
+	if p.distinctOpen {
+		panic("distinct batch open")
+	}
+	return p.batch
+}
+
+// ApplyBatchRepr implements the Batch interface.
+func (p *pebbleBatch) ApplyBatchRepr(repr []byte, sync bool) error {
+	if sync {
+		panic("sync is not supported on a batch")
+	}
+	var batch pebble.Batch
+	if err := batch.SetRepr(repr); err != nil {
+		return err
+	}
+	return p.writer().Apply(&batch, nil)
+}
+
+// Clear implements the Batch interface.
+func (p *pebbleBatch) Clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.writer().Delete(EncodeKey(key), nil)
+}
+
+// SingleClear implements the Batch interface. See (*Pebble).SingleClear.
+func (p *pebbleBatch) SingleClear(key MVCCKey) error {
+	return p.Clear(key)
+}
+
+// ClearRange implements the Batch interface.
+func (p *pebbleBatch) ClearRange(start, end MVCCKey) error {
+	return p.writer().DeleteRange(EncodeKey(start), EncodeKey(end), nil)
+}
+
+// ClearIterRange implements the Batch interface.
+func (p *pebbleBatch) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	w := p.writer()
+	iter.Seek(start)
+	for ; ; iter.Next() {
+		valid, err := iter.Valid()
+		if err != nil {
+			return err
+		} else if !valid {
+			break
+		}
+		key := iter.UnsafeKey()
+		if !key.Less(end) {
+			break
+		}
+		if err := w.Delete(EncodeKey(key), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge implements the Batch interface.
+func (p *pebbleBatch) Merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.writer().Merge(EncodeKey(key), value, nil)
+}
+
+// Put implements the Batch interface.
+func (p *pebbleBatch) Put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.writer().Set(EncodeKey(key), value, nil)
+}
+
+// LogData implements the Batch interface.
+func (p *pebbleBatch) LogData(data []byte) error {
+	return p.writer().LogData(data, nil)
+}
+
+// LogLogicalOp implements the Batch interface.
+func (p *pebbleBatch) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op.
+}
+
+// Commit implements the Batch interface.
+func (p *pebbleBatch) Commit(sync bool) error {
+	if p.closed {
+		panic("this batch was already committed")
+	}
+	if p.parentBatch != nil {
+		panic("cannot commit a distinct batch")
+	}
+	p.distinctOpen = false
+
+	opts := pebble.NoSync
+	if sync {
+		opts = pebble.Sync
+	}
+	return p.batch.Commit(opts)
+}
+
+// Distinct implements the Batch interface.
+func (p *pebbleBatch) Distinct() ReadWriter {
+	if p.distinctOpen {
+		panic("distinct batch already open")
+	}
+	p.distinctOpen = true
+	return &pebbleBatch{
+		db:          p.db,
+		batch:       p.batch,
+		writeOnly:   p.writeOnly,
+		parentBatch: p,
+	}
+}
+
+// Empty implements the Batch interface.
+func (p *pebbleBatch) Empty() bool {
+	return p.batch.Count() == 0
+}
+
+// Len implements the Batch interface.
+func (p *pebbleBatch) Len() int {
+	return len(p.batch.Repr())
+}
+
+// Repr implements the Batch interface.
+func (p *pebbleBatch) Repr() []byte {
+	// Make a copy so that the returned slice remains valid after the batch is
+	// modified or closed.
+	repr := p.batch.Repr()
+	reprCopy := make([]byte, len(repr))
+	copy(reprCopy, repr)
+	return reprCopy
+}
@@ -0,0 +1,487 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/pkg/errors"
+)
+
+// pebbleIterator is a wrapper around a pebble.Iterator that implements the
+// Iterator interface.
+type pebbleIterator struct {
+	// handle is the reader the underlying iterator was created from. It is
+	// retained so that the iterator can be recreated when its bounds change.
+	handle  pebble.Reader
+	iter    *pebble.Iterator
+	options pebble.IterOptions
+	// Reusable buffers for the encoded bounds and seek keys.
+	lowerBoundBuf []byte
+	upperBoundBuf []byte
+	keyBuf        []byte
+	// prefix is set for prefix iterators, in which case prefixKey holds the
+	// user key of the last seek. Iteration stops once the iterator moves to a
+	// different user key, mirroring RocksDB's prefix_same_as_start.
+	prefix    bool
+	prefixKey []byte
+	// withStats is set if the caller asked for iterator stats.
+	withStats bool
+	stats     IteratorStats
+}
+
+var _ Iterator = &pebbleIterator{}
+
+// newPebbleIterator creates a new iterator over the given pebble.Reader,
+// which may be a DB, a snapshot or an indexed batch.
+func newPebbleIterator(handle pebble.Reader, opts IterOptions) Iterator {
+	if !opts.Prefix && len(opts.UpperBound) == 0 && len(opts.LowerBound) == 0 {
+		panic("iterator must set prefix or upper bound or lower bound")
+	}
+
+	p := &pebbleIterator{
+		handle:    handle,
+		prefix:    opts.Prefix,
+		withStats: opts.WithStats,
+	}
+	p.setBounds(opts.LowerBound, opts.UpperBound)
+
+	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.MaxTimestampHint != (hlc.Timestamp{}) {
+		// Mirror the table filter installed by libroach/iterator.cc: skip
+		// sstables whose time-bound table properties don't overlap with the
+		// requested timestamp range.
+		minTS := encodeTimestamp(opts.MinTimestampHint)
+		maxTS := encodeTimestamp(opts.MaxTimestampHint)
+		p.options.TableFilter = func(userProps map[string]string) bool {
+			tableMinTS, ok := userProps["crdb.ts.min"]
+			if !ok || len(tableMinTS) == 0 {
+				p.stats.TimeBoundNumSSTs++
+				return true
+			}
+			tableMaxTS, ok := userProps["crdb.ts.max"]
+			if !ok || len(tableMaxTS) == 0 {
+				p.stats.TimeBoundNumSSTs++
+				return true
+			}
+			// If the timestamp range of the table overlaps with the timestamp
+			// range we want to iterate, the table might contain timestamps we
+			// care about.
+			used := bytes.Compare(maxTS, []byte(tableMinTS)) >= 0 &&
+				bytes.Compare(minTS, []byte(tableMaxTS)) <= 0
+			if used {
+				p.stats.TimeBoundNumSSTs++
+			}
+			return used
+		}
+	}
+
+	p.iter = handle.NewIter(&p.options)
+	return p
+}
+
+// encodeTimestamp encodes a timestamp the same way it appears in the suffix
+// of an encoded MVCC key (and in the time-bound table properties), minus the
+// leading sentinel and trailing length bytes.
+func encodeTimestamp(ts hlc.Timestamp) []byte {
+	encoded := EncodeKey(MVCCKey{Key: roachpb.KeyMin, Timestamp: ts})
+	if len(encoded) <= 1 {
+		return nil
+	}
+	// Strip the NUL sentinel and the trailing timestamp length byte.
+	return encoded[1 : len(encoded)-1]
+}
+
+// setBounds encodes the given user-key bounds into the iterator's options.
+func (p *pebbleIterator) setBounds(lowerBound, upperBound roachpb.Key) {
+	p.options.LowerBound = nil
+	p.options.UpperBound = nil
+	if lowerBound != nil {
+		// This is the same as
+		// p.options.LowerBound = EncodeKeyToBuf(p.lowerBoundBuf[:0], MVCCKey{Key: lowerBound}).
+		// Since we are encoding zero-timestamp MVCC keys anyway, we can just
+		// append the NUL byte instead of calling EncodeKey which will do the
+		// same thing.
+		p.lowerBoundBuf = append(p.lowerBoundBuf[:0], lowerBound...)
+		p.lowerBoundBuf = append(p.lowerBoundBuf, 0x00)
+		p.options.LowerBound = p.lowerBoundBuf
+	}
+	if upperBound != nil {
+		p.upperBoundBuf = append(p.upperBoundBuf[:0], upperBound...)
+		p.upperBoundBuf = append(p.upperBoundBuf, 0x00)
+		p.options.UpperBound = p.upperBoundBuf
+	}
+}
+
+// Close implements the Iterator interface.
+func (p *pebbleIterator) Close() {
+	if p.iter == nil {
+		return
+	}
+	if err := p.iter.Close(); err != nil {
+		panic(err)
+	}
+	p.iter = nil
+}
+
+// Seek implements the Iterator interface.
+func (p *pebbleIterator) Seek(key MVCCKey) {
+	if p.prefix {
+		p.prefixKey = append(p.prefixKey[:0], key.Key...)
+	}
+	if len(key.Key) == 0 {
+		p.iter.First()
+		return
+	}
+	p.keyBuf = EncodeKeyToBuf(p.keyBuf[:0], key)
+	p.iter.SeekGE(p.keyBuf)
+}
+
+// SeekReverse implements the Iterator interface.
+func (p *pebbleIterator) SeekReverse(key MVCCKey) {
+	if p.prefix {
+		p.prefixKey = append(p.prefixKey[:0], key.Key...)
+	}
+	if len(key.Key) == 0 {
+		p.iter.Last()
+		return
+	}
+	// Position the iterator at the last key that is <= the provided key.
+	p.keyBuf = EncodeKeyToBuf(p.keyBuf[:0], key)
+	if p.iter.SeekGE(p.keyBuf) && bytes.Equal(p.iter.Key(), p.keyBuf) {
+		return
+	}
+	p.iter.SeekLT(p.keyBuf)
+}
+
+// Valid implements the Iterator interface.
+func (p *pebbleIterator) Valid() (bool, error) {
+	if !p.iter.Valid() {
+		return false, p.iter.Error()
+	}
+	if p.prefix {
+		key, _, ok := enginepb.SplitMVCCKey(p.iter.Key())
+		if !ok || !bytes.Equal(key, p.prefixKey) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Next implements the Iterator interface.
+func (p *pebbleIterator) Next() {
+	p.iter.Next()
+}
+
+// NextKey implements the Iterator interface.
+func (p *pebbleIterator) NextKey() {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return
+	}
+	p.keyBuf = append(p.keyBuf[:0], p.UnsafeKey().Key...)
+
+	for p.iter.Next() {
+		if !bytes.Equal(p.keyBuf, p.UnsafeKey().Key) {
+			break
+		}
+	}
+}
+
+// Prev implements the Iterator interface.
+func (p *pebbleIterator) Prev() {
+	p.iter.Prev()
+}
+
+// PrevKey implements the Iterator interface.
+func (p *pebbleIterator) PrevKey() {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return
+	}
+	curKey := p.Key()
+	for p.iter.Prev() {
+		if !bytes.Equal(curKey.Key, p.UnsafeKey().Key) {
+			break
+		}
+	}
+}
+
+// Key implements the Iterator interface.
+func (p *pebbleIterator) Key() MVCCKey {
+	key := p.UnsafeKey()
+	keyCopy := make([]byte, len(key.Key))
+	copy(keyCopy, key.Key)
+	key.Key = keyCopy
+	return key
+}
+
+// Value implements the Iterator interface.
+func (p *pebbleIterator) Value() []byte {
+	value := p.UnsafeValue()
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	return valueCopy
+}
+
+// ValueProto implements the Iterator interface.
+func (p *pebbleIterator) ValueProto(msg protoutil.Message) error {
+	value := p.UnsafeValue()
+	if len(value) == 0 {
+		return nil
+	}
+	return protoutil.Unmarshal(value, msg)
+}
+
+// UnsafeKey implements the Iterator interface.
+func (p *pebbleIterator) UnsafeKey() MVCCKey {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return MVCCKey{}
+	}
+	mvccKey, err := DecodeMVCCKey(p.iter.Key())
+	if err != nil {
+		return MVCCKey{}
+	}
+	return mvccKey
+}
+
+// UnsafeValue implements the Iterator interface.
+func (p *pebbleIterator) UnsafeValue() []byte {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return nil
+	}
+	return p.iter.Value()
+}
+
+// ComputeStats implements the Iterator interface.
+func (p *pebbleIterator) ComputeStats(
+	start, end MVCCKey, nowNanos int64,
+) (enginepb.MVCCStats, error) {
+	return ComputeStatsGo(p, start, end, nowNanos)
+}
+
+// FindSplitKey implements the Iterator interface. It is a port of
+// MVCCFindSplitKey in libroach/mvcc.cc.
+func (p *pebbleIterator) FindSplitKey(
+	start, end, minSplitKey MVCCKey, targetSize int64,
+) (MVCCKey, error) {
+	const timestampLen = 12
+
+	sizeSoFar := int64(0)
+	bestDiff := int64(math.MaxInt64)
+	bestSplitKey := MVCCKey{}
+	// found indicates that we have found a valid split key that is the best
+	// known so far.
+	found := false
+	prevKey := MVCCKey{}
+
+	encodedEnd := EncodeKey(end)
+	p.keyBuf = EncodeKeyToBuf(p.keyBuf[:0], start)
+	for valid := p.iter.SeekGE(p.keyBuf); valid; valid = p.iter.Next() {
+		if MVCCKeyCompare(p.iter.Key(), encodedEnd) >= 0 {
+			break
+		}
+		mvccKey, err := DecodeMVCCKey(p.iter.Key())
+		if err != nil {
+			return MVCCKey{}, errors.Wrap(err, "unable to decode key")
+		}
+
+		validSplitKey := IsValidSplitKey(mvccKey.Key) && bytes.Compare(mvccKey.Key, minSplitKey.Key) >= 0
+		diff := targetSize - sizeSoFar
+		if diff < 0 {
+			diff = -diff
+		}
+		if validSplitKey && diff < bestDiff {
+			bestSplitKey.Key = append(bestSplitKey.Key[:0], mvccKey.Key...)
+			bestDiff = diff
+			found = true
+		}
+		// If diff is increasing, that means we've passed the ideal split point
+		// and should return the first key that we can. Note that bestSplitKey
+		// may still be empty if we haven't reached minSplitKey yet.
+		if diff > bestDiff && found {
+			break
+		}
+
+		isValue := mvccKey.IsValue()
+		if isValue && bytes.Equal(mvccKey.Key, prevKey.Key) {
+			sizeSoFar += timestampLen + int64(len(p.iter.Value()))
+		} else {
+			sizeSoFar += int64(len(mvccKey.Key) + 1 + len(p.iter.Value()))
+			if isValue {
+				sizeSoFar += timestampLen
+			}
+		}
+
+		prevKey.Key = append(prevKey.Key[:0], mvccKey.Key...)
+	}
+	if err := p.iter.Error(); err != nil {
+		return MVCCKey{}, err
+	}
+
+	if !found {
+		return MVCCKey{}, nil
+	}
+	return bestSplitKey, nil
+}
+
+// MVCCGet implements the Iterator interface.
+func (p *pebbleIterator) MVCCGet(
+	key roachpb.Key, timestamp hlc.Timestamp, opts MVCCGetOptions,
+) (*roachpb.Value, *roachpb.Intent, error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(key) == 0 {
+		return nil, nil, emptyKeyError()
+	}
+
+	mvccScanner := pebbleMVCCScanner{
+		parent:       p.iter,
+		start:        key,
+		ts:           timestamp,
+		maxKeys:      1,
+		txn:          opts.Txn,
+		inconsistent: opts.Inconsistent,
+		tombstones:   opts.Tombstones,
+		ignoreSeq:    opts.IgnoreSequence,
+	}
+	mvccScanner.init()
+	mvccScanner.get()
+
+	if mvccScanner.err != nil {
+		return nil, nil, mvccScanner.err
+	}
+	if mvccScanner.uncertaintyTS != (hlc.Timestamp{}) {
+		return nil, nil, roachpb.NewReadWithinUncertaintyIntervalError(
+			timestamp, mvccScanner.uncertaintyTS, opts.Txn)
+	}
+
+	intents, err := buildScanIntents(mvccScanner.intents.Finish())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.Inconsistent && len(intents) > 0 {
+		return nil, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	var intent *roachpb.Intent
+	if len(intents) > 1 {
+		return nil, nil, errors.Errorf("expected 0 or 1 intents, got %d", len(intents))
+	} else if len(intents) == 1 {
+		intent = &intents[0]
+	}
+
+	if mvccScanner.results.count == 0 {
+		return nil, intent, nil
+	}
+
+	mvccKey, rawValue, _, err := MVCCScanDecodeKeyValue(mvccScanner.results.repr)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := &roachpb.Value{
+		RawBytes:  rawValue,
+		Timestamp: mvccKey.Timestamp,
+	}
+	return value, intent, nil
+}
+
+// MVCCScan implements the Iterator interface.
+func (p *pebbleIterator) MVCCScan(
+	start, end roachpb.Key, max int64, timestamp hlc.Timestamp, opts MVCCScanOptions,
+) (kvData []byte, numKVs int64, resumeSpan *roachpb.Span, intents []roachpb.Intent, err error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, 0, nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(end) == 0 {
+		return nil, 0, nil, nil, emptyKeyError()
+	}
+	if max == 0 {
+		resumeSpan = &roachpb.Span{Key: start, EndKey: end}
+		return nil, 0, resumeSpan, nil, nil
+	}
+
+	mvccScanner := pebbleMVCCScanner{
+		parent:       p.iter,
+		reverse:      opts.Reverse,
+		start:        start,
+		ts:           timestamp,
+		maxKeys:      max,
+		txn:          opts.Txn,
+		inconsistent: opts.Inconsistent,
+		tombstones:   opts.Tombstones,
+		ignoreSeq:    opts.IgnoreSequence,
+	}
+	if opts.Reverse {
+		// Reverse scans begin at the end of the span.
+		mvccScanner.start = end
+	}
+	mvccScanner.init()
+	mvccScanner.scan()
+
+	if mvccScanner.err != nil {
+		return nil, 0, nil, nil, mvccScanner.err
+	}
+	if mvccScanner.uncertaintyTS != (hlc.Timestamp{}) {
+		return nil, 0, nil, nil, roachpb.NewReadWithinUncertaintyIntervalError(
+			timestamp, mvccScanner.uncertaintyTS, opts.Txn)
+	}
+
+	kvData = mvccScanner.results.repr
+	numKVs = mvccScanner.results.count
+
+	if resumeKey := mvccScanner.resumeKey; resumeKey != nil {
+		if opts.Reverse {
+			resumeSpan = &roachpb.Span{Key: start, EndKey: resumeKey.Next()}
+		} else {
+			resumeSpan = &roachpb.Span{Key: resumeKey, EndKey: end}
+		}
+	}
+
+	intents, err = buildScanIntents(mvccScanner.intents.Finish())
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	if !opts.Inconsistent && len(intents) > 0 {
+		// When encountering intents during a consistent scan we still need to
+		// return the resume key.
+		return nil, 0, resumeSpan, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	return kvData, numKVs, resumeSpan, intents, nil
+}
+
+// SetUpperBound implements the Iterator interface. The underlying
+// pebble.Iterator is recreated with the new bound.
+func (p *pebbleIterator) SetUpperBound(upperBound roachpb.Key) {
+	var lowerBound roachpb.Key
+	if p.options.LowerBound != nil {
+		lowerBound = append(roachpb.Key(nil), p.options.LowerBound[:len(p.options.LowerBound)-1]...)
+	}
+	p.setBounds(lowerBound, upperBound)
+	if err := p.iter.Close(); err != nil {
+		panic(err)
+	}
+	p.iter = p.handle.NewIter(&p.options)
+}
+
+// Stats implements the Iterator interface. Only TimeBoundNumSSTs is tracked;
+// Pebble does not expose a count of skipped deletion tombstones.
+func (p *pebbleIterator) Stats() IteratorStats {
+	if !p.withStats {
+		return IteratorStats{}
+	}
+	return p.stats
+}
@@ -0,0 +1,623 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/pkg/errors"
+)
+
+// maxItersBeforeSeek is the number of calls to iter.{Next,Prev}() to perform
+// when looking for the next/prev key or a particular version before calling
+// iter.Seek(). Note that pebbleMVCCScanner makes this number adaptive. It
+// starts with a value of maxItersBeforeSeek/2 and increases the value every
+// time a call to iter.{Next,Prev}() successfully finds the desired next key.
+// It decrements the value whenever a call to iter.Seek() occurs. The adaptive
+// iters-before-seek value is constrained to the range [1,maxItersBeforeSeek].
+//
+// This mirrors kMaxItersBeforeSeek in libroach/mvcc.h.
+const maxItersBeforeSeek = 10
+
+// pebbleResults holds the key/value pairs accumulated by a scan, encoded in
+// the same format as libroach's chunkedBuffer so that the results can be
+// consumed by MVCCScanDecodeKeyValue.
+type pebbleResults struct {
+	count int64
+	repr  []byte
+}
+
+// put appends a key/value pair to the results. The key and value lengths are
+// encoded as two little endian uint32s, followed by the key and value.
+func (p *pebbleResults) put(key []byte, value []byte) {
+	var lenBuf [8]byte
+	binary.LittleEndian.PutUint32(lenBuf[0:4], uint32(len(value)))
+	binary.LittleEndian.PutUint32(lenBuf[4:8], uint32(len(key)))
+	p.repr = append(p.repr, lenBuf[:]...)
+	p.repr = append(p.repr, key...)
+	p.repr = append(p.repr, value...)
+	p.count++
+}
+
+func (p *pebbleResults) clear() {
+	*p = pebbleResults{}
+}
+
+// pebbleMVCCScanner implements the MVCCGet, MVCCScan and MVCCReverseScan
+// operations on top of a pebble.Iterator. It is a port of the mvccScanner in
+// libroach/mvcc.h and the two must be kept in sync; see the comments there
+// for a description of the MVCC data layout and the various cases handled by
+// getAndAdvance.
+//
+// WARNING: Do not use parent.Key() or parent.Value() directly, use curRawKey,
+// curKey and curValue instead. In order to efficiently support reverse scans,
+// we maintain a single entry buffer that allows "peeking" at the previous
+// key. But the operation of "peeking" causes parent.{Key,Value}() to point to
+// different data than what the scanner considers the "current" key/value.
+type pebbleMVCCScanner struct {
+	parent  *pebble.Iterator
+	reverse bool
+	// start is the key the scan begins at. For reverse scans this is the
+	// (exclusive) end of the scanned span.
+	start roachpb.Key
+	// isGet is set for MVCCGet, in which case no key other than start may be
+	// returned.
+	isGet   bool
+	ts      hlc.Timestamp
+	maxKeys int64
+	// Transaction specific fields.
+	txn              *roachpb.Transaction
+	checkUncertainty bool
+	inconsistent     bool
+	tombstones       bool
+	ignoreSeq        bool
+	// Results.
+	results       pebbleResults
+	intents       RocksDBBatchBuilder
+	resumeKey     roachpb.Key
+	uncertaintyTS hlc.Timestamp
+	err           error
+	// Scratch state.
+	keyBuf   []byte
+	seekBuf  []byte
+	savedBuf []byte
+	peeked   bool
+	meta     enginepb.MVCCMetadata
+	// curRawKey holds either parent.Key() or the saved value of parent.Key()
+	// if we've peeked at the previous key (and peeked is true).
+	curRawKey []byte
+	// curKey is the decoded MVCC key, separated from the timestamp suffix.
+	curKey roachpb.Key
+	// curValue holds either parent.Value() or the saved value of
+	// parent.Value() if we've peeked at the previous key (and peeked is true).
+	curValue []byte
+	// curTS is the timestamp for a decoded MVCC key.
+	curTS           hlc.Timestamp
+	itersBeforeSeek int
+}
+
+// init sets up the transactional state of the scanner. It must be called after
+// the txn and ts fields have been set and before get or scan.
+func (p *pebbleMVCCScanner) init() {
+	p.itersBeforeSeek = maxItersBeforeSeek / 2
+	if p.txn != nil {
+		p.checkUncertainty = p.ts.Less(p.txn.MaxTimestamp)
+	}
+}
+
+// get seeks to the start key exactly once and adds one KV to the result set.
+func (p *pebbleMVCCScanner) get() {
+	p.isGet = true
+	if !p.iterSeek(MVCCKey{Key: p.start}) {
+		return
+	}
+	p.getAndAdvance()
+}
+
+// scan iterates until maxKeys records are in results, or the underlying
+// iterator is exhausted, or an error is encountered.
+func (p *pebbleMVCCScanner) scan() {
+	if p.reverse {
+		if !p.iterSeekReverse(MVCCKey{Key: p.start}) {
+			return
+		}
+	} else {
+		if !p.iterSeek(MVCCKey{Key: p.start}) {
+			return
+		}
+	}
+
+	for p.getAndAdvance() {
+	}
+
+	if p.results.count == p.maxKeys && p.advanceKey() {
+		p.resumeKey = append(roachpb.Key(nil), p.curKey...)
+	}
+}
+
+func (p *pebbleMVCCScanner) getFromIntentHistory() bool {
+	intentHistory := p.meta.IntentHistory
+	// Look for the intent with the sequence number less than or equal to the
+	// read sequence. To do so, search using sort.Search to find the first
+	// intent that has a sequence number greater than the read sequence, and
+	// then return the previous value.
+	upIdx := sort.Search(len(intentHistory), func(i int) bool {
+		return intentHistory[i].Sequence > p.txn.Sequence
+	})
+	if upIdx == 0 {
+		// It is possible that no intent exists such that the sequence is less
+		// than the read sequence. In this case, we cannot read a value from the
+		// intent history.
+		return false
+	}
+	intent := &intentHistory[upIdx-1]
+	if len(intent.Value) > 0 || p.tombstones {
+		p.results.put(p.curRawKey, intent.Value)
+	}
+	return true
+}
+
+func (p *pebbleMVCCScanner) uncertaintyError(ts hlc.Timestamp) bool {
+	p.uncertaintyTS = ts
+	p.results.clear()
+	p.intents.reset()
+	return false
+}
+
+func (p *pebbleMVCCScanner) setError(err error) bool {
+	p.err = err
+	return false
+}
+
+// getAndAdvance emits or skips the current key/value pair and advances the
+// iterator. Returns true if the scan should continue. The numbered cases
+// match those in libroach/mvcc.h.
+func (p *pebbleMVCCScanner) getAndAdvance() bool {
+	if p.curTS != (hlc.Timestamp{}) {
+		if !p.ts.Less(p.curTS) {
+			// 1. Fast path: there is no intent and our read timestamp is newer
+			// than the most recent version's timestamp.
+			return p.addAndAdvance(p.curValue)
+		}
+
+		if p.checkUncertainty {
+			// 2. Our txn's read timestamp is less than the max timestamp seen by
+			// the txn. We need to check for clock uncertainty errors.
+			if !p.txn.MaxTimestamp.Less(p.curTS) {
+				return p.uncertaintyError(p.curTS)
+			}
+			// Delegate to seekVersion to return a clock uncertainty error if
+			// there are any more versions above txn.MaxTimestamp.
+			return p.seekVersion(p.txn.MaxTimestamp, true)
+		}
+
+		// 3. Our txn's read timestamp is greater than or equal to the max
+		// timestamp seen by the txn so clock uncertainty checks are
+		// unnecessary. We need to seek to the desired version of the value
+		// (i.e. one with a timestamp earlier than our read timestamp).
+		return p.seekVersion(p.ts, false)
+	}
+
+	if len(p.curValue) == 0 {
+		return p.setError(errors.Errorf("zero-length mvcc metadata"))
+	}
+	if err := protoutil.Unmarshal(p.curValue, &p.meta); err != nil {
+		return p.setError(errors.Wrap(err, "unable to decode MVCCMetadata"))
+	}
+
+	if p.meta.RawBytes != nil {
+		// 4. Emit immediately if the value is inline.
+		return p.addAndAdvance(p.meta.RawBytes)
+	}
+
+	if p.meta.Txn == nil {
+		return p.setError(errors.Errorf("intent without transaction"))
+	}
+
+	ownIntent := p.txn != nil && p.meta.Txn.ID == p.txn.ID
+	metaTS := hlc.Timestamp(p.meta.Timestamp)
+	// metaTS is the timestamp of an intent value, which we may or may not end
+	// up ignoring, depending on factors codified below. If we do ignore the
+	// intent then we want to read at a lower timestamp that's strictly below
+	// the intent timestamp (to skip the intent), but also does not exceed our
+	// read timestamp (to avoid erroneously picking up future committed
+	// values); this timestamp is prevTS.
+	prevTS := p.ts
+	if !p.ts.Less(metaTS) {
+		prevTS = metaTS.Prev()
+	}
+
+	if p.ts.Less(metaTS) && !ownIntent {
+		// 5. The key contains an intent, but we're reading before the intent.
+		// Seek to the desired version. Note that if we own the intent (i.e.
+		// we're reading transactionally) we want to read the intent regardless
+		// of our read timestamp and fall into case 8 below.
+		return p.seekVersion(p.ts, false)
+	}
+
+	if p.inconsistent {
+		// 6. The key contains an intent and we're doing an inconsistent read at
+		// a timestamp newer than the intent. We ignore the intent by insisting
+		// that the timestamp we're reading at is a historical timestamp < the
+		// intent timestamp. However, we return the intent separately; the
+		// caller may want to resolve it.
+		if p.results.count == p.maxKeys {
+			// We've already retrieved the desired number of keys and now we're
+			// adding the resume key. We don't want to add the intent here as the
+			// intents should only correspond to KVs that lie before the resume
+			// key.
+			return false
+		}
+		p.intents.Put(MVCCKey{Key: p.curKey}, p.curValue)
+		return p.seekVersion(prevTS, false)
+	}
+
+	if !ownIntent {
+		// 7. The key contains an intent which was not written by our
+		// transaction and our read timestamp is newer than that of the intent.
+		// Note that this will trigger an error higher up. We continue scanning
+		// so that we can return all of the intents in the scan range.
+		p.intents.Put(MVCCKey{Key: p.curKey}, p.curValue)
+		return p.advanceKey()
+	}
+
+	if p.txn.Epoch == p.meta.Txn.Epoch {
+		if p.ignoreSeq || p.txn.Sequence >= p.meta.Txn.Sequence {
+			// 8. We're reading our own txn's intent at an equal or higher
+			// sequence. Note that we read at the intent timestamp, not at our
+			// read timestamp as the intent timestamp may have been pushed
+			// forward by another transaction. Txn's always need to read their
+			// own writes.
+			return p.seekVersion(metaTS, false)
+		}
+
+		// 9. We're reading our own txn's intent at a lower sequence than is
+		// currently present in the intent. This means the intent we're seeing
+		// was written at a higher sequence than the read and that there may or
+		// may not be earlier versions of the intent (with lower sequence
+		// numbers) that we should read. If there exists a value in the intent
+		// history that has a sequence number equal to or less than the read
+		// sequence, read that value.
+		if p.getFromIntentHistory() {
+			return p.advanceKey()
+		}
+		// 10. If no value in the intent history has a sequence number equal to
+		// or less than the read, we must ignore the intents laid down by the
+		// transaction all together. We ignore the intent by insisting that the
+		// timestamp we're reading at is a historical timestamp < the intent
+		// timestamp.
+		return p.seekVersion(prevTS, false)
+	}
+
+	if p.txn.Epoch < p.meta.Txn.Epoch {
+		// 11. We're reading our own txn's intent but the current txn has an
+		// earlier epoch than the intent. Return an error so that the earlier
+		// incarnation of our transaction aborts (presumably this is some
+		// operation that was retried).
+		return p.setError(errors.Errorf("failed to read with epoch %d due to a write intent with epoch %d",
+			p.txn.Epoch, p.meta.Txn.Epoch))
+	}
+
+	// 12. We're reading our own txn's intent but the current txn has a later
+	// epoch than the intent. This can happen if the txn was restarted and an
+	// earlier iteration wrote the value we're now reading. In this case, we
+	// ignore the intent and read the previous value as if the transaction were
+	// starting fresh.
+	return p.seekVersion(prevTS, false)
+}
+
+// nextKey advances the iterator to point to the next MVCC key greater than
+// curKey. Returns false if the iterator is exhausted or an error occurs.
+func (p *pebbleMVCCScanner) nextKey() bool {
+	p.keyBuf = append(p.keyBuf[:0], p.curKey...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		if !p.iterNext() {
+			return false
+		}
+		if !bytes.Equal(p.curKey, p.keyBuf) {
+			p.incrementItersBeforeSeek()
+			return true
+		}
+	}
+
+	// We're pointed at a different version of the same key. Fall back to
+	// seeking to the next key.
+	p.decrementItersBeforeSeek()
+	return p.iterSeek(MVCCKey{Key: roachpb.Key(p.keyBuf).Next()})
+}
+
+// backwardLatestVersion backs up the iterator to the latest version for the
+// specified key. The parameter i is used to maintain the iteration count
+// between the loop here and the caller (usually prevKey). Returns false if an
+// error occurred.
+func (p *pebbleMVCCScanner) backwardLatestVersion(key []byte, i int) bool {
+	p.keyBuf = append(p.keyBuf[:0], key...)
+
+	for ; i < p.itersBeforeSeek; i++ {
+		peekedKey, ok := p.iterPeekPrev()
+		if !ok {
+			return false
+		}
+		if !bytes.Equal(peekedKey, p.keyBuf) {
+			// The key changed which means the current key is the latest version.
+			p.incrementItersBeforeSeek()
+			return true
+		}
+		if !p.iterPrev() {
+			return false
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	return p.iterSeek(MVCCKey{Key: p.keyBuf})
+}
+
+// prevKey backs up the iterator to point to the prev MVCC key less than the
+// specified key. Returns false if the iterator is exhausted or an error
+// occurs.
+func (p *pebbleMVCCScanner) prevKey(key []byte) bool {
+	p.keyBuf = append(p.keyBuf[:0], key...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		peekedKey, ok := p.iterPeekPrev()
+		if !ok {
+			return false
+		}
+		if peekedKey == nil {
+			// iterPeekPrev may return true even when it did not find a key. In
+			// that case there is not going to be any prev key, so we are done.
+			return false
+		}
+		if !bytes.Equal(peekedKey, p.keyBuf) {
+			return p.backwardLatestVersion(peekedKey, i+1)
+		}
+		if !p.iterPrev() {
+			return false
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	return p.iterSeekReverse(MVCCKey{Key: p.keyBuf})
+}
+
+// advanceKey advances the iterator to point to the next MVCC key. Returns
+// false if the iterator is exhausted or an error occurs.
+func (p *pebbleMVCCScanner) advanceKey() bool {
+	if p.isGet {
+		return false
+	}
+	if p.reverse {
+		return p.prevKey(p.curKey)
+	}
+	return p.nextKey()
+}
+
+func (p *pebbleMVCCScanner) advanceKeyAtEnd() bool {
+	if p.reverse {
+		// Iterating to the next key might have caused the iterator to reach the
+		// end of the key space. If that happens, back up to the very last key.
+		p.clearPeeked()
+		p.parent.Last()
+		if !p.updateCurrent() {
+			return false
+		}
+		return p.advanceKey()
+	}
+	// We've reached the end of the iterator and there is nothing left to do.
+	return false
+}
+
+func (p *pebbleMVCCScanner) advanceKeyAtNewKey(key []byte) bool {
+	if p.reverse {
+		// We've advanced to the next key but need to move back to the previous
+		// key.
+		return p.prevKey(key)
+	}
+	// We're already at the new key so there is nothing to do.
+	return true
+}
+
+func (p *pebbleMVCCScanner) addAndAdvance(val []byte) bool {
+	// Don't include deleted versions (len(val) == 0), unless we've been
+	// instructed to include tombstones in the results.
+	if len(val) > 0 || p.tombstones {
+		p.results.put(p.curRawKey, val)
+		if p.results.count == p.maxKeys {
+			return false
+		}
+	}
+	return p.advanceKey()
+}
+
+// seekVersion advances the iterator to point to an MVCC version for the
+// specified key that is earlier than ts. Returns false if the iterator is
+// exhausted or an error occurs. On success, advances the iterator to the next
+// key. If checkUncertainty is true, then observing any version of the desired
+// key with a timestamp larger than our read timestamp results in an
+// uncertainty error.
+func (p *pebbleMVCCScanner) seekVersion(ts hlc.Timestamp, checkUncertainty bool) bool {
+	p.keyBuf = append(p.keyBuf[:0], p.curKey...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		if !p.iterNext() {
+			return p.advanceKeyAtEnd()
+		}
+		if !bytes.Equal(p.curKey, p.keyBuf) {
+			p.incrementItersBeforeSeek()
+			return p.advanceKeyAtNewKey(p.keyBuf)
+		}
+		if !ts.Less(p.curTS) {
+			p.incrementItersBeforeSeek()
+			if checkUncertainty && p.ts.Less(p.curTS) {
+				return p.uncertaintyError(p.curTS)
+			}
+			return p.addAndAdvance(p.curValue)
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	if !p.iterSeek(MVCCKey{Key: p.keyBuf, Timestamp: ts}) {
+		return p.advanceKeyAtEnd()
+	}
+	if !bytes.Equal(p.curKey, p.keyBuf) {
+		return p.advanceKeyAtNewKey(p.keyBuf)
+	}
+	if !ts.Less(p.curTS) {
+		if checkUncertainty && p.ts.Less(p.curTS) {
+			return p.uncertaintyError(p.curTS)
+		}
+		return p.addAndAdvance(p.curValue)
+	}
+	return p.advanceKey()
+}
+
+func (p *pebbleMVCCScanner) updateCurrent() bool {
+	if !p.parent.Valid() {
+		if err := p.parent.Error(); err != nil {
+			return p.setError(err)
+		}
+		return false
+	}
+
+	p.curRawKey = p.parent.Key()
+	p.curValue = p.parent.Value()
+	key, err := DecodeMVCCKey(p.curRawKey)
+	if err != nil {
+		return p.setError(errors.Wrap(err, "failed to split mvcc key"))
+	}
+	p.curKey = key.Key
+	p.curTS = key.Timestamp
+	if p.isGet && !bytes.Equal(p.curKey, p.start) {
+		// There is no prefix iteration, so MVCCGet must explicitly stop once
+		// the iterator moves past the requested key.
+		return false
+	}
+	return true
+}
+
+// iterSeek positions the iterator at the first key that is greater than or
+// equal to key.
+func (p *pebbleMVCCScanner) iterSeek(key MVCCKey) bool {
+	p.clearPeeked()
+	p.seekBuf = EncodeKeyToBuf(p.seekBuf[:0], key)
+	p.parent.SeekGE(p.seekBuf)
+	return p.updateCurrent()
+}
+
+// iterSeekReverse positions the iterator at the last key that is less than
+// key.
+func (p *pebbleMVCCScanner) iterSeekReverse(key MVCCKey) bool {
+	p.clearPeeked()
+	p.seekBuf = EncodeKeyToBuf(p.seekBuf[:0], key)
+	p.parent.SeekLT(p.seekBuf)
+	if !p.updateCurrent() {
+		return false
+	}
+	if p.curTS == (hlc.Timestamp{}) {
+		// We landed on an intent or inline value.
+		return true
+	}
+
+	// We landed on a versioned value, we need to back up to find the latest
+	// version.
+	return p.backwardLatestVersion(p.curKey, 0)
+}
+
+func (p *pebbleMVCCScanner) iterNext() bool {
+	if p.reverse && p.peeked {
+		// If we had peeked at the previous entry, we need to advance the
+		// iterator twice to get to the real next entry.
+		p.peeked = false
+		if !p.parent.Next() {
+			return p.updateCurrent()
+		}
+	}
+	p.parent.Next()
+	return p.updateCurrent()
+}
+
+func (p *pebbleMVCCScanner) iterPrev() bool {
+	if p.peeked {
+		p.peeked = false
+		return p.updateCurrent()
+	}
+	p.parent.Prev()
+	return p.updateCurrent()
+}
+
+// iterPeekPrev "peeks" at the previous key before the current iterator
+// position. It returns the decoded key (without timestamp) of the previous
+// entry, or a nil key if there is no such entry.
+func (p *pebbleMVCCScanner) iterPeekPrev() ([]byte, bool) {
+	if !p.peeked {
+		p.peeked = true
+		// We need to save a copy of the current iterator key and value and
+		// adjust curRawKey, curKey and curValue to point to this saved data. We
+		// use a single buffer for this purpose: savedBuf.
+		p.savedBuf = append(p.savedBuf[:0], p.curRawKey...)
+		p.savedBuf = append(p.savedBuf, p.curValue...)
+		p.curRawKey = p.savedBuf[:len(p.curRawKey)]
+		p.curValue = p.savedBuf[len(p.curRawKey):]
+		key, _, ok := enginepb.SplitMVCCKey(p.curRawKey)
+		if !ok {
+			return nil, p.setError(errors.Errorf("failed to split mvcc key"))
+		}
+		p.curKey = key
+
+		// With the current iterator state saved we can move the iterator to
+		// the previous entry.
+		if !p.parent.Prev() {
+			// Peeking at the previous key should never leave the iterator
+			// invalid. Instead, we seek back to the first key and return a nil
+			// peeked key. Note that this prevents using reverse scan to scan to
+			// the empty key.
+			p.peeked = false
+			p.parent.First()
+			return nil, p.updateCurrent()
+		}
+	}
+
+	peekedKey, _, ok := enginepb.SplitMVCCKey(p.parent.Key())
+	if !ok {
+		return nil, p.setError(errors.Errorf("failed to split mvcc key"))
+	}
+	return peekedKey, true
+}
+
+// clearPeeked clears the peeked flag. This should be called before any
+// iterator movement operations on p.parent.
+func (p *pebbleMVCCScanner) clearPeeked() {
+	if p.reverse {
+		p.peeked = false
+	}
+}
+
+func (p *pebbleMVCCScanner) incrementItersBeforeSeek() {
+	p.itersBeforeSeek++
+	if p.itersBeforeSeek > maxItersBeforeSeek {
+		p.itersBeforeSeek = maxItersBeforeSeek
+	}
+}
+
+func (p *pebbleMVCCScanner) decrementItersBeforeSeek() {
+	p.itersBeforeSeek--
+	if p.itersBeforeSeek < 1 {
+		p.itersBeforeSeek = 1
+	}
+}
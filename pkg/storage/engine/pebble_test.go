@@ -0,0 +1,272 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPebbleBasic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newPebbleInMem(roachpb.Attributes{}, 1<<20)
+	defer p.Close()
+
+	keyA := MakeMVCCMetadataKey(roachpb.Key("a"))
+	keyB := MakeMVCCMetadataKey(roachpb.Key("b"))
+	keyC := MakeMVCCMetadataKey(roachpb.Key("c"))
+
+	require.NoError(t, p.Put(keyA, []byte("1")))
+	require.NoError(t, p.Put(keyB, []byte("2")))
+	require.NoError(t, p.Put(keyC, []byte("3")))
+
+	val, err := p.Get(keyB)
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), val)
+
+	// Reads from a batch see the batch's own writes, but the engine does not
+	// until the batch is committed.
+	b := p.NewBatch()
+	require.NoError(t, b.Clear(keyB))
+	val, err = b.Get(keyB)
+	require.NoError(t, err)
+	require.Nil(t, val)
+	val, err = p.Get(keyB)
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), val)
+	require.NoError(t, b.Commit(false /* sync */))
+	b.Close()
+
+	val, err = p.Get(keyB)
+	require.NoError(t, err)
+	require.Nil(t, val)
+
+	// A batch repr can be applied to the engine directly.
+	b = p.NewWriteOnlyBatch()
+	require.NoError(t, b.Put(keyB, []byte("4")))
+	repr := b.Repr()
+	b.Close()
+	require.NoError(t, p.ApplyBatchRepr(repr, false /* sync */))
+
+	kvs, err := Scan(p, keyA, MakeMVCCMetadataKey(roachpb.KeyMax), 0)
+	require.NoError(t, err)
+	require.Equal(t, []MVCCKeyValue{
+		{Key: keyA, Value: []byte("1")},
+		{Key: keyB, Value: []byte("4")},
+		{Key: keyC, Value: []byte("3")},
+	}, kvs)
+
+	// ClearRange removes [a, c).
+	require.NoError(t, p.ClearRange(keyA, keyC))
+	kvs, err = Scan(p, keyA, MakeMVCCMetadataKey(roachpb.KeyMax), 0)
+	require.NoError(t, err)
+	require.Equal(t, []MVCCKeyValue{{Key: keyC, Value: []byte("3")}}, kvs)
+}
+
+func TestPebbleIngestExternalFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	p := newPebbleInMem(roachpb.Attributes{}, 1<<20)
+	defer p.Close()
+
+	sst, err := MakeRocksDBSstFileWriter()
+	require.NoError(t, err)
+	defer sst.Close()
+
+	ts := hlc.Timestamp{WallTime: 1}
+	var expected []MVCCKeyValue
+	for i := 0; i < 10; i++ {
+		kv := MVCCKeyValue{
+			Key:   MVCCKey{Key: roachpb.Key(fmt.Sprintf("key%02d", i)), Timestamp: ts},
+			Value: []byte(fmt.Sprintf("value%02d", i)),
+		}
+		require.NoError(t, sst.Put(kv.Key, kv.Value))
+		expected = append(expected, kv)
+	}
+	data, err := sst.Finish()
+	require.NoError(t, err)
+
+	path := p.GetAuxiliaryDir() + "/ingest.sst"
+	f, err := p.OpenFile(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Append(data))
+	require.NoError(t, f.Close())
+
+	p.PreIngestDelay(ctx)
+	require.NoError(t, p.IngestExternalFiles(ctx, []string{path}, true, true))
+
+	kvs, err := Scan(p, MakeMVCCMetadataKey(roachpb.KeyMin), MakeMVCCMetadataKey(roachpb.KeyMax), 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, kvs)
+}
+
+// TestPebbleMVCCScanParity verifies that the Go port of the MVCC scanner used
+// by the Pebble engine returns exactly the same results as the C++ scanner
+// used by RocksDB.
+func TestPebbleMVCCScanParity(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	rocks := NewInMem(roachpb.Attributes{}, 1<<20)
+	defer rocks.Close()
+	peb := newPebbleInMem(roachpb.Attributes{}, 1<<20)
+	defer peb.Close()
+
+	ts := func(w int64) hlc.Timestamp { return hlc.Timestamp{WallTime: w} }
+	txn := makeTxn(*txn1, ts(30))
+
+	for _, eng := range []Engine{rocks, peb} {
+		for i, k := range []string{"a", "b", "c", "d", "e"} {
+			for w := int64(1); w <= 3; w++ {
+				v := roachpb.MakeValueFromString(fmt.Sprintf("%s-%d", k, w))
+				require.NoError(t, MVCCPut(ctx, eng, nil, roachpb.Key(k), ts(w*int64(i+1)), v, nil))
+			}
+		}
+		// A deletion tombstone on "b" and an intent on "d".
+		require.NoError(t, MVCCDelete(ctx, eng, nil, roachpb.Key("b"), ts(20), nil))
+		require.NoError(t, MVCCPut(
+			ctx, eng, nil, roachpb.Key("d"), txn.Timestamp, roachpb.MakeValueFromString("intent"), txn,
+		))
+		// An inline value.
+		require.NoError(t, MVCCPut(
+			ctx, eng, nil, roachpb.Key("f"), hlc.Timestamp{}, roachpb.MakeValueFromString("inline"), nil,
+		))
+	}
+
+	type result struct {
+		kvs     []roachpb.KeyValue
+		resume  *roachpb.Span
+		intents []roachpb.Intent
+		err     string
+	}
+	scan := func(
+		eng Engine, max int64, readTS hlc.Timestamp, opts MVCCScanOptions,
+	) result {
+		kvs, resume, intents, err := MVCCScan(
+			ctx, eng, roachpb.Key("a"), roachpb.Key("z"), max, readTS, opts)
+		var r result
+		r.kvs, r.resume, r.intents = kvs, resume, intents
+		if err != nil {
+			r.err = err.Error()
+		}
+		return r
+	}
+
+	for _, readTS := range []hlc.Timestamp{ts(1), ts(3), ts(5), ts(10), ts(25), ts(35)} {
+		for _, max := range []int64{1, 2, 100} {
+			for _, reverse := range []bool{false, true} {
+				for _, inconsistent := range []bool{false, true} {
+					for _, tombstones := range []bool{false, true} {
+						opts := MVCCScanOptions{
+							Inconsistent: inconsistent,
+							Tombstones:   tombstones,
+							Reverse:      reverse,
+						}
+						name := fmt.Sprintf("ts=%s/max=%d/%+v", readTS, max, opts)
+						t.Run(name, func(t *testing.T) {
+							require.Equal(t, scan(rocks, max, readTS, opts), scan(peb, max, readTS, opts))
+						})
+					}
+				}
+			}
+		}
+	}
+
+	// Transactional reads see their own intent.
+	txnOpts := MVCCScanOptions{Txn: txn}
+	require.Equal(t, scan(rocks, 100, txn.Timestamp, txnOpts), scan(peb, 100, txn.Timestamp, txnOpts))
+
+	for _, eng := range []Engine{rocks, peb} {
+		val, intent, err := MVCCGet(ctx, eng, roachpb.Key("d"), ts(10), MVCCGetOptions{Inconsistent: true})
+		require.NoError(t, err)
+		require.NotNil(t, intent)
+		require.Equal(t, "d-2", string(mustGetBytes(t, val)))
+
+		val, _, err = MVCCGet(ctx, eng, roachpb.Key("c"), ts(5), MVCCGetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "c-1", string(mustGetBytes(t, val)))
+
+		val, _, err = MVCCGet(ctx, eng, roachpb.Key("cc"), ts(5), MVCCGetOptions{})
+		require.NoError(t, err)
+		require.Nil(t, val)
+	}
+}
+
+func mustGetBytes(t *testing.T, val *roachpb.Value) []byte {
+	t.Helper()
+	require.NotNil(t, val)
+	b, err := val.GetBytes()
+	require.NoError(t, err)
+	return b
+}
+
+// TestPebbleTimeBoundIterator verifies that sstables which don't overlap with
+// an iterator's timestamp hints are skipped and counted in the iterator stats.
+func TestPebbleTimeBoundIterator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newPebbleInMem(roachpb.Attributes{}, 1<<20)
+	defer p.Close()
+
+	// Write two sstables with disjoint timestamps.
+	for _, w := range []int64{1, 10} {
+		key := MVCCKey{Key: roachpb.Key(fmt.Sprintf("key%02d", w)), Timestamp: hlc.Timestamp{WallTime: w}}
+		require.NoError(t, p.Put(key, []byte("value")))
+		require.NoError(t, p.Flush())
+	}
+
+	iter := p.NewIterator(IterOptions{
+		UpperBound:       roachpb.KeyMax,
+		MinTimestampHint: hlc.Timestamp{WallTime: 5},
+		MaxTimestampHint: hlc.Timestamp{WallTime: 15},
+		WithStats:        true,
+	})
+	defer iter.Close()
+
+	var keys []string
+	for iter.Seek(MakeMVCCMetadataKey(roachpb.KeyMin)); ; iter.Next() {
+		ok, err := iter.Valid()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		keys = append(keys, string(iter.UnsafeKey().Key))
+	}
+	require.Equal(t, []string{"key10"}, keys)
+	require.Equal(t, 1, iter.Stats().TimeBoundNumSSTs)
+}
+
+func TestEngineTypeFlag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var e enginepb.EngineType
+	require.Equal(t, "rocksdb", e.String())
+	require.NoError(t, e.Set("pebble"))
+	require.Equal(t, enginepb.EngineTypePebble, e)
+	require.Error(t, e.Set("leveldb"))
+}
+
+func TestMVCCComparerFormatKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	key := MVCCKey{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: 1, Logical: 2}}
+	require.Equal(t, key.String(), fmt.Sprint(MVCCComparer.FormatKey(EncodeKey(key))))
+	require.Contains(t, fmt.Sprint(MVCCComparer.FormatKey([]byte{})), "invalid key")
+}
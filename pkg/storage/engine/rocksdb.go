@@ -482,6 +482,9 @@ type RocksDBConfig struct {
 	WarnLargeBatchThreshold time.Duration
 	// Settings instance for cluster-wide knobs.
 	Settings *cluster.Settings
+	// DiskStallDetector, if set, watches the writes and WAL syncs of the
+	// instance.
+	DiskStallDetector *DiskStallDetector
 	// UseFileRegistry is true if the file registry is needed (eg: encryption-at-rest).
 	// This may force the store version to versionFileRegistry if currently lower.
 	UseFileRegistry bool
@@ -711,7 +714,9 @@ func (r *RocksDB) syncLoop() {
 		// corruption. So, we must not call `DBSyncWAL` again after it has
 		// failed once.
 		if r.cfg.Dir != "" && err == nil {
-			err = statusToError(C.DBSyncWAL(r.rdb))
+			err = r.cfg.DiskStallDetector.watch(r.cfg.Dir, "sync", func() error {
+				return statusToError(C.DBSyncWAL(r.rdb))
+			})
 			lastSync = timeutil.Now()
 		}
 
@@ -873,16 +878,22 @@ func (r *RocksDB) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)
 
 // Capacity queries the underlying file system for disk capacity information.
 func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(r.cfg.Dir, r.cfg.MaxSizeBytes)
+}
+
+// computeCapacity returns capacity details for an engine stored in dir whose
+// size is limited to maxSizeBytes (0 means unlimited). It is shared by all
+// on-disk engine implementations.
+func computeCapacity(dir string, maxSizeBytes int64) (roachpb.StoreCapacity, error) {
 	fileSystemUsage := gosigar.FileSystemUsage{}
-	dir := r.cfg.Dir
 	if dir == "" {
 		// This is an in-memory instance. Pretend we're empty since we
 		// don't know better and only use this for testing. Using any
 		// part of the actual file system here can throw off allocator
 		// rebalancing in a hard-to-trace manner. See #7050.
 		return roachpb.StoreCapacity{
-			Capacity:  r.cfg.MaxSizeBytes,
-			Available: r.cfg.MaxSizeBytes,
+			Capacity:  maxSizeBytes,
+			Available: maxSizeBytes,
 		}, nil
 	}
 	if err := fileSystemUsage.Get(dir); err != nil {
@@ -900,10 +911,10 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	fsuTotal := int64(fileSystemUsage.Total)
 	fsuAvail := int64(fileSystemUsage.Avail)
 
-	// Find the total size of all the files in the r.dir and all its
+	// Find the total size of all the files in the dir and all its
 	// subdirectories.
 	var totalUsedBytes int64
-	if errOuter := filepath.Walk(r.cfg.Dir, func(path string, info os.FileInfo, err error) error {
+	if errOuter := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			// This can happen if rocksdb removes files out from under us - just keep
 			// going to get the best estimate we can.
@@ -929,7 +940,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	// If no size limitation have been placed on the store size or if the
 	// limitation is greater than what's available, just return the actual
 	// totals.
-	if r.cfg.MaxSizeBytes == 0 || r.cfg.MaxSizeBytes >= fsuTotal || r.cfg.Dir == "" {
+	if maxSizeBytes == 0 || maxSizeBytes >= fsuTotal || dir == "" {
 		return roachpb.StoreCapacity{
 			Capacity:  fsuTotal,
 			Available: fsuAvail,
@@ -937,7 +948,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 		}, nil
 	}
 
-	available := r.cfg.MaxSizeBytes - totalUsedBytes
+	available := maxSizeBytes - totalUsedBytes
 	if available > fsuAvail {
 		available = fsuAvail
 	}
@@ -946,7 +957,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	}
 
 	return roachpb.StoreCapacity{
-		Capacity:  r.cfg.MaxSizeBytes,
+		Capacity:  maxSizeBytes,
 		Available: available,
 		Used:      totalUsedBytes,
 	}, nil
@@ -2009,12 +2020,15 @@ func (r *rocksDBBatch) commitInternal(sync bool) error {
 	start := timeutil.Now()
 	var count, size int
 
+	detector := r.parent.cfg.DiskStallDetector
 	if r.flushes > 0 {
 		// We've previously flushed mutations to the C++ batch, so we have to flush
 		// any remaining mutations as well and then commit the batch.
 		r.flushMutations()
 		r.ensureBatch()
-		if err := statusToError(C.DBCommitAndCloseBatch(r.batch, C.bool(sync))); err != nil {
+		if err := detector.watch(r.parent.cfg.Dir, "write", func() error {
+			return statusToError(C.DBCommitAndCloseBatch(r.batch, C.bool(sync)))
+		}); err != nil {
 			return err
 		}
 		r.batch = nil
@@ -2024,7 +2038,10 @@ func (r *rocksDBBatch) commitInternal(sync bool) error {
 
 		// Fast-path which avoids flushing mutations to the C++ batch. Instead, we
 		// directly apply the mutations to the database.
-		if err := dbApplyBatchRepr(r.parent.rdb, r.builder.Finish(), sync); err != nil {
+		repr := r.builder.Finish()
+		if err := detector.watch(r.parent.cfg.Dir, "write", func() error {
+			return dbApplyBatchRepr(r.parent.rdb, repr, sync)
+		}); err != nil {
 			return err
 		}
 		if r.batch != nil {
@@ -3122,15 +3139,21 @@ func (r *RocksDB) setAuxiliaryDir(d string) error {
 // hitting its configured (via settings) maximum delay. If the pending
 // compaction limit is exceeded, it waits for the maximum delay.
 func (r *RocksDB) PreIngestDelay(ctx context.Context) {
-	if r.cfg.Settings == nil {
+	preIngestDelay(ctx, r, r.cfg.Settings)
+}
+
+// preIngestDelay implements Engine.PreIngestDelay for any engine whose
+// GetStats reports L0FileCount and PendingCompactionBytesEstimate.
+func preIngestDelay(ctx context.Context, eng Engine, settings *cluster.Settings) {
+	if settings == nil {
 		return
 	}
-	stats, err := r.GetStats()
+	stats, err := eng.GetStats()
 	if err != nil {
 		log.Warningf(ctx, "failed to read stats: %+v", err)
 		return
 	}
-	targetDelay := calculatePreIngestDelay(r.cfg, stats)
+	targetDelay := calculatePreIngestDelay(settings, stats)
 
 	if targetDelay == 0 {
 		return
@@ -3143,10 +3166,10 @@ func (r *RocksDB) PreIngestDelay(ctx context.Context) {
 	}
 }
 
-func calculatePreIngestDelay(cfg RocksDBConfig, stats *Stats) time.Duration {
-	maxDelay := ingestDelayTime.Get(&cfg.Settings.SV)
-	l0Filelimit := ingestDelayL0Threshold.Get(&cfg.Settings.SV)
-	compactionLimit := ingestDelayPendingLimit.Get(&cfg.Settings.SV)
+func calculatePreIngestDelay(settings *cluster.Settings, stats *Stats) time.Duration {
+	maxDelay := ingestDelayTime.Get(&settings.SV)
+	l0Filelimit := ingestDelayL0Threshold.Get(&settings.SV)
+	compactionLimit := ingestDelayPendingLimit.Get(&settings.SV)
 
 	if stats.PendingCompactionBytesEstimate >= compactionLimit {
 		return maxDelay
@@ -3286,9 +3309,21 @@ func MVCCScanDecodeKeyValue(repr []byte) (key MVCCKey, value []byte, orepr []byt
 // within the interval is exported. Deletions are included if all revisions are
 // requested or if the start.Timestamp is non-zero. Returns the bytes of an
 // SSTable containing the exported keys, the size of exported data, or an error.
+//
+// If targetSize is positive, the export stops at the first key after
+// targetSize bytes have been exported, without splitting the versions of a key,
+// and returns the key from which to resume the export. Otherwise the returned
+// resume key is nil. The blocks of the SSTable are compressed unless
+// disableCompression is set.
 func ExportToSst(
-	ctx context.Context, e Reader, start, end MVCCKey, exportAllRevisions bool, io IterOptions,
-) ([]byte, roachpb.BulkOpSummary, error) {
+	ctx context.Context,
+	e Reader,
+	start, end MVCCKey,
+	exportAllRevisions bool,
+	targetSize uint64,
+	disableCompression bool,
+	io IterOptions,
+) ([]byte, roachpb.BulkOpSummary, roachpb.Key, error) {
 
 	var cdbEngine *C.DBEngine
 	switch v := e.(type) {
@@ -3303,28 +3338,30 @@ func ExportToSst(
 	var data C.DBString
 	var intentErr C.DBString
 	var bulkopSummary C.DBString
+	var resumeKey C.DBString
 
 	err := statusToError(C.DBExportToSst(goToCKey(start), goToCKey(end), C.bool(exportAllRevisions),
-		goToCIterOptions(io), cdbEngine, &data, &intentErr, &bulkopSummary))
+		goToCIterOptions(io), cdbEngine, C.uint64_t(targetSize), C.bool(disableCompression),
+		&data, &intentErr, &bulkopSummary, &resumeKey))
 
 	if err != nil {
 		if err.Error() == "WriteIntentError" {
 			var e roachpb.WriteIntentError
 			if err := protoutil.Unmarshal(cStringToGoBytes(intentErr), &e); err != nil {
-				return nil, roachpb.BulkOpSummary{}, errors.Wrap(err, "failed to decode write intent error")
+				return nil, roachpb.BulkOpSummary{}, nil, errors.Wrap(err, "failed to decode write intent error")
 			}
 
-			return nil, roachpb.BulkOpSummary{}, &e
+			return nil, roachpb.BulkOpSummary{}, nil, &e
 		}
-		return nil, roachpb.BulkOpSummary{}, err
+		return nil, roachpb.BulkOpSummary{}, nil, err
 	}
 
 	var summary roachpb.BulkOpSummary
 	if err := protoutil.Unmarshal(cStringToGoBytes(bulkopSummary), &summary); err != nil {
-		return nil, roachpb.BulkOpSummary{}, errors.Wrap(err, "failed to decode BulkopSummary")
+		return nil, roachpb.BulkOpSummary{}, nil, errors.Wrap(err, "failed to decode BulkopSummary")
 	}
 
-	return cStringToGoBytes(data), summary, nil
+	return cStringToGoBytes(data), summary, roachpb.Key(cStringToGoBytes(resumeKey)), nil
 }
 
 func notFoundErrOrDefault(err error) error {
@@ -44,6 +44,14 @@ func (r *rocksDBTempEngine) NewSortedDiskMultiMap() diskmap.SortedDiskMap {
 // working set is larger than can be stored in memory.
 func NewTempEngine(
 	tempStorage base.TempStorageConfig, storeSpec base.StoreSpec,
+) (diskmap.Factory, error) {
+	return NewTempEngineWithDiskStallDetector(tempStorage, storeSpec, nil /* detector */)
+}
+
+// NewTempEngineWithDiskStallDetector is like NewTempEngine, but the writes
+// of the on-disk temp engine are watched by the given DiskStallDetector.
+func NewTempEngineWithDiskStallDetector(
+	tempStorage base.TempStorageConfig, storeSpec base.StoreSpec, detector *DiskStallDetector,
 ) (diskmap.Factory, error) {
 	if tempStorage.InMemory {
 		// TODO(arjun): Limit the size of the store once #16750 is addressed.
@@ -57,10 +65,11 @@ func NewTempEngine(
 		Dir:   tempStorage.Path,
 		// MaxSizeBytes doesn't matter for temp storage - it's not
 		// enforced in any way.
-		MaxSizeBytes:    0,
-		MaxOpenFiles:    128, // TODO(arjun): Revisit this.
-		UseFileRegistry: storeSpec.UseFileRegistry,
-		ExtraOptions:    storeSpec.ExtraOptions,
+		MaxSizeBytes:      0,
+		MaxOpenFiles:      128, // TODO(arjun): Revisit this.
+		UseFileRegistry:   storeSpec.UseFileRegistry,
+		ExtraOptions:      storeSpec.ExtraOptions,
+		DiskStallDetector: detector,
 	}
 	rocksDBCache := NewRocksDBCache(0)
 	db, err := NewRocksDB(cfg, rocksDBCache)
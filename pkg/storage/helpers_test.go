@@ -165,8 +165,14 @@ func (s *Store) ManualReplicaGC(repl *Replica) error {
 	return manualQueue(s, s.replicaGCQueue, repl)
 }
 
+// SetReplicaGCQueuePaused pauses or resumes the replica GC queue through its
+// cluster setting.
+func (s *Store) SetReplicaGCQueuePaused(paused bool) {
+	replicaGCQueueEnabled.Override(&s.ClusterSettings().SV, !paused)
+}
+
 func (s *Store) ReservationCount() int {
-	return len(s.snapshotApplySem)
+	return s.snapshotApplySem.inUse()
 }
 
 // ClearClosedTimestampStorage clears the closed timestamp storage of all
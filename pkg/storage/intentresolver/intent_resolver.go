@@ -21,15 +21,20 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
@@ -91,14 +96,43 @@ const (
 	defaultIntentResolutionBatchIdle = 5 * time.Millisecond
 )
 
+// batchSize bounds the number of point intent resolutions which are sent to a
+// single range in one batch, as well as the number of keys resolved by each
+// ranged intent resolution request before it is resumed.
+var batchSize = settings.RegisterPositiveIntSetting(
+	"kv.intent_resolver.batch_size",
+	"maximum number of intents resolved in a single batch sent to a range",
+	intentResolverBatchSize,
+)
+
+// maxInFlightBatches bounds the number of intent resolution batches which may
+// be outstanding before callers resolving further intents are backpressured.
+var maxInFlightBatches = settings.RegisterPositiveIntSetting(
+	"kv.intent_resolver.max_in_flight_batches",
+	"maximum number of intent resolution batches in flight before new resolutions are backpressured",
+	requestbatcher.DefaultInFlightBackpressureLimit,
+)
+
+// rangedResolutionConcurrency bounds the number of ranged intent resolutions
+// which a single call to ResolveIntents will process in parallel.
+var rangedResolutionConcurrency = settings.RegisterPositiveIntSetting(
+	"kv.intent_resolver.ranged_resolution_concurrency",
+	"maximum number of ranged intent resolutions processed in parallel for a single transaction",
+	4,
+)
+
 // Config contains the dependencies to construct an IntentResolver.
 type Config struct {
+	Settings             *cluster.Settings
 	Clock                *hlc.Clock
 	DB                   *client.DB
 	Stopper              *stop.Stopper
 	AmbientCtx           log.AmbientContext
 	TestingKnobs         storagebase.IntentResolverTestingKnobs
 	RangeDescriptorCache kvbase.RangeDescriptorCache
+	// ContentionRegistry, if set, records the transactions pushed when
+	// processing WriteIntentErrors.
+	ContentionRegistry *contention.Registry
 
 	TaskLimit                    int
 	MaxGCBatchWait               time.Duration
@@ -112,6 +146,7 @@ type Config struct {
 type IntentResolver struct {
 	Metrics Metrics
 
+	st           *cluster.Settings
 	clock        *hlc.Clock
 	db           *client.DB
 	stopper      *stop.Stopper
@@ -120,7 +155,8 @@ type IntentResolver struct {
 	sem          chan struct{}    // Semaphore to limit async goroutines.
 	contentionQ  *contentionQueue // manages contention on individual keys
 
-	rdc kvbase.RangeDescriptorCache
+	rdc        kvbase.RangeDescriptorCache
+	contention *contention.Registry
 
 	gcBatcher *requestbatcher.RequestBatcher
 	irBatcher *requestbatcher.RequestBatcher
@@ -138,6 +174,9 @@ type IntentResolver struct {
 }
 
 func setConfigDefaults(c *Config) {
+	if c.Settings == nil {
+		c.Settings = cluster.MakeTestingClusterSettings()
+	}
 	if c.TaskLimit == 0 {
 		c.TaskLimit = defaultTaskLimit
 	}
@@ -175,8 +214,10 @@ func (nrdc nopRangeDescriptorCache) LookupRangeDescriptor(
 func New(c Config) *IntentResolver {
 	setConfigDefaults(&c)
 	ir := &IntentResolver{
+		st:           c.Settings,
 		clock:        c.Clock,
 		db:           c.DB,
+		contention:   c.ContentionRegistry,
 		stopper:      c.Stopper,
 		sem:          make(chan struct{}, c.TaskLimit),
 		contentionQ:  newContentionQueue(c.Clock, c.DB),
@@ -195,17 +236,24 @@ func New(c Config) *IntentResolver {
 		Stopper:         c.Stopper,
 		Sender:          c.DB.NonTransactionalSender(),
 	})
-	batchSize := intentResolverBatchSize
-	if c.TestingKnobs.MaxIntentResolutionBatchSize > 0 {
-		batchSize = c.TestingKnobs.MaxIntentResolutionBatchSize
+	// The batch size and in-flight limit are read on every use so that changes
+	// to the settings take effect without a restart.
+	irBatchSize := func() int {
+		if c.TestingKnobs.MaxIntentResolutionBatchSize > 0 {
+			return c.TestingKnobs.MaxIntentResolutionBatchSize
+		}
+		return int(batchSize.Get(&c.Settings.SV))
 	}
 	ir.irBatcher = requestbatcher.New(requestbatcher.Config{
-		Name:            "intent_resolver_ir_batcher",
-		MaxMsgsPerBatch: batchSize,
-		MaxWait:         c.MaxIntentResolutionBatchWait,
-		MaxIdle:         c.MaxIntentResolutionBatchIdle,
-		Stopper:         c.Stopper,
-		Sender:          c.DB.NonTransactionalSender(),
+		Name:                "intent_resolver_ir_batcher",
+		MaxMsgsPerBatchFunc: irBatchSize,
+		MaxWait:             c.MaxIntentResolutionBatchWait,
+		MaxIdle:             c.MaxIntentResolutionBatchIdle,
+		InFlightBackpressureLimitFunc: func() int {
+			return int(maxInFlightBatches.Get(&c.Settings.SV))
+		},
+		Stopper: c.Stopper,
+		Sender:  c.DB.NonTransactionalSender(),
 	})
 	return ir
 }
@@ -239,6 +287,7 @@ func (ir *IntentResolver) ProcessWriteIntentError(
 	if log.V(6) {
 		log.Infof(ctx, "resolving write intent %s", wiErr)
 	}
+	start := timeutil.Now()
 
 	// Possibly queue this processing if the write intent error is for a
 	// single intent affecting a unitary key.
@@ -258,6 +307,7 @@ func (ir *IntentResolver) ProcessWriteIntentError(
 	resolveIntents, pErr := ir.maybePushIntents(
 		ctx, wiErr.Intents, h, pushType, false, /* skipIfInFlight */
 	)
+	ir.recordContention(start, wiErr.Intents, resolveIntents, h, pushType, pErr)
 	if pErr != nil {
 		return cleanup, pErr
 	}
@@ -280,6 +330,47 @@ func (ir *IntentResolver) ProcessWriteIntentError(
 	return cleanup, nil
 }
 
+// recordContention records a contention event for each of the intents which
+// were pushed while processing a WriteIntentError. resolved holds the intents
+// updated with the outcome of the pushes, and pErr is the push error, if any.
+func (ir *IntentResolver) recordContention(
+	start time.Time,
+	intents, resolved []roachpb.Intent,
+	h roachpb.Header,
+	pushType roachpb.PushTxnType,
+	pErr *roachpb.Error,
+) {
+	if ir.contention == nil {
+		return
+	}
+	duration := timeutil.Since(start)
+	statuses := make(map[uuid.UUID]roachpb.TransactionStatus, len(resolved))
+	for _, intent := range resolved {
+		statuses[intent.Txn.ID] = intent.Status
+	}
+	for _, intent := range intents {
+		ev := contention.Event{
+			Time:         start,
+			Key:          intent.Key,
+			PusheeTxnID:  intent.Txn.ID,
+			PushType:     pushType,
+			Duration:     duration,
+			PusheeStatus: intent.Status,
+		}
+		if h.Txn != nil {
+			ev.PusherTxnID = h.Txn.ID
+			ev.PusherTxnName = h.Txn.Name
+		}
+		if status, ok := statuses[intent.Txn.ID]; ok {
+			ev.PusheeStatus = status
+		}
+		if pErr != nil {
+			ev.Err = pErr.String()
+		}
+		ir.contention.Add(ev)
+	}
+}
+
 func getPusherTxn(h roachpb.Header) roachpb.Transaction {
 	// If the txn is nil, we communicate a priority by sending an empty
 	// txn with only the priority set. This is official usage of PushTxn.
@@ -925,27 +1016,53 @@ func (ir *IntentResolver) ResolveIntents(
 
 	// Resolve spans differently. We don't know how many intents will be
 	// swept up with each request, so we limit the spanning resolve
-	// requests to a maximum number of keys and resume as necessary.
+	// requests to a maximum number of keys and resume as necessary. The
+	// spans are independent of one another, so up to
+	// rangedResolutionConcurrency of them are resolved in parallel.
+	if len(resolveRangeReqs) == 1 {
+		return ir.resolveIntentRange(ctx, resolveRangeReqs[0])
+	}
+	sem := make(chan struct{}, rangedResolutionConcurrency.Get(&ir.st.SV))
+	g := ctxgroup.WithContext(ctx)
 	for _, req := range resolveRangeReqs {
-		for {
-			b := &client.Batch{}
-			b.Header.MaxSpanRequestKeys = intentResolverBatchSize
-			b.AddRawRequest(req)
-			if err := ir.db.Run(ctx, b); err != nil {
-				return err
-			}
-			// Check response to see if it must be resumed.
-			resp := b.RawResponse().Responses[0].GetInner().(*roachpb.ResolveIntentRangeResponse)
-			if resp.ResumeSpan == nil {
-				break
-			}
-			reqCopy := *(req.(*roachpb.ResolveIntentRangeRequest))
-			reqCopy.SetSpan(*resp.ResumeSpan)
-			req = &reqCopy
+		req := req // copy for goroutine
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// Wait for the resolutions already started, but report the
+			// cancellation: the remaining spans were never resolved.
+			_ = g.Wait()
+			return ctx.Err()
 		}
+		g.GoCtx(func(ctx context.Context) error {
+			defer func() { <-sem }()
+			return ir.resolveIntentRange(ctx, req)
+		})
 	}
+	return g.Wait()
+}
 
-	return nil
+// resolveIntentRange resolves the intents in the span of the supplied
+// ResolveIntentRangeRequest, resuming the request until the entire span has
+// been processed.
+func (ir *IntentResolver) resolveIntentRange(ctx context.Context, req roachpb.Request) error {
+	maxKeys := batchSize.Get(&ir.st.SV)
+	for {
+		b := &client.Batch{}
+		b.Header.MaxSpanRequestKeys = maxKeys
+		b.AddRawRequest(req)
+		if err := ir.db.Run(ctx, b); err != nil {
+			return err
+		}
+		// Check response to see if it must be resumed.
+		resp := b.RawResponse().Responses[0].GetInner().(*roachpb.ResolveIntentRangeResponse)
+		if resp.ResumeSpan == nil {
+			return nil
+		}
+		reqCopy := *(req.(*roachpb.ResolveIntentRangeRequest))
+		reqCopy.SetSpan(*resp.ResumeSpan)
+		req = &reqCopy
+	}
 }
 
 // intentsByTxn implements sort.Interface to sort intents based on txnID.
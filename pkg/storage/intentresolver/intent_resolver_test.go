@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -850,6 +851,74 @@ func TestCleanupIntents(t *testing.T) {
 	}
 }
 
+// TestResolveIntentsRangedConcurrency verifies that ranged intent resolutions
+// are processed in parallel, bounded by the
+// kv.intent_resolver.ranged_resolution_concurrency setting.
+func TestResolveIntentsRangedConcurrency(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	const concurrency = 2
+	const numSpans = 5
+	st := cluster.MakeTestingClusterSettings()
+	rangedResolutionConcurrency.Override(&st.SV, concurrency)
+
+	var inFlight, maxInFlight int32
+	arrived := make(chan struct{}, numSpans)
+	release := make(chan struct{})
+	db := client.NewDB(log.AmbientContext{
+		Tracer: tracing.NewTracer(),
+	}, client.NonTransactionalFactoryFunc(func(
+		_ context.Context, ba roachpb.BatchRequest,
+	) (*roachpb.BatchResponse, *roachpb.Error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			prev := atomic.LoadInt32(&maxInFlight)
+			if cur <= prev || atomic.CompareAndSwapInt32(&maxInFlight, prev, cur) {
+				break
+			}
+		}
+		arrived <- struct{}{}
+		<-release
+		return respForResolveIntentBatch(t, ba), nil
+	}), clock)
+	ir := New(Config{
+		Settings: st,
+		Stopper:  stopper,
+		DB:       db,
+		Clock:    clock,
+	})
+
+	txn := newTransaction("txn", roachpb.Key("a"), 1, clock)
+	var intents []roachpb.Intent
+	for i := 0; i < numSpans; i++ {
+		key := roachpb.Key(fmt.Sprintf("a%d", i))
+		intents = append(intents, roachpb.Intent{
+			Span: roachpb.Span{Key: key, EndKey: key.PrefixEnd()},
+			Txn:  txn.TxnMeta,
+		})
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ir.ResolveIntents(ctx, intents, ResolveOptions{Wait: true})
+	}()
+	for i := 0; i < concurrency; i++ {
+		<-arrived
+	}
+	close(release)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max != concurrency {
+		t.Fatalf("expected %d concurrent ranged resolutions, found %d", concurrency, max)
+	}
+}
+
 func newTransaction(
 	name string, baseKey roachpb.Key, userPriority roachpb.UserPriority, clock *hlc.Clock,
 ) *roachpb.Transaction {
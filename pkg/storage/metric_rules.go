@@ -0,0 +1,65 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+// CreateAndAddRules adds the recommended rules over the metrics of the
+// stores to the registry.
+func CreateAndAddRules(r *metric.RuleRegistry) {
+	r.AddRule(metric.NewAlertingRule(
+		"UnavailableRanges",
+		"sum by(instance, cluster) (%[1]s) > 0",
+		[]metric.Metadata{metaUnavailableRangeCount},
+		10*time.Minute,
+		"critical",
+		"Instance {{ $labels.instance }} has {{ $value }} unavailable ranges",
+		"Ranges with fewer live replicas than needed for quorum can't serve "+
+			"requests.",
+	))
+	r.AddRule(metric.NewAlertingRule(
+		"UnderReplicatedRanges",
+		"sum by(instance, cluster) (%[1]s) > 0",
+		[]metric.Metadata{metaUnderReplicatedRangeCount},
+		time.Hour,
+		"warning",
+		"Instance {{ $labels.instance }} has {{ $value }} under-replicated ranges",
+		"Ranges with fewer live replicas than their replication target are "+
+			"less tolerant to failures.",
+	))
+	r.AddRule(metric.NewAlertingRule(
+		"StoreDiskLow",
+		"%[1]s / %[2]s < 0.15",
+		[]metric.Metadata{metaAvailable, metaCapacity},
+		30*time.Minute,
+		"critical",
+		"Store {{ $labels.store }} on instance {{ $labels.instance }} has less "+
+			"than 15% of its capacity available",
+		"Stores stop accepting rebalanced replicas when low on capacity, and "+
+			"the node crashes when a store is out of disk space.",
+	))
+	r.AddRule(metric.NewAggregationRule(
+		"cluster:capacity_available:sum",
+		"sum without(store, instance) (%[1]s)",
+		[]metric.Metadata{metaAvailable},
+		"Available storage capacity of the cluster.",
+	))
+	r.AddRule(metric.NewAggregationRule(
+		"cluster:ranges_unavailable:sum",
+		"sum without(store, instance) (%[1]s)",
+		[]metric.Metadata{metaUnavailableRangeCount},
+		"Number of unavailable ranges of the cluster.",
+	))
+}
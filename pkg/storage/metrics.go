@@ -569,6 +569,12 @@ var (
 		Measurement: "Log Entries",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRaftLogSize = metric.Metadata{
+		Name:        "raftlog.size",
+		Help:        "Approximate total size of the Raft logs of all replicas on this store",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaRaftLogTruncated = metric.Metadata{
 		Name:        "raftlog.truncated",
 		Help:        "Number of Raft log entries truncated",
@@ -727,6 +733,18 @@ var (
 		Measurement: "Processing Time",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaReplicaGCQueuePaused = metric.Metadata{
+		Name:        "queue.replicagc.paused",
+		Help:        "Whether the replica GC queue is paused (1) or not (0)",
+		Measurement: "Paused",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicaGCQueueForced = metric.Metadata{
+		Name:        "queue.replicagc.forced",
+		Help:        "Number of replicas explicitly processed by the replica GC queue",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaReplicateQueueSuccesses = metric.Metadata{
 		Name:        "queue.replicate.process.success",
 		Help:        "Number of replicas successfully processed by the replicate queue",
@@ -918,6 +936,20 @@ var (
 		Unit:        metric.Unit_COUNT,
 	}
 
+	// Replica circuit breaker metrics.
+	metaReplicaCircuitBreakerCurTripped = metric.Metadata{
+		Name:        "kv.replica_circuit_breaker.num_tripped_replicas",
+		Help:        "Number of replicas for which the per-replica circuit breaker is currently tripped",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicaCircuitBreakerCumTripped = metric.Metadata{
+		Name:        "kv.replica_circuit_breaker.num_tripped_events",
+		Help:        "Number of times the per-replica circuit breakers tripped since process start",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+
 	// Backpressure metrics.
 	metaBackpressuredOnSplitRequests = metric.Metadata{
 		Name:        "requests.backpressure.split",
@@ -926,6 +958,20 @@ var (
 		Unit:        metric.Unit_COUNT,
 	}
 
+	// Rate limiting metrics.
+	metaRateLimitedReadRequests = metric.Metadata{
+		Name:        "requests.ratelimit.read",
+		Help:        "Number of read requests delayed by the per-range read rate limit",
+		Measurement: "Reads",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRateLimitedWriteRequests = metric.Metadata{
+		Name:        "requests.ratelimit.write",
+		Help:        "Number of write requests delayed by the per-range write rate limit",
+		Measurement: "Writes",
+		Unit:        metric.Unit_COUNT,
+	}
+
 	// AddSSTable metrics.
 	metaAddSSTableProposals = metric.Metadata{
 		Name:        "addsstable.proposals",
@@ -1079,6 +1125,7 @@ type StoreMetrics struct {
 
 	// Raft log metrics.
 	RaftLogFollowerBehindCount *metric.Gauge
+	RaftLogSize                *metric.Gauge
 	RaftLogTruncated           *metric.Counter
 
 	// A map for conveniently finding the appropriate metric. The individual
@@ -1116,6 +1163,8 @@ type StoreMetrics struct {
 	ReplicaGCQueueFailures                    *metric.Counter
 	ReplicaGCQueuePending                     *metric.Gauge
 	ReplicaGCQueueProcessingNanos             *metric.Counter
+	ReplicaGCQueuePaused                      *metric.Gauge
+	ReplicaGCQueueForced                      *metric.Counter
 	ReplicateQueueSuccesses                   *metric.Counter
 	ReplicateQueueFailures                    *metric.Counter
 	ReplicateQueuePending                     *metric.Gauge
@@ -1152,9 +1201,17 @@ type StoreMetrics struct {
 	SlowLeaseRequests *metric.Gauge
 	SlowRaftRequests  *metric.Gauge
 
+	// Replica circuit breaker counts.
+	ReplicaCircuitBreakerCurTripped *metric.Gauge
+	ReplicaCircuitBreakerCumTripped *metric.Counter
+
 	// Backpressure counts.
 	BackpressuredOnSplitRequests *metric.Gauge
 
+	// Rate limiting counts.
+	RateLimitedReadRequests  *metric.Counter
+	RateLimitedWriteRequests *metric.Counter
+
 	// AddSSTable stats: how many AddSSTable commands were proposed and how many
 	// were applied? How many applications required writing a copy?
 	AddSSTableProposals         *metric.Counter
@@ -1296,6 +1353,7 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 
 		// Raft log metrics.
 		RaftLogFollowerBehindCount: metric.NewGauge(metaRaftLogFollowerBehindCount),
+		RaftLogSize:                metric.NewGauge(metaRaftLogSize),
 		RaftLogTruncated:           metric.NewCounter(metaRaftLogTruncated),
 
 		// Replica queue metrics.
@@ -1324,6 +1382,8 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		ReplicaGCQueueFailures:                    metric.NewCounter(metaReplicaGCQueueFailures),
 		ReplicaGCQueuePending:                     metric.NewGauge(metaReplicaGCQueuePending),
 		ReplicaGCQueueProcessingNanos:             metric.NewCounter(metaReplicaGCQueueProcessingNanos),
+		ReplicaGCQueuePaused:                      metric.NewGauge(metaReplicaGCQueuePaused),
+		ReplicaGCQueueForced:                      metric.NewCounter(metaReplicaGCQueueForced),
 		ReplicateQueueSuccesses:                   metric.NewCounter(metaReplicateQueueSuccesses),
 		ReplicateQueueFailures:                    metric.NewCounter(metaReplicateQueueFailures),
 		ReplicateQueuePending:                     metric.NewGauge(metaReplicateQueuePending),
@@ -1360,9 +1420,17 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SlowLeaseRequests: metric.NewGauge(metaSlowLeaseRequests),
 		SlowRaftRequests:  metric.NewGauge(metaSlowRaftRequests),
 
+		// Replica circuit breaker counters.
+		ReplicaCircuitBreakerCurTripped: metric.NewGauge(metaReplicaCircuitBreakerCurTripped),
+		ReplicaCircuitBreakerCumTripped: metric.NewCounter(metaReplicaCircuitBreakerCumTripped),
+
 		// Backpressure counters.
 		BackpressuredOnSplitRequests: metric.NewGauge(metaBackpressuredOnSplitRequests),
 
+		// Rate limiting counters.
+		RateLimitedReadRequests:  metric.NewCounter(metaRateLimitedReadRequests),
+		RateLimitedWriteRequests: metric.NewCounter(metaRateLimitedWriteRequests),
+
 		// AddSSTable proposal + applications counters.
 		AddSSTableProposals:         metric.NewCounter(metaAddSSTableProposals),
 		AddSSTableApplications:      metric.NewCounter(metaAddSSTableApplications),
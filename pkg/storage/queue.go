@@ -263,6 +263,9 @@ type queueConfig struct {
 	processingNanos *metric.Counter
 	// purgatory is a gauge measuring current replica count in purgatory.
 	purgatory *metric.Gauge
+	// timeSource is the source of time of the timers of the queue. If not
+	// set, defaults to timeutil.DefaultTimeSource.
+	timeSource timeutil.TimeSource
 }
 
 // baseQueue is the base implementation of the replicaQueue interface. Queue
@@ -383,6 +386,9 @@ func newBaseQueue(
 	if cfg.addOrMaybeAddSemSize == 0 {
 		cfg.addOrMaybeAddSemSize = 20
 	}
+	if cfg.timeSource == nil {
+		cfg.timeSource = timeutil.DefaultTimeSource{}
+	}
 
 	ambient := store.cfg.AmbientCtx
 	ambient.AddLogTag(name, nil)
@@ -740,6 +746,8 @@ func (bq *baseQueue) processLoop(stopper *stop.Stopper) {
 		// nextTime is initially nil; we don't start any timers until the queue
 		// becomes non-empty.
 		var nextTime <-chan time.Time
+		timer := bq.timeSource.NewTimer()
+		defer timer.Stop()
 
 		immediately := make(chan time.Time)
 		close(immediately)
@@ -763,6 +771,9 @@ func (bq *baseQueue) processLoop(stopper *stop.Stopper) {
 				}
 			// Process replicas as the timer expires.
 			case <-nextTime:
+				if nextTime != immediately {
+					timer.MarkRead()
+				}
 				// Acquire from the process semaphore.
 				bq.processSem <- struct{}{}
 
@@ -775,10 +786,10 @@ func (bq *baseQueue) processLoop(stopper *stop.Stopper) {
 							// Release semaphore when finished processing.
 							defer func() { <-bq.processSem }()
 
-							start := timeutil.Now()
+							start := bq.timeSource.Now()
 							err := bq.processReplica(ctx, repl)
 
-							duration := timeutil.Since(start)
+							duration := bq.timeSource.Since(start)
 							bq.recordProcessDuration(ctx, duration)
 
 							bq.finishProcessingReplica(ctx, stopper, repl, err)
@@ -801,7 +812,8 @@ func (bq *baseQueue) processLoop(stopper *stop.Stopper) {
 					case 0:
 						nextTime = immediately
 					default:
-						nextTime = time.After(t)
+						timer.Reset(t)
+						nextTime = timer.Ch()
 					}
 				}
 			}
@@ -1081,7 +1093,8 @@ func (bq *baseQueue) addToPurgatoryLocked(
 
 	workerCtx := bq.AnnotateCtx(context.Background())
 	stopper.RunWorker(workerCtx, func(ctx context.Context) {
-		ticker := time.NewTicker(purgatoryReportInterval)
+		ticker := bq.timeSource.NewTicker(purgatoryReportInterval)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-bq.impl.purgatoryChan():
@@ -1130,7 +1143,7 @@ func (bq *baseQueue) addToPurgatoryLocked(
 					return
 				}
 				bq.mu.Unlock()
-			case <-ticker.C:
+			case <-ticker.Ch():
 				// Report purgatory status.
 				bq.mu.Lock()
 				errMap := map[string]int{}
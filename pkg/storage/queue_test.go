@@ -481,6 +481,51 @@ func TestBaseQueueAddRemove(t *testing.T) {
 	}
 }
 
+// TestBaseQueueManualTime verifies that the queue waits between replicas
+// on the timers of its time source.
+func TestBaseQueueManualTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+	tc.Start(t, stopper)
+
+	testQueue := &testQueueImpl{
+		duration: time.Hour,
+		shouldQueueFn: func(now hlc.Timestamp, r *Replica) (shouldQueue bool, priority float64) {
+			return true, float64(r.RangeID)
+		},
+	}
+	repls := createReplicas(t, &tc, 2)
+	manual := timeutil.NewManualTime(timeutil.Unix(0, 0))
+	bq := makeTestBaseQueue("test", testQueue, tc.store, tc.gossip,
+		queueConfig{maxSize: 2, timeSource: manual})
+	bq.Start(stopper)
+	for _, r := range repls {
+		bq.maybeAdd(ctx, r, hlc.Timestamp{})
+	}
+
+	// The first replica is processed immediately, and the second one once
+	// the timer set after the first one fires.
+	testutils.SucceedsSoon(t, func() error {
+		if timers := manual.Timers(); len(timers) != 1 {
+			return errors.Errorf("expected a pending timer, got %v", timers)
+		}
+		if pc := testQueue.getProcessed(); pc != 1 {
+			return errors.Errorf("expected 1 processed replica, got %d", pc)
+		}
+		return nil
+	})
+	manual.Advance(time.Hour)
+	testutils.SucceedsSoon(t, func() error {
+		if pc := testQueue.getProcessed(); pc != 2 {
+			return errors.Errorf("expected 2 processed replicas, got %d", pc)
+		}
+		return nil
+	})
+}
+
 // TestNeedsSystemConfig verifies that queues that don't need the system config
 // are able to process replicas when the system config isn't available.
 func TestNeedsSystemConfig(t *testing.T) {
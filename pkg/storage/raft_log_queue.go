@@ -21,10 +21,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/raft"
@@ -34,15 +36,10 @@ import (
 const (
 	// raftLogQueueTimerDuration is the duration between truncations.
 	raftLogQueueTimerDuration = 0 // zero duration to process truncations greedily
-	// RaftLogQueueStaleThreshold is the minimum threshold for stale raft log
-	// entries. A stale entry is one which all replicas of the range have
-	// progressed past and thus is no longer needed and can be truncated.
-	RaftLogQueueStaleThreshold = 100
-	// RaftLogQueueStaleSize is the minimum size of the Raft log that we'll
-	// truncate even if there are fewer than RaftLogQueueStaleThreshold entries
-	// to truncate. The value of 64 KB was chosen experimentally by looking at
-	// when Raft log truncation usually occurs when using the number of entries
-	// as the sole criteria.
+	// RaftLogQueueStaleSize is the default value of the
+	// kv.raft_log.range_stale_size cluster setting. The value of 64 KB was
+	// chosen experimentally by looking at when Raft log truncation usually
+	// occurred back when the number of entries was used as the criteria.
 	RaftLogQueueStaleSize = 64 << 10
 	// Allow a limited number of Raft log truncations to be processed
 	// concurrently.
@@ -54,6 +51,69 @@ const (
 	raftLogQueuePendingSnapshotGracePeriod = 3 * time.Second
 )
 
+// raftLogQueueStaleSize is the per-range byte budget for the Raft log. Once
+// the stale part of a range's Raft log (i.e. the entries which all replicas
+// have progressed past) would free up at least this many bytes, the log is
+// truncated.
+var raftLogQueueStaleSize = settings.RegisterValidatedByteSizeSetting(
+	"kv.raft_log.range_stale_size",
+	"minimum size of a range's Raft log before it is truncated up to the index acknowledged by all followers",
+	RaftLogQueueStaleSize,
+	func(size int64) error {
+		if size <= 0 {
+			return errors.Errorf("cannot set kv.raft_log.range_stale_size to a non-positive value: %d", size)
+		}
+		return nil
+	},
+)
+
+// raftLogNodeMaxSize is the limit on the total size of the Raft logs of all
+// replicas on a node. Once it is exceeded, every range treats its log as too
+// large and is willing to truncate it up to the quorum index, cutting off
+// followers which haven't been recently active. This bounds the growth of the
+// Raft logs when followers are slow or offline for an extended period.
+var raftLogNodeMaxSize = settings.RegisterByteSizeSetting(
+	"kv.raft_log.node_max_size",
+	"total size of the Raft logs on a node above which logs are truncated even if this cuts off "+
+		"inactive followers (0 to disable)",
+	4<<30, // 4 GiB
+)
+
+// RaftLogSizeTracker tracks the total size of the Raft logs of the replicas
+// on a node. A single tracker is shared by all of the node's stores, each of
+// which periodically reports the summed Raft log size of its replicas.
+type RaftLogSizeTracker struct {
+	mu struct {
+		syncutil.Mutex
+		sizes map[roachpb.StoreID]int64
+	}
+}
+
+// NewRaftLogSizeTracker creates a new RaftLogSizeTracker.
+func NewRaftLogSizeTracker() *RaftLogSizeTracker {
+	t := &RaftLogSizeTracker{}
+	t.mu.sizes = make(map[roachpb.StoreID]int64)
+	return t
+}
+
+// update records the total Raft log size of the given store.
+func (t *RaftLogSizeTracker) update(storeID roachpb.StoreID, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.sizes[storeID] = size
+}
+
+// Total returns the total Raft log size across all of the node's stores.
+func (t *RaftLogSizeTracker) Total() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var total int64
+	for _, size := range t.mu.sizes {
+		total += size
+	}
+	return total
+}
+
 // raftLogQueue manages a queue of replicas slated to have their raft logs
 // truncated by removing unneeded entries.
 type raftLogQueue struct {
@@ -156,17 +216,17 @@ func newTruncateDecision(ctx context.Context, r *Replica) (truncateDecision, err
 	r.mu.Lock()
 	raftLogSize := r.mu.raftLogSize
 	// A "cooperative" truncation (i.e. one that does not cut off followers from
-	// the log) takes place whenever there are more than
-	// RaftLogQueueStaleThreshold entries or the log's estimated size is above
-	// RaftLogQueueStaleSize bytes. This is fairly aggressive, so under normal
-	// conditions, the log is very small.
+	// the log) takes place whenever the log's estimated size is above the
+	// kv.raft_log.range_stale_size budget. This is fairly aggressive, so under
+	// normal conditions, the log is very small.
 	//
 	// If followers start falling behind, at some point the logs still need to
 	// be truncated. We do this either when the size of the log exceeds
 	// RaftLogTruncationThreshold (or, in eccentric configurations, the zone's
-	// RangeMaxBytes). This captures the heuristic that at some point, it's more
-	// efficient to catch up via a snapshot than via applying a long tail of log
-	// entries.
+	// RangeMaxBytes), or when the Raft logs of all replicas on this node
+	// together exceed kv.raft_log.node_max_size. This captures the heuristic
+	// that at some point, it's more efficient to catch up via a snapshot than
+	// via applying a long tail of log entries.
 	targetSize := r.store.cfg.RaftLogTruncationThreshold
 	if targetSize > *r.mu.zone.RangeMaxBytes {
 		targetSize = *r.mu.zone.RangeMaxBytes
@@ -214,10 +274,20 @@ func newTruncateDecision(ctx context.Context, r *Replica) (truncateDecision, err
 		raftStatus.Progress[raftStatus.Lead] = pr
 	}
 
+	sv := &r.store.ClusterSettings().SV
+	var nodeLogSize int64
+	maxNodeLogSize := raftLogNodeMaxSize.Get(sv)
+	if maxNodeLogSize > 0 {
+		nodeLogSize = r.store.cfg.RaftLogSizes.Total()
+	}
+
 	input := truncateDecisionInput{
 		RaftStatus:                     *raftStatus,
 		LogSize:                        raftLogSize,
 		MaxLogSize:                     targetSize,
+		StaleLogSize:                   raftLogQueueStaleSize.Get(sv),
+		NodeLogSize:                    nodeLogSize,
+		MaxNodeLogSize:                 maxNodeLogSize,
 		LogSizeTrusted:                 logSizeTrusted,
 		FirstIndex:                     firstIndex,
 		LastIndex:                      lastIndex,
@@ -266,13 +336,25 @@ const (
 type truncateDecisionInput struct {
 	RaftStatus                     raft.Status
 	LogSize, MaxLogSize            int64
-	LogSizeTrusted                 bool // false when LogSize might be off
+	StaleLogSize                   int64 // per-range budget for cooperative truncations
+	NodeLogSize, MaxNodeLogSize    int64 // MaxNodeLogSize is zero when the node-wide limit is disabled
+	LogSizeTrusted                 bool  // false when LogSize might be off
 	FirstIndex, LastIndex          uint64
 	PendingPreemptiveSnapshotIndex uint64
 }
 
+// LogTooLarge returns whether the log is large enough that we're willing to
+// cut off followers that haven't been recently active, either because the
+// range's own log exceeds its limit or because the node-wide limit on the Raft
+// logs has been exceeded.
 func (input truncateDecisionInput) LogTooLarge() bool {
-	return input.LogSize > input.MaxLogSize
+	return input.LogSize > input.MaxLogSize || input.NodeLogTooLarge()
+}
+
+// NodeLogTooLarge returns whether the Raft logs of all replicas on the node
+// together exceed the node-wide limit.
+func (input truncateDecisionInput) NodeLogTooLarge() bool {
+	return input.MaxNodeLogSize > 0 && input.NodeLogSize > input.MaxNodeLogSize
 }
 
 type truncateDecision struct {
@@ -324,7 +406,7 @@ func (td *truncateDecision) String() string {
 		"truncate %d entries to first index %d (chosen via: %s)",
 		td.NumTruncatableIndexes(), td.NewFirstIndex, td.ChosenVia,
 	)
-	if td.Input.LogTooLarge() {
+	if td.Input.LogSize > td.Input.MaxLogSize {
 		_, _ = fmt.Fprintf(
 			&buf,
 			"; log too large (%s > %s)",
@@ -332,6 +414,14 @@ func (td *truncateDecision) String() string {
 			humanizeutil.IBytes(td.Input.MaxLogSize),
 		)
 	}
+	if td.Input.NodeLogTooLarge() {
+		_, _ = fmt.Fprintf(
+			&buf,
+			"; node logs too large (%s > %s)",
+			humanizeutil.IBytes(td.Input.NodeLogSize),
+			humanizeutil.IBytes(td.Input.MaxNodeLogSize),
+		)
+	}
 	if n := td.NumNewRaftSnapshots(); n > 0 {
 		_, _ = fmt.Fprintf(&buf, "; implies %d Raft snapshot%s", n, util.Pluralize(int64(n)))
 	}
@@ -350,10 +440,12 @@ func (td *truncateDecision) NumTruncatableIndexes() int {
 	return int(td.NewFirstIndex - td.Input.FirstIndex)
 }
 
+// ShouldTruncate returns whether the decision calls for a truncation, which
+// is the case when there is something to truncate and the log has outgrown
+// its per-range budget.
 func (td *truncateDecision) ShouldTruncate() bool {
 	n := td.NumTruncatableIndexes()
-	return n >= RaftLogQueueStaleThreshold ||
-		(n > 0 && td.Input.LogSize >= RaftLogQueueStaleSize)
+	return n > 0 && td.Input.LogSize >= td.Input.StaleLogSize
 }
 
 // ProtectIndex attempts to "protect" a position in the log by making sure it's
@@ -488,8 +580,8 @@ func getQuorumIndex(raftStatus *raft.Status) uint64 {
 }
 
 // shouldQueue determines whether a range should be queued for truncating. This
-// is true only if the replica is the raft leader and if the total number of
-// the range's raft log's stale entries exceeds RaftLogQueueStaleThreshold.
+// is true only if the replica is the raft leader and if the size of the
+// range's raft log exceeds its budget (see truncateDecision.ShouldTruncate).
 func (rlq *raftLogQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, r *Replica, _ *config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -530,8 +622,7 @@ func (rlq *raftLogQueue) shouldQueueImpl(
 }
 
 // process truncates the raft log of the range if the replica is the raft
-// leader and if the total number of the range's raft log's stale entries
-// exceeds RaftLogQueueStaleThreshold.
+// leader and if the size of the range's raft log exceeds its budget.
 func (rlq *raftLogQueue) process(ctx context.Context, r *Replica, _ *config.SystemConfig) error {
 	decision, err := newTruncateDecision(ctx, r)
 	if err != nil {
@@ -43,8 +43,8 @@ func TestShouldTruncate(t *testing.T) {
 		raftLogSize        int64
 		expected           bool
 	}{
-		{RaftLogQueueStaleThreshold - 1, 0, false},
-		{RaftLogQueueStaleThreshold, 0, true},
+		{1000, 0, false},
+		{1000, RaftLogQueueStaleSize - 1, false},
 		{0, RaftLogQueueStaleSize, false},
 		{1, RaftLogQueueStaleSize - 1, false},
 		{1, RaftLogQueueStaleSize, true},
@@ -53,6 +53,7 @@ func TestShouldTruncate(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			var d truncateDecision
 			d.Input.LogSize = c.raftLogSize
+			d.Input.StaleLogSize = RaftLogQueueStaleSize
 			d.Input.FirstIndex = 123
 			d.NewFirstIndex = d.Input.FirstIndex + c.truncatableIndexes
 			v := d.ShouldTruncate()
@@ -229,6 +230,7 @@ func TestComputeTruncateDecision(t *testing.T) {
 				RaftStatus:                     status,
 				LogSize:                        c.raftLogSize,
 				MaxLogSize:                     targetSize,
+				StaleLogSize:                   RaftLogQueueStaleSize,
 				LogSizeTrusted:                 true,
 				FirstIndex:                     c.firstIndex,
 				LastIndex:                      c.lastIndex,
@@ -314,6 +316,7 @@ func TestComputeTruncateDecisionProgressStatusProbe(t *testing.T) {
 			input := truncateDecisionInput{
 				RaftStatus:     status,
 				MaxLogSize:     1024,
+				StaleLogSize:   256,
 				FirstIndex:     10,
 				LastIndex:      500,
 				LogSizeTrusted: true,
@@ -330,6 +333,70 @@ func TestComputeTruncateDecisionProgressStatusProbe(t *testing.T) {
 	})
 }
 
+// TestComputeTruncateDecisionNodeLogTooLarge verifies that once the Raft logs
+// on the node exceed the node-wide limit, followers which haven't been
+// recently active are cut off even though the range's own log is within its
+// limit.
+func TestComputeTruncateDecisionNodeLogTooLarge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	exp := map[bool]string{ // nodeTooLarge
+		false: "should truncate: false [truncate 0 entries to first index 10 (chosen via: followers)]",
+		true:  "should truncate: true [truncate 190 entries to first index 200 (chosen via: followers); node logs too large (2.0 KiB > 1.0 KiB)]",
+	}
+
+	testutils.RunTrueAndFalse(t, "nodeTooLarge", func(t *testing.T, nodeTooLarge bool) {
+		status := raft.Status{
+			Progress: make(map[uint64]tracker.Progress),
+		}
+		for j, v := range []uint64{10, 200, 300, 400, 500} {
+			status.Progress[uint64(j)] = tracker.Progress{
+				Match: v,
+				Next:  v + 1,
+				// The follower at index 10 is behind and hasn't been heard from.
+				RecentActive: v != 10,
+				State:        tracker.StateReplicate,
+			}
+		}
+
+		input := truncateDecisionInput{
+			RaftStatus:     status,
+			LogSize:        512,
+			MaxLogSize:     4096,
+			StaleLogSize:   256,
+			NodeLogSize:    1024,
+			MaxNodeLogSize: 1024,
+			FirstIndex:     10,
+			LastIndex:      500,
+			LogSizeTrusted: true,
+		}
+		if nodeTooLarge {
+			input.NodeLogSize += 1024
+		}
+
+		decision := computeTruncateDecision(input)
+		if s, exp := decision.String(), exp[nodeTooLarge]; s != exp {
+			t.Errorf("expected %q, got %q", exp, s)
+		}
+
+		// A zero limit disables the node-wide check.
+		input.MaxNodeLogSize = 0
+		assert.False(t, input.LogTooLarge())
+	})
+}
+
+func TestRaftLogSizeTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tr := NewRaftLogSizeTracker()
+	assert.Zero(t, tr.Total())
+	tr.update(1, 100)
+	tr.update(2, 50)
+	assert.Equal(t, int64(150), tr.Total())
+	tr.update(1, 10)
+	assert.Equal(t, int64(60), tr.Total())
+}
+
 func TestTruncateDecisionZeroValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -507,10 +574,10 @@ func TestNewTruncateDecision(t *testing.T) {
 		t.Errorf("expected first index to be greater than 0, got %d", aFirst)
 	}
 
-	// Write a few keys to the range.
-	for i := 0; i < RaftLogQueueStaleThreshold+1; i++ {
+	// Write enough data to the range to exceed its Raft log budget.
+	for i := 0; i < 10; i++ {
 		key := roachpb.Key(fmt.Sprintf("key%02d", i))
-		args := putArgs(key, []byte(fmt.Sprintf("value%02d", i)))
+		args := putArgs(key, bytes.Repeat([]byte("v"), RaftLogQueueStaleSize/5))
 		if _, err := client.SendWrapped(context.Background(), store.TestSender(), &args); err != nil {
 			t.Fatal(err)
 		}
@@ -179,6 +179,14 @@ type Replica struct {
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
 
+	// breaker fails requests fast once the replica is found to be unable to
+	// replicate commands.
+	breaker *replicaCircuitBreaker
+
+	// readLimiter and writeLimiter enforce the per-range read and write rate
+	// limits on user data.
+	readLimiter, writeLimiter replicaRateLimiter
+
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
 	creatingReplica *roachpb.ReplicaDescriptor
@@ -553,6 +561,15 @@ func (r *Replica) sendWithRangeID(
 		}
 	}
 
+	// Fail fast if the replica's circuit breaker has tripped. Lease requests
+	// are exempt so that the replica can regain a lease once the range becomes
+	// available again, and so are the probes which reset the breaker.
+	if !ba.IsSingleSkipLeaseCheckRequest() {
+		if err := r.breaker.Err(); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+	}
+
 	// Differentiate between admin, read-only and write.
 	var pErr *roachpb.Error
 	if useRaft {
@@ -1052,6 +1069,12 @@ func (r *Replica) checkBatchRequest(ba *roachpb.BatchRequest, isReadOnly bool) e
 	} else if !consistent {
 		return errors.Errorf("%v mode is only available to reads", ba.ReadConsistency)
 	}
+	if ba.BoundedStaleness != nil {
+		if !isReadOnly {
+			return errors.New("bounded staleness reads cannot contain writes")
+		}
+		return checkBoundedStalenessBatch(ba)
+	}
 
 	return nil
 }
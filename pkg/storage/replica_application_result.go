@@ -136,6 +136,11 @@ func (r *Replica) prepareLocalResult(ctx context.Context, cmd *replicatedCmd) {
 	if pErr == nil {
 		pErr = cmd.forcedErr
 	}
+	if pErr == noopOnProbeCommandErr {
+		// The probe was committed to the Raft log, which is all it set out to
+		// do. Return its (empty) reply.
+		pErr = nil
+	}
 
 	if cmd.proposalRetry != proposalNoReevaluation && pErr == nil {
 		log.Fatalf(ctx, "proposal with nontrivial retry behavior, but no error: %+v", cmd.proposal)
@@ -322,12 +327,13 @@ func (r *Replica) handleNoRaftLogDeltaResult(ctx context.Context) {
 	// Check for whether to queue the range for Raft log truncation if this is
 	// not a Raft log truncation command itself. We don't want to check the
 	// Raft log for truncation on every write operation or even every operation
-	// which occurs after the Raft log exceeds RaftLogQueueStaleSize. The logic
-	// below queues the replica for possible Raft log truncation whenever an
-	// additional RaftLogQueueStaleSize bytes have been written to the Raft
-	// log.
+	// which occurs after the Raft log exceeds kv.raft_log.range_stale_size. The
+	// logic below queues the replica for possible Raft log truncation whenever
+	// an additional kv.raft_log.range_stale_size bytes have been written to the
+	// Raft log.
+	staleSize := raftLogQueueStaleSize.Get(&r.store.cfg.Settings.SV)
 	r.mu.Lock()
-	checkRaftLog := r.mu.raftLogSize-r.mu.raftLogLastCheckSize >= RaftLogQueueStaleSize
+	checkRaftLog := r.mu.raftLogSize-r.mu.raftLogLastCheckSize >= staleSize
 	if checkRaftLog {
 		r.mu.raftLogLastCheckSize = r.mu.raftLogSize
 	}
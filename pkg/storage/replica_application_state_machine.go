@@ -144,6 +144,11 @@ func (r *Replica) shouldApplyCommand(
 	return cmd.forcedErr == nil
 }
 
+// noopOnProbeCommandErr is the forced error with which ProbeRequests are
+// rejected below Raft. The proposer of a probe treats it as success; see
+// prepareLocalResult.
+var noopOnProbeCommandErr = roachpb.NewErrorf("no-op on ProbeRequest")
+
 // checkForcedErr determines whether or not a command should be applied to the
 // replicated state machine after it has been committed to the Raft log. This
 // decision is deterministic on all replicas, such that a command that is
@@ -184,6 +189,12 @@ func checkForcedErr(
 		return leaseIndex, proposalNoReevaluation, roachpb.NewErrorf("no-op on empty Raft entry")
 	}
 
+	if raftCmd.ReplicatedEvalResult.IsProbe {
+		// A ProbeRequest is proposed without regard to the lease and is never
+		// applied. Reaching this point is all it set out to do.
+		return leaseIndex, proposalNoReevaluation, noopOnProbeCommandErr
+	}
+
 	// Verify the lease matches the proposer's expectation. We rely on
 	// the proposer's determination of whether the existing lease is
 	// held, and can be used, or is expired, and can be replaced.
@@ -0,0 +1,110 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// checkBoundedStalenessBatch returns an error if a batch carrying a
+// BoundedStaleness header cannot be served as a bounded staleness read.
+func checkBoundedStalenessBatch(ba *roachpb.BatchRequest) error {
+	if ba.Txn != nil {
+		return errors.New("bounded staleness reads cannot be transactional")
+	}
+	if ba.ReadConsistency != roachpb.CONSISTENT {
+		return errors.Errorf("bounded staleness reads cannot be %v", ba.ReadConsistency)
+	}
+	for _, union := range ba.Requests {
+		switch args := union.GetInner(); args.(type) {
+		case *roachpb.GetRequest, *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+		default:
+			return errors.Errorf("%s is not supported in bounded staleness reads", args.Method())
+		}
+	}
+	return nil
+}
+
+// startBoundedStalenessRead sets the timestamp of a bounded staleness read to
+// the replica's local resolved timestamp, at or below which the replica can
+// serve consistent reads from its local data without coordinating with the
+// leaseholder, ignoring any unresolved intents. This is the replica's closed
+// timestamp.
+func (r *Replica) startBoundedStalenessRead(
+	ctx context.Context, ba *roachpb.BatchRequest,
+) *roachpb.Error {
+	// Learners are excluded for the same reasons as in canServeFollowerRead.
+	repDesc, err := r.GetReplicaDescriptor()
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	if repDesc.GetType() == roachpb.ReplicaType_LEARNER {
+		return roachpb.NewErrorf("%s is a learner and cannot serve bounded staleness reads", r)
+	}
+	if pErr := setBoundedStalenessTimestamp(ba, r.maxClosed(ctx)); pErr != nil {
+		// Like canServeFollowerRead, signal that we want an update so that
+		// future requests can succeed.
+		if lease, _ := r.GetLease(); lease.Replica.StoreID != r.store.StoreID() {
+			r.store.cfg.ClosedTimestamp.Clients.Request(lease.Replica.NodeID, r.RangeID)
+		}
+		return pErr
+	}
+	return nil
+}
+
+// setBoundedStalenessTimestamp sets the timestamp of a bounded staleness read
+// to ts, unless ts is below the read's minimum timestamp bound, in which case
+// a MinTimestampBoundUnsatisfiableError is returned.
+func setBoundedStalenessTimestamp(ba *roachpb.BatchRequest, ts hlc.Timestamp) *roachpb.Error {
+	if ts.Less(ba.BoundedStaleness.MinTimestampBound) {
+		return roachpb.NewError(&roachpb.MinTimestampBoundUnsatisfiableError{
+			MinTimestampBound: ba.BoundedStaleness.MinTimestampBound,
+			ResolvedTimestamp: ts,
+		})
+	}
+	ba.Timestamp = ts
+	return nil
+}
+
+// lowerBoundedStalenessTimestamp is called when evaluating a bounded
+// staleness read ran into the unresolved intents of a WriteIntentError. Since
+// all of them are at or below the read's timestamp, resolving them would
+// require coordinating with their transactions. Instead, the read's timestamp
+// is lowered below all of them and true is returned to indicate that the read
+// should be evaluated again. Any other error is returned unchanged.
+func lowerBoundedStalenessTimestamp(
+	ctx context.Context, ba *roachpb.BatchRequest, pErr *roachpb.Error,
+) (bool, *roachpb.Error) {
+	wiErr, ok := pErr.GetDetail().(*roachpb.WriteIntentError)
+	if !ok {
+		return false, pErr
+	}
+	ts := ba.Timestamp
+	for _, intent := range wiErr.Intents {
+		if !ts.Less(intent.Txn.Timestamp) {
+			ts = intent.Txn.Timestamp.Prev()
+		}
+	}
+	if ts == ba.Timestamp {
+		return false, pErr
+	}
+	log.VEventf(ctx, 2, "lowering bounded staleness read timestamp to %s below %d intents",
+		ts, len(wiErr.Intents))
+	if pErr := setBoundedStalenessTimestamp(ba, ts); pErr != nil {
+		return false, pErr
+	}
+	return true, nil
+}
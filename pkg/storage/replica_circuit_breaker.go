@@ -0,0 +1,254 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// replicaCircuitBreakerSlowReplicationThreshold is the duration after which a
+// proposal that has not been applied, or a request which is still waiting for
+// the lease or latches, trips the replica's circuit breaker.
+var replicaCircuitBreakerSlowReplicationThreshold = settings.RegisterNonNegativeDurationSetting(
+	"kv.replica_circuit_breaker.slow_replication_threshold",
+	"duration after which slow proposals, lease acquisitions and latch acquisitions trip the "+
+		"per-replica circuit breaker (zero disables the breaker)",
+	0,
+)
+
+// replicaCircuitBreakerProbeInterval is the interval at which a tripped
+// circuit breaker probes the replica to check whether it has become available
+// again.
+const replicaCircuitBreakerProbeInterval = time.Second
+
+// replicaUnavailableError is returned by requests to a replica whose circuit
+// breaker has tripped.
+type replicaUnavailableError struct {
+	rangeID roachpb.RangeID
+	replica roachpb.ReplicaDescriptor
+	tripped time.Time
+	cause   error
+}
+
+func (e *replicaUnavailableError) Error() string {
+	return fmt.Sprintf("replica %s of r%d unavailable since %s: %s",
+		e.replica, e.rangeID, e.tripped.Format(time.RFC3339), e.cause)
+}
+
+// replicaCircuitBreaker fails requests to a replica fast once the replica has
+// been determined to be unable to replicate commands, typically because its
+// range has lost quorum. Without it, such requests hang until they time out
+// (if ever), which makes it hard for clients to bound their latency during
+// partial outages.
+//
+// The breaker is tripped by a proposal which has not been applied within
+// kv.replica_circuit_breaker.slow_replication_threshold, or by a request which
+// has waited as long for the lease or for latches (see tripIfSlow). Once
+// tripped, a background probe periodically sends a ProbeRequest through the
+// replica and resets the breaker once one makes it through Raft. Probes don't
+// require the lease, so the breakers of followers and of leaseholders which
+// aren't the Raft leader are reset as well.
+type replicaCircuitBreaker struct {
+	r *Replica
+	// err holds a *replicaUnavailableError, which is non-nil while the breaker
+	// is tripped. It is read without locking by every request to the replica
+	// and only updated with mu held.
+	err atomic.Value
+	mu  syncutil.Mutex
+}
+
+func newReplicaCircuitBreaker(r *Replica) *replicaCircuitBreaker {
+	br := &replicaCircuitBreaker{r: r}
+	br.err.Store((*replicaUnavailableError)(nil))
+	return br
+}
+
+// Err returns a non-nil error if the breaker is tripped.
+func (br *replicaCircuitBreaker) Err() error {
+	if err := br.loadErr(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (br *replicaCircuitBreaker) loadErr() *replicaUnavailableError {
+	return br.err.Load().(*replicaUnavailableError)
+}
+
+// slowReplicationThreshold returns the duration after which a pending
+// proposal trips the breaker, or zero if the breaker is disabled.
+func (br *replicaCircuitBreaker) slowReplicationThreshold() time.Duration {
+	return replicaCircuitBreakerSlowReplicationThreshold.Get(&br.r.store.cfg.Settings.SV)
+}
+
+// trip trips the breaker with the given cause. If the breaker was not already
+// tripped, a probe is started which resets the breaker once the replica is
+// able to make progress again.
+func (br *replicaCircuitBreaker) trip(ctx context.Context, cause error) error {
+	replDesc, _ := br.r.GetReplicaDescriptor()
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if err := br.loadErr(); err != nil {
+		return err
+	}
+	err := &replicaUnavailableError{
+		rangeID: br.r.RangeID,
+		replica: replDesc,
+		tripped: timeutil.Now(),
+		cause:   cause,
+	}
+	br.err.Store(err)
+	br.r.store.metrics.ReplicaCircuitBreakerCurTripped.Inc(1)
+	br.r.store.metrics.ReplicaCircuitBreakerCumTripped.Inc(1)
+	log.Warningf(ctx, "circuit breaker tripped: %s", err)
+
+	ctx = br.r.AnnotateCtx(context.Background())
+	if err := br.r.store.stopper.RunAsyncTask(ctx, "replica-circuit-breaker-probe", br.probe); err != nil {
+		// The stopper is quiescing. Leave the breaker tripped; nobody will be
+		// sending requests to the replica any more.
+		log.Infof(ctx, "unable to start circuit breaker probe: %s", err)
+	}
+	return err
+}
+
+// tripIfSlow runs fn, which waits for the replica to become able to serve a
+// request, for instance by acquiring the lease or latches. If fn has not
+// returned within the slow replication threshold, the context passed to it is
+// canceled and, if fn then fails, the breaker is tripped and its error is
+// returned. Otherwise, nil is returned and the caller uses the results of fn.
+// This lets requests which don't propose commands, such as reads, trip the
+// breaker.
+func (br *replicaCircuitBreaker) tripIfSlow(
+	ctx context.Context, op string, fn func(context.Context) error,
+) error {
+	threshold := br.slowReplicationThreshold()
+	if threshold == 0 {
+		_ = fn(ctx)
+		return nil
+	}
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var slow int32
+	timer := time.AfterFunc(threshold, func() {
+		atomic.StoreInt32(&slow, 1)
+		cancel()
+	})
+	defer timer.Stop()
+	if err := fn(fnCtx); err != nil && atomic.LoadInt32(&slow) == 1 && ctx.Err() == nil {
+		return br.trip(ctx, errors.Errorf("%s not completed after %.2fs", op, threshold.Seconds()))
+	}
+	return nil
+}
+
+// probe periodically sends a probe through the replica and resets the breaker
+// once one succeeds.
+func (br *replicaCircuitBreaker) probe(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		timer.Reset(replicaCircuitBreakerProbeInterval)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-br.r.store.stopper.ShouldQuiesce():
+			return
+		}
+		if _, destroyed := br.r.IsDestroyed(); destroyed != nil {
+			br.reset(ctx, "replica destroyed")
+			return
+		}
+		if br.slowReplicationThreshold() == 0 {
+			br.reset(ctx, "circuit breaker disabled")
+			return
+		}
+		if err := br.sendProbe(ctx); err != nil {
+			log.VEventf(ctx, 1, "circuit breaker probe failed: %s", err)
+			continue
+		}
+		br.reset(ctx, "probe succeeded")
+		return
+	}
+}
+
+// sendProbe sends a ProbeRequest through the replica. The probe is proposed
+// to Raft without regard to the lease and returns once it has been committed,
+// which shows that the range is able to replicate commands.
+func (br *replicaCircuitBreaker) sendProbe(ctx context.Context) error {
+	r := br.r
+	return contextutil.RunWithTimeout(ctx, "circuit breaker probe", br.slowReplicationThreshold(),
+		func(ctx context.Context) error {
+			var ba roachpb.BatchRequest
+			ba.Timestamp = r.store.Clock().Now()
+			ba.RangeID = r.RangeID
+			ba.Add(&roachpb.ProbeRequest{
+				RequestHeader: roachpb.RequestHeader{Key: r.Desc().StartKey.AsRawKey()},
+			})
+			_, pErr := r.Send(ctx, ba)
+			return pErr.GoError()
+		})
+}
+
+// reset untrips the breaker.
+func (br *replicaCircuitBreaker) reset(ctx context.Context, reason string) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	err := br.loadErr()
+	if err == nil {
+		return
+	}
+	log.Infof(ctx, "circuit breaker reset after %s: %s",
+		timeutil.Since(err.tripped), reason)
+	br.err.Store((*replicaUnavailableError)(nil))
+	br.r.store.metrics.ReplicaCircuitBreakerCurTripped.Dec(1)
+}
+
+// redirectOnOrAcquireLeaseWithBreaker is like redirectOnOrAcquireLease, but
+// trips the replica's circuit breaker if the lease can't be acquired within
+// the slow replication threshold.
+func (r *Replica) redirectOnOrAcquireLeaseWithBreaker(
+	ctx context.Context,
+) (status storagepb.LeaseStatus, pErr *roachpb.Error) {
+	if err := r.breaker.tripIfSlow(ctx, "lease acquisition", func(ctx context.Context) error {
+		status, pErr = r.redirectOnOrAcquireLease(ctx)
+		return pErr.GoError()
+	}); err != nil {
+		return storagepb.LeaseStatus{}, roachpb.NewError(err)
+	}
+	return status, pErr
+}
+
+// beginCmdsWithBreaker is like beginCmds, but trips the replica's circuit
+// breaker if latches can't be acquired within the slow replication threshold.
+func (r *Replica) beginCmdsWithBreaker(
+	ctx context.Context, ba *roachpb.BatchRequest, spans *spanset.SpanSet,
+) (ec endCmds, err error) {
+	if tripErr := r.breaker.tripIfSlow(ctx, "latch acquisition", func(ctx context.Context) error {
+		ec, err = r.beginCmds(ctx, ba, spans)
+		return err
+	}); tripErr != nil {
+		return endCmds{}, tripErr
+	}
+	return ec, err
+}
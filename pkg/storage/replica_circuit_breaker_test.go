@@ -0,0 +1,164 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+)
+
+// TestReplicaCircuitBreaker verifies that requests to a replica with a tripped
+// circuit breaker fail fast, and that the breaker is reset by its probe once
+// the replica is found to be healthy.
+func TestReplicaCircuitBreaker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	tc := testContext{}
+	tc.Start(t, stopper)
+	replicaCircuitBreakerSlowReplicationThreshold.Override(&tc.store.cfg.Settings.SV, time.Minute)
+
+	key := roachpb.Key("a")
+	pArgs := putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrapped(&pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if err := tc.repl.breaker.trip(ctx, errors.New("injected")); err == nil {
+		t.Fatal("expected tripping the breaker to return an error")
+	}
+	gArgs := getArgs(key)
+	if _, pErr := tc.SendWrapped(&gArgs); !testutils.IsPError(pErr, "unavailable.*injected") {
+		t.Fatalf("expected circuit breaker error, got %v", pErr)
+	}
+	if n := tc.store.metrics.ReplicaCircuitBreakerCurTripped.Value(); n != 1 {
+		t.Fatalf("expected 1 tripped replica, found %d", n)
+	}
+
+	// The range has a single replica, so the probe makes it through Raft and
+	// resets the breaker.
+	testutils.SucceedsSoon(t, func() error {
+		return tc.repl.breaker.Err()
+	})
+	if _, pErr := tc.SendWrapped(&gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := tc.store.metrics.ReplicaCircuitBreakerCurTripped.Value(); n != 0 {
+		t.Fatalf("expected no tripped replicas, found %d", n)
+	}
+	if n := tc.store.metrics.ReplicaCircuitBreakerCumTripped.Count(); n != 1 {
+		t.Fatalf("expected 1 trip event, found %d", n)
+	}
+}
+
+// TestReplicaCircuitBreakerNotLeaseHolder verifies that the breaker of a
+// replica which is the Raft leader but doesn't hold the lease is reset by its
+// probe. Such a replica can't serve requests and doesn't apply new log entries
+// while the range is idle, so only a probe can show that it is healthy.
+func TestReplicaCircuitBreakerNotLeaseHolder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	tc := testContext{manualClock: hlc.NewManualClock(123)}
+	cfg := TestStoreConfig(hlc.NewClock(tc.manualClock.UnixNano, time.Nanosecond))
+	cfg.TestingKnobs.DisableAutomaticLeaseRenewal = true
+	tc.StartWithStoreConfig(t, stopper, cfg)
+	replicaCircuitBreakerSlowReplicationThreshold.Override(&tc.store.cfg.Settings.SV, time.Minute)
+
+	// Give the lease to a replica which is only part of the range descriptor.
+	// The Raft group still consists of tc.repl alone, which remains its leader.
+	secondReplica, err := tc.addBogusReplicaToRangeDesc(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.manualClock.Set(leaseExpiry(tc.repl))
+	now := tc.Clock().Now()
+	if err := sendLeaseRequest(tc.repl, &roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(10, 0).Clone(),
+		Replica:    secondReplica,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tc.repl.breaker.trip(ctx, errors.New("injected")); err == nil {
+		t.Fatal("expected tripping the breaker to return an error")
+	}
+	testutils.SucceedsSoon(t, func() error {
+		return tc.repl.breaker.Err()
+	})
+
+	gArgs := getArgs(roachpb.Key("a"))
+	_, pErr := tc.SendWrappedWith(roachpb.Header{Timestamp: now}, &gArgs)
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		t.Fatalf("expected not lease holder error, got %v", pErr)
+	}
+}
+
+// TestReplicaCircuitBreakerRead verifies that a read which can't acquire the
+// lease within the slow replication threshold trips the circuit breaker.
+func TestReplicaCircuitBreakerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	var blockLeases int32
+	unblock := make(chan struct{})
+	tc := testContext{manualClock: hlc.NewManualClock(123)}
+	cfg := TestStoreConfig(hlc.NewClock(tc.manualClock.UnixNano, time.Nanosecond))
+	cfg.TestingKnobs.DisableAutomaticLeaseRenewal = true
+	cfg.TestingKnobs.TestingRequestFilter = func(ba roachpb.BatchRequest) *roachpb.Error {
+		if ba.IsLeaseRequest() && atomic.LoadInt32(&blockLeases) == 1 {
+			<-unblock
+		}
+		return nil
+	}
+	tc.StartWithStoreConfig(t, stopper, cfg)
+	replicaCircuitBreakerSlowReplicationThreshold.Override(
+		&tc.store.cfg.Settings.SV, 250*time.Millisecond)
+
+	// Let the lease expire and prevent the replica from acquiring a new one.
+	atomic.StoreInt32(&blockLeases, 1)
+	tc.manualClock.Set(leaseExpiry(tc.repl))
+
+	gArgs := getArgs(roachpb.Key("a"))
+	if _, pErr := tc.SendWrapped(&gArgs); !testutils.IsPError(pErr, "unavailable.*lease acquisition") {
+		t.Fatalf("expected circuit breaker error, got %v", pErr)
+	}
+	if n := tc.store.metrics.ReplicaCircuitBreakerCumTripped.Count(); n != 1 {
+		t.Fatalf("expected 1 trip event, found %d", n)
+	}
+
+	// Once the lease can be acquired again, the probe resets the breaker and
+	// reads succeed.
+	atomic.StoreInt32(&blockLeases, 0)
+	close(unblock)
+	testutils.SucceedsSoon(t, func() error {
+		return tc.repl.breaker.Err()
+	})
+	if _, pErr := tc.SendWrapped(&gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
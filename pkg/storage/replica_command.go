@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	crdberrors "github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
 	"github.com/gogo/protobuf/proto"
@@ -1368,6 +1369,10 @@ func (r *Replica) sendSnapshot(
 	snapType SnapshotRequest_Type,
 	priority SnapshotRequest_Priority,
 ) error {
+	ctx, op := tracing.StartOperation(ctx, r.AmbientContext.Tracer,
+		fmt.Sprintf("%s snapshot of r%d to %s", snapType, r.RangeID, recipient))
+	defer op.Finish()
+
 	snap, err := r.GetSnapshot(ctx, snapType)
 	if err != nil {
 		return errors.Wrapf(err, "%s: failed to generate %s snapshot", r, snapType)
@@ -1445,6 +1450,8 @@ func (r *Replica) sendSnapshot(
 	sent := func() {
 		r.store.metrics.RangeSnapshotsGenerated.Inc(1)
 	}
+	trackID := r.store.outgoingSnapshots.track(&req, false /* queued */)
+	defer r.store.outgoingSnapshots.untrack(trackID)
 	if err := r.store.cfg.Transport.SendSnapshot(
 		ctx,
 		&r.store.cfg.RaftConfig,
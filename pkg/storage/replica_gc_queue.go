@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -52,6 +53,16 @@ const (
 	replicaGCPriorityRemoved = 2.0
 )
 
+// replicaGCQueueEnabled can be used to pause the replica GC queue, for
+// example while investigating an incident in which replicas may have been
+// removed incorrectly. Replicas can still be GC'ed explicitly through
+// Store.ForceReplicaGC while the queue is paused.
+var replicaGCQueueEnabled = settings.RegisterBoolSetting(
+	"kv.replica_gc_queue.enabled",
+	"whether the replica GC queue is enabled; if disabled, replicas are only GC'ed when forced",
+	true,
+)
+
 var (
 	metaReplicaGCQueueRemoveReplicaCount = metric.Metadata{
 		Name:        "queue.replicagc.removereplica",
@@ -106,6 +117,20 @@ func newReplicaGCQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *repl
 	return rgcq
 }
 
+func (rgcq *replicaGCQueue) enabled() bool {
+	st := rgcq.store.ClusterSettings()
+	return replicaGCQueueEnabled.Get(&st.SV)
+}
+
+// updatePausedMetric records whether the queue is currently paused.
+func (rgcq *replicaGCQueue) updatePausedMetric() {
+	var paused int64
+	if !rgcq.enabled() {
+		paused = 1
+	}
+	rgcq.store.metrics.ReplicaGCQueuePaused.Update(paused)
+}
+
 // shouldQueue determines whether a replica should be queued for GC,
 // and if so at what priority. To be considered for possible GC, a
 // replica's range lease must not have been active for longer than
@@ -115,6 +140,10 @@ func newReplicaGCQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *repl
 func (rgcq *replicaGCQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, _ *config.SystemConfig,
 ) (bool, float64) {
+	if !rgcq.enabled() {
+		return false, 0
+	}
+
 	lastCheck, err := repl.GetLastReplicaGCTimestamp(ctx)
 	if err != nil {
 		log.Errorf(ctx, "could not read last replica GC timestamp: %+v", err)
@@ -187,6 +216,17 @@ func replicaGCShouldQueueImpl(
 func (rgcq *replicaGCQueue) process(
 	ctx context.Context, repl *Replica, _ *config.SystemConfig,
 ) error {
+	if !rgcq.enabled() {
+		log.VEventf(ctx, 2, "skipping replica GC: queue has been disabled")
+		return nil
+	}
+	return rgcq.processReplica(ctx, repl)
+}
+
+// processReplica checks whether the replica is still a member of its range
+// and removes it if not. Unlike process, it runs even while the queue is
+// paused.
+func (rgcq *replicaGCQueue) processReplica(ctx context.Context, repl *Replica) error {
 	// Note that the Replicas field of desc is probably out of date, so
 	// we should only use `desc` for its static fields like RangeID and
 	// StartKey (and avoid rng.GetReplica() for the same reason).
@@ -45,6 +45,7 @@ type ReplicaMetrics struct {
 	LatchInfoLocal  storagepb.LatchManagerInfo
 	LatchInfoGlobal storagepb.LatchManagerInfo
 	RaftLogTooLarge bool
+	RaftLogSize     int64
 }
 
 // Metrics returns the current metrics for the replica.
@@ -130,6 +131,7 @@ func calcReplicaMetrics(
 
 	const raftLogTooLargeMultiple = 4
 	m.RaftLogTooLarge = raftLogSize > (raftLogTooLargeMultiple * raftCfg.RaftLogTruncationThreshold)
+	m.RaftLogSize = raftLogSize
 
 	return m
 }
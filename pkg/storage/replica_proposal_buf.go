@@ -186,10 +186,12 @@ func (b *propBuf) LastAssignedLeaseIndexRLocked() uint64 {
 // buffer, the assigned max lease index is returned.
 func (b *propBuf) Insert(p *ProposalData, data []byte) (uint64, error) {
 	// Request a new max lease applied index for any request that isn't itself
-	// a lease request. Lease requests don't need unique max lease index values
-	// because their max lease indexes are ignored. See checkForcedErrLocked.
+	// a lease request or a probe. Lease requests don't need unique max lease
+	// index values because their max lease indexes are ignored, and probes are
+	// never applied. See checkForcedErrLocked.
 	isLease := p.Request.IsLeaseRequest()
-	req := makePropBufCntReq(!isLease)
+	isProbe := p.Request.IsSingleProbeRequest()
+	req := makePropBufCntReq(!isLease && !isProbe)
 
 	// Hold the read lock while inserting into the proposal buffer. Other
 	// insertion attempts will also grab the read lock, so they can insert
@@ -235,9 +237,9 @@ func (b *propBuf) Insert(p *ProposalData, data []byte) (uint64, error) {
 	b.insertIntoArray(p, res.arrayIndex())
 
 	// Return the maximum lease index that the proposal's command was given.
-	if isLease {
-		// For lease requests, we return zero because no real MaxLeaseIndex is
-		// assigned. We could also return command.MaxLeaseIndex but this invites
+	if isLease || isProbe {
+		// For lease requests and probes, we return zero because no real
+		// MaxLeaseIndex is assigned. We could also return command.MaxLeaseIndex but this invites
 		// confusion.
 		return 0, nil
 	}
@@ -0,0 +1,135 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+var rateLimitLogLimiter = log.Every(10 * time.Second)
+
+// rangeReadRateLimit is the maximum rate of read requests to user data that
+// each range serves.
+var rangeReadRateLimit = settings.RegisterNonNegativeFloatSetting(
+	"kv.range.read_rate_limit",
+	"maximum number of read requests per second served by each range on "+
+		"user data, or 0 to disable",
+	0,
+)
+
+// rangeWriteRateLimit is the maximum rate of write requests to user data
+// that each range serves.
+var rangeWriteRateLimit = settings.RegisterNonNegativeFloatSetting(
+	"kv.range.write_rate_limit",
+	"maximum number of write requests per second served by each range on "+
+		"user data, or 0 to disable",
+	0,
+)
+
+// rangeRateLimitBurst is the number of requests that may be served by a
+// range in excess of its rate limit after a period of inactivity.
+var rangeRateLimitBurst = settings.RegisterPositiveIntSetting(
+	"kv.range.rate_limit_burst",
+	"number of requests which a range may serve in a burst above its read "+
+		"or write rate limit",
+	100,
+)
+
+// rateLimitableSpan contains the keys to which the per-range rate limits
+// apply. System data is exempt so that an abusive client cannot starve the
+// cluster's internal operations.
+var rateLimitableSpan = roachpb.Span{Key: keys.UserTableDataMin, EndKey: keys.TableDataMax}
+
+// replicaRateLimiter is a token bucket limiting the rate at which a replica
+// serves requests. The bucket is recreated whenever its configuration
+// changes.
+type replicaRateLimiter struct {
+	mu struct {
+		syncutil.Mutex
+		lim *rate.Limiter
+	}
+}
+
+func (l *replicaRateLimiter) limiter(limit float64, burst int) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.lim == nil || l.mu.lim.Limit() != rate.Limit(limit) || l.mu.lim.Burst() != burst {
+		l.mu.lim = rate.NewLimiter(rate.Limit(limit), burst)
+	}
+	return l.mu.lim
+}
+
+// canRateLimitBatch returns whether the provided BatchRequest is subject to
+// the per-range rate limits. Only batches containing point or range reads
+// and writes of user data are rate limited; transaction bookkeeping such as
+// heartbeats and intent resolution is not, so that rate limiting a client
+// does not prevent its transactions from being cleaned up.
+func canRateLimitBatch(ba *roachpb.BatchRequest) bool {
+	for _, ru := range ba.Requests {
+		req := ru.GetInner()
+		switch req.(type) {
+		case *roachpb.GetRequest, *roachpb.ScanRequest, *roachpb.ReverseScanRequest,
+			*roachpb.PutRequest, *roachpb.ConditionalPutRequest, *roachpb.InitPutRequest,
+			*roachpb.IncrementRequest, *roachpb.DeleteRequest, *roachpb.DeleteRangeRequest:
+		default:
+			continue
+		}
+		if rateLimitableSpan.Contains(req.Header().Span()) {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeRateLimitBatch blocks until the replica's read or write rate limit,
+// if one is configured, admits the batch. An error is returned if the
+// context is canceled, or if its deadline would expire before the batch is
+// admitted.
+func (r *Replica) maybeRateLimitBatch(ctx context.Context, ba *roachpb.BatchRequest) error {
+	var l *replicaRateLimiter
+	var limit float64
+	var delayed *metric.Counter
+	if ba.IsReadOnly() {
+		l, limit, delayed = &r.readLimiter, rangeReadRateLimit.Get(&r.store.cfg.Settings.SV),
+			r.store.metrics.RateLimitedReadRequests
+	} else if ba.IsWrite() {
+		l, limit, delayed = &r.writeLimiter, rangeWriteRateLimit.Get(&r.store.cfg.Settings.SV),
+			r.store.metrics.RateLimitedWriteRequests
+	} else {
+		return nil
+	}
+	if limit == 0 || !canRateLimitBatch(ba) {
+		return nil
+	}
+
+	lim := l.limiter(limit, int(rangeRateLimitBurst.Get(&r.store.cfg.Settings.SV)))
+	if lim.Allow() {
+		return nil
+	}
+	delayed.Inc(1)
+	if rateLimitLogLimiter.ShouldLog() {
+		log.Warningf(ctx, "rate limiting batch %s", ba)
+	}
+	if err := lim.Wait(ctx); err != nil {
+		return errors.Wrapf(err, "rate limited on range %s", r.Desc())
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// TestReplicaRateLimit verifies that reads of user data are delayed by the
+// per-range read rate limit, while reads of system data are exempt.
+func TestReplicaRateLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	tc := testContext{}
+	tc.Start(t, stopper)
+	sv := &tc.store.cfg.Settings.SV
+	rangeReadRateLimit.Override(sv, 0.001)
+	rangeRateLimitBurst.Override(sv, 1)
+
+	userKey := roachpb.Key(keys.MakeTablePrefix(keys.MinUserDescID))
+	gArgs := getArgs(userKey)
+	if _, pErr := tc.SendWrapped(&gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// The burst has been used up, so the next read would have to wait far
+	// longer than its deadline.
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, pErr := client.SendWrappedWith(
+		timeoutCtx, tc.Sender(), roachpb.Header{}, &gArgs,
+	); !testutils.IsPError(pErr, "rate limited") {
+		t.Fatalf("expected rate limit error, got %v", pErr)
+	}
+	if n := tc.store.metrics.RateLimitedReadRequests.Count(); n != 1 {
+		t.Fatalf("expected 1 rate limited read, found %d", n)
+	}
+
+	// System data is not rate limited.
+	sysArgs := getArgs(roachpb.Key("a"))
+	for i := 0; i < 3; i++ {
+		if _, pErr := tc.SendWrapped(&sysArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	// Disabling the limit admits reads of user data again.
+	rangeReadRateLimit.Override(sv, 0)
+	if _, pErr := tc.SendWrapped(&gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
+
+// TestReplicaRateLimitNotLeaseHolder verifies that batches redirected to the
+// leaseholder don't use up the rate limit of the replica they were sent to.
+func TestReplicaRateLimitNotLeaseHolder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	tc := testContext{manualClock: hlc.NewManualClock(123)}
+	cfg := TestStoreConfig(hlc.NewClock(tc.manualClock.UnixNano, time.Nanosecond))
+	cfg.TestingKnobs.DisableAutomaticLeaseRenewal = true
+	tc.StartWithStoreConfig(t, stopper, cfg)
+	sv := &tc.store.cfg.Settings.SV
+	rangeReadRateLimit.Override(sv, 0.001)
+	rangeWriteRateLimit.Override(sv, 0.001)
+	rangeRateLimitBurst.Override(sv, 1)
+
+	secondReplica, err := tc.addBogusReplicaToRangeDesc(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.manualClock.Set(leaseExpiry(tc.repl))
+	now := tc.Clock().Now()
+	if err := sendLeaseRequest(tc.repl, &roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(10, 0).Clone(),
+		Replica:    secondReplica,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	userKey := roachpb.Key(keys.MakeTablePrefix(keys.MinUserDescID))
+	gArgs := getArgs(userKey)
+	pArgs := putArgs(userKey, []byte("value"))
+	for i := 0; i < 3; i++ {
+		for _, args := range []roachpb.Request{&gArgs, &pArgs} {
+			_, pErr := tc.SendWrappedWith(roachpb.Header{Timestamp: now}, args)
+			if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+				t.Fatalf("%d: expected not lease holder error, got %v", i, pErr)
+			}
+		}
+	}
+	if n := tc.store.metrics.RateLimitedReadRequests.Count(); n != 0 {
+		t.Fatalf("expected no rate limited reads, found %d", n)
+	}
+	if n := tc.store.metrics.RateLimitedWriteRequests.Count(); n != 0 {
+		t.Fatalf("expected no rate limited writes, found %d", n)
+	}
+}
@@ -32,8 +32,14 @@ func (r *Replica) executeReadOnlyBatch(
 	// If the read is not inconsistent, the read requires the range lease or
 	// permission to serve via follower reads.
 	var status storagepb.LeaseStatus
-	if ba.ReadConsistency.RequiresReadLease() {
-		if status, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
+	if ba.BoundedStaleness != nil {
+		// Bounded staleness reads are served at or below the replica's closed
+		// timestamp, so they don't require the lease.
+		if pErr := r.startBoundedStalenessRead(ctx, ba); pErr != nil {
+			return nil, pErr
+		}
+	} else if ba.ReadConsistency.RequiresReadLease() {
+		if status, pErr = r.redirectOnOrAcquireLeaseWithBreaker(ctx); pErr != nil {
 			if nErr := r.canServeFollowerRead(ctx, ba, pErr); nErr != nil {
 				return nil, nErr
 			}
@@ -42,6 +48,13 @@ func (r *Replica) executeReadOnlyBatch(
 	}
 	r.limitTxnMaxTimestamp(ctx, ba, status)
 
+	// Rate limit the batch only once the replica is known to be able to serve
+	// it, and before acquiring latches so that a delayed batch doesn't block
+	// others.
+	if err := r.maybeRateLimitBatch(ctx, ba); err != nil {
+		return nil, roachpb.NewError(err)
+	}
+
 	spans, err := r.collectSpans(ba)
 	if err != nil {
 		return nil, roachpb.NewError(err)
@@ -50,7 +63,7 @@ func (r *Replica) executeReadOnlyBatch(
 	// Acquire latches to prevent overlapping commands from executing
 	// until this command completes.
 	log.Event(ctx, "acquire latches")
-	ec, err := r.beginCmds(ctx, ba, spans)
+	ec, err := r.beginCmdsWithBreaker(ctx, ba, spans)
 	if err != nil {
 		return nil, roachpb.NewError(err)
 	}
@@ -92,6 +105,19 @@ func (r *Replica) executeReadOnlyBatch(
 	}
 	defer readOnly.Close()
 	br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba, true /* readOnly */)
+	for ba.BoundedStaleness != nil && pErr != nil {
+		// The latches acquired at the original timestamp of the bounded
+		// staleness read also cover any lower timestamp.
+		var retry bool
+		if retry, pErr = lowerBoundedStalenessTimestamp(ctx, ba, pErr); !retry {
+			break
+		}
+		if err := r.requestCanProceed(rSpan, ba.Timestamp); err != nil {
+			pErr = roachpb.NewError(err)
+			break
+		}
+		br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba, true /* readOnly */)
+	}
 
 	// A merge is (likely) about to be carried out, and this replica
 	// needs to block all traffic until the merge either commits or
@@ -770,10 +770,10 @@ func testRaftSSTableSideloadingProposal(t *testing.T, engineInMem, mockSideloade
 	// former bug in which the raft log size took the sideloaded payload into account when adding
 	// to the log, but not when truncating.
 
-	// Write enough keys to the range to make sure that a truncation will happen.
-	for i := 0; i < RaftLogQueueStaleThreshold+1; i++ {
+	// Write enough data to the range to make sure that a truncation will happen.
+	for i := 0; i < 10; i++ {
 		key := roachpb.Key(fmt.Sprintf("key%02d", i))
-		args := putArgs(key, []byte(fmt.Sprintf("value%02d", i)))
+		args := putArgs(key, bytes.Repeat([]byte("v"), RaftLogQueueStaleSize/5))
 		if _, err := client.SendWrapped(context.Background(), tc.store.TestSender(), &args); err != nil {
 			t.Fatal(err)
 		}
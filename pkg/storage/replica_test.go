@@ -8461,6 +8461,7 @@ func TestReplicaMetrics(t *testing.T) {
 				Underreplicated: false,
 				BehindCount:     10,
 				RaftLogTooLarge: true,
+				RaftLogSize:     5 * cfg.RaftLogTruncationThreshold,
 			}},
 	}
 
@@ -68,6 +68,19 @@ func (r *Replica) executeWriteBatch(
 		return nil, roachpb.NewError(err)
 	}
 
+	// Rate limit the batch only once the replica is known to hold the lease,
+	// so that batches which are redirected to the leaseholder don't use up
+	// tokens, and before acquiring latches so that a delayed batch doesn't
+	// block others. The lease is checked again below, after latching.
+	if !ba.IsSingleSkipLeaseCheckRequest() && canRateLimitBatch(ba) {
+		if _, pErr := r.redirectOnOrAcquireLeaseWithBreaker(ctx); pErr != nil {
+			return nil, pErr
+		}
+		if err := r.maybeRateLimitBatch(ctx, ba); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+	}
+
 	// NB: must be performed before collecting request spans.
 	ba, err := maybeStripInFlightWrites(ba)
 	if err != nil {
@@ -87,7 +100,7 @@ func (r *Replica) executeWriteBatch(
 		// after preceding commands have been run to successful completion.
 		log.Event(ctx, "acquire latches")
 		var err error
-		ec, err = r.beginCmds(ctx, ba, spans)
+		ec, err = r.beginCmdsWithBreaker(ctx, ba, spans)
 		if err != nil {
 			return nil, roachpb.NewError(err)
 		}
@@ -103,13 +116,17 @@ func (r *Replica) executeWriteBatch(
 
 	var lease roachpb.Lease
 	var status storagepb.LeaseStatus
-	// For lease commands, use the provided previous lease for verification.
-	if ba.IsSingleSkipLeaseCheckRequest() {
+	if ba.IsSingleProbeRequest() {
+		// Probes are proposed under whichever lease the replica knows of. They
+		// are rejected below Raft regardless.
+		lease, _ = r.GetLease()
+	} else if ba.IsSingleSkipLeaseCheckRequest() {
+		// For lease commands, use the provided previous lease for verification.
 		lease = ba.GetPrevLeaseForLeaseRequest()
 	} else {
 		// Other write commands require that this replica has the range
 		// lease.
-		if status, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
+		if status, pErr = r.redirectOnOrAcquireLeaseWithBreaker(ctx); pErr != nil {
 			return nil, pErr
 		}
 		lease = status.Lease
@@ -172,6 +189,11 @@ func (r *Replica) executeWriteBatch(
 	slowTimer := timeutil.NewTimer()
 	defer slowTimer.Stop()
 	slowTimer.Reset(base.SlowRequestThreshold)
+	var breakerTimer timeutil.Timer
+	defer breakerTimer.Stop()
+	if threshold := r.breaker.slowReplicationThreshold(); threshold > 0 {
+		breakerTimer.Reset(threshold)
+	}
 	tBegin := timeutil.Now()
 
 	for {
@@ -227,6 +249,16 @@ and the following Raft status: %+v`,
 				)
 			}()
 
+		case <-breakerTimer.C:
+			breakerTimer.Read = true
+			// The command has not been applied within the slow replication
+			// threshold. Trip the replica's circuit breaker so that subsequent
+			// requests fail fast, and return an AmbiguousResultError since the
+			// command may still execute.
+			abandon()
+			err := r.breaker.trip(ctx, errors.Errorf("proposal %s not applied after %.2fs",
+				ba.Summary(), timeutil.Since(tBegin).Seconds()))
+			return nil, roachpb.NewError(roachpb.NewAmbiguousResultError(err.Error()))
 		case <-ctxDone:
 			// If our context was canceled, return an AmbiguousResultError,
 			// which indicates to the caller that the command may have executed.
@@ -0,0 +1,198 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// snapshotReservationQueue limits the number of non-empty snapshots that may
+// be applied concurrently on a store. Unlike a plain semaphore, waiters are
+// admitted in priority order: RECOVERY snapshots, which are needed to restore
+// an under-replicated range, are admitted ahead of REBALANCE snapshots that
+// may have been waiting longer. Waiters of equal priority are admitted in
+// FIFO order.
+type snapshotReservationQueue struct {
+	mu struct {
+		syncutil.Mutex
+		limit   int
+		inUse   int
+		seq     uint64
+		waiters snapshotWaiterHeap
+	}
+}
+
+// snapshotWaiter is a request for a reservation that is blocked waiting for
+// one of the in-use reservations to be released.
+type snapshotWaiter struct {
+	priority  SnapshotRequest_Priority
+	rangeID   roachpb.RangeID
+	rangeSize int64
+	enqueued  time.Time
+	seq       uint64
+	// admitted is closed when the waiter has been granted a reservation.
+	admitted chan struct{}
+	index    int
+}
+
+// snapshotPriorityRank returns the admission rank of a snapshot priority;
+// lower ranks are admitted first. Snapshots of unknown priority (sent by
+// nodes which predate the priority field) are treated as recovery snapshots
+// since those were the only snapshots that could block on this queue.
+func snapshotPriorityRank(p SnapshotRequest_Priority) int {
+	switch p {
+	case SnapshotRequest_REBALANCE:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type snapshotWaiterHeap []*snapshotWaiter
+
+var _ heap.Interface = (*snapshotWaiterHeap)(nil)
+
+func (h snapshotWaiterHeap) Len() int { return len(h) }
+
+func (h snapshotWaiterHeap) Less(i, j int) bool {
+	ri, rj := snapshotPriorityRank(h[i].priority), snapshotPriorityRank(h[j].priority)
+	if ri != rj {
+		return ri < rj
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h snapshotWaiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *snapshotWaiterHeap) Push(x interface{}) {
+	w := x.(*snapshotWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *snapshotWaiterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
+
+func newSnapshotReservationQueue(limit int) *snapshotReservationQueue {
+	q := &snapshotReservationQueue{}
+	q.mu.limit = limit
+	return q
+}
+
+// tryAcquire acquires a reservation if one is immediately available and no
+// other request is waiting for one.
+func (q *snapshotReservationQueue) tryAcquire() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.mu.inUse < q.mu.limit && len(q.mu.waiters) == 0 {
+		q.mu.inUse++
+		return true
+	}
+	return false
+}
+
+// acquire blocks until a reservation is granted to the snapshot described by
+// header, the context is canceled or the stopper quiesces.
+func (q *snapshotReservationQueue) acquire(
+	ctx context.Context, shouldStop <-chan struct{}, header *SnapshotRequest_Header,
+) error {
+	q.mu.Lock()
+	if q.mu.inUse < q.mu.limit && len(q.mu.waiters) == 0 {
+		q.mu.inUse++
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.seq++
+	w := &snapshotWaiter{
+		priority:  header.Priority,
+		rangeSize: header.RangeSize,
+		enqueued:  timeutil.Now(),
+		seq:       q.mu.seq,
+		admitted:  make(chan struct{}),
+	}
+	if header.State.Desc != nil {
+		w.rangeID = header.State.Desc.RangeID
+	}
+	heap.Push(&q.mu.waiters, w)
+	q.mu.Unlock()
+
+	var err error
+	select {
+	case <-w.admitted:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-shouldStop:
+		err = errors.Errorf("stopped")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w.index < 0 {
+		// We raced with being admitted. Pass the reservation on to the next
+		// waiter, if any.
+		q.releaseLocked()
+	} else {
+		heap.Remove(&q.mu.waiters, w.index)
+	}
+	return err
+}
+
+// release returns a reservation to the queue, admitting the highest priority
+// waiter if there is one.
+func (q *snapshotReservationQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked()
+}
+
+func (q *snapshotReservationQueue) releaseLocked() {
+	if len(q.mu.waiters) > 0 {
+		// Hand the reservation directly to the next waiter.
+		w := heap.Pop(&q.mu.waiters).(*snapshotWaiter)
+		close(w.admitted)
+		return
+	}
+	q.mu.inUse--
+}
+
+// inUse returns the number of reservations currently held.
+func (q *snapshotReservationQueue) inUse() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.mu.inUse
+}
+
+// numWaiting returns the number of requests blocked waiting for a
+// reservation.
+func (q *snapshotReservationQueue) numWaiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.mu.waiters)
+}
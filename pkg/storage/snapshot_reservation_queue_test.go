@@ -0,0 +1,113 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+// TestSnapshotReservationQueuePriority verifies that recovery snapshots are
+// admitted ahead of rebalance snapshots that have been waiting longer.
+func TestSnapshotReservationQueuePriority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	stopCh := make(chan struct{})
+	q := newSnapshotReservationQueue(1)
+	if !q.tryAcquire() {
+		t.Fatal("expected to acquire reservation")
+	}
+	if q.tryAcquire() {
+		t.Fatal("unexpectedly acquired second reservation")
+	}
+
+	admittedCh := make(chan SnapshotRequest_Priority, 3)
+	waitFor := func(n int) {
+		testutils.SucceedsSoon(t, func() error {
+			if w := q.numWaiting(); w != n {
+				return errors.Errorf("expected %d waiters, found %d", n, w)
+			}
+			return nil
+		})
+	}
+	enqueue := func(p SnapshotRequest_Priority) {
+		go func() {
+			if err := q.acquire(ctx, stopCh, &SnapshotRequest_Header{RangeSize: 1, Priority: p}); err != nil {
+				t.Error(err)
+				return
+			}
+			admittedCh <- p
+		}()
+	}
+
+	enqueue(SnapshotRequest_REBALANCE)
+	waitFor(1)
+	enqueue(SnapshotRequest_REBALANCE)
+	waitFor(2)
+	enqueue(SnapshotRequest_RECOVERY)
+	waitFor(3)
+
+	for _, expected := range []SnapshotRequest_Priority{
+		SnapshotRequest_RECOVERY, SnapshotRequest_REBALANCE, SnapshotRequest_REBALANCE,
+	} {
+		q.release()
+		if p := <-admittedCh; p != expected {
+			t.Fatalf("expected %s snapshot to be admitted, found %s", expected, p)
+		}
+		if n := q.inUse(); n != 1 {
+			t.Fatalf("expected 1 reservation in use, found %d", n)
+		}
+	}
+	q.release()
+	if n := q.inUse(); n != 0 {
+		t.Fatalf("expected no reservations in use, found %d", n)
+	}
+}
+
+// TestSnapshotReservationQueueCancel verifies that a waiter whose context is
+// canceled is removed from the queue and does not leak a reservation.
+func TestSnapshotReservationQueueCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	q := newSnapshotReservationQueue(1)
+	if !q.tryAcquire() {
+		t.Fatal("expected to acquire reservation")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- q.acquire(ctx, nil /* shouldStop */, &SnapshotRequest_Header{RangeSize: 1})
+	}()
+	testutils.SucceedsSoon(t, func() error {
+		if w := q.numWaiting(); w != 1 {
+			return errors.Errorf("expected 1 waiter, found %d", w)
+		}
+		return nil
+	})
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Fatalf("expected %v, found %v", context.Canceled, err)
+	}
+	if w := q.numWaiting(); w != 0 {
+		t.Fatalf("expected no waiters, found %d", w)
+	}
+
+	q.release()
+	if !q.tryAcquire() {
+		t.Fatal("expected to acquire reservation after release")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// SnapshotInfo describes a snapshot which is being sent or received by a
+// store, or which is waiting for a reservation to be received.
+type SnapshotInfo struct {
+	RangeID  roachpb.RangeID
+	Type     SnapshotRequest_Type
+	Priority SnapshotRequest_Priority
+	// Size is the size of the range as reported by the sender.
+	Size int64
+	// From and To are the sending and receiving replicas.
+	From, To roachpb.ReplicaDescriptor
+	// Queued is true for incoming snapshots which are waiting for a
+	// reservation.
+	Queued bool
+	// Age is the time since the snapshot was first tracked.
+	Age time.Duration
+}
+
+// snapshotTracker keeps track of the snapshots a store is sending or
+// receiving, so that stalled snapshots can be diagnosed.
+type snapshotTracker struct {
+	mu struct {
+		syncutil.Mutex
+		nextID    int64
+		snapshots map[int64]*trackedSnapshot
+	}
+}
+
+type trackedSnapshot struct {
+	info  SnapshotInfo
+	start time.Time
+}
+
+// track starts tracking the snapshot described by header and returns an ID
+// which must be passed to untrack when the snapshot is done.
+func (t *snapshotTracker) track(header *SnapshotRequest_Header, queued bool) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.snapshots == nil {
+		t.mu.snapshots = make(map[int64]*trackedSnapshot)
+	}
+	t.mu.nextID++
+	t.mu.snapshots[t.mu.nextID] = &trackedSnapshot{
+		info: SnapshotInfo{
+			RangeID:  header.RaftMessageRequest.RangeID,
+			Type:     header.Type,
+			Priority: header.Priority,
+			Size:     header.RangeSize,
+			From:     header.RaftMessageRequest.FromReplica,
+			To:       header.RaftMessageRequest.ToReplica,
+			Queued:   queued,
+		},
+		start: timeutil.Now(),
+	}
+	return t.mu.nextID
+}
+
+// dequeue marks a queued snapshot as having obtained its reservation.
+func (t *snapshotTracker) dequeue(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if snap, ok := t.mu.snapshots[id]; ok {
+		snap.info.Queued = false
+	}
+}
+
+func (t *snapshotTracker) untrack(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.mu.snapshots, id)
+}
+
+// snapshots returns the tracked snapshots, oldest first.
+func (t *snapshotTracker) snapshots() []SnapshotInfo {
+	now := timeutil.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// IDs are assigned in tracking order.
+	ids := make([]int64, 0, len(t.mu.snapshots))
+	for id := range t.mu.snapshots {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	infos := make([]SnapshotInfo, len(ids))
+	for i, id := range ids {
+		snap := t.mu.snapshots[id]
+		infos[i] = snap.info
+		infos[i].Age = now.Sub(snap.start)
+	}
+	return infos
+}
+
+// IncomingSnapshots returns the snapshots the store is receiving or waiting
+// to receive, oldest first.
+func (s *Store) IncomingSnapshots() []SnapshotInfo {
+	return s.incomingSnapshots.snapshots()
+}
+
+// OutgoingSnapshots returns the snapshots the store is sending, oldest
+// first.
+func (s *Store) OutgoingSnapshots() []SnapshotInfo {
+	return s.outgoingSnapshots.snapshots()
+}
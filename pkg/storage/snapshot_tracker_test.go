@@ -0,0 +1,54 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSnapshotTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var tr snapshotTracker
+	header := func(rangeID int) *SnapshotRequest_Header {
+		h := &SnapshotRequest_Header{
+			RangeSize: int64(rangeID) << 20,
+			Priority:  SnapshotRequest_RECOVERY,
+			Type:      SnapshotRequest_RAFT,
+		}
+		h.RaftMessageRequest.RangeID = roachpb.RangeID(rangeID)
+		return h
+	}
+	id1 := tr.track(header(1), true /* queued */)
+	id2 := tr.track(header(2), true /* queued */)
+	tr.dequeue(id2)
+
+	snaps := tr.snapshots()
+	if len(snaps) != 2 {
+		t.Fatalf("expected 2 snapshots, found %+v", snaps)
+	}
+	// Snapshots are returned oldest first.
+	if snaps[0].RangeID != 1 || !snaps[0].Queued || snaps[0].Size != 1<<20 {
+		t.Fatalf("unexpected snapshot: %+v", snaps[0])
+	}
+	if snaps[1].RangeID != 2 || snaps[1].Queued {
+		t.Fatalf("unexpected snapshot: %+v", snaps[1])
+	}
+
+	tr.untrack(id1)
+	tr.untrack(id2)
+	if snaps := tr.snapshots(); len(snaps) != 0 {
+		t.Fatalf("expected no snapshots, found %+v", snaps)
+	}
+}
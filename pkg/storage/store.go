@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/container"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/compactor"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
@@ -114,6 +115,22 @@ var bulkIOWriteLimit = settings.RegisterByteSizeSetting(
 	1<<40,
 )
 
+// exportRequestMaxRate limits the rate at which a store writes exported data
+// to external storage.
+var exportRequestMaxRate = settings.RegisterByteSizeSetting(
+	"kv.bulk_io_write.export_max_rate",
+	"the rate limit (bytes/sec) to use for the data exported by a store to external storage",
+	1<<40,
+)
+
+// importRequestMaxRate limits the rate at which a store reads the backup data
+// it ingests.
+var importRequestMaxRate = settings.RegisterByteSizeSetting(
+	"kv.bulk_io_write.import_max_rate",
+	"the rate limit (bytes/sec) to use for the backup data ingested by a store from external storage",
+	1<<40,
+)
+
 // importRequestsLimit limits concurrent import requests.
 var importRequestsLimit = settings.RegisterPositiveIntSetting(
 	"kv.bulk_io_write.concurrent_import_requests",
@@ -442,7 +459,11 @@ type Store struct {
 	initComplete sync.WaitGroup // Signaled by async init tasks
 
 	// Semaphore to limit concurrent non-empty snapshot application.
-	snapshotApplySem chan struct{}
+	snapshotApplySem *snapshotReservationQueue
+	// Track the snapshots being received (or waiting for a reservation) and
+	// sent by the store, for observability.
+	incomingSnapshots snapshotTracker
+	outgoingSnapshots snapshotTracker
 
 	// Track newly-acquired expiration-based leases that we want to proactively
 	// renew. An object is sent on the signal whenever a new entry is added to
@@ -635,6 +656,9 @@ type StoreConfig struct {
 	NodeDialer              *nodedialer.Dialer
 	RPCContext              *rpc.Context
 	RangeDescriptorCache    kvbase.RangeDescriptorCache
+	// ContentionRegistry records the transactions pushed by the store's
+	// requests. It may be nil.
+	ContentionRegistry *contention.Registry
 
 	ClosedTimestamp *container.Container
 
@@ -689,6 +713,11 @@ type StoreConfig struct {
 	// shared by all Raft groups managed by the store.
 	RaftEntryCacheSize uint64
 
+	// RaftLogSizes tracks the total size of the Raft logs on this node. It is
+	// shared by all stores on the node and used to enforce the
+	// kv.raft_log.node_max_size limit.
+	RaftLogSizes *RaftLogSizeTracker
+
 	// IntentResolverTaskLimit is the maximum number of asynchronous tasks that
 	// may be started by the intent resolver. -1 indicates no asynchronous tasks
 	// are allowed. 0 uses the default value (defaultIntentResolverTaskLimit)
@@ -754,6 +783,9 @@ func (sc *StoreConfig) SetDefaults() {
 	if sc.RaftEntryCacheSize == 0 {
 		sc.RaftEntryCacheSize = defaultRaftEntryCacheSize
 	}
+	if sc.RaftLogSizes == nil {
+		sc.RaftLogSizes = NewRaftLogSizeTracker()
+	}
 	if sc.concurrentSnapshotApplyLimit == 0 {
 		// NB: setting this value higher than 1 is likely to degrade client
 		// throughput.
@@ -849,7 +881,7 @@ func NewStore(
 	)
 	s.metrics.registry.AddMetricStruct(s.compactor.Metrics)
 
-	s.snapshotApplySem = make(chan struct{}, cfg.concurrentSnapshotApplyLimit)
+	s.snapshotApplySem = newSnapshotReservationQueue(cfg.concurrentSnapshotApplyLimit)
 
 	s.renewableLeasesSignal = make(chan struct{})
 
@@ -863,9 +895,19 @@ func NewStore(
 	importRequestsLimit.SetOnChange(&cfg.Settings.SV, func() {
 		s.limiters.ConcurrentImportRequests.SetLimit(int(importRequestsLimit.Get(&cfg.Settings.SV)))
 	})
+	s.limiters.ImportRequestRate = rate.NewLimiter(
+		rate.Limit(importRequestMaxRate.Get(&cfg.Settings.SV)), bulkIOWriteBurst)
+	importRequestMaxRate.SetOnChange(&cfg.Settings.SV, func() {
+		s.limiters.ImportRequestRate.SetLimit(rate.Limit(importRequestMaxRate.Get(&cfg.Settings.SV)))
+	})
 	s.limiters.ConcurrentExportRequests = limit.MakeConcurrentRequestLimiter(
 		"exportRequestLimiter", int(ExportRequestsLimit.Get(&cfg.Settings.SV)),
 	)
+	s.limiters.ExportRequestRate = rate.NewLimiter(
+		rate.Limit(exportRequestMaxRate.Get(&cfg.Settings.SV)), bulkIOWriteBurst)
+	exportRequestMaxRate.SetOnChange(&cfg.Settings.SV, func() {
+		s.limiters.ExportRequestRate.SetLimit(rate.Limit(exportRequestMaxRate.Get(&cfg.Settings.SV)))
+	})
 
 	// The snapshot storage is usually empty at this point since it is cleared
 	// after each snapshot application, except when the node crashed right before
@@ -902,7 +944,7 @@ func NewStore(
 	s.limiters.ConcurrentAddSSTableRequests = limit.MakeConcurrentRequestLimiter(
 		"addSSTableRequestLimiter", int(addSSTableRequestLimit.Get(&cfg.Settings.SV)),
 	)
-	importRequestsLimit.SetOnChange(&cfg.Settings.SV, func() {
+	addSSTableRequestLimit.SetOnChange(&cfg.Settings.SV, func() {
 		s.limiters.ConcurrentAddSSTableRequests.SetLimit(int(addSSTableRequestLimit.Get(&cfg.Settings.SV)))
 	})
 	s.limiters.ConcurrentRangefeedIters = limit.MakeConcurrentRequestLimiter(
@@ -995,6 +1037,25 @@ func (s *Store) AnnotateCtx(ctx context.Context) context.Context {
 // to drain a store.
 const raftLeadershipTransferWait = 5 * time.Second
 
+// NumTransferableLeases returns the number of valid leases held by the
+// Store's Replicas which can be transferred to another replica, i.e. the
+// leases which SetDraining attempts to transfer away.
+func (s *Store) NumTransferableLeases() int {
+	now := s.Clock().Now()
+	var n int
+	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
+		r.mu.RLock()
+		lease, _ := r.getLeaseRLocked()
+		r.mu.RUnlock()
+		if len(r.Desc().Replicas().Voters()) > 1 &&
+			lease.OwnedBy(s.StoreID()) && r.IsLeaseValid(lease, now) {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
 // SetDraining (when called with 'true') causes incoming lease transfers to be
 // rejected, prevents all of the Store's Replicas from acquiring or extending
 // range leases, and attempts to transfer away any leases owned.
@@ -1324,6 +1385,7 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 
 	// Create the intent resolver.
 	s.intentResolver = intentresolver.New(intentresolver.Config{
+		Settings:             s.cfg.Settings,
 		Clock:                s.cfg.Clock,
 		DB:                   s.db,
 		Stopper:              stopper,
@@ -1331,6 +1393,7 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		AmbientCtx:           s.cfg.AmbientCtx,
 		TestingKnobs:         s.cfg.TestingKnobs.IntentResolverKnobs,
 		RangeDescriptorCache: s.cfg.RangeDescriptorCache,
+		ContentionRegistry:   s.cfg.ContentionRegistry,
 	})
 	s.metrics.registry.AddMetricStruct(s.intentResolver.Metrics)
 
@@ -4024,6 +4087,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		underreplicatedRangeCount int64
 		overreplicatedRangeCount  int64
 		behindCount               int64
+		raftLogSize               int64
 	)
 
 	timestamp := s.cfg.Clock.Now()
@@ -4068,6 +4132,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 			}
 		}
 		behindCount += metrics.BehindCount
+		raftLogSize += metrics.RaftLogSize
 		if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
 			averageQueriesPerSecond += qps
 		}
@@ -4095,6 +4160,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.UnderReplicatedRangeCount.Update(underreplicatedRangeCount)
 	s.metrics.OverReplicatedRangeCount.Update(overreplicatedRangeCount)
 	s.metrics.RaftLogFollowerBehindCount.Update(behindCount)
+	s.metrics.RaftLogSize.Update(raftLogSize)
+	s.cfg.RaftLogSizes.update(s.StoreID(), raftLogSize)
 
 	if !minMaxClosedTS.IsEmpty() {
 		nanos := timeutil.Since(minMaxClosedTS.GoTime()).Nanoseconds()
@@ -4115,6 +4182,9 @@ func (s *Store) ComputeMetrics(ctx context.Context, tick int) error {
 	if err := s.updateReplicationGauges(ctx); err != nil {
 		return err
 	}
+	if s.replicaGCQueue != nil {
+		s.replicaGCQueue.updatePausedMetric()
+	}
 
 	// Get the latest RocksDB stats.
 	stats, err := s.engine.GetStats()
@@ -4138,8 +4208,8 @@ func (s *Store) ComputeMetrics(ctx context.Context, tick int) error {
 		s.metrics.RdbReadAmplification.Update(int64(readAmp))
 		// Log this metric infrequently.
 		if tick%logSSTInfoTicks == 0 /* every 10m */ {
-			log.Infof(ctx, "sstables (read amplification = %d):\n%s", readAmp, sstables)
-			log.Infof(ctx, "%sestimated_pending_compaction_bytes: %s",
+			log.Storage.Infof(ctx, "sstables (read amplification = %d):\n%s", readAmp, sstables)
+			log.Storage.Infof(ctx, "%sestimated_pending_compaction_bytes: %s",
 				rocksdb.GetCompactionStats(), humanizeutil.IBytes(stats.PendingCompactionBytesEstimate))
 		}
 	}
@@ -4221,6 +4291,19 @@ func (s *Store) AllocatorDryRun(
 	return collect(), nil
 }
 
+// AllocatorSimulateDecommission runs the allocator to choose the stores that
+// would replace the replicas of the range on the given nodes if they were
+// decommissioned, without carrying out any changes. Intended to help power an
+// admin endpoint. See Allocator.SimulateDecommission.
+func (s *Store) AllocatorSimulateDecommission(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	zone *config.ZoneConfig,
+	decommissioning map[roachpb.NodeID]bool,
+) ([]roachpb.ReplicaDescriptor, error) {
+	return s.allocator.SimulateDecommission(ctx, desc, zone, decommissioning)
+}
+
 // ManuallyEnqueue runs the given replica through the requested queue,
 // returning all trace events collected along the way as well as the error
 // message returned from the queue's process method, if any.  Intended to help
@@ -4281,6 +4364,24 @@ func (s *Store) ManuallyEnqueue(
 	return collect(), "", nil
 }
 
+// ForceReplicaGC checks whether the store's replica of the given range is
+// still a member of the range and removes it if not. Unlike ManuallyEnqueue,
+// it does so even while the replica GC queue is paused. Intended for use
+// during incident response.
+func (s *Store) ForceReplicaGC(ctx context.Context, rangeID roachpb.RangeID) error {
+	if s.replicaGCQueue == nil {
+		return errors.Errorf("%s: replica GC queue is not available", s)
+	}
+	repl, err := s.GetReplica(rangeID)
+	if err != nil {
+		return err
+	}
+	ctx = repl.AnnotateCtx(ctx)
+	s.metrics.ReplicaGCQueueForced.Inc(1)
+	log.Infof(ctx, "forcing replica GC of %s", repl)
+	return s.replicaGCQueue.processReplica(ctx, repl)
+}
+
 // GetClusterVersion reads the the cluster version from the store-local version
 // key. Returns an empty version if the key is not found.
 func (s *Store) GetClusterVersion(ctx context.Context) (cluster.ClusterVersion, error) {
@@ -404,7 +404,8 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 
 		var raftStatus *raft.Status
 
-		preferred := sr.rq.allocator.preferredLeaseholders(zone, candidates)
+		preferred := sr.rq.allocator.preferredLeaseholders(zone,
+			sr.rq.allocator.leasePreferenceCandidates(desc.RangeID, localDesc.StoreID, candidates))
 		for _, candidate := range candidates {
 			if candidate.StoreID == localDesc.StoreID {
 				continue
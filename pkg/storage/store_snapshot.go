@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	crdberrors "github.com/cockroachdb/errors"
 	"github.com/pkg/errors"
@@ -351,7 +352,10 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 	assertStrategy(ctx, header, SnapshotRequest_KV_BATCH)
 
 	// Iterate over all keys using the provided iterator and stream out batches
-	// of key-values.
+	// of key-values. The progress of the operation sending the snapshot, if
+	// any, is reported relative to the size of the range.
+	op := tracing.OperationFromContext(ctx)
+	var bytesSent int64
 	n := 0
 	var b engine.Batch
 	for iter := snap.Iter; ; iter.Next() {
@@ -372,10 +376,14 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 		}
 
 		if int64(b.Len()) >= kvSS.batchSize {
+			bytesSent += int64(b.Len())
 			if err := kvSS.sendBatch(ctx, stream, b); err != nil {
 				return err
 			}
 			b = nil
+			if header.RangeSize > 0 {
+				op.SetProgress(float64(bytesSent) / float64(header.RangeSize))
+			}
 			// We no longer need the keys and values in the batch we just sent,
 			// so reset ReplicaDataIterator's allocator and allow its data to
 			// be garbage collected.
@@ -548,22 +556,15 @@ func (s *Store) reserveSnapshot(
 		if ok && (!maxCapacityCheck(storeDesc) || header.RangeSize > storeDesc.Capacity.Available) {
 			return nil, snapshotStoreTooFullMsg, nil
 		}
-		select {
-		case s.snapshotApplySem <- struct{}{}:
-		case <-ctx.Done():
-			return nil, "", ctx.Err()
-		case <-s.stopper.ShouldStop():
-			return nil, "", errors.Errorf("stopped")
-		default:
+		if !s.snapshotApplySem.tryAcquire() {
 			return nil, snapshotApplySemBusyMsg, nil
 		}
 	} else {
-		select {
-		case s.snapshotApplySem <- struct{}{}:
-		case <-ctx.Done():
-			return nil, "", ctx.Err()
-		case <-s.stopper.ShouldStop():
-			return nil, "", errors.Errorf("stopped")
+		// Non-declinable snapshots wait for a reservation. Recovery snapshots
+		// are admitted ahead of rebalance snapshots so that an under-replicated
+		// range is not stuck behind bulk rebalancing traffic.
+		if err := s.snapshotApplySem.acquire(ctx, s.stopper.ShouldStop(), header); err != nil {
+			return nil, "", err
 		}
 	}
 
@@ -590,7 +591,7 @@ func (s *Store) reserveSnapshot(
 		s.metrics.ReservedReplicaCount.Dec(1)
 		s.metrics.Reserved.Dec(header.RangeSize)
 		if header.RangeSize != 0 {
-			<-s.snapshotApplySem
+			s.snapshotApplySem.release()
 		}
 	}, "", nil
 }
@@ -785,6 +786,8 @@ func (s *Store) receiveSnapshot(
 		}
 	}
 
+	trackID := s.incomingSnapshots.track(header, true /* queued */)
+	defer s.incomingSnapshots.untrack(trackID)
 	cleanup, rejectionMsg, err := s.reserveSnapshot(ctx, header)
 	if err != nil {
 		return err
@@ -796,6 +799,7 @@ func (s *Store) receiveSnapshot(
 		})
 	}
 	defer cleanup()
+	s.incomingSnapshots.dequeue(trackID)
 
 	// Check to see if the snapshot can be applied but don't attempt to add
 	// a placeholder here, because we're not holding the replica's raftMu.
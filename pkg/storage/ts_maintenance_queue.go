@@ -18,15 +18,17 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/pkg/errors"
 )
 
 const (
-	// TimeSeriesMaintenanceInterval is the minimum interval between two
-	// time series maintenance runs on a replica.
+	// TimeSeriesMaintenanceInterval is the default minimum interval between
+	// two time series maintenance runs on a replica.
 	TimeSeriesMaintenanceInterval = 24 * time.Hour // daily
 
 	// TimeSeriesMaintenanceMemoryBudget is the maximum amount of memory that
@@ -34,6 +36,23 @@ const (
 	TimeSeriesMaintenanceMemoryBudget = int64(8 * 1024 * 1024) // 8MB
 )
 
+// timeSeriesMaintenanceInterval is the minimum interval between two time
+// series maintenance runs on a replica. Rollups and pruning of time series
+// data past the TTLs of the timeseries.storage.resolution_* settings happen at
+// most this often.
+var timeSeriesMaintenanceInterval = settings.RegisterValidatedDurationSetting(
+	"timeseries.storage.maintenance.interval",
+	"the minimum time between two rollups and deletions of the time series data of a range",
+	TimeSeriesMaintenanceInterval,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf(
+				"cannot set timeseries.storage.maintenance.interval to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
 // TimeSeriesDataStore is an interface defined in the storage package that can
 // be implemented by the higher-level time series system. This allows the
 // storage queues to run periodic time series maintenance; importantly, this
@@ -80,6 +99,7 @@ type TimeSeriesDataStore interface {
 type timeSeriesMaintenanceQueue struct {
 	*baseQueue
 	tsData         TimeSeriesDataStore
+	interval       func() time.Duration
 	replicaCountFn func() int
 	db             *client.DB
 	mem            mon.BytesMonitor
@@ -91,7 +111,10 @@ func newTimeSeriesMaintenanceQueue(
 	store *Store, db *client.DB, g *gossip.Gossip, tsData TimeSeriesDataStore,
 ) *timeSeriesMaintenanceQueue {
 	q := &timeSeriesMaintenanceQueue{
-		tsData:         tsData,
+		tsData: tsData,
+		interval: func() time.Duration {
+			return timeSeriesMaintenanceInterval.Get(&store.ClusterSettings().SV)
+		},
 		replicaCountFn: store.ReplicaCount,
 		db:             db,
 		mem: mon.MakeUnlimitedMonitor(
@@ -131,7 +154,7 @@ func (q *timeSeriesMaintenanceQueue) shouldQueue(
 		if err != nil {
 			return false, 0
 		}
-		shouldQ, priority = shouldQueueAgain(now, lpTS, TimeSeriesMaintenanceInterval)
+		shouldQ, priority = shouldQueueAgain(now, lpTS, q.interval())
 		if !shouldQ {
 			return
 		}
@@ -169,7 +192,7 @@ func (q *timeSeriesMaintenanceQueue) timer(duration time.Duration) time.Duration
 	if replicaCount == 0 {
 		return 0
 	}
-	replInterval := TimeSeriesMaintenanceInterval / time.Duration(replicaCount)
+	replInterval := q.interval() / time.Duration(replicaCount)
 	if replInterval < duration {
 		return 0
 	}
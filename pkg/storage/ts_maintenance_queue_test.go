@@ -209,6 +209,26 @@ func TestTimeSeriesMaintenanceQueue(t *testing.T) {
 		}
 		return nil
 	})
+
+	// Shorten the maintenance interval; the replicas are processed again once
+	// the shorter interval has elapsed.
+	if err := store.ClusterSettings().MakeUpdater().Set(
+		"timeseries.storage.maintenance.interval", "1h", "d",
+	); err != nil {
+		t.Fatal(err)
+	}
+	manual.Increment(time.Hour.Nanoseconds())
+	if err := store.ForceTimeSeriesMaintenanceQueueProcess(); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		model.Lock()
+		defer model.Unlock()
+		if a, e := model.pruneCalled, len(expectedStartKeys)*3; a != e {
+			return errors.Errorf("MaintainTimeSeries called %d times; expected %d", a, e)
+		}
+		return nil
+	})
 }
 
 // TestTimeSeriesMaintenanceQueueServer verifies that the time series
@@ -109,6 +109,14 @@ var charts = []sectionDescription{
 					"sys.cpu.user.ns",
 				},
 			},
+			{
+				Title:   "Cgroup Limit",
+				Metrics: []string{"sys.cpu.cgroup.limit"},
+			},
+			{
+				Title:   "Cgroup Throttled Time",
+				Metrics: []string{"sys.cpu.cgroup.throttled.ns"},
+			},
 		},
 	},
 	{
@@ -219,6 +227,20 @@ var charts = []sectionDescription{
 					"sys.host.disk.write.bytes",
 				},
 			},
+			{
+				Title: "IOPS",
+				Metrics: []string{
+					"sys.host.disk.read.iops",
+					"sys.host.disk.write.iops",
+				},
+			},
+			{
+				Title: "Throughput",
+				Metrics: []string{
+					"sys.host.disk.read.throughput",
+					"sys.host.disk.write.throughput",
+				},
+			},
 			{
 				Title: "Time",
 				Metrics: []string{
@@ -480,6 +502,13 @@ var charts = []sectionDescription{
 					"clock-offset.stddevnanos",
 				},
 			},
+			{
+				Title: "Maximum Offset",
+				Metrics: []string{
+					"clock-offset.maxabsnanos",
+					"clock-offset.maxoffsetnanos",
+				},
+			},
 		},
 	},
 	{
@@ -489,6 +518,14 @@ var charts = []sectionDescription{
 				Title:   "Closed Timestamp",
 				Metrics: []string{"kv.closed_timestamp.max_behind_nanos"},
 			},
+			{
+				Title:   "Closed Timestamp Lag",
+				Metrics: []string{"kv.closed_timestamp.lag_nanos"},
+			},
+			{
+				Title:   "Closed Timestamp Close Failures",
+				Metrics: []string{"kv.closed_timestamp.close_failures"},
+			},
 			{
 				Title:   "Count",
 				Metrics: []string{"follower_reads.success_count"},
@@ -594,6 +631,20 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{
+			{KVTransactionLayer, "Requests", "Rate Limiting"},
+		},
+		Charts: []chartDescription{
+			{
+				Title: "Requests Delayed by Range Rate Limits",
+				Metrics: []string{
+					"requests.ratelimit.read",
+					"requests.ratelimit.write",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{
 			{KVTransactionLayer, "Requests", "Slow"},
@@ -618,6 +669,18 @@ var charts = []sectionDescription{
 				Percentiles: false,
 				Metrics:     []string{"requests.slow.raft"},
 			},
+			{
+				Title:       "Tripped Circuit Breakers",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics:     []string{"kv.replica_circuit_breaker.num_tripped_replicas"},
+			},
+			{
+				Title:       "Circuit Breaker Trip Events",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics:     []string{"kv.replica_circuit_breaker.num_tripped_events"},
+			},
 		},
 	},
 	{
@@ -1076,6 +1139,10 @@ var charts = []sectionDescription{
 				Title:   "Followers Behind By...",
 				Metrics: []string{"raftlog.behind"},
 			},
+			{
+				Title:   "Size",
+				Metrics: []string{"raftlog.size"},
+			},
 		},
 	},
 	{
@@ -1230,6 +1297,14 @@ var charts = []sectionDescription{
 				Title:   "Pending",
 				Metrics: []string{"queue.replicagc.pending"},
 			},
+			{
+				Title:   "Paused",
+				Metrics: []string{"queue.replicagc.paused"},
+			},
+			{
+				Title:   "Forced",
+				Metrics: []string{"queue.replicagc.forced"},
+			},
 			{
 				Title:   "Removal Count",
 				Metrics: []string{"queue.replicagc.removereplica"},
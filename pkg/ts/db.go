@@ -47,7 +47,7 @@ var TimeseriesStorageEnabled = settings.RegisterBoolSetting(
 // Resolution10sStorageTTL defines the maximum age of data that will be retained
 // at he 10 second resolution. Data older than this is subject to being "rolled
 // up" into the 30 minute resolution and then deleted.
-var Resolution10sStorageTTL = settings.RegisterDurationSetting(
+var Resolution10sStorageTTL = settings.RegisterNonNegativeDurationSetting(
 	"timeseries.storage.resolution_10s.ttl",
 	"the maximum age of time series data stored at the 10 second resolution. Data older than this "+
 		"is subject to rollup and deletion.",
@@ -74,7 +74,7 @@ func init() {
 // Resolution30mStorageTTL defines the maximum age of data that will be
 // retained at he 30 minute resolution. Data older than this is subject to
 // deletion.
-var Resolution30mStorageTTL = settings.RegisterDurationSetting(
+var Resolution30mStorageTTL = settings.RegisterNonNegativeDurationSetting(
 	"timeseries.storage.resolution_30m.ttl",
 	"the maximum age of time series data stored at the 30 minute resolution. Data older than this "+
 		"is subject to deletion.",
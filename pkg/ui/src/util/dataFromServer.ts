@@ -15,6 +15,7 @@ export interface DataFromServer {
   Tag: string;
   Version: string;
   NodeID: string;
+  OIDCLoginEnabled: boolean;
 }
 
 // Tell TypeScript about `window.dataFromServer`, which is set in a script
@@ -36,6 +36,28 @@ export default function (props: GraphDashboardProps) {
       </Axis>
     </LineGraph>,
 
+    <LineGraph
+      title="CPU Throttled Time"
+      sources={nodeSources}
+      tooltip={(
+        <div>
+          Time for which the cgroup of the process was throttled by its CPU
+          quota {tooltipSelection}
+        </div>
+      )}
+    >
+      <Axis units={AxisUnits.Duration} label="throttled time">
+        {nodeIDs.map((nid) => (
+          <Metric
+            name="cr.node.sys.cpu.cgroup.throttled.ns"
+            title={nodeDisplayName(nodesSummary, nid)}
+            sources={[nid]}
+            nonNegativeRate
+          />
+        ))}
+      </Axis>
+    </LineGraph>,
+
     <LineGraph
       title="Memory Usage"
       sources={nodeSources}
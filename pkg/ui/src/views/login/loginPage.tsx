@@ -16,6 +16,7 @@ import { withRouter, WithRouterProps } from "react-router";
 
 import { doLogin, LoginAPIState } from "src/redux/login";
 import { AdminUIState } from "src/redux/state";
+import { getDataFromServer } from "src/util/dataFromServer";
 import * as docsURL from "src/util/docs";
 import { trustIcon } from "src/util/trust";
 import InfoBox from "src/views/shared/components/infoBox";
@@ -70,6 +71,17 @@ class LoginPage extends React.Component<LoginPageProps & WithRouterProps, LoginP
       });
   }
 
+  renderOIDCLogin() {
+    if (!getDataFromServer().OIDCLoginEnabled) {
+      return null;
+    }
+    return (
+      <a href="oidc/v1/login" className="submit-button login-page__oidc-button">
+        Log in with OIDC
+      </a>
+    );
+  }
+
   renderError() {
     const { error } = this.props.loginState;
 
@@ -152,6 +164,7 @@ class LoginPage extends React.Component<LoginPageProps & WithRouterProps, LoginP
                   value={this.props.loginState.inProgress ? "Logging in..." : "Log In"}
                 />
               </form>
+              {this.renderOIDCLogin()}
             </div>
           </section>
         </div>
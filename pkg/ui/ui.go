@@ -93,6 +93,7 @@ type indexHTMLArgs struct {
 	Tag                  string
 	Version              string
 	NodeID               string
+	OIDCLoginEnabled     bool
 }
 
 // bareIndexHTML is used in place of indexHTMLTemplate when the binary is built
@@ -107,8 +108,11 @@ Binary built without web UI.
 type Config struct {
 	ExperimentalUseLogin bool
 	LoginEnabled         bool
-	NodeID               *base.NodeIDContainer
-	GetUser              func(ctx context.Context) *string
+	// OIDCLoginEnabled returns whether the login page offers to log in with
+	// OIDC single sign-on.
+	OIDCLoginEnabled func() bool
+	NodeID           *base.NodeIDContainer
+	GetUser          func(ctx context.Context) *string
 }
 
 // Handler returns an http.Handler that serves the UI,
@@ -140,6 +144,7 @@ func Handler(cfg Config) http.Handler {
 			Tag:                  buildInfo.Tag,
 			Version:              build.VersionPrefix(),
 			NodeID:               cfg.NodeID.String(),
+			OIDCLoginEnabled:     cfg.OIDCLoginEnabled != nil && cfg.OIDCLoginEnabled(),
 		}); err != nil {
 			err = errors.Wrap(err, "templating index.html")
 			http.Error(w, err.Error(), 500)
@@ -0,0 +1,209 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package cron parses crontab expressions and computes the times at which
+// they fire.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Schedule is a parsed crontab expression.
+type Schedule struct {
+	// Each field is a bit set of the values which the field matches.
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day of month and day of week
+	// fields are unrestricted. As in cron, when both fields are restricted,
+	// a day matches if it matches either of them.
+	domStar, dowStar bool
+}
+
+// field describes one of the fields of a crontab expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors are the shorthands accepted in place of the five fields of a
+// crontab expression.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a crontab expression made of the five standard fields
+// (minute, hour, day of month, month and day of week), or one of the
+// @yearly, @monthly, @weekly, @daily and @hourly shorthands. Fields accept
+// lists, ranges, steps and, for months and days of the week, three-letter
+// names. As in most cron implementations, 7 is accepted for Sunday.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"invalid crontab expression %q: expected 5 fields, found %d", expr, len(fields))
+	}
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	// Sunday can be written as 7.
+	dow := field{name: dowField.name, min: 0, max: 7, names: dowField.names}
+	if s.dow, err = parseField(fields[4], dow); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return &s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps into
+// the bit set of the values they match.
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rangePart := part
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step %q in %s field", part[i+1:], f.name)
+			}
+			rangePart = part[:i]
+		}
+		if rangePart != "*" && rangePart != "?" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			switch {
+			case len(bounds) == 2:
+				if hi, err = parseValue(bounds[1], f); err != nil {
+					return 0, err
+				}
+			case step > 1:
+				// "a/n" means every n values starting at a.
+				hi = f.max
+			default:
+				hi = lo
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a single value of a field.
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q in %s field", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, errors.Errorf(
+			"value %d out of range [%d, %d] in %s field", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search of Next, so that expressions which never
+// fire, like February 30th, don't loop forever.
+const maxSearchYears = 5
+
+// Next returns the first time strictly after t at which the schedule fires,
+// in the location of t, or the zero time if the schedule never fires.
+func (s *Schedule) Next(t time.Time) time.Time {
+	// Start at the next whole minute.
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second -
+		time.Duration(t.Nanosecond()))
+	loc := t.Location()
+	limit := t.Year() + maxSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay returns whether the day of t matches the day of month and day
+// of week fields.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
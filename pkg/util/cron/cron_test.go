@@ -0,0 +1,71 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// 2019-10-15 was a Tuesday.
+	start := time.Date(2019, 10, 15, 10, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2019, 10, 15, 10, 31, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, 10, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 10, 15, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2019, 10, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2019, 10, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2019, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// When both days are restricted, either of them matches.
+		{"0 0 1 * fri", time.Date(2019, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 feb *", time.Time{}},
+	}
+	for _, tc := range testCases {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		if next := s.Next(start); !next.Equal(tc.expected) {
+			t.Errorf("%s: expected %s, got %s", tc.expr, tc.expected, next)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cockroachdb/apd"
+	"github.com/pkg/errors"
+)
+
+// This file contains routines decoding many consecutive values of the same
+// type in one call, into preallocated slices such as the column vectors of
+// the vectorized execution engine. They are equivalent to calling the
+// routines decoding a single value in a loop, without the overhead of a call
+// per value.
+
+// DecodeVarintsAscending decodes up to len(dst) consecutive values encoded
+// by EncodeVarintAscending from b into dst. It stops early at the first
+// value which isn't a varint, e.g. a NULL, and returns the remainder of b and
+// the number of values decoded.
+func DecodeVarintsAscending(b []byte, dst []int64) ([]byte, int, error) {
+	return decodeVarints(b, dst, false /* invert */)
+}
+
+// DecodeVarintsDescending is the descending equivalent of
+// DecodeVarintsAscending, for values encoded by EncodeVarintDescending.
+func DecodeVarintsDescending(b []byte, dst []int64) ([]byte, int, error) {
+	return decodeVarints(b, dst, true /* invert */)
+}
+
+func decodeVarints(b []byte, dst []int64, invert bool) ([]byte, int, error) {
+	for i := range dst {
+		if len(b) == 0 || b[0] < IntMin || b[0] > IntMax {
+			return b, i, nil
+		}
+		var v int64
+		length := int(b[0]) - intZero
+		switch {
+		case length < 0:
+			// Negative values are encoded with -length bytes. See
+			// DecodeVarintAscending.
+			length = -length
+			if len(b) <= length {
+				return nil, i, errors.Errorf("insufficient bytes to decode varint value: %q", b[1:])
+			}
+			for _, t := range b[1 : 1+length] {
+				v = (v << 8) | int64(^t)
+			}
+			v = ^v
+		case length <= intSmall:
+			// Small values are encoded in the tag.
+			v = int64(length)
+			length = 0
+		default:
+			length -= intSmall
+			if length > 8 {
+				return nil, i, errors.Errorf("invalid uvarint length of %d", length)
+			} else if len(b) <= length {
+				return nil, i, errors.Errorf("insufficient bytes to decode varint value: %q", b[1:])
+			}
+			var u uint64
+			for _, t := range b[1 : 1+length] {
+				u = (u << 8) | uint64(t)
+			}
+			if u > math.MaxInt64 {
+				return nil, i, errors.Errorf("varint %d overflows int64", u)
+			}
+			v = int64(u)
+		}
+		if invert {
+			v = ^v
+		}
+		dst[i] = v
+		b = b[1+length:]
+	}
+	return b, len(dst), nil
+}
+
+// DecodeDecimalsAscending decodes up to len(dst) consecutive values encoded
+// by EncodeDecimalAscending from b into dst. It stops early at the first
+// value which isn't a decimal, e.g. a NULL, and returns the remainder of b
+// and the number of values decoded.
+func DecodeDecimalsAscending(b []byte, dst []apd.Decimal) ([]byte, int, error) {
+	return decodeDecimals(b, dst, false /* invert */)
+}
+
+// DecodeDecimalsDescending is the descending equivalent of
+// DecodeDecimalsAscending, for values encoded by EncodeDecimalDescending.
+func DecodeDecimalsDescending(b []byte, dst []apd.Decimal) ([]byte, int, error) {
+	return decodeDecimals(b, dst, true /* invert */)
+}
+
+func decodeDecimals(b []byte, dst []apd.Decimal, invert bool) ([]byte, int, error) {
+	for i := range dst {
+		if len(b) == 0 || b[0] < decimalNaN || b[0] > decimalNaNDesc {
+			return b, i, nil
+		}
+		var err error
+		b, dst[i], err = decodeDecimal(b, nil /* tmp */, invert)
+		if err != nil {
+			return nil, i, err
+		}
+	}
+	return b, len(dst), nil
+}
+
+// DecodeUntaggedIntValues decodes len(dst) consecutive values encoded by
+// EncodeUntaggedIntValue from b into dst, and returns the remainder of b.
+func DecodeUntaggedIntValues(b []byte, dst []int64) ([]byte, error) {
+	for i := range dst {
+		v, n := binary.Varint(b)
+		if n <= 0 {
+			return nil, errors.Errorf("int64 varint decoding failed: %d", n)
+		}
+		dst[i] = v
+		b = b[n:]
+	}
+	return b, nil
+}
+
+// DecodeIntoUntaggedDecimalValues decodes len(dst) consecutive values
+// encoded by EncodeUntaggedDecimalValue from b into dst, and returns the
+// remainder of b. Like DecodeIntoUntaggedDecimalValue, it reuses the storage
+// of the decimals of dst.
+func DecodeIntoUntaggedDecimalValues(dst []apd.Decimal, b []byte) ([]byte, error) {
+	for i := range dst {
+		l, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("buffer too small")
+		}
+		b = b[n:]
+		if uint64(len(b)) < l {
+			return nil, errors.Errorf("insufficient bytes to decode decimal value: %q", b)
+		}
+		if err := DecodeIntoNonsortingDecimal(&dst[i], b[:l], nil /* tmp */); err != nil {
+			return nil, err
+		}
+		b = b[l:]
+	}
+	return b, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func randVarints(n int) []int64 {
+	rng, _ := randutil.NewPseudoRand()
+	vals := []int64{0, 1, -1, math.MinInt64, math.MaxInt64, intSmall, intSmall + 1}
+	for len(vals) < n {
+		// Vary the number of bytes of the encodings.
+		vals = append(vals, rng.Int63()>>uint(rng.Intn(64))*int64(1-2*rng.Intn(2)))
+	}
+	return vals[:n]
+}
+
+func TestDecodeVarints(t *testing.T) {
+	vals := randVarints(1000)
+	for _, tc := range []struct {
+		encode func([]byte, int64) []byte
+		decode func([]byte, []int64) ([]byte, int, error)
+		null   func([]byte) []byte
+	}{
+		{EncodeVarintAscending, DecodeVarintsAscending, EncodeNullAscending},
+		{EncodeVarintDescending, DecodeVarintsDescending, EncodeNullDescending},
+	} {
+		var b []byte
+		for _, v := range vals {
+			b = tc.encode(b, v)
+		}
+		// The decoding stops at the NULL following the values.
+		b = tc.null(b)
+		dst := make([]int64, len(vals)+1)
+		rem, n, err := tc.decode(b, dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(vals) || len(rem) != 1 {
+			t.Fatalf("expected to decode %d values up to the NULL, decoded %d with %d bytes left",
+				len(vals), n, len(rem))
+		}
+		for i, v := range vals {
+			if dst[i] != v {
+				t.Fatalf("%d: expected %d, got %d", i, v, dst[i])
+			}
+		}
+
+		// Truncated encodings are errors.
+		if _, _, err := tc.decode(tc.encode(nil, math.MaxInt64)[:3], dst); err == nil {
+			t.Error("expected an error decoding a truncated varint")
+		}
+	}
+
+	var b []byte
+	for _, v := range vals {
+		b = EncodeUntaggedIntValue(b, v)
+	}
+	dst := make([]int64, len(vals))
+	if rem, err := DecodeUntaggedIntValues(b, dst); err != nil {
+		t.Fatal(err)
+	} else if len(rem) != 0 {
+		t.Fatalf("unexpected remaining bytes %x", rem)
+	}
+	for i, v := range vals {
+		if dst[i] != v {
+			t.Fatalf("%d: expected %d, got %d", i, v, dst[i])
+		}
+	}
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	rng, _ := randutil.NewPseudoRand()
+	vals := []*apd.Decimal{
+		{Form: apd.NaN}, {Form: apd.Infinite}, {Form: apd.Infinite, Negative: true}, {},
+	}
+	for len(vals) < 1000 {
+		vals = append(vals, randDecimal(rng, -20, 20))
+	}
+
+	for _, tc := range []struct {
+		encode       func([]byte, *apd.Decimal) []byte
+		decode       func([]byte, []apd.Decimal) ([]byte, int, error)
+		decodeSingle func([]byte, []byte) ([]byte, apd.Decimal, error)
+	}{
+		{EncodeDecimalAscending, DecodeDecimalsAscending, DecodeDecimalAscending},
+		{EncodeDecimalDescending, DecodeDecimalsDescending, DecodeDecimalDescending},
+	} {
+		var b []byte
+		// Key encodings don't preserve the exponent of the values, so the
+		// batched decoding is compared to the decoding of single values.
+		expected := make([]apd.Decimal, len(vals))
+		for i, v := range vals {
+			enc := tc.encode(nil, v)
+			var err error
+			if _, expected[i], err = tc.decodeSingle(enc, nil); err != nil {
+				t.Fatal(err)
+			}
+			b = append(b, enc...)
+		}
+		b = EncodeNullAscending(b)
+		dst := make([]apd.Decimal, len(vals)+1)
+		rem, n, err := tc.decode(b, dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(vals) || len(rem) != 1 {
+			t.Fatalf("expected to decode %d values up to the NULL, decoded %d with %d bytes left",
+				len(vals), n, len(rem))
+		}
+		for i := range expected {
+			if dst[i].CmpTotal(&expected[i]) != 0 {
+				t.Fatalf("%d: expected %s, got %s", i, &expected[i], &dst[i])
+			}
+		}
+	}
+
+	var b []byte
+	for _, v := range vals {
+		b = EncodeUntaggedDecimalValue(b, v)
+	}
+	dst := make([]apd.Decimal, len(vals))
+	if rem, err := DecodeIntoUntaggedDecimalValues(dst, b); err != nil {
+		t.Fatal(err)
+	} else if len(rem) != 0 {
+		t.Fatalf("unexpected remaining bytes %x", rem)
+	}
+	for i, v := range vals {
+		if dst[i].CmpTotal(v) != 0 {
+			t.Fatalf("%d: expected %s, got %s", i, v, &dst[i])
+		}
+	}
+}
+
+func BenchmarkDecodeVarints(b *testing.B) {
+	const batchSize = 1024
+	var buf []byte
+	for _, v := range randVarints(batchSize) {
+		buf = EncodeVarintAscending(buf, v)
+	}
+	dst := make([]int64, batchSize)
+
+	b.Run("single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rem := buf
+			for j := range dst {
+				rem, dst[j], _ = DecodeVarintAscending(rem)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = DecodeVarintsAscending(buf, dst)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"time"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/util/bitarray"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/pkg/errors"
+)
+
+// NullsOrder specifies whether NULLs sort before or after the other values
+// of a column.
+type NullsOrder int
+
+// NullsOrder values.
+const (
+	_ NullsOrder = iota
+	NullsFirst
+	NullsLast
+)
+
+// KeyColumn specifies the order of a column of a composite key: a single
+// ordered byte string built by appending the encodings of the columns of a
+// tuple. Comparing two composite keys with bytes.Compare orders their tuples
+// column by column, each column according to its direction and NULLs order.
+//
+// The values of a column are encoded with the Ascending or Descending
+// routines of its direction. NULLs are encoded as encodedNull when first and
+// as encodedNullDesc when last, in either direction: neither byte starts the
+// encoding of any other value, and DecodeIfNull recognizes both. As a result,
+// the key of a column ordered by DefaultKeyColumn is the same as the one
+// produced by the routines of its direction.
+type KeyColumn struct {
+	Direction Direction
+	Nulls     NullsOrder
+}
+
+// DefaultKeyColumn returns the KeyColumn of the given direction used by
+// index keys, where NULLs sort first in ascending order and last in
+// descending order.
+func DefaultKeyColumn(dir Direction) KeyColumn {
+	if dir == Descending {
+		return KeyColumn{Direction: Descending, Nulls: NullsLast}
+	}
+	return KeyColumn{Direction: Ascending, Nulls: NullsFirst}
+}
+
+// Validate returns an error if the direction or the NULLs order of the column
+// is invalid.
+func (c KeyColumn) Validate() error {
+	if c.Direction != Ascending && c.Direction != Descending {
+		return errors.Errorf("invalid direction: %d", c.Direction)
+	}
+	if c.Nulls != NullsFirst && c.Nulls != NullsLast {
+		return errors.Errorf("invalid nulls order: %d", c.Nulls)
+	}
+	return nil
+}
+
+// Reverse returns the column ordered in the opposite direction, with NULLs
+// on the opposite side.
+func (c KeyColumn) Reverse() KeyColumn {
+	nulls := NullsFirst
+	if c.Nulls == NullsFirst {
+		nulls = NullsLast
+	}
+	return KeyColumn{Direction: c.Direction.Reverse(), Nulls: nulls}
+}
+
+// EncodeNull appends the encoding of a NULL in the column to b.
+func (c KeyColumn) EncodeNull(b []byte) []byte {
+	if c.Nulls == NullsLast {
+		return append(b, encodedNullDesc)
+	}
+	return append(b, encodedNull)
+}
+
+// EncodeVarint appends the encoding of an int64 in the column to b.
+func (c KeyColumn) EncodeVarint(b []byte, v int64) []byte {
+	if c.Direction == Descending {
+		return EncodeVarintDescending(b, v)
+	}
+	return EncodeVarintAscending(b, v)
+}
+
+// EncodeFloat appends the encoding of a float64 in the column to b.
+func (c KeyColumn) EncodeFloat(b []byte, f float64) []byte {
+	if c.Direction == Descending {
+		return EncodeFloatDescending(b, f)
+	}
+	return EncodeFloatAscending(b, f)
+}
+
+// EncodeDecimal appends the encoding of a decimal in the column to b.
+func (c KeyColumn) EncodeDecimal(b []byte, d *apd.Decimal) []byte {
+	if c.Direction == Descending {
+		return EncodeDecimalDescending(b, d)
+	}
+	return EncodeDecimalAscending(b, d)
+}
+
+// EncodeBytes appends the encoding of a byte slice in the column to b.
+func (c KeyColumn) EncodeBytes(b []byte, data []byte) []byte {
+	if c.Direction == Descending {
+		return EncodeBytesDescending(b, data)
+	}
+	return EncodeBytesAscending(b, data)
+}
+
+// EncodeString appends the encoding of a string in the column to b.
+func (c KeyColumn) EncodeString(b []byte, s string) []byte {
+	if c.Direction == Descending {
+		return EncodeStringDescending(b, s)
+	}
+	return EncodeStringAscending(b, s)
+}
+
+// EncodeTime appends the encoding of a time in the column to b.
+func (c KeyColumn) EncodeTime(b []byte, t time.Time) []byte {
+	if c.Direction == Descending {
+		return EncodeTimeDescending(b, t)
+	}
+	return EncodeTimeAscending(b, t)
+}
+
+// EncodeDuration appends the encoding of a duration in the column to b.
+func (c KeyColumn) EncodeDuration(b []byte, d duration.Duration) ([]byte, error) {
+	if c.Direction == Descending {
+		return EncodeDurationDescending(b, d)
+	}
+	return EncodeDurationAscending(b, d)
+}
+
+// EncodeBitArray appends the encoding of a bit array in the column to b.
+func (c KeyColumn) EncodeBitArray(b []byte, d bitarray.BitArray) []byte {
+	if c.Direction == Descending {
+		return EncodeBitArrayDescending(b, d)
+	}
+	return EncodeBitArrayAscending(b, d)
+}
+
+// DecodeVarint decodes an int64 encoded by EncodeVarint.
+func (c KeyColumn) DecodeVarint(b []byte) ([]byte, int64, error) {
+	if c.Direction == Descending {
+		return DecodeVarintDescending(b)
+	}
+	return DecodeVarintAscending(b)
+}
+
+// DecodeFloat decodes a float64 encoded by EncodeFloat.
+func (c KeyColumn) DecodeFloat(b []byte) ([]byte, float64, error) {
+	if c.Direction == Descending {
+		return DecodeFloatDescending(b)
+	}
+	return DecodeFloatAscending(b)
+}
+
+// DecodeDecimal decodes a decimal encoded by EncodeDecimal. The tmp []byte
+// is used as a temporary buffer, as in DecodeDecimalAscending.
+func (c KeyColumn) DecodeDecimal(b []byte, tmp []byte) ([]byte, apd.Decimal, error) {
+	if c.Direction == Descending {
+		return DecodeDecimalDescending(b, tmp)
+	}
+	return DecodeDecimalAscending(b, tmp)
+}
+
+// DecodeBytes decodes a byte slice encoded by EncodeBytes or EncodeString.
+// The r []byte is used as a temporary buffer, as in DecodeBytesAscending.
+func (c KeyColumn) DecodeBytes(b []byte, r []byte) ([]byte, []byte, error) {
+	if c.Direction == Descending {
+		return DecodeBytesDescending(b, r)
+	}
+	return DecodeBytesAscending(b, r)
+}
+
+// DecodeUnsafeString decodes a string encoded by EncodeString or
+// EncodeBytes. Note that the returned string may share storage with the
+// input buffer.
+func (c KeyColumn) DecodeUnsafeString(b []byte, r []byte) ([]byte, string, error) {
+	if c.Direction == Descending {
+		return DecodeUnsafeStringDescending(b, r)
+	}
+	return DecodeUnsafeStringAscending(b, r)
+}
+
+// DecodeTime decodes a time encoded by EncodeTime.
+func (c KeyColumn) DecodeTime(b []byte) ([]byte, time.Time, error) {
+	if c.Direction == Descending {
+		return DecodeTimeDescending(b)
+	}
+	return DecodeTimeAscending(b)
+}
+
+// DecodeDuration decodes a duration encoded by EncodeDuration.
+func (c KeyColumn) DecodeDuration(b []byte) ([]byte, duration.Duration, error) {
+	if c.Direction == Descending {
+		return DecodeDurationDescending(b)
+	}
+	return DecodeDurationAscending(b)
+}
+
+// DecodeBitArray decodes a bit array encoded by EncodeBitArray.
+func (c KeyColumn) DecodeBitArray(b []byte) ([]byte, bitarray.BitArray, error) {
+	if c.Direction == Descending {
+		return DecodeBitArrayDescending(b)
+	}
+	return DecodeBitArrayAscending(b)
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// keyTuple is a tuple of an int and a string column, where nil is NULL.
+type keyTuple struct {
+	i *int64
+	s *string
+}
+
+func (t keyTuple) String() string {
+	var i, s interface{} = "NULL", "NULL"
+	if t.i != nil {
+		i = *t.i
+	}
+	if t.s != nil {
+		s = *t.s
+	}
+	return fmt.Sprintf("(%v, %v)", i, s)
+}
+
+func encodeKeyTuple(cols [2]KeyColumn, t keyTuple) []byte {
+	var b []byte
+	if t.i == nil {
+		b = cols[0].EncodeNull(b)
+	} else {
+		b = cols[0].EncodeVarint(b, *t.i)
+	}
+	if t.s == nil {
+		b = cols[1].EncodeNull(b)
+	} else {
+		b = cols[1].EncodeString(b, *t.s)
+	}
+	return b
+}
+
+func decodeKeyTuple(cols [2]KeyColumn, b []byte) (keyTuple, error) {
+	var t keyTuple
+	var isNull bool
+	if b, isNull = DecodeIfNull(b); !isNull {
+		var i int64
+		var err error
+		if b, i, err = cols[0].DecodeVarint(b); err != nil {
+			return t, err
+		}
+		t.i = &i
+	}
+	if b, isNull = DecodeIfNull(b); !isNull {
+		var s string
+		var err error
+		if b, s, err = cols[1].DecodeUnsafeString(b, nil); err != nil {
+			return t, err
+		}
+		t.s = &s
+	}
+	if len(b) != 0 {
+		return t, fmt.Errorf("unexpected remaining bytes %x", b)
+	}
+	return t, nil
+}
+
+// compareKeyColumn compares two values of a column, with nil as NULL and cmp
+// comparing non-NULL values in ascending order.
+func compareKeyColumn(col KeyColumn, aNull, bNull bool, cmp func() int) int {
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull || bNull:
+		if aNull == (col.Nulls == NullsFirst) {
+			return -1
+		}
+		return 1
+	}
+	if col.Direction == Descending {
+		return -cmp()
+	}
+	return cmp()
+}
+
+func TestKeyColumnOrdering(t *testing.T) {
+	ints := []int64{-100, -1, 0, 1, 1000}
+	strs := []string{"", "a", "a\x00", "ab", "b"}
+	tuples := []keyTuple{{}}
+	for i := range ints {
+		tuples = append(tuples, keyTuple{i: &ints[i]})
+		for j := range strs {
+			tuples = append(tuples, keyTuple{i: &ints[i], s: &strs[j]})
+		}
+	}
+	for j := range strs {
+		tuples = append(tuples, keyTuple{s: &strs[j]})
+	}
+
+	var orders []KeyColumn
+	for _, dir := range []Direction{Ascending, Descending} {
+		for _, nulls := range []NullsOrder{NullsFirst, NullsLast} {
+			orders = append(orders, KeyColumn{Direction: dir, Nulls: nulls})
+		}
+	}
+	for _, c0 := range orders {
+		for _, c1 := range orders {
+			cols := [2]KeyColumn{c0, c1}
+			t.Run(fmt.Sprintf("%v", cols), func(t *testing.T) {
+				compare := func(a, b keyTuple) int {
+					if c := compareKeyColumn(c0, a.i == nil, b.i == nil, func() int {
+						switch {
+						case *a.i < *b.i:
+							return -1
+						case *a.i > *b.i:
+							return 1
+						}
+						return 0
+					}); c != 0 {
+						return c
+					}
+					return compareKeyColumn(c1, a.s == nil, b.s == nil, func() int {
+						return bytes.Compare([]byte(*a.s), []byte(*b.s))
+					})
+				}
+
+				keys := make([][]byte, len(tuples))
+				for i, tuple := range tuples {
+					keys[i] = encodeKeyTuple(cols, tuple)
+					decoded, err := decodeKeyTuple(cols, keys[i])
+					if err != nil {
+						t.Fatalf("%s: %v", tuple, err)
+					}
+					if compare(decoded, tuple) != 0 {
+						t.Fatalf("expected %s to round-trip, got %s", tuple, decoded)
+					}
+				}
+				for i := range tuples {
+					for j := range tuples {
+						expected := compare(tuples[i], tuples[j])
+						if c := bytes.Compare(keys[i], keys[j]); c != expected {
+							t.Errorf("expected %s compared to %s to be %d, got %d",
+								tuples[i], tuples[j], expected, c)
+						}
+					}
+				}
+
+				// Sorting the keys sorts the tuples.
+				sorted := append([]keyTuple(nil), tuples...)
+				sort.Slice(sorted, func(i, j int) bool {
+					return bytes.Compare(encodeKeyTuple(cols, sorted[i]), encodeKeyTuple(cols, sorted[j])) < 0
+				})
+				for i := 1; i < len(sorted); i++ {
+					if compare(sorted[i-1], sorted[i]) > 0 {
+						t.Fatalf("%s sorted before %s", sorted[i-1], sorted[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDefaultKeyColumn(t *testing.T) {
+	// Keys in the default order of a direction are the same as the ones of
+	// the routines of the direction.
+	asc, desc := DefaultKeyColumn(Ascending), DefaultKeyColumn(Descending)
+	if !bytes.Equal(asc.EncodeNull(nil), EncodeNullAscending(nil)) ||
+		!bytes.Equal(desc.EncodeNull(nil), EncodeNullDescending(nil)) {
+		t.Error("unexpected NULL encodings")
+	}
+	if !bytes.Equal(desc.EncodeVarint(nil, 42), EncodeVarintDescending(nil, 42)) {
+		t.Error("unexpected descending varint encoding")
+	}
+	if asc.Reverse() != desc || desc.Reverse() != asc {
+		t.Errorf("expected %v and %v to be the reverse of each other", asc, desc)
+	}
+
+	if err := (KeyColumn{Direction: Ascending}).Validate(); err == nil {
+		t.Error("expected an error for a column without NULLs order")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Channel is a logical category of log entries. The entries of each channel
+// are written to their own group of log files, which has its own rotation and
+// retention limits (see the --log-channel flag) so that, for example, audit
+// entries can be kept longer than the chatty operational entries.
+//
+// The logger of a channel is created by NewChannelLogger. Until then, the
+// entries of the channel go to the main log.
+type Channel int
+
+const (
+	// Ops is the channel of the operational events of the node, e.g. its
+	// startup, draining or decommissioning.
+	Ops Channel = iota
+	// Health is the channel of the periodic reports about the health of the
+	// node, e.g. its runtime statistics and health alerts.
+	Health
+	// SQLExec is the channel of the SQL statements executed by the node,
+	// enabled by the sql.trace.log_statement_execute cluster setting.
+	SQLExec
+	// SensitiveAccess is the channel of the accesses to audited tables,
+	// enabled by ALTER TABLE ... EXPERIMENTAL_AUDIT.
+	SensitiveAccess
+	// Storage is the channel of the reports of the storage engine, e.g. its
+	// sstables and compactions.
+	Storage
+	// SQLPerf is the channel of the SQL statements whose latency exceeds the
+	// sql.log.slow_query.latency_threshold cluster setting.
+	SQLPerf
+
+	numChannels
+)
+
+var channelNames = [numChannels]string{
+	Ops:             "OPS",
+	Health:          "HEALTH",
+	SQLExec:         "SQL_EXEC",
+	SensitiveAccess: "SENSITIVE_ACCESS",
+	Storage:         "STORAGE",
+	SQLPerf:         "SQL_PERF",
+}
+
+// channelFileGroups are the names of the file groups of the channels, which
+// follow the program name in the names of the log files. The file groups of
+// SQLExec and SensitiveAccess predate the channels and are kept for
+// compatibility with existing tooling.
+var channelFileGroups = [numChannels]string{
+	Ops:             "ops",
+	Health:          "health",
+	SQLExec:         "sql-exec",
+	SensitiveAccess: "sql-audit",
+	Storage:         "storage",
+	SQLPerf:         "sql-slow",
+}
+
+func (ch Channel) String() string {
+	if ch < 0 || ch >= numChannels {
+		return fmt.Sprintf("Channel(%d)", int(ch))
+	}
+	return channelNames[ch]
+}
+
+// ChannelByName returns the channel with the given name, e.g. OPS.
+func ChannelByName(name string) (Channel, bool) {
+	for ch, n := range channelNames {
+		if strings.EqualFold(n, name) {
+			return Channel(ch), true
+		}
+	}
+	return 0, false
+}
+
+// Infof logs to the channel with INFO severity.
+func (ch Channel) Infof(ctx context.Context, format string, args ...interface{}) {
+	ch.logDepth(ctx, 1, Severity_INFO, format, args)
+}
+
+// Warningf logs to the channel with WARNING severity.
+func (ch Channel) Warningf(ctx context.Context, format string, args ...interface{}) {
+	ch.logDepth(ctx, 1, Severity_WARNING, format, args)
+}
+
+// Errorf logs to the channel with ERROR severity.
+func (ch Channel) Errorf(ctx context.Context, format string, args ...interface{}) {
+	ch.logDepth(ctx, 1, Severity_ERROR, format, args)
+}
+
+func (ch Channel) logDepth(
+	ctx context.Context, depth int, sev Severity, format string, args []interface{},
+) {
+	channels.mu.Lock()
+	l := channels.loggers[ch]
+	channels.mu.Unlock()
+	if l == nil {
+		logDepth(ctx, depth+1, sev, format, args)
+		return
+	}
+	file, line, _ := caller.Lookup(depth + 1)
+	msg := MakeMessage(ctx, format, args)
+	eventInternal(ctx, sev >= Severity_ERROR, false /*withTags*/, "%s:%d %s", file, line, msg)
+	if redactableLogs {
+		msg = makeRedactableMessage(ctx, format, args)
+	}
+	l.logger.outputLogEntry(sev, file, line, msg, true /* redactable */)
+}
+
+// channelConfig holds the rotation and retention limits of a channel. Zero
+// limits default to --log-file-max-size and --log-dir-max-size.
+type channelConfig struct {
+	fileMaxSize  int64
+	groupMaxSize int64
+}
+
+var channels struct {
+	mu      syncutil.Mutex
+	configs [numChannels]channelConfig
+	loggers [numChannels]*SecondaryLogger
+}
+
+// NewChannelLogger creates the logger of a channel, which writes the entries
+// of the channel to its file group. See NewSecondaryLogger for the arguments.
+func NewChannelLogger(
+	ctx context.Context, dirName *DirName, ch Channel, enableGc, forceSyncWrites bool,
+) *SecondaryLogger {
+	channels.mu.Lock()
+	cfg := channels.configs[ch]
+	channels.mu.Unlock()
+	l := newSecondaryLogger(ctx, dirName, channelFileGroups[ch], cfg, enableGc, forceSyncWrites)
+	channels.mu.Lock()
+	channels.loggers[ch] = l
+	channels.mu.Unlock()
+	return l
+}
+
+// channelFlag is the value of the --log-channel flag, which configures the
+// limits of a channel as <channel>:<key>=<size>[,<key>=<size>]. The keys are
+// file-max-size, the maximum size of each log file of the channel, and
+// group-max-size, the maximum combined size of its log files. The flag can
+// be specified once per channel.
+type channelFlag struct{}
+
+// String is part of the flag.Value interface.
+func (channelFlag) String() string {
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	var specs []string
+	for ch, cfg := range channels.configs {
+		var limits []string
+		if cfg.fileMaxSize != 0 {
+			limits = append(limits, "file-max-size="+humanizeutil.IBytes(cfg.fileMaxSize))
+		}
+		if cfg.groupMaxSize != 0 {
+			limits = append(limits, "group-max-size="+humanizeutil.IBytes(cfg.groupMaxSize))
+		}
+		if len(limits) > 0 {
+			specs = append(specs, Channel(ch).String()+":"+strings.Join(limits, ","))
+		}
+	}
+	return strings.Join(specs, " ")
+}
+
+// Set is part of the flag.Value interface.
+func (channelFlag) Set(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid channel configuration %q, expected <channel>:<key>=<size>", value)
+	}
+	ch, ok := ChannelByName(parts[0])
+	if !ok {
+		return fmt.Errorf("unknown log channel %q", parts[0])
+	}
+	var cfg channelConfig
+	for _, limit := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(limit, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid channel configuration %q, expected <key>=<size>", limit)
+		}
+		size, err := humanizeutil.ParseBytes(kv[1])
+		if err != nil {
+			return err
+		}
+		if size <= 0 {
+			return fmt.Errorf("invalid size %q for %s", kv[1], kv[0])
+		}
+		switch kv[0] {
+		case "file-max-size":
+			cfg.fileMaxSize = size
+		case "group-max-size":
+			cfg.groupMaxSize = size
+		default:
+			return fmt.Errorf("unknown channel configuration key %q, "+
+				"expected file-max-size or group-max-size", kv[0])
+		}
+	}
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	channels.configs[ch] = cfg
+	return nil
+}
+
+// Type is part of the pflag.Value interface.
+func (channelFlag) Type() string {
+	return "string"
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestChannelFlag(t *testing.T) {
+	defer func(save [numChannels]channelConfig) { channels.configs = save }(channels.configs)
+
+	var f channelFlag
+	if err := f.Set("sensitive_access:file-max-size=1MiB,group-max-size=1GiB"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("STORAGE:group-max-size=10MiB"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := channels.configs[SensitiveAccess]; cfg.fileMaxSize != 1<<20 || cfg.groupMaxSize != 1<<30 {
+		t.Fatalf("unexpected configuration %+v", cfg)
+	}
+	if expected, s := "SENSITIVE_ACCESS:file-max-size=1.0 MiB,group-max-size=1.0 GiB "+
+		"STORAGE:group-max-size=10 MiB", f.String(); s != expected {
+		t.Fatalf("expected %q, got %q", expected, s)
+	}
+
+	for _, tc := range []struct {
+		value string
+		err   string
+	}{
+		{"OPS", "expected <channel>:<key>=<size>"},
+		{"FOO:file-max-size=1MiB", "unknown log channel"},
+		{"OPS:file-max-size", "expected <key>=<size>"},
+		{"OPS:file-max-size=abc", ""},
+		{"OPS:file-max-size=0", "invalid size"},
+		{"OPS:max-size=1MiB", "unknown channel configuration key"},
+	} {
+		if err := f.Set(tc.value); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.value, tc.err, err)
+		}
+	}
+}
+
+func TestChannelLogger(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Entries go to the main log until the channel has a logger.
+	Ops.Infof(ctx, "before")
+
+	defer func(save [numChannels]channelConfig) { channels.configs = save }(channels.configs)
+	channels.configs[Ops] = channelConfig{fileMaxSize: 1 << 20, groupMaxSize: 1 << 30}
+	l := NewChannelLogger(ctx, &logging.logDir, Ops, true /* enableGc */, false /* forceSyncWrites */)
+	defer func() { channels.loggers[Ops] = nil }()
+	if l.logger.getFileMaxSize() != 1<<20 || l.logger.getCombinedMaxSize() != 1<<30 {
+		t.Fatalf("unexpected limits %d, %d", l.logger.getFileMaxSize(), l.logger.getCombinedMaxSize())
+	}
+	Ops.Warningf(ctx, "after")
+	Flush()
+
+	contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "before") || strings.Contains(string(contents), "after") {
+		t.Errorf("unexpected main log contents\n%s", contents)
+	}
+	name := l.logger.file.(*syncBuffer).file.Name()
+	if !strings.Contains(name, program+"-ops.") {
+		t.Errorf("unexpected file name %s", name)
+	}
+	contents, err = ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "after") {
+		t.Errorf("channel log does not contain text\n%s", contents)
+	}
+}
@@ -343,8 +343,11 @@ func (t *traceLocation) Set(value string) error {
 // We don't include a capture group for the log message here, just for the
 // preamble, because a capture group that handles multiline messages is very
 // slow when running on the large buffers passed to EntryDecoder.split.
+// Entries in the JSON format are matched by their leading timestamp key.
+// The indicator of redactable entries is part of the preamble.
 var entryRE = regexp.MustCompile(
-	`(?m)^([IWEF])(\d{6} \d{2}:\d{2}:\d{2}.\d{6}) (?:(\d+) )?([^:]+):(\d+)`)
+	`(?m)^(?:([IWEF])(\d{6} \d{2}:\d{2}:\d{2}.\d{6}) (?:(\d+) )?([^:]+):(\d+)(?: +(` +
+		redactableIndicator + `))?|\{"timestamp":)`)
 
 // EntryDecoder reads successive encoded log entries from the input
 // buffer. Each entry is preceded by a single big-ending uint32
@@ -380,6 +383,14 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 		if m == nil {
 			continue
 		}
+		if len(m[1]) == 0 {
+			// A JSON entry. Skip it if it can't be decoded, which happens when
+			// its end was truncated.
+			if err := decodeJSONLogEntry(b, entry); err != nil {
+				continue
+			}
+			return nil
+		}
 		entry.Severity = Severity(strings.IndexByte(severityChar, m[1][0]) + 1)
 		t, err := time.Parse(MessageTimeFormat, string(m[2]))
 		if err != nil {
@@ -399,6 +410,7 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return err
 		}
 		entry.Line = int64(line)
+		entry.Redactable = len(m[6]) > 0
 		entry.Message = strings.TrimSpace(string(b[len(m[0]):]))
 		return nil
 	}
@@ -595,6 +607,9 @@ func (buf *buffer) someDigits(i, d int) int {
 func formatLogEntry(entry Entry, stacks []byte, cp ttycolor.Profile) *buffer {
 	buf := formatHeader(entry.Severity, timeutil.Unix(0, entry.Time),
 		int(entry.Goroutine), entry.File, int(entry.Line), cp)
+	if entry.Redactable {
+		_, _ = buf.WriteString(redactableIndicator + " ")
+	}
 	_, _ = buf.WriteString(entry.Message)
 	if buf.Bytes()[buf.Len()-1] != '\n' {
 		_ = buf.WriteByte('\n')
@@ -696,6 +711,12 @@ type loggingT struct {
 	// Level flag for output to files.
 	fileThreshold Severity
 
+	// Maximum size of each log file and maximum combined size of the log
+	// files of this logger, in bytes. Zero values default to LogFileMaxSize
+	// and LogFilesCombinedMaxSize.
+	fileMaxSize     int64
+	combinedMaxSize int64
+
 	// freeList is a list of byte buffers, maintained under freeListMu.
 	freeList *buffer
 	// freeListMu maintains the free list. It is separate from the main mutex
@@ -758,7 +779,7 @@ func SetClusterID(clusterID string) {
 	// will always find it.
 	file, line, _ := caller.Lookup(1)
 	logging.outputLogEntry(Severity_INFO, file, line,
-		fmt.Sprintf("[config] clusterID: %s", clusterID))
+		fmt.Sprintf("[config] clusterID: %s", clusterID), true /* redactable */)
 
 	// Perform the change proper.
 	logging.mu.Lock()
@@ -842,11 +863,22 @@ func (l *loggingT) writeToFile(data []byte) error {
 
 // outputLogEntry marshals a log entry proto into bytes, and writes
 // the data to the log files. If a trace location is set, stack traces
-// are added to the entry before marshaling.
-func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string) {
+// are added to the entry before marshaling. redactable indicates whether
+// msg encloses its sensitive data in redaction markers; with
+// --redactable-logs, a message which doesn't is considered sensitive as a
+// whole.
+func (l *loggingT) outputLogEntry(
+	s Severity, file string, line int, msg string, redactable bool,
+) {
 	// Set additional details in log entry.
 	now := timeutil.Now()
 	entry := MakeEntry(s, now.UnixNano(), file, line, msg)
+	if redactableLogs {
+		if !redactable {
+			entry.Message = markRedactable(msg)
+		}
+		entry.Redactable = true
+	}
 
 	if f, ok := l.interceptor.Load().(InterceptorFn); ok && f != nil {
 		f(entry)
@@ -943,6 +975,9 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 
 		l.putBuffer(buf)
 	}
+	if s >= l.fileThreshold.get() {
+		l.outputToNetworkSinks(entry, stacks)
+	}
 	// Flush and exit on fatal logging.
 	if s == Severity_FATAL {
 		l.flushAndSync(true /*doSync*/)
@@ -995,11 +1030,17 @@ func (l *loggingT) outputToStderr(entry Entry, stacks []byte) {
 
 // processForStderr formats a log entry for output to standard error.
 func (l *loggingT) processForStderr(entry Entry, stacks []byte) *buffer {
+	if logFormat == FormatJSON {
+		return formatJSONLogEntry(entry, stacks, l.prefix)
+	}
 	return formatLogEntry(entry, stacks, ttycolor.StderrProfile)
 }
 
 // processForFile formats a log entry for output to a file.
 func (l *loggingT) processForFile(entry Entry, stacks []byte) *buffer {
+	if logFormat == FormatJSON {
+		return formatJSONLogEntry(entry, stacks, l.prefix)
+	}
 	return formatLogEntry(entry, stacks, nil)
 }
 
@@ -1082,7 +1123,7 @@ func (sb *syncBuffer) Sync() error {
 }
 
 func (sb *syncBuffer) Write(p []byte) (n int, err error) {
-	if sb.nbytes+int64(len(p)) >= atomic.LoadInt64(&LogFileMaxSize) {
+	if sb.nbytes+int64(len(p)) >= sb.logger.getFileMaxSize() {
 		if err := sb.rotateFile(timeutil.Now()); err != nil {
 			sb.logger.exitLocked(err)
 		}
@@ -1139,18 +1180,22 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	}
 	// Including a non-ascii character in the first 1024 bytes of the log helps
 	// viewers that attempt to guess the character encoding.
-	messages = append(messages, fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713\n"))
+	if logFormat == FormatJSON {
+		messages = append(messages, fmt.Sprintf("line format: json utf8=\u2713\n"))
+	} else {
+		messages = append(messages, fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713\n"))
+	}
 
 	f, l, _ := caller.Lookup(1)
 	for _, msg := range messages {
-		buf := formatLogEntry(Entry{
+		buf := sb.logger.processForFile(Entry{
 			Severity:  Severity_INFO,
 			Time:      now.UnixNano(),
 			Goroutine: goid.Get(),
 			File:      f,
 			Line:      int64(l),
 			Message:   msg,
-		}, nil, nil)
+		}, nil)
 		var n int
 		n, err = sb.file.Write(buf.Bytes())
 		sb.nbytes += int64(n)
@@ -1280,6 +1325,23 @@ func (l *loggingT) flushAndSync(doSync bool) {
 	}
 }
 
+// getFileMaxSize returns the maximum size of each log file of the logger.
+func (l *loggingT) getFileMaxSize() int64 {
+	if l.fileMaxSize != 0 {
+		return l.fileMaxSize
+	}
+	return atomic.LoadInt64(&LogFileMaxSize)
+}
+
+// getCombinedMaxSize returns the maximum combined size of the log files of
+// the logger.
+func (l *loggingT) getCombinedMaxSize() int64 {
+	if l.combinedMaxSize != 0 {
+		return l.combinedMaxSize
+	}
+	return atomic.LoadInt64(&LogFilesCombinedMaxSize)
+}
+
 func (l *loggingT) gcDaemon(ctx context.Context) {
 	l.gcOldFiles()
 	for {
@@ -1311,7 +1373,7 @@ func (l *loggingT) gcOldFiles() {
 		return
 	}
 
-	logFilesCombinedMaxSize := atomic.LoadInt64(&LogFilesCombinedMaxSize)
+	logFilesCombinedMaxSize := l.getCombinedMaxSize()
 	files := selectFiles(allFiles, math.MaxInt64)
 	if len(files) == 0 {
 		return
@@ -1373,7 +1435,7 @@ func (lb logBridge) Write(b []byte) (n int, err error) {
 			line = 1
 		}
 	}
-	logging.outputLogEntry(Severity(lb), file, line, text)
+	logging.outputLogEntry(Severity(lb), file, line, text, false /* redactable */)
 	return len(b), nil
 }
 
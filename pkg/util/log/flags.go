@@ -23,10 +23,18 @@ func init() {
 		&logging.vmodule,
 		&LogFileMaxSize, &LogFilesCombinedMaxSize,
 	)
-	// We define these flags here because they have the type Severity
+	// We define these flags here because they have types defined in this package,
 	// which we can't pass to logflags without creating an import cycle.
 	flag.Var(&logging.stderrThreshold,
 		logflags.LogToStderrName, "logs at or above this threshold go to stderr")
 	flag.Var(&logging.fileThreshold,
 		logflags.LogFileVerbosityThresholdName, "minimum verbosity of messages written to the log file")
+	flag.Var(&logFormat,
+		logflags.LogFormatName, "format of log entries written to files and stderr (text or json)")
+	flag.Var(networkSinkFlag{},
+		logflags.LogSinkName, "comma-separated list of network sinks to send log entries to")
+	flag.Var(channelFlag{},
+		logflags.LogChannelName, "rotation and retention limits of a log channel")
+	flag.BoolVar(&redactableLogs,
+		logflags.RedactableLogsName, false, "enclose the sensitive data of log messages in redaction markers")
 }
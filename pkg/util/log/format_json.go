@@ -0,0 +1,163 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Format is the format of log entries written to files and stderr.
+type Format int
+
+const (
+	// FormatText is the default, human-readable format of log entries:
+	// 	Lyymmdd hh:mm:ss.uuuuuu goid file:line msg...
+	FormatText Format = iota
+	// FormatJSON writes each log entry as a single line holding a JSON
+	// object, for ingestion by log processing tools.
+	FormatJSON
+)
+
+// the --log-format flag.
+var logFormat Format
+
+// String is part of the flag.Value interface.
+func (f *Format) String() string {
+	switch *f {
+	case FormatText:
+		return "text"
+	case FormatJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(*f))
+	}
+}
+
+// Set is part of the flag.Value interface.
+func (f *Format) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "text":
+		*f = FormatText
+	case "json":
+		*f = FormatJSON
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", value)
+	}
+	return nil
+}
+
+// Type is part of the pflag.Value interface.
+func (f *Format) Type() string {
+	return "string"
+}
+
+// jsonEntry is a log entry in the JSON format. Timestamp must remain the
+// first field as entryRE relies on it to find the start of entries.
+type jsonEntry struct {
+	Timestamp string `json:"timestamp"`
+	Severity  string `json:"severity"`
+	// Channel is the name of the logger the entry was written to, e.g.
+	// "cockroach" or "cockroach-sql-audit".
+	Channel   string `json:"channel"`
+	Goroutine int64  `json:"goroutine,omitempty"`
+	File      string `json:"file"`
+	Line      int64  `json:"line"`
+	// Tags are the log tags of the context the entry was logged with, as
+	// they would be printed between brackets in the text format.
+	Tags    string `json:"tags,omitempty"`
+	Message string `json:"message"`
+	Stacks  string `json:"stacks,omitempty"`
+	// Redactable is set if the tags and message enclose their sensitive data
+	// in redaction markers.
+	Redactable bool `json:"redactable,omitempty"`
+}
+
+// makeJSONEntry converts a log entry written to the given channel to its
+// JSON representation.
+func makeJSONEntry(entry Entry, stacks []byte, channel string) jsonEntry {
+	tags, msg := splitTags(strings.TrimSuffix(entry.Message, "\n"))
+	return jsonEntry{
+		Timestamp:  timeutil.Unix(0, entry.Time).UTC().Format(time.RFC3339Nano),
+		Severity:   entry.Severity.String(),
+		Channel:    channel,
+		Goroutine:  entry.Goroutine,
+		File:       entry.File,
+		Line:       entry.Line,
+		Tags:       tags,
+		Message:    msg,
+		Stacks:     string(stacks),
+		Redactable: entry.Redactable,
+	}
+}
+
+// formatJSONLogEntry formats a log entry as a JSON object terminated by a
+// newline.
+func formatJSONLogEntry(entry Entry, stacks []byte, channel string) *buffer {
+	e := makeJSONEntry(entry, stacks, channel)
+	buf := logging.getBuffer()
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// Encoding can't fail: all the fields are strings and integers.
+	_ = enc.Encode(&e)
+	return buf
+}
+
+// splitTags splits the log tags, which are formatted as "[tags] " at the
+// start of a message, from the rest of the message.
+func splitTags(msg string) (tags string, rest string) {
+	if !strings.HasPrefix(msg, "[") {
+		return "", msg
+	}
+	i := strings.Index(msg, "] ")
+	if i < 0 {
+		return "", msg
+	}
+	return msg[1:i], msg[i+2:]
+}
+
+// decodeJSONLogEntry decodes a log entry written in the JSON format. The tags
+// and stacks are folded back into the message, as in the text format.
+func decodeJSONLogEntry(b []byte, entry *Entry) error {
+	var e jsonEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return err
+	}
+	sev, ok := Severity_value[e.Severity]
+	if !ok {
+		return fmt.Errorf("unknown severity %q", e.Severity)
+	}
+	msg := e.Message
+	if e.Tags != "" {
+		msg = "[" + e.Tags + "] " + msg
+	}
+	if e.Stacks != "" {
+		msg += "\n" + e.Stacks
+	}
+	*entry = Entry{
+		Severity:   Severity(sev),
+		Time:       t.UnixNano(),
+		Goroutine:  e.Goroutine,
+		File:       e.File,
+		Line:       e.Line,
+		Message:    strings.TrimSpace(msg),
+		Redactable: e.Redactable,
+	}
+	return nil
+}
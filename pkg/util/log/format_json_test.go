@@ -0,0 +1,93 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/logtags"
+)
+
+func TestJSONFormat(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer func(save Format) { logFormat = save }(logFormat)
+	if err := logFormat.Set("json"); err != nil {
+		t.Fatal(err)
+	}
+	defer logging.swap(logging.newBuffers())
+
+	ctx := logtags.AddTag(context.Background(), "n", 1)
+	Warningf(ctx, "hello %q", "world")
+	Info(context.Background(), "untagged\nmulti-line")
+
+	lines := strings.Split(strings.TrimSuffix(contents(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per entry, got:\n%s", contents())
+	}
+	var e jsonEntry
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, e.Timestamp); err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "WARNING" || e.Channel != program || !strings.HasSuffix(e.File, "format_json_test.go") ||
+		e.Line == 0 || e.Tags != "n1" || e.Message != `hello "world"` {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	// The entries can be read back, alongside entries in the text format.
+	text := formatLogEntry(Entry{
+		Severity: Severity_ERROR,
+		Time:     timeutil.Now().Round(time.Microsecond).UnixNano(),
+		File:     "text.go",
+		Line:     12,
+		Message:  "[n2] text",
+	}, nil, nil)
+	defer logging.putBuffer(text)
+	decoder := NewEntryDecoder(strings.NewReader(contents() + text.String()))
+	var messages []string
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, entry.Severity.String()+" "+entry.Message)
+	}
+	expected := []string{
+		`WARNING [n1] hello "world"`,
+		"INFO untagged\nmulti-line",
+		"ERROR [n2] text",
+	}
+	if strings.Join(messages, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestFormatFlag(t *testing.T) {
+	var f Format
+	if err := f.Set("JSON"); err != nil || f != FormatJSON {
+		t.Fatalf("unexpected format %s, err %v", f.String(), err)
+	}
+	if err := f.Set("xml"); err == nil {
+		t.Fatal("expected error")
+	}
+}
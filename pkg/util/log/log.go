@@ -261,8 +261,3 @@ func (e Entry) Format(w io.Writer) error {
 	_, err := w.Write(buf.Bytes())
 	return err
 }
-
-// SetVModule alters the vmodule logging level to the passed in value.
-func SetVModule(value string) error {
-	return logging.vmodule.Set(value)
-}
@@ -67,6 +67,10 @@ const (
 	LogFileMaxSizeName            = "log-file-max-size"
 	LogFilesCombinedMaxSizeName   = "log-dir-max-size"
 	LogFileVerbosityThresholdName = "log-file-verbosity"
+	LogFormatName                 = "log-format"
+	LogSinkName                   = "log-sink"
+	LogChannelName                = "log-channel"
+	RedactableLogsName            = "redactable-logs"
 )
 
 // InitFlags creates logging flags which update the given variables. The passed mutex is
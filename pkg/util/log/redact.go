@@ -0,0 +1,241 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/logtags"
+)
+
+// With --redactable-logs, the data which may be sensitive is enclosed in
+// redaction markers in the messages written to the log files, e.g.
+//
+//   I191015 12:00:00.000000 123 sql/conn.go:42  ⋮ [n1,client=‹1.2.3.4:5678›] received ‹SELECT 1›
+//
+// so that it can be stripped mechanically, for example by `cockroach debug
+// zip --redact`, while the operational context (file, line, node IDs, counts,
+// durations etc.) is preserved. The format strings of the log calls are
+// considered safe, as are the arguments which are:
+//
+// - wrapped with Safe();
+// - of a boolean or numeric type, which includes the IDs of nodes, stores
+//   and ranges and time.Duration;
+// - implementing SafeValue.
+//
+// All the other arguments and log tag values are enclosed in markers. The
+// markers occurring in these values are escaped, so that a value can't end
+// its marked region early.
+
+const (
+	startRedactable = '‹'
+	endRedactable   = '›'
+	// escapeMark replaces the markers found in the values to enclose.
+	escapeMark = '?'
+)
+
+// RedactedMarker is the replacement of the sensitive data in redacted log
+// messages.
+const RedactedMarker = "‹×›"
+
+// redactableIndicator is printed between the header and the message of the
+// redactable entries in the text format.
+const redactableIndicator = "⋮"
+
+// the --redactable-logs flag.
+var redactableLogs bool
+
+// SafeValue is implemented by the types whose printed representation never
+// contains sensitive data. Their values are not enclosed in redaction
+// markers in redactable logs.
+type SafeValue interface {
+	SafeValue()
+}
+
+// isSafe returns whether the given log argument may be printed in
+// redactable logs without redaction markers.
+func isSafe(v interface{}) bool {
+	switch v.(type) {
+	case nil, SafeType, SafeValue:
+		return true
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// redactableArg wraps a log argument which may contain sensitive data so
+// that it is formatted enclosed in redaction markers.
+type redactableArg struct {
+	v interface{}
+}
+
+// Format implements fmt.Formatter.
+func (a redactableArg) Format(s fmt.State, verb rune) {
+	var directive strings.Builder
+	directive.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			directive.WriteRune(flag)
+		}
+	}
+	if w, ok := s.Width(); ok {
+		directive.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := s.Precision(); ok {
+		directive.WriteByte('.')
+		directive.WriteString(strconv.Itoa(p))
+	}
+	directive.WriteRune(verb)
+	_, _ = fmt.Fprint(s, markRedactable(fmt.Sprintf(directive.String(), a.v)))
+}
+
+// markRedactable encloses s in redaction markers, escaping the markers it
+// contains.
+func markRedactable(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s) + 2*utf8.RuneLen(startRedactable))
+	buf.WriteRune(startRedactable)
+	for _, r := range s {
+		if r == startRedactable || r == endRedactable {
+			r = escapeMark
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteRune(endRedactable)
+	return buf.String()
+}
+
+// redactableArgs wraps the unsafe arguments of a log call.
+func redactableArgs(args []interface{}) []interface{} {
+	res := make([]interface{}, len(args))
+	for i, arg := range args {
+		if isSafe(arg) {
+			res[i] = arg
+		} else {
+			res[i] = redactableArg{v: arg}
+		}
+	}
+	return res
+}
+
+// formatRedactableTags appends the tags to a strings.Builder like formatTags,
+// enclosing the unsafe tag values in redaction markers.
+func formatRedactableTags(ctx context.Context, buf *strings.Builder) bool {
+	tags := logtags.FromContext(ctx)
+	if tags == nil {
+		return false
+	}
+	buf.WriteByte('[')
+	for i, t := range tags.Get() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(t.Key())
+		if v := t.Value(); v != nil && v != "" {
+			if len(t.Key()) > 1 {
+				buf.WriteByte('=')
+			}
+			if isSafe(v) {
+				fmt.Fprint(buf, v)
+			} else {
+				buf.WriteString(markRedactable(fmt.Sprint(v)))
+			}
+		}
+	}
+	buf.WriteString("] ")
+	return true
+}
+
+// makeRedactableMessage formats a log message like MakeMessage, enclosing
+// the unsafe arguments and tag values in redaction markers.
+func makeRedactableMessage(ctx context.Context, format string, args []interface{}) string {
+	var buf strings.Builder
+	formatRedactableTags(ctx, &buf)
+	if len(args) == 0 {
+		buf.WriteString(format)
+	} else if len(format) == 0 {
+		// Print adds spaces between the operands when neither is a string,
+		// which has to be decided on the original arguments.
+		for i, arg := range args {
+			if i > 0 && !isString(arg) && !isString(args[i-1]) {
+				buf.WriteByte(' ')
+			}
+			if isSafe(arg) {
+				fmt.Fprint(&buf, arg)
+			} else {
+				fmt.Fprint(&buf, redactableArg{v: arg})
+			}
+		}
+	} else {
+		fmt.Fprintf(&buf, format, redactableArgs(args)...)
+	}
+	return buf.String()
+}
+
+func isString(v interface{}) bool {
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.String
+}
+
+// replaceRedactable calls fn with the contents of each region enclosed in
+// redaction markers in msg, and replaces the contents with the result. An
+// unterminated region extends to the end of msg.
+func replaceRedactable(msg string, fn func(string) string) string {
+	var buf strings.Builder
+	for {
+		start := strings.IndexRune(msg, startRedactable)
+		if start < 0 {
+			buf.WriteString(msg)
+			return buf.String()
+		}
+		buf.WriteString(msg[:start])
+		buf.WriteRune(startRedactable)
+		msg = msg[start+utf8.RuneLen(startRedactable):]
+		end := strings.IndexRune(msg, endRedactable)
+		if end < 0 {
+			end = len(msg)
+		}
+		buf.WriteString(fn(msg[:end]))
+		buf.WriteRune(endRedactable)
+		msg = msg[end:]
+		if len(msg) > 0 {
+			msg = msg[utf8.RuneLen(endRedactable):]
+		}
+	}
+}
+
+// RedactEntry replaces each piece of sensitive data in the message of the
+// entry with the result of fn, keeping the redaction markers around it. The
+// message of an entry which is not redactable, i.e. which was not written
+// with --redactable-logs, is considered sensitive as a whole.
+func RedactEntry(entry *Entry, fn func(string) string) {
+	if !entry.Redactable {
+		entry.Message = markRedactable(entry.Message)
+		entry.Redactable = true
+	}
+	entry.Message = replaceRedactable(entry.Message, fn)
+}
+
+// Redacted is a replacement function for RedactEntry which hides the
+// sensitive data entirely, leaving RedactedMarker in its place.
+func Redacted(string) string {
+	return "×"
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/logtags"
+)
+
+type safeID int32
+
+type unsafeStruct struct{ name string }
+
+type safeStruct struct{ name string }
+
+func (safeStruct) SafeValue() {}
+
+func TestRedactableMessage(t *testing.T) {
+	ctx := logtags.AddTag(context.Background(), "n", 1)
+	ctx = logtags.AddTag(ctx, "client", "1.2.3.4:5678")
+	ctx = logtags.AddTag(ctx, "safe", safeStruct{"x"})
+
+	testCases := []struct {
+		format string
+		args   []interface{}
+		exp    string
+	}{
+		{"no args", nil, "no args"},
+		{"%s and %q", []interface{}{"secret", "quoted"}, `‹secret› and ‹"quoted"›`},
+		{"%d rows in %s", []interface{}{safeID(3), 2 * time.Second}, "3 rows in 2s"},
+		{"%5.1f%%", []interface{}{12.34}, " 12.3%"},
+		{"%-6s|", []interface{}{"ab"}, "‹ab    ›|"},
+		{"%v %+v", []interface{}{unsafeStruct{"a"}, unsafeStruct{"b"}}, "‹{a}› ‹{name:b}›"},
+		{"%v", []interface{}{safeStruct{"a"}}, "{a}"},
+		{"%s", []interface{}{Safe("safe")}, "safe"},
+		{"%v", []interface{}{errors.New("boom")}, "‹boom›"},
+		// Markers in the data are escaped.
+		{"%s", []interface{}{"a›b‹c"}, "‹a?b?c›"},
+		// Print semantics are preserved.
+		{"", []interface{}{"a", "b", 1, 2}, "‹a›‹b›1 2"},
+	}
+	for _, tc := range testCases {
+		exp := "[n1,client=‹1.2.3.4:5678›,safe={x}] " + tc.exp
+		if msg := makeRedactableMessage(ctx, tc.format, tc.args); msg != exp {
+			t.Errorf("%q %v: expected %q, got %q", tc.format, tc.args, exp, msg)
+		}
+	}
+}
+
+func TestRedactEntry(t *testing.T) {
+	testCases := []struct {
+		entry Entry
+		exp   string
+	}{
+		{Entry{Message: "[n1] no sensitive data", Redactable: true}, "[n1] no sensitive data"},
+		{Entry{Message: "[n1,client=‹addr›] ran ‹SELECT 1› 3 times", Redactable: true},
+			"[n1,client=‹×›] ran ‹×› 3 times"},
+		{Entry{Message: "unterminated ‹data", Redactable: true}, "unterminated ‹×›"},
+		// Entries which aren't redactable are sensitive as a whole.
+		{Entry{Message: "[n1] ran SELECT 1"}, RedactedMarker},
+	}
+	for _, tc := range testCases {
+		e := tc.entry
+		RedactEntry(&e, Redacted)
+		if e.Message != tc.exp || !e.Redactable {
+			t.Errorf("%q: expected %q, got %q", tc.entry.Message, tc.exp, e.Message)
+		}
+	}
+}
+
+func TestRedactableLogs(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer func(save bool) { redactableLogs = save }(redactableLogs)
+	redactableLogs = true
+	defer logging.swap(logging.newBuffers())
+
+	ctx := logtags.AddTag(context.Background(), "n", 1)
+	Infof(ctx, "ran %s in %s", "SELECT 1", time.Second)
+	Info(ctx, "untagged")
+	// The messages which weren't formatted to be redactable, e.g. those
+	// forwarded from the standard logger, are sensitive as a whole.
+	if _, err := logBridge(Severity_INFO).Write([]byte("redact_test.go:1: from std log\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The redactable entries are read back as such.
+	decoder := NewEntryDecoder(strings.NewReader(contents()))
+	var messages []string
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if !entry.Redactable {
+			t.Errorf("entry not redactable: %+v", entry)
+		}
+		messages = append(messages, entry.Message)
+	}
+	expected := []string{
+		"[n1] ran ‹SELECT 1› in 1s",
+		"[n1] ‹untagged›",
+		"‹from std log›",
+	}
+	if strings.Join(messages, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %q, got %q", expected, messages)
+	}
+}
@@ -45,6 +45,16 @@ var secondaryLogRegistry struct {
 // The logger's GC daemon stops when the provided context is canceled.
 func NewSecondaryLogger(
 	ctx context.Context, dirName *DirName, fileNamePrefix string, enableGc, forceSyncWrites bool,
+) *SecondaryLogger {
+	return newSecondaryLogger(ctx, dirName, fileNamePrefix, channelConfig{}, enableGc, forceSyncWrites)
+}
+
+func newSecondaryLogger(
+	ctx context.Context,
+	dirName *DirName,
+	fileNamePrefix string,
+	cfg channelConfig,
+	enableGc, forceSyncWrites bool,
 ) *SecondaryLogger {
 	logging.mu.Lock()
 	defer logging.mu.Unlock()
@@ -65,6 +75,8 @@ func NewSecondaryLogger(
 			syncWrites:       forceSyncWrites || logging.syncWrites,
 			gcNotify:         make(chan struct{}, 1),
 			disableDaemons:   logging.disableDaemons,
+			fileMaxSize:      cfg.fileMaxSize,
+			combinedMaxSize:  cfg.groupMaxSize,
 		},
 		forceSyncWrites: forceSyncWrites,
 	}
@@ -86,12 +98,19 @@ func NewSecondaryLogger(
 func (l *SecondaryLogger) Logf(ctx context.Context, format string, args ...interface{}) {
 	file, line, _ := caller.Lookup(1)
 	var buf strings.Builder
-	formatTags(ctx, &buf)
+	if redactableLogs {
+		formatRedactableTags(ctx, &buf)
+	} else {
+		formatTags(ctx, &buf)
+	}
 
 	// Add a counter. This is important for auditing.
 	counter := atomic.AddUint64(&l.msgCount, 1)
 	fmt.Fprintf(&buf, "%d ", counter)
 
+	if redactableLogs {
+		args = redactableArgs(args)
+	}
 	fmt.Fprintf(&buf, format, args...)
-	l.logger.outputLogEntry(Severity_INFO, file, line, buf.String())
+	l.logger.outputLogEntry(Severity_INFO, file, line, buf.String(), true /* redactable */)
 }
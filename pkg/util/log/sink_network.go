@@ -0,0 +1,307 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The schemes of the network log sinks.
+const (
+	// syslogTCPScheme sends entries to a syslog server over TCP, in the RFC
+	// 5424 format with octet-counting framing (RFC 6587).
+	syslogTCPScheme = "syslog+tcp"
+	// syslogUDPScheme sends entries to a syslog server over UDP, in the RFC
+	// 5424 format with one entry per datagram.
+	syslogUDPScheme = "syslog+udp"
+	// fluentScheme sends entries to a Fluentd server over TCP, using the
+	// message mode of the forward protocol with JSON-encoded events.
+	fluentScheme = "fluent"
+)
+
+const (
+	// networkSinkBufferSize is the number of entries buffered by a network
+	// sink while its server is slow or unavailable. Entries logged while the
+	// buffer is full are dropped.
+	networkSinkBufferSize = 10000
+	// networkSinkDialTimeout bounds the time spent connecting to the server
+	// of a network sink.
+	networkSinkDialTimeout = 5 * time.Second
+	// networkSinkWriteTimeout bounds the time spent writing an entry to the
+	// server of a network sink.
+	networkSinkWriteTimeout = 5 * time.Second
+	// networkSinkMaxBackoff is the maximum delay between two attempts to
+	// reconnect to the server of a network sink.
+	networkSinkMaxBackoff = 10 * time.Second
+)
+
+// networkSink ships the log entries of a channel, or of all channels, to a
+// syslog or Fluentd server. Entries are encoded when they are logged and
+// buffered; a goroutine sends them to the server, reconnecting as needed, so
+// that logging never blocks on the network.
+type networkSink struct {
+	spec string
+	// channel is the name of the logger whose entries are sent, or empty for
+	// all loggers.
+	channel  string
+	scheme   string
+	addr     string
+	hostname string
+
+	entries chan []byte
+	// dropped counts the entries dropped since the last report.
+	dropped int64
+	stopper chan struct{}
+	done    chan struct{}
+}
+
+// newNetworkSink parses a sink specification of the form
+// [<channel>=]<scheme>://<host>:<port>.
+func newNetworkSink(spec string) (*networkSink, error) {
+	s := &networkSink{spec: spec}
+	target := spec
+	if i := strings.Index(spec, "="); i >= 0 {
+		s.channel, target = spec[:i], spec[i+1:]
+		if s.channel == "" {
+			return nil, fmt.Errorf("log sink %q: empty channel name", spec)
+		}
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("log sink %q: %v", spec, err)
+	}
+	switch u.Scheme {
+	case syslogTCPScheme, syslogUDPScheme, fluentScheme:
+	default:
+		return nil, fmt.Errorf("log sink %q: unknown scheme %q, expected %s, %s or %s",
+			spec, u.Scheme, syslogTCPScheme, syslogUDPScheme, fluentScheme)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return nil, fmt.Errorf("log sink %q: %v", spec, err)
+	}
+	s.scheme, s.addr = u.Scheme, u.Host
+	s.hostname, err = os.Hostname()
+	if err != nil || s.hostname == "" {
+		s.hostname = "-"
+	}
+	s.entries = make(chan []byte, networkSinkBufferSize)
+	s.stopper = make(chan struct{})
+	s.done = make(chan struct{})
+	return s, nil
+}
+
+// accepts returns whether the entries of the given channel are sent to the
+// sink.
+func (s *networkSink) accepts(channel string) bool {
+	return s.channel == "" || s.channel == channel
+}
+
+// send encodes and buffers an entry written to logger l. It does not block:
+// the entry is dropped if the buffer is full.
+func (s *networkSink) send(l *loggingT, entry Entry, stacks []byte) {
+	var data []byte
+	if s.scheme == fluentScheme {
+		data = s.encodeFluent(l.prefix, entry, stacks)
+	} else {
+		buf := l.processForFile(entry, stacks)
+		data = s.encodeSyslog(l.prefix, entry, bytes.TrimRight(buf.Bytes(), "\n"))
+		l.putBuffer(buf)
+	}
+	select {
+	case s.entries <- data:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// syslogSeverity maps severities to the syslog severity levels.
+var syslogSeverity = map[Severity]int{
+	Severity_INFO:    6, // informational
+	Severity_WARNING: 4, // warning
+	Severity_ERROR:   3, // error
+	Severity_FATAL:   2, // critical
+}
+
+// syslogFacility is the syslog facility of the entries, user-level messages.
+const syslogFacility = 1
+
+// encodeSyslog encodes an entry as an RFC 5424 syslog message whose body is
+// the formatted entry. The app name is the channel.
+func (s *networkSink) encodeSyslog(channel string, entry Entry, body []byte) []byte {
+	sev, ok := syslogSeverity[entry.Severity]
+	if !ok {
+		sev = syslogSeverity[Severity_INFO]
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		syslogFacility*8+sev,
+		timeutil.Unix(0, entry.Time).UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, channel, os.Getpid(), body)
+	if s.scheme == syslogTCPScheme {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	return []byte(msg)
+}
+
+// encodeFluent encodes an entry as a Fluentd event whose tag is the channel
+// and whose record is the JSON representation of the entry.
+func (s *networkSink) encodeFluent(channel string, entry Entry, stacks []byte) []byte {
+	event := []interface{}{
+		channel,
+		entry.Time / int64(time.Second),
+		makeJSONEntry(entry, stacks, channel),
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		// Encoding can't fail: the event holds strings and integers.
+		panic(err)
+	}
+	return append(data, '\n')
+}
+
+// start starts the goroutine sending the buffered entries to the server.
+func (s *networkSink) start() {
+	go s.run()
+}
+
+// stop stops the sink, dropping the entries that have not been sent yet.
+func (s *networkSink) stop() {
+	close(s.stopper)
+	<-s.done
+}
+
+func (s *networkSink) run() {
+	defer close(s.done)
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	backoff := 100 * time.Millisecond
+	// failing is set while the server is unavailable, so that the error is
+	// only reported once.
+	failing := false
+	var pending []byte
+	for {
+		if pending == nil {
+			select {
+			case pending = <-s.entries:
+			case <-s.stopper:
+				return
+			}
+		}
+		var err error
+		if conn == nil {
+			network := "tcp"
+			if s.scheme == syslogUDPScheme {
+				network = "udp"
+			}
+			conn, err = net.DialTimeout(network, s.addr, networkSinkDialTimeout)
+		}
+		if err == nil {
+			_ = conn.SetWriteDeadline(timeutil.Now().Add(networkSinkWriteTimeout))
+			if _, err = conn.Write(pending); err != nil {
+				_ = conn.Close()
+				conn = nil
+			}
+		}
+		if err != nil {
+			if !failing {
+				// We can't log the error as that would recurse into the sink.
+				fmt.Fprintf(OrigStderr, "log: unable to send entries to log sink %s: %v\n", s.spec, err)
+				failing = true
+			}
+			select {
+			case <-time.After(backoff):
+			case <-s.stopper:
+				return
+			}
+			if backoff *= 2; backoff > networkSinkMaxBackoff {
+				backoff = networkSinkMaxBackoff
+			}
+			continue
+		}
+		pending = nil
+		backoff = 100 * time.Millisecond
+		failing = false
+		if n := atomic.SwapInt64(&s.dropped, 0); n > 0 {
+			fmt.Fprintf(OrigStderr, "log: dropped %d entries while log sink %s was unavailable\n", n, s.spec)
+		}
+	}
+}
+
+// networkSinks holds the sinks configured with the --log-sink flag.
+var networkSinks struct {
+	mu    syncutil.Mutex
+	sinks []*networkSink
+}
+
+// outputToNetworkSinks sends an entry to the network sinks of the logger's
+// channel.
+func (l *loggingT) outputToNetworkSinks(entry Entry, stacks []byte) {
+	networkSinks.mu.Lock()
+	defer networkSinks.mu.Unlock()
+	for _, s := range networkSinks.sinks {
+		if s.accepts(l.prefix) {
+			s.send(l, entry, stacks)
+		}
+	}
+}
+
+// networkSinkFlag is the value of the --log-sink flag. Each use of the flag
+// adds the given comma-separated sinks.
+type networkSinkFlag struct{}
+
+// String is part of the flag.Value interface.
+func (networkSinkFlag) String() string {
+	networkSinks.mu.Lock()
+	defer networkSinks.mu.Unlock()
+	specs := make([]string, len(networkSinks.sinks))
+	for i, s := range networkSinks.sinks {
+		specs[i] = s.spec
+	}
+	return strings.Join(specs, ",")
+}
+
+// Set is part of the flag.Value interface.
+func (networkSinkFlag) Set(value string) error {
+	var sinks []*networkSink
+	for _, spec := range strings.Split(value, ",") {
+		s, err := newNetworkSink(strings.TrimSpace(spec))
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, s)
+	}
+	networkSinks.mu.Lock()
+	defer networkSinks.mu.Unlock()
+	for _, s := range sinks {
+		s.start()
+		networkSinks.sinks = append(networkSinks.sinks, s)
+	}
+	return nil
+}
+
+// Type is part of the pflag.Value interface.
+func (networkSinkFlag) Type() string {
+	return "string"
+}
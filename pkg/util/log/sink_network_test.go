@@ -0,0 +1,201 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestNewNetworkSink(t *testing.T) {
+	for _, tc := range []struct {
+		spec    string
+		channel string
+		scheme  string
+		err     string
+	}{
+		{"syslog+tcp://localhost:514", "", syslogTCPScheme, ""},
+		{"cockroach-sql-audit=syslog+udp://10.0.0.1:514", "cockroach-sql-audit", syslogUDPScheme, ""},
+		{"fluent://fluentd:24224", "", fluentScheme, ""},
+		{"=fluent://fluentd:24224", "", "", "empty channel name"},
+		{"http://localhost:80", "", "", "unknown scheme"},
+		{"fluent://fluentd", "", "", "missing port"},
+	} {
+		s, err := newNetworkSink(tc.spec)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: expected error %q, got %v", tc.spec, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.channel != tc.channel || s.scheme != tc.scheme {
+			t.Errorf("%s: unexpected sink %+v", tc.spec, s)
+		}
+	}
+}
+
+// testEntry returns an entry to send to sinks.
+func testEntry(msg string) Entry {
+	return Entry{
+		Severity: Severity_WARNING,
+		Time:     timeutil.Now().UnixNano(),
+		File:     "sink_network_test.go",
+		Line:     42,
+		Message:  msg,
+	}
+}
+
+func TestNetworkSinkSyslog(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	s, err := newNetworkSink("syslog+tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.start()
+	defer s.stop()
+	s.send(&logging, testEntry("[n1] hello"), nil)
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(timeutil.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+	// Messages are framed by their length.
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`^<12>1 \S+Z \S+ ` + program + ` \d+ - - W\d{6} .* sink_network_test.go:42  \[n1\] hello$`)
+	if !re.Match(msg) {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestNetworkSinkFluent(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	s, err := newNetworkSink(program + "=fluent://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.start()
+	defer s.stop()
+	for _, msg := range []string{"first", "second"} {
+		s.send(&logging, testEntry(msg), nil)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(timeutil.Now().Add(10 * time.Second))
+	dec := json.NewDecoder(conn)
+	for _, expected := range []string{"first", "second"} {
+		var event []json.RawMessage
+		if err := dec.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+		var tag string
+		var e jsonEntry
+		if len(event) != 3 {
+			t.Fatalf("unexpected event %s", event)
+		}
+		if err := json.Unmarshal(event[0], &tag); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(event[2], &e); err != nil {
+			t.Fatal(err)
+		}
+		if tag != program || e.Severity != "WARNING" || e.Message != expected {
+			t.Fatalf("unexpected event %s", event)
+		}
+	}
+}
+
+func TestNetworkSinkReconnect(t *testing.T) {
+	// Reserve an address, and don't listen on it until an entry was sent.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s, err := newNetworkSink("fluent://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.start()
+	defer s.stop()
+	s.send(&logging, testEntry("buffered"), nil)
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("unable to listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(timeutil.Now().Add(10 * time.Second))
+	var event []json.RawMessage
+	if err := json.NewDecoder(conn).Decode(&event); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(event[2]), `"message":"buffered"`) {
+		t.Fatalf("unexpected event %s", event)
+	}
+}
+
+func TestNetworkSinkAccepts(t *testing.T) {
+	all := &networkSink{}
+	audit := &networkSink{channel: program + "-sql-audit"}
+	if !all.accepts(program) || !all.accepts(program+"-sql-audit") {
+		t.Error("expected the sink without channel to accept all channels")
+	}
+	if audit.accepts(program) || !audit.accepts(program+"-sql-audit") {
+		t.Error("expected the sink to only accept its channel")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// vmoduleRestore tracks the restoration of the vmodule setting in effect
+// before a temporary one set by SetVModuleFor.
+var vmoduleRestore struct {
+	syncutil.Mutex
+	// timer restores prev at the given time. It is nil when no temporary
+	// vmodule setting is in effect.
+	timer *time.Timer
+	prev  string
+	at    time.Time
+}
+
+// GetVModule returns the current vmodule setting, in the syntax of the
+// --vmodule flag.
+func GetVModule() string {
+	return logging.vmodule.String()
+}
+
+// SetVModule alters the vmodule logging level to the passed in value. It
+// cancels the restoration of the setting preceding a temporary one set by
+// SetVModuleFor, if any.
+func SetVModule(value string) error {
+	vmoduleRestore.Lock()
+	defer vmoduleRestore.Unlock()
+	if err := logging.vmodule.Set(value); err != nil {
+		return err
+	}
+	if vmoduleRestore.timer != nil {
+		vmoduleRestore.timer.Stop()
+		vmoduleRestore.timer = nil
+	}
+	return nil
+}
+
+// SetVModuleFor alters the vmodule logging level to the passed in value for
+// the given duration, after which the setting in effect before it is
+// restored. This allows enabling verbose logging briefly on a running node
+// without having to remember to turn it off. A temporary setting replacing
+// another one restores the setting preceding both.
+func SetVModuleFor(value string, d time.Duration) error {
+	if d <= 0 {
+		return errors.Errorf("invalid vmodule duration: %s", d)
+	}
+	vmoduleRestore.Lock()
+	defer vmoduleRestore.Unlock()
+	prev := GetVModule()
+	if vmoduleRestore.timer != nil {
+		prev = vmoduleRestore.prev
+	}
+	if err := logging.vmodule.Set(value); err != nil {
+		return err
+	}
+	if vmoduleRestore.timer != nil {
+		vmoduleRestore.timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		vmoduleRestore.Lock()
+		defer vmoduleRestore.Unlock()
+		// The timer may have fired concurrently with a new setting.
+		if vmoduleRestore.timer != timer {
+			return
+		}
+		vmoduleRestore.timer = nil
+		// prev was a valid setting, so this can't fail.
+		_ = logging.vmodule.Set(prev)
+	})
+	vmoduleRestore.timer = timer
+	vmoduleRestore.prev = prev
+	vmoduleRestore.at = timeutil.Now().Add(d)
+	return nil
+}
+
+// GetVModuleRestore returns the vmodule setting which will be restored at
+// the end of a temporary one set by SetVModuleFor, and the time at which it
+// will be restored. ok is false if no temporary setting is in effect.
+func GetVModuleRestore() (prev string, at time.Time, ok bool) {
+	vmoduleRestore.Lock()
+	defer vmoduleRestore.Unlock()
+	if vmoduleRestore.timer == nil {
+		return "", time.Time{}, false
+	}
+	return vmoduleRestore.prev, vmoduleRestore.at, true
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetVModuleFor(t *testing.T) {
+	defer func() { _ = SetVModule("") }()
+
+	if err := SetVModule("clog_test=1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVModuleFor("clog_test=3", 0); err == nil {
+		t.Fatal("expected an error for a non-positive duration")
+	}
+	if err := SetVModuleFor("clog_test", time.Hour); err == nil {
+		t.Fatal("expected an error for an invalid setting")
+	}
+	if _, _, ok := GetVModuleRestore(); ok {
+		t.Fatal("unexpected temporary setting after errors")
+	}
+
+	// A temporary setting replacing another one restores the setting
+	// preceding both.
+	if err := SetVModuleFor("clog_test=2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVModuleFor("clog_test=3", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !v(3) {
+		t.Error("V not enabled for 3")
+	}
+	// testutils can't be imported here, as it depends on this package.
+	for deadline := time.Now().Add(45 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, _, ok := GetVModuleRestore(); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("temporary setting still in effect")
+		}
+	}
+	if vmodule := GetVModule(); vmodule != "clog_test=1" {
+		t.Fatalf("expected the previous setting to be restored, got %q", vmodule)
+	}
+
+	// A permanent setting cancels the restoration.
+	if err := SetVModuleFor("clog_test=2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if prev, _, ok := GetVModuleRestore(); !ok || prev != "clog_test=1" {
+		t.Fatalf("unexpected restoration of %q (%t)", prev, ok)
+	}
+	if err := SetVModule("clog_test=3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := GetVModuleRestore(); ok {
+		t.Fatal("expected the restoration to be canceled")
+	}
+	if vmodule := GetVModule(); vmodule != "clog_test=3" {
+		t.Fatalf("unexpected setting %q", vmodule)
+	}
+}
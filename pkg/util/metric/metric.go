@@ -303,6 +303,52 @@ func (h *Histogram) ToPrometheusMetric() *prometheusgo.Metric {
 	}
 }
 
+// ToPrometheusMetricWithBuckets is like ToPrometheusMetric, but exports the
+// cumulative counts at the given sorted upper bounds rather than at the
+// buckets of the underlying HDR histogram, which depend on the recorded
+// values. Histograms exported with the same bounds by all the nodes can be
+// aggregated, e.g. with histogram_quantile() in Prometheus. The values are
+// attributed to the first bucket which covers their HDR bucket entirely.
+func (h *Histogram) ToPrometheusMetricWithBuckets(upperBounds []float64) *prometheusgo.Metric {
+	hist := &prometheusgo.Histogram{}
+
+	h.mu.Lock()
+	maybeTick(h.mu.sliding)
+	bars := h.mu.cumulative.Distribution()
+	h.mu.Unlock()
+
+	hist.Bucket = make([]*prometheusgo.Bucket, len(upperBounds))
+	var cumCount uint64
+	var sum float64
+	i := 0
+	for _, bar := range bars {
+		if bar.Count == 0 {
+			continue
+		}
+		for ; i < len(upperBounds) && float64(bar.To) > upperBounds[i]; i++ {
+			hist.Bucket[i] = makePrometheusBucket(upperBounds[i], cumCount)
+		}
+		sum += float64(bar.To) * float64(bar.Count)
+		cumCount += uint64(bar.Count)
+	}
+	for ; i < len(upperBounds); i++ {
+		hist.Bucket[i] = makePrometheusBucket(upperBounds[i], cumCount)
+	}
+	hist.SampleCount = &cumCount
+	hist.SampleSum = &sum
+
+	return &prometheusgo.Metric{
+		Histogram: hist,
+	}
+}
+
+func makePrometheusBucket(upperBound float64, cumCount uint64) *prometheusgo.Bucket {
+	return &prometheusgo.Bucket{
+		CumulativeCount: &cumCount,
+		UpperBound:      &upperBound,
+	}
+}
+
 // GetMetadata returns the metric's metadata including the Prometheus
 // MetricType.
 func (h *Histogram) GetMetadata() Metadata {
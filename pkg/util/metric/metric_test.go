@@ -13,7 +13,9 @@ package metric
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -132,6 +134,27 @@ func TestHistogramPrometheus(t *testing.T) {
 	}
 }
 
+func TestHistogramPrometheusWithBuckets(t *testing.T) {
+	h := NewHistogram(Metadata{}, time.Hour, 100, 1)
+	for _, v := range []int64{1, 5, 5, 10, 50, 15000} {
+		h.RecordValue(v)
+	}
+	act := h.ToPrometheusMetricWithBuckets([]float64{2, 10, 20, 40}).Histogram
+
+	var buckets []string
+	for _, b := range act.Bucket {
+		buckets = append(buckets, fmt.Sprintf("%g:%d", b.GetUpperBound(), b.GetCumulativeCount()))
+	}
+	// All the buckets are exported, even the empty ones. The values above the
+	// last bound are only reflected in the sample count.
+	if exp, a := "2:1 10:4 20:4 40:4", strings.Join(buckets, " "); exp != a {
+		t.Errorf("expected buckets %s, got %s", exp, a)
+	}
+	if act.GetSampleCount() != 6 {
+		t.Errorf("expected 6 samples, got %d", act.GetSampleCount())
+	}
+}
+
 func TestHistogramRotate(t *testing.T) {
 	defer TestingSetNow(nil)()
 	setNow(0)
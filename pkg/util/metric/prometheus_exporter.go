@@ -12,6 +12,7 @@ package metric
 
 import (
 	"io"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,6 +34,10 @@ import (
 //  pe.Export(w)
 type PrometheusExporter struct {
 	families map[string]*prometheusgo.MetricFamily
+	// latencyBuckets are the upper bounds, in nanoseconds, of the buckets of
+	// the exported latency histograms. The buckets of the underlying HDR
+	// histograms are exported if empty.
+	latencyBuckets []float64
 }
 
 // MakePrometheusExporter returns an initialized prometheus exporter.
@@ -59,17 +64,55 @@ func (pm *PrometheusExporter) findOrCreateFamily(
 	return family
 }
 
+// SetLatencyBuckets sets the upper bounds of the buckets of the latency
+// histograms, i.e. the histograms measured in nanoseconds, exported by the
+// subsequent scrapes. The bounds must be sorted. The buckets of the
+// underlying HDR histograms, which vary with the recorded values, are
+// exported if there are no bounds.
+func (pm *PrometheusExporter) SetLatencyBuckets(upperBounds []time.Duration) {
+	pm.latencyBuckets = pm.latencyBuckets[:0]
+	for _, b := range upperBounds {
+		pm.latencyBuckets = append(pm.latencyBuckets, float64(b.Nanoseconds()))
+	}
+}
+
+// MakeLabelPair returns a prometheus label pair, for use with
+// ScrapeRegistryWithLabels.
+func MakeLabelPair(name, value string) *prometheusgo.LabelPair {
+	return &prometheusgo.LabelPair{
+		Name:  proto.String(exportedLabel(name)),
+		Value: proto.String(value),
+	}
+}
+
 // ScrapeRegistry scrapes all metrics contained in the registry to the metric
 // family map, holding on only to the scraped data (which is no longer
 // connected to the registry and metrics within) when returning from the the
 // call. It creates new families as needed.
 func (pm *PrometheusExporter) ScrapeRegistry(registry *Registry) {
-	labels := registry.getLabels()
+	pm.ScrapeRegistryWithLabels(registry)
+}
+
+// ScrapeRegistryWithLabels is like ScrapeRegistry, but also sets the given
+// labels on all the metrics of the registry, ahead of the registry and metric
+// labels. This allows labels which aren't known when the registry is created,
+// e.g. the node ID, to be set at scrape time.
+func (pm *PrometheusExporter) ScrapeRegistryWithLabels(
+	registry *Registry, labels ...*prometheusgo.LabelPair,
+) {
+	labels = append(labels[:len(labels):len(labels)], registry.getLabels()...)
 	registry.Each(func(_ string, v interface{}) {
 		if prom, ok := v.(PrometheusExportable); ok {
-			m := prom.ToPrometheusMetric()
-			// Set registry and metric labels.
-			m.Label = append(labels, prom.GetLabels()...)
+			var m *prometheusgo.Metric
+			if h, ok := v.(*Histogram); ok && len(pm.latencyBuckets) > 0 &&
+				h.Unit == Unit_NANOSECONDS {
+				m = h.ToPrometheusMetricWithBuckets(pm.latencyBuckets)
+			} else {
+				m = prom.ToPrometheusMetric()
+			}
+			// Set the scrape, registry and metric labels. The labels are copied
+			// since they are shared by all the metrics of the registry.
+			m.Label = append(labels[:len(labels):len(labels)], prom.GetLabels()...)
 
 			family := pm.findOrCreateFamily(prom)
 			family.Metric = append(family.Metric, m)
@@ -81,8 +124,20 @@ func (pm *PrometheusExporter) ScrapeRegistry(registry *Registry) {
 // prometheus' text format. It removes individual metrics from the families
 // as it goes, readying the families for another found of registry additions.
 func (pm *PrometheusExporter) PrintAsText(w io.Writer) error {
+	return pm.PrintAs(w, expfmt.FmtText)
+}
+
+// PrintAs is like PrintAsText, but writes the metrics in the given prometheus
+// exposition format, e.g. the delimited protobuf format, in which histograms
+// are exported as such rather than as separate bucket, sum and count series.
+func (pm *PrometheusExporter) PrintAs(w io.Writer, format expfmt.Format) error {
+	enc := expfmt.NewEncoder(w, format)
 	for _, family := range pm.families {
-		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+		if len(family.Metric) == 0 {
+			// Families without metrics can't be encoded.
+			continue
+		}
+		if err := enc.Encode(family); err != nil {
 			return err
 		}
 	}
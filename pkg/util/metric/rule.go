@@ -0,0 +1,218 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Rule is a Prometheus rule recommended for monitoring CockroachDB, defined
+// over the metrics exported by the server. The expression of a rule refers to
+// its metrics by position, with fmt verbs such as %[1]s, so that it follows
+// the renames of the metrics it is defined over.
+type Rule interface {
+	// Name returns the name of the rule: the name of the alert for an
+	// alerting rule, or of the recorded series for an aggregation rule.
+	Name() string
+	// Expr returns the PromQL expression of the rule, with the exported
+	// names of its metrics.
+	Expr() string
+	// Metrics returns the metadata of the metrics used by the rule.
+	Metrics() []Metadata
+	// Help returns a description of the rule.
+	Help() string
+
+	prometheusRule() prometheusRule
+}
+
+// prometheusRule is the representation of a rule in a Prometheus rules file.
+type prometheusRule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// ruleBase holds the fields shared by all the rules.
+type ruleBase struct {
+	name    string
+	expr    string
+	metrics []Metadata
+	help    string
+}
+
+// Name implements the Rule interface.
+func (r *ruleBase) Name() string { return r.name }
+
+// Expr implements the Rule interface.
+func (r *ruleBase) Expr() string {
+	names := make([]interface{}, len(r.metrics))
+	for i := range r.metrics {
+		names[i] = exportedName(r.metrics[i].Name)
+	}
+	return fmt.Sprintf(r.expr, names...)
+}
+
+// Metrics implements the Rule interface.
+func (r *ruleBase) Metrics() []Metadata { return r.metrics }
+
+// Help implements the Rule interface.
+func (r *ruleBase) Help() string { return r.help }
+
+// AlertingRule is a rule which fires an alert when its expression has
+// results for a hold duration.
+type AlertingRule struct {
+	ruleBase
+	holdDuration time.Duration
+	severity     string
+	summary      string
+}
+
+var _ Rule = &AlertingRule{}
+
+// NewAlertingRule creates an alerting rule. The severity, e.g. "critical" or
+// "warning", and the summary of the alert are attached to the alert as a
+// label and an annotation respectively.
+func NewAlertingRule(
+	name, expr string,
+	metrics []Metadata,
+	holdDuration time.Duration,
+	severity, summary, help string,
+) *AlertingRule {
+	return &AlertingRule{
+		ruleBase:     ruleBase{name: name, expr: expr, metrics: metrics, help: help},
+		holdDuration: holdDuration,
+		severity:     severity,
+		summary:      summary,
+	}
+}
+
+func (r *AlertingRule) prometheusRule() prometheusRule {
+	rule := prometheusRule{
+		Alert:       r.name,
+		Expr:        r.Expr(),
+		Labels:      map[string]string{"severity": r.severity},
+		Annotations: map[string]string{"summary": r.summary, "description": r.help},
+	}
+	if r.holdDuration > 0 {
+		rule.For = r.holdDuration.String()
+	}
+	return rule
+}
+
+// AggregationRule is a rule which records the result of its expression as
+// a new series, e.g. to aggregate a metric across the nodes of a cluster.
+type AggregationRule struct {
+	ruleBase
+}
+
+var _ Rule = &AggregationRule{}
+
+// NewAggregationRule creates an aggregation rule recording the series of
+// the given name.
+func NewAggregationRule(name, expr string, metrics []Metadata, help string) *AggregationRule {
+	return &AggregationRule{ruleBase{name: name, expr: expr, metrics: metrics, help: help}}
+}
+
+func (r *AggregationRule) prometheusRule() prometheusRule {
+	return prometheusRule{Record: r.name, Expr: r.Expr()}
+}
+
+// RuleRegistry holds the rules recommended for monitoring the server.
+type RuleRegistry struct {
+	syncutil.Mutex
+	rules map[string]Rule
+}
+
+// NewRuleRegistry creates an empty RuleRegistry.
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{rules: make(map[string]Rule)}
+}
+
+// AddRule adds a rule to the registry. A rule replaces the rule of the same
+// name, if any.
+func (r *RuleRegistry) AddRule(rule Rule) {
+	r.Lock()
+	defer r.Unlock()
+	r.rules[rule.Name()] = rule
+}
+
+// Rules returns the rules of the registry, ordered by name.
+func (r *RuleRegistry) Rules() []Rule {
+	r.Lock()
+	defer r.Unlock()
+	rules := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+	return rules
+}
+
+// CheckMetrics returns an error listing the rules which use metrics for
+// which exported is false.
+func (r *RuleRegistry) CheckMetrics(exported func(name string) bool) error {
+	var missing []string
+	for _, rule := range r.Rules() {
+		for _, m := range rule.Metrics() {
+			if !exported(exportedName(m.Name)) {
+				missing = append(missing, fmt.Sprintf("%s (%s)", rule.Name(), m.Name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("rules use metrics which aren't exported: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Prometheus rule group names of the two kinds of rules.
+const (
+	alertingRulesGroup    = "rules/alerts"
+	aggregationRulesGroup = "rules/aggregation"
+)
+
+// PrintAsYAML writes the rules in the format of a Prometheus rules file,
+// with a group of alerting rules and a group of aggregation rules.
+func (r *RuleRegistry) PrintAsYAML(w io.Writer) error {
+	type group struct {
+		Name  string           `yaml:"name"`
+		Rules []prometheusRule `yaml:"rules"`
+	}
+	alerting := group{Name: alertingRulesGroup, Rules: []prometheusRule{}}
+	aggregation := group{Name: aggregationRulesGroup, Rules: []prometheusRule{}}
+	for _, rule := range r.Rules() {
+		switch rule.(type) {
+		case *AlertingRule:
+			alerting.Rules = append(alerting.Rules, rule.prometheusRule())
+		default:
+			aggregation.Rules = append(aggregation.Rules, rule.prometheusRule())
+		}
+	}
+	out, err := yaml.Marshal(struct {
+		Groups []group `yaml:"groups"`
+	}{Groups: []group{alerting, aggregation}})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
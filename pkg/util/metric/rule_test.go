@@ -0,0 +1,73 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRuleRegistry(t *testing.T) {
+	capacity := Metadata{Name: "capacity"}
+	available := Metadata{Name: "capacity.available"}
+
+	r := NewRuleRegistry()
+	r.AddRule(NewAlertingRule(
+		"StoreDiskLow", "%[2]s / %[1]s < 0.15", []Metadata{capacity, available},
+		30*time.Minute, "critical", "low disk", "The store is low on disk space.",
+	))
+	r.AddRule(NewAggregationRule(
+		"cluster:capacity:sum", "sum without(store) (%[1]s)", []Metadata{capacity},
+		"Capacity of the cluster.",
+	))
+
+	rules := r.Rules()
+	if len(rules) != 2 || rules[0].Name() != "StoreDiskLow" {
+		t.Fatalf("unexpected rules: %v", rules)
+	}
+	if e, a := "capacity_available / capacity < 0.15", rules[0].Expr(); e != a {
+		t.Errorf("expected expression %q, got %q", e, a)
+	}
+
+	var buf bytes.Buffer
+	if err := r.PrintAsYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `groups:
+- name: rules/alerts
+  rules:
+  - alert: StoreDiskLow
+    expr: capacity_available / capacity < 0.15
+    for: 30m0s
+    labels:
+      severity: critical
+    annotations:
+      description: The store is low on disk space.
+      summary: low disk
+- name: rules/aggregation
+  rules:
+  - record: cluster:capacity:sum
+    expr: sum without(store) (capacity)
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	// The registry reports the metrics which aren't exported.
+	if err := r.CheckMetrics(func(name string) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	err := r.CheckMetrics(func(name string) bool { return name != "capacity_available" })
+	if e := "rules use metrics which aren't exported: StoreDiskLow (capacity.available)"; err == nil || err.Error() != e {
+		t.Errorf("expected error %q, got %v", e, err)
+	}
+}
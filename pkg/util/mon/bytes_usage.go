@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math"
 	"math/bits"
+	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -181,6 +182,16 @@ type BytesMonitor struct {
 		// curBudget represents the budget allocated at the pool on behalf of
 		// this monitor.
 		curBudget BoundAccount
+
+		// head is the first of the monitors which use this monitor as their
+		// pool, linked through their parentMu.nextSibling fields.
+		head *BytesMonitor
+	}
+
+	// parentMu links this monitor in the list of the children of its pool.
+	// It is protected by the mutex of the pool.
+	parentMu struct {
+		prevSibling, nextSibling *BytesMonitor
 	}
 
 	// name identifies this monitor in logging messages.
@@ -328,6 +339,9 @@ func (mm *BytesMonitor) Start(ctx context.Context, pool *BytesMonitor, reserved
 	mm.mu.maxAllocated = 0
 	mm.mu.curBudget = pool.MakeBoundAccount()
 	mm.reserved = reserved
+	if pool != nil {
+		pool.addChild(mm)
+	}
 	if log.V(2) {
 		poolname := "(none)"
 		if pool != nil {
@@ -380,8 +394,8 @@ func (mm *BytesMonitor) Stop(ctx context.Context) {
 }
 
 func (mm *BytesMonitor) doStop(ctx context.Context, check bool) {
-	// NB: No need to lock mm.mu here, when StopMonitor() is called the
-	// monitor is not shared any more.
+	// NB: No need to lock mm.mu to read the state of the monitor here, when
+	// StopMonitor() is called the monitor is not shared any more.
 	if log.V(1) {
 		log.InfofDepth(ctx, 1, "%s, bytes usage max %s",
 			mm.name,
@@ -395,6 +409,14 @@ func (mm *BytesMonitor) doStop(ctx context.Context, check bool) {
 		mm.releaseBytes(ctx, mm.mu.curAllocated)
 	}
 
+	if pool := mm.mu.curBudget.mon; pool != nil {
+		pool.removeChild(mm)
+	}
+	// The monitor may still be visited by a concurrent TraverseTree which
+	// found it in the list of its pool before it was removed.
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
 	mm.releaseBudget(ctx)
 
 	if mm.maxBytesHist != nil && mm.mu.maxAllocated > 0 {
@@ -428,6 +450,106 @@ func (mm *BytesMonitor) AllocBytes() int64 {
 	return mm.mu.curAllocated
 }
 
+// addChild adds child to the list of the monitors which use mm as their pool.
+func (mm *BytesMonitor) addChild(child *BytesMonitor) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if mm.mu.head != nil {
+		mm.mu.head.parentMu.prevSibling = child
+		child.parentMu.nextSibling = mm.mu.head
+	}
+	mm.mu.head = child
+}
+
+// removeChild removes child from the list of the monitors which use mm as
+// their pool.
+func (mm *BytesMonitor) removeChild(child *BytesMonitor) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	prev, next := child.parentMu.prevSibling, child.parentMu.nextSibling
+	if prev != nil {
+		prev.parentMu.nextSibling = next
+	} else {
+		mm.mu.head = next
+	}
+	if next != nil {
+		next.parentMu.prevSibling = prev
+	}
+	child.parentMu.prevSibling, child.parentMu.nextSibling = nil, nil
+}
+
+// MonitorState describes the state of a monitor at the time it was visited by
+// TraverseTree.
+type MonitorState struct {
+	// Level is the depth of the monitor in the tree, the root being at level
+	// 0.
+	Level int
+	// Name is the name of the monitor.
+	Name string
+	// ID identifies the monitor while it is running; ParentID is the ID of its
+	// pool, or 0 for the root of the traversal.
+	ID, ParentID int64
+	// Used is the number of bytes currently allocated by the clients of the
+	// monitor, and Peak the high water mark of Used.
+	Used, Peak int64
+	// PoolBudget is the number of bytes the monitor currently holds from its
+	// pool, and ReservedBudget the number of bytes pre-reserved for it when it
+	// was started.
+	PoolBudget, ReservedBudget int64
+	// Limit is the local limit of the monitor, or 0 if it has none.
+	Limit int64
+}
+
+// TraverseTree calls fn with the state of mm and, recursively, of the monitors
+// which use it as their pool, in depth-first order. The monitors which are
+// started or stopped during the traversal may or may not be visited.
+func (mm *BytesMonitor) TraverseTree(fn func(MonitorState) error) error {
+	return mm.traverseTree(0 /* level */, 0 /* parentID */, fn)
+}
+
+func (mm *BytesMonitor) traverseTree(
+	level int, parentID int64, fn func(MonitorState) error,
+) error {
+	// The mutex of a monitor is never held while locking its pool's, but
+	// allocations lock the monitor of a client before its pool's, so only one
+	// of them is locked at a time here.
+	mm.mu.Lock()
+	state := MonitorState{
+		Level:          level,
+		Name:           mm.name,
+		ID:             mm.id(),
+		ParentID:       parentID,
+		Used:           mm.mu.curAllocated,
+		Peak:           mm.mu.maxAllocated,
+		PoolBudget:     mm.mu.curBudget.used,
+		ReservedBudget: mm.reserved.used,
+	}
+	if mm.limit != math.MaxInt64 {
+		state.Limit = mm.limit
+	}
+	var children []*BytesMonitor
+	for c := mm.mu.head; c != nil; c = c.parentMu.nextSibling {
+		children = append(children, c)
+	}
+	mm.mu.Unlock()
+
+	if err := fn(state); err != nil {
+		return err
+	}
+	for _, c := range children {
+		if err := c.traverseTree(level+1, state.ID, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// id returns an identifier of the monitor, unique among the running
+// monitors.
+func (mm *BytesMonitor) id() int64 {
+	return int64(uintptr(unsafe.Pointer(mm)))
+}
+
 // BoundAccount tracks the cumulated allocations for one client of a pool or
 // monitor. BytesMonitor has an account to its pool; BytesMonitor clients have
 // an account to the monitor. This allows each client to release all the bytes
@@ -672,7 +794,7 @@ func (mm *BytesMonitor) roundSize(sz int64) int64 {
 // releaseBudget relinquishes all the monitor's allocated bytes back to the
 // pool.
 func (mm *BytesMonitor) releaseBudget(ctx context.Context) {
-	// NB: mm.mu need not be locked here, as this is only called from StopMonitor().
+	// NB: mm.mu already locked by doStop().
 	if log.V(2) {
 		log.Infof(ctx, "%s: releasing %d bytes to the pool", mm.name, mm.mu.curBudget.allocated())
 	}
@@ -327,6 +327,64 @@ func TestBytesMonitor(t *testing.T) {
 	m.Stop(ctx)
 }
 
+func TestBytesMonitorTraverseTree(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	root := MakeMonitor("root", MemoryResource, nil, nil, 1, 1000, st)
+	root.Start(ctx, nil, MakeStandaloneBudget(1000))
+	child1 := MakeMonitorWithLimit("child1", MemoryResource, 100, nil, nil, 1, 1000, st)
+	child1.Start(ctx, &root, BoundAccount{})
+	child2 := MakeMonitor("child2", MemoryResource, nil, nil, 1, 1000, st)
+	child2.Start(ctx, &root, MakeStandaloneBudget(10))
+	grandchild := MakeMonitor("grandchild", MemoryResource, nil, nil, 1, 1000, st)
+	grandchild.Start(ctx, &child1, BoundAccount{})
+
+	acc := grandchild.MakeBoundAccount()
+	if err := acc.Grow(ctx, 50); err != nil {
+		t.Fatal(err)
+	}
+	acc.Shrink(ctx, 20)
+
+	traverse := func() []string {
+		var res []string
+		if err := root.TraverseTree(func(s MonitorState) error {
+			res = append(res, fmt.Sprintf("%d %s used=%d peak=%d budget=%d reserved=%d limit=%d",
+				s.Level, s.Name, s.Used, s.Peak, s.PoolBudget, s.ReservedBudget, s.Limit))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	checkTree := func(expected []string) {
+		t.Helper()
+		if res := traverse(); fmt.Sprint(res) != fmt.Sprint(expected) {
+			t.Fatalf("expected:\n%s\nfound:\n%s", expected, res)
+		}
+	}
+
+	// The children of a monitor are visited from the most recently started.
+	checkTree([]string{
+		"0 root used=30 peak=50 budget=0 reserved=1000 limit=0",
+		"1 child2 used=0 peak=0 budget=0 reserved=10 limit=0",
+		"1 child1 used=30 peak=50 budget=30 reserved=0 limit=100",
+		"2 grandchild used=30 peak=50 budget=30 reserved=0 limit=0",
+	})
+
+	acc.Close(ctx)
+	grandchild.Stop(ctx)
+	child2.Stop(ctx)
+	checkTree([]string{
+		"0 root used=0 peak=50 budget=0 reserved=1000 limit=0",
+		"1 child1 used=0 peak=50 budget=0 reserved=0 limit=100",
+	})
+
+	child1.Stop(ctx)
+	root.Stop(ctx)
+}
+
 func TestMemoryAllocationEdgeCases(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
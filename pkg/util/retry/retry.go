@@ -28,15 +28,41 @@ type Options struct {
 	MaxRetries          int             // Maximum number of attempts (0 for infinite)
 	RandomizationFactor float64         // Randomize the backoff interval by constant
 	Closer              <-chan struct{} // Optionally end retry loop channel close.
+	// MaxDuration bounds the cumulative time spent in the retry loop since
+	// its start or last reset: no retry is attempted past it (0 for
+	// unlimited).
+	MaxDuration time.Duration
+	// DecorrelatedJitter, if set, draws each backoff at random between
+	// InitialBackoff and three times the previous backoff, capped at
+	// MaxBackoff, instead of using Multiplier and RandomizationFactor. This
+	// spreads the retries of concurrent loops over time better than
+	// exponential backoff with proportional jitter.
+	DecorrelatedJitter bool
+	// OnRetry, if set, is called with the context of the retry loop before
+	// waiting for each retry, e.g. to log it. attempt is the number of the
+	// retry, starting at 1.
+	OnRetry func(ctx context.Context, attempt int, backoff time.Duration)
+}
+
+// IsZero returns whether the options are all unset.
+func (opts Options) IsZero() bool {
+	return opts.InitialBackoff == 0 && opts.MaxBackoff == 0 && opts.Multiplier == 0 &&
+		opts.MaxRetries == 0 && opts.RandomizationFactor == 0 && opts.Closer == nil &&
+		opts.MaxDuration == 0 && !opts.DecorrelatedJitter && opts.OnRetry == nil
 }
 
 // Retry implements the public methods necessary to control an exponential-
 // backoff retry loop.
 type Retry struct {
 	opts           Options
+	ctx            context.Context
 	ctxDoneChan    <-chan struct{}
 	currentAttempt int
 	isReset        bool
+	// start is the time of the start or last reset of the loop, and
+	// prevBackoff the previous backoff when using decorrelated jitter.
+	start       time.Time
+	prevBackoff time.Duration
 }
 
 // Start returns a new Retry initialized to some default values. The Retry can
@@ -62,7 +88,7 @@ func StartWithCtx(ctx context.Context, opts Options) Retry {
 		opts.Multiplier = 2
 	}
 
-	r := Retry{opts: opts}
+	r := Retry{opts: opts, ctx: ctx}
 	r.ctxDoneChan = ctx.Done()
 	r.Reset()
 	return r
@@ -84,6 +110,8 @@ func (r *Retry) Reset() {
 	}
 	r.currentAttempt = 0
 	r.isReset = true
+	r.start = timeutil.Now()
+	r.prevBackoff = 0
 }
 
 func (r Retry) retryIn() time.Duration {
@@ -99,6 +127,37 @@ func (r Retry) retryIn() time.Duration {
 	return time.Duration(backoff - delta + rand.Float64()*(2*delta+1))
 }
 
+// nextBackoff returns the backoff before the next retry, and whether the
+// retry fits in the MaxRetries and MaxDuration budgets. It calls the
+// OnRetry hook for retries within the budgets.
+func (r *Retry) nextBackoff() (time.Duration, bool) {
+	if r.opts.MaxRetries > 0 && r.currentAttempt >= r.opts.MaxRetries {
+		return 0, false
+	}
+	var backoff time.Duration
+	if r.opts.DecorrelatedJitter {
+		// Draw the backoff from [InitialBackoff, 3*prevBackoff].
+		lo, hi := r.opts.InitialBackoff, 3*r.prevBackoff
+		if hi < lo {
+			hi = lo
+		}
+		backoff = lo + time.Duration(rand.Int63n(int64(hi-lo)+1))
+		if backoff > r.opts.MaxBackoff {
+			backoff = r.opts.MaxBackoff
+		}
+		r.prevBackoff = backoff
+	} else {
+		backoff = r.retryIn()
+	}
+	if r.opts.MaxDuration > 0 && timeutil.Since(r.start)+backoff > r.opts.MaxDuration {
+		return 0, false
+	}
+	if r.opts.OnRetry != nil {
+		r.opts.OnRetry(r.ctx, r.currentAttempt+1, backoff)
+	}
+	return backoff, true
+}
+
 // Next returns whether the retry loop should continue, and blocks for the
 // appropriate length of time before yielding back to the caller. If a stopper
 // is present, Next will eagerly return false when the stopper is stopped.
@@ -108,13 +167,14 @@ func (r *Retry) Next() bool {
 		return true
 	}
 
-	if r.opts.MaxRetries > 0 && r.currentAttempt >= r.opts.MaxRetries {
+	backoff, ok := r.nextBackoff()
+	if !ok {
 		return false
 	}
 
 	// Wait before retry.
 	select {
-	case <-time.After(r.retryIn()):
+	case <-time.After(backoff):
 		r.currentAttempt++
 		return true
 	case <-r.opts.Closer:
@@ -139,11 +199,12 @@ func (r *Retry) NextCh() <-chan time.Time {
 		r.isReset = false
 		return closedC
 	}
+	backoff, ok := r.nextBackoff()
 	r.currentAttempt++
-	if r.opts.MaxRetries > 0 && r.currentAttempt > r.opts.MaxRetries {
+	if !ok {
 		return nil
 	}
-	return time.After(r.retryIn())
+	return time.After(backoff)
 }
 
 // WithMaxAttempts is a helper that runs fn N times and collects the last err.
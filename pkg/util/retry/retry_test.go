@@ -163,3 +163,84 @@ func TestRetryWithMaxAttempts(t *testing.T) {
 		t.Errorf("expected %d attempts, got %d attempts", maxAttempts, attempts)
 	}
 }
+
+func TestRetryMaxDuration(t *testing.T) {
+	opts := Options{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		MaxDuration:    55 * time.Millisecond,
+	}
+
+	// The retries fit in the budget as long as their backoff ends before it
+	// is exhausted.
+	var attempts int
+	for r := Start(opts); r.Next(); attempts++ {
+	}
+	if attempts < 2 || attempts > 6 {
+		t.Errorf("expected between 2 and 6 attempts in the budget, got %d", attempts)
+	}
+
+	// A retry whose backoff would exceed the budget isn't attempted.
+	opts.MaxBackoff = time.Minute
+	opts.InitialBackoff = time.Minute
+	attempts = 0
+	for r := Start(opts); r.Next(); attempts++ {
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryDecorrelatedJitter(t *testing.T) {
+	opts := Options{
+		InitialBackoff:     10 * time.Microsecond,
+		MaxBackoff:         time.Millisecond,
+		MaxRetries:         100,
+		DecorrelatedJitter: true,
+	}
+
+	r := Start(opts)
+	var prev time.Duration
+	for i := 0; i < 100; i++ {
+		d, ok := r.nextBackoff()
+		if !ok {
+			t.Fatalf("unexpected end of the retries at %d", i)
+		}
+		hi := 3 * prev
+		if hi < opts.InitialBackoff {
+			hi = opts.InitialBackoff
+		}
+		if hi > opts.MaxBackoff {
+			hi = opts.MaxBackoff
+		}
+		if d < opts.InitialBackoff || d > hi {
+			t.Fatalf("expected backoff in [%s, %s], got %s", opts.InitialBackoff, hi, d)
+		}
+		prev = d
+		r.currentAttempt++
+	}
+}
+
+func TestRetryOnRetry(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	var retries []int
+	opts := Options{
+		InitialBackoff: time.Microsecond,
+		MaxRetries:     3,
+		OnRetry: func(ctx context.Context, attempt int, backoff time.Duration) {
+			if ctx.Value(key{}) != "v" {
+				t.Error("expected the context of the retry loop")
+			}
+			retries = append(retries, attempt)
+		},
+	}
+	for r := StartWithCtx(ctx, opts); r.Next(); {
+	}
+	if len(retries) != 3 || retries[0] != 1 || retries[2] != 3 {
+		t.Errorf("expected retries 1 to 3, got %v", retries)
+	}
+	if opts.IsZero() || !(Options{}).IsZero() {
+		t.Error("unexpected IsZero")
+	}
+}
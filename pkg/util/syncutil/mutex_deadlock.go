@@ -13,13 +13,34 @@
 package syncutil
 
 import (
+	"bytes"
 	"time"
 
 	deadlock "github.com/sasha-s/go-deadlock"
 )
 
+// deadlockReport buffers the report of a potential deadlock, which includes
+// the stacks of the conflicting lock acquisitions, until it is included in
+// the panic of onPotentialDeadlock. It is only accessed while go-deadlock
+// holds its options lock.
+var deadlockReport bytes.Buffer
+
 func init() {
 	deadlock.Opts.DeadlockTimeout = 5 * time.Minute
+	// Mutexes record the order in which they are acquired by each goroutine,
+	// and acquiring two of them in an order inconsistent with a previously
+	// recorded one is reported as a potential deadlock, like a lock which
+	// can't be acquired within the timeout above. Instead of exiting the
+	// process, which loses the context of the failing test, panic with the
+	// report.
+	deadlock.Opts.LogBuf = &deadlockReport
+	deadlock.Opts.OnPotentialDeadlock = onPotentialDeadlock
+}
+
+func onPotentialDeadlock() {
+	report := deadlockReport.String()
+	deadlockReport.Reset()
+	panic("potential deadlock detected:\n" + report)
 }
 
 // A Mutex is a mutual exclusion lock.
@@ -0,0 +1,54 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build deadlock
+
+package syncutil
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const lockOrderEnv = "COCKROACH_TEST_INCONSISTENT_LOCK_ORDER"
+
+func TestInconsistentLockOrder(t *testing.T) {
+	if os.Getenv(lockOrderEnv) != "" {
+		// The panic leaves the state of go-deadlock locked, so it is triggered
+		// in a separate process.
+		var a, b Mutex
+		a.Lock()
+		b.Lock()
+		b.Unlock()
+		a.Unlock()
+
+		b.Lock()
+		a.Lock()
+		t.Fatal("expected acquiring the mutexes in the reverse order to panic")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestInconsistentLockOrder$")
+	cmd.Env = append(os.Environ(), lockOrderEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the process to fail, got:\n%s", out)
+	}
+	for _, expected := range []string{
+		"panic: potential deadlock detected",
+		"Inconsistent locking",
+		"TestInconsistentLockOrder",
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package timeutil
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ManualTime is a TimeSource whose time only moves when advanced by Advance
+// or AdvanceTo, which fire the timers and tickers whose time has come. It is
+// used for testing.
+type ManualTime struct {
+	mu struct {
+		sync.Mutex
+		now     time.Time
+		timers  map[*manualTimer]struct{}
+		tickers map[*manualTicker]struct{}
+	}
+}
+
+var _ TimeSource = &ManualTime{}
+
+// NewManualTime creates a ManualTime starting at the given time.
+func NewManualTime(initialTime time.Time) *ManualTime {
+	m := &ManualTime{}
+	m.mu.now = initialTime
+	m.mu.timers = make(map[*manualTimer]struct{})
+	m.mu.tickers = make(map[*manualTicker]struct{})
+	return m
+}
+
+// Now implements the TimeSource interface.
+func (m *ManualTime) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mu.now
+}
+
+// Since implements the TimeSource interface.
+func (m *ManualTime) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+// NewTimer implements the TimeSource interface.
+func (m *ManualTime) NewTimer() TimerI {
+	return &manualTimer{m: m, ch: make(chan time.Time, 1)}
+}
+
+// NewTicker implements the TimeSource interface.
+func (m *ManualTime) NewTicker(duration time.Duration) TickerI {
+	if duration <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &manualTicker{
+		m:        m,
+		ch:       make(chan time.Time, 1),
+		next:     m.mu.now.Add(duration),
+		duration: duration,
+	}
+	m.mu.tickers[t] = struct{}{}
+	return t
+}
+
+// Advance moves the time forward by the given duration.
+func (m *ManualTime) Advance(duration time.Duration) {
+	m.AdvanceTo(m.Now().Add(duration))
+}
+
+// AdvanceTo moves the time forward to the given time, firing the timers and
+// tickers due by then. It is a no-op if the time is in the past.
+func (m *ManualTime) AdvanceTo(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !now.After(m.mu.now) {
+		return
+	}
+	m.mu.now = now
+	for t := range m.mu.timers {
+		if !t.deadline.After(now) {
+			delete(m.mu.timers, t)
+			t.fire(t.deadline)
+		}
+	}
+	for t := range m.mu.tickers {
+		// Like time.Ticker, drop the ticks which the reader doesn't keep up
+		// with.
+		for !t.next.After(now) {
+			select {
+			case t.ch <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.duration)
+		}
+	}
+}
+
+// Timers returns the deadlines of the pending timers, in increasing order.
+// Tests can use it to wait for a component to set a timer before advancing
+// the time.
+func (m *ManualTime) Timers() []time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deadlines := make([]time.Time, 0, len(m.mu.timers))
+	for t := range m.mu.timers {
+		deadlines = append(deadlines, t.deadline)
+	}
+	sort.Slice(deadlines, func(i, j int) bool { return deadlines[i].Before(deadlines[j]) })
+	return deadlines
+}
+
+// manualTimer is the TimerI of a ManualTime.
+type manualTimer struct {
+	m  *ManualTime
+	ch chan time.Time
+	// deadline is protected by m.mu.
+	deadline time.Time
+}
+
+// fire notifies the channel of the timer, unless a previous notification
+// wasn't read.
+func (t *manualTimer) fire(now time.Time) {
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// Reset implements the TimerI interface.
+func (t *manualTimer) Reset(duration time.Duration) {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	// Discard a notification which wasn't read.
+	select {
+	case <-t.ch:
+	default:
+	}
+	t.deadline = t.m.mu.now.Add(duration)
+	if duration <= 0 {
+		delete(t.m.mu.timers, t)
+		t.fire(t.m.mu.now)
+		return
+	}
+	t.m.mu.timers[t] = struct{}{}
+}
+
+// Stop implements the TimerI interface.
+func (t *manualTimer) Stop() bool {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	_, ok := t.m.mu.timers[t]
+	delete(t.m.mu.timers, t)
+	return ok
+}
+
+// Ch implements the TimerI interface.
+func (t *manualTimer) Ch() <-chan time.Time {
+	return t.ch
+}
+
+// MarkRead implements the TimerI interface.
+func (t *manualTimer) MarkRead() {}
+
+// manualTicker is the TickerI of a ManualTime.
+type manualTicker struct {
+	m  *ManualTime
+	ch chan time.Time
+	// next is protected by m.mu.
+	next     time.Time
+	duration time.Duration
+}
+
+// Stop implements the TickerI interface.
+func (t *manualTicker) Stop() {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	delete(t.m.mu.tickers, t)
+}
+
+// Ch implements the TickerI interface.
+func (t *manualTicker) Ch() <-chan time.Time {
+	return t.ch
+}
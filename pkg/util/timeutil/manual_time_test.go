@@ -0,0 +1,76 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualTime(t *testing.T) {
+	start := Unix(0, 0)
+	m := NewManualTime(start)
+
+	timer := m.NewTimer()
+	timer.Reset(time.Second)
+	ticker := m.NewTicker(time.Second)
+	defer ticker.Stop()
+	if timers := m.Timers(); len(timers) != 1 || !timers[0].Equal(start.Add(time.Second)) {
+		t.Fatalf("unexpected timers %v", timers)
+	}
+
+	m.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.Ch():
+		t.Fatal("unexpected timer notification")
+	case <-ticker.Ch():
+		t.Fatal("unexpected tick")
+	default:
+	}
+
+	m.Advance(time.Millisecond)
+	if now := <-timer.Ch(); !now.Equal(start.Add(time.Second)) {
+		t.Fatalf("unexpected timer notification at %s", now)
+	}
+	timer.MarkRead()
+	if now := <-ticker.Ch(); !now.Equal(start.Add(time.Second)) {
+		t.Fatalf("unexpected tick at %s", now)
+	}
+	if len(m.Timers()) != 0 {
+		t.Fatalf("unexpected timers %v", m.Timers())
+	}
+
+	// The ticks which aren't read are dropped, and stopped timers don't fire.
+	timer.Reset(time.Second)
+	if !timer.Stop() {
+		t.Fatal("expected the timer to be stopped")
+	}
+	m.AdvanceTo(start.Add(5 * time.Second))
+	if now := <-ticker.Ch(); !now.Equal(start.Add(2 * time.Second)) {
+		t.Fatalf("unexpected tick at %s", now)
+	}
+	select {
+	case <-timer.Ch():
+		t.Fatal("unexpected timer notification")
+	case <-ticker.Ch():
+		t.Fatal("unexpected tick")
+	default:
+	}
+	if d := m.Since(start); d != 5*time.Second {
+		t.Fatalf("expected 5s since the start, got %s", d)
+	}
+
+	// The time doesn't go backwards.
+	m.AdvanceTo(start)
+	if !m.Now().Equal(start.Add(5 * time.Second)) {
+		t.Fatalf("unexpected time %s", m.Now())
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package timeutil
+
+import "time"
+
+// TimeSource is used to interact with the clock and timers. Components
+// which wait on timers take a TimeSource, so that tests can substitute a
+// ManualTime and advance time deterministically instead of sleeping.
+// DefaultTimeSource is used otherwise.
+type TimeSource interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer() TimerI
+	NewTicker(duration time.Duration) TickerI
+}
+
+// TimerI is an interface wrapping Timer.
+type TimerI interface {
+	// Reset sets the timer to notify on Ch() after duration. As for Timer,
+	// MarkRead must have been called if a value was read from Ch() since
+	// the last Reset.
+	Reset(duration time.Duration)
+	// Stop prevents the timer from firing. The timer can't be used anymore
+	// after it was stopped.
+	Stop() bool
+	// Ch returns the channel notified when the timer fires.
+	Ch() <-chan time.Time
+	// MarkRead must be called when a value is read from Ch().
+	MarkRead()
+}
+
+// TickerI is an interface wrapping time.Ticker.
+type TickerI interface {
+	// Stop turns off the ticker.
+	Stop()
+	// Ch returns the channel notified on each tick.
+	Ch() <-chan time.Time
+}
+
+// DefaultTimeSource is a TimeSource using the system clock.
+type DefaultTimeSource struct{}
+
+var _ TimeSource = DefaultTimeSource{}
+
+// Now implements the TimeSource interface.
+func (DefaultTimeSource) Now() time.Time {
+	return Now()
+}
+
+// Since implements the TimeSource interface.
+func (DefaultTimeSource) Since(t time.Time) time.Duration {
+	return Since(t)
+}
+
+// NewTimer implements the TimeSource interface.
+func (DefaultTimeSource) NewTimer() TimerI {
+	return NewTimer()
+}
+
+// NewTicker implements the TimeSource interface.
+func (DefaultTimeSource) NewTicker(duration time.Duration) TickerI {
+	return (*ticker)(time.NewTicker(duration))
+}
+
+// ticker implements TickerI with a time.Ticker.
+type ticker time.Ticker
+
+// Stop implements the TickerI interface.
+func (t *ticker) Stop() {
+	(*time.Ticker)(t).Stop()
+}
+
+// Ch implements the TickerI interface.
+func (t *ticker) Ch() <-chan time.Time {
+	return t.C
+}
@@ -106,3 +106,14 @@ func (t *Timer) Stop() bool {
 	timerPool.Put(t)
 	return res
 }
+
+// Ch returns the channel of the timer, C. It is part of the TimerI
+// interface.
+func (t *Timer) Ch() <-chan time.Time {
+	return t.C
+}
+
+// MarkRead sets Read to true. It is part of the TimerI interface.
+func (t *Timer) MarkRead() {
+	t.Read = true
+}
@@ -0,0 +1,174 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// An Operation is a long-running internal operation, e.g. a schema change
+// backfill, a GC run or the sending of a snapshot, registered with the
+// Tracer while it is running so that the active operations of a node can be
+// inspected. Each operation has its own span, which is real even if tracing
+// is disabled so that the operation can be identified in the traces.
+//
+// A nil *Operation is valid and does nothing.
+type Operation struct {
+	id        int64
+	name      string
+	startTime time.Time
+	tracer    *Tracer
+	span      opentracing.Span
+
+	mu struct {
+		syncutil.Mutex
+		// progress is the fraction of the operation which is completed, or
+		// negative if unknown.
+		progress float64
+		details  string
+	}
+}
+
+// OperationState describes an active operation, as returned by
+// Tracer.Operations.
+type OperationState struct {
+	ID        int64
+	Name      string
+	StartTime time.Time
+	// TraceID and SpanID identify the span of the operation.
+	TraceID, SpanID uint64
+	// Progress is the fraction of the operation which is completed, or
+	// negative if it is not reported by the operation.
+	Progress float64
+	Details  string
+}
+
+// operations is the registry of the active operations of a Tracer.
+type operations struct {
+	syncutil.Mutex
+	lastID int64
+	active map[int64]*Operation
+}
+
+type operationKey struct{}
+
+// StartOperation registers a long-running operation with the given tracer,
+// if it is a *Tracer, and returns a context with the span of the operation,
+// which is a child of the span of ctx if any. Finish must be called on the
+// returned operation once it completes. The operation can be retrieved from
+// the returned context with OperationFromContext to report its progress.
+func StartOperation(
+	ctx context.Context, tr opentracing.Tracer, name string,
+) (context.Context, *Operation) {
+	t, ok := tr.(*Tracer)
+	if !ok || t == nil {
+		return ctx, nil
+	}
+	opts := []opentracing.StartSpanOption{Recordable, LogTagsFromCtx(ctx)}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	op := &Operation{
+		name:      name,
+		startTime: timeutil.Now(),
+		tracer:    t,
+		span:      t.StartSpan(name, opts...),
+	}
+	op.mu.progress = -1
+
+	t.operations.Lock()
+	t.operations.lastID++
+	op.id = t.operations.lastID
+	if t.operations.active == nil {
+		t.operations.active = make(map[int64]*Operation)
+	}
+	t.operations.active[op.id] = op
+	t.operations.Unlock()
+
+	ctx = opentracing.ContextWithSpan(ctx, op.span)
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+// OperationFromContext returns the operation started with StartOperation
+// which ctx belongs to, or nil.
+func OperationFromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	return op
+}
+
+// SetProgress reports the fraction of the operation which is completed.
+func (op *Operation) SetProgress(fraction float64) {
+	if op == nil {
+		return
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	op.mu.Lock()
+	op.mu.progress = fraction
+	op.mu.Unlock()
+}
+
+// SetDetails sets a human-readable description of the current state of the
+// operation.
+func (op *Operation) SetDetails(details string) {
+	if op == nil {
+		return
+	}
+	op.mu.Lock()
+	op.mu.details = details
+	op.mu.Unlock()
+}
+
+// Finish unregisters the operation and finishes its span.
+func (op *Operation) Finish() {
+	if op == nil {
+		return
+	}
+	op.tracer.operations.Lock()
+	delete(op.tracer.operations.active, op.id)
+	op.tracer.operations.Unlock()
+	op.span.Finish()
+}
+
+// Operations returns the operations registered with the Tracer which are
+// still running, ordered by ID.
+func (t *Tracer) Operations() []OperationState {
+	t.operations.Lock()
+	ops := make([]*Operation, 0, len(t.operations.active))
+	for _, op := range t.operations.active {
+		ops = append(ops, op)
+	}
+	t.operations.Unlock()
+
+	states := make([]OperationState, len(ops))
+	for i, op := range ops {
+		states[i] = OperationState{
+			ID:        op.id,
+			Name:      op.name,
+			StartTime: op.startTime,
+		}
+		if sc, ok := op.span.Context().(*spanContext); ok {
+			states[i].TraceID, states[i].SpanID = sc.TraceID, sc.SpanID
+		}
+		op.mu.Lock()
+		states[i].Progress, states[i].Details = op.mu.progress, op.mu.details
+		op.mu.Unlock()
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
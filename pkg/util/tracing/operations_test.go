@@ -0,0 +1,67 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestOperations(t *testing.T) {
+	tr := NewTracer()
+	if ops := tr.Operations(); len(ops) != 0 {
+		t.Fatalf("unexpected operations: %+v", ops)
+	}
+
+	parent := tr.StartSpan("parent", Recordable)
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	ctx1, op1 := StartOperation(ctx, tr, "backfill")
+	_, op2 := StartOperation(context.Background(), tr, "gc")
+	if OperationFromContext(ctx1) != op1 || OperationFromContext(ctx) != nil {
+		t.Fatal("unexpected operation in context")
+	}
+	op1.SetProgress(0.5)
+	op1.SetDetails("2 of 4 ranges remaining")
+
+	ops := tr.Operations()
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 operations, got %+v", ops)
+	}
+	// The span of the operation is a child of the span of the context.
+	parentCtx := parent.Context().(*spanContext)
+	if o := ops[0]; o.Name != "backfill" || o.Progress != 0.5 ||
+		o.Details != "2 of 4 ranges remaining" || o.TraceID != parentCtx.TraceID ||
+		o.SpanID == parentCtx.SpanID || o.SpanID == 0 {
+		t.Errorf("unexpected operation: %+v", o)
+	}
+	// The span of an operation is real even if tracing is disabled.
+	if o := ops[1]; o.Name != "gc" || o.Progress >= 0 || o.SpanID == 0 {
+		t.Errorf("unexpected operation: %+v", o)
+	}
+
+	op1.Finish()
+	if ops := tr.Operations(); len(ops) != 1 || ops[0].Name != "gc" {
+		t.Fatalf("unexpected operations: %+v", ops)
+	}
+	op2.Finish()
+	parent.Finish()
+
+	// Operations aren't registered without a Tracer, and the nil operation
+	// can be used.
+	ctx, op := StartOperation(context.Background(), nil /* tracer */, "noop")
+	if op != nil || OperationFromContext(ctx) != nil {
+		t.Fatal("unexpected operation without a tracer")
+	}
+	op.SetProgress(1)
+	op.Finish()
+}
@@ -0,0 +1,587 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+)
+
+// This file implements a minimal opentracing.Tracer which is used as a shadow
+// tracer and exports finished spans to an OpenTelemetry collector using the
+// OTLP/HTTP protocol with JSON encoding. This allows CockroachDB traces to be
+// viewed alongside application traces in any OTLP-compatible backend (Jaeger,
+// Tempo, etc).
+
+const (
+	// otlpMaxBufferedSpans is the number of finished spans that can be waiting
+	// to be exported; spans finished while the buffer is full are dropped.
+	otlpMaxBufferedSpans = 10000
+	// otlpMaxBatchSize is the maximum number of spans sent in a single request.
+	otlpMaxBatchSize = 512
+	// otlpFlushInterval is how often partial batches are sent.
+	otlpFlushInterval = 5 * time.Second
+	// otlpRequestTimeout bounds each export request.
+	otlpRequestTimeout = 10 * time.Second
+
+	// otlpSpanKindInternal is SPAN_KIND_INTERNAL in the OTLP protocol.
+	otlpSpanKindInternal = 1
+
+	otlpServiceName = "cockroach"
+	otlpScopeName   = "github.com/cockroachdb/cockroach/pkg/util/tracing"
+
+	// Keys used to encode an otlpSpanContext in a TextMap carrier. They are
+	// lowercase because Tracer.Extract lowercases all keys.
+	otlpFieldTraceID = "traceid"
+	otlpFieldSpanID  = "spanid"
+	otlpFieldSampled = "sampled"
+)
+
+type otlpManager struct {
+	exporter *otlpExporter
+}
+
+func (*otlpManager) Name() string {
+	return "opentelemetry"
+}
+
+func (m *otlpManager) Close(tr opentracing.Tracer) {
+	m.exporter.close()
+}
+
+func createOTLPTracer(
+	collectorAddr string, sampleRate float64,
+) (shadowTracerManager, opentracing.Tracer) {
+	exporter := newOTLPExporter(otlpTracesURL(collectorAddr))
+	return &otlpManager{exporter: exporter}, &otlpTracer{
+		exporter:   exporter,
+		sampleRate: sampleRate,
+	}
+}
+
+// otlpTracesURL returns the OTLP/HTTP traces endpoint for the given collector
+// address, which can be either a host:port or a URL.
+func otlpTracesURL(collectorAddr string) string {
+	if !strings.Contains(collectorAddr, "://") {
+		collectorAddr = "http://" + collectorAddr
+	}
+	return strings.TrimSuffix(collectorAddr, "/") + "/v1/traces"
+}
+
+// otlpTracer is an opentracing.Tracer whose sampled spans are sent to an
+// otlpExporter when finished. The sampling decision is made when a root span
+// is created and is inherited by all its descendants, including remote ones.
+type otlpTracer struct {
+	exporter   *otlpExporter
+	sampleRate float64
+}
+
+var _ opentracing.Tracer = &otlpTracer{}
+
+type otlpSpanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+var _ opentracing.SpanContext = otlpSpanContext{}
+
+// ForeachBaggageItem is part of the opentracing.SpanContext interface.
+// Baggage is propagated by our own tracer, not by the shadow tracer.
+func (otlpSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
+
+func randomOTLPIDs() (traceID [16]byte, spanID [8]byte) {
+	binary.BigEndian.PutUint64(traceID[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(traceID[8:], rand.Uint64())
+	binary.BigEndian.PutUint64(spanID[:], rand.Uint64())
+	return traceID, spanID
+}
+
+// StartSpan is part of the opentracing.Tracer interface.
+func (t *otlpTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+
+	s := &otlpSpan{tracer: t}
+	s.ctx.traceID, s.ctx.spanID = randomOTLPIDs()
+	var hasParent bool
+	for _, r := range sso.References {
+		if parent, ok := r.ReferencedContext.(otlpSpanContext); ok {
+			s.ctx.traceID = parent.traceID
+			s.ctx.sampled = parent.sampled
+			s.parentSpanID = parent.spanID
+			hasParent = true
+			break
+		}
+	}
+	if !hasParent {
+		s.ctx.sampled = t.sampleRate > 0 && rand.Float64() < t.sampleRate
+	}
+	if !s.ctx.sampled {
+		// Unsampled spans only need to propagate their context.
+		return s
+	}
+
+	s.mu.name = operationName
+	s.mu.start = sso.StartTime
+	if s.mu.start.IsZero() {
+		s.mu.start = timeutil.Now()
+	}
+	for k, v := range sso.Tags {
+		s.setTagLocked(k, v)
+	}
+	return s
+}
+
+// Inject is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Inject(
+	osc opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	sc, ok := osc.(otlpSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return opentracing.ErrUnsupportedFormat
+	}
+	mapWriter, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	mapWriter.Set(otlpFieldTraceID, hex.EncodeToString(sc.traceID[:]))
+	mapWriter.Set(otlpFieldSpanID, hex.EncodeToString(sc.spanID[:]))
+	mapWriter.Set(otlpFieldSampled, strconv.FormatBool(sc.sampled))
+	return nil
+}
+
+// Extract is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	mapReader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	var sc otlpSpanContext
+	var found int
+	err := mapReader.ForeachKey(func(k, v string) error {
+		switch strings.ToLower(k) {
+		case otlpFieldTraceID:
+			if n, err := hex.Decode(sc.traceID[:], []byte(v)); err != nil || n != len(sc.traceID) {
+				return opentracing.ErrSpanContextCorrupted
+			}
+			found++
+		case otlpFieldSpanID:
+			if n, err := hex.Decode(sc.spanID[:], []byte(v)); err != nil || n != len(sc.spanID) {
+				return opentracing.ErrSpanContextCorrupted
+			}
+			found++
+		case otlpFieldSampled:
+			var err error
+			if sc.sampled, err = strconv.ParseBool(v); err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found != 2 {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return sc, nil
+}
+
+// otlpSpan is the opentracing.Span implementation of otlpTracer. Only sampled
+// spans record their name, tags and logs.
+type otlpSpan struct {
+	tracer       *otlpTracer
+	ctx          otlpSpanContext
+	parentSpanID [8]byte
+
+	mu struct {
+		syncutil.Mutex
+		name     string
+		start    time.Time
+		attrs    []otlpKeyValue
+		events   []otlpEvent
+		finished bool
+	}
+}
+
+var _ opentracing.Span = &otlpSpan{}
+
+// Finish is part of the opentracing.Span interface.
+func (s *otlpSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions is part of the opentracing.Span interface.
+func (s *otlpSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	if !s.ctx.sampled {
+		return
+	}
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = timeutil.Now()
+	}
+
+	s.mu.Lock()
+	if s.mu.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.finished = true
+	for _, lr := range opts.LogRecords {
+		s.logFieldsLocked(lr.Timestamp, lr.Fields...)
+	}
+	exported := otlpJSONSpan{
+		TraceID:           hex.EncodeToString(s.ctx.traceID[:]),
+		SpanID:            hex.EncodeToString(s.ctx.spanID[:]),
+		Name:              s.mu.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: otlpTimestamp(s.mu.start),
+		EndTimeUnixNano:   otlpTimestamp(finishTime),
+		Attributes:        s.mu.attrs,
+		Events:            s.mu.events,
+	}
+	s.mu.Unlock()
+
+	if s.parentSpanID != ([8]byte{}) {
+		exported.ParentSpanID = hex.EncodeToString(s.parentSpanID[:])
+	}
+	s.tracer.exporter.enqueue(exported)
+}
+
+// Context is part of the opentracing.Span interface.
+func (s *otlpSpan) Context() opentracing.SpanContext {
+	return s.ctx
+}
+
+// SetOperationName is part of the opentracing.Span interface.
+func (s *otlpSpan) SetOperationName(operationName string) opentracing.Span {
+	if s.ctx.sampled {
+		s.mu.Lock()
+		s.mu.name = operationName
+		s.mu.Unlock()
+	}
+	return s
+}
+
+// SetTag is part of the opentracing.Span interface.
+func (s *otlpSpan) SetTag(key string, value interface{}) opentracing.Span {
+	if s.ctx.sampled {
+		s.mu.Lock()
+		s.setTagLocked(key, value)
+		s.mu.Unlock()
+	}
+	return s
+}
+
+func (s *otlpSpan) setTagLocked(key string, value interface{}) {
+	for i := range s.mu.attrs {
+		if s.mu.attrs[i].Key == key {
+			s.mu.attrs[i].Value = otlpStringValue(value)
+			return
+		}
+	}
+	s.mu.attrs = append(s.mu.attrs, otlpKeyValue{Key: key, Value: otlpStringValue(value)})
+}
+
+// LogFields is part of the opentracing.Span interface.
+func (s *otlpSpan) LogFields(fields ...otlog.Field) {
+	if !s.ctx.sampled {
+		return
+	}
+	s.mu.Lock()
+	s.logFieldsLocked(timeutil.Now(), fields...)
+	s.mu.Unlock()
+}
+
+func (s *otlpSpan) logFieldsLocked(t time.Time, fields ...otlog.Field) {
+	if len(s.mu.events) >= maxLogsPerSpan {
+		return
+	}
+	if t.IsZero() {
+		t = timeutil.Now()
+	}
+	ev := otlpEvent{TimeUnixNano: otlpTimestamp(t), Name: "log"}
+	for _, f := range fields {
+		if f.Key() == "event" {
+			ev.Name = fmt.Sprint(f.Value())
+			continue
+		}
+		ev.Attributes = append(ev.Attributes, otlpKeyValue{
+			Key:   f.Key(),
+			Value: otlpStringValue(f.Value()),
+		})
+	}
+	s.mu.events = append(s.mu.events, ev)
+}
+
+// LogKV is part of the opentracing.Span interface.
+func (s *otlpSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	return s
+}
+
+// BaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) BaggageItem(restrictedKey string) string {
+	return ""
+}
+
+// Tracer is part of the opentracing.Span interface.
+func (s *otlpSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEvent(event string) {
+	s.LogFields(otlog.String("event", event))
+}
+
+// LogEventWithPayload is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String("event", event), otlog.Object("payload", payload))
+}
+
+// Log is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) Log(data opentracing.LogData) {
+	if !s.ctx.sampled {
+		return
+	}
+	s.mu.Lock()
+	s.logFieldsLocked(data.Timestamp, data.ToLogRecord().Fields...)
+	s.mu.Unlock()
+}
+
+// The types below mirror the JSON encoding of the OTLP
+// ExportTraceServiceRequest message. IDs are hex-encoded and 64-bit integers
+// are encoded as strings, as required by the OTLP/HTTP JSON specification.
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope      `json:"scope"`
+	Spans []otlpJSONSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpJSONSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func otlpStringValue(v interface{}) otlpAnyValue {
+	return otlpAnyValue{StringValue: fmt.Sprint(v)}
+}
+
+func otlpTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+var otlpLogEveryN = util.Every(5 * time.Second)
+
+// otlpLogf reports exporter errors. We can't use `log` from this package so
+// they are printed (rate limited) to stderr.
+func otlpLogf(format string, args ...interface{}) {
+	if otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+		fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: "+format+"\n", args...)
+	}
+}
+
+// otlpExporter batches finished spans and sends them to the collector from a
+// background goroutine.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+
+	spanCh  chan otlpJSONSpan
+	stopper chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newOTLPExporter(url string) *otlpExporter {
+	e := &otlpExporter{
+		url:     url,
+		client:  &http.Client{Timeout: otlpRequestTimeout},
+		spanCh:  make(chan otlpJSONSpan, otlpMaxBufferedSpans),
+		stopper: make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// enqueue schedules a span for export; the span is dropped if the buffer is
+// full.
+func (e *otlpExporter) enqueue(s otlpJSONSpan) {
+	select {
+	case e.spanCh <- s:
+	default:
+		otlpLogf("buffer full, dropping spans")
+	}
+}
+
+// close stops the exporter after flushing all buffered spans.
+func (e *otlpExporter) close() {
+	close(e.stopper)
+	e.wg.Wait()
+}
+
+func (e *otlpExporter) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpJSONSpan, 0, otlpMaxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			otlpLogf("dropped %d spans: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case s := <-e.spanCh:
+			batch = append(batch, s)
+			if len(batch) >= otlpMaxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stopper:
+			for {
+				select {
+				case s := <-e.spanCh:
+					batch = append(batch, s)
+					if len(batch) >= otlpMaxBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *otlpExporter) export(spans []otlpJSONSpan) error {
+	req := otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{
+					{Key: "service.name", Value: otlpStringValue(otlpServiceName)},
+				},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: otlpScopeName},
+				Spans: spans,
+			}},
+		}},
+	}
+	body, err := json.Marshal(&req)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), otlpRequestTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// otlpTestCollector is an OTLP/HTTP endpoint which records the spans it
+// receives.
+type otlpTestCollector struct {
+	*httptest.Server
+	mu struct {
+		syncutil.Mutex
+		spans []otlpJSONSpan
+	}
+}
+
+func newOTLPTestCollector(t *testing.T) *otlpTestCollector {
+	c := &otlpTestCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req otlpExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.mu.spans = append(c.mu.spans, ss.Spans...)
+			}
+		}
+	}))
+	return c
+}
+
+func (c *otlpTestCollector) spans() []otlpJSONSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]otlpJSONSpan(nil), c.mu.spans...)
+}
+
+func TestOTLPExport(t *testing.T) {
+	collector := newOTLPTestCollector(t)
+	defer collector.Close()
+
+	tr := NewTracer()
+	tr.setShadowTracer(createOTLPTracer(collector.URL, 1 /* sampleRate */))
+
+	s := tr.StartSpan("parent")
+	s.SetTag("tag", "val")
+	s.LogKV("event", "hello")
+
+	// Propagate the context the way it would be propagated to another node.
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	if err := tr.Inject(s.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := tr.StartSpan("child", opentracing.FollowsFrom(wireContext))
+	s2.Finish()
+	s.Finish()
+
+	// Closing the shadow tracer flushes the exporter.
+	tr.setShadowTracer(nil, nil)
+
+	spans := collector.spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %+v", spans)
+	}
+	child, parent := spans[0], spans[1]
+	if child.Name != "child" || parent.Name != "parent" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+	if len(parent.TraceID) != 32 || len(parent.SpanID) != 16 {
+		t.Errorf("invalid IDs in %+v", parent)
+	}
+	if child.TraceID != parent.TraceID {
+		t.Errorf("expected trace ID %s, got %s", parent.TraceID, child.TraceID)
+	}
+	if child.ParentSpanID != parent.SpanID {
+		t.Errorf("expected parent span ID %s, got %s", parent.SpanID, child.ParentSpanID)
+	}
+	if parent.ParentSpanID != "" {
+		t.Errorf("expected root span, got parent %s", parent.ParentSpanID)
+	}
+	var foundTag bool
+	for _, kv := range parent.Attributes {
+		if kv.Key == "tag" && kv.Value.StringValue == "val" {
+			foundTag = true
+		}
+	}
+	if !foundTag {
+		t.Errorf("expected tag in %+v", parent.Attributes)
+	}
+	if len(parent.Events) != 1 || parent.Events[0].Name != "hello" {
+		t.Errorf("expected event hello, got %+v", parent.Events)
+	}
+}
+
+func TestOTLPSampling(t *testing.T) {
+	collector := newOTLPTestCollector(t)
+	defer collector.Close()
+
+	tr := NewTracer()
+	tr.setShadowTracer(createOTLPTracer(collector.URL, 0 /* sampleRate */))
+
+	s := tr.StartSpan("parent")
+	s2 := tr.StartSpan("child", opentracing.ChildOf(s.Context()))
+	s2.Finish()
+	s.Finish()
+	tr.setShadowTracer(nil, nil)
+
+	if spans := collector.spans(); len(spans) != 0 {
+		t.Fatalf("expected no spans, got %+v", spans)
+	}
+}
+
+func TestOTLPTracesURL(t *testing.T) {
+	for _, tc := range []struct {
+		addr, expected string
+	}{
+		{"127.0.0.1:4318", "http://127.0.0.1:4318/v1/traces"},
+		{"http://collector:4318/", "http://collector:4318/v1/traces"},
+		{"https://collector", "https://collector/v1/traces"},
+	} {
+		if url := otlpTracesURL(tc.addr); url != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.addr, tc.expected, url)
+		}
+	}
+}
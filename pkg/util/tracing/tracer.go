@@ -74,6 +74,24 @@ var zipkinCollector = settings.RegisterStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 )
 
+var otlpCollector = settings.RegisterStringSetting(
+	"trace.opentelemetry.collector",
+	"if set, traces go to the given OpenTelemetry collector using OTLP/HTTP (example: '127.0.0.1:4318'); ignored if trace.lightstep.token or trace.zipkin.collector is set",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_OTLP_COLLECTOR", ""),
+)
+
+var otlpSampleRate = settings.RegisterValidatedFloatSetting(
+	"trace.opentelemetry.sample_rate",
+	"fraction of traces sent to the collector configured by trace.opentelemetry.collector",
+	1,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("sample rate must be between 0 and 1, got %f", v)
+		}
+		return nil
+	},
+)
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -82,16 +100,15 @@ var zipkinCollector = settings.RegisterStringSetting(
 //    the Snowball baggage and can be started explicitly as well. Recorded
 //    events can be retrieved at any time.
 //
-//  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
-//    span inside each of our spans.
+//  - lightstep, zipkin or OpenTelemetry traces. This is implemented by
+//    maintaining a "shadow" span inside each of our spans.
 //
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (snowball
 // tracing needs to work in all cases).
 //
-// Tracer is currently stateless so we could have a single instance; however,
-// this won't be the case if the cluster settings move away from using global
-// state.
+// The only state of the Tracer, besides its configuration, is the registry of
+// the long-running operations of the node (see StartOperation).
 type Tracer struct {
 	// Preallocated noopSpan, used to avoid creating spans when we are not using
 	// x/net/trace or lightstep and we are not recording.
@@ -108,6 +125,10 @@ type Tracer struct {
 
 	// Pointer to shadowTracer, if using one.
 	shadowTracer unsafe.Pointer
+
+	// operations are the long-running operations registered with
+	// StartOperation.
+	operations operations
 }
 
 var _ opentracing.Tracer = &Tracer{}
@@ -129,6 +150,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if otlpAddr := otlpCollector.Get(sv); otlpAddr != "" {
+			t.setShadowTracer(createOTLPTracer(otlpAddr, otlpSampleRate.Get(sv)))
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -144,6 +167,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	otlpCollector.SetOnChange(sv, reconfigure)
+	otlpSampleRate.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
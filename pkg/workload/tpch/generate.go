@@ -0,0 +1,440 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tpch
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"golang.org/x/exp/rand"
+)
+
+// The values below are set by the TPC-H spec (section 4.2) - they're not
+// knobs. The generated data follows the distributions of the spec, but the
+// text fields use a simplified grammar, so the data is not identical to the
+// output of the official dbgen tool.
+
+// These are the dates between which orders are placed, and the date that
+// determines whether an order has shipped or been returned. They're expressed
+// in days since startDate.
+var (
+	startDate   = time.Date(1992, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate     = daysSinceStart(time.Date(1998, 12, 31, 0, 0, 0, 0, time.UTC))
+	currentDate = daysSinceStart(time.Date(1995, 6, 17, 0, 0, 0, 0, time.UTC))
+)
+
+const (
+	minLineItemsPerOrder = 1
+	maxLineItemsPerOrder = 7
+	// numClerksPerSF is the number of distinct o_clerk values per scale
+	// factor.
+	numClerksPerSF = 1000
+)
+
+var regionNames = []string{`AFRICA`, `AMERICA`, `ASIA`, `EUROPE`, `MIDDLE EAST`}
+
+var nations = []struct {
+	name      string
+	regionKey int
+}{
+	{`ALGERIA`, 0}, {`ARGENTINA`, 1}, {`BRAZIL`, 1}, {`CANADA`, 1}, {`EGYPT`, 4},
+	{`ETHIOPIA`, 0}, {`FRANCE`, 3}, {`GERMANY`, 3}, {`INDIA`, 2}, {`INDONESIA`, 2},
+	{`IRAN`, 4}, {`IRAQ`, 4}, {`JAPAN`, 2}, {`JORDAN`, 4}, {`KENYA`, 0},
+	{`MOROCCO`, 0}, {`MOZAMBIQUE`, 0}, {`PERU`, 1}, {`CHINA`, 2}, {`ROMANIA`, 3},
+	{`SAUDI ARABIA`, 4}, {`VIETNAM`, 2}, {`RUSSIA`, 3}, {`UNITED KINGDOM`, 3},
+	{`UNITED STATES`, 1},
+}
+
+var partNameWords = []string{
+	`almond`, `antique`, `aquamarine`, `azure`, `beige`, `bisque`, `black`,
+	`blanched`, `blue`, `blush`, `brown`, `burlywood`, `burnished`, `chartreuse`,
+	`chiffon`, `chocolate`, `coral`, `cornflower`, `cornsilk`, `cream`, `cyan`,
+	`dark`, `deep`, `dim`, `dodger`, `drab`, `firebrick`, `floral`, `forest`,
+	`frosted`, `gainsboro`, `ghost`, `goldenrod`, `green`, `grey`, `honeydew`,
+	`hot`, `indian`, `ivory`, `khaki`, `lace`, `lavender`, `lawn`, `lemon`,
+	`light`, `lime`, `linen`, `magenta`, `maroon`, `medium`, `metallic`,
+	`midnight`, `mint`, `misty`, `moccasin`, `navajo`, `navy`, `olive`, `orange`,
+	`orchid`, `pale`, `papaya`, `peach`, `peru`, `pink`, `plum`, `powder`, `puff`,
+	`purple`, `red`, `rose`, `rosy`, `royal`, `saddle`, `salmon`, `sandy`,
+	`seashell`, `sienna`, `sky`, `slate`, `smoke`, `snow`, `spring`, `steel`,
+	`tan`, `thistle`, `tomato`, `turquoise`, `violet`, `wheat`, `white`, `yellow`,
+}
+
+var (
+	partTypeSyllables1  = []string{`STANDARD`, `SMALL`, `MEDIUM`, `LARGE`, `ECONOMY`, `PROMO`}
+	partTypeSyllables2  = []string{`ANODIZED`, `BURNISHED`, `PLATED`, `POLISHED`, `BRUSHED`}
+	partTypeSyllables3  = []string{`TIN`, `NICKEL`, `BRASS`, `STEEL`, `COPPER`}
+	containerSyllables1 = []string{`SM`, `LG`, `MED`, `JUMBO`, `WRAP`}
+	containerSyllables2 = []string{`CASE`, `BOX`, `BAG`, `JAR`, `PKG`, `PACK`, `CAN`, `DRUM`}
+	marketSegments      = []string{`AUTOMOBILE`, `BUILDING`, `FURNITURE`, `MACHINERY`, `HOUSEHOLD`}
+	orderPriorities     = []string{`1-URGENT`, `2-HIGH`, `3-MEDIUM`, `4-NOT SPECIFIED`, `5-LOW`}
+	shipInstructions    = []string{`DELIVER IN PERSON`, `COLLECT COD`, `NONE`, `TAKE BACK RETURN`}
+	shipModes           = []string{`REG AIR`, `AIR`, `RAIL`, `SHIP`, `TRUCK`, `MAIL`, `FOB`}
+)
+
+// commentWords is the vocabulary of the comment columns.
+var commentWords = []string{
+	`foxes`, `ideas`, `theodolites`, `pinto`, `beans`, `instructions`, `dependencies`,
+	`excuses`, `platelets`, `asymptotes`, `courts`, `dolphins`, `multipliers`,
+	`sauternes`, `warthogs`, `frets`, `dinos`, `attainments`, `somas`, `packages`,
+	`requests`, `accounts`, `deposits`, `pearls`, `braids`, `sheaves`, `furiously`,
+	`sometimes`, `carefully`, `slyly`, `quickly`, `fluffily`, `blithely`, `boldly`,
+	`regular`, `final`, `express`, `special`, `pending`, `ironic`, `even`, `silent`,
+	`unusual`, `bold`, `sleep`, `wake`, `are`, `cajole`, `haggle`, `nag`, `use`,
+	`boost`, `affix`, `detect`, `integrate`, `maintain`, `nod`, `was`, `lose`,
+	`sublate`, `solve`, `thrash`, `promise`, `engage`, `hinder`, `print`, `above`,
+	`against`, `along`, `among`, `around`, `beside`, `across`, `after`,
+}
+
+// Each table uses a distinct seed offset, so that the rows of different
+// tables with the same index don't use the same random data. The orders and
+// lineitem tables share one, since the columns of an order are derived from its
+// line items.
+const (
+	nationSeedOffset = iota
+	regionSeedOffset
+	partSeedOffset
+	supplierSeedOffset
+	partSuppSeedOffset
+	customerSeedOffset
+	orderSeedOffset
+	numSeedOffsets
+)
+
+type generateLocals struct {
+	rng *rand.Rand
+}
+
+// rngFor returns the locals for generating the given row of a table, with
+// their random number generator seeded deterministically.
+func (w *tpch) rngFor(seedOffset int, rowIdx int) *generateLocals {
+	l := w.localsPool.Get().(*generateLocals)
+	l.rng.Seed(uint64(w.seed) + uint64(rowIdx)*numSeedOffsets + uint64(seedOffset))
+	return l
+}
+
+func randInt(rng *rand.Rand, min, max int) int {
+	return rng.Intn(max-min+1) + min
+}
+
+// randFloat returns a random number with two decimal places in [min, max].
+func randFloat(rng *rand.Rand, min, max float64) float64 {
+	return float64(randInt(rng, int(math.Round(min*100)), int(math.Round(max*100)))) / 100
+}
+
+func randChoice(rng *rand.Rand, s []string) string {
+	return s[rng.Intn(len(s))]
+}
+
+// randText returns a random sentence of commentWords whose length is between
+// minLen and maxLen.
+func randText(rng *rand.Rand, minLen, maxLen int) string {
+	n := randInt(rng, minLen, maxLen)
+	var b strings.Builder
+	for b.Len() < n {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(randChoice(rng, commentWords))
+	}
+	return b.String()[:n]
+}
+
+// randVString returns a random alphanumeric string whose length is between
+// minLen and maxLen.
+func randVString(rng *rand.Rand, minLen, maxLen int) string {
+	const alphabet = `abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789,. `
+	b := make([]byte, randInt(rng, minLen, maxLen))
+	for i := range b {
+		b[i] = alphabet[rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// randPhone returns a phone number whose country code is derived from the
+// nation key.
+func randPhone(rng *rand.Rand, nationKey int) string {
+	return fmt.Sprintf(`%02d-%03d-%03d-%04d`, nationKey+10,
+		randInt(rng, 100, 999), randInt(rng, 100, 999), randInt(rng, 1000, 9999))
+}
+
+func daysSinceStart(t time.Time) int {
+	return int(t.Sub(startDate).Hours() / 24)
+}
+
+func dateString(days int) string {
+	return startDate.AddDate(0, 0, days).Format(`2006-01-02`)
+}
+
+// retailPrice returns the p_retailprice of a part, which the spec defines as
+// a function of its key.
+func retailPrice(partKey int) float64 {
+	return float64(90000+((partKey/10)%20001)+100*(partKey%1000)) / 100
+}
+
+// partSuppKey returns the key of the i'th of the 4 suppliers of a part.
+func partSuppKey(partKey, i, numSuppliers int) int {
+	return (partKey+(i*((numSuppliers/4)+(partKey-1)/numSuppliers)))%numSuppliers + 1
+}
+
+// orderKey returns the key of the order with the given index. Order keys are
+// sparse: only the first 8 of every 32 keys are used.
+func orderKey(orderIdx int) int {
+	return (orderIdx/8)*32 + orderIdx%8 + 1
+}
+
+func (w *tpch) tpchNationInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(nationSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	return []interface{}{
+		rowIdx,                    // n_nationkey
+		nations[rowIdx].name,      // n_name
+		nations[rowIdx].regionKey, // n_regionkey
+		randText(l.rng, 31, 114),  // n_comment
+	}
+}
+
+func (w *tpch) tpchRegionInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(regionSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	return []interface{}{
+		rowIdx,                   // r_regionkey
+		regionNames[rowIdx],      // r_name
+		randText(l.rng, 31, 115), // r_comment
+	}
+}
+
+func (w *tpch) tpchPartInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(partSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	partKey := rowIdx + 1
+
+	// The name is made of 5 distinct words.
+	nameIdxs := l.rng.Perm(len(partNameWords))[:5]
+	nameWords := make([]string, len(nameIdxs))
+	for i, idx := range nameIdxs {
+		nameWords[i] = partNameWords[idx]
+	}
+	m := randInt(l.rng, 1, 5)
+	n := randInt(l.rng, 1, 5)
+	return []interface{}{
+		partKey,                           // p_partkey
+		strings.Join(nameWords, ` `),      // p_name
+		fmt.Sprintf(`Manufacturer#%d`, m), // p_mfgr
+		fmt.Sprintf(`Brand#%d%d`, m, n),   // p_brand
+		randChoice(l.rng, partTypeSyllables1) + ` ` +
+			randChoice(l.rng, partTypeSyllables2) + ` ` +
+			randChoice(l.rng, partTypeSyllables3), // p_type
+		randInt(l.rng, 1, 50), // p_size
+		randChoice(l.rng, containerSyllables1) + ` ` +
+			randChoice(l.rng, containerSyllables2), // p_container
+		retailPrice(partKey),   // p_retailprice
+		randText(l.rng, 5, 22), // p_comment
+	}
+}
+
+func (w *tpch) tpchSupplierInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(supplierSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	suppKey := rowIdx + 1
+	nationKey := randInt(l.rng, 0, numNation-1)
+	return []interface{}{
+		suppKey,                               // s_suppkey
+		fmt.Sprintf(`Supplier#%09d`, suppKey), // s_name
+		randVString(l.rng, 10, 40),            // s_address
+		nationKey,                             // s_nationkey
+		randPhone(l.rng, nationKey),           // s_phone
+		randFloat(l.rng, -999.99, 9999.99),    // s_acctbal
+		randText(l.rng, 25, 100),              // s_comment
+	}
+}
+
+func (w *tpch) tpchPartSuppInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(partSuppSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	// There are 4 rows per part.
+	partKey := rowIdx/4 + 1
+	return []interface{}{
+		partKey, // ps_partkey
+		partSuppKey(partKey, rowIdx%4, numSupplierPerSF*w.scaleFactor), // ps_suppkey
+		randInt(l.rng, 1, 9999),         // ps_availqty
+		randFloat(l.rng, 1.00, 1000.00), // ps_supplycost
+		randText(l.rng, 49, 198),        // ps_comment
+	}
+}
+
+func (w *tpch) tpchCustomerInitialRow(rowIdx int) []interface{} {
+	l := w.rngFor(customerSeedOffset, rowIdx)
+	defer w.localsPool.Put(l)
+	custKey := rowIdx + 1
+	nationKey := randInt(l.rng, 0, numNation-1)
+	return []interface{}{
+		custKey,                               // c_custkey
+		fmt.Sprintf(`Customer#%09d`, custKey), // c_name
+		randVString(l.rng, 10, 40),            // c_address
+		nationKey,                             // c_nationkey
+		randPhone(l.rng, nationKey),           // c_phone
+		randFloat(l.rng, -999.99, 9999.99),    // c_acctbal
+		randChoice(l.rng, marketSegments),     // c_mktsegment
+		randText(l.rng, 29, 116),              // c_comment
+	}
+}
+
+type lineItem struct {
+	partKey, suppKey                  int
+	quantity                          int
+	extendedPrice, discount, tax      float64
+	returnFlag, lineStatus            string
+	shipDate, commitDate, receiptDate int
+	shipInstruct, shipMode, comment   string
+}
+
+type order struct {
+	custKey    int
+	status     string
+	totalPrice float64
+	orderDate  int
+	priority   string
+	clerk      string
+	comment    string
+	lineItems  []lineItem
+}
+
+// generateOrder returns the order with the given index, along with its line
+// items. Both the orders and the lineitem tables are generated from it, so
+// that the columns of an order that depend on its line items are consistent
+// with them.
+func (w *tpch) generateOrder(orderIdx int) order {
+	l := w.rngFor(orderSeedOffset, orderIdx)
+	defer w.localsPool.Put(l)
+	rng := l.rng
+
+	numParts := numPartPerSF * w.scaleFactor
+	numSuppliers := numSupplierPerSF * w.scaleFactor
+	numCustomers := numCustomerPerSF * w.scaleFactor
+
+	var o order
+	// Customers whose key is a multiple of 3 don't place orders.
+	o.custKey = randInt(rng, 1, numCustomers)
+	for o.custKey%3 == 0 {
+		o.custKey = randInt(rng, 1, numCustomers)
+	}
+	o.orderDate = randInt(rng, 0, endDate-151)
+	o.priority = randChoice(rng, orderPriorities)
+	o.clerk = fmt.Sprintf(`Clerk#%09d`, randInt(rng, 1, numClerksPerSF*w.scaleFactor))
+	o.comment = randText(rng, 19, 78)
+
+	o.lineItems = make([]lineItem, randInt(rng, minLineItemsPerOrder, maxLineItemsPerOrder))
+	numShipped := 0
+	for i := range o.lineItems {
+		li := &o.lineItems[i]
+		li.partKey = randInt(rng, 1, numParts)
+		li.suppKey = partSuppKey(li.partKey, rng.Intn(4), numSuppliers)
+		li.quantity = randInt(rng, 1, 50)
+		li.extendedPrice = float64(li.quantity) * retailPrice(li.partKey)
+		li.discount = randFloat(rng, 0.00, 0.10)
+		li.tax = randFloat(rng, 0.00, 0.08)
+		li.shipDate = o.orderDate + randInt(rng, 1, 121)
+		li.commitDate = o.orderDate + randInt(rng, 30, 90)
+		li.receiptDate = li.shipDate + randInt(rng, 1, 30)
+		if li.receiptDate <= currentDate {
+			li.returnFlag = randChoice(rng, []string{`R`, `A`})
+		} else {
+			li.returnFlag = `N`
+		}
+		if li.shipDate > currentDate {
+			li.lineStatus = `O`
+		} else {
+			li.lineStatus = `F`
+			numShipped++
+		}
+		li.shipInstruct = randChoice(rng, shipInstructions)
+		li.shipMode = randChoice(rng, shipModes)
+		li.comment = randText(rng, 10, 43)
+
+		o.totalPrice += li.extendedPrice * (1 + li.tax) * (1 - li.discount)
+	}
+	switch numShipped {
+	case 0:
+		o.status = `O`
+	case len(o.lineItems):
+		o.status = `F`
+	default:
+		o.status = `P`
+	}
+	return o
+}
+
+func (w *tpch) tpchOrdersInitialRow(rowIdx int) []interface{} {
+	o := w.generateOrder(rowIdx)
+	return []interface{}{
+		orderKey(rowIdx),                     // o_orderkey
+		o.custKey,                            // o_custkey
+		o.status,                             // o_orderstatus
+		float64(int(o.totalPrice*100)) / 100, // o_totalprice
+		dateString(o.orderDate),              // o_orderdate
+		o.priority,                           // o_orderpriority
+		o.clerk,                              // o_clerk
+		0,                                    // o_shippriority
+		o.comment,                            // o_comment
+	}
+}
+
+var lineItemColTypes = []types.T{
+	types.Int64,   // l_orderkey
+	types.Int64,   // l_partkey
+	types.Int64,   // l_suppkey
+	types.Int64,   // l_linenumber
+	types.Float64, // l_quantity
+	types.Float64, // l_extendedprice
+	types.Float64, // l_discount
+	types.Float64, // l_tax
+	types.Bytes,   // l_returnflag
+	types.Bytes,   // l_linestatus
+	types.Bytes,   // l_shipdate
+	types.Bytes,   // l_commitdate
+	types.Bytes,   // l_receiptdate
+	types.Bytes,   // l_shipinstruct
+	types.Bytes,   // l_shipmode
+	types.Bytes,   // l_comment
+}
+
+// tpchLineItemInitialRowBatch fills the batch with the line items of the order
+// with the given index. There is one batch of lineitem rows per order.
+func (w *tpch) tpchLineItemInitialRowBatch(
+	orderIdx int, cb coldata.Batch, _ *bufalloc.ByteAllocator,
+) {
+	o := w.generateOrder(orderIdx)
+	cb.Reset(lineItemColTypes, len(o.lineItems))
+	for i, li := range o.lineItems {
+		cb.ColVec(0).Int64()[i] = int64(orderKey(orderIdx))
+		cb.ColVec(1).Int64()[i] = int64(li.partKey)
+		cb.ColVec(2).Int64()[i] = int64(li.suppKey)
+		cb.ColVec(3).Int64()[i] = int64(i + 1)
+		cb.ColVec(4).Float64()[i] = float64(li.quantity)
+		cb.ColVec(5).Float64()[i] = li.extendedPrice
+		cb.ColVec(6).Float64()[i] = li.discount
+		cb.ColVec(7).Float64()[i] = li.tax
+		cb.ColVec(8).Bytes().Set(i, []byte(li.returnFlag))
+		cb.ColVec(9).Bytes().Set(i, []byte(li.lineStatus))
+		cb.ColVec(10).Bytes().Set(i, []byte(dateString(li.shipDate)))
+		cb.ColVec(11).Bytes().Set(i, []byte(dateString(li.commitDate)))
+		cb.ColVec(12).Bytes().Set(i, []byte(dateString(li.receiptDate)))
+		cb.ColVec(13).Bytes().Set(i, []byte(li.shipInstruct))
+		cb.ColVec(14).Bytes().Set(i, []byte(li.shipMode))
+		cb.ColVec(15).Bytes().Set(i, []byte(li.comment))
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tpch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/workload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 33, 34}
+	for i, e := range expected {
+		if k := orderKey(i); k != e {
+			t.Errorf("%d: expected %d, got %d", i, e, k)
+		}
+	}
+}
+
+func TestPartSuppKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numSuppliers = numSupplierPerSF
+	for _, partKey := range []int{1, 2, 1000, numPartPerSF} {
+		seen := make(map[int]bool)
+		for i := 0; i < 4; i++ {
+			k := partSuppKey(partKey, i, numSuppliers)
+			if k < 1 || k > numSuppliers {
+				t.Errorf("part %d: supplier %d out of range", partKey, k)
+			}
+			if seen[k] {
+				t.Errorf("part %d: duplicate supplier %d", partKey, k)
+			}
+			seen[k] = true
+		}
+	}
+}
+
+func TestOrdersConsistentWithLineItems(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	w := tpchMeta.New().(*tpch)
+	var orders, lineItems workload.Table
+	for _, table := range w.Tables() {
+		switch table.Name {
+		case `orders`:
+			orders = table
+		case `lineitem`:
+			lineItems = table
+		}
+	}
+
+	for orderIdx := 0; orderIdx < 100; orderIdx++ {
+		order := orders.InitialRows.BatchRows(orderIdx)[0]
+		lines := lineItems.InitialRows.BatchRows(orderIdx)
+		if n := len(lines); n < minLineItemsPerOrder || n > maxLineItemsPerOrder {
+			t.Fatalf("order %d: unexpected number of line items %d", orderIdx, n)
+		}
+
+		var totalPrice float64
+		numShipped := 0
+		for _, line := range lines {
+			if line[0] != order[0] {
+				t.Fatalf("order %d: line item has order key %v, expected %v",
+					orderIdx, line[0], order[0])
+			}
+			extendedPrice, discount, tax := line[5].(float64), line[6].(float64), line[7].(float64)
+			totalPrice += extendedPrice * (1 + tax) * (1 - discount)
+			if string(line[9].([]byte)) == `F` {
+				numShipped++
+			}
+		}
+		if math.Abs(order[3].(float64)-totalPrice) > 0.01 {
+			t.Errorf("order %d: total price %v, expected %v", orderIdx, order[3], totalPrice)
+		}
+		expectedStatus := `P`
+		if numShipped == 0 {
+			expectedStatus = `O`
+		} else if numShipped == len(lines) {
+			expectedStatus = `F`
+		}
+		if status := string(order[2].([]byte)); status != expectedStatus {
+			t.Errorf("order %d: status %s, expected %s", orderIdx, status, expectedStatus)
+		}
+	}
+}
+
+// TestInitialDataDeterministic verifies that each batch of initial data only
+// depends on its index, so that batches generated in any order and by
+// different generators are identical.
+func TestInitialDataDeterministic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const maxBatches = 50
+	forward, reverse := tpchMeta.New().Tables(), tpchMeta.New().Tables()
+	for i, table := range forward {
+		t.Run(table.Name, func(t *testing.T) {
+			numBatches := table.InitialRows.NumBatches
+			if numBatches > maxBatches {
+				numBatches = maxBatches
+			}
+			expected := make([][][]interface{}, numBatches)
+			for batchIdx := 0; batchIdx < numBatches; batchIdx++ {
+				expected[batchIdx] = table.InitialRows.BatchRows(batchIdx)
+			}
+			for batchIdx := numBatches - 1; batchIdx >= 0; batchIdx-- {
+				require.Equal(t, expected[batchIdx], reverse[i].InitialRows.BatchRows(batchIdx),
+					"batch %d", batchIdx)
+			}
+		})
+	}
+}
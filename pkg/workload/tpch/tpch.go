@@ -15,6 +15,7 @@ import (
 	gosql "database/sql"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -22,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/workload/histogram"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/rand"
 )
 
 const (
@@ -47,6 +49,8 @@ type tpch struct {
 
 	queriesRaw      string
 	selectedQueries []string
+
+	localsPool sync.Pool
 }
 
 func init() {
@@ -73,8 +77,12 @@ var tpchMeta = workload.Meta{
 		g.flags.BoolVar(&g.distsql, `dist-sql`, true, `Use DistSQL for query execution`)
 		g.flags.BoolVar(&g.disableChecks, `disable-checks`, false,
 			"Disable checking the output against the expected rows (default false). "+
-				"Note that the checks are only supported for scale factor 1")
+				"Note that the checks are only supported for scale factor 1, with the data "+
+				"loaded from the dbgen fixtures (see import-sf1.sql)")
 		g.connFlags = workload.NewConnFlags(&g.flags)
+		g.localsPool.New = func() interface{} {
+			return &generateLocals{rng: rand.New(new(rand.PCGSource))}
+		}
 		return g
 	},
 }
@@ -89,6 +97,9 @@ func (w *tpch) Flags() workload.Flags { return w.flags }
 func (w *tpch) Hooks() workload.Hooks {
 	return workload.Hooks{
 		Validate: func() error {
+			if w.scaleFactor < 1 {
+				return errors.Errorf(`--scale-factor must be at least 1: %d`, w.scaleFactor)
+			}
 			if w.scaleFactor != 1 {
 				fmt.Printf("check for expected rows is only supported with " +
 					"scale factor 1, so it was disabled\n")
@@ -109,46 +120,48 @@ func (w *tpch) Hooks() workload.Hooks {
 
 // Tables implements the Generator interface.
 func (w *tpch) Tables() []workload.Table {
-	// TODO(dan): Implement the InitialRowFns for these.
 	nation := workload.Table{
 		Name:        `nation`,
 		Schema:      tpchNationSchema,
-		InitialRows: workload.Tuples(numNation, nil),
+		InitialRows: workload.Tuples(numNation, w.tpchNationInitialRow),
 	}
 	region := workload.Table{
 		Name:        `region`,
 		Schema:      tpchRegionSchema,
-		InitialRows: workload.Tuples(numRegion, nil),
+		InitialRows: workload.Tuples(numRegion, w.tpchRegionInitialRow),
 	}
 	part := workload.Table{
 		Name:        `part`,
 		Schema:      tpchPartSchema,
-		InitialRows: workload.Tuples(numPartPerSF*w.scaleFactor, nil),
+		InitialRows: workload.Tuples(numPartPerSF*w.scaleFactor, w.tpchPartInitialRow),
 	}
 	supplier := workload.Table{
 		Name:        `supplier`,
 		Schema:      tpchSupplierSchema,
-		InitialRows: workload.Tuples(numSupplierPerSF*w.scaleFactor, nil),
+		InitialRows: workload.Tuples(numSupplierPerSF*w.scaleFactor, w.tpchSupplierInitialRow),
 	}
 	partsupp := workload.Table{
 		Name:        `partsupp`,
 		Schema:      tpchPartSuppSchema,
-		InitialRows: workload.Tuples(numPartSuppPerSF*w.scaleFactor, nil),
+		InitialRows: workload.Tuples(numPartSuppPerSF*w.scaleFactor, w.tpchPartSuppInitialRow),
 	}
 	customer := workload.Table{
 		Name:        `customer`,
 		Schema:      tpchCustomerSchema,
-		InitialRows: workload.Tuples(numCustomerPerSF*w.scaleFactor, nil),
+		InitialRows: workload.Tuples(numCustomerPerSF*w.scaleFactor, w.tpchCustomerInitialRow),
 	}
 	orders := workload.Table{
 		Name:        `orders`,
 		Schema:      tpchOrdersSchema,
-		InitialRows: workload.Tuples(numOrderPerSF*w.scaleFactor, nil),
+		InitialRows: workload.Tuples(numOrderPerSF*w.scaleFactor, w.tpchOrdersInitialRow),
 	}
 	lineitem := workload.Table{
-		Name:        `lineitem`,
-		Schema:      tpchLineItemSchema,
-		InitialRows: workload.Tuples(numLineItemPerSF*w.scaleFactor, nil),
+		Name:   `lineitem`,
+		Schema: tpchLineItemSchema,
+		InitialRows: workload.BatchedTuples{
+			NumBatches: numOrderPerSF * w.scaleFactor,
+			FillBatch:  w.tpchLineItemInitialRowBatch,
+		},
 	}
 
 	return []workload.Table{